@@ -1,15 +1,20 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -30,24 +35,39 @@ func gtfsConfigFromData(gtfsCfgData appconf.GtfsConfigData) gtfs.Config {
 		StaticAuthHeaderKey:   gtfsCfgData.StaticAuthHeaderKey,
 		StaticAuthHeaderValue: gtfsCfgData.StaticAuthHeaderValue,
 		GTFSDataPath:          gtfsCfgData.GTFSDataPath,
+		DataEncryptionKey:     gtfsCfgData.DataEncryptionKey,
 		Env:                   gtfsCfgData.Env,
 
 		EnableGTFSTidy: gtfsCfgData.EnableGTFSTidy,
+		MirrorDir:      gtfsCfgData.MirrorDir,
+		AmenitiesPath:  gtfsCfgData.AmenitiesPath,
+
+		PostImportProcessors: gtfsCfgData.PostImportProcessors,
+
+		SlowQueryThreshold: gtfsCfgData.SlowQueryThreshold,
+
+		ReloadGuardMaxDropPercent: gtfsCfgData.ReloadGuardMaxDropPercent,
+		FeedExpiryWarningDays:     gtfsCfgData.FeedExpiryWarningDays,
+		RealtimeDisabledAgencies:  gtfsCfgData.RealtimeDisabledAgencies,
+		TransferRadiusMeters:      gtfsCfgData.TransferRadiusMeters,
+		ArrivalHistoryDays:        gtfsCfgData.ArrivalHistoryDays,
+		DatasetSnapshots:          gtfsCfgData.DatasetSnapshots,
+		DeviationSampleInterval:   gtfsCfgData.DeviationSampleInterval,
 	}
 
-	for _, feedData := range gtfsCfgData.RTFeeds {
-		gtfsCfg.RTFeeds = append(gtfsCfg.RTFeeds, gtfs.RTFeedConfig{
-			ID:                  feedData.ID,
-			AgencyIDs:           feedData.AgencyIDs,
-			TripUpdatesURL:      feedData.TripUpdatesURL,
-			VehiclePositionsURL: feedData.VehiclePositionsURL,
-			ServiceAlertsURL:    feedData.ServiceAlertsURL,
-			Headers:             feedData.Headers,
-			RefreshInterval:     feedData.RefreshInterval,
-			Enabled:             feedData.Enabled,
+	for _, feedData := range gtfsCfgData.AdditionalStaticFeeds {
+		gtfsCfg.AdditionalStaticFeeds = append(gtfsCfg.AdditionalStaticFeeds, gtfs.StaticFeedConfig{
+			URL:             feedData.URL,
+			AuthHeaderKey:   feedData.AuthHeaderKey,
+			AuthHeaderValue: feedData.AuthHeaderValue,
+			EnableGTFSTidy:  feedData.EnableGTFSTidy,
 		})
 	}
 
+	if len(gtfsCfgData.RTFeeds) > 0 {
+		gtfsCfg.RTFeeds = app.RTFeedConfigs(gtfsCfgData.RTFeeds)
+	}
+
 	return gtfsCfg
 }
 
@@ -65,6 +85,16 @@ func ParseAPIKeys(apiKeysFlag string) []string {
 	return keys
 }
 
+// additionalStaticFeeds builds the additional static feed configs for the
+// -additional-gtfs-urls flag. Feeds given on the command line have no auth.
+func additionalStaticFeeds(urls []string) []appconf.GtfsStaticFeed {
+	feeds := make([]appconf.GtfsStaticFeed, 0, len(urls))
+	for _, url := range urls {
+		feeds = append(feeds, appconf.GtfsStaticFeed{URL: url})
+	}
+	return feeds
+}
+
 func parseLogLevel(level string) slog.Level {
 	switch strings.ToLower(strings.TrimSpace(level)) {
 	case "debug":
@@ -95,6 +125,9 @@ func BuildApplication(ctx context.Context, cfg appconf.Config, gtfsCfg gtfs.Conf
 	logger := slog.Default()
 	appMetrics := metrics.NewWithLogger(logger)
 	gtfsCfg.Metrics = appMetrics
+	gtfsCfg.Logger = logger
+	// The feed-reload scheduled job reloads the static feed instead.
+	gtfsCfg.ExternalStaticRefresh = true
 
 	gtfsManager, err := gtfs.InitGTFSManager(ctx, gtfsCfg)
 	if err != nil {
@@ -162,26 +195,39 @@ func CreateServer(coreApp *app.Application, cfg appconf.Config) (*http.Server, *
 	webUI.SetWebUIRoutes(mux)
 
 	// Add metrics endpoint (no auth required) - uses custom registry with structured error logging
-	mux.Handle("GET /metrics", promhttp.HandlerFor(coreApp.Metrics.Registry, promhttp.HandlerOpts{
-		ErrorLog: slog.NewLogLogger(coreApp.Logger.Handler(), slog.LevelError),
-	}))
+	if cfg.MetricsEnabled {
+		mux.Handle("GET /metrics", promhttp.HandlerFor(coreApp.Metrics.Registry, promhttp.HandlerOpts{
+			ErrorLog: slog.NewLogLogger(coreApp.Logger.Handler(), slog.LevelError),
+		}))
+	}
 
 	// Apply API-specific middleware closest to the routes. Both middlewares
 	// guard on the "/api/" path prefix internally, so wrapping the whole mux
 	// leaves web UI and other endpoints untouched.
-	// Order (innermost to outermost): expiry -> version.
+	// Order (innermost to outermost): expiry -> version -> slo -> xml.
 	var apiHandler http.Handler = mux
 	apiHandler = restapi.GtfsExpiryMiddleware(api.GtfsManager)(apiHandler)
 	apiHandler = api.VersionValidationMiddleware(apiHandler)
+	// The canary probes below the SLO middleware so synthetic traffic does
+	// not count against the error budget.
+	api.StartCanary(apiHandler)
+	api.StartScheduler()
+	apiHandler = api.SLOMiddleware(apiHandler)
+	// .xml requests are served by the JSON handlers and rewritten on the way out
+	apiHandler = restapi.XMLFormatMiddleware(apiHandler)
+	// .pb requests likewise, encoded as protobuf by the routes that support it
+	apiHandler = restapi.ProtobufFormatMiddleware(apiHandler)
+	// /regions/{id} prefixes are removed before any path-based middleware
+	apiHandler = api.RegionMiddleware(apiHandler)
 
 	// Apply compression around apiHandler (the mux plus API-specific middleware)
-	compressedMux := restapi.CompressionMiddleware(apiHandler)
+	compressedMux := restapi.NewCompressionMiddleware(cfg.Compression)(apiHandler)
 
 	// Add freshness middleware
 	freshnessHandler := api.FreshnessMiddleware(compressedMux)
 
 	// Wrap with security middleware
-	secureHandler := api.WithSecurityHeaders(freshnessHandler)
+	secureHandler := restapi.VersionHeaderMiddleware(api.WithSecurityHeaders(freshnessHandler))
 
 	// Add metrics middleware
 	metricsHandler := restapi.MetricsHandler(coreApp.Metrics)(secureHandler)
@@ -211,12 +257,16 @@ func CreateServer(coreApp *app.Application, cfg appconf.Config) (*http.Server, *
 
 // Run manages the server lifecycle with graceful shutdown.
 // Starts the server in a goroutine, waits for shutdown signals (SIGINT, SIGTERM) or context cancellation,
-// and performs graceful shutdown with a 30-second timeout.
+// and stops the server and its subsystems through a Lifecycle within the configured
+// shutdown timeout (30 seconds by default). SIGHUP reloads the config file in place.
 // Returns an error if the server fails to start or shutdown fails.
 func Run(ctx context.Context, srv *http.Server, coreApp *app.Application, api *restapi.RestAPI) error {
 	cfg := coreApp.Config
 	tlsEnabled := cfg.TLSCertPath != "" && cfg.TLSKeyPath != ""
 	logger := coreApp.Logger
+	if api != nil {
+		api.LogStartupBanner(ctx, srv.Addr)
+	}
 	logger.Info("starting server", "addr", srv.Addr, "tls", tlsEnabled)
 
 	// Set up signal handling for graceful shutdown, merging with provided context
@@ -239,41 +289,142 @@ func Run(ctx context.Context, srv *http.Server, coreApp *app.Application, api *r
 		}
 	}()
 
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
 	// Wait for either shutdown signal/context cancellation or server error
-	select {
-	case err := <-serverErrors:
-		return fmt.Errorf("server failed to start: %w", err)
-	case <-ctx.Done():
-		logger.Info("shutting down server...")
+serve:
+	for {
+		select {
+		case err := <-serverErrors:
+			return fmt.Errorf("server failed to start: %w", err)
+		case <-reload:
+			reloadConfig(coreApp)
+		case <-ctx.Done():
+			logger.Info("shutting down server...")
+			break serve
+		}
 	}
 
-	// Create shutdown context with timeout
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout := cmp.Or(cfg.ShutdownTimeout, appconf.DefaultShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-
-	// Shutdown server
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		logger.Error("server forced to shutdown", "error", err)
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	if err := newLifecycle(srv, coreApp, api).Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown incomplete: %w", err)
 	}
 
-	// Shutdown API rate limiter first (stops background goroutines for request handling)
-	if api != nil {
-		api.Shutdown()
-	}
+	logger.Info("server exited")
+	return nil
+}
 
-	// Shutdown metrics collector (blocks until goroutine exits)
-	if coreApp.Metrics != nil {
-		coreApp.Metrics.Shutdown()
+// Lifecycle owns the shutdown of the server and its background subsystems.
+// Subsystems register a stop function as they are started; Shutdown stops
+// them in reverse order, so each one stops before the dependencies
+// registered ahead of it, all within one drain deadline.
+type Lifecycle struct {
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	hooks []lifecycleHook
+}
+
+type lifecycleHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// NewLifecycle creates a lifecycle with nothing registered.
+func NewLifecycle(logger *slog.Logger) *Lifecycle {
+	return &Lifecycle{logger: logger}
+}
+
+// Register adds a subsystem to stop on shutdown. stop should return once the
+// subsystem's goroutines have exited, giving up when ctx ends.
+func (l *Lifecycle) Register(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, lifecycleHook{name: name, stop: stop})
+}
+
+// Shutdown stops every registered subsystem, newest first. A subsystem that
+// fails or is still running when ctx ends is logged and left behind so the
+// rest still get stopped; those reached after ctx ends are told to stop but
+// not waited for. The returned error joins every such failure.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := slices.Clone(l.hooks)
+	l.hooks = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for _, hook := range slices.Backward(hooks) {
+		var err error
+		if ctx.Err() != nil {
+			// Still tell the subsystem to stop, but the deadline has passed.
+			go func() { _ = hook.stop(ctx) }()
+			err = fmt.Errorf("not waited for after the drain timeout: %w", ctx.Err())
+		} else {
+			done := make(chan error, 1)
+			go func() { done <- hook.stop(ctx) }()
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = fmt.Errorf("did not stop within the drain timeout: %w", ctx.Err())
+			}
+		}
+		if err != nil {
+			l.logger.Error("subsystem shutdown failed", "subsystem", hook.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", hook.name, err))
+			continue
+		}
+		l.logger.Debug("subsystem stopped", "subsystem", hook.name)
 	}
+	return errors.Join(errs...)
+}
 
-	// Then shutdown GTFS manager (stops data fetching - the lowest-level dependency)
+// newLifecycle registers the server's subsystems from the lowest-level
+// dependency up: the GTFS manager's reload and realtime loops, the metrics
+// collector, the REST API's scheduler, canary, usage tracker and rate limiter,
+// and last the HTTP server, which therefore stops first and drains in-flight
+// requests while everything they use is still running.
+func newLifecycle(srv *http.Server, coreApp *app.Application, api *restapi.RestAPI) *Lifecycle {
+	lifecycle := NewLifecycle(coreApp.Logger)
 	if coreApp.GtfsManager != nil {
-		coreApp.GtfsManager.Shutdown()
+		lifecycle.Register("gtfs-manager", func(context.Context) error {
+			coreApp.GtfsManager.Shutdown()
+			return nil
+		})
+	}
+	if coreApp.Metrics != nil {
+		lifecycle.Register("metrics", func(context.Context) error {
+			coreApp.Metrics.Shutdown()
+			return nil
+		})
+	}
+	if api != nil {
+		lifecycle.Register("rest-api", func(context.Context) error {
+			api.Shutdown()
+			return nil
+		})
 	}
+	lifecycle.Register("http-server", srv.Shutdown)
+	return lifecycle
+}
 
-	logger.Info("server exited")
-	return nil
+// reloadConfig applies the config file's reloadable settings on SIGHUP,
+// logging rather than failing so a bad edit never takes the server down.
+func reloadConfig(coreApp *app.Application) {
+	logger := coreApp.Logger
+	restartRequired, err := coreApp.ReloadConfig()
+	if err != nil {
+		logging.LogError(logger, "configuration reload failed; keeping the current configuration", err)
+		return
+	}
+	logger.Info("configuration reloaded")
+	if restartRequired {
+		logger.Warn("configuration changes other than API keys, rate limits and realtime feeds take effect on restart")
+	}
 }
 
 // dumpConfigJSON converts current configuration to JSON and prints it to stdout
@@ -312,6 +463,180 @@ func dumpConfigJSON(cfg appconf.Config, gtfsCfg gtfs.Config) {
 		"gtfs-static-feed": staticFeed,
 		"data-path":        gtfsCfg.GTFSDataPath,
 	}
+	if gtfsCfg.DataEncryptionKey != "" {
+		jsonConfig["data-encryption-key"] = "***REDACTED***"
+	}
+	if !cfg.MetricsEnabled {
+		jsonConfig["metrics-enabled"] = false
+	}
+	if cfg.ShutdownTimeout > 0 {
+		jsonConfig["shutdown-timeout-seconds"] = int(cfg.ShutdownTimeout.Seconds())
+	}
+	if cfg.Interpolation.Enabled {
+		interpolation := map[string]any{"enabled": true}
+		if cfg.Interpolation.MaxElapsed > 0 {
+			interpolation["max-elapsed-seconds"] = int(cfg.Interpolation.MaxElapsed / time.Second)
+		}
+		jsonConfig["vehicle-interpolation"] = interpolation
+	}
+	if len(gtfsCfg.AdditionalStaticFeeds) > 0 {
+		additionalFeeds := make([]map[string]string, 0, len(gtfsCfg.AdditionalStaticFeeds))
+		for _, feed := range gtfsCfg.AdditionalStaticFeeds {
+			feedMap := map[string]string{"url": feed.URL}
+			if feed.AuthHeaderKey != "" {
+				feedMap["auth-header-name"] = feed.AuthHeaderKey
+				feedMap["auth-header-value"] = "***REDACTED***"
+			}
+			additionalFeeds = append(additionalFeeds, feedMap)
+		}
+		jsonConfig["additional-gtfs-static-feeds"] = additionalFeeds
+	}
+	if len(cfg.ExemptIPRanges) > 0 {
+		jsonConfig["exempt-ip-ranges"] = cfg.ExemptIPRanges
+	}
+	if gtfsCfg.MirrorDir != "" {
+		jsonConfig["mirror-dir"] = gtfsCfg.MirrorDir
+	}
+	if gtfsCfg.AmenitiesPath != "" {
+		jsonConfig["amenities-path"] = gtfsCfg.AmenitiesPath
+	}
+	if len(gtfsCfg.PostImportProcessors) > 0 {
+		jsonConfig["post-import-processors"] = gtfsCfg.PostImportProcessors
+	}
+	if gtfsCfg.SlowQueryThreshold > 0 {
+		jsonConfig["slow-query-threshold-ms"] = gtfsCfg.SlowQueryThreshold.Milliseconds()
+	}
+	if gtfsCfg.ReloadGuardMaxDropPercent > 0 {
+		jsonConfig["reload-guard-max-drop-percent"] = gtfsCfg.ReloadGuardMaxDropPercent
+	}
+	if gtfsCfg.FeedExpiryWarningDays > 0 {
+		jsonConfig["feed-expiry-warning-days"] = gtfsCfg.FeedExpiryWarningDays
+	}
+	if gtfsCfg.TransferRadiusMeters > 0 {
+		jsonConfig["transfer-radius-meters"] = gtfsCfg.TransferRadiusMeters
+	}
+	if gtfsCfg.ArrivalHistoryDays > 0 {
+		jsonConfig["arrival-history-days"] = gtfsCfg.ArrivalHistoryDays
+	}
+	if gtfsCfg.DatasetSnapshots > 0 {
+		jsonConfig["dataset-snapshots"] = gtfsCfg.DatasetSnapshots
+	}
+	if gtfsCfg.DeviationSampleInterval > 0 {
+		jsonConfig["deviation-sample-interval-seconds"] = int(gtfsCfg.DeviationSampleInterval / time.Second)
+	}
+	if len(gtfsCfg.RealtimeDisabledAgencies) > 0 {
+		jsonConfig["realtime-disabled-agencies"] = gtfsCfg.RealtimeDisabledAgencies
+	}
+	if cfg.LoadShedding.MaxInFlight > 0 || cfg.LoadShedding.TargetP99 > 0 {
+		loadShedding := map[string]any{
+			"max-in-flight": cfg.LoadShedding.MaxInFlight,
+			"target-p99-ms": cfg.LoadShedding.TargetP99.Milliseconds(),
+		}
+		if len(cfg.LoadShedding.Priorities) > 0 {
+			loadShedding["priorities"] = cfg.LoadShedding.Priorities
+		}
+		jsonConfig["load-shedding"] = loadShedding
+	}
+	if cfg.Canary.Enabled() {
+		canary := map[string]any{
+			"interval-seconds": int(cfg.Canary.Interval.Seconds()),
+		}
+		if cfg.Canary.StopID != "" {
+			canary["stop-id"] = cfg.Canary.StopID
+		}
+		if cfg.Canary.RouteID != "" {
+			canary["route-id"] = cfg.Canary.RouteID
+		}
+		jsonConfig["canary"] = canary
+	}
+	if cfg.SMS.Template != "" || len(cfg.SMS.AgencyTemplates) > 0 {
+		sms := map[string]any{}
+		if cfg.SMS.Template != "" {
+			sms["template"] = cfg.SMS.Template
+		}
+		if len(cfg.SMS.AgencyTemplates) > 0 {
+			sms["agency-templates"] = cfg.SMS.AgencyTemplates
+		}
+		jsonConfig["sms"] = sms
+	}
+	if cfg.Portal.Enabled {
+		portalCfg := cfg.Portal.WithDefaults()
+		developerPortal := map[string]any{
+			"enabled":                true,
+			"base-url":               portalCfg.BaseURL,
+			"rate-limit":             portalCfg.RateLimit,
+			"elevated-rate-limit":    portalCfg.ElevatedRateLimit,
+			"verification-ttl-hours": int(portalCfg.VerificationTTL.Hours()),
+			"max-keys-per-email":     portalCfg.MaxKeysPerEmail,
+		}
+		if portalCfg.SMTP.Host != "" {
+			smtp := map[string]any{
+				"host": portalCfg.SMTP.Host,
+				"port": portalCfg.SMTP.Port,
+				"from": portalCfg.SMTP.From,
+			}
+			if portalCfg.SMTP.Username != "" {
+				smtp["username"] = portalCfg.SMTP.Username
+				smtp["password"] = "***REDACTED***"
+			}
+			developerPortal["smtp"] = smtp
+		}
+		jsonConfig["developer-portal"] = developerPortal
+	}
+	if cfg.SLO != (appconf.SLOConfig{}) {
+		slo := cfg.SLO.WithDefaults()
+		jsonConfig["slo"] = map[string]any{
+			"availability-target-percent": slo.AvailabilityTarget,
+			"latency-threshold-ms":        slo.LatencyThreshold.Milliseconds(),
+			"latency-target-percent":      slo.LatencyTarget,
+			"window-days":                 int(slo.Window.Hours() / 24),
+		}
+	}
+	if cfg.RateLimitBackend != (appconf.RateLimitBackendConfig{}) {
+		backend := map[string]any{"type": cfg.RateLimitBackend.Type}
+		if cfg.RateLimitBackend.RedisURL != "" {
+			redisURL := "***REDACTED***"
+			if u, err := url.Parse(cfg.RateLimitBackend.RedisURL); err == nil {
+				redisURL = u.Redacted()
+			}
+			backend["redis-url"] = redisURL
+		}
+		if cfg.RateLimitBackend.RedisKey != "" {
+			backend["redis-key"] = cfg.RateLimitBackend.RedisKey
+		}
+		jsonConfig["rate-limit-backend"] = backend
+	}
+	if cfg.Search != (appconf.SearchConfig{}) {
+		search := cfg.Search.WithDefaults()
+		jsonConfig["search"] = map[string]any{
+			"default-radius-meters":    search.DefaultRadius,
+			"query-radius-meters":      search.QueryRadius,
+			"max-radius-meters":        search.MaxRadius,
+			"default-max-count-stops":  search.DefaultMaxCountStops,
+			"default-max-count-routes": search.DefaultMaxCountRoutes,
+			"max-count":                search.MaxCount,
+		}
+	}
+	if len(cfg.Jobs) > 0 {
+		jobs := make(map[string]any, len(cfg.Jobs))
+		for name, job := range cfg.Jobs {
+			entry := map[string]any{}
+			if job.Enabled != nil {
+				entry["enabled"] = *job.Enabled
+			}
+			if job.Interval > 0 {
+				entry["interval-seconds"] = int(job.Interval.Seconds())
+			}
+			if job.Jitter > 0 {
+				entry["jitter-seconds"] = int(job.Jitter.Seconds())
+			}
+			if job.Retention > 0 {
+				entry["retention-days"] = int(job.Retention.Hours() / 24)
+			}
+			jobs[name] = entry
+		}
+		jsonConfig["scheduled-jobs"] = jobs
+	}
 
 	var feeds []map[string]any
 	for _, feedCfg := range gtfsCfg.RTFeeds {
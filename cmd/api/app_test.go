@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -97,9 +99,13 @@ func TestBuildApplicationWithMemoryDB(t *testing.T) {
 	assert.NotNil(t, coreApp.Logger, "Logger should be initialized")
 	assert.Equal(t, cfg, coreApp.Config, "Config should match input")
 
-	// BuildApplication injects the metrics client into the config.
-	// We sync the injected Metrics over to our local copy so the assertion passes.
+	// BuildApplication injects the metrics client and logger into the config.
+	// We sync the injected values over to our local copy so the assertion passes.
+	assert.Same(t, coreApp.Logger, coreApp.GtfsConfig.Logger, "GTFS manager should share the application logger")
 	gtfsCfg.Metrics = coreApp.GtfsConfig.Metrics
+	gtfsCfg.Logger = coreApp.GtfsConfig.Logger
+	assert.True(t, coreApp.GtfsConfig.ExternalStaticRefresh, "static reloads are left to the feed-reload scheduled job")
+	gtfsCfg.ExternalStaticRefresh = true
 	assert.Equal(t, gtfsCfg, coreApp.GtfsConfig, "GtfsConfig should match input")
 }
 
@@ -215,6 +221,43 @@ func TestCreateServerHandlerResponds(t *testing.T) {
 	assert.NotEqual(t, http.StatusNotFound, w.Code, "Handler should be configured and respond to requests")
 }
 
+func TestCreateServerMetricsEndpoint(t *testing.T) {
+	testDataPath := filepath.Join("..", "..", "testdata", "raba.zip")
+	if _, err := os.Stat(testDataPath); os.IsNotExist(err) {
+		t.Skip("Test data not available, skipping test")
+	}
+
+	cfg := appconf.Config{
+		Port:           8080,
+		Env:            appconf.Test,
+		ApiKeys:        []string{"test"},
+		RateLimit:      100,
+		MetricsEnabled: true,
+	}
+	coreApp, err := BuildApplication(context.Background(), cfg, gtfs.Config{
+		GTFSDataPath: ":memory:",
+		GtfsURL:      testDataPath,
+	})
+	require.NoError(t, err)
+
+	srv, api := CreateServer(coreApp, cfg)
+	defer api.Shutdown()
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	for _, name := range []string{"go_goroutines", "go_memstats_heap_alloc_bytes", "maglev_gtfs_static_last_import_time"} {
+		assert.Contains(t, body, name)
+	}
+
+	cfg.MetricsEnabled = false
+	srv, disabledAPI := CreateServer(coreApp, cfg)
+	defer disabledAPI.Shutdown()
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NotContains(t, w.Body.String(), "go_goroutines", "with metrics disabled the path falls through to the web UI")
+}
+
 func TestRunServerStartsAndStopsCleanly(t *testing.T) {
 	ctx := context.Background()
 
@@ -543,6 +586,51 @@ func TestRun_GracefulShutdown(t *testing.T) {
 	}
 }
 
+func TestLifecycle_StopsNewestFirst(t *testing.T) {
+	lifecycle := NewLifecycle(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var stopped []string
+	for _, name := range []string{"gtfs-manager", "rest-api", "http-server"} {
+		lifecycle.Register(name, func(context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+
+	require.NoError(t, lifecycle.Shutdown(context.Background()))
+	assert.Equal(t, []string{"http-server", "rest-api", "gtfs-manager"}, stopped)
+	require.NoError(t, lifecycle.Shutdown(context.Background()), "subsystems are stopped once")
+	assert.Len(t, stopped, 3)
+}
+
+func TestLifecycle_ContinuesPastFailedAndStuckSubsystems(t *testing.T) {
+	lifecycle := NewLifecycle(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var stopped atomic.Bool
+	lifecycle.Register("database", func(context.Context) error {
+		stopped.Store(true)
+		return nil
+	})
+	release := make(chan struct{})
+	defer close(release)
+	lifecycle.Register("stuck", func(context.Context) error {
+		<-release
+		return nil
+	})
+	lifecycle.Register("failing", func(context.Context) error {
+		return errors.New("flush failed")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := lifecycle.Shutdown(ctx)
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "failing: flush failed")
+	assert.ErrorContains(t, err, "stuck: did not stop within the drain timeout")
+	assert.ErrorContains(t, err, "database: not waited for after the drain timeout")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Eventually(t, stopped.Load, time.Second, 10*time.Millisecond, "later subsystems are still told to stop")
+}
+
 func TestDumpConfigJSON_WithExampleFile(t *testing.T) {
 	// Load configuration from JSON file
 	jsonConfig, err := appconf.LoadFromFile("../../config.example.json")
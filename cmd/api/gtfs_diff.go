@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+// runGtfsDiff implements "maglev gtfs-diff": it compares two static GTFS zips
+// and reports the routes, stops and trips added, removed or changed, plus the
+// routes whose number of scheduled trips changed, so operators can review a
+// feed update before it goes live.
+func runGtfsDiff(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("gtfs-diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "Print the full diff as JSON")
+	maxIDs := fs.Int("max-ids", 20, "Maximum IDs listed per category in the text report (0 for all)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: maglev gtfs-diff [-json] [-max-ids <n>] <old.zip> <new.zip>")
+		_, _ = fmt.Fprintln(fs.Output(), "\nReports what changes between two versions of a static GTFS feed.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected an old and a new GTFS zip")
+	}
+
+	feeds := make([]*gtfsdb.GtfsData, 2)
+	for i, path := range fs.Args() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read GTFS zip: %w", err)
+		}
+		if feeds[i], err = gtfsdb.ParseGtfsData(b, path); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	diff := gtfsdb.DiffGtfsData(feeds[0].Static, feeds[1].Static)
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+	return writeGtfsDiffReport(stdout, diff, *maxIDs)
+}
+
+func writeGtfsDiffReport(w io.Writer, diff gtfsdb.GtfsDiff, maxIDs int) error {
+	var b strings.Builder
+	if diff.Empty() {
+		b.WriteString("No changes.\n")
+	}
+	for _, section := range []struct {
+		name string
+		diff gtfsdb.EntityDiff
+	}{
+		{"routes", diff.Routes},
+		{"stops", diff.Stops},
+		{"trips", diff.Trips},
+	} {
+		if section.diff.Empty() {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %d added, %d removed, %d changed\n",
+			section.name, len(section.diff.Added), len(section.diff.Removed), len(section.diff.Changed))
+		writeIDList(&b, "added", section.diff.Added, maxIDs)
+		writeIDList(&b, "removed", section.diff.Removed, maxIDs)
+		writeIDList(&b, "changed", section.diff.Changed, maxIDs)
+	}
+	if len(diff.ServiceLevels) > 0 {
+		b.WriteString("scheduled trips per route:\n")
+		for i, level := range diff.ServiceLevels {
+			if maxIDs > 0 && i == maxIDs {
+				fmt.Fprintf(&b, "  ... and %d more\n", len(diff.ServiceLevels)-maxIDs)
+				break
+			}
+			fmt.Fprintf(&b, "  %s: %d -> %d", level.RouteID, level.OldTrips, level.NewTrips)
+			if level.OldTrips > 0 {
+				fmt.Fprintf(&b, " (%+.1f%%)", float64(level.NewTrips-level.OldTrips)*100/float64(level.OldTrips))
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeIDList(b *strings.Builder, label string, ids []string, maxIDs int) {
+	if len(ids) == 0 {
+		return
+	}
+	shown := ids
+	if maxIDs > 0 && len(ids) > maxIDs {
+		shown = ids[:maxIDs]
+	}
+	fmt.Fprintf(b, "  %s: %s", label, strings.Join(shown, ", "))
+	if len(shown) < len(ids) {
+		fmt.Fprintf(b, " ... and %d more", len(ids)-len(shown))
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+func TestRunGtfsDiff_SameFeed(t *testing.T) {
+	raba := filepath.Join("..", "..", "testdata", "raba.zip")
+
+	var stdout, stderr bytes.Buffer
+	err := runGtfsDiff(context.Background(), []string{raba, raba}, &stdout, &stderr)
+	require.NoError(t, err, stderr.String())
+	assert.Equal(t, "No changes.\n", stdout.String())
+}
+
+func TestRunGtfsDiff_DifferentFeeds(t *testing.T) {
+	raba := filepath.Join("..", "..", "testdata", "raba.zip")
+	other := filepath.Join("..", "..", "testdata", "gtfs.zip")
+
+	var stdout, stderr bytes.Buffer
+	err := runGtfsDiff(context.Background(), []string{"-json", raba, other}, &stdout, &stderr)
+	require.NoError(t, err, stderr.String())
+
+	var diff gtfsdb.GtfsDiff
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &diff))
+	assert.NotEmpty(t, diff.Routes.Removed, "RABA routes are not in the other feed")
+	assert.NotEmpty(t, diff.Routes.Added)
+	assert.NotEmpty(t, diff.ServiceLevels)
+
+	stdout.Reset()
+	err = runGtfsDiff(context.Background(), []string{"-max-ids", "1", raba, other}, &stdout, &stderr)
+	require.NoError(t, err, stderr.String())
+	report := stdout.String()
+	assert.Contains(t, report, "routes: ")
+	assert.Contains(t, report, "scheduled trips per route:")
+	assert.Contains(t, report, "more")
+	for _, line := range strings.Split(strings.TrimSpace(report), "\n") {
+		if strings.HasPrefix(line, "  removed: ") {
+			assert.NotContains(t, strings.SplitN(line, " ... ", 2)[0], ",", "-max-ids limits the listed IDs")
+		}
+	}
+}
+
+func TestRunGtfsDiff_Errors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	err := runGtfsDiff(context.Background(), []string{"only-one.zip"}, &stdout, &stderr)
+	assert.EqualError(t, err, "expected an old and a new GTFS zip")
+
+	err = runGtfsDiff(context.Background(), []string{"missing.zip", "missing.zip"}, &stdout, &stderr)
+	assert.ErrorContains(t, err, "failed to read GTFS zip")
+
+	err = runGtfsDiff(context.Background(), []string{"-h"}, &stdout, &stderr)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
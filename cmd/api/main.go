@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log/slog"
 	"net/http"
@@ -20,6 +21,33 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// "maglev query" answers a single request offline instead of serving.
+	// Logs go to stderr so stdout holds only the JSON response.
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+		if err := runQuery(ctx, os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			slog.Error("query failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// "maglev gtfs-diff" compares two static feeds instead of serving.
+	if len(os.Args) > 1 && os.Args[1] == "gtfs-diff" {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+		if err := runGtfsDiff(ctx, os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			slog.Error("gtfs-diff failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create a temporary logger for reporting errors during startup.
 	startupLogger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
@@ -47,9 +75,17 @@ func main() {
 	var gtfsCfg gtfs.Config
 	var apiKeysFlag string
 	var exemptApiKeysFlag string
+	var exemptIPRangesFlag string
+	var postImportProcessorsFlag string
+	var realtimeDisabledAgenciesFlag string
+	var additionalGtfsURLsFlag string
 	var envFlag string
 	var configFile string
 	var dumpConfig bool
+	var slowQueryMs int
+	var deviationSampleSeconds int
+	var loadShedTargetP99Ms int
+	var shutdownTimeoutSeconds int
 
 	// CLI-only realtime feed fields (assembled into RTFeeds slice below)
 	var cliFeedTripUpdatesURL string
@@ -65,8 +101,10 @@ func main() {
 	flag.StringVar(&envFlag, "env", "development", "Environment (development|test|production)")
 	flag.StringVar(&apiKeysFlag, "api-keys", "test", "Comma Separated API Keys (test, etc)")
 	flag.StringVar(&exemptApiKeysFlag, "exempt-api-keys", "org.onebusaway.iphone", "Comma separated list of API keys exempt from rate limiting")
+	flag.StringVar(&exemptIPRangesFlag, "exempt-ip-ranges", "", "Comma separated list of CIDR ranges or IP addresses exempt from rate limiting (e.g. internal signage networks)")
 	flag.IntVar(&cfg.RateLimit, "rate-limit", 100, "Requests per second across the entire service (global shared bucket; exempt keys bypass it)")
 	flag.StringVar(&gtfsCfg.GtfsURL, "gtfs-url", "https://www.soundtransit.org/GTFS-rail/40_gtfs.zip", "URL for a static GTFS zip file")
+	flag.StringVar(&additionalGtfsURLsFlag, "additional-gtfs-urls", "", "Comma separated list of further static GTFS zip files combined with -gtfs-url (route, stop and trip IDs must be distinct across feeds)")
 	flag.StringVar(&gtfsCfg.StaticAuthHeaderKey, "gtfs-static-auth-header-name", "", "Optional header name for static GTFS feed auth")
 	flag.StringVar(&gtfsCfg.StaticAuthHeaderValue, "gtfs-static-auth-header-value", "", "Optional header value for static GTFS feed auth")
 	flag.StringVar(&cliFeedTripUpdatesURL, "trip-updates-url", "https://api.pugetsound.onebusaway.org/api/gtfs_realtime/trip-updates-for-agency/40.pb?key=org.onebusaway.iphone", "URL for a GTFS-RT trip updates feed")
@@ -75,8 +113,25 @@ func main() {
 	flag.StringVar(&cliFeedAuthHeaderValue, "realtime-auth-header-value", "", "Optional header value for GTFS-RT auth")
 	flag.StringVar(&cliFeedServiceAlertsURL, "service-alerts-url", "", "URL for a GTFS-RT service alerts feed")
 	flag.StringVar(&gtfsCfg.GTFSDataPath, "data-path", "./gtfs.db", "Path to the SQLite database containing GTFS data")
+	flag.StringVar(&gtfsCfg.DataEncryptionKey, "data-encryption-key", "", "Optional SQLCipher key for encrypting the database at rest (requires a SQLCipher build)")
+	flag.StringVar(&gtfsCfg.MirrorDir, "mirror-dir", "", "Directory where the last good static and realtime feeds are mirrored for offline boot (disabled when empty)")
+	flag.StringVar(&gtfsCfg.AmenitiesPath, "amenities-path", "", "Optional CSV or GeoJSON file describing stop amenities such as parking, bike racks and ticket machines")
+	flag.StringVar(&realtimeDisabledAgenciesFlag, "realtime-disabled-agencies", "", "Comma separated list of agency IDs to serve schedule-only, ignoring their realtime data")
+	flag.StringVar(&postImportProcessorsFlag, "post-import-processors", "", "Comma separated list of registered processors to run after each static import, in order (e.g. sqlite-optimize)")
+	flag.IntVar(&slowQueryMs, "slow-query-threshold-ms", 0, "Log database queries taking at least this many milliseconds, with their parameters (disabled when 0)")
+	flag.Float64Var(&gtfsCfg.ReloadGuardMaxDropPercent, "reload-guard-max-drop-percent", 0, "Refuse static reloads that remove more than this percentage of trips or stops until approved (disabled when 0)")
+	flag.IntVar(&gtfsCfg.FeedExpiryWarningDays, "feed-expiry-warning-days", 7, "Warn when the static feed's service ends within this many days")
+	flag.Float64Var(&gtfsCfg.TransferRadiusMeters, "transfer-radius-meters", 0, "Generate transfers between stops at most this many meters apart when the feed has no transfers.txt (disabled when 0)")
+	flag.IntVar(&gtfsCfg.ArrivalHistoryDays, "arrival-history-days", 0, "Record realized arrivals from the realtime feeds and keep them this many days for historical occupancy (disabled when 0)")
+	flag.IntVar(&gtfsCfg.DatasetSnapshots, "dataset-snapshots", 0, "Keep this many datasets replaced by static reloads for requests with datasetVersion (disabled when 0)")
+	flag.IntVar(&deviationSampleSeconds, "deviation-sample-interval-seconds", 0, "Sample the schedule deviation of realtime trips this often for schedule-deviation-history-for-trip (disabled when 0)")
+	flag.IntVar(&cfg.LoadShedding.MaxInFlight, "load-shed-max-in-flight", 0, "In-flight API requests at which low-priority endpoints start returning 503 (disabled when 0)")
+	flag.IntVar(&loadShedTargetP99Ms, "load-shed-target-p99-ms", 0, "Recent p99 latency in milliseconds at which low-priority endpoints start returning 503 (disabled when 0)")
 	flag.StringVar(&cfg.TLSCertPath, "tls-cert-path", "", "Path to TLS certificate file (enables HTTPS when set with tls-key-path)")
 	flag.StringVar(&cfg.TLSKeyPath, "tls-key-path", "", "Path to TLS private key file (enables HTTPS when set with tls-cert-path)")
+	flag.BoolVar(&cfg.MetricsEnabled, "metrics-enabled", true, "Serve Prometheus metrics on /metrics")
+	flag.BoolVar(&cfg.Interpolation.Enabled, "interpolate-vehicle-positions", false, "Advance vehicle positions along their trip's shape between realtime updates")
+	flag.IntVar(&shutdownTimeoutSeconds, "shutdown-timeout-seconds", 30, "Seconds shutdown waits for in-flight requests and background subsystems before exiting anyway")
 	flag.Parse()
 
 	// Enforce mutual exclusivity between -f and other flags (except --dump-config)
@@ -115,16 +170,18 @@ func main() {
 		// Pack the CLI flags into a temporary JSONConfig struct
 		// This allows us to run the exact same robust validation logic as the JSON path!
 		cliConfig := appconf.JSONConfig{
-			Port:          cfg.Port,
-			Env:           envFlag,
-			ApiKeys:       ParseAPIKeys(apiKeysFlag),
-			ExemptApiKeys: ParseAPIKeys(exemptApiKeysFlag),
-			RateLimit:     cfg.RateLimit,
+			Port:           cfg.Port,
+			Env:            envFlag,
+			ApiKeys:        ParseAPIKeys(apiKeysFlag),
+			ExemptApiKeys:  ParseAPIKeys(exemptApiKeysFlag),
+			ExemptIPRanges: ParseAPIKeys(exemptIPRangesFlag),
+			RateLimit:      cfg.RateLimit,
 			GtfsStaticFeed: appconf.GtfsStaticFeed{
 				URL:             gtfsCfg.GtfsURL,
 				AuthHeaderName:  gtfsCfg.StaticAuthHeaderKey,
 				AuthHeaderValue: gtfsCfg.StaticAuthHeaderValue,
 			},
+			AdditionalGtfsStaticFeeds: additionalStaticFeeds(ParseAPIKeys(additionalGtfsURLsFlag)),
 			GtfsRtFeeds: []appconf.GtfsRtFeed{
 				{
 					ID:                      "feed-0",
@@ -136,9 +193,28 @@ func main() {
 					RefreshInterval:         30,
 				},
 			},
-			DataPath:    gtfsCfg.GTFSDataPath,
-			TLSCertPath: cfg.TLSCertPath,
-			TLSKeyPath:  cfg.TLSKeyPath,
+			DataPath:                  gtfsCfg.GTFSDataPath,
+			DataEncryptionKey:         gtfsCfg.DataEncryptionKey,
+			MirrorDir:                 gtfsCfg.MirrorDir,
+			AmenitiesPath:             gtfsCfg.AmenitiesPath,
+			RealtimeDisabledAgencies:  ParseAPIKeys(realtimeDisabledAgenciesFlag),
+			PostImportProcessors:      ParseAPIKeys(postImportProcessorsFlag),
+			SlowQueryThresholdMs:      slowQueryMs,
+			ReloadGuardMaxDropPercent: gtfsCfg.ReloadGuardMaxDropPercent,
+			FeedExpiryWarningDays:     gtfsCfg.FeedExpiryWarningDays,
+			TransferRadiusMeters:      gtfsCfg.TransferRadiusMeters,
+			ArrivalHistoryDays:        gtfsCfg.ArrivalHistoryDays,
+			DatasetSnapshots:          gtfsCfg.DatasetSnapshots,
+			DeviationSampleSeconds:    deviationSampleSeconds,
+			TLSCertPath:               cfg.TLSCertPath,
+			TLSKeyPath:                cfg.TLSKeyPath,
+			MetricsEnabled:            &cfg.MetricsEnabled,
+			Interpolation:             appconf.Interpolation{Enabled: cfg.Interpolation.Enabled},
+			ShutdownTimeoutSeconds:    shutdownTimeoutSeconds,
+			LoadShedding: appconf.LoadShedding{
+				MaxInFlight: cfg.LoadShedding.MaxInFlight,
+				TargetP99Ms: loadShedTargetP99Ms,
+			},
 		}
 
 		// Run the shared validation logic
@@ -180,6 +256,7 @@ func main() {
 		logger.Error("failed to build application", "error", err)
 		os.Exit(1)
 	}
+	coreApp.ConfigPath = configFile
 
 	// Create HTTP server
 	srv, api := CreateServer(coreApp, cfg)
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/restapi"
+)
+
+// queryAPIKey authenticates the in-process request made by the query subcommand.
+const queryAPIKey = "maglev-query"
+
+// runQuery implements "maglev query": it answers what the arrivals endpoint
+// would return for a stop at a given time, against a local gtfs.db and an
+// optional GTFS-RT snapshot, without starting the server. The request goes
+// through the same routes and handlers as the server, so the output can be
+// compared directly with a production response when reproducing bugs.
+func runQuery(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dataPath := fs.String("data-path", "./gtfs.db", "Path to an imported GTFS SQLite database")
+	stopID := fs.String("stop", "", "Combined stop ID to query, e.g. 1_75403 (required)")
+	at := fs.String("time", "", "Time to evaluate, as RFC 3339 or Unix milliseconds (default: now)")
+	minutesBefore := fs.Int("minutes-before", 5, "Include arrivals this many minutes before -time")
+	minutesAfter := fs.Int("minutes-after", 35, "Include arrivals this many minutes after -time")
+	tripUpdatesPath := fs.String("trip-updates", "", "Path to a GTFS-RT trip updates snapshot (.pb)")
+	vehiclePositionsPath := fs.String("vehicle-positions", "", "Path to a GTFS-RT vehicle positions snapshot (.pb)")
+	serviceAlertsPath := fs.String("service-alerts", "", "Path to a GTFS-RT service alerts snapshot (.pb)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: maglev query -stop <id> [-time <t>] [-data-path <db>] [-trip-updates <pb>] [-vehicle-positions <pb>] [-service-alerts <pb>]")
+		_, _ = fmt.Fprintln(fs.Output(), "\nPrints the arrivals-and-departures-for-stop response the API would return.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *stopID == "" {
+		fs.Usage()
+		return errors.New("-stop is required")
+	}
+	queryTime, err := parseQueryTime(*at, time.Now())
+	if err != nil {
+		return err
+	}
+
+	manager, err := gtfs.OpenGTFSManager(ctx, gtfs.Config{GTFSDataPath: *dataPath})
+	if err != nil {
+		return err
+	}
+	defer manager.Shutdown()
+
+	snapshot := make([][]byte, 3)
+	for i, path := range []string{*tripUpdatesPath, *vehiclePositionsPath, *serviceAlertsPath} {
+		if path == "" {
+			continue
+		}
+		if snapshot[i], err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("failed to read realtime snapshot: %w", err)
+		}
+	}
+	if err := manager.LoadRealtimeSnapshot("snapshot", snapshot[0], snapshot[1], snapshot[2]); err != nil {
+		return err
+	}
+
+	directionCalculator := gtfs.NewAdvancedDirectionCalculator(manager.GtfsDB.Queries)
+	manager.DirectionCalculator = directionCalculator
+
+	api := restapi.NewRestAPI(&app.Application{
+		Config: appconf.Config{
+			ApiKeys:   []string{queryAPIKey},
+			RateLimit: -1, // unlimited
+		},
+		Logger:              slog.Default(),
+		GtfsManager:         manager,
+		DirectionCalculator: directionCalculator,
+		Clock:               clock.NewMockClock(queryTime),
+	})
+	mux := http.NewServeMux()
+	api.SetRoutes(mux)
+
+	params := url.Values{
+		"key":           {queryAPIKey},
+		"time":          {strconv.FormatInt(queryTime.UnixMilli(), 10)},
+		"minutesBefore": {strconv.Itoa(*minutesBefore)},
+		"minutesAfter":  {strconv.Itoa(*minutesAfter)},
+	}
+	target := "/api/where/arrivals-and-departures-for-stop/" + url.PathEscape(*stopID) + ".json?" + params.Encode()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequestWithContext(ctx, http.MethodGet, target, nil))
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, rec.Body.Bytes(), "", "  "); err != nil {
+		out.Reset()
+		out.Write(rec.Body.Bytes())
+	}
+	if _, err := stdout.Write(out.Bytes()); err != nil {
+		return err
+	}
+	if rec.Code != http.StatusOK {
+		return fmt.Errorf("query returned HTTP %d", rec.Code)
+	}
+	return nil
+}
+
+// parseQueryTime accepts RFC 3339 or Unix milliseconds, matching the API's own
+// time parameter. An empty value means now.
+func parseQueryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return now, nil
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -time %q: use RFC 3339 (2024-01-02T08:30:00-08:00) or Unix milliseconds", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/gtfs"
+)
+
+// importTestDB imports the RABA fixture into a file-backed database, since
+// the query subcommand only reads an existing database.
+func importTestDB(t *testing.T) string {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "gtfs.db")
+	manager, err := gtfs.InitGTFSManager(context.Background(), gtfs.Config{
+		GtfsURL:      filepath.Join("..", "..", "testdata", "raba.zip"),
+		GTFSDataPath: dbPath,
+	})
+	require.NoError(t, err)
+	manager.Shutdown()
+	return dbPath
+}
+
+func TestRunQuery_ArrivalsForStop(t *testing.T) {
+	dbPath := importTestDB(t)
+
+	var stdout, stderr bytes.Buffer
+	err := runQuery(context.Background(), []string{
+		"-data-path", dbPath,
+		"-stop", "25_4062",
+		"-time", "2025-06-13T11:00:00-07:00",
+		"-minutes-before", "60",
+		"-minutes-after", "240",
+		"-trip-updates", filepath.Join("..", "..", "testdata", "raba-trip-updates.pb"),
+		"-vehicle-positions", filepath.Join("..", "..", "testdata", "raba-vehicle-positions.pb"),
+	}, &stdout, &stderr)
+	require.NoError(t, err, stderr.String())
+
+	var response struct {
+		Code        int   `json:"code"`
+		CurrentTime int64 `json:"currentTime"`
+		Data        struct {
+			Entry struct {
+				StopID                string           `json:"stopId"`
+				ArrivalsAndDepartures []map[string]any `json:"arrivalsAndDepartures"`
+			} `json:"entry"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &response))
+	assert.Equal(t, 200, response.Code)
+	assert.Equal(t, time.Date(2025, 6, 13, 18, 0, 0, 0, time.UTC).UnixMilli(), response.CurrentTime,
+		"the API clock must be pinned to -time")
+	assert.Equal(t, "25_4062", response.Data.Entry.StopID)
+	assert.NotEmpty(t, response.Data.Entry.ArrivalsAndDepartures)
+}
+
+func TestRunQuery_Errors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	err := runQuery(context.Background(), []string{"-data-path", "unused.db"}, &stdout, &stderr)
+	assert.EqualError(t, err, "-stop is required")
+
+	err = runQuery(context.Background(), []string{"-stop", "25_4062", "-time", "tomorrow"}, &stdout, &stderr)
+	assert.ErrorContains(t, err, "invalid -time")
+
+	err = runQuery(context.Background(), []string{"-h"}, &stdout, &stderr)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+
+	err = runQuery(context.Background(), []string{"-data-path", importTestDB(t), "-stop", "25_missing"}, &stdout, &stderr)
+	assert.ErrorContains(t, err, "query returned HTTP 404")
+}
+
+func TestParseQueryTime(t *testing.T) {
+	now := time.Date(2025, 6, 13, 11, 0, 0, 0, time.UTC)
+
+	got, err := parseQueryTime("", now)
+	require.NoError(t, err)
+	assert.Equal(t, now, got)
+
+	got, err = parseQueryTime("1749837600000", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1749837600000), got.UnixMilli())
+
+	got, err = parseQueryTime("2025-06-13T11:00:00-07:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 13, 18, 0, 0, 0, time.UTC), got.UTC())
+}
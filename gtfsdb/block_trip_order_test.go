@@ -0,0 +1,48 @@
+package gtfsdb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBlockTripOrder_SequencesEveryBlockTrip(t *testing.T) {
+	client := newTestClientWithRABA(t)
+	ctx := context.Background()
+
+	var blockTrips, ordered int
+	require.NoError(t, client.DB.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM trips WHERE block_id IS NOT NULL AND block_id != ''").Scan(&blockTrips))
+	require.NoError(t, client.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM block_trip_order").Scan(&ordered))
+	require.Greater(t, blockTrips, 0)
+	assert.Equal(t, blockTrips, ordered)
+
+	// Sequences are dense from zero and follow start times within each block.
+	var gaps int
+	require.NoError(t, client.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT block_id, MIN(block_sequence) AS lo, MAX(block_sequence) AS hi, COUNT(*) AS n
+			FROM block_trip_order GROUP BY block_id
+		) WHERE lo != 0 OR hi != n - 1`).Scan(&gaps))
+	assert.Zero(t, gaps)
+
+	var outOfOrder int
+	require.NoError(t, client.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM block_trip_order a
+		JOIN block_trip_order b ON b.block_id = a.block_id AND b.block_sequence = a.block_sequence + 1
+		WHERE b.start_time < a.start_time`).Scan(&outOfOrder))
+	assert.Zero(t, outOfOrder)
+
+	// A reimport clears the table before rebuilding it.
+	rabaBytes, err := os.ReadFile("../testdata/raba.zip")
+	require.NoError(t, err)
+	parsed, err := ParseGtfsData(rabaBytes, "test-raba")
+	require.NoError(t, err)
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+	require.NoError(t, client.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM block_trip_order").Scan(&ordered))
+	assert.Equal(t, blockTrips, ordered)
+}
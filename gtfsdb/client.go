@@ -13,6 +13,7 @@ type Client struct {
 	DB            *sql.DB
 	Queries       *Queries
 	importRuntime time.Duration
+	pins          pinTracker
 }
 
 // NewClient creates a new Client with the provided configuration
@@ -23,11 +24,15 @@ func NewClient(config Config) (*Client, error) {
 	}
 	slog.Default().Debug("successfully created DB")
 
-	// Wrap DB for query interception (optional metrics).
+	// Wrap DB for query interception (optional metrics and slow-query logging).
 	var dbtx DBTX = db
-	if config.QueryMetricsRecorder != nil {
-		wrapper := newMetricsWrapper(db)
+	if config.DBPath != ":memory:" {
+		dbtx = snapshotDB{db: db}
+	}
+	if config.QueryMetricsRecorder != nil || config.SlowQueryThreshold > 0 {
+		wrapper := newMetricsWrapper(dbtx)
 		wrapper.queryMetrics = config.QueryMetricsRecorder
+		wrapper.slowQueryThreshold = config.SlowQueryThreshold
 		dbtx = wrapper
 	}
 	queries := New(dbtx)
@@ -0,0 +1,423 @@
+package gtfsdb
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"maglev.onebusaway.org/internal/logging"
+)
+
+// CombineGtfsData combines separately parsed static feeds into one dataset so
+// that several feeds, typically one per agency, can be served from a single
+// database. Entities are matched by ID:
+//
+//   - An agency defined by more than one feed keeps its first definition.
+//   - Routes, stops and trips must be unique across feeds, since realtime
+//     feeds refer to them by ID; a collision is an error.
+//   - Service, shape and block IDs are internal to a feed, so colliding IDs in
+//     later feeds are renamed with a "<feed index>_" prefix.
+//   - Shapes with the same geometry, to within about a metre, are kept once
+//     and their trips point at the retained shape.
+//
+// Feeds are modified in place. The combined hash changes whenever any feed
+// changes, so an unchanged set of feeds is not re-imported. The combined data
+// carries MergeStats describing the merge, including how long each phase took
+// and how large the heap grew, so pathological inputs can be spotted.
+func CombineGtfsData(feeds []*GtfsData) (*GtfsData, error) {
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("no GTFS feeds to combine")
+	}
+	if len(feeds) == 1 {
+		return feeds[0], nil
+	}
+
+	combined := &GtfsData{Static: &gtfs.Static{}}
+	agencyIDs := make(map[string]struct{})
+	routeOwners := make(map[string]string)
+	stopOwners := make(map[string]string)
+	tripOwners := make(map[string]string)
+	serviceIDs := make(map[string]struct{})
+	shapeIDs := make(map[string]struct{})
+	shapesByGeometry := make(map[string]*gtfs.Shape)
+	blockIDs := make(map[string]struct{})
+	hashes := sha256.New()
+	sources := make([]string, 0, len(feeds))
+	stats := &MergeStats{Renamed: make(map[string]int), Phases: make(map[string]time.Duration)}
+	timer := mergeTimer{stats: stats, last: time.Now()}
+
+	for i, feed := range feeds {
+		static := feed.Static
+		sources = append(sources, feed.Source)
+		hashes.Write([]byte(feed.Hash))
+		stats.Feeds = append(stats.Feeds, FeedCounts{
+			Source:   feed.Source,
+			Agencies: len(static.Agencies),
+			Routes:   len(static.Routes),
+			Stops:    len(static.Stops),
+			Trips:    len(static.Trips),
+		})
+
+		for _, agency := range static.Agencies {
+			if _, ok := agencyIDs[agency.Id]; ok {
+				stats.DuplicateAgencies++
+				continue
+			}
+			agencyIDs[agency.Id] = struct{}{}
+			combined.Static.Agencies = append(combined.Static.Agencies, agency)
+		}
+		for _, route := range static.Routes {
+			if err := claimID(routeOwners, "route", route.Id, feed.Source); err != nil {
+				return nil, err
+			}
+		}
+		for _, stop := range static.Stops {
+			if err := claimID(stopOwners, "stop", stop.Id, feed.Source); err != nil {
+				return nil, err
+			}
+		}
+		for _, trip := range static.Trips {
+			if err := claimID(tripOwners, "trip", trip.ID, feed.Source); err != nil {
+				return nil, err
+			}
+		}
+		timer.mark("claim_ids")
+
+		// Trips and flex rows point at these entities, so renaming them in place
+		// updates every reference within the feed.
+		renamedServices := make(map[string]string)
+		for j := range static.Services {
+			service := &static.Services[j]
+			if newID, ok := uniqueID(serviceIDs, service.Id, i); ok {
+				renamedServices[service.Id] = newID
+				service.Id = newID
+				stats.Renamed["service"]++
+			}
+			serviceIDs[service.Id] = struct{}{}
+		}
+		// Trips point into static.Shapes, so duplicates stay in place until
+		// their trips have been moved to the retained shape.
+		duplicateShapes := make(map[*gtfs.Shape]*gtfs.Shape)
+		keptShapes := make([]gtfs.Shape, 0, len(static.Shapes))
+		for j := range static.Shapes {
+			shape := &static.Shapes[j]
+			key := shapeGeometryKey(shape)
+			if retained, ok := shapesByGeometry[key]; ok && key != "" {
+				duplicateShapes[shape] = retained
+				stats.DuplicateShapes++
+				continue
+			}
+			if newID, ok := uniqueID(shapeIDs, shape.ID, i); ok {
+				shape.ID = newID
+				stats.Renamed["shape"]++
+			}
+			shapeIDs[shape.ID] = struct{}{}
+			if key != "" {
+				shapesByGeometry[key] = shape
+			}
+			keptShapes = append(keptShapes, *shape)
+		}
+		if len(duplicateShapes) > 0 {
+			for j := range static.Trips {
+				if retained, ok := duplicateShapes[static.Trips[j].Shape]; ok {
+					static.Trips[j].Shape = retained
+				}
+			}
+		}
+		feedBlocks := make(map[string]string)
+		for j := range static.Trips {
+			trip := &static.Trips[j]
+			if trip.BlockID == "" {
+				continue
+			}
+			if newID, seen := feedBlocks[trip.BlockID]; seen {
+				trip.BlockID = newID
+				continue
+			}
+			original := trip.BlockID
+			if newID, ok := uniqueID(blockIDs, trip.BlockID, i); ok {
+				trip.BlockID = newID
+				stats.Renamed["block"]++
+			}
+			feedBlocks[original] = trip.BlockID
+		}
+		for _, blockID := range feedBlocks {
+			blockIDs[blockID] = struct{}{}
+		}
+		timer.mark("rename_ids")
+
+		combined.Static.Routes = append(combined.Static.Routes, static.Routes...)
+		combined.Static.Stops = append(combined.Static.Stops, static.Stops...)
+		combined.Static.Transfers = append(combined.Static.Transfers, static.Transfers...)
+		combined.Static.Services = append(combined.Static.Services, static.Services...)
+		combined.Static.Trips = append(combined.Static.Trips, static.Trips...)
+		combined.Static.Shapes = append(combined.Static.Shapes, keptShapes...)
+		combined.Static.Warnings = append(combined.Static.Warnings, static.Warnings...)
+		timer.mark("append")
+
+		if feed.Flex != nil {
+			if combined.Flex == nil {
+				combined.Flex = &FlexData{}
+			}
+			if err := combineFlexData(combined.Flex, feed.Flex, renamedServices, feed.Source); err != nil {
+				return nil, err
+			}
+		}
+		if feed.Fares != nil {
+			if combined.Fares == nil {
+				combined.Fares = &FareData{}
+			}
+			if err := combineFareData(combined.Fares, feed.Fares, feed.Source); err != nil {
+				return nil, err
+			}
+		}
+		timer.mark("extensions")
+	}
+
+	combined.Hash = hex.EncodeToString(hashes.Sum(nil))
+	combined.Source = strings.Join(sources, ",")
+	combined.Merge = stats
+
+	attrs := []slog.Attr{
+		slog.Int("feeds", len(feeds)),
+		slog.Int("agencies", len(combined.Static.Agencies)),
+		slog.Int("duplicate_agencies", stats.DuplicateAgencies),
+		slog.Int("duplicate_shapes", stats.DuplicateShapes),
+		slog.Int("renamed_ids", stats.RenamedTotal()),
+		slog.Duration("duration", stats.Duration()),
+		slog.Uint64("peak_heap_bytes", stats.PeakHeapBytes),
+	}
+	for _, phase := range MergePhases {
+		attrs = append(attrs, slog.Duration(phase+"_duration", stats.Phases[phase]))
+	}
+	for entity, perSecond := range stats.Throughput() {
+		attrs = append(attrs, slog.Float64(entity+"_per_second", perSecond))
+	}
+	logging.LogOperation(slog.Default().With(slog.String("component", "gtfs_importer")), "gtfs_feeds_combined", attrs...)
+	return combined, nil
+}
+
+// MergePhases names the phases of CombineGtfsData in the order they run for
+// each feed: checking route, stop and trip IDs, renaming colliding internal
+// IDs, appending the feed's entities and combining its Flex and fare rows.
+var MergePhases = []string{"claim_ids", "rename_ids", "append", "extensions"}
+
+// mergeTimer attributes the time since its last mark to a phase and samples
+// the heap at each mark. runtime.ReadMemStats briefly stops the world, which
+// is negligible at a few samples per feed.
+type mergeTimer struct {
+	stats *MergeStats
+	last  time.Time
+}
+
+func (t *mergeTimer) mark(phase string) {
+	now := time.Now()
+	t.stats.Phases[phase] += now.Sub(t.last)
+	t.last = now
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	t.stats.PeakHeapBytes = max(t.stats.PeakHeapBytes, mem.HeapAlloc)
+}
+
+// MergeStats describes how CombineGtfsData merged a set of feeds.
+type MergeStats struct {
+	// DuplicateAgencies counts agency definitions dropped because an earlier
+	// feed defined the same agency.
+	DuplicateAgencies int
+	// DuplicateShapes counts shapes dropped because a shape with the same
+	// geometry was already kept.
+	DuplicateShapes int
+	// Renamed counts the feed-internal IDs renamed to avoid a collision, by
+	// kind: "service", "shape" or "block".
+	Renamed map[string]int
+	// Feeds holds the entity counts of each feed, in the order given.
+	Feeds []FeedCounts
+	// Phases holds the time spent in each of MergePhases, summed over feeds.
+	Phases map[string]time.Duration
+	// PeakHeapBytes is the largest Go heap size sampled between phases. It
+	// includes the parsed feeds, which dominate it for large inputs.
+	PeakHeapBytes uint64
+}
+
+// FeedCounts holds the entity counts of one feed before merging.
+type FeedCounts struct {
+	Source   string
+	Agencies int
+	Routes   int
+	Stops    int
+	Trips    int
+}
+
+// RenamedTotal returns the number of IDs renamed across all kinds.
+func (s *MergeStats) RenamedTotal() int {
+	total := 0
+	for _, n := range s.Renamed {
+		total += n
+	}
+	return total
+}
+
+// Duration returns the total time spent merging.
+func (s *MergeStats) Duration() time.Duration {
+	var total time.Duration
+	for _, d := range s.Phases {
+		total += d
+	}
+	return total
+}
+
+// Throughput returns the entities merged per second, by entity. It is empty
+// when no time was recorded.
+func (s *MergeStats) Throughput() map[string]float64 {
+	seconds := s.Duration().Seconds()
+	if seconds <= 0 {
+		return map[string]float64{}
+	}
+	var totals FeedCounts
+	for _, feed := range s.Feeds {
+		totals.Agencies += feed.Agencies
+		totals.Routes += feed.Routes
+		totals.Stops += feed.Stops
+		totals.Trips += feed.Trips
+	}
+	return map[string]float64{
+		"agencies": float64(totals.Agencies) / seconds,
+		"routes":   float64(totals.Routes) / seconds,
+		"stops":    float64(totals.Stops) / seconds,
+		"trips":    float64(totals.Trips) / seconds,
+	}
+}
+
+// combineFlexData appends a feed's GTFS-Flex rows. Booking rule and location
+// group IDs must be unique across feeds.
+func combineFlexData(dst, src *FlexData, renamedServices map[string]string, source string) error {
+	ruleIDs := make(map[string]struct{}, len(dst.BookingRules))
+	for _, rule := range dst.BookingRules {
+		ruleIDs[rule.ID] = struct{}{}
+	}
+	for _, rule := range src.BookingRules {
+		if _, ok := ruleIDs[rule.ID]; ok {
+			return fmt.Errorf("booking rule %q is defined by more than one feed (including %s)", rule.ID, source)
+		}
+	}
+	groupIDs := make(map[string]struct{}, len(dst.LocationGroups))
+	for _, group := range dst.LocationGroups {
+		groupIDs[group.ID] = struct{}{}
+	}
+	for _, group := range src.LocationGroups {
+		if _, ok := groupIDs[group.ID]; ok {
+			return fmt.Errorf("location group %q is defined by more than one feed (including %s)", group.ID, source)
+		}
+	}
+
+	for _, rule := range src.BookingRules {
+		if newID, ok := renamedServices[rule.PriorNoticeServiceID.String]; rule.PriorNoticeServiceID.Valid && ok {
+			rule.PriorNoticeServiceID.String = newID
+		}
+		dst.BookingRules = append(dst.BookingRules, rule)
+	}
+	dst.LocationGroups = append(dst.LocationGroups, src.LocationGroups...)
+	dst.LocationGroupStops = append(dst.LocationGroupStops, src.LocationGroupStops...)
+	dst.StopTimes = append(dst.StopTimes, src.StopTimes...)
+	return nil
+}
+
+// combineFareData appends a feed's fare rows. Fare, fare product and fare
+// media IDs must be unique across feeds.
+func combineFareData(dst, src *FareData, source string) error {
+	fareIDs := make(map[string]struct{}, len(dst.Attributes))
+	for _, fare := range dst.Attributes {
+		fareIDs[fare.ID] = struct{}{}
+	}
+	for _, fare := range src.Attributes {
+		if _, ok := fareIDs[fare.ID]; ok {
+			return fmt.Errorf("fare %q is defined by more than one feed (including %s)", fare.ID, source)
+		}
+	}
+	productIDs := make(map[string]struct{}, len(dst.Products))
+	for _, product := range dst.Products {
+		productIDs[product.FareProductID] = struct{}{}
+	}
+	for _, product := range src.Products {
+		if _, ok := productIDs[product.FareProductID]; ok {
+			return fmt.Errorf("fare product %q is defined by more than one feed (including %s)", product.FareProductID, source)
+		}
+	}
+	mediaIDs := make(map[string]struct{}, len(dst.Media))
+	for _, media := range dst.Media {
+		mediaIDs[media.ID] = struct{}{}
+	}
+	for _, media := range src.Media {
+		if _, ok := mediaIDs[media.ID]; ok {
+			return fmt.Errorf("fare media %q is defined by more than one feed (including %s)", media.ID, source)
+		}
+	}
+
+	dst.Attributes = append(dst.Attributes, src.Attributes...)
+	dst.Rules = append(dst.Rules, src.Rules...)
+	dst.Media = append(dst.Media, src.Media...)
+	dst.Products = append(dst.Products, src.Products...)
+	dst.LegRules = append(dst.LegRules, src.LegRules...)
+	dst.RouteNetworks = append(dst.RouteNetworks, src.RouteNetworks...)
+	return nil
+}
+
+// shapeCoordinateScale rounds shape coordinates to five decimal places, about
+// a metre, when comparing geometries.
+const shapeCoordinateScale = 1e5
+
+// shapeGeometryKey hashes a shape's rounded points and distances, so shapes
+// that differ only by ID or by sub-metre noise share a key. Distances are
+// part of the key because stop times measure shape_dist_traveled along them.
+// Shapes without points have no key and are never merged.
+func shapeGeometryKey(shape *gtfs.Shape) string {
+	if len(shape.Points) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	buf := make([]byte, 0, 25)
+	for _, point := range shape.Points {
+		buf = buf[:0]
+		buf = binary.AppendVarint(buf, int64(math.Round(point.Latitude*shapeCoordinateScale)))
+		buf = binary.AppendVarint(buf, int64(math.Round(point.Longitude*shapeCoordinateScale)))
+		if point.Distance != nil {
+			buf = append(buf, 1)
+			buf = binary.AppendVarint(buf, int64(math.Round(*point.Distance*100)))
+		} else {
+			buf = append(buf, 0)
+		}
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// claimID records that source defines an entity, failing when an earlier feed
+// already defined one with the same ID.
+func claimID(owners map[string]string, kind, id, source string) error {
+	if owner, ok := owners[id]; ok {
+		return fmt.Errorf("%s %q is defined by both %s and %s; combined feeds must use distinct %s IDs", kind, id, owner, source, kind)
+	}
+	owners[id] = source
+	return nil
+}
+
+// uniqueID returns a new ID for id when an earlier feed already uses it.
+func uniqueID(used map[string]struct{}, id string, feedIndex int) (string, bool) {
+	if _, ok := used[id]; !ok {
+		return "", false
+	}
+	for n := feedIndex; ; n++ {
+		candidate := fmt.Sprintf("%d_%s", n, id)
+		if _, ok := used[candidate]; !ok {
+			return candidate, true
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package gtfsdb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCombineTestFeed builds a one-route feed whose trip uses service "weekday",
+// shape "s1" and block "b1", so two such feeds collide on every internal ID.
+func newCombineTestFeed(source, agencyID, routeID, stopID, tripID string) *GtfsData {
+	static := &gtfs.Static{
+		Agencies: []gtfs.Agency{{Id: agencyID, Name: agencyID}},
+		Stops:    []gtfs.Stop{{Id: stopID}},
+		Services: []gtfs.Service{{Id: "weekday", Monday: true}},
+		Shapes:   []gtfs.Shape{{ID: "s1"}},
+	}
+	static.Routes = []gtfs.Route{{Id: routeID, Agency: &static.Agencies[0]}}
+	static.Trips = []gtfs.ScheduledTrip{{
+		ID:      tripID,
+		Route:   &static.Routes[0],
+		Service: &static.Services[0],
+		Shape:   &static.Shapes[0],
+		BlockID: "b1",
+	}}
+	return &GtfsData{Static: static, Hash: source + "-hash", Source: source}
+}
+
+func TestCombineGtfsData_SingleFeedUnchanged(t *testing.T) {
+	feed := newCombineTestFeed("a.zip", "a1", "r1", "st1", "t1")
+
+	combined, err := CombineGtfsData([]*GtfsData{feed})
+	require.NoError(t, err)
+	assert.Same(t, feed, combined)
+
+	_, err = CombineGtfsData(nil)
+	assert.Error(t, err)
+}
+
+func TestCombineGtfsData_RenamesFeedInternalIDs(t *testing.T) {
+	first := newCombineTestFeed("a.zip", "shared", "r1", "st1", "t1")
+	second := newCombineTestFeed("b.zip", "shared", "r2", "st2", "t2")
+	second.Static.Agencies = append(second.Static.Agencies, gtfs.Agency{Id: "a2"})
+	second.Flex = &FlexData{BookingRules: []CreateBookingRuleParams{{
+		ID:                   "rule1",
+		PriorNoticeServiceID: sql.NullString{String: "weekday", Valid: true},
+	}}}
+
+	combined, err := CombineGtfsData([]*GtfsData{first, second})
+	require.NoError(t, err)
+
+	static := combined.Static
+	require.Len(t, static.Agencies, 2, "an agency shared by both feeds is kept once")
+	assert.Equal(t, "shared", static.Agencies[0].Id)
+	assert.Equal(t, "a2", static.Agencies[1].Id)
+	assert.Len(t, static.Routes, 2)
+	assert.Len(t, static.Stops, 2)
+	require.Len(t, static.Trips, 2)
+
+	assert.Equal(t, []string{"weekday", "1_weekday"}, []string{static.Services[0].Id, static.Services[1].Id})
+	assert.Equal(t, []string{"s1", "1_s1"}, []string{static.Shapes[0].ID, static.Shapes[1].ID})
+	firstTrip, secondTrip := static.Trips[0], static.Trips[1]
+	assert.Equal(t, "weekday", firstTrip.Service.Id)
+	assert.Equal(t, "b1", firstTrip.BlockID)
+	assert.Equal(t, "1_weekday", secondTrip.Service.Id, "trips see their feed's renamed service")
+	assert.Equal(t, "1_s1", secondTrip.Shape.ID)
+	assert.Equal(t, "1_b1", secondTrip.BlockID)
+
+	require.NotNil(t, combined.Flex)
+	assert.Equal(t, "1_weekday", combined.Flex.BookingRules[0].PriorNoticeServiceID.String)
+
+	require.NotNil(t, combined.Merge)
+	assert.Equal(t, 1, combined.Merge.DuplicateAgencies)
+	assert.Equal(t, map[string]int{"service": 1, "shape": 1, "block": 1}, combined.Merge.Renamed)
+	assert.Equal(t, 3, combined.Merge.RenamedTotal())
+	assert.Equal(t, []FeedCounts{
+		{Source: "a.zip", Agencies: 1, Routes: 1, Stops: 1, Trips: 1},
+		{Source: "b.zip", Agencies: 2, Routes: 1, Stops: 1, Trips: 1},
+	}, combined.Merge.Feeds)
+	assert.Len(t, combined.Merge.Phases, len(MergePhases))
+	assert.NotZero(t, combined.Merge.PeakHeapBytes)
+
+	assert.Equal(t, "a.zip,b.zip", combined.Source)
+	assert.NotEqual(t, first.Hash, combined.Hash)
+}
+
+func TestCombineGtfsData_DedupesShapesByGeometry(t *testing.T) {
+	line := func(offset float64) []gtfs.ShapePoint {
+		return []gtfs.ShapePoint{
+			{Latitude: 47.6062 + offset, Longitude: -122.3321},
+			{Latitude: 47.6097 + offset, Longitude: -122.3331},
+		}
+	}
+	first := newCombineTestFeed("a.zip", "a1", "r1", "st1", "t1")
+	first.Static.Shapes[0].Points = line(0)
+	second := newCombineTestFeed("b.zip", "a2", "r2", "st2", "t2")
+	second.Static.Shapes[0].ID = "other"
+	second.Static.Shapes[0].Points = line(0.000001)
+	third := newCombineTestFeed("c.zip", "a3", "r3", "st3", "t3")
+	third.Static.Shapes[0].Points = line(0.001)
+
+	combined, err := CombineGtfsData([]*GtfsData{first, second, third})
+	require.NoError(t, err)
+
+	static := combined.Static
+	require.Len(t, static.Shapes, 2, "a sub-metre difference is treated as the same geometry")
+	assert.Equal(t, []string{"s1", "2_s1"}, []string{static.Shapes[0].ID, static.Shapes[1].ID})
+	assert.Equal(t, "s1", static.Trips[1].Shape.ID, "trips move to the retained shape")
+	assert.Equal(t, "2_s1", static.Trips[2].Shape.ID)
+	assert.Equal(t, 1, combined.Merge.DuplicateShapes)
+}
+
+func TestCombineGtfsData_PreservesStationHierarchy(t *testing.T) {
+	withStation := func(feed *GtfsData, station string, platforms ...string) {
+		feed.Static.Stops = []gtfs.Stop{{Id: station, Type: gtfs.StopType_Station}}
+		for _, platform := range platforms {
+			feed.Static.Stops = append(feed.Static.Stops, gtfs.Stop{Id: platform, Parent: &feed.Static.Stops[0]})
+		}
+	}
+	first := newCombineTestFeed("a.zip", "a1", "r1", "st1", "t1")
+	withStation(first, "central", "central_1", "central_2")
+	second := newCombineTestFeed("b.zip", "a2", "r2", "st2", "t2")
+	withStation(second, "harbor", "harbor_1")
+
+	combined, err := CombineGtfsData([]*GtfsData{first, second})
+	require.NoError(t, err)
+
+	parents := make(map[string]string)
+	for _, stop := range combined.Static.Stops {
+		if stop.Parent != nil {
+			parents[stop.Id] = stop.Parent.Id
+		}
+	}
+	assert.Equal(t, map[string]string{"central_1": "central", "central_2": "central", "harbor_1": "harbor"}, parents)
+
+	// Stop IDs are never renamed, so a station defined by both feeds is a
+	// collision rather than a silent re-parenting of the second feed's platforms.
+	other := newCombineTestFeed("c.zip", "a3", "r3", "st3", "t3")
+	withStation(other, "central", "central_1")
+	third := newCombineTestFeed("d.zip", "a4", "r4", "st4", "t4")
+	withStation(third, "central", "central_3")
+	_, err = CombineGtfsData([]*GtfsData{other, third})
+	assert.ErrorContains(t, err, `stop "central"`)
+}
+
+func TestShapeGeometryKey_IncludesDistances(t *testing.T) {
+	one, two := 1.0, 2.0
+	withDistance := func(d *float64) *gtfs.Shape {
+		return &gtfs.Shape{Points: []gtfs.ShapePoint{{Latitude: 1, Longitude: 2, Distance: d}}}
+	}
+	assert.Equal(t, shapeGeometryKey(withDistance(&one)), shapeGeometryKey(withDistance(&one)))
+	assert.NotEqual(t, shapeGeometryKey(withDistance(&one)), shapeGeometryKey(withDistance(&two)))
+	assert.NotEqual(t, shapeGeometryKey(withDistance(nil)), shapeGeometryKey(withDistance(&one)))
+	assert.Empty(t, shapeGeometryKey(&gtfs.Shape{ID: "empty"}))
+}
+
+func TestMergeStats_Throughput(t *testing.T) {
+	stats := &MergeStats{
+		Feeds: []FeedCounts{
+			{Agencies: 1, Routes: 10, Stops: 100, Trips: 1000},
+			{Agencies: 1, Routes: 10, Stops: 100, Trips: 1000},
+		},
+		Phases: map[string]time.Duration{"claim_ids": 1500 * time.Millisecond, "append": 500 * time.Millisecond},
+	}
+	assert.Equal(t, 2*time.Second, stats.Duration())
+	assert.Equal(t, map[string]float64{"agencies": 1, "routes": 10, "stops": 100, "trips": 1000}, stats.Throughput())
+
+	assert.Empty(t, (&MergeStats{Feeds: stats.Feeds}).Throughput(), "no time recorded")
+}
+
+func TestCombineGtfsData_RejectsDuplicateFares(t *testing.T) {
+	first := newCombineTestFeed("a.zip", "a1", "r1", "st1", "t1")
+	first.Fares = &FareData{Products: []CreateFareProductParams{{FareProductID: "single_ride"}}}
+	second := newCombineTestFeed("b.zip", "a2", "r2", "st2", "t2")
+	second.Fares = &FareData{Products: []CreateFareProductParams{{FareProductID: "single_ride"}}}
+
+	_, err := CombineGtfsData([]*GtfsData{first, second})
+	assert.ErrorContains(t, err, `fare product "single_ride" is defined by more than one feed`)
+
+	second.Fares.Products[0].FareProductID = "day_pass"
+	combined, err := CombineGtfsData([]*GtfsData{first, second})
+	require.NoError(t, err)
+	assert.Len(t, combined.Fares.Products, 2)
+}
+
+func TestCombineGtfsData_HashTracksEveryFeed(t *testing.T) {
+	combine := func(secondHash string) string {
+		second := newCombineTestFeed("b.zip", "a2", "r2", "st2", "t2")
+		second.Hash = secondHash
+		combined, err := CombineGtfsData([]*GtfsData{newCombineTestFeed("a.zip", "a1", "r1", "st1", "t1"), second})
+		require.NoError(t, err)
+		return combined.Hash
+	}
+
+	assert.Equal(t, combine("v1"), combine("v1"))
+	assert.NotEqual(t, combine("v1"), combine("v2"))
+}
+
+func TestCombineGtfsData_RejectsSharedPublicIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		second *GtfsData
+		want   string
+	}{
+		{"route", newCombineTestFeed("b.zip", "a2", "r1", "st2", "t2"), `route "r1" is defined by both a.zip and b.zip`},
+		{"stop", newCombineTestFeed("b.zip", "a2", "r2", "st1", "t2"), `stop "st1" is defined by both a.zip and b.zip`},
+		{"trip", newCombineTestFeed("b.zip", "a2", "r2", "st2", "t1"), `trip "t1" is defined by both a.zip and b.zip`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := newCombineTestFeed("a.zip", "a1", "r1", "st1", "t1")
+			_, err := CombineGtfsData([]*GtfsData{first, tt.second})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
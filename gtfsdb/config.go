@@ -2,6 +2,7 @@ package gtfsdb
 
 import (
 	"fmt"
+	"time"
 
 	"maglev.onebusaway.org/internal/appconf"
 )
@@ -19,12 +20,21 @@ type Config struct {
 	Env    appconf.Environment // Environment name: development, test, production.
 	// Optional recorder for DB query metrics.
 	QueryMetricsRecorder DBQueryMetricsRecorder
+	// Queries taking at least this long are logged with their parameters.
+	// Zero disables slow-query logging.
+	SlowQueryThreshold time.Duration
+	// SQLCipher key for encrypting the database at rest. Empty leaves the
+	// database unencrypted.
+	EncryptionKey string
+	// Feeds without transfers.txt get transfers generated between stops at
+	// most this many meters apart. Zero disables generation.
+	TransferRadiusMeters float64
 }
 
 // DBQueryMetricsRecorder is a minimal abstraction used to emit per-query metrics
 // without coupling gtfsdb to a specific metrics implementation.
 type DBQueryMetricsRecorder interface {
-	RecordDBQuery(queryName, op string, err error)
+	RecordDBQuery(queryName, op string, duration time.Duration, err error)
 }
 
 func NewConfig(dbPath string, env appconf.Environment) Config {
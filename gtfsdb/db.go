@@ -24,12 +24,27 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.activateDeveloperAPIKeyStmt, err = db.PrepareContext(ctx, activateDeveloperAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query ActivateDeveloperAPIKey: %w", err)
+	}
+	if q.addAPIKeyUsageStmt, err = db.PrepareContext(ctx, addAPIKeyUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query AddAPIKeyUsage: %w", err)
+	}
+	if q.buildBlockTripOrderStmt, err = db.PrepareContext(ctx, buildBlockTripOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query BuildBlockTripOrder: %w", err)
+	}
+	if q.buildRouteServiceSpansStmt, err = db.PrepareContext(ctx, buildRouteServiceSpans); err != nil {
+		return nil, fmt.Errorf("error preparing query BuildRouteServiceSpans: %w", err)
+	}
 	if q.bulkUpdateTripTimeBoundsStmt, err = db.PrepareContext(ctx, bulkUpdateTripTimeBounds); err != nil {
 		return nil, fmt.Errorf("error preparing query BulkUpdateTripTimeBounds: %w", err)
 	}
 	if q.clearAgenciesStmt, err = db.PrepareContext(ctx, clearAgencies); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearAgencies: %w", err)
 	}
+	if q.clearAreasStmt, err = db.PrepareContext(ctx, clearAreas); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearAreas: %w", err)
+	}
 	if q.clearBlockLayoversStmt, err = db.PrepareContext(ctx, clearBlockLayovers); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearBlockLayovers: %w", err)
 	}
@@ -39,33 +54,81 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.clearBlockTripIndicesStmt, err = db.PrepareContext(ctx, clearBlockTripIndices); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearBlockTripIndices: %w", err)
 	}
+	if q.clearBlockTripOrderStmt, err = db.PrepareContext(ctx, clearBlockTripOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearBlockTripOrder: %w", err)
+	}
+	if q.clearBookingRulesStmt, err = db.PrepareContext(ctx, clearBookingRules); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearBookingRules: %w", err)
+	}
 	if q.clearCalendarStmt, err = db.PrepareContext(ctx, clearCalendar); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearCalendar: %w", err)
 	}
 	if q.clearCalendarDatesStmt, err = db.PrepareContext(ctx, clearCalendarDates); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearCalendarDates: %w", err)
 	}
+	if q.clearFareAttributesStmt, err = db.PrepareContext(ctx, clearFareAttributes); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFareAttributes: %w", err)
+	}
+	if q.clearFareLegRulesStmt, err = db.PrepareContext(ctx, clearFareLegRules); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFareLegRules: %w", err)
+	}
+	if q.clearFareMediaStmt, err = db.PrepareContext(ctx, clearFareMedia); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFareMedia: %w", err)
+	}
+	if q.clearFareProductsStmt, err = db.PrepareContext(ctx, clearFareProducts); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFareProducts: %w", err)
+	}
+	if q.clearFareRulesStmt, err = db.PrepareContext(ctx, clearFareRules); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFareRules: %w", err)
+	}
+	if q.clearFlexStopTimesStmt, err = db.PrepareContext(ctx, clearFlexStopTimes); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearFlexStopTimes: %w", err)
+	}
 	if q.clearFrequenciesStmt, err = db.PrepareContext(ctx, clearFrequencies); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearFrequencies: %w", err)
 	}
+	if q.clearLocationGroupStopsStmt, err = db.PrepareContext(ctx, clearLocationGroupStops); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearLocationGroupStops: %w", err)
+	}
+	if q.clearLocationGroupsStmt, err = db.PrepareContext(ctx, clearLocationGroups); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearLocationGroups: %w", err)
+	}
+	if q.clearRiderCategoriesStmt, err = db.PrepareContext(ctx, clearRiderCategories); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearRiderCategories: %w", err)
+	}
+	if q.clearRouteNetworksStmt, err = db.PrepareContext(ctx, clearRouteNetworks); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearRouteNetworks: %w", err)
+	}
+	if q.clearRouteServiceSpansStmt, err = db.PrepareContext(ctx, clearRouteServiceSpans); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearRouteServiceSpans: %w", err)
+	}
 	if q.clearRoutesStmt, err = db.PrepareContext(ctx, clearRoutes); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearRoutes: %w", err)
 	}
 	if q.clearShapesStmt, err = db.PrepareContext(ctx, clearShapes); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearShapes: %w", err)
 	}
+	if q.clearStopAreasStmt, err = db.PrepareContext(ctx, clearStopAreas); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearStopAreas: %w", err)
+	}
 	if q.clearStopTimesStmt, err = db.PrepareContext(ctx, clearStopTimes); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearStopTimes: %w", err)
 	}
 	if q.clearStopsStmt, err = db.PrepareContext(ctx, clearStops); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearStops: %w", err)
 	}
+	if q.clearTransfersStmt, err = db.PrepareContext(ctx, clearTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearTransfers: %w", err)
+	}
 	if q.clearTripsStmt, err = db.PrepareContext(ctx, clearTrips); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearTrips: %w", err)
 	}
 	if q.countAgenciesStmt, err = db.PrepareContext(ctx, countAgencies); err != nil {
 		return nil, fmt.Errorf("error preparing query CountAgencies: %w", err)
 	}
+	if q.countOpenDeveloperAPIKeysByEmailStmt, err = db.PrepareContext(ctx, countOpenDeveloperAPIKeysByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query CountOpenDeveloperAPIKeysByEmail: %w", err)
+	}
 	if q.countRoutesStmt, err = db.PrepareContext(ctx, countRoutes); err != nil {
 		return nil, fmt.Errorf("error preparing query CountRoutes: %w", err)
 	}
@@ -75,9 +138,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countTripsStmt, err = db.PrepareContext(ctx, countTrips); err != nil {
 		return nil, fmt.Errorf("error preparing query CountTrips: %w", err)
 	}
+	if q.createAPIKeyStmt, err = db.PrepareContext(ctx, createAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAPIKey: %w", err)
+	}
+	if q.createAdminAuditEntryStmt, err = db.PrepareContext(ctx, createAdminAuditEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAdminAuditEntry: %w", err)
+	}
 	if q.createAgencyStmt, err = db.PrepareContext(ctx, createAgency); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAgency: %w", err)
 	}
+	if q.createAreaStmt, err = db.PrepareContext(ctx, createArea); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateArea: %w", err)
+	}
 	if q.createBlockLayoverStmt, err = db.PrepareContext(ctx, createBlockLayover); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateBlockLayover: %w", err)
 	}
@@ -87,36 +159,105 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createBlockTripIndexStmt, err = db.PrepareContext(ctx, createBlockTripIndex); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateBlockTripIndex: %w", err)
 	}
+	if q.createBookingRuleStmt, err = db.PrepareContext(ctx, createBookingRule); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBookingRule: %w", err)
+	}
 	if q.createCalendarStmt, err = db.PrepareContext(ctx, createCalendar); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateCalendar: %w", err)
 	}
 	if q.createCalendarDateStmt, err = db.PrepareContext(ctx, createCalendarDate); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateCalendarDate: %w", err)
 	}
+	if q.createDeveloperAPIKeyStmt, err = db.PrepareContext(ctx, createDeveloperAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDeveloperAPIKey: %w", err)
+	}
+	if q.createFareAttributeStmt, err = db.PrepareContext(ctx, createFareAttribute); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFareAttribute: %w", err)
+	}
+	if q.createFareLegRuleStmt, err = db.PrepareContext(ctx, createFareLegRule); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFareLegRule: %w", err)
+	}
+	if q.createFareMediaStmt, err = db.PrepareContext(ctx, createFareMedia); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFareMedia: %w", err)
+	}
+	if q.createFareProductStmt, err = db.PrepareContext(ctx, createFareProduct); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFareProduct: %w", err)
+	}
+	if q.createFareRuleStmt, err = db.PrepareContext(ctx, createFareRule); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFareRule: %w", err)
+	}
+	if q.createFlexStopTimeStmt, err = db.PrepareContext(ctx, createFlexStopTime); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFlexStopTime: %w", err)
+	}
 	if q.createFrequencyStmt, err = db.PrepareContext(ctx, createFrequency); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFrequency: %w", err)
 	}
+	if q.createLocationGroupStmt, err = db.PrepareContext(ctx, createLocationGroup); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateLocationGroup: %w", err)
+	}
+	if q.createLocationGroupStopStmt, err = db.PrepareContext(ctx, createLocationGroupStop); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateLocationGroupStop: %w", err)
+	}
 	if q.createProblemReportStopStmt, err = db.PrepareContext(ctx, createProblemReportStop); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateProblemReportStop: %w", err)
 	}
 	if q.createProblemReportTripStmt, err = db.PrepareContext(ctx, createProblemReportTrip); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateProblemReportTrip: %w", err)
 	}
+	if q.createRiderCategoryStmt, err = db.PrepareContext(ctx, createRiderCategory); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRiderCategory: %w", err)
+	}
 	if q.createRouteStmt, err = db.PrepareContext(ctx, createRoute); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateRoute: %w", err)
 	}
+	if q.createRouteNetworkStmt, err = db.PrepareContext(ctx, createRouteNetwork); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRouteNetwork: %w", err)
+	}
 	if q.createShapeStmt, err = db.PrepareContext(ctx, createShape); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateShape: %w", err)
 	}
 	if q.createStopStmt, err = db.PrepareContext(ctx, createStop); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateStop: %w", err)
 	}
+	if q.createStopAreaStmt, err = db.PrepareContext(ctx, createStopArea); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStopArea: %w", err)
+	}
 	if q.createStopTimeStmt, err = db.PrepareContext(ctx, createStopTime); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateStopTime: %w", err)
 	}
+	if q.createTransferStmt, err = db.PrepareContext(ctx, createTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransfer: %w", err)
+	}
 	if q.createTripStmt, err = db.PrepareContext(ctx, createTrip); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTrip: %w", err)
 	}
+	if q.decideDeveloperAPIKeyQuotaStmt, err = db.PrepareContext(ctx, decideDeveloperAPIKeyQuota); err != nil {
+		return nil, fmt.Errorf("error preparing query DecideDeveloperAPIKeyQuota: %w", err)
+	}
+	if q.deleteAPIKeyUsageBeforeStmt, err = db.PrepareContext(ctx, deleteAPIKeyUsageBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAPIKeyUsageBefore: %w", err)
+	}
+	if q.deleteArrivalHistoryBeforeStmt, err = db.PrepareContext(ctx, deleteArrivalHistoryBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteArrivalHistoryBefore: %w", err)
+	}
+	if q.deleteExpiredDeveloperAPIKeySignupsStmt, err = db.PrepareContext(ctx, deleteExpiredDeveloperAPIKeySignups); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredDeveloperAPIKeySignups: %w", err)
+	}
+	if q.deleteProblemReportsStopBeforeStmt, err = db.PrepareContext(ctx, deleteProblemReportsStopBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteProblemReportsStopBefore: %w", err)
+	}
+	if q.deleteProblemReportsTripBeforeStmt, err = db.PrepareContext(ctx, deleteProblemReportsTripBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteProblemReportsTripBefore: %w", err)
+	}
+	if q.getAPIKeyStmt, err = db.PrepareContext(ctx, getAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAPIKey: %w", err)
+	}
+	if q.getActiveAPIKeyByHashStmt, err = db.PrepareContext(ctx, getActiveAPIKeyByHash); err != nil {
+		return nil, fmt.Errorf("error preparing query GetActiveAPIKeyByHash: %w", err)
+	}
+	if q.getActiveDeveloperAPIKeyStmt, err = db.PrepareContext(ctx, getActiveDeveloperAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetActiveDeveloperAPIKey: %w", err)
+	}
 	if q.getActiveLayoverBlockIDsForRouteStmt, err = db.PrepareContext(ctx, getActiveLayoverBlockIDsForRoute); err != nil {
 		return nil, fmt.Errorf("error preparing query GetActiveLayoverBlockIDsForRoute: %w", err)
 	}
@@ -138,6 +279,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getActiveTripsWithNullBlockForRouteStmt, err = db.PrepareContext(ctx, getActiveTripsWithNullBlockForRoute); err != nil {
 		return nil, fmt.Errorf("error preparing query GetActiveTripsWithNullBlockForRoute: %w", err)
 	}
+	if q.getAdminAuditEntryByIdempotencyKeyStmt, err = db.PrepareContext(ctx, getAdminAuditEntryByIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAdminAuditEntryByIdempotencyKey: %w", err)
+	}
 	if q.getAgenciesByIDsStmt, err = db.PrepareContext(ctx, getAgenciesByIDs); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAgenciesByIDs: %w", err)
 	}
@@ -159,6 +303,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getAllTripsForRouteStmt, err = db.PrepareContext(ctx, getAllTripsForRoute); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllTripsForRoute: %w", err)
 	}
+	if q.getAreasForStopStmt, err = db.PrepareContext(ctx, getAreasForStop); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAreasForStop: %w", err)
+	}
 	if q.getArrivalsAndDeparturesForStopStmt, err = db.PrepareContext(ctx, getArrivalsAndDeparturesForStop); err != nil {
 		return nil, fmt.Errorf("error preparing query GetArrivalsAndDeparturesForStop: %w", err)
 	}
@@ -174,23 +321,65 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getBlockTripIndexIDsForRouteStmt, err = db.PrepareContext(ctx, getBlockTripIndexIDsForRoute); err != nil {
 		return nil, fmt.Errorf("error preparing query GetBlockTripIndexIDsForRoute: %w", err)
 	}
-	if q.getBlockTripSequenceStmt, err = db.PrepareContext(ctx, getBlockTripSequence); err != nil {
-		return nil, fmt.Errorf("error preparing query GetBlockTripSequence: %w", err)
+	if q.getBlockTripOrderStmt, err = db.PrepareContext(ctx, getBlockTripOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockTripOrder: %w", err)
+	}
+	if q.getBlockTripOrderForServicesStmt, err = db.PrepareContext(ctx, getBlockTripOrderForServices); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockTripOrderForServices: %w", err)
+	}
+	if q.getBlockTripsForAgencyStmt, err = db.PrepareContext(ctx, getBlockTripsForAgency); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBlockTripsForAgency: %w", err)
 	}
 	if q.getBlocksForBlockTripIndexIDsStmt, err = db.PrepareContext(ctx, getBlocksForBlockTripIndexIDs); err != nil {
 		return nil, fmt.Errorf("error preparing query GetBlocksForBlockTripIndexIDs: %w", err)
 	}
+	if q.getBookingRulesByIDsStmt, err = db.PrepareContext(ctx, getBookingRulesByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBookingRulesByIDs: %w", err)
+	}
+	if q.getBookingRulesForRouteStmt, err = db.PrepareContext(ctx, getBookingRulesForRoute); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBookingRulesForRoute: %w", err)
+	}
+	if q.getBookingRulesForStopStmt, err = db.PrepareContext(ctx, getBookingRulesForStop); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBookingRulesForStop: %w", err)
+	}
 	if q.getCalendarByServiceIDStmt, err = db.PrepareContext(ctx, getCalendarByServiceID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCalendarByServiceID: %w", err)
 	}
 	if q.getCalendarDateExceptionsForServiceIDStmt, err = db.PrepareContext(ctx, getCalendarDateExceptionsForServiceID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCalendarDateExceptionsForServiceID: %w", err)
 	}
+	if q.getDeveloperAPIKeyStmt, err = db.PrepareContext(ctx, getDeveloperAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDeveloperAPIKey: %w", err)
+	}
+	if q.getDeveloperAPIKeyByVerificationTokenStmt, err = db.PrepareContext(ctx, getDeveloperAPIKeyByVerificationToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDeveloperAPIKeyByVerificationToken: %w", err)
+	}
+	if q.getFareAttributesForRouteStmt, err = db.PrepareContext(ctx, getFareAttributesForRoute); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFareAttributesForRoute: %w", err)
+	}
+	if q.getFareLegRulesStmt, err = db.PrepareContext(ctx, getFareLegRules); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFareLegRules: %w", err)
+	}
+	if q.getFareLegRulesForRouteStmt, err = db.PrepareContext(ctx, getFareLegRulesForRoute); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFareLegRulesForRoute: %w", err)
+	}
+	if q.getFareMediaByIDsStmt, err = db.PrepareContext(ctx, getFareMediaByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFareMediaByIDs: %w", err)
+	}
+	if q.getFareProductsByIDsStmt, err = db.PrepareContext(ctx, getFareProductsByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFareProductsByIDs: %w", err)
+	}
+	if q.getFareRulesForRouteStmt, err = db.PrepareContext(ctx, getFareRulesForRoute); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFareRulesForRoute: %w", err)
+	}
 	if q.getFeedEndDateStmt, err = db.PrepareContext(ctx, getFeedEndDate); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFeedEndDate: %w", err)
 	}
-	if q.getFirstStopOfNextTripInBlockStmt, err = db.PrepareContext(ctx, getFirstStopOfNextTripInBlock); err != nil {
-		return nil, fmt.Errorf("error preparing query GetFirstStopOfNextTripInBlock: %w", err)
+	if q.getFlexStopTimesForStopStmt, err = db.PrepareContext(ctx, getFlexStopTimesForStop); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFlexStopTimesForStop: %w", err)
+	}
+	if q.getFlexStopTimesForTripStmt, err = db.PrepareContext(ctx, getFlexStopTimesForTrip); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFlexStopTimesForTrip: %w", err)
 	}
 	if q.getFrequenciesForTripStmt, err = db.PrepareContext(ctx, getFrequenciesForTrip); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFrequenciesForTrip: %w", err)
@@ -204,18 +393,24 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getImportMetadataStmt, err = db.PrepareContext(ctx, getImportMetadata); err != nil {
 		return nil, fmt.Errorf("error preparing query GetImportMetadata: %w", err)
 	}
-	if q.getNextAndPreviousTripsInBlockStmt, err = db.PrepareContext(ctx, getNextAndPreviousTripsInBlock); err != nil {
-		return nil, fmt.Errorf("error preparing query GetNextAndPreviousTripsInBlock: %w", err)
+	if q.getNetworksForRouteStmt, err = db.PrepareContext(ctx, getNetworksForRoute); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNetworksForRoute: %w", err)
 	}
 	if q.getNextStopInTripStmt, err = db.PrepareContext(ctx, getNextStopInTrip); err != nil {
 		return nil, fmt.Errorf("error preparing query GetNextStopInTrip: %w", err)
 	}
+	if q.getNextTripInBlockStmt, err = db.PrepareContext(ctx, getNextTripInBlock); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNextTripInBlock: %w", err)
+	}
 	if q.getOrderedStopIDsForRouteDirectionStmt, err = db.PrepareContext(ctx, getOrderedStopIDsForRouteDirection); err != nil {
 		return nil, fmt.Errorf("error preparing query GetOrderedStopIDsForRouteDirection: %w", err)
 	}
 	if q.getOrderedStopIDsForTripStmt, err = db.PrepareContext(ctx, getOrderedStopIDsForTrip); err != nil {
 		return nil, fmt.Errorf("error preparing query GetOrderedStopIDsForTrip: %w", err)
 	}
+	if q.getPreviousTripInBlockStmt, err = db.PrepareContext(ctx, getPreviousTripInBlock); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPreviousTripInBlock: %w", err)
+	}
 	if q.getProblemReportsByStopStmt, err = db.PrepareContext(ctx, getProblemReportsByStop); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProblemReportsByStop: %w", err)
 	}
@@ -240,6 +435,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getRoutesForAgencyStmt, err = db.PrepareContext(ctx, getRoutesForAgency); err != nil {
 		return nil, fmt.Errorf("error preparing query GetRoutesForAgency: %w", err)
 	}
+	if q.getRoutesForShapeIDsStmt, err = db.PrepareContext(ctx, getRoutesForShapeIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRoutesForShapeIDs: %w", err)
+	}
 	if q.getRoutesForStopStmt, err = db.PrepareContext(ctx, getRoutesForStop); err != nil {
 		return nil, fmt.Errorf("error preparing query GetRoutesForStop: %w", err)
 	}
@@ -255,9 +453,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getScheduleForStopOnDateStmt, err = db.PrepareContext(ctx, getScheduleForStopOnDate); err != nil {
 		return nil, fmt.Errorf("error preparing query GetScheduleForStopOnDate: %w", err)
 	}
+	if q.getServiceExceptionsForAgencyStmt, err = db.PrepareContext(ctx, getServiceExceptionsForAgency); err != nil {
+		return nil, fmt.Errorf("error preparing query GetServiceExceptionsForAgency: %w", err)
+	}
 	if q.getShapeByIDStmt, err = db.PrepareContext(ctx, getShapeByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetShapeByID: %w", err)
 	}
+	if q.getShapeIDsWithinBoundsStmt, err = db.PrepareContext(ctx, getShapeIDsWithinBounds); err != nil {
+		return nil, fmt.Errorf("error preparing query GetShapeIDsWithinBounds: %w", err)
+	}
 	if q.getShapePointWindowStmt, err = db.PrepareContext(ctx, getShapePointWindow); err != nil {
 		return nil, fmt.Errorf("error preparing query GetShapePointWindow: %w", err)
 	}
@@ -306,6 +510,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getStopTimesForTripIDsStmt, err = db.PrepareContext(ctx, getStopTimesForTripIDs); err != nil {
 		return nil, fmt.Errorf("error preparing query GetStopTimesForTripIDs: %w", err)
 	}
+	if q.getStopsByCodeStmt, err = db.PrepareContext(ctx, getStopsByCode); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStopsByCode: %w", err)
+	}
 	if q.getStopsByIDsStmt, err = db.PrepareContext(ctx, getStopsByIDs); err != nil {
 		return nil, fmt.Errorf("error preparing query GetStopsByIDs: %w", err)
 	}
@@ -327,9 +534,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getTargetStopTimeWithTotalStopsBySequenceStmt, err = db.PrepareContext(ctx, getTargetStopTimeWithTotalStopsBySequence); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTargetStopTimeWithTotalStopsBySequence: %w", err)
 	}
+	if q.getTransfersFromStopStmt, err = db.PrepareContext(ctx, getTransfersFromStop); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransfersFromStop: %w", err)
+	}
 	if q.getTripStmt, err = db.PrepareContext(ctx, getTrip); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTrip: %w", err)
 	}
+	if q.getTripTimeZonesStmt, err = db.PrepareContext(ctx, getTripTimeZones); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTripTimeZones: %w", err)
+	}
 	if q.getTripsByBlockIDStmt, err = db.PrepareContext(ctx, getTripsByBlockID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTripsByBlockID: %w", err)
 	}
@@ -354,12 +567,27 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getTripsInBlockStmt, err = db.PrepareContext(ctx, getTripsInBlock); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTripsInBlock: %w", err)
 	}
+	if q.listAPIKeyUsageStmt, err = db.PrepareContext(ctx, listAPIKeyUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAPIKeyUsage: %w", err)
+	}
+	if q.listAPIKeysStmt, err = db.PrepareContext(ctx, listAPIKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAPIKeys: %w", err)
+	}
+	if q.listAdminAuditEntriesStmt, err = db.PrepareContext(ctx, listAdminAuditEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAdminAuditEntries: %w", err)
+	}
 	if q.listAgenciesStmt, err = db.PrepareContext(ctx, listAgencies); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAgencies: %w", err)
 	}
 	if q.listAgencyIdsStmt, err = db.PrepareContext(ctx, listAgencyIds); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAgencyIds: %w", err)
 	}
+	if q.listArrivalHistoryForTripStmt, err = db.PrepareContext(ctx, listArrivalHistoryForTrip); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArrivalHistoryForTrip: %w", err)
+	}
+	if q.listDeveloperAPIKeyQuotaRequestsStmt, err = db.PrepareContext(ctx, listDeveloperAPIKeyQuotaRequests); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDeveloperAPIKeyQuotaRequests: %w", err)
+	}
 	if q.listRoutesStmt, err = db.PrepareContext(ctx, listRoutes); err != nil {
 		return nil, fmt.Errorf("error preparing query ListRoutes: %w", err)
 	}
@@ -372,6 +600,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listTripsWithLimitStmt, err = db.PrepareContext(ctx, listTripsWithLimit); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTripsWithLimit: %w", err)
 	}
+	if q.recordArrivalHistoryStmt, err = db.PrepareContext(ctx, recordArrivalHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordArrivalHistory: %w", err)
+	}
+	if q.requestDeveloperAPIKeyQuotaStmt, err = db.PrepareContext(ctx, requestDeveloperAPIKeyQuota); err != nil {
+		return nil, fmt.Errorf("error preparing query RequestDeveloperAPIKeyQuota: %w", err)
+	}
+	if q.revokeAPIKeyStmt, err = db.PrepareContext(ctx, revokeAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeAPIKey: %w", err)
+	}
 	if q.routeHasFutureServiceStmt, err = db.PrepareContext(ctx, routeHasFutureService); err != nil {
 		return nil, fmt.Errorf("error preparing query RouteHasFutureService: %w", err)
 	}
@@ -392,6 +629,26 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.activateDeveloperAPIKeyStmt != nil {
+		if cerr := q.activateDeveloperAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing activateDeveloperAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.addAPIKeyUsageStmt != nil {
+		if cerr := q.addAPIKeyUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addAPIKeyUsageStmt: %w", cerr)
+		}
+	}
+	if q.buildBlockTripOrderStmt != nil {
+		if cerr := q.buildBlockTripOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing buildBlockTripOrderStmt: %w", cerr)
+		}
+	}
+	if q.buildRouteServiceSpansStmt != nil {
+		if cerr := q.buildRouteServiceSpansStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing buildRouteServiceSpansStmt: %w", cerr)
+		}
+	}
 	if q.bulkUpdateTripTimeBoundsStmt != nil {
 		if cerr := q.bulkUpdateTripTimeBoundsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing bulkUpdateTripTimeBoundsStmt: %w", cerr)
@@ -402,6 +659,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing clearAgenciesStmt: %w", cerr)
 		}
 	}
+	if q.clearAreasStmt != nil {
+		if cerr := q.clearAreasStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearAreasStmt: %w", cerr)
+		}
+	}
 	if q.clearBlockLayoversStmt != nil {
 		if cerr := q.clearBlockLayoversStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearBlockLayoversStmt: %w", cerr)
@@ -417,6 +679,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing clearBlockTripIndicesStmt: %w", cerr)
 		}
 	}
+	if q.clearBlockTripOrderStmt != nil {
+		if cerr := q.clearBlockTripOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearBlockTripOrderStmt: %w", cerr)
+		}
+	}
+	if q.clearBookingRulesStmt != nil {
+		if cerr := q.clearBookingRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearBookingRulesStmt: %w", cerr)
+		}
+	}
 	if q.clearCalendarStmt != nil {
 		if cerr := q.clearCalendarStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearCalendarStmt: %w", cerr)
@@ -427,11 +699,66 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing clearCalendarDatesStmt: %w", cerr)
 		}
 	}
+	if q.clearFareAttributesStmt != nil {
+		if cerr := q.clearFareAttributesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFareAttributesStmt: %w", cerr)
+		}
+	}
+	if q.clearFareLegRulesStmt != nil {
+		if cerr := q.clearFareLegRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFareLegRulesStmt: %w", cerr)
+		}
+	}
+	if q.clearFareMediaStmt != nil {
+		if cerr := q.clearFareMediaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFareMediaStmt: %w", cerr)
+		}
+	}
+	if q.clearFareProductsStmt != nil {
+		if cerr := q.clearFareProductsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFareProductsStmt: %w", cerr)
+		}
+	}
+	if q.clearFareRulesStmt != nil {
+		if cerr := q.clearFareRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFareRulesStmt: %w", cerr)
+		}
+	}
+	if q.clearFlexStopTimesStmt != nil {
+		if cerr := q.clearFlexStopTimesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearFlexStopTimesStmt: %w", cerr)
+		}
+	}
 	if q.clearFrequenciesStmt != nil {
 		if cerr := q.clearFrequenciesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearFrequenciesStmt: %w", cerr)
 		}
 	}
+	if q.clearLocationGroupStopsStmt != nil {
+		if cerr := q.clearLocationGroupStopsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearLocationGroupStopsStmt: %w", cerr)
+		}
+	}
+	if q.clearLocationGroupsStmt != nil {
+		if cerr := q.clearLocationGroupsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearLocationGroupsStmt: %w", cerr)
+		}
+	}
+	if q.clearRiderCategoriesStmt != nil {
+		if cerr := q.clearRiderCategoriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearRiderCategoriesStmt: %w", cerr)
+		}
+	}
+	if q.clearRouteNetworksStmt != nil {
+		if cerr := q.clearRouteNetworksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearRouteNetworksStmt: %w", cerr)
+		}
+	}
+	if q.clearRouteServiceSpansStmt != nil {
+		if cerr := q.clearRouteServiceSpansStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearRouteServiceSpansStmt: %w", cerr)
+		}
+	}
 	if q.clearRoutesStmt != nil {
 		if cerr := q.clearRoutesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearRoutesStmt: %w", cerr)
@@ -442,6 +769,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing clearShapesStmt: %w", cerr)
 		}
 	}
+	if q.clearStopAreasStmt != nil {
+		if cerr := q.clearStopAreasStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearStopAreasStmt: %w", cerr)
+		}
+	}
 	if q.clearStopTimesStmt != nil {
 		if cerr := q.clearStopTimesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearStopTimesStmt: %w", cerr)
@@ -452,6 +784,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing clearStopsStmt: %w", cerr)
 		}
 	}
+	if q.clearTransfersStmt != nil {
+		if cerr := q.clearTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearTransfersStmt: %w", cerr)
+		}
+	}
 	if q.clearTripsStmt != nil {
 		if cerr := q.clearTripsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearTripsStmt: %w", cerr)
@@ -462,6 +799,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countAgenciesStmt: %w", cerr)
 		}
 	}
+	if q.countOpenDeveloperAPIKeysByEmailStmt != nil {
+		if cerr := q.countOpenDeveloperAPIKeysByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countOpenDeveloperAPIKeysByEmailStmt: %w", cerr)
+		}
+	}
 	if q.countRoutesStmt != nil {
 		if cerr := q.countRoutesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countRoutesStmt: %w", cerr)
@@ -477,11 +819,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countTripsStmt: %w", cerr)
 		}
 	}
+	if q.createAPIKeyStmt != nil {
+		if cerr := q.createAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.createAdminAuditEntryStmt != nil {
+		if cerr := q.createAdminAuditEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAdminAuditEntryStmt: %w", cerr)
+		}
+	}
 	if q.createAgencyStmt != nil {
 		if cerr := q.createAgencyStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createAgencyStmt: %w", cerr)
 		}
 	}
+	if q.createAreaStmt != nil {
+		if cerr := q.createAreaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAreaStmt: %w", cerr)
+		}
+	}
 	if q.createBlockLayoverStmt != nil {
 		if cerr := q.createBlockLayoverStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createBlockLayoverStmt: %w", cerr)
@@ -497,6 +854,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createBlockTripIndexStmt: %w", cerr)
 		}
 	}
+	if q.createBookingRuleStmt != nil {
+		if cerr := q.createBookingRuleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBookingRuleStmt: %w", cerr)
+		}
+	}
 	if q.createCalendarStmt != nil {
 		if cerr := q.createCalendarStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createCalendarStmt: %w", cerr)
@@ -507,11 +869,56 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createCalendarDateStmt: %w", cerr)
 		}
 	}
+	if q.createDeveloperAPIKeyStmt != nil {
+		if cerr := q.createDeveloperAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDeveloperAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.createFareAttributeStmt != nil {
+		if cerr := q.createFareAttributeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFareAttributeStmt: %w", cerr)
+		}
+	}
+	if q.createFareLegRuleStmt != nil {
+		if cerr := q.createFareLegRuleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFareLegRuleStmt: %w", cerr)
+		}
+	}
+	if q.createFareMediaStmt != nil {
+		if cerr := q.createFareMediaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFareMediaStmt: %w", cerr)
+		}
+	}
+	if q.createFareProductStmt != nil {
+		if cerr := q.createFareProductStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFareProductStmt: %w", cerr)
+		}
+	}
+	if q.createFareRuleStmt != nil {
+		if cerr := q.createFareRuleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFareRuleStmt: %w", cerr)
+		}
+	}
+	if q.createFlexStopTimeStmt != nil {
+		if cerr := q.createFlexStopTimeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFlexStopTimeStmt: %w", cerr)
+		}
+	}
 	if q.createFrequencyStmt != nil {
 		if cerr := q.createFrequencyStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createFrequencyStmt: %w", cerr)
 		}
 	}
+	if q.createLocationGroupStmt != nil {
+		if cerr := q.createLocationGroupStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createLocationGroupStmt: %w", cerr)
+		}
+	}
+	if q.createLocationGroupStopStmt != nil {
+		if cerr := q.createLocationGroupStopStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createLocationGroupStopStmt: %w", cerr)
+		}
+	}
 	if q.createProblemReportStopStmt != nil {
 		if cerr := q.createProblemReportStopStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createProblemReportStopStmt: %w", cerr)
@@ -522,11 +929,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createProblemReportTripStmt: %w", cerr)
 		}
 	}
+	if q.createRiderCategoryStmt != nil {
+		if cerr := q.createRiderCategoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRiderCategoryStmt: %w", cerr)
+		}
+	}
 	if q.createRouteStmt != nil {
 		if cerr := q.createRouteStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createRouteStmt: %w", cerr)
 		}
 	}
+	if q.createRouteNetworkStmt != nil {
+		if cerr := q.createRouteNetworkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRouteNetworkStmt: %w", cerr)
+		}
+	}
 	if q.createShapeStmt != nil {
 		if cerr := q.createShapeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createShapeStmt: %w", cerr)
@@ -537,16 +954,71 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createStopStmt: %w", cerr)
 		}
 	}
+	if q.createStopAreaStmt != nil {
+		if cerr := q.createStopAreaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStopAreaStmt: %w", cerr)
+		}
+	}
 	if q.createStopTimeStmt != nil {
 		if cerr := q.createStopTimeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createStopTimeStmt: %w", cerr)
 		}
 	}
+	if q.createTransferStmt != nil {
+		if cerr := q.createTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferStmt: %w", cerr)
+		}
+	}
 	if q.createTripStmt != nil {
 		if cerr := q.createTripStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createTripStmt: %w", cerr)
 		}
 	}
+	if q.decideDeveloperAPIKeyQuotaStmt != nil {
+		if cerr := q.decideDeveloperAPIKeyQuotaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing decideDeveloperAPIKeyQuotaStmt: %w", cerr)
+		}
+	}
+	if q.deleteAPIKeyUsageBeforeStmt != nil {
+		if cerr := q.deleteAPIKeyUsageBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAPIKeyUsageBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteArrivalHistoryBeforeStmt != nil {
+		if cerr := q.deleteArrivalHistoryBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteArrivalHistoryBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredDeveloperAPIKeySignupsStmt != nil {
+		if cerr := q.deleteExpiredDeveloperAPIKeySignupsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredDeveloperAPIKeySignupsStmt: %w", cerr)
+		}
+	}
+	if q.deleteProblemReportsStopBeforeStmt != nil {
+		if cerr := q.deleteProblemReportsStopBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteProblemReportsStopBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteProblemReportsTripBeforeStmt != nil {
+		if cerr := q.deleteProblemReportsTripBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteProblemReportsTripBeforeStmt: %w", cerr)
+		}
+	}
+	if q.getAPIKeyStmt != nil {
+		if cerr := q.getAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.getActiveAPIKeyByHashStmt != nil {
+		if cerr := q.getActiveAPIKeyByHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getActiveAPIKeyByHashStmt: %w", cerr)
+		}
+	}
+	if q.getActiveDeveloperAPIKeyStmt != nil {
+		if cerr := q.getActiveDeveloperAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getActiveDeveloperAPIKeyStmt: %w", cerr)
+		}
+	}
 	if q.getActiveLayoverBlockIDsForRouteStmt != nil {
 		if cerr := q.getActiveLayoverBlockIDsForRouteStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getActiveLayoverBlockIDsForRouteStmt: %w", cerr)
@@ -582,6 +1054,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getActiveTripsWithNullBlockForRouteStmt: %w", cerr)
 		}
 	}
+	if q.getAdminAuditEntryByIdempotencyKeyStmt != nil {
+		if cerr := q.getAdminAuditEntryByIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAdminAuditEntryByIdempotencyKeyStmt: %w", cerr)
+		}
+	}
 	if q.getAgenciesByIDsStmt != nil {
 		if cerr := q.getAgenciesByIDsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAgenciesByIDsStmt: %w", cerr)
@@ -617,6 +1094,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getAllTripsForRouteStmt: %w", cerr)
 		}
 	}
+	if q.getAreasForStopStmt != nil {
+		if cerr := q.getAreasForStopStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAreasForStopStmt: %w", cerr)
+		}
+	}
 	if q.getArrivalsAndDeparturesForStopStmt != nil {
 		if cerr := q.getArrivalsAndDeparturesForStopStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getArrivalsAndDeparturesForStopStmt: %w", cerr)
@@ -642,9 +1124,19 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getBlockTripIndexIDsForRouteStmt: %w", cerr)
 		}
 	}
-	if q.getBlockTripSequenceStmt != nil {
-		if cerr := q.getBlockTripSequenceStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getBlockTripSequenceStmt: %w", cerr)
+	if q.getBlockTripOrderStmt != nil {
+		if cerr := q.getBlockTripOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockTripOrderStmt: %w", cerr)
+		}
+	}
+	if q.getBlockTripOrderForServicesStmt != nil {
+		if cerr := q.getBlockTripOrderForServicesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockTripOrderForServicesStmt: %w", cerr)
+		}
+	}
+	if q.getBlockTripsForAgencyStmt != nil {
+		if cerr := q.getBlockTripsForAgencyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBlockTripsForAgencyStmt: %w", cerr)
 		}
 	}
 	if q.getBlocksForBlockTripIndexIDsStmt != nil {
@@ -652,6 +1144,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getBlocksForBlockTripIndexIDsStmt: %w", cerr)
 		}
 	}
+	if q.getBookingRulesByIDsStmt != nil {
+		if cerr := q.getBookingRulesByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBookingRulesByIDsStmt: %w", cerr)
+		}
+	}
+	if q.getBookingRulesForRouteStmt != nil {
+		if cerr := q.getBookingRulesForRouteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBookingRulesForRouteStmt: %w", cerr)
+		}
+	}
+	if q.getBookingRulesForStopStmt != nil {
+		if cerr := q.getBookingRulesForStopStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBookingRulesForStopStmt: %w", cerr)
+		}
+	}
 	if q.getCalendarByServiceIDStmt != nil {
 		if cerr := q.getCalendarByServiceIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getCalendarByServiceIDStmt: %w", cerr)
@@ -662,14 +1169,59 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getCalendarDateExceptionsForServiceIDStmt: %w", cerr)
 		}
 	}
+	if q.getDeveloperAPIKeyStmt != nil {
+		if cerr := q.getDeveloperAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDeveloperAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.getDeveloperAPIKeyByVerificationTokenStmt != nil {
+		if cerr := q.getDeveloperAPIKeyByVerificationTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDeveloperAPIKeyByVerificationTokenStmt: %w", cerr)
+		}
+	}
+	if q.getFareAttributesForRouteStmt != nil {
+		if cerr := q.getFareAttributesForRouteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFareAttributesForRouteStmt: %w", cerr)
+		}
+	}
+	if q.getFareLegRulesStmt != nil {
+		if cerr := q.getFareLegRulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFareLegRulesStmt: %w", cerr)
+		}
+	}
+	if q.getFareLegRulesForRouteStmt != nil {
+		if cerr := q.getFareLegRulesForRouteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFareLegRulesForRouteStmt: %w", cerr)
+		}
+	}
+	if q.getFareMediaByIDsStmt != nil {
+		if cerr := q.getFareMediaByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFareMediaByIDsStmt: %w", cerr)
+		}
+	}
+	if q.getFareProductsByIDsStmt != nil {
+		if cerr := q.getFareProductsByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFareProductsByIDsStmt: %w", cerr)
+		}
+	}
+	if q.getFareRulesForRouteStmt != nil {
+		if cerr := q.getFareRulesForRouteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFareRulesForRouteStmt: %w", cerr)
+		}
+	}
 	if q.getFeedEndDateStmt != nil {
 		if cerr := q.getFeedEndDateStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getFeedEndDateStmt: %w", cerr)
 		}
 	}
-	if q.getFirstStopOfNextTripInBlockStmt != nil {
-		if cerr := q.getFirstStopOfNextTripInBlockStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getFirstStopOfNextTripInBlockStmt: %w", cerr)
+	if q.getFlexStopTimesForStopStmt != nil {
+		if cerr := q.getFlexStopTimesForStopStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFlexStopTimesForStopStmt: %w", cerr)
+		}
+	}
+	if q.getFlexStopTimesForTripStmt != nil {
+		if cerr := q.getFlexStopTimesForTripStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFlexStopTimesForTripStmt: %w", cerr)
 		}
 	}
 	if q.getFrequenciesForTripStmt != nil {
@@ -692,9 +1244,9 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getImportMetadataStmt: %w", cerr)
 		}
 	}
-	if q.getNextAndPreviousTripsInBlockStmt != nil {
-		if cerr := q.getNextAndPreviousTripsInBlockStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getNextAndPreviousTripsInBlockStmt: %w", cerr)
+	if q.getNetworksForRouteStmt != nil {
+		if cerr := q.getNetworksForRouteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNetworksForRouteStmt: %w", cerr)
 		}
 	}
 	if q.getNextStopInTripStmt != nil {
@@ -702,6 +1254,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getNextStopInTripStmt: %w", cerr)
 		}
 	}
+	if q.getNextTripInBlockStmt != nil {
+		if cerr := q.getNextTripInBlockStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNextTripInBlockStmt: %w", cerr)
+		}
+	}
 	if q.getOrderedStopIDsForRouteDirectionStmt != nil {
 		if cerr := q.getOrderedStopIDsForRouteDirectionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getOrderedStopIDsForRouteDirectionStmt: %w", cerr)
@@ -712,6 +1269,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getOrderedStopIDsForTripStmt: %w", cerr)
 		}
 	}
+	if q.getPreviousTripInBlockStmt != nil {
+		if cerr := q.getPreviousTripInBlockStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPreviousTripInBlockStmt: %w", cerr)
+		}
+	}
 	if q.getProblemReportsByStopStmt != nil {
 		if cerr := q.getProblemReportsByStopStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getProblemReportsByStopStmt: %w", cerr)
@@ -752,6 +1314,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getRoutesForAgencyStmt: %w", cerr)
 		}
 	}
+	if q.getRoutesForShapeIDsStmt != nil {
+		if cerr := q.getRoutesForShapeIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRoutesForShapeIDsStmt: %w", cerr)
+		}
+	}
 	if q.getRoutesForStopStmt != nil {
 		if cerr := q.getRoutesForStopStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getRoutesForStopStmt: %w", cerr)
@@ -777,11 +1344,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getScheduleForStopOnDateStmt: %w", cerr)
 		}
 	}
+	if q.getServiceExceptionsForAgencyStmt != nil {
+		if cerr := q.getServiceExceptionsForAgencyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getServiceExceptionsForAgencyStmt: %w", cerr)
+		}
+	}
 	if q.getShapeByIDStmt != nil {
 		if cerr := q.getShapeByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getShapeByIDStmt: %w", cerr)
 		}
 	}
+	if q.getShapeIDsWithinBoundsStmt != nil {
+		if cerr := q.getShapeIDsWithinBoundsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getShapeIDsWithinBoundsStmt: %w", cerr)
+		}
+	}
 	if q.getShapePointWindowStmt != nil {
 		if cerr := q.getShapePointWindowStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getShapePointWindowStmt: %w", cerr)
@@ -862,6 +1439,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getStopTimesForTripIDsStmt: %w", cerr)
 		}
 	}
+	if q.getStopsByCodeStmt != nil {
+		if cerr := q.getStopsByCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStopsByCodeStmt: %w", cerr)
+		}
+	}
 	if q.getStopsByIDsStmt != nil {
 		if cerr := q.getStopsByIDsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getStopsByIDsStmt: %w", cerr)
@@ -897,11 +1479,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getTargetStopTimeWithTotalStopsBySequenceStmt: %w", cerr)
 		}
 	}
+	if q.getTransfersFromStopStmt != nil {
+		if cerr := q.getTransfersFromStopStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransfersFromStopStmt: %w", cerr)
+		}
+	}
 	if q.getTripStmt != nil {
 		if cerr := q.getTripStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTripStmt: %w", cerr)
 		}
 	}
+	if q.getTripTimeZonesStmt != nil {
+		if cerr := q.getTripTimeZonesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTripTimeZonesStmt: %w", cerr)
+		}
+	}
 	if q.getTripsByBlockIDStmt != nil {
 		if cerr := q.getTripsByBlockIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTripsByBlockIDStmt: %w", cerr)
@@ -942,6 +1534,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getTripsInBlockStmt: %w", cerr)
 		}
 	}
+	if q.listAPIKeyUsageStmt != nil {
+		if cerr := q.listAPIKeyUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAPIKeyUsageStmt: %w", cerr)
+		}
+	}
+	if q.listAPIKeysStmt != nil {
+		if cerr := q.listAPIKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAPIKeysStmt: %w", cerr)
+		}
+	}
+	if q.listAdminAuditEntriesStmt != nil {
+		if cerr := q.listAdminAuditEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAdminAuditEntriesStmt: %w", cerr)
+		}
+	}
 	if q.listAgenciesStmt != nil {
 		if cerr := q.listAgenciesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAgenciesStmt: %w", cerr)
@@ -952,6 +1559,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listAgencyIdsStmt: %w", cerr)
 		}
 	}
+	if q.listArrivalHistoryForTripStmt != nil {
+		if cerr := q.listArrivalHistoryForTripStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArrivalHistoryForTripStmt: %w", cerr)
+		}
+	}
+	if q.listDeveloperAPIKeyQuotaRequestsStmt != nil {
+		if cerr := q.listDeveloperAPIKeyQuotaRequestsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDeveloperAPIKeyQuotaRequestsStmt: %w", cerr)
+		}
+	}
 	if q.listRoutesStmt != nil {
 		if cerr := q.listRoutesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listRoutesStmt: %w", cerr)
@@ -972,6 +1589,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listTripsWithLimitStmt: %w", cerr)
 		}
 	}
+	if q.recordArrivalHistoryStmt != nil {
+		if cerr := q.recordArrivalHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordArrivalHistoryStmt: %w", cerr)
+		}
+	}
+	if q.requestDeveloperAPIKeyQuotaStmt != nil {
+		if cerr := q.requestDeveloperAPIKeyQuotaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requestDeveloperAPIKeyQuotaStmt: %w", cerr)
+		}
+	}
+	if q.revokeAPIKeyStmt != nil {
+		if cerr := q.revokeAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeAPIKeyStmt: %w", cerr)
+		}
+	}
 	if q.routeHasFutureServiceStmt != nil {
 		if cerr := q.routeHasFutureServiceStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing routeHasFutureServiceStmt: %w", cerr)
@@ -1036,37 +1668,84 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 type Queries struct {
 	db                                            DBTX
 	tx                                            *sql.Tx
+	activateDeveloperAPIKeyStmt                   *sql.Stmt
+	addAPIKeyUsageStmt                            *sql.Stmt
+	buildBlockTripOrderStmt                       *sql.Stmt
+	buildRouteServiceSpansStmt                    *sql.Stmt
 	bulkUpdateTripTimeBoundsStmt                  *sql.Stmt
 	clearAgenciesStmt                             *sql.Stmt
+	clearAreasStmt                                *sql.Stmt
 	clearBlockLayoversStmt                        *sql.Stmt
 	clearBlockTripEntriesStmt                     *sql.Stmt
 	clearBlockTripIndicesStmt                     *sql.Stmt
+	clearBlockTripOrderStmt                       *sql.Stmt
+	clearBookingRulesStmt                         *sql.Stmt
 	clearCalendarStmt                             *sql.Stmt
 	clearCalendarDatesStmt                        *sql.Stmt
+	clearFareAttributesStmt                       *sql.Stmt
+	clearFareLegRulesStmt                         *sql.Stmt
+	clearFareMediaStmt                            *sql.Stmt
+	clearFareProductsStmt                         *sql.Stmt
+	clearFareRulesStmt                            *sql.Stmt
+	clearFlexStopTimesStmt                        *sql.Stmt
 	clearFrequenciesStmt                          *sql.Stmt
+	clearLocationGroupStopsStmt                   *sql.Stmt
+	clearLocationGroupsStmt                       *sql.Stmt
+	clearRiderCategoriesStmt                      *sql.Stmt
+	clearRouteNetworksStmt                        *sql.Stmt
+	clearRouteServiceSpansStmt                    *sql.Stmt
 	clearRoutesStmt                               *sql.Stmt
 	clearShapesStmt                               *sql.Stmt
+	clearStopAreasStmt                            *sql.Stmt
 	clearStopTimesStmt                            *sql.Stmt
 	clearStopsStmt                                *sql.Stmt
+	clearTransfersStmt                            *sql.Stmt
 	clearTripsStmt                                *sql.Stmt
 	countAgenciesStmt                             *sql.Stmt
+	countOpenDeveloperAPIKeysByEmailStmt          *sql.Stmt
 	countRoutesStmt                               *sql.Stmt
 	countStopsStmt                                *sql.Stmt
 	countTripsStmt                                *sql.Stmt
+	createAPIKeyStmt                              *sql.Stmt
+	createAdminAuditEntryStmt                     *sql.Stmt
 	createAgencyStmt                              *sql.Stmt
+	createAreaStmt                                *sql.Stmt
 	createBlockLayoverStmt                        *sql.Stmt
 	createBlockTripEntryStmt                      *sql.Stmt
 	createBlockTripIndexStmt                      *sql.Stmt
+	createBookingRuleStmt                         *sql.Stmt
 	createCalendarStmt                            *sql.Stmt
 	createCalendarDateStmt                        *sql.Stmt
+	createDeveloperAPIKeyStmt                     *sql.Stmt
+	createFareAttributeStmt                       *sql.Stmt
+	createFareLegRuleStmt                         *sql.Stmt
+	createFareMediaStmt                           *sql.Stmt
+	createFareProductStmt                         *sql.Stmt
+	createFareRuleStmt                            *sql.Stmt
+	createFlexStopTimeStmt                        *sql.Stmt
 	createFrequencyStmt                           *sql.Stmt
+	createLocationGroupStmt                       *sql.Stmt
+	createLocationGroupStopStmt                   *sql.Stmt
 	createProblemReportStopStmt                   *sql.Stmt
 	createProblemReportTripStmt                   *sql.Stmt
+	createRiderCategoryStmt                       *sql.Stmt
 	createRouteStmt                               *sql.Stmt
+	createRouteNetworkStmt                        *sql.Stmt
 	createShapeStmt                               *sql.Stmt
 	createStopStmt                                *sql.Stmt
+	createStopAreaStmt                            *sql.Stmt
 	createStopTimeStmt                            *sql.Stmt
+	createTransferStmt                            *sql.Stmt
 	createTripStmt                                *sql.Stmt
+	decideDeveloperAPIKeyQuotaStmt                *sql.Stmt
+	deleteAPIKeyUsageBeforeStmt                   *sql.Stmt
+	deleteArrivalHistoryBeforeStmt                *sql.Stmt
+	deleteExpiredDeveloperAPIKeySignupsStmt       *sql.Stmt
+	deleteProblemReportsStopBeforeStmt            *sql.Stmt
+	deleteProblemReportsTripBeforeStmt            *sql.Stmt
+	getAPIKeyStmt                                 *sql.Stmt
+	getActiveAPIKeyByHashStmt                     *sql.Stmt
+	getActiveDeveloperAPIKeyStmt                  *sql.Stmt
 	getActiveLayoverBlockIDsForRouteStmt          *sql.Stmt
 	getActiveRouteIDsForStopsOnDateStmt           *sql.Stmt
 	getActiveServiceIDsForDateStmt                *sql.Stmt
@@ -1074,6 +1753,7 @@ type Queries struct {
 	getActiveTripForRouteAtTimeStmt               *sql.Stmt
 	getActiveTripInBlockAtTimeStmt                *sql.Stmt
 	getActiveTripsWithNullBlockForRouteStmt       *sql.Stmt
+	getAdminAuditEntryByIdempotencyKeyStmt        *sql.Stmt
 	getAgenciesByIDsStmt                          *sql.Stmt
 	getAgenciesForStopsStmt                       *sql.Stmt
 	getAgencyStmt                                 *sql.Stmt
@@ -1081,25 +1761,42 @@ type Queries struct {
 	getAllShapesStmt                              *sql.Stmt
 	getAllStopIDsStmt                             *sql.Stmt
 	getAllTripsForRouteStmt                       *sql.Stmt
+	getAreasForStopStmt                           *sql.Stmt
 	getArrivalsAndDeparturesForStopStmt           *sql.Stmt
 	getBlockDetailsStmt                           *sql.Stmt
 	getBlockIDByTripIDStmt                        *sql.Stmt
 	getBlockTripIndexIDsForBlocksStmt             *sql.Stmt
 	getBlockTripIndexIDsForRouteStmt              *sql.Stmt
-	getBlockTripSequenceStmt                      *sql.Stmt
+	getBlockTripOrderStmt                         *sql.Stmt
+	getBlockTripOrderForServicesStmt              *sql.Stmt
+	getBlockTripsForAgencyStmt                    *sql.Stmt
 	getBlocksForBlockTripIndexIDsStmt             *sql.Stmt
+	getBookingRulesByIDsStmt                      *sql.Stmt
+	getBookingRulesForRouteStmt                   *sql.Stmt
+	getBookingRulesForStopStmt                    *sql.Stmt
 	getCalendarByServiceIDStmt                    *sql.Stmt
 	getCalendarDateExceptionsForServiceIDStmt     *sql.Stmt
+	getDeveloperAPIKeyStmt                        *sql.Stmt
+	getDeveloperAPIKeyByVerificationTokenStmt     *sql.Stmt
+	getFareAttributesForRouteStmt                 *sql.Stmt
+	getFareLegRulesStmt                           *sql.Stmt
+	getFareLegRulesForRouteStmt                   *sql.Stmt
+	getFareMediaByIDsStmt                         *sql.Stmt
+	getFareProductsByIDsStmt                      *sql.Stmt
+	getFareRulesForRouteStmt                      *sql.Stmt
 	getFeedEndDateStmt                            *sql.Stmt
-	getFirstStopOfNextTripInBlockStmt             *sql.Stmt
+	getFlexStopTimesForStopStmt                   *sql.Stmt
+	getFlexStopTimesForTripStmt                   *sql.Stmt
 	getFrequenciesForTripStmt                     *sql.Stmt
 	getFrequenciesForTripsStmt                    *sql.Stmt
 	getFrequencyTripIDsStmt                       *sql.Stmt
 	getImportMetadataStmt                         *sql.Stmt
-	getNextAndPreviousTripsInBlockStmt            *sql.Stmt
+	getNetworksForRouteStmt                       *sql.Stmt
 	getNextStopInTripStmt                         *sql.Stmt
+	getNextTripInBlockStmt                        *sql.Stmt
 	getOrderedStopIDsForRouteDirectionStmt        *sql.Stmt
 	getOrderedStopIDsForTripStmt                  *sql.Stmt
+	getPreviousTripInBlockStmt                    *sql.Stmt
 	getProblemReportsByStopStmt                   *sql.Stmt
 	getProblemReportsByTripStmt                   *sql.Stmt
 	getRouteStmt                                  *sql.Stmt
@@ -1108,12 +1805,15 @@ type Queries struct {
 	getRouteIDsForStopsStmt                       *sql.Stmt
 	getRoutesByIDsStmt                            *sql.Stmt
 	getRoutesForAgencyStmt                        *sql.Stmt
+	getRoutesForShapeIDsStmt                      *sql.Stmt
 	getRoutesForStopStmt                          *sql.Stmt
 	getRoutesForStopsStmt                         *sql.Stmt
 	getRoutesInBlockTripIndicesStmt               *sql.Stmt
 	getScheduleForStopStmt                        *sql.Stmt
 	getScheduleForStopOnDateStmt                  *sql.Stmt
+	getServiceExceptionsForAgencyStmt             *sql.Stmt
 	getShapeByIDStmt                              *sql.Stmt
+	getShapeIDsWithinBoundsStmt                   *sql.Stmt
 	getShapePointWindowStmt                       *sql.Stmt
 	getShapePointsByIDsStmt                       *sql.Stmt
 	getShapePointsByTripIDStmt                    *sql.Stmt
@@ -1130,6 +1830,7 @@ type Queries struct {
 	getStopTimesForStopInWindowStmt               *sql.Stmt
 	getStopTimesForTripStmt                       *sql.Stmt
 	getStopTimesForTripIDsStmt                    *sql.Stmt
+	getStopsByCodeStmt                            *sql.Stmt
 	getStopsByIDsStmt                             *sql.Stmt
 	getStopsForRouteStmt                          *sql.Stmt
 	getStopsWithShapeContextStmt                  *sql.Stmt
@@ -1137,7 +1838,9 @@ type Queries struct {
 	getStopsWithTripContextStmt                   *sql.Stmt
 	getTargetStopTimeWithTotalStopsStmt           *sql.Stmt
 	getTargetStopTimeWithTotalStopsBySequenceStmt *sql.Stmt
+	getTransfersFromStopStmt                      *sql.Stmt
 	getTripStmt                                   *sql.Stmt
+	getTripTimeZonesStmt                          *sql.Stmt
 	getTripsByBlockIDStmt                         *sql.Stmt
 	getTripsByBlockIDOrderedStmt                  *sql.Stmt
 	getTripsByBlockIDsStmt                        *sql.Stmt
@@ -1146,12 +1849,20 @@ type Queries struct {
 	getTripsByServiceIDStmt                       *sql.Stmt
 	getTripsForRouteInActiveServiceIDsStmt        *sql.Stmt
 	getTripsInBlockStmt                           *sql.Stmt
+	listAPIKeyUsageStmt                           *sql.Stmt
+	listAPIKeysStmt                               *sql.Stmt
+	listAdminAuditEntriesStmt                     *sql.Stmt
 	listAgenciesStmt                              *sql.Stmt
 	listAgencyIdsStmt                             *sql.Stmt
+	listArrivalHistoryForTripStmt                 *sql.Stmt
+	listDeveloperAPIKeyQuotaRequestsStmt          *sql.Stmt
 	listRoutesStmt                                *sql.Stmt
 	listStopsStmt                                 *sql.Stmt
 	listTripsStmt                                 *sql.Stmt
 	listTripsWithLimitStmt                        *sql.Stmt
+	recordArrivalHistoryStmt                      *sql.Stmt
+	requestDeveloperAPIKeyQuotaStmt               *sql.Stmt
+	revokeAPIKeyStmt                              *sql.Stmt
 	routeHasFutureServiceStmt                     *sql.Stmt
 	updateFeedExpiresAtStmt                       *sql.Stmt
 	updateImportTimeStmt                          *sql.Stmt
@@ -1163,37 +1874,84 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
 		db:                                            tx,
 		tx:                                            tx,
+		activateDeveloperAPIKeyStmt:                   q.activateDeveloperAPIKeyStmt,
+		addAPIKeyUsageStmt:                            q.addAPIKeyUsageStmt,
+		buildBlockTripOrderStmt:                       q.buildBlockTripOrderStmt,
+		buildRouteServiceSpansStmt:                    q.buildRouteServiceSpansStmt,
 		bulkUpdateTripTimeBoundsStmt:                  q.bulkUpdateTripTimeBoundsStmt,
 		clearAgenciesStmt:                             q.clearAgenciesStmt,
+		clearAreasStmt:                                q.clearAreasStmt,
 		clearBlockLayoversStmt:                        q.clearBlockLayoversStmt,
 		clearBlockTripEntriesStmt:                     q.clearBlockTripEntriesStmt,
 		clearBlockTripIndicesStmt:                     q.clearBlockTripIndicesStmt,
+		clearBlockTripOrderStmt:                       q.clearBlockTripOrderStmt,
+		clearBookingRulesStmt:                         q.clearBookingRulesStmt,
 		clearCalendarStmt:                             q.clearCalendarStmt,
 		clearCalendarDatesStmt:                        q.clearCalendarDatesStmt,
+		clearFareAttributesStmt:                       q.clearFareAttributesStmt,
+		clearFareLegRulesStmt:                         q.clearFareLegRulesStmt,
+		clearFareMediaStmt:                            q.clearFareMediaStmt,
+		clearFareProductsStmt:                         q.clearFareProductsStmt,
+		clearFareRulesStmt:                            q.clearFareRulesStmt,
+		clearFlexStopTimesStmt:                        q.clearFlexStopTimesStmt,
 		clearFrequenciesStmt:                          q.clearFrequenciesStmt,
+		clearLocationGroupStopsStmt:                   q.clearLocationGroupStopsStmt,
+		clearLocationGroupsStmt:                       q.clearLocationGroupsStmt,
+		clearRiderCategoriesStmt:                      q.clearRiderCategoriesStmt,
+		clearRouteNetworksStmt:                        q.clearRouteNetworksStmt,
+		clearRouteServiceSpansStmt:                    q.clearRouteServiceSpansStmt,
 		clearRoutesStmt:                               q.clearRoutesStmt,
 		clearShapesStmt:                               q.clearShapesStmt,
+		clearStopAreasStmt:                            q.clearStopAreasStmt,
 		clearStopTimesStmt:                            q.clearStopTimesStmt,
 		clearStopsStmt:                                q.clearStopsStmt,
+		clearTransfersStmt:                            q.clearTransfersStmt,
 		clearTripsStmt:                                q.clearTripsStmt,
 		countAgenciesStmt:                             q.countAgenciesStmt,
+		countOpenDeveloperAPIKeysByEmailStmt:          q.countOpenDeveloperAPIKeysByEmailStmt,
 		countRoutesStmt:                               q.countRoutesStmt,
 		countStopsStmt:                                q.countStopsStmt,
 		countTripsStmt:                                q.countTripsStmt,
+		createAPIKeyStmt:                              q.createAPIKeyStmt,
+		createAdminAuditEntryStmt:                     q.createAdminAuditEntryStmt,
 		createAgencyStmt:                              q.createAgencyStmt,
+		createAreaStmt:                                q.createAreaStmt,
 		createBlockLayoverStmt:                        q.createBlockLayoverStmt,
 		createBlockTripEntryStmt:                      q.createBlockTripEntryStmt,
 		createBlockTripIndexStmt:                      q.createBlockTripIndexStmt,
+		createBookingRuleStmt:                         q.createBookingRuleStmt,
 		createCalendarStmt:                            q.createCalendarStmt,
 		createCalendarDateStmt:                        q.createCalendarDateStmt,
+		createDeveloperAPIKeyStmt:                     q.createDeveloperAPIKeyStmt,
+		createFareAttributeStmt:                       q.createFareAttributeStmt,
+		createFareLegRuleStmt:                         q.createFareLegRuleStmt,
+		createFareMediaStmt:                           q.createFareMediaStmt,
+		createFareProductStmt:                         q.createFareProductStmt,
+		createFareRuleStmt:                            q.createFareRuleStmt,
+		createFlexStopTimeStmt:                        q.createFlexStopTimeStmt,
 		createFrequencyStmt:                           q.createFrequencyStmt,
+		createLocationGroupStmt:                       q.createLocationGroupStmt,
+		createLocationGroupStopStmt:                   q.createLocationGroupStopStmt,
 		createProblemReportStopStmt:                   q.createProblemReportStopStmt,
 		createProblemReportTripStmt:                   q.createProblemReportTripStmt,
+		createRiderCategoryStmt:                       q.createRiderCategoryStmt,
 		createRouteStmt:                               q.createRouteStmt,
+		createRouteNetworkStmt:                        q.createRouteNetworkStmt,
 		createShapeStmt:                               q.createShapeStmt,
 		createStopStmt:                                q.createStopStmt,
+		createStopAreaStmt:                            q.createStopAreaStmt,
 		createStopTimeStmt:                            q.createStopTimeStmt,
+		createTransferStmt:                            q.createTransferStmt,
 		createTripStmt:                                q.createTripStmt,
+		decideDeveloperAPIKeyQuotaStmt:                q.decideDeveloperAPIKeyQuotaStmt,
+		deleteAPIKeyUsageBeforeStmt:                   q.deleteAPIKeyUsageBeforeStmt,
+		deleteArrivalHistoryBeforeStmt:                q.deleteArrivalHistoryBeforeStmt,
+		deleteExpiredDeveloperAPIKeySignupsStmt:       q.deleteExpiredDeveloperAPIKeySignupsStmt,
+		deleteProblemReportsStopBeforeStmt:            q.deleteProblemReportsStopBeforeStmt,
+		deleteProblemReportsTripBeforeStmt:            q.deleteProblemReportsTripBeforeStmt,
+		getAPIKeyStmt:                                 q.getAPIKeyStmt,
+		getActiveAPIKeyByHashStmt:                     q.getActiveAPIKeyByHashStmt,
+		getActiveDeveloperAPIKeyStmt:                  q.getActiveDeveloperAPIKeyStmt,
 		getActiveLayoverBlockIDsForRouteStmt:          q.getActiveLayoverBlockIDsForRouteStmt,
 		getActiveRouteIDsForStopsOnDateStmt:           q.getActiveRouteIDsForStopsOnDateStmt,
 		getActiveServiceIDsForDateStmt:                q.getActiveServiceIDsForDateStmt,
@@ -1201,6 +1959,7 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getActiveTripForRouteAtTimeStmt:               q.getActiveTripForRouteAtTimeStmt,
 		getActiveTripInBlockAtTimeStmt:                q.getActiveTripInBlockAtTimeStmt,
 		getActiveTripsWithNullBlockForRouteStmt:       q.getActiveTripsWithNullBlockForRouteStmt,
+		getAdminAuditEntryByIdempotencyKeyStmt:        q.getAdminAuditEntryByIdempotencyKeyStmt,
 		getAgenciesByIDsStmt:                          q.getAgenciesByIDsStmt,
 		getAgenciesForStopsStmt:                       q.getAgenciesForStopsStmt,
 		getAgencyStmt:                                 q.getAgencyStmt,
@@ -1208,25 +1967,42 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getAllShapesStmt:                              q.getAllShapesStmt,
 		getAllStopIDsStmt:                             q.getAllStopIDsStmt,
 		getAllTripsForRouteStmt:                       q.getAllTripsForRouteStmt,
+		getAreasForStopStmt:                           q.getAreasForStopStmt,
 		getArrivalsAndDeparturesForStopStmt:           q.getArrivalsAndDeparturesForStopStmt,
 		getBlockDetailsStmt:                           q.getBlockDetailsStmt,
 		getBlockIDByTripIDStmt:                        q.getBlockIDByTripIDStmt,
 		getBlockTripIndexIDsForBlocksStmt:             q.getBlockTripIndexIDsForBlocksStmt,
 		getBlockTripIndexIDsForRouteStmt:              q.getBlockTripIndexIDsForRouteStmt,
-		getBlockTripSequenceStmt:                      q.getBlockTripSequenceStmt,
+		getBlockTripOrderStmt:                         q.getBlockTripOrderStmt,
+		getBlockTripOrderForServicesStmt:              q.getBlockTripOrderForServicesStmt,
+		getBlockTripsForAgencyStmt:                    q.getBlockTripsForAgencyStmt,
 		getBlocksForBlockTripIndexIDsStmt:             q.getBlocksForBlockTripIndexIDsStmt,
+		getBookingRulesByIDsStmt:                      q.getBookingRulesByIDsStmt,
+		getBookingRulesForRouteStmt:                   q.getBookingRulesForRouteStmt,
+		getBookingRulesForStopStmt:                    q.getBookingRulesForStopStmt,
 		getCalendarByServiceIDStmt:                    q.getCalendarByServiceIDStmt,
 		getCalendarDateExceptionsForServiceIDStmt:     q.getCalendarDateExceptionsForServiceIDStmt,
+		getDeveloperAPIKeyStmt:                        q.getDeveloperAPIKeyStmt,
+		getDeveloperAPIKeyByVerificationTokenStmt:     q.getDeveloperAPIKeyByVerificationTokenStmt,
+		getFareAttributesForRouteStmt:                 q.getFareAttributesForRouteStmt,
+		getFareLegRulesStmt:                           q.getFareLegRulesStmt,
+		getFareLegRulesForRouteStmt:                   q.getFareLegRulesForRouteStmt,
+		getFareMediaByIDsStmt:                         q.getFareMediaByIDsStmt,
+		getFareProductsByIDsStmt:                      q.getFareProductsByIDsStmt,
+		getFareRulesForRouteStmt:                      q.getFareRulesForRouteStmt,
 		getFeedEndDateStmt:                            q.getFeedEndDateStmt,
-		getFirstStopOfNextTripInBlockStmt:             q.getFirstStopOfNextTripInBlockStmt,
+		getFlexStopTimesForStopStmt:                   q.getFlexStopTimesForStopStmt,
+		getFlexStopTimesForTripStmt:                   q.getFlexStopTimesForTripStmt,
 		getFrequenciesForTripStmt:                     q.getFrequenciesForTripStmt,
 		getFrequenciesForTripsStmt:                    q.getFrequenciesForTripsStmt,
 		getFrequencyTripIDsStmt:                       q.getFrequencyTripIDsStmt,
 		getImportMetadataStmt:                         q.getImportMetadataStmt,
-		getNextAndPreviousTripsInBlockStmt:            q.getNextAndPreviousTripsInBlockStmt,
+		getNetworksForRouteStmt:                       q.getNetworksForRouteStmt,
 		getNextStopInTripStmt:                         q.getNextStopInTripStmt,
+		getNextTripInBlockStmt:                        q.getNextTripInBlockStmt,
 		getOrderedStopIDsForRouteDirectionStmt:        q.getOrderedStopIDsForRouteDirectionStmt,
 		getOrderedStopIDsForTripStmt:                  q.getOrderedStopIDsForTripStmt,
+		getPreviousTripInBlockStmt:                    q.getPreviousTripInBlockStmt,
 		getProblemReportsByStopStmt:                   q.getProblemReportsByStopStmt,
 		getProblemReportsByTripStmt:                   q.getProblemReportsByTripStmt,
 		getRouteStmt:                                  q.getRouteStmt,
@@ -1235,12 +2011,15 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getRouteIDsForStopsStmt:                       q.getRouteIDsForStopsStmt,
 		getRoutesByIDsStmt:                            q.getRoutesByIDsStmt,
 		getRoutesForAgencyStmt:                        q.getRoutesForAgencyStmt,
+		getRoutesForShapeIDsStmt:                      q.getRoutesForShapeIDsStmt,
 		getRoutesForStopStmt:                          q.getRoutesForStopStmt,
 		getRoutesForStopsStmt:                         q.getRoutesForStopsStmt,
 		getRoutesInBlockTripIndicesStmt:               q.getRoutesInBlockTripIndicesStmt,
 		getScheduleForStopStmt:                        q.getScheduleForStopStmt,
 		getScheduleForStopOnDateStmt:                  q.getScheduleForStopOnDateStmt,
+		getServiceExceptionsForAgencyStmt:             q.getServiceExceptionsForAgencyStmt,
 		getShapeByIDStmt:                              q.getShapeByIDStmt,
+		getShapeIDsWithinBoundsStmt:                   q.getShapeIDsWithinBoundsStmt,
 		getShapePointWindowStmt:                       q.getShapePointWindowStmt,
 		getShapePointsByIDsStmt:                       q.getShapePointsByIDsStmt,
 		getShapePointsByTripIDStmt:                    q.getShapePointsByTripIDStmt,
@@ -1257,6 +2036,7 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getStopTimesForStopInWindowStmt:               q.getStopTimesForStopInWindowStmt,
 		getStopTimesForTripStmt:                       q.getStopTimesForTripStmt,
 		getStopTimesForTripIDsStmt:                    q.getStopTimesForTripIDsStmt,
+		getStopsByCodeStmt:                            q.getStopsByCodeStmt,
 		getStopsByIDsStmt:                             q.getStopsByIDsStmt,
 		getStopsForRouteStmt:                          q.getStopsForRouteStmt,
 		getStopsWithShapeContextStmt:                  q.getStopsWithShapeContextStmt,
@@ -1264,7 +2044,9 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getStopsWithTripContextStmt:                   q.getStopsWithTripContextStmt,
 		getTargetStopTimeWithTotalStopsStmt:           q.getTargetStopTimeWithTotalStopsStmt,
 		getTargetStopTimeWithTotalStopsBySequenceStmt: q.getTargetStopTimeWithTotalStopsBySequenceStmt,
+		getTransfersFromStopStmt:                      q.getTransfersFromStopStmt,
 		getTripStmt:                                   q.getTripStmt,
+		getTripTimeZonesStmt:                          q.getTripTimeZonesStmt,
 		getTripsByBlockIDStmt:                         q.getTripsByBlockIDStmt,
 		getTripsByBlockIDOrderedStmt:                  q.getTripsByBlockIDOrderedStmt,
 		getTripsByBlockIDsStmt:                        q.getTripsByBlockIDsStmt,
@@ -1273,12 +2055,20 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getTripsByServiceIDStmt:                       q.getTripsByServiceIDStmt,
 		getTripsForRouteInActiveServiceIDsStmt:        q.getTripsForRouteInActiveServiceIDsStmt,
 		getTripsInBlockStmt:                           q.getTripsInBlockStmt,
+		listAPIKeyUsageStmt:                           q.listAPIKeyUsageStmt,
+		listAPIKeysStmt:                               q.listAPIKeysStmt,
+		listAdminAuditEntriesStmt:                     q.listAdminAuditEntriesStmt,
 		listAgenciesStmt:                              q.listAgenciesStmt,
 		listAgencyIdsStmt:                             q.listAgencyIdsStmt,
+		listArrivalHistoryForTripStmt:                 q.listArrivalHistoryForTripStmt,
+		listDeveloperAPIKeyQuotaRequestsStmt:          q.listDeveloperAPIKeyQuotaRequestsStmt,
 		listRoutesStmt:                                q.listRoutesStmt,
 		listStopsStmt:                                 q.listStopsStmt,
 		listTripsStmt:                                 q.listTripsStmt,
 		listTripsWithLimitStmt:                        q.listTripsWithLimitStmt,
+		recordArrivalHistoryStmt:                      q.recordArrivalHistoryStmt,
+		requestDeveloperAPIKeyQuotaStmt:               q.requestDeveloperAPIKeyQuotaStmt,
+		revokeAPIKeyStmt:                              q.revokeAPIKeyStmt,
 		routeHasFutureServiceStmt:                     q.routeHasFutureServiceStmt,
 		updateFeedExpiresAtStmt:                       q.updateFeedExpiresAtStmt,
 		updateImportTimeStmt:                          q.updateImportTimeStmt,
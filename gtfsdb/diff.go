@@ -0,0 +1,199 @@
+package gtfsdb
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/OneBusAway/go-gtfs"
+)
+
+// GtfsDiff summarizes what changes between two versions of a static feed.
+type GtfsDiff struct {
+	Routes EntityDiff `json:"routes"`
+	Stops  EntityDiff `json:"stops"`
+	Trips  EntityDiff `json:"trips"`
+	// Routes whose number of scheduled trips changed, ordered by route ID.
+	ServiceLevels []RouteServiceLevel `json:"serviceLevels"`
+}
+
+// EntityDiff lists the IDs of one entity type that were added, removed, or
+// changed in place, each sorted.
+type EntityDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// Empty reports whether no entity was added, removed or changed.
+func (d EntityDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// RouteServiceLevel is the number of scheduled trips of a route in each
+// version. A route missing from a version has zero trips there.
+type RouteServiceLevel struct {
+	RouteID  string `json:"routeId"`
+	OldTrips int    `json:"oldTrips"`
+	NewTrips int    `json:"newTrips"`
+}
+
+// Empty reports whether the two versions are the same as far as the diff can
+// tell.
+func (d GtfsDiff) Empty() bool {
+	return d.Routes.Empty() && d.Stops.Empty() && d.Trips.Empty() && len(d.ServiceLevels) == 0
+}
+
+// DiffGtfsData compares two parsed feeds by ID. A trip counts as changed when
+// its route, service, headsign, direction, block, shape or stop times differ.
+func DiffGtfsData(old, new *gtfs.Static) GtfsDiff {
+	diff := GtfsDiff{
+		Routes: diffEntities(old.Routes, new.Routes, func(r gtfs.Route) string { return r.Id }, routesEqual),
+		Stops:  diffEntities(old.Stops, new.Stops, func(s gtfs.Stop) string { return s.Id }, stopsEqual),
+		Trips:  diffEntities(old.Trips, new.Trips, func(t gtfs.ScheduledTrip) string { return t.ID }, tripsEqual),
+
+		ServiceLevels: []RouteServiceLevel{},
+	}
+
+	oldLevels := tripsPerRoute(old.Trips)
+	newLevels := tripsPerRoute(new.Trips)
+	for routeID, oldTrips := range oldLevels {
+		if newTrips := newLevels[routeID]; newTrips != oldTrips {
+			diff.ServiceLevels = append(diff.ServiceLevels, RouteServiceLevel{RouteID: routeID, OldTrips: oldTrips, NewTrips: newTrips})
+		}
+	}
+	for routeID, newTrips := range newLevels {
+		if _, ok := oldLevels[routeID]; !ok {
+			diff.ServiceLevels = append(diff.ServiceLevels, RouteServiceLevel{RouteID: routeID, NewTrips: newTrips})
+		}
+	}
+	slices.SortFunc(diff.ServiceLevels, func(a, b RouteServiceLevel) int {
+		return cmp.Compare(a.RouteID, b.RouteID)
+	})
+	return diff
+}
+
+func diffEntities[T any](old, new []T, id func(T) string, equal func(a, b T) bool) EntityDiff {
+	oldByID := make(map[string]*T, len(old))
+	for i := range old {
+		oldByID[id(old[i])] = &old[i]
+	}
+	diff := EntityDiff{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	seen := make(map[string]struct{}, len(new))
+	for i := range new {
+		entityID := id(new[i])
+		seen[entityID] = struct{}{}
+		previous, ok := oldByID[entityID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entityID)
+		case !equal(*previous, new[i]):
+			diff.Changed = append(diff.Changed, entityID)
+		}
+	}
+	for entityID := range oldByID {
+		if _, ok := seen[entityID]; !ok {
+			diff.Removed = append(diff.Removed, entityID)
+		}
+	}
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Removed)
+	slices.Sort(diff.Changed)
+	return diff
+}
+
+func tripsPerRoute(trips []gtfs.ScheduledTrip) map[string]int {
+	counts := make(map[string]int)
+	for _, trip := range trips {
+		if trip.Route != nil {
+			counts[trip.Route.Id]++
+		}
+	}
+	return counts
+}
+
+func routesEqual(a, b gtfs.Route) bool {
+	return agencyIDOf(a.Agency) == agencyIDOf(b.Agency) &&
+		a.ShortName == b.ShortName &&
+		a.LongName == b.LongName &&
+		a.Description == b.Description &&
+		a.Type == b.Type &&
+		a.Url == b.Url &&
+		a.Color == b.Color &&
+		a.TextColor == b.TextColor
+}
+
+func stopsEqual(a, b gtfs.Stop) bool {
+	return a.Code == b.Code &&
+		a.Name == b.Name &&
+		a.Description == b.Description &&
+		floatPtrEqual(a.Latitude, b.Latitude) &&
+		floatPtrEqual(a.Longitude, b.Longitude) &&
+		a.Type == b.Type &&
+		stopIDOf(a.Parent) == stopIDOf(b.Parent) &&
+		a.WheelchairBoarding == b.WheelchairBoarding &&
+		a.PlatformCode == b.PlatformCode
+}
+
+func tripsEqual(a, b gtfs.ScheduledTrip) bool {
+	if routeIDOf(a.Route) != routeIDOf(b.Route) ||
+		serviceIDOf(a.Service) != serviceIDOf(b.Service) ||
+		a.Headsign != b.Headsign ||
+		a.DirectionId != b.DirectionId ||
+		a.BlockID != b.BlockID ||
+		shapeIDOf(a.Shape) != shapeIDOf(b.Shape) ||
+		len(a.StopTimes) != len(b.StopTimes) {
+		return false
+	}
+	for i := range a.StopTimes {
+		x, y := a.StopTimes[i], b.StopTimes[i]
+		if stopIDOf(x.Stop) != stopIDOf(y.Stop) ||
+			x.StopSequence != y.StopSequence ||
+			x.ArrivalTime != y.ArrivalTime ||
+			x.DepartureTime != y.DepartureTime {
+			return false
+		}
+	}
+	return true
+}
+
+func floatPtrEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func agencyIDOf(a *gtfs.Agency) string {
+	if a == nil {
+		return ""
+	}
+	return a.Id
+}
+
+func routeIDOf(r *gtfs.Route) string {
+	if r == nil {
+		return ""
+	}
+	return r.Id
+}
+
+func serviceIDOf(s *gtfs.Service) string {
+	if s == nil {
+		return ""
+	}
+	return s.Id
+}
+
+func shapeIDOf(s *gtfs.Shape) string {
+	if s == nil {
+		return ""
+	}
+	return s.ID
+}
+
+func stopIDOf(s *gtfs.Stop) string {
+	if s == nil {
+		return ""
+	}
+	return s.Id
+}
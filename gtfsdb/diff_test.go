@@ -0,0 +1,79 @@
+package gtfsdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDiffTestFeed builds a feed with routes r1 and r2, stops s1 and s2, and
+// trips t1 and t2 on r1 and t3 on r2.
+func newDiffTestFeed() *gtfs.Static {
+	static := &gtfs.Static{
+		Agencies: []gtfs.Agency{{Id: "a1"}},
+		Stops:    []gtfs.Stop{{Id: "s1", Name: "First"}, {Id: "s2", Name: "Second"}},
+		Services: []gtfs.Service{{Id: "weekday"}},
+	}
+	static.Routes = []gtfs.Route{
+		{Id: "r1", Agency: &static.Agencies[0], ShortName: "1"},
+		{Id: "r2", Agency: &static.Agencies[0], ShortName: "2"},
+	}
+	trip := func(id string, route *gtfs.Route) gtfs.ScheduledTrip {
+		return gtfs.ScheduledTrip{
+			ID:      id,
+			Route:   route,
+			Service: &static.Services[0],
+			StopTimes: []gtfs.ScheduledStopTime{
+				{Stop: &static.Stops[0], StopSequence: 1, ArrivalTime: 8 * time.Hour, DepartureTime: 8 * time.Hour},
+				{Stop: &static.Stops[1], StopSequence: 2, ArrivalTime: 8*time.Hour + 10*time.Minute, DepartureTime: 8*time.Hour + 10*time.Minute},
+			},
+		}
+	}
+	static.Trips = []gtfs.ScheduledTrip{
+		trip("t1", &static.Routes[0]),
+		trip("t2", &static.Routes[0]),
+		trip("t3", &static.Routes[1]),
+	}
+	return static
+}
+
+func TestDiffGtfsData_Identical(t *testing.T) {
+	diff := DiffGtfsData(newDiffTestFeed(), newDiffTestFeed())
+
+	assert.True(t, diff.Empty())
+	assert.Equal(t, []string{}, diff.Trips.Added, "empty lists encode as [] rather than null")
+	assert.Equal(t, []RouteServiceLevel{}, diff.ServiceLevels)
+}
+
+func TestDiffGtfsData_ReportsChanges(t *testing.T) {
+	old := newDiffTestFeed()
+	updated := newDiffTestFeed()
+	// r2 and its trip are withdrawn, a new stop is added, s1 is renamed and
+	// t1 runs five minutes later.
+	updated.Routes = updated.Routes[:1]
+	updated.Trips = updated.Trips[:2]
+	updated.Stops = append(updated.Stops, gtfs.Stop{Id: "s3"})
+	updated.Stops[0].Name = "First Avenue"
+	updated.Trips[0].StopTimes[1].ArrivalTime += 5 * time.Minute
+
+	diff := DiffGtfsData(old, updated)
+
+	assert.Equal(t, EntityDiff{Added: []string{}, Removed: []string{"r2"}, Changed: []string{}}, diff.Routes)
+	assert.Equal(t, EntityDiff{Added: []string{"s3"}, Removed: []string{}, Changed: []string{"s1"}}, diff.Stops)
+	assert.Equal(t, EntityDiff{Added: []string{}, Removed: []string{"t3"}, Changed: []string{"t1"}}, diff.Trips)
+	assert.Equal(t, []RouteServiceLevel{{RouteID: "r2", OldTrips: 1, NewTrips: 0}}, diff.ServiceLevels)
+	assert.False(t, diff.Empty())
+}
+
+func TestDiffGtfsData_NewRouteServiceLevel(t *testing.T) {
+	old := newDiffTestFeed()
+	old.Routes = old.Routes[:1]
+	old.Trips = old.Trips[:2]
+
+	diff := DiffGtfsData(old, newDiffTestFeed())
+
+	assert.Equal(t, []string{"r2"}, diff.Routes.Added)
+	assert.Equal(t, []RouteServiceLevel{{RouteID: "r2", OldTrips: 0, NewTrips: 1}}, diff.ServiceLevels)
+}
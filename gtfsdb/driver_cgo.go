@@ -2,6 +2,23 @@
 
 package gtfsdb
 
-import _ "github.com/mattn/go-sqlite3" // CGo-based SQLite driver
+import (
+	"database/sql"
+
+	"github.com/mattn/go-sqlite3" // CGo-based SQLite driver
+)
 
 const DriverName = "sqlite3"
+
+// openEncryptedDB opens dbPath with the SQLCipher key applied to every new
+// connection in the pool.
+func openEncryptedDB(dbPath, key string) (*sql.DB, error) {
+	pragma := encryptionKeyPragma(key)
+	drv := &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec(pragma, nil)
+			return err
+		},
+	}
+	return sql.OpenDB(dsnConnector{dsn: dbPath, driver: drv}), nil
+}
@@ -2,6 +2,15 @@
 
 package gtfsdb
 
-import _ "modernc.org/sqlite" // Pure Go SQLite driver
+import (
+	"database/sql"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+)
 
 const DriverName = "sqlite"
+
+// openEncryptedDB always fails: the pure Go driver cannot use SQLCipher.
+func openEncryptedDB(string, string) (*sql.DB, error) {
+	return nil, ErrEncryptionUnsupported
+}
@@ -0,0 +1,50 @@
+package gtfsdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEncryptionUnsupported is returned when an encryption key is configured
+// but the linked SQLite library is not SQLCipher, which would otherwise
+// silently ignore the key and write the database in plaintext.
+var ErrEncryptionUnsupported = errors.New("database encryption requires SQLite built with SQLCipher (build with the libsqlite3 tag against a SQLCipher-enabled libsqlite3)")
+
+// encryptionKeyPragma returns the statement that unlocks a SQLCipher
+// database. It must run on every connection before the database is read.
+func encryptionKeyPragma(key string) string {
+	return "PRAGMA key = '" + strings.ReplaceAll(key, "'", "''") + "'"
+}
+
+// verifyEncryption fails unless db is served by SQLCipher. Plain SQLite
+// returns no rows for the cipher_version pragma.
+func verifyEncryption(ctx context.Context, db *sql.DB) error {
+	var version string
+	err := db.QueryRowContext(ctx, "PRAGMA cipher_version").Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && version == "") {
+		return ErrEncryptionUnsupported
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check SQLCipher support: %w", err)
+	}
+	return nil
+}
+
+// dsnConnector opens connections to a fixed DSN with a preconfigured driver,
+// letting sql.OpenDB use a driver instance that was not globally registered.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,22 @@
+package gtfsdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+func TestEncryptionKeyPragma(t *testing.T) {
+	assert.Equal(t, "PRAGMA key = 'secret'", encryptionKeyPragma("secret"))
+	assert.Equal(t, "PRAGMA key = 'it''s'", encryptionKeyPragma("it's"))
+}
+
+func TestNewClient_EncryptionRequiresSQLCipher(t *testing.T) {
+	// The bundled SQLite is not SQLCipher, so the key must be rejected rather
+	// than silently ignored.
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test, EncryptionKey: "secret"})
+
+	assert.ErrorIs(t, err, ErrEncryptionUnsupported)
+	assert.Nil(t, client)
+}
@@ -0,0 +1,382 @@
+package gtfsdb
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+
+	"github.com/OneBusAway/go-gtfs"
+	"maglev.onebusaway.org/internal/logging"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+// FareData holds the fare files of a feed: fare_attributes.txt and
+// fare_rules.txt (Fares v1) and fare_media.txt, rider_categories.txt,
+// fare_products.txt, fare_leg_rules.txt, areas.txt and stop_areas.txt
+// (Fares v2). go-gtfs does not parse them, so they are read from the zip
+// directly, like FlexData.
+type FareData struct {
+	Attributes      []CreateFareAttributeParams
+	Rules           []CreateFareRuleParams
+	Media           []CreateFareMediaParams
+	RiderCategories []CreateRiderCategoryParams
+	Products        []CreateFareProductParams
+	LegRules        []CreateFareLegRuleParams
+	Areas           []CreateAreaParams
+	StopAreas       []CreateStopAreaParams
+	// RouteNetworks assigns routes to the networks leg rules refer to, from
+	// routes.txt network_id and route_networks.txt.
+	RouteNetworks []CreateRouteNetworkParams
+}
+
+// parseFareData reads the fare files from a GTFS zip. It returns nil when the
+// feed has neither fare_attributes.txt nor fare_products.txt.
+func parseFareData(b []byte) (*FareData, error) {
+	reader, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("error opening GTFS zip: %w", err)
+	}
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[path.Base(f.Name)] = f
+	}
+	if files["fare_attributes.txt"] == nil && files["fare_products.txt"] == nil {
+		return nil, nil
+	}
+
+	fares := &FareData{}
+
+	if err := readGtfsCSV(files["fare_attributes.txt"], func(row gtfsCSVRow) error {
+		id := row.get("fare_id")
+		price, err := strconv.ParseFloat(row.get("price"), 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("fare %q: invalid price %q", id, row.get("price"))
+		}
+		paymentMethod, err := strconv.ParseInt(row.get("payment_method"), 10, 64)
+		if err != nil || paymentMethod < 0 || paymentMethod > 1 {
+			return fmt.Errorf("fare %q: invalid payment_method %q", id, row.get("payment_method"))
+		}
+		// An empty transfers field means unlimited transfers.
+		transfers := row.nullInt("transfers")
+		if transfers.Valid && (transfers.Int64 < 0 || transfers.Int64 > 2) {
+			return fmt.Errorf("fare %q: invalid transfers %q", id, row.get("transfers"))
+		}
+		fares.Attributes = append(fares.Attributes, CreateFareAttributeParams{
+			ID:               id,
+			Price:            price,
+			CurrencyType:     row.get("currency_type"),
+			PaymentMethod:    paymentMethod,
+			Transfers:        transfers,
+			AgencyID:         nulls.NonEmptyString(row.get("agency_id")),
+			TransferDuration: row.nullInt("transfer_duration"),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("fare_attributes.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["fare_rules.txt"], func(row gtfsCSVRow) error {
+		fares.Rules = append(fares.Rules, CreateFareRuleParams{
+			FareID:        row.get("fare_id"),
+			RouteID:       nulls.NonEmptyString(row.get("route_id")),
+			OriginID:      nulls.NonEmptyString(row.get("origin_id")),
+			DestinationID: nulls.NonEmptyString(row.get("destination_id")),
+			ContainsID:    nulls.NonEmptyString(row.get("contains_id")),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("fare_rules.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["fare_media.txt"], func(row gtfsCSVRow) error {
+		mediaType, err := strconv.ParseInt(row.get("fare_media_type"), 10, 64)
+		if err != nil || mediaType < 0 || mediaType > 4 {
+			return fmt.Errorf("fare media %q: invalid fare_media_type %q", row.get("fare_media_id"), row.get("fare_media_type"))
+		}
+		fares.Media = append(fares.Media, CreateFareMediaParams{
+			ID:            row.get("fare_media_id"),
+			Name:          nulls.NonEmptyString(row.get("fare_media_name")),
+			FareMediaType: mediaType,
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("fare_media.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["rider_categories.txt"], func(row gtfsCSVRow) error {
+		id := row.get("rider_category_id")
+		isDefault := row.nullInt("is_default_fare_category")
+		if isDefault.Int64 < 0 || isDefault.Int64 > 1 {
+			return fmt.Errorf("rider category %q: invalid is_default_fare_category %q", id, row.get("is_default_fare_category"))
+		}
+		fares.RiderCategories = append(fares.RiderCategories, CreateRiderCategoryParams{
+			ID:                    id,
+			Name:                  row.get("rider_category_name"),
+			IsDefaultFareCategory: isDefault.Int64,
+			EligibilityUrl:        nulls.NonEmptyString(row.get("eligibility_url")),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("rider_categories.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["fare_products.txt"], func(row gtfsCSVRow) error {
+		amount, err := strconv.ParseFloat(row.get("amount"), 64)
+		if err != nil {
+			return fmt.Errorf("fare product %q: invalid amount %q", row.get("fare_product_id"), row.get("amount"))
+		}
+		fares.Products = append(fares.Products, CreateFareProductParams{
+			FareProductID:   row.get("fare_product_id"),
+			FareMediaID:     row.get("fare_media_id"),
+			RiderCategoryID: row.get("rider_category_id"),
+			Name:            nulls.NonEmptyString(row.get("fare_product_name")),
+			Amount:          amount,
+			Currency:        row.get("currency"),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("fare_products.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["fare_leg_rules.txt"], func(row gtfsCSVRow) error {
+		fares.LegRules = append(fares.LegRules, CreateFareLegRuleParams{
+			LegGroupID:    nulls.NonEmptyString(row.get("leg_group_id")),
+			NetworkID:     nulls.NonEmptyString(row.get("network_id")),
+			FromAreaID:    nulls.NonEmptyString(row.get("from_area_id")),
+			ToAreaID:      nulls.NonEmptyString(row.get("to_area_id")),
+			FareProductID: row.get("fare_product_id"),
+			RulePriority:  row.nullInt("rule_priority").Int64,
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("fare_leg_rules.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["areas.txt"], func(row gtfsCSVRow) error {
+		fares.Areas = append(fares.Areas, CreateAreaParams{
+			ID:   row.get("area_id"),
+			Name: nulls.NonEmptyString(row.get("area_name")),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("areas.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["stop_areas.txt"], func(row gtfsCSVRow) error {
+		fares.StopAreas = append(fares.StopAreas, CreateStopAreaParams{
+			AreaID: row.get("area_id"),
+			StopID: row.get("stop_id"),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("stop_areas.txt: %w", err)
+	}
+
+	if len(fares.LegRules) > 0 {
+		networkRow := func(row gtfsCSVRow) error {
+			if network := row.get("network_id"); network != "" {
+				fares.RouteNetworks = append(fares.RouteNetworks, CreateRouteNetworkParams{
+					RouteID:   row.get("route_id"),
+					NetworkID: network,
+				})
+			}
+			return nil
+		}
+		if err := readGtfsCSV(files["routes.txt"], networkRow); err != nil {
+			return nil, fmt.Errorf("routes.txt: %w", err)
+		}
+		if err := readGtfsCSV(files["route_networks.txt"], networkRow); err != nil {
+			return nil, fmt.Errorf("route_networks.txt: %w", err)
+		}
+	}
+
+	return fares, nil
+}
+
+// insertFareData stores the fare rows of a feed. Rules, products and stop
+// areas are only kept when the fares, fare products, rider categories, areas,
+// routes and stops they reference were imported, mirroring how the rest of
+// the import drops dangling rows.
+func insertFareData(ctx context.Context, q *Queries, fares *FareData, static *gtfs.Static) error {
+	knownRoutes := make(map[string]struct{}, len(static.Routes))
+	for _, route := range static.Routes {
+		knownRoutes[route.Id] = struct{}{}
+	}
+	knownStops := make(map[string]struct{}, len(static.Stops))
+	for _, stop := range static.Stops {
+		knownStops[stop.Id] = struct{}{}
+	}
+	knownFares := make(map[string]struct{}, len(fares.Attributes))
+	knownProducts := make(map[string]struct{}, len(fares.Products))
+	knownRiderCategories := make(map[string]struct{}, len(fares.RiderCategories))
+	knownAreas := make(map[string]struct{}, len(fares.Areas))
+
+	for _, params := range fares.Attributes {
+		if err := q.CreateFareAttribute(ctx, params); err != nil {
+			return fmt.Errorf("fare %q: %w", params.ID, err)
+		}
+		knownFares[params.ID] = struct{}{}
+	}
+	for _, params := range fares.Rules {
+		_, fareOK := knownFares[params.FareID]
+		_, routeOK := knownRoutes[params.RouteID.String]
+		if !fareOK || (params.RouteID.Valid && !routeOK) {
+			continue
+		}
+		if err := q.CreateFareRule(ctx, params); err != nil {
+			return fmt.Errorf("fare rule for %q: %w", params.FareID, err)
+		}
+	}
+	for _, params := range fares.Media {
+		if err := q.CreateFareMedia(ctx, params); err != nil {
+			return fmt.Errorf("fare media %q: %w", params.ID, err)
+		}
+	}
+	for _, params := range fares.RiderCategories {
+		if err := q.CreateRiderCategory(ctx, params); err != nil {
+			return fmt.Errorf("rider category %q: %w", params.ID, err)
+		}
+		knownRiderCategories[params.ID] = struct{}{}
+	}
+	for _, params := range fares.Products {
+		if _, ok := knownRiderCategories[params.RiderCategoryID]; params.RiderCategoryID != "" && !ok {
+			continue
+		}
+		if err := q.CreateFareProduct(ctx, params); err != nil {
+			return fmt.Errorf("fare product %q: %w", params.FareProductID, err)
+		}
+		knownProducts[params.FareProductID] = struct{}{}
+	}
+	for _, params := range fares.LegRules {
+		if _, ok := knownProducts[params.FareProductID]; !ok {
+			continue
+		}
+		if err := q.CreateFareLegRule(ctx, params); err != nil {
+			return fmt.Errorf("fare leg rule for %q: %w", params.FareProductID, err)
+		}
+	}
+	for _, params := range fares.RouteNetworks {
+		if _, ok := knownRoutes[params.RouteID]; !ok {
+			continue
+		}
+		if err := q.CreateRouteNetwork(ctx, params); err != nil {
+			return fmt.Errorf("route network %q/%q: %w", params.RouteID, params.NetworkID, err)
+		}
+	}
+	for _, params := range fares.Areas {
+		if err := q.CreateArea(ctx, params); err != nil {
+			return fmt.Errorf("area %q: %w", params.ID, err)
+		}
+		knownAreas[params.ID] = struct{}{}
+	}
+	for _, params := range fares.StopAreas {
+		_, areaOK := knownAreas[params.AreaID]
+		_, stopOK := knownStops[params.StopID]
+		if !areaOK || !stopOK {
+			continue
+		}
+		if err := q.CreateStopArea(ctx, params); err != nil {
+			return fmt.Errorf("stop area %q/%q: %w", params.AreaID, params.StopID, err)
+		}
+	}
+
+	logging.LogOperation(slog.Default().With(slog.String("component", "gtfs_importer")), "fare_data_inserted",
+		slog.Int("fare_attributes", len(fares.Attributes)),
+		slog.Int("fare_rules", len(fares.Rules)),
+		slog.Int("fare_products", len(fares.Products)),
+		slog.Int("fare_leg_rules", len(fares.LegRules)),
+		slog.Int("rider_categories", len(fares.RiderCategories)),
+		slog.Int("stop_areas", len(fares.StopAreas)))
+	return nil
+}
+
+// MatchFareLegRules returns the leg rules that price a leg on a route in the
+// given networks from a stop in fromAreas to a stop in toAreas. As in Fares
+// v2, a rule without a network or area matches only legs whose networks or
+// areas no rule names, and of the matching rules only those with the highest
+// rule_priority apply. rules must be all of the feed's leg rules.
+func MatchFareLegRules(rules []FareLegRule, networks, fromAreas, toAreas []string) []FareLegRule {
+	named := func(field func(FareLegRule) sql.NullString, values []string) bool {
+		for _, rule := range rules {
+			if v := field(rule); v.Valid && slices.Contains(values, v.String) {
+				return true
+			}
+		}
+		return false
+	}
+	matcher := func(field func(FareLegRule) sql.NullString, values []string) func(FareLegRule) bool {
+		valuesNamed := named(field, values)
+		return func(rule FareLegRule) bool {
+			v := field(rule)
+			if !v.Valid {
+				return !valuesNamed
+			}
+			return slices.Contains(values, v.String)
+		}
+	}
+	networkMatches := matcher(func(r FareLegRule) sql.NullString { return r.NetworkID }, networks)
+	fromMatches := matcher(func(r FareLegRule) sql.NullString { return r.FromAreaID }, fromAreas)
+	toMatches := matcher(func(r FareLegRule) sql.NullString { return r.ToAreaID }, toAreas)
+
+	var matched []FareLegRule
+	for _, rule := range rules {
+		if networkMatches(rule) && fromMatches(rule) && toMatches(rule) {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	highest := slices.MaxFunc(matched, func(a, b FareLegRule) int {
+		return cmp.Compare(a.RulePriority, b.RulePriority)
+	}).RulePriority
+	return slices.DeleteFunc(matched, func(rule FareLegRule) bool {
+		return rule.RulePriority != highest
+	})
+}
+
+// migrateFareProductsRiderCategory rebuilds a fare_products table created
+// before rider categories: the column is part of the primary key, which ALTER
+// TABLE cannot change. Existing rows are kept without a rider category.
+func migrateFareProductsRiderCategory(ctx context.Context, db *sql.DB) error {
+	var columns, riderCategoryColumns int
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE name = 'rider_category_id') FROM pragma_table_info('fare_products')`,
+	).Scan(&columns, &riderCategoryColumns)
+	if err != nil || columns == 0 || riderCategoryColumns > 0 {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer logging.SafeRollbackWithLogging(tx, slog.Default().With(slog.String("component", "migration")), "fare_products")
+	for _, stmt := range []string{
+		`ALTER TABLE fare_products RENAME TO fare_products_old`,
+		`CREATE TABLE fare_products (
+			fare_product_id TEXT NOT NULL,
+			fare_media_id TEXT NOT NULL DEFAULT '',
+			rider_category_id TEXT NOT NULL DEFAULT '',
+			name TEXT,
+			amount REAL NOT NULL,
+			currency TEXT NOT NULL,
+			PRIMARY KEY (fare_product_id, rider_category_id, fare_media_id)
+		) STRICT`,
+		`INSERT INTO fare_products (fare_product_id, fare_media_id, name, amount, currency)
+			SELECT fare_product_id, fare_media_id, name, amount, currency FROM fare_products_old`,
+		`DROP TABLE fare_products_old`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("error migrating fare_products: %w", err)
+		}
+	}
+	return tx.Commit()
+}
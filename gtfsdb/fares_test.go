@@ -0,0 +1,245 @@
+package gtfsdb
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+// buildSyntheticFareGTFSZip returns a minimal feed with a local and an
+// express route priced with both Fares v1 and Fares v2. The local route joins
+// its network through routes.txt, the express route through
+// route_networks.txt.
+func buildSyntheticFareGTFSZip(t *testing.T) []byte {
+	t.Helper()
+
+	files := map[string]string{
+		"agency.txt": "agency_id,agency_name,agency_url,agency_timezone\n" +
+			"agency_1,Synthetic Transit,http://example.com,America/Los_Angeles\n",
+		"routes.txt": "route_id,agency_id,route_short_name,route_long_name,route_type,network_id\n" +
+			"route_local,agency_1,L,Local,3,local\n" +
+			"route_express,agency_1,X,Express,3,\n",
+		"route_networks.txt": "network_id,route_id\n" +
+			"express,route_express\n",
+		"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+			"service_1,1,1,1,1,1,0,0,20240101,20251231\n",
+		"stops.txt": "stop_id,stop_name,stop_lat,stop_lon,zone_id\n" +
+			"stop_1,First Stop,37.7749,-122.4194,zone_1\n" +
+			"stop_2,Second Stop,37.7849,-122.4094,zone_2\n",
+		"trips.txt": "route_id,service_id,trip_id\n" +
+			"route_local,service_1,trip_local\n" +
+			"route_express,service_1,trip_express\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"trip_local,06:00:00,06:00:00,stop_1,1\n" +
+			"trip_local,06:10:00,06:10:00,stop_2,2\n" +
+			"trip_express,07:00:00,07:00:00,stop_1,1\n" +
+			"trip_express,07:05:00,07:05:00,stop_2,2\n",
+		"fare_attributes.txt": "fare_id,price,currency_type,payment_method,transfers,agency_id,transfer_duration\n" +
+			"local,2.50,USD,0,,agency_1,5400\n" +
+			"express,4.00,USD,1,0,agency_1,\n" +
+			"cross_zone,1.00,USD,0,1,agency_1,\n",
+		"fare_rules.txt": "fare_id,route_id,origin_id,destination_id,contains_id\n" +
+			"local,route_local,,,\n" +
+			"express,route_express,,,\n" +
+			"express,route_missing,,,\n" +
+			"cross_zone,,zone_1,zone_2,\n",
+		"fare_media.txt": "fare_media_id,fare_media_name,fare_media_type\n" +
+			"cash,Cash,0\n" +
+			"card,Transit Card,2\n",
+		"rider_categories.txt": "rider_category_id,rider_category_name,is_default_fare_category,eligibility_url\n" +
+			"adult,Adult,1,\n" +
+			"senior,Senior,0,https://example.com/senior\n",
+		"fare_products.txt": "fare_product_id,fare_product_name,fare_media_id,amount,currency,rider_category_id\n" +
+			"single_ride,Single Ride,cash,2.50,USD,\n" +
+			"single_ride,Single Ride,card,2.25,USD,\n" +
+			"single_ride,Single Ride,cash,1.25,USD,senior\n" +
+			"single_ride,Single Ride,cash,0.50,USD,missing_category\n" +
+			"express_ride,Express Ride,,4.00,USD,\n" +
+			"day_pass,Day Pass,card,6.00,USD,\n",
+		"fare_leg_rules.txt": "leg_group_id,network_id,from_area_id,to_area_id,fare_product_id,rule_priority\n" +
+			"local_leg,local,,,single_ride,\n" +
+			"express_leg,express,,,express_ride,1\n" +
+			"any_leg,,,,day_pass,\n" +
+			"orphan_leg,local,,,missing_product,\n" +
+			"downtown_leg,local,downtown,,single_ride,\n",
+		"areas.txt": "area_id,area_name\n" +
+			"downtown,Downtown\n",
+		"stop_areas.txt": "area_id,stop_id\n" +
+			"downtown,stop_1\n" +
+			"downtown,stop_missing\n",
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParseFareData(t *testing.T) {
+	fares, err := parseFareData(buildSyntheticFareGTFSZip(t))
+	require.NoError(t, err)
+	require.NotNil(t, fares)
+
+	require.Len(t, fares.Attributes, 3)
+	local := fares.Attributes[0]
+	assert.Equal(t, 2.5, local.Price)
+	assert.False(t, local.Transfers.Valid, "an empty transfers field means unlimited")
+	assert.Equal(t, int64(5400), local.TransferDuration.Int64)
+	assert.Equal(t, int64(0), fares.Attributes[1].Transfers.Int64)
+	assert.Len(t, fares.Rules, 4, "unknown routes are filtered at import, not parse")
+
+	assert.Len(t, fares.Media, 2)
+	require.Len(t, fares.RiderCategories, 2)
+	assert.Equal(t, int64(1), fares.RiderCategories[0].IsDefaultFareCategory)
+	assert.Equal(t, "https://example.com/senior", fares.RiderCategories[1].EligibilityUrl.String)
+	require.Len(t, fares.Products, 6)
+	assert.Equal(t, "senior", fares.Products[2].RiderCategoryID)
+	assert.Empty(t, fares.Products[4].FareMediaID)
+	require.Len(t, fares.LegRules, 5)
+	assert.Equal(t, "downtown", fares.LegRules[4].FromAreaID.String)
+	assert.Equal(t, []CreateAreaParams{{ID: "downtown", Name: sql.NullString{String: "Downtown", Valid: true}}}, fares.Areas)
+	assert.Len(t, fares.StopAreas, 2, "unknown stops are filtered at import, not parse")
+	assert.Equal(t, int64(1), fares.LegRules[1].RulePriority)
+	assert.ElementsMatch(t, []CreateRouteNetworkParams{
+		{RouteID: "route_local", NetworkID: "local"},
+		{RouteID: "route_express", NetworkID: "express"},
+	}, fares.RouteNetworks)
+}
+
+func TestParseFareData_NoFareFiles(t *testing.T) {
+	fares, err := parseFareData(buildSyntheticGTFSZip(t, false))
+	require.NoError(t, err)
+	assert.Nil(t, fares)
+}
+
+func TestParseFareData_InvalidPrice(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("fare_attributes.txt")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("fare_id,price,currency_type,payment_method\nbad,free,USD,0\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = parseFareData(buf.Bytes())
+	assert.ErrorContains(t, err, "invalid price")
+}
+
+func TestSyntheticGTFS_FareIngestion(t *testing.T) {
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	ctx := t.Context()
+	parsed, err := ParseGtfsData(buildSyntheticFareGTFSZip(t), "synthetic-fares")
+	require.NoError(t, err)
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+
+	agency := sql.NullString{String: "agency_1", Valid: true}
+	route := sql.NullString{String: "route_local", Valid: true}
+	fares, err := client.Queries.GetFareAttributesForRoute(ctx, GetFareAttributesForRouteParams{AgencyID: agency, RouteID: route})
+	require.NoError(t, err)
+	require.Len(t, fares, 2, "the route's own fare and the zone fare")
+	assert.Equal(t, "cross_zone", fares[0].ID)
+	assert.Equal(t, "local", fares[1].ID)
+
+	rules, err := client.Queries.GetFareRulesForRoute(ctx, route)
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	var count int
+	require.NoError(t, client.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM fare_rules").Scan(&count))
+	assert.Equal(t, 3, count, "rules for unknown routes are dropped")
+
+	legRules, err := client.Queries.GetFareLegRulesForRoute(ctx, "route_express")
+	require.NoError(t, err)
+	require.Len(t, legRules, 2)
+	assert.Equal(t, "express_ride", legRules[0].FareProductID, "higher priority first")
+	assert.Equal(t, "day_pass", legRules[1].FareProductID)
+
+	legRules, err = client.Queries.GetFareLegRulesForRoute(ctx, "route_local")
+	require.NoError(t, err)
+	require.Len(t, legRules, 3, "rules for unknown fare products are dropped")
+
+	products, err := client.Queries.GetFareProductsByIDs(ctx, []string{"single_ride"})
+	require.NoError(t, err)
+	require.Len(t, products, 3, "one row per fare media and rider category, unknown categories dropped")
+	assert.Equal(t, "card", products[0].FareMediaID)
+	assert.Equal(t, "senior", products[2].RiderCategoryID)
+
+	categories, err := client.Queries.GetRiderCategoriesByIDs(ctx, []string{"senior"})
+	require.NoError(t, err)
+	require.Len(t, categories, 1)
+	assert.Equal(t, "Senior", categories[0].Name)
+
+	areas, err := client.Queries.GetAreasForStop(ctx, "stop_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"downtown"}, areas)
+	require.NoError(t, client.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM stop_areas").Scan(&count))
+	assert.Equal(t, 1, count, "stop areas for unknown stops are dropped")
+
+	// Reimporting a feed without fare files clears the fare tables.
+	parsed, err = ParseGtfsData(buildSyntheticGTFSZip(t, false), "synthetic-fares")
+	require.NoError(t, err)
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+
+	products, err = client.Queries.GetFareProductsByIDs(ctx, []string{"single_ride", "day_pass"})
+	require.NoError(t, err)
+	assert.Empty(t, products)
+	areas, err = client.Queries.GetAreasForStop(ctx, "stop_1")
+	require.NoError(t, err)
+	assert.Empty(t, areas)
+}
+
+func TestMatchFareLegRules(t *testing.T) {
+	str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	rule := func(id int64, network, from, to string, priority int64) FareLegRule {
+		return FareLegRule{ID: id, NetworkID: str(network), FromAreaID: str(from), ToAreaID: str(to), FareProductID: "p", RulePriority: priority}
+	}
+	rules := []FareLegRule{
+		rule(1, "local", "", "", 0),
+		rule(2, "local", "downtown", "airport", 0),
+		rule(3, "", "", "", 0),
+		rule(4, "express", "", "", 5),
+		rule(5, "express", "", "", 1),
+	}
+	ids := func(matched []FareLegRule) []int64 {
+		var ids []int64
+		for _, r := range matched {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name               string
+		networks           []string
+		fromAreas, toAreas []string
+		want               []int64
+	}{
+		{"network without areas", []string{"local"}, nil, nil, []int64{1}},
+		{"named areas exclude the empty area rule", []string{"local"}, []string{"downtown"}, []string{"airport"}, []int64{2}},
+		{"one named area is not enough", []string{"local"}, []string{"downtown"}, nil, nil},
+		{"unnamed network matches empty network", []string{"ferry"}, nil, nil, []int64{3}},
+		{"no network matches empty network", nil, nil, nil, []int64{3}},
+		{"highest priority wins", []string{"express"}, nil, nil, []int64{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(MatchFareLegRules(rules, tt.networks, tt.fromAreas, tt.toAreas)))
+		})
+	}
+}
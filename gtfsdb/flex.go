@@ -0,0 +1,295 @@
+package gtfsdb
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"maglev.onebusaway.org/internal/logging"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+// FlexData holds the GTFS-Flex extension files of a feed. go-gtfs does not
+// parse them, so they are read from the zip directly. Rows are kept as they
+// appear in the feed; references to unknown stops, trips or routes are
+// dropped at import time.
+type FlexData struct {
+	BookingRules       []CreateBookingRuleParams
+	LocationGroups     []CreateLocationGroupParams
+	LocationGroupStops []CreateLocationGroupStopParams
+	StopTimes          []CreateFlexStopTimeParams
+}
+
+// parseFlexData reads the GTFS-Flex files from a GTFS zip. It returns nil when
+// the feed has neither booking_rules.txt nor location_groups.txt, so regular
+// feeds skip the extra pass over stop_times.txt.
+func parseFlexData(b []byte) (*FlexData, error) {
+	reader, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("error opening GTFS zip: %w", err)
+	}
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[path.Base(f.Name)] = f
+	}
+	if files["booking_rules.txt"] == nil && files["location_groups.txt"] == nil {
+		return nil, nil
+	}
+
+	flex := &FlexData{}
+
+	if err := readGtfsCSV(files["booking_rules.txt"], func(row gtfsCSVRow) error {
+		bookingType, err := strconv.ParseInt(row.get("booking_type"), 10, 64)
+		if err != nil || bookingType < 0 || bookingType > 2 {
+			return fmt.Errorf("booking rule %q: invalid booking_type %q", row.get("booking_rule_id"), row.get("booking_type"))
+		}
+		flex.BookingRules = append(flex.BookingRules, CreateBookingRuleParams{
+			ID:                     row.get("booking_rule_id"),
+			BookingType:            bookingType,
+			PriorNoticeDurationMin: row.nullInt("prior_notice_duration_min"),
+			PriorNoticeDurationMax: row.nullInt("prior_notice_duration_max"),
+			PriorNoticeLastDay:     row.nullInt("prior_notice_last_day"),
+			PriorNoticeLastTime:    nulls.NonEmptyString(row.get("prior_notice_last_time")),
+			PriorNoticeStartDay:    row.nullInt("prior_notice_start_day"),
+			PriorNoticeStartTime:   nulls.NonEmptyString(row.get("prior_notice_start_time")),
+			PriorNoticeServiceID:   nulls.NonEmptyString(row.get("prior_notice_service_id")),
+			Message:                nulls.NonEmptyString(row.get("message")),
+			PickupMessage:          nulls.NonEmptyString(row.get("pickup_message")),
+			DropOffMessage:         nulls.NonEmptyString(row.get("drop_off_message")),
+			PhoneNumber:            nulls.NonEmptyString(row.get("phone_number")),
+			InfoUrl:                nulls.NonEmptyString(row.get("info_url")),
+			BookingUrl:             nulls.NonEmptyString(row.get("booking_url")),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("booking_rules.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["location_groups.txt"], func(row gtfsCSVRow) error {
+		flex.LocationGroups = append(flex.LocationGroups, CreateLocationGroupParams{
+			ID:   row.get("location_group_id"),
+			Name: nulls.NonEmptyString(row.get("location_group_name")),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("location_groups.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["location_group_stops.txt"], func(row gtfsCSVRow) error {
+		flex.LocationGroupStops = append(flex.LocationGroupStops, CreateLocationGroupStopParams{
+			LocationGroupID: row.get("location_group_id"),
+			StopID:          row.get("stop_id"),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("location_group_stops.txt: %w", err)
+	}
+
+	routeByTrip := make(map[string]string)
+	if err := readGtfsCSV(files["trips.txt"], func(row gtfsCSVRow) error {
+		routeByTrip[row.get("trip_id")] = row.get("route_id")
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("trips.txt: %w", err)
+	}
+
+	if err := readGtfsCSV(files["stop_times.txt"], func(row gtfsCSVRow) error {
+		stopTime, ok, err := flexStopTimeFromRow(row, routeByTrip)
+		if ok {
+			flex.StopTimes = append(flex.StopTimes, stopTime)
+		}
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("stop_times.txt: %w", err)
+	}
+
+	return flex, nil
+}
+
+// insertFlexData stores the GTFS-Flex rows of a feed. Location group members
+// and flex stop times are only kept when the stops and routes they reference
+// were imported, mirroring how the rest of the import drops dangling rows.
+func insertFlexData(ctx context.Context, q *Queries, flex *FlexData, static *gtfs.Static) error {
+	knownStops := make(map[string]struct{}, len(static.Stops))
+	for _, stop := range static.Stops {
+		knownStops[stop.Id] = struct{}{}
+	}
+	knownRoutes := make(map[string]struct{}, len(static.Routes))
+	for _, route := range static.Routes {
+		knownRoutes[route.Id] = struct{}{}
+	}
+	knownGroups := make(map[string]struct{}, len(flex.LocationGroups))
+
+	for _, params := range flex.BookingRules {
+		if err := q.CreateBookingRule(ctx, params); err != nil {
+			return fmt.Errorf("booking rule %q: %w", params.ID, err)
+		}
+	}
+	for _, params := range flex.LocationGroups {
+		if err := q.CreateLocationGroup(ctx, params); err != nil {
+			return fmt.Errorf("location group %q: %w", params.ID, err)
+		}
+		knownGroups[params.ID] = struct{}{}
+	}
+	for _, params := range flex.LocationGroupStops {
+		_, stopOK := knownStops[params.StopID]
+		_, groupOK := knownGroups[params.LocationGroupID]
+		if !stopOK || !groupOK {
+			continue
+		}
+		if err := q.CreateLocationGroupStop(ctx, params); err != nil {
+			return fmt.Errorf("location group stop %q/%q: %w", params.LocationGroupID, params.StopID, err)
+		}
+	}
+	for _, params := range flex.StopTimes {
+		if _, ok := knownRoutes[params.RouteID]; !ok {
+			continue
+		}
+		if err := q.CreateFlexStopTime(ctx, params); err != nil {
+			return fmt.Errorf("flex stop time %q/%d: %w", params.TripID, params.StopSequence, err)
+		}
+	}
+
+	logging.LogOperation(slog.Default().With(slog.String("component", "gtfs_importer")), "flex_data_inserted",
+		slog.Int("booking_rules", len(flex.BookingRules)),
+		slog.Int("location_groups", len(flex.LocationGroups)),
+		slog.Int("flex_stop_times", len(flex.StopTimes)))
+	return nil
+}
+
+// flexStopTimeFromRow extracts the GTFS-Flex fields of a stop_times.txt row.
+// ok is false for rows without any flex fields, which are plain scheduled stops.
+func flexStopTimeFromRow(row gtfsCSVRow, routeByTrip map[string]string) (CreateFlexStopTimeParams, bool, error) {
+	stopTime := CreateFlexStopTimeParams{
+		TripID:               row.get("trip_id"),
+		StopID:               nulls.NonEmptyString(row.get("stop_id")),
+		LocationGroupID:      nulls.NonEmptyString(row.get("location_group_id")),
+		PickupBookingRuleID:  nulls.NonEmptyString(row.get("pickup_booking_rule_id")),
+		DropOffBookingRuleID: nulls.NonEmptyString(row.get("drop_off_booking_rule_id")),
+	}
+	start, startOK := parseGtfsTime(row.get("start_pickup_drop_off_window"))
+	end, endOK := parseGtfsTime(row.get("end_pickup_drop_off_window"))
+	if startOK {
+		stopTime.StartPickupDropOffWindow = sql.NullInt64{Int64: int64(start), Valid: true}
+	}
+	if endOK {
+		stopTime.EndPickupDropOffWindow = sql.NullInt64{Int64: int64(end), Valid: true}
+	}
+
+	isFlex := stopTime.LocationGroupID.Valid || startOK || endOK ||
+		stopTime.PickupBookingRuleID.Valid || stopTime.DropOffBookingRuleID.Valid
+	if !isFlex || (!stopTime.StopID.Valid && !stopTime.LocationGroupID.Valid) {
+		return CreateFlexStopTimeParams{}, false, nil
+	}
+
+	routeID, ok := routeByTrip[stopTime.TripID]
+	if !ok {
+		// Dropped like any other stop time of an unknown trip.
+		return CreateFlexStopTimeParams{}, false, nil
+	}
+	stopTime.RouteID = routeID
+
+	sequence, err := strconv.ParseInt(row.get("stop_sequence"), 10, 64)
+	if err != nil {
+		return CreateFlexStopTimeParams{}, false, fmt.Errorf("trip %q: invalid stop_sequence %q", stopTime.TripID, row.get("stop_sequence"))
+	}
+	stopTime.StopSequence = sequence
+	return stopTime, true, nil
+}
+
+// parseGtfsTime parses a GTFS HH:MM:SS time, which may exceed 24:00:00, into
+// the duration since midnight used throughout the schema.
+func parseGtfsTime(s string) (time.Duration, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var fields [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, false
+		}
+		fields[i] = v
+	}
+	if fields[1] > 59 || fields[2] > 59 {
+		return 0, false
+	}
+	return time.Duration(fields[0])*time.Hour + time.Duration(fields[1])*time.Minute + time.Duration(fields[2])*time.Second, true
+}
+
+// gtfsCSVRow gives access to a CSV record by column name.
+type gtfsCSVRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r gtfsCSVRow) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r gtfsCSVRow) nullInt(column string) sql.NullInt64 {
+	v, err := strconv.ParseInt(r.get(column), 10, 64)
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: v, Valid: true}
+}
+
+// readGtfsCSV calls fn for every row of a GTFS CSV file. A nil file is treated
+// as empty, since every file read this way is optional.
+func readGtfsCSV(f *zip.File, fn func(gtfsCSVRow) error) error {
+	if f == nil {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	reader := csv.NewReader(rc)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(gtfsCSVRow{columns: columns, record: record}); err != nil {
+			return err
+		}
+	}
+}
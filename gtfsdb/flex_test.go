@@ -0,0 +1,177 @@
+package gtfsdb
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+// buildSyntheticFlexGTFSZip returns a minimal feed with one fixed-route trip
+// and one GTFS-Flex trip that serves stop_2 directly and stop_3 through a
+// location group.
+func buildSyntheticFlexGTFSZip(t *testing.T) []byte {
+	t.Helper()
+
+	files := map[string]string{
+		"agency.txt": "agency_id,agency_name,agency_url,agency_timezone\n" +
+			"agency_1,Synthetic Transit,http://example.com,America/Los_Angeles\n",
+		"routes.txt": "route_id,agency_id,route_short_name,route_long_name,route_type\n" +
+			"route_fixed,agency_1,F,Fixed Route,3\n" +
+			"route_flex,agency_1,D,Dial-a-Ride,3\n",
+		"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+			"service_1,1,1,1,1,1,0,0,20240101,20251231\n",
+		"stops.txt": "stop_id,stop_name,stop_lat,stop_lon\n" +
+			"stop_1,First Stop,37.7749,-122.4194\n" +
+			"stop_2,Second Stop,37.7849,-122.4094\n" +
+			"stop_3,Third Stop,37.7949,-122.3994\n",
+		"trips.txt": "route_id,service_id,trip_id\n" +
+			"route_fixed,service_1,trip_fixed\n" +
+			"route_flex,service_1,trip_flex\n",
+		"stop_times.txt": "\uFEFFtrip_id,arrival_time,departure_time,stop_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_booking_rule_id,drop_off_booking_rule_id\n" +
+			"trip_fixed,06:00:00,06:00:00,stop_1,1,,,,\n" +
+			"trip_fixed,06:10:00,06:10:00,stop_2,2,,,,\n" +
+			"trip_flex,08:00:00,08:00:00,stop_2,1,08:00:00,25:30:00,same_day,\n" +
+			"trip_flex,09:00:00,09:00:00,stop_3,2,,,,same_day\n",
+		"booking_rules.txt": "booking_rule_id,booking_type,prior_notice_duration_min,message,phone_number,booking_url\n" +
+			"same_day,1,60,\"Call at least an hour ahead, please\",555-0100,https://example.com/book\n",
+		"location_groups.txt": "location_group_id,location_group_name\n" +
+			"zone_a,Zone A\n",
+		"location_group_stops.txt": "location_group_id,stop_id\n" +
+			"zone_a,stop_3\n" +
+			"zone_a,stop_missing\n",
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParseFlexData(t *testing.T) {
+	flex, err := parseFlexData(buildSyntheticFlexGTFSZip(t))
+	require.NoError(t, err)
+	require.NotNil(t, flex)
+
+	require.Len(t, flex.BookingRules, 1)
+	rule := flex.BookingRules[0]
+	assert.Equal(t, "same_day", rule.ID)
+	assert.Equal(t, int64(1), rule.BookingType)
+	assert.Equal(t, int64(60), rule.PriorNoticeDurationMin.Int64)
+	assert.False(t, rule.PriorNoticeDurationMax.Valid)
+	assert.Equal(t, "Call at least an hour ahead, please", rule.Message.String)
+
+	require.Len(t, flex.LocationGroups, 1)
+	assert.Equal(t, "Zone A", flex.LocationGroups[0].Name.String)
+	assert.Len(t, flex.LocationGroupStops, 2, "unknown stops are filtered at import, not parse")
+
+	require.Len(t, flex.StopTimes, 2, "rows without flex fields are skipped")
+	assert.Equal(t, "route_flex", flex.StopTimes[0].RouteID)
+	assert.Equal(t, int64(8*time.Hour), flex.StopTimes[0].StartPickupDropOffWindow.Int64)
+	assert.Equal(t, int64(25*time.Hour+30*time.Minute), flex.StopTimes[0].EndPickupDropOffWindow.Int64)
+	assert.Equal(t, "same_day", flex.StopTimes[1].DropOffBookingRuleID.String)
+}
+
+func TestParseFlexData_NoFlexFiles(t *testing.T) {
+	flex, err := parseFlexData(buildSyntheticGTFSZip(t, false))
+	require.NoError(t, err)
+	assert.Nil(t, flex)
+}
+
+func TestParseFlexData_InvalidBookingType(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("booking_rules.txt")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("booking_rule_id,booking_type\nbad,7\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = parseFlexData(buf.Bytes())
+	assert.ErrorContains(t, err, "invalid booking_type")
+}
+
+func TestParseGtfsTime(t *testing.T) {
+	d, ok := parseGtfsTime("26:05:09")
+	assert.True(t, ok)
+	assert.Equal(t, 26*time.Hour+5*time.Minute+9*time.Second, d)
+
+	for _, invalid := range []string{"", "8:00", "08:60:00", "aa:00:00"} {
+		_, ok := parseGtfsTime(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestSyntheticGTFS_FlexIngestion(t *testing.T) {
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	ctx := t.Context()
+	parsed, err := ParseGtfsData(buildSyntheticFlexGTFSZip(t), "synthetic-flex")
+	require.NoError(t, err)
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+
+	rules, err := client.Queries.GetBookingRulesForRoute(ctx, "route_flex")
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "https://example.com/book", rules[0].BookingUrl.String)
+
+	rules, err = client.Queries.GetBookingRulesForRoute(ctx, "route_fixed")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	rules, err = client.Queries.GetBookingRulesForStop(ctx, "stop_2")
+	require.NoError(t, err)
+	assert.Len(t, rules, 1, "stop served directly by a flex stop time")
+
+	rules, err = client.Queries.GetBookingRulesForStop(ctx, "stop_1")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	// Reimporting a feed without flex files clears the flex tables.
+	parsed, err = ParseGtfsData(buildSyntheticGTFSZip(t, false), "synthetic-flex")
+	require.NoError(t, err)
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+
+	rules, err = client.Queries.GetBookingRulesForStop(ctx, "stop_2")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestInsertFlexData_LocationGroupMembership(t *testing.T) {
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	ctx := t.Context()
+	parsed, err := ParseGtfsData(buildSyntheticFlexGTFSZip(t), "synthetic-flex")
+	require.NoError(t, err)
+	// Serve the zone rather than a single stop, as zone-based feeds do.
+	parsed.Flex.StopTimes = append(parsed.Flex.StopTimes, CreateFlexStopTimeParams{
+		TripID:              "trip_flex",
+		StopSequence:        3,
+		RouteID:             "route_flex",
+		LocationGroupID:     sql.NullString{String: "zone_a", Valid: true},
+		PickupBookingRuleID: sql.NullString{String: "same_day", Valid: true},
+	})
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+
+	rules, err := client.Queries.GetBookingRulesForStop(ctx, "stop_3")
+	require.NoError(t, err)
+	assert.Len(t, rules, 1, "stop served through its location group")
+}
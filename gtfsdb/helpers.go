@@ -30,6 +30,13 @@ var ddl string
 // source data is unchanged.
 type GtfsData struct {
 	Static *gtfs.Static
+	// Flex holds the GTFS-Flex files, or nil when the feed has none.
+	Flex *FlexData
+	// Fares holds the fare files, or nil when the feed has none.
+	Fares *FareData
+	// Merge describes how CombineGtfsData built the data from several feeds,
+	// or is nil for a single feed.
+	Merge  *MergeStats
 	Hash   string
 	Source string
 }
@@ -49,17 +56,33 @@ func ParseGtfsData(b []byte, source string) (*GtfsData, error) {
 		return nil, fmt.Errorf("GTFS validation failed: %w", err)
 	}
 
-	return &GtfsData{Static: staticData, Hash: hashStr, Source: source}, nil
+	// GTFS-Flex is optional: a malformed extension must not block the import
+	// of the fixed-route service.
+	flexData, err := parseFlexData(b)
+	if err != nil {
+		slog.Default().Warn("ignoring GTFS-Flex data", slog.String("source", source), slog.Any("error", err))
+		flexData = nil
+	}
+
+	// Fares are likewise optional.
+	fareData, err := parseFareData(b)
+	if err != nil {
+		slog.Default().Warn("ignoring GTFS fare data", slog.String("source", source), slog.Any("error", err))
+		fareData = nil
+	}
+
+	return &GtfsData{Static: staticData, Flex: flexData, Fares: fareData, Hash: hashStr, Source: source}, nil
 }
 
-// metricsWrapper wraps *sql.DB for metric reporting purposes
+// metricsWrapper wraps the database for metric reporting and slow-query logging
 type metricsWrapper struct {
-	db           *sql.DB
-	logger       *slog.Logger
-	queryMetrics DBQueryMetricsRecorder
+	db                 DBTX
+	logger             *slog.Logger
+	queryMetrics       DBQueryMetricsRecorder
+	slowQueryThreshold time.Duration
 }
 
-func newMetricsWrapper(db *sql.DB) *metricsWrapper {
+func newMetricsWrapper(db DBTX) *metricsWrapper {
 	return &metricsWrapper{
 		db:     db,
 		logger: slog.Default().With(slog.String("component", "db_metrics_wrapper")),
@@ -67,8 +90,9 @@ func newMetricsWrapper(db *sql.DB) *metricsWrapper {
 }
 
 func (s *metricsWrapper) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
 	res, err := s.db.ExecContext(ctx, query, args...)
-	s.recordQueryMetrics("exec", query, err)
+	s.recordQuery("exec", query, args, time.Since(start), err)
 	return res, err
 }
 
@@ -79,21 +103,37 @@ func (s *metricsWrapper) PrepareContext(ctx context.Context, query string) (*sql
 }
 
 func (s *metricsWrapper) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
 	rows, err := s.db.QueryContext(ctx, query, args...)
-	s.recordQueryMetrics("query", query, err)
+	s.recordQuery("query", query, args, time.Since(start), err)
 	return rows, err
 }
 
 func (s *metricsWrapper) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
 	row := s.db.QueryRowContext(ctx, query, args...)
 	// Note: QueryRowContext defers errors to row.Scan(), so err is always nil here.
 	// query_row metrics always report status="ok". See PR description for follow-up plan.
-	s.recordQueryMetrics("query_row", query, nil)
+	s.recordQuery("query_row", query, args, time.Since(start), nil)
 	return row
 }
 
-func (s *metricsWrapper) recordQueryMetrics(op, query string, err error) {
-	s.queryMetrics.RecordDBQuery(extractQueryName(query), op, err)
+// recordQuery reports the query's latency to the metrics recorder and logs it
+// when it exceeds the slow-query threshold. Latency for QueryContext covers
+// statement execution up to the first row, not iteration of the result set.
+func (s *metricsWrapper) recordQuery(op, query string, args []any, elapsed time.Duration, err error) {
+	queryName := extractQueryName(query)
+	if s.queryMetrics != nil {
+		s.queryMetrics.RecordDBQuery(queryName, op, elapsed, err)
+	}
+	if s.slowQueryThreshold > 0 && elapsed >= s.slowQueryThreshold {
+		s.logger.Warn("slow query",
+			slog.String("query_name", queryName),
+			slog.String("op", op),
+			slog.Duration("duration", elapsed),
+			slog.String("query", trimQuery(query)),
+			slog.String("args", formatQueryArgs(args)))
+	}
 }
 
 func extractQueryName(query string) string {
@@ -131,19 +171,51 @@ func trimQuery(q string) string {
 	return q
 }
 
+// formatQueryArgs renders bound parameters for the slow-query log, truncating
+// each one so large IN-lists or blobs don't flood the log.
+func formatQueryArgs(args []any) string {
+	const maxArgLen = 64
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if b, ok := arg.([]byte); ok {
+			parts[i] = fmt.Sprintf("<%d bytes>", len(b))
+			continue
+		}
+		runes := []rune(fmt.Sprint(arg))
+		if len(runes) > maxArgLen {
+			runes = append(runes[:maxArgLen], '…')
+		}
+		parts[i] = string(runes)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
 // createDB creates a new SQLite database with tables for static GTFS data
 func createDB(config Config) (*sql.DB, error) {
 	if config.Env == appconf.Test && config.DBPath != ":memory:" {
 		return nil, fmt.Errorf("test database must use in-memory storage, got path: %s", config.DBPath)
 	}
 
-	db, err := sql.Open(DriverName, config.DBPath)
+	var db *sql.DB
+	var err error
+	if config.EncryptionKey != "" {
+		db, err = openEncryptedDB(config.DBPath, config.EncryptionKey)
+	} else {
+		db, err = sql.Open(DriverName, config.DBPath)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Configure SQLite performance settings immediately after opening
 	ctx := context.Background()
+	if config.EncryptionKey != "" {
+		if err := verifyEncryption(ctx, db); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	// Configure SQLite performance settings immediately after opening
 	err = configureSQLitePerformance(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("error configuring SQLite performance: %w", err)
@@ -168,9 +240,37 @@ func performDatabaseMigration(ctx context.Context, db *sql.DB) error {
 			continue // Skip empty statements
 		}
 		if _, err := db.ExecContext(ctx, trimmedStmt); err != nil {
+			// ALTER TABLE ... ADD COLUMN has no IF NOT EXISTS; the column being
+			// there already means the database is up to date.
+			if strings.Contains(err.Error(), "duplicate column name") {
+				continue
+			}
 			return fmt.Errorf("error executing DDL statement [%s]: %w", trimmedStmt, err)
 		}
 	}
+	if err := migrateFareProductsRiderCategory(ctx, db); err != nil {
+		return err
+	}
+	return backfillRouteServiceSpans(ctx, db)
+}
+
+// backfillRouteServiceSpans builds the route service spans of a database
+// imported before they were computed at import time, as an unchanged feed is
+// not imported again.
+func backfillRouteServiceSpans(ctx context.Context, db *sql.DB) error {
+	var hasTable, hasSpans bool
+	err := db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'route_service_spans')`,
+	).Scan(&hasTable)
+	if err != nil || !hasTable {
+		return err
+	}
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM route_service_spans)`).Scan(&hasSpans); err != nil || hasSpans {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, buildRouteServiceSpans); err != nil {
+		return fmt.Errorf("error building route service spans: %w", err)
+	}
 	return nil
 }
 
@@ -299,6 +399,9 @@ func (c *Client) StoreGtfsData(ctx context.Context, data *GtfsData) (bool, error
 			ContinuousPickup:  toNullInt64(int64(r.ContinuousPickup)),
 			ContinuousDropOff: toNullInt64(int64(r.ContinuousDropOff)),
 		}
+		if r.SortOrder != nil {
+			route.SortOrder = sql.NullInt64{Int64: int64(*r.SortOrder), Valid: true}
+		}
 
 		_, err := qtx.CreateRoute(ctx, route)
 
@@ -404,12 +507,19 @@ func (c *Client) StoreGtfsData(ctx context.Context, data *GtfsData) (bool, error
 		return false, fmt.Errorf("unable to create trips: %w", err)
 	}
 
+	// Stop times without a shape_dist_traveled get their distance along the
+	// trip's shape, so realtime interpolation can rely on it.
+	shapeDistances := newShapeDistanceCalculator()
 	var allStopTimeParams []CreateStopTimeParams
 	for _, t := range data.Static.Trips {
-		for _, st := range t.StopTimes {
-			var shapeDistTraveled float64
-			if st.ShapeDistanceTraveled != nil {
-				shapeDistTraveled = *st.ShapeDistanceTraveled
+		computed := shapeDistances.stopDistances(&t)
+		for i, st := range t.StopTimes {
+			var shapeDistTraveled sql.NullFloat64
+			switch {
+			case st.ShapeDistanceTraveled != nil:
+				shapeDistTraveled = sql.NullFloat64{Float64: *st.ShapeDistanceTraveled, Valid: true}
+			case computed != nil:
+				shapeDistTraveled = sql.NullFloat64{Float64: computed[i], Valid: true}
 			}
 
 			params := CreateStopTimeParams{
@@ -421,7 +531,7 @@ func (c *Client) StoreGtfsData(ctx context.Context, data *GtfsData) (bool, error
 				StopHeadsign:      nulls.String(st.Headsign),
 				PickupType:        toNullInt64(int64(st.PickupType)),
 				DropOffType:       toNullInt64(int64(st.DropOffType)),
-				ShapeDistTraveled: toNullFloat64(shapeDistTraveled),
+				ShapeDistTraveled: shapeDistTraveled,
 				Timepoint:         toNullInt64(boolToInt(st.ExactTimes)),
 			}
 
@@ -452,6 +562,22 @@ func (c *Client) StoreGtfsData(ctx context.Context, data *GtfsData) (bool, error
 		}
 	}
 
+	if data.Flex != nil {
+		if err := insertFlexData(ctx, qtx, data.Flex, data.Static); err != nil {
+			return false, fmt.Errorf("unable to create GTFS-Flex data: %w", err)
+		}
+	}
+
+	if data.Fares != nil {
+		if err := insertFareData(ctx, qtx, data.Fares, data.Static); err != nil {
+			return false, fmt.Errorf("unable to create fare data: %w", err)
+		}
+	}
+
+	if err := insertTransfers(ctx, qtx, data.Static, c.config.TransferRadiusMeters); err != nil {
+		return false, fmt.Errorf("unable to create transfers: %w", err)
+	}
+
 	var allShapeParams []CreateShapeParams
 	for _, s := range data.Static.Shapes {
 		for idx, pt := range s.Points {
@@ -533,6 +659,16 @@ func (c *Client) StoreGtfsData(ctx context.Context, data *GtfsData) (bool, error
 		return false, fmt.Errorf("failed to bulk update trip time bounds: %w", err)
 	}
 
+	logging.LogOperation(logger, "building_block_trip_order")
+	if err := qtx.BuildBlockTripOrder(ctx); err != nil {
+		return false, fmt.Errorf("failed to build block trip order: %w", err)
+	}
+
+	logging.LogOperation(logger, "building_route_service_spans")
+	if err := qtx.BuildRouteServiceSpans(ctx); err != nil {
+		return false, fmt.Errorf("failed to build route service spans: %w", err)
+	}
+
 	logging.LogOperation(logger, "building_block_layover_index")
 	if err := c.buildBlockLayoverIndex(ctx, data.Static, tx); err != nil {
 		logging.LogError(logger, "Unable to build block layover index", err)
@@ -604,6 +740,51 @@ func updateFeedExpiresAtFromCalendar(ctx context.Context, qtx *Queries) error {
 // clearAllGTFSDataWithQueries clears all GTFS data using the given Queries (e.g. transaction-scoped).
 // Delete order respects foreign key constraints.
 func (c *Client) clearAllGTFSDataWithQueries(ctx context.Context, q *Queries) error {
+	if err := q.ClearFlexStopTimes(ctx); err != nil {
+		return fmt.Errorf("error clearing flex_stop_times: %w", err)
+	}
+	if err := q.ClearLocationGroupStops(ctx); err != nil {
+		return fmt.Errorf("error clearing location_group_stops: %w", err)
+	}
+	if err := q.ClearLocationGroups(ctx); err != nil {
+		return fmt.Errorf("error clearing location_groups: %w", err)
+	}
+	if err := q.ClearBookingRules(ctx); err != nil {
+		return fmt.Errorf("error clearing booking_rules: %w", err)
+	}
+	if err := q.ClearFareRules(ctx); err != nil {
+		return fmt.Errorf("error clearing fare_rules: %w", err)
+	}
+	if err := q.ClearFareAttributes(ctx); err != nil {
+		return fmt.Errorf("error clearing fare_attributes: %w", err)
+	}
+	if err := q.ClearFareLegRules(ctx); err != nil {
+		return fmt.Errorf("error clearing fare_leg_rules: %w", err)
+	}
+	if err := q.ClearFareProducts(ctx); err != nil {
+		return fmt.Errorf("error clearing fare_products: %w", err)
+	}
+	if err := q.ClearFareMedia(ctx); err != nil {
+		return fmt.Errorf("error clearing fare_media: %w", err)
+	}
+	if err := q.ClearRiderCategories(ctx); err != nil {
+		return fmt.Errorf("error clearing rider_categories: %w", err)
+	}
+	if err := q.ClearStopAreas(ctx); err != nil {
+		return fmt.Errorf("error clearing stop_areas: %w", err)
+	}
+	if err := q.ClearAreas(ctx); err != nil {
+		return fmt.Errorf("error clearing areas: %w", err)
+	}
+	if err := q.ClearRouteNetworks(ctx); err != nil {
+		return fmt.Errorf("error clearing route_networks: %w", err)
+	}
+	if err := q.ClearRouteServiceSpans(ctx); err != nil {
+		return fmt.Errorf("error clearing route_service_spans: %w", err)
+	}
+	if err := q.ClearTransfers(ctx); err != nil {
+		return fmt.Errorf("error clearing transfers: %w", err)
+	}
 	if err := q.ClearBlockLayovers(ctx); err != nil {
 		return fmt.Errorf("error clearing block_layover: %w", err)
 	}
@@ -613,6 +794,9 @@ func (c *Client) clearAllGTFSDataWithQueries(ctx context.Context, q *Queries) er
 	if err := q.ClearBlockTripIndices(ctx); err != nil {
 		return fmt.Errorf("error clearing block_trip_index: %w", err)
 	}
+	if err := q.ClearBlockTripOrder(ctx); err != nil {
+		return fmt.Errorf("error clearing block_trip_order: %w", err)
+	}
 	if err := q.ClearFrequencies(ctx); err != nil {
 		return fmt.Errorf("error clearing frequencies: %w", err)
 	}
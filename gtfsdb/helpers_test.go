@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"log/slog"
 	"os"
 	"testing"
 	"time"
@@ -34,6 +35,59 @@ func TestPerformDatabaseMigration_Idempotency(t *testing.T) {
 	assert.NoError(t, err, "Second migration should be idempotent and succeed")
 }
 
+func TestPerformDatabaseMigration_AddsColumnsToExistingTables(t *testing.T) {
+	db, err := sql.Open(DriverName, ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1) // Keep every statement on the one in-memory database
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+
+	// A routes table as created before sort_order was added.
+	_, err = db.ExecContext(ctx, `CREATE TABLE routes (
+		id TEXT PRIMARY KEY, agency_id TEXT NOT NULL, short_name TEXT, long_name TEXT, desc TEXT,
+		type INTEGER NOT NULL, url TEXT, color TEXT, text_color TEXT,
+		continuous_pickup INTEGER, continuous_drop_off INTEGER) STRICT`)
+	require.NoError(t, err)
+
+	require.NoError(t, performDatabaseMigration(ctx, db))
+	require.NoError(t, performDatabaseMigration(ctx, db), "re-adding an existing column is not an error")
+
+	var columns int
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM pragma_table_info('routes') WHERE name = 'sort_order'`).Scan(&columns))
+	assert.Equal(t, 1, columns)
+}
+
+func TestPerformDatabaseMigration_RebuildsFareProducts(t *testing.T) {
+	db, err := sql.Open(DriverName, ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+
+	// A fare_products table as created before rider categories.
+	_, err = db.ExecContext(ctx, `CREATE TABLE fare_products (
+		fare_product_id TEXT NOT NULL, fare_media_id TEXT NOT NULL DEFAULT '', name TEXT,
+		amount REAL NOT NULL, currency TEXT NOT NULL,
+		PRIMARY KEY (fare_product_id, fare_media_id)) STRICT`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO fare_products VALUES ('ride', 'card', 'Ride', 2.5, 'USD')`)
+	require.NoError(t, err)
+
+	require.NoError(t, performDatabaseMigration(ctx, db))
+	require.NoError(t, performDatabaseMigration(ctx, db))
+
+	q := New(db)
+	require.NoError(t, q.CreateFareProduct(ctx, CreateFareProductParams{
+		FareProductID: "ride", FareMediaID: "card", RiderCategoryID: "senior", Amount: 1.25, Currency: "USD",
+	}))
+	products, err := q.GetFareProductsByIDs(ctx, []string{"ride"})
+	require.NoError(t, err)
+	require.Len(t, products, 2, "the existing row is kept and a second rider category fits")
+	assert.Empty(t, products[0].RiderCategoryID)
+	assert.Equal(t, "senior", products[1].RiderCategoryID)
+}
+
 func TestPerformDatabaseMigration_ErrorHandling(t *testing.T) {
 	db, err := sql.Open(DriverName, ":memory:")
 	assert.NoError(t, err)
@@ -143,7 +197,7 @@ type testQueryMetricsRecorder struct {
 	calls []queryMetricCall
 }
 
-func (r *testQueryMetricsRecorder) RecordDBQuery(queryName, op string, err error) {
+func (r *testQueryMetricsRecorder) RecordDBQuery(queryName, op string, _ time.Duration, err error) {
 	r.calls = append(r.calls, queryMetricCall{
 		queryName: queryName,
 		op:        op,
@@ -179,6 +233,45 @@ func TestSlowQueryDB_RecordsQueryMetrics(t *testing.T) {
 	assert.Equal(t, queryMetricCall{queryName: "unknown", op: "query_row", hadErr: false}, recorder.calls[2])
 }
 
+func TestSlowQueryDB_LogsQueriesOverThreshold(t *testing.T) {
+	db, err := sql.Open(DriverName, ":memory:")
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	var logs bytes.Buffer
+	wrapper := newMetricsWrapper(db)
+	wrapper.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	// Threshold disabled: nothing is logged, and a nil recorder is tolerated.
+	_, err = wrapper.QueryContext(ctx, "-- name: GetTrip :one\nSELECT ?", "trip_1")
+	require.NoError(t, err)
+	assert.Empty(t, logs.String())
+
+	wrapper.slowQueryThreshold = time.Nanosecond
+	_, err = wrapper.QueryContext(ctx, "-- name: GetTrip :one\nSELECT ?", "trip_1")
+	require.NoError(t, err)
+
+	output := logs.String()
+	assert.Contains(t, output, "slow query")
+	assert.Contains(t, output, "query_name=GetTrip")
+	assert.Contains(t, output, "op=query")
+	assert.Contains(t, output, "args=[trip_1]")
+
+	wrapper.slowQueryThreshold = time.Hour
+	logs.Reset()
+	_, err = wrapper.QueryContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	assert.Empty(t, logs.String(), "queries under the threshold must not be logged")
+}
+
+func TestFormatQueryArgs(t *testing.T) {
+	long := string(bytes.Repeat([]byte("a"), 100))
+	assert.Equal(t, "[]", formatQueryArgs(nil))
+	assert.Equal(t, "[1, x, <3 bytes>]", formatQueryArgs([]any{1, "x", []byte("abc")}))
+	assert.Equal(t, "["+long[:64]+"…]", formatQueryArgs([]any{long}))
+}
+
 func TestNewClient_RecordsQueryMetricsWhenOnlyMetricsEnabled(t *testing.T) {
 	originalDDL := ddl
 	ddl = `
@@ -8,6 +8,19 @@ import (
 	"database/sql"
 )
 
+type AdminAuditLog struct {
+	ID             int64
+	Actor          string
+	Action         string
+	Target         string
+	PreviousValue  sql.NullString
+	NewValue       sql.NullString
+	IdempotencyKey sql.NullString
+	ResponseStatus int64
+	ResponseBody   []byte
+	CreatedAt      int64
+}
+
 type Agency struct {
 	ID       string
 	Name     string
@@ -19,6 +32,41 @@ type Agency struct {
 	Email    sql.NullString
 }
 
+type ApiKey struct {
+	ID           int64
+	KeyHash      string
+	ContactEmail string
+	Description  string
+	RateLimit    int64
+	CreatedAt    int64
+	RevokedAt    sql.NullInt64
+}
+
+type ApiKeyUsage struct {
+	Day            string
+	KeyFingerprint string
+	Endpoint       string
+	Requests       int64
+}
+
+type Area struct {
+	ID   string
+	Name sql.NullString
+}
+
+type ArrivalHistory struct {
+	ServiceDate     string
+	TripID          string
+	StopSequence    int64
+	StopID          string
+	VehicleID       string
+	ActualArrival   sql.NullInt64
+	ActualDeparture sql.NullInt64
+	Delay           int64
+	OccupancyStatus string
+	RecordedAt      int64
+}
+
 type BlockLayover struct {
 	ID            int64
 	BlockID       string
@@ -47,6 +95,33 @@ type BlockTripIndex struct {
 	CreatedAt       int64
 }
 
+type BlockTripOrder struct {
+	TripID        string
+	BlockID       string
+	ServiceID     string
+	BlockSequence int64
+	StartTime     int64
+	EndTime       int64
+}
+
+type BookingRule struct {
+	ID                     string
+	BookingType            int64
+	PriorNoticeDurationMin sql.NullInt64
+	PriorNoticeDurationMax sql.NullInt64
+	PriorNoticeLastDay     sql.NullInt64
+	PriorNoticeLastTime    sql.NullString
+	PriorNoticeStartDay    sql.NullInt64
+	PriorNoticeStartTime   sql.NullString
+	PriorNoticeServiceID   sql.NullString
+	Message                sql.NullString
+	PickupMessage          sql.NullString
+	DropOffMessage         sql.NullString
+	PhoneNumber            sql.NullString
+	InfoUrl                sql.NullString
+	BookingUrl             sql.NullString
+}
+
 type Calendar struct {
 	ID        string
 	Monday    int64
@@ -66,6 +141,77 @@ type CalendarDate struct {
 	ExceptionType int64
 }
 
+type DeveloperApiKey struct {
+	ID                    int64
+	Email                 string
+	Name                  string
+	ApiKeyHash            sql.NullString
+	VerificationTokenHash string
+	Status                string
+	Tier                  string
+	QuotaRequest          sql.NullString
+	QuotaRequestedAt      sql.NullInt64
+	CreatedAt             int64
+	VerificationExpiresAt int64
+	VerifiedAt            sql.NullInt64
+}
+
+type FareAttribute struct {
+	ID               string
+	Price            float64
+	CurrencyType     string
+	PaymentMethod    int64
+	Transfers        sql.NullInt64
+	AgencyID         sql.NullString
+	TransferDuration sql.NullInt64
+}
+
+type FareLegRule struct {
+	ID            int64
+	LegGroupID    sql.NullString
+	NetworkID     sql.NullString
+	FromAreaID    sql.NullString
+	ToAreaID      sql.NullString
+	FareProductID string
+	RulePriority  int64
+}
+
+type FareMedium struct {
+	ID            string
+	Name          sql.NullString
+	FareMediaType int64
+}
+
+type FareProduct struct {
+	FareProductID   string
+	FareMediaID     string
+	RiderCategoryID string
+	Name            sql.NullString
+	Amount          float64
+	Currency        string
+}
+
+type FareRule struct {
+	ID            int64
+	FareID        string
+	RouteID       sql.NullString
+	OriginID      sql.NullString
+	DestinationID sql.NullString
+	ContainsID    sql.NullString
+}
+
+type FlexStopTime struct {
+	TripID                   string
+	StopSequence             int64
+	RouteID                  string
+	StopID                   sql.NullString
+	LocationGroupID          sql.NullString
+	StartPickupDropOffWindow sql.NullInt64
+	EndPickupDropOffWindow   sql.NullInt64
+	PickupBookingRuleID      sql.NullString
+	DropOffBookingRuleID     sql.NullString
+}
+
 type Frequency struct {
 	TripID      string
 	StartTime   int64
@@ -82,6 +228,16 @@ type ImportMetadatum struct {
 	FeedExpiresAt sql.NullInt64
 }
 
+type LocationGroup struct {
+	ID   string
+	Name sql.NullString
+}
+
+type LocationGroupStop struct {
+	LocationGroupID string
+	StopID          string
+}
+
 type ProblemReportsStop struct {
 	ID                   int64
 	StopID               string
@@ -111,6 +267,13 @@ type ProblemReportsTrip struct {
 	SubmittedAt          int64
 }
 
+type RiderCategory struct {
+	ID                    string
+	Name                  string
+	IsDefaultFareCategory int64
+	EligibilityUrl        sql.NullString
+}
+
 type Route struct {
 	ID                string
 	AgencyID          string
@@ -123,6 +286,19 @@ type Route struct {
 	TextColor         sql.NullString
 	ContinuousPickup  sql.NullInt64
 	ContinuousDropOff sql.NullInt64
+	SortOrder         sql.NullInt64
+}
+
+type RouteNetwork struct {
+	RouteID   string
+	NetworkID string
+}
+
+type RouteServiceSpan struct {
+	RouteID            string
+	ServiceID          string
+	FirstDepartureTime int64
+	LastDepartureTime  int64
 }
 
 type RoutesFt struct {
@@ -159,6 +335,11 @@ type Stop struct {
 	ParentStation      sql.NullString
 }
 
+type StopArea struct {
+	AreaID string
+	StopID string
+}
+
 type StopTime struct {
 	TripID            string
 	ArrivalTime       int64
@@ -192,6 +373,20 @@ type StopsRtreeRowid struct {
 	Nodeno interface{}
 }
 
+type Transfer struct {
+	ID              int64
+	FromStopID      string
+	ToStopID        string
+	FromRouteID     sql.NullString
+	ToRouteID       sql.NullString
+	FromTripID      sql.NullString
+	ToTripID        sql.NullString
+	TransferType    int64
+	MinTransferTime sql.NullInt64
+	Generated       int64
+	Distance        sql.NullFloat64
+}
+
 type Trip struct {
 	ID                   string
 	RouteID              string
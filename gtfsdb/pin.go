@@ -0,0 +1,252 @@
+package gtfsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+type pinKey struct{}
+
+// datasetPin is the read transaction of a pinned context. It is begun at the
+// first read, so that a pin holds a connection only while it reads, and SQLite
+// takes its snapshot at that read; WAL mode keeps it while a reload commits.
+type datasetPin struct {
+	db *sql.DB
+	// ctx is the context that was pinned.
+	ctx      context.Context
+	mu       sync.Mutex
+	conn     *sql.Conn
+	tx       *sql.Tx
+	released bool
+	// failed is set when the transaction could not be begun, so the pin's
+	// later reads are all unpinned too rather than some of them pinned.
+	failed   bool
+	failures *atomic.Uint64
+	done     func()
+}
+
+// begin returns the pin's read transaction, beginning it if this is the
+// first read. The wait for a connection ends with ctx, the read's context, or
+// with the pinned one, which a read made on behalf of coalesced callers has
+// been detached from. The transaction lasts until the pin is released, as
+// work coalesced onto a request whose caller disconnected may still be
+// reading. It returns nil once the pin is released or when no transaction
+// could be begun, and the read is then made unpinned. A failure to begin is
+// logged, unless it came from the read's context ending, which fails the
+// read anyway, or the pinned one, whose request has been served.
+func (p *datasetPin) begin(ctx context.Context) *sql.Tx {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.released || p.failed || p.tx != nil {
+		return p.tx
+	}
+	wait, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+	conn, err := p.db.Conn(wait)
+	if err != nil {
+		p.fail(ctx, err)
+		return nil
+	}
+	tx, err := conn.BeginTx(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		_ = conn.Close()
+		p.fail(ctx, err)
+		return nil
+	}
+	p.conn, p.tx = conn, tx
+	return tx
+}
+
+func (p *datasetPin) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		// The read fails too, and a later read may still be pinned.
+		return
+	}
+	p.failed = true
+	if p.ctx.Err() != nil {
+		return
+	}
+	p.failures.Add(1)
+	slog.Default().With(slog.String("component", "gtfsdb")).Warn(
+		"could not pin dataset; the request reads it unpinned", slog.String("error", err.Error()))
+}
+
+func (p *datasetPin) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.released {
+		return
+	}
+	p.released = true
+	if p.tx != nil {
+		_ = p.tx.Rollback()
+		_ = p.conn.Close()
+		p.tx = nil
+	}
+	p.done()
+}
+
+// pinTracker counts the pins taken in each epoch, so that a reload can wait
+// for those that may still read the dataset it replaced.
+type pinTracker struct {
+	mu     sync.Mutex
+	epoch  uint64
+	active map[uint64]*sync.WaitGroup
+	// failures counts the pins whose requests read unpinned because their
+	// transaction could not be begun.
+	failures atomic.Uint64
+}
+
+func (t *pinTracker) add() func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active == nil {
+		t.active = make(map[uint64]*sync.WaitGroup)
+	}
+	wg := t.active[t.epoch]
+	if wg == nil {
+		wg = &sync.WaitGroup{}
+		t.active[t.epoch] = wg
+	}
+	wg.Add(1)
+	return wg.Done
+}
+
+// snapshotDB sends the reads of a context pinned with Client.Pin to its read
+// transaction, and everything else to db. Writes are never pinned: a read
+// transaction cannot write, and a write must not wait for the request
+// that made it.
+type snapshotDB struct {
+	db *sql.DB
+}
+
+// pinnedTx returns the read transaction ctx is pinned to on db, if any and
+// if query only reads. A context pinned on another database, such as the
+// main one for a request served from a retained dataset, reads db unpinned.
+func pinnedTx(ctx context.Context, db *sql.DB, query string) *sql.Tx {
+	if !readsOnly(query) {
+		return nil
+	}
+	pin, _ := ctx.Value(pinKey{}).(*datasetPin)
+	if pin == nil || pin.db != db {
+		return nil
+	}
+	return pin.begin(ctx)
+}
+
+// readsOnly reports whether query is a SELECT, after the name comment sqlc
+// starts it with. Writes returning rows, such as an INSERT ... RETURNING,
+// come through QueryRowContext too.
+func readsOnly(query string) bool {
+	for {
+		query = strings.TrimSpace(query)
+		if !strings.HasPrefix(query, "--") {
+			break
+		}
+		_, query, _ = strings.Cut(query, "\n")
+	}
+	keyword := query
+	if end := strings.IndexFunc(query, unicode.IsSpace); end >= 0 {
+		keyword = query[:end]
+	}
+	return strings.EqualFold(keyword, "SELECT") || strings.EqualFold(keyword, "WITH")
+}
+
+func (s snapshotDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
+}
+
+func (s snapshotDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return s.db.PrepareContext(ctx, query)
+}
+
+func (s snapshotDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	// A pin released while the query was on its way, by a request that left
+	// work behind, falls back to reading unpinned.
+	if tx := pinnedTx(ctx, s.db, query); tx != nil {
+		if rows, err := tx.QueryContext(ctx, query, args...); !errors.Is(err, sql.ErrTxDone) {
+			return rows, err
+		}
+	}
+	return s.db.QueryContext(ctx, query, args...)
+}
+
+func (s snapshotDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if tx := pinnedTx(ctx, s.db, query); tx != nil {
+		if row := tx.QueryRowContext(ctx, query, args...); !errors.Is(row.Err(), sql.ErrTxDone) {
+			return row
+		}
+	}
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+// pinnable reports whether the client's reads can be pinned. An in-memory
+// database has a single connection, which a pin would hold for the whole
+// request, and it serializes every query anyway.
+func (c *Client) pinnable() bool {
+	return c.config.DBPath != ":memory:"
+}
+
+// Pin returns ctx with the dataset being served pinned to it: until release
+// is called, every read Queries makes with it comes from one read
+// transaction, and so from one dataset, even when a reload commits a new one
+// meanwhile. The transaction holds a connection from the first read until
+// release, so only requests that need their reads to agree should be pinned,
+// and they must not wait on writes of their own. A context already pinned to
+// the client is returned as it is, as is ctx for in-memory databases. release
+// must be called once the reads are done; reads made with ctx afterwards are
+// no longer pinned.
+func (c *Client) Pin(ctx context.Context) (pinned context.Context, release func()) {
+	if pin, _ := ctx.Value(pinKey{}).(*datasetPin); !c.pinnable() || (pin != nil && pin.db == c.DB) {
+		return ctx, func() {}
+	}
+	pin := &datasetPin{db: c.DB, ctx: ctx, failures: &c.pins.failures, done: c.pins.add()}
+	return context.WithValue(ctx, pinKey{}, pin), pin.release
+}
+
+// PinFailures returns how many pinned requests have read unpinned because
+// their read transaction could not be begun. Each is also logged.
+func (c *Client) PinFailures() uint64 {
+	return c.pins.failures.Load()
+}
+
+// DrainPins waits for the pins taken before it was called to be released,
+// for at most timeout, and reports whether they all were. A reload calls it
+// after committing, so that caches it resets are not filled again from the
+// dataset it replaced.
+func (c *Client) DrainPins(timeout time.Duration) bool {
+	t := &c.pins
+	t.mu.Lock()
+	waits := make([]*sync.WaitGroup, 0, len(t.active))
+	for _, wg := range t.active {
+		waits = append(waits, wg)
+	}
+	t.active = nil
+	t.epoch++
+	t.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		for _, wg := range waits {
+			wg.Wait()
+		}
+		close(drained)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,148 @@
+package gtfsdb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+func newPinTestClient(t *testing.T) *Client {
+	t.Helper()
+	client, err := NewClient(Config{DBPath: filepath.Join(t.TempDir(), "pin.db"), Env: appconf.Development})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func createPinTestAgency(t *testing.T, ctx context.Context, client *Client, id string) {
+	t.Helper()
+	_, err := client.Queries.CreateAgency(ctx, CreateAgencyParams{
+		ID: id, Name: id, Url: "https://example.com", Timezone: "UTC",
+	})
+	require.NoError(t, err)
+}
+
+func TestPinKeepsDatasetAcrossCommits(t *testing.T) {
+	client := newPinTestClient(t)
+	createPinTestAgency(t, context.Background(), client, "old")
+
+	ctx, release := client.Pin(context.Background())
+	agencies, err := client.Queries.ListAgencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, agencies, 1)
+
+	// A write made with the pinned context is not held by its snapshot.
+	createPinTestAgency(t, ctx, client, "new")
+	_, err = client.DB.Exec("DELETE FROM agencies WHERE id = 'old'")
+	require.NoError(t, err)
+
+	agencies, err = client.Queries.ListAgencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, agencies, 1)
+	assert.Equal(t, "old", agencies[0].ID)
+	_, err = client.Queries.GetAgency(ctx, "old")
+	assert.NoError(t, err, "the pinned context still reads the old dataset")
+
+	agencies, err = client.Queries.ListAgencies(context.Background())
+	require.NoError(t, err)
+	require.Len(t, agencies, 1)
+	assert.Equal(t, "new", agencies[0].ID, "unpinned reads see the commit")
+
+	again, releaseAgain := client.Pin(ctx)
+	assert.Equal(t, ctx, again, "a pinned context is not pinned twice")
+	releaseAgain()
+
+	release()
+	release()
+	agencies, err = client.Queries.ListAgencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, agencies, 1)
+	assert.Equal(t, "new", agencies[0].ID, "a released context reads unpinned")
+}
+
+func TestPinHoldsConnectionOnlyOnceRead(t *testing.T) {
+	client := newPinTestClient(t)
+	createPinTestAgency(t, context.Background(), client, "old")
+	client.DB.SetMaxOpenConns(1)
+
+	ctx, release := client.Pin(context.Background())
+	defer release()
+	assert.Zero(t, client.DB.Stats().InUse, "a pin that has not read holds no connection")
+
+	_, err := client.Queries.ListAgencies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.DB.Stats().InUse)
+
+	// The wait of another pin for a connection ends with its read's context.
+	other, releaseOther := client.Pin(context.Background())
+	defer releaseOther()
+	timeout, cancel := context.WithTimeout(other, 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Queries.ListAgencies(timeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	assert.Zero(t, client.DB.Stats().InUse, "release gives the connection back")
+	_, err = client.Queries.ListAgencies(other)
+	assert.NoError(t, err)
+}
+
+func TestPinFailureIsCounted(t *testing.T) {
+	client := newPinTestClient(t)
+	client.DB.SetMaxOpenConns(1)
+	conn, err := client.DB.Conn(context.Background())
+	require.NoError(t, err)
+
+	// A read whose context ends while it waits is not a pin failure.
+	ctx, release := client.Pin(context.Background())
+	defer release()
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Queries.ListAgencies(timeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, client.PinFailures())
+
+	require.NoError(t, conn.Close())
+	require.NoError(t, client.DB.Close())
+	_, err = client.Queries.ListAgencies(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(1), client.PinFailures(), "a pin that cannot begin is counted")
+	_, _ = client.Queries.ListAgencies(ctx)
+	assert.Equal(t, uint64(1), client.PinFailures(), "and its later reads are unpinned without trying again")
+}
+
+func TestPinInMemoryDatabase(t *testing.T) {
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+	pinned, release := client.Pin(ctx)
+	defer release()
+	assert.Equal(t, ctx, pinned)
+	assert.True(t, client.DrainPins(time.Millisecond))
+}
+
+func TestDrainPins(t *testing.T) {
+	client := newPinTestClient(t)
+
+	_, held := client.Pin(context.Background())
+	assert.False(t, client.DrainPins(20*time.Millisecond), "a pin taken before is waited for")
+	held()
+
+	_, before := client.Pin(context.Background())
+	drained := make(chan bool)
+	go func() { drained <- client.DrainPins(5 * time.Second) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// A pin taken once the drain began is not waited for.
+	_, after := client.Pin(context.Background())
+	defer after()
+	before()
+	assert.True(t, <-drained)
+}
@@ -60,10 +60,11 @@ OR REPLACE INTO routes (
     color,
     text_color,
     continuous_pickup,
-    continuous_drop_off
+    continuous_drop_off,
+    sort_order
 )
 VALUES
-    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
+    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
 
 -- name: CreateStop :one
 INSERT
@@ -192,7 +193,8 @@ SELECT
     color,
     text_color,
     continuous_pickup,
-    continuous_drop_off
+    continuous_drop_off,
+    sort_order
 FROM
     routes
 ORDER BY
@@ -228,7 +230,8 @@ SELECT
     routes.type,
     routes.url,
     routes.color,
-    routes.text_color
+    routes.text_color,
+    routes.sort_order
 FROM
     routes
 WHERE
@@ -363,7 +366,8 @@ SELECT DISTINCT
     routes.type,
     routes.url,
     routes.color,
-    routes.text_color
+    routes.text_color,
+    routes.sort_order
 FROM
     stop_times
     JOIN trips ON stop_times.trip_id = trips.id
@@ -600,6 +604,48 @@ DELETE FROM frequencies;
 -- name: ClearShapes :exec
 DELETE FROM shapes;
 
+-- name: ClearFlexStopTimes :exec
+DELETE FROM flex_stop_times;
+
+-- name: ClearLocationGroupStops :exec
+DELETE FROM location_group_stops;
+
+-- name: ClearLocationGroups :exec
+DELETE FROM location_groups;
+
+-- name: ClearBookingRules :exec
+DELETE FROM booking_rules;
+
+-- name: ClearFareRules :exec
+DELETE FROM fare_rules;
+
+-- name: ClearFareAttributes :exec
+DELETE FROM fare_attributes;
+
+-- name: ClearFareLegRules :exec
+DELETE FROM fare_leg_rules;
+
+-- name: ClearFareProducts :exec
+DELETE FROM fare_products;
+
+-- name: ClearFareMedia :exec
+DELETE FROM fare_media;
+
+-- name: ClearRiderCategories :exec
+DELETE FROM rider_categories;
+
+-- name: ClearStopAreas :exec
+DELETE FROM stop_areas;
+
+-- name: ClearAreas :exec
+DELETE FROM areas;
+
+-- name: ClearRouteNetworks :exec
+DELETE FROM route_networks;
+
+-- name: ClearTransfers :exec
+DELETE FROM transfers;
+
 -- name: ClearTrips :exec
 DELETE FROM trips;
 
@@ -632,6 +678,7 @@ SELECT DISTINCT
     routes.url,
     routes.color,
     routes.text_color,
+    routes.sort_order,
     stop_times.stop_id
 FROM
     stop_times
@@ -723,6 +770,20 @@ FROM
 WHERE
     service_id = ?;
 
+-- name: GetServiceExceptionsForAgency :many
+-- calendar_dates exceptions from start_date to end_date (YYYYMMDD, both
+-- included) of the services running the agency's trips.
+SELECT cd.service_id, cd.date, cd.exception_type
+FROM calendar_dates cd
+WHERE cd.date BETWEEN @start_date AND @end_date
+  AND cd.service_id IN (
+    SELECT t.service_id
+    FROM trips t
+    JOIN routes r ON r.id = t.route_id
+    WHERE r.agency_id = @agency_id
+  )
+ORDER BY cd.date, cd.service_id;
+
 -- name: GetStopsForRoute :many
 SELECT DISTINCT
     stops.id,
@@ -824,6 +885,20 @@ WHERE
 ORDER BY
     id;
 
+-- name: GetTripTimeZones :many
+-- The stop_timezone of the stop a trip departs from, when set, otherwise the
+-- timezone of the agency operating its route.
+SELECT
+    t.id AS trip_id,
+    CAST(COALESCE(NULLIF(s.timezone, ''), a.timezone) AS TEXT) AS timezone
+FROM trips t
+JOIN routes r ON r.id = t.route_id
+JOIN agencies a ON a.id = r.agency_id
+LEFT JOIN stop_times st ON st.trip_id = t.id
+    AND st.stop_sequence = (SELECT MIN(stop_sequence) FROM stop_times WHERE trip_id = t.id)
+LEFT JOIN stops s ON s.id = st.stop_id
+WHERE t.id IN (sqlc.slice('trip_ids'));
+
 -- name: GetBlockDetails :many
 SELECT
     t.service_id,
@@ -943,6 +1018,8 @@ SELECT
     st.stop_id,
     st.stop_sequence,
     st.stop_headsign,
+    st.pickup_type,
+    st.drop_off_type,
     t.route_id,
     t.service_id,
     t.trip_headsign,
@@ -1017,6 +1094,91 @@ DELETE FROM block_trip_entry;
 -- name: ClearBlockTripIndices :exec
 DELETE FROM block_trip_index;
 
+-- name: ClearBlockTripOrder :exec
+DELETE FROM block_trip_order;
+
+-- name: BuildBlockTripOrder :exec
+-- Orders each block's trips by start time across all of its services, so a
+-- trip's neighbours on a service date are the nearest trips in this order
+-- whose services are active. Requires trip time bounds to be computed.
+INSERT OR REPLACE INTO block_trip_order (trip_id, block_id, service_id, block_sequence, start_time, end_time)
+SELECT
+    id,
+    block_id,
+    service_id,
+    ROW_NUMBER() OVER (PARTITION BY block_id ORDER BY min_arrival_time, id) - 1,
+    min_arrival_time,
+    max_departure_time
+FROM trips
+WHERE block_id IS NOT NULL
+  AND block_id != ''
+  AND min_arrival_time IS NOT NULL
+  AND max_departure_time IS NOT NULL;
+
+-- name: GetBlockTripOrder :one
+SELECT * FROM block_trip_order
+WHERE trip_id = ?;
+
+-- name: GetBlockTripOrderForServices :many
+SELECT * FROM block_trip_order
+WHERE block_id = @block_id
+  AND service_id IN (sqlc.slice('service_ids'))
+ORDER BY block_sequence;
+
+-- name: GetPreviousTripInBlock :one
+SELECT * FROM block_trip_order
+WHERE block_id = @block_id
+  AND block_sequence < @block_sequence
+  AND service_id IN (sqlc.slice('service_ids'))
+ORDER BY block_sequence DESC
+LIMIT 1;
+
+-- name: GetNextTripInBlock :one
+SELECT * FROM block_trip_order
+WHERE block_id = @block_id
+  AND block_sequence > @block_sequence
+  AND service_id IN (sqlc.slice('service_ids'))
+ORDER BY block_sequence
+LIMIT 1;
+
+-- name: ClearRouteServiceSpans :exec
+DELETE FROM route_service_spans;
+
+-- name: BuildRouteServiceSpans :exec
+-- Spans each route's trips per service ID. A frequency-based trip runs from
+-- the start of its first frequency window to the end of its last window plus
+-- the trip's duration. Requires trip time bounds to be computed.
+INSERT OR REPLACE INTO route_service_spans (route_id, service_id, first_departure_time, last_departure_time)
+SELECT
+    route_id,
+    service_id,
+    MIN(start_time),
+    MAX(end_time)
+FROM (
+    SELECT
+        t.route_id,
+        t.service_id,
+        COALESCE(MIN(f.start_time), t.min_arrival_time) AS start_time,
+        COALESCE(MAX(f.end_time) + t.max_departure_time - t.min_arrival_time, t.max_departure_time) AS end_time
+    FROM trips t
+    LEFT JOIN frequencies f ON f.trip_id = t.id
+    WHERE t.min_arrival_time IS NOT NULL
+      AND t.max_departure_time IS NOT NULL
+    GROUP BY t.id
+)
+GROUP BY route_id, service_id;
+
+-- name: GetRouteServiceSpans :many
+SELECT * FROM route_service_spans
+WHERE route_id = @route_id
+  AND service_id IN (sqlc.slice('service_ids'));
+
+-- name: GetRouteServiceSpansForAgency :many
+SELECT s.* FROM route_service_spans s
+JOIN routes r ON r.id = s.route_id
+WHERE r.agency_id = @agency_id
+  AND s.service_id IN (sqlc.slice('service_ids'));
+
 -- name: CreateBlockLayover :exec
 INSERT INTO block_layover (
     block_id,
@@ -1113,6 +1275,23 @@ WHERE t.max_departure_time >= sqlc.arg('from_time')
   AND bte.block_trip_index_id IN (sqlc.slice('index_ids'))
   AND bte.service_id IN (sqlc.slice('service_ids'));
 
+-- name: GetBlockTripsForAgency :many
+-- All block trips of an agency on the given services, grouped by block and
+-- ordered by start time within each block.
+SELECT
+    t.id,
+    t.block_id,
+    t.route_id,
+    t.min_arrival_time,
+    t.max_departure_time
+FROM trips t
+JOIN routes r ON r.id = t.route_id
+WHERE r.agency_id = sqlc.arg('agency_id')
+  AND t.block_id IS NOT NULL
+  AND t.block_id != ''
+  AND t.service_id IN (sqlc.slice('service_ids'))
+ORDER BY t.block_id, t.min_arrival_time, t.id;
+
 -- name: GetActiveTripInBlockAtTime :one
 -- Find the currently active trip in a specific block at the given time
 -- Returns the trip whose stop times contain the current time (with late/early windows)
@@ -1224,6 +1403,143 @@ SELECT * FROM problem_reports_stop
 WHERE stop_id = ?
 ORDER BY created_at DESC;
 
+-- name: DeleteProblemReportsTripBefore :execrows
+DELETE FROM problem_reports_trip
+WHERE created_at < @before;
+
+-- name: DeleteProblemReportsStopBefore :execrows
+DELETE FROM problem_reports_stop
+WHERE created_at < @before;
+
+-- Developer Portal Queries
+
+-- name: CreateDeveloperAPIKey :one
+INSERT INTO developer_api_keys (
+    email,
+    name,
+    verification_token_hash,
+    status,
+    tier,
+    created_at,
+    verification_expires_at
+) VALUES (?, ?, ?, 'pending', 'standard', ?, ?)
+RETURNING *;
+
+-- name: CountOpenDeveloperAPIKeysByEmail :one
+-- Counts active keys plus signups still awaiting verification.
+SELECT COUNT(*) FROM developer_api_keys
+WHERE email = @email
+  AND (status = 'active' OR (status = 'pending' AND verification_expires_at > @now));
+
+-- name: GetDeveloperAPIKeyByVerificationToken :one
+SELECT * FROM developer_api_keys
+WHERE verification_token_hash = ?;
+
+-- name: ActivateDeveloperAPIKey :one
+UPDATE developer_api_keys
+SET status = 'active', api_key_hash = @api_key_hash, verified_at = @verified_at
+WHERE id = @id AND status = 'pending'
+RETURNING *;
+
+-- name: GetDeveloperAPIKey :one
+SELECT * FROM developer_api_keys
+WHERE id = ?;
+
+-- name: DeleteExpiredDeveloperAPIKeySignups :execrows
+-- Removes signups whose verification link expired before @before.
+DELETE FROM developer_api_keys
+WHERE status = 'pending' AND verification_expires_at < @before;
+
+-- name: GetActiveDeveloperAPIKey :one
+SELECT * FROM developer_api_keys
+WHERE api_key_hash = ? AND status = 'active';
+
+-- name: RequestDeveloperAPIKeyQuota :one
+UPDATE developer_api_keys
+SET quota_request = @quota_request, quota_requested_at = @quota_requested_at
+WHERE id = @id AND status = 'active'
+RETURNING *;
+
+-- name: ListDeveloperAPIKeyQuotaRequests :many
+SELECT * FROM developer_api_keys
+WHERE status = 'active' AND quota_request IS NOT NULL
+ORDER BY quota_requested_at, id;
+
+-- name: DecideDeveloperAPIKeyQuota :one
+UPDATE developer_api_keys
+SET tier = @tier, quota_request = NULL, quota_requested_at = NULL
+WHERE id = @id AND status = 'active' AND quota_request IS NOT NULL
+RETURNING *;
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    key_hash,
+    contact_email,
+    description,
+    rate_limit,
+    created_at
+) VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT * FROM api_keys
+WHERE id = ?;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = ? AND revoked_at IS NULL;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY id;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = @revoked_at
+WHERE id = @id AND revoked_at IS NULL
+RETURNING *;
+
+-- name: AddAPIKeyUsage :exec
+INSERT INTO api_key_usage (day, key_fingerprint, endpoint, requests)
+VALUES (@day, @key_fingerprint, @endpoint, @requests)
+ON CONFLICT(day, key_fingerprint, endpoint) DO UPDATE SET requests = requests + excluded.requests;
+
+-- name: ListAPIKeyUsage :many
+-- Newest day first; days before @since are skipped. An empty
+-- @key_fingerprint matches every key.
+SELECT * FROM api_key_usage
+WHERE day >= @since AND (CAST(@key_fingerprint AS TEXT) = '' OR key_fingerprint = @key_fingerprint)
+ORDER BY day DESC, key_fingerprint, endpoint;
+
+-- name: DeleteAPIKeyUsageBefore :execrows
+DELETE FROM api_key_usage
+WHERE day < @day;
+
+-- name: CreateAdminAuditEntry :one
+INSERT INTO admin_audit_log (
+    actor,
+    action,
+    target,
+    previous_value,
+    new_value,
+    idempotency_key,
+    response_status,
+    response_body,
+    created_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAdminAuditEntryByIdempotencyKey :one
+SELECT * FROM admin_audit_log
+WHERE idempotency_key = ?;
+
+-- name: ListAdminAuditEntries :many
+-- Newest first; entries older than @since are skipped.
+SELECT * FROM admin_audit_log
+WHERE created_at >= @since
+ORDER BY created_at DESC, id DESC
+LIMIT @limit;
+
 
 -- name: GetFeedEndDate :one
 SELECT COALESCE(CAST(MAX(max_date) AS TEXT), '') AS feed_end_date
@@ -1294,46 +1610,6 @@ FROM stop_times st
 WHERE st.trip_id = @trip_id AND st.stop_id = @stop_id AND st.stop_sequence = @stop_sequence
 LIMIT 1;
 
--- name: GetBlockTripSequence :one
--- Calculates a trip's zero-based index within its block's ordered sequence,
-WITH BlockTrips AS (
-    SELECT id, ROW_NUMBER() OVER (ORDER BY min_arrival_time) - 1 AS seq
-    FROM trips
-    WHERE block_id = @block_id
-      AND service_id IN (sqlc.slice('service_ids'))
-)
-SELECT seq FROM BlockTrips WHERE id = @trip_id;
-
--- name: GetNextAndPreviousTripsInBlock :one
--- Uses LAG/LEAD window functions to find prev/next trip IDs in one query,
-WITH NavTrips AS (
-    SELECT
-        id,
-        LAG(id)  OVER (ORDER BY min_arrival_time) AS prev_trip_id,
-        LEAD(id) OVER (ORDER BY min_arrival_time) AS next_trip_id
-    FROM trips
-    WHERE block_id = @block_id
-      AND service_id IN (sqlc.slice('service_ids'))
-)
-SELECT prev_trip_id, next_trip_id
-FROM NavTrips
-WHERE id = @trip_id;
-
--- name: GetFirstStopOfNextTripInBlock :one
--- Uses LEAD() to find the next trip and directly fetches its first stop,
-SELECT st.*
-FROM stop_times st
-WHERE st.trip_id = (
-    SELECT next_trip_id FROM (
-        SELECT id, LEAD(id) OVER (ORDER BY min_arrival_time) AS next_trip_id
-        FROM trips
-        WHERE block_id = @block_id
-          AND service_id IN (sqlc.slice('service_ids'))
-    ) WHERE id = @trip_id
-)
-ORDER BY st.stop_sequence ASC
-LIMIT 1;
-
 -- name: GetStopBoundsPerAgency :many
 SELECT
     r.agency_id,
@@ -1351,3 +1627,272 @@ GROUP BY
     r.agency_id;
 
 
+
+-- name: CreateBookingRule :exec
+INSERT OR IGNORE INTO booking_rules (
+    id,
+    booking_type,
+    prior_notice_duration_min,
+    prior_notice_duration_max,
+    prior_notice_last_day,
+    prior_notice_last_time,
+    prior_notice_start_day,
+    prior_notice_start_time,
+    prior_notice_service_id,
+    message,
+    pickup_message,
+    drop_off_message,
+    phone_number,
+    info_url,
+    booking_url
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: CreateLocationGroup :exec
+INSERT OR IGNORE INTO location_groups (id, name) VALUES (?, ?);
+
+-- name: CreateLocationGroupStop :exec
+INSERT OR IGNORE INTO location_group_stops (location_group_id, stop_id) VALUES (?, ?);
+
+-- name: CreateFlexStopTime :exec
+INSERT OR IGNORE INTO flex_stop_times (
+    trip_id,
+    stop_sequence,
+    route_id,
+    stop_id,
+    location_group_id,
+    start_pickup_drop_off_window,
+    end_pickup_drop_off_window,
+    pickup_booking_rule_id,
+    drop_off_booking_rule_id
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetBookingRulesForStop :many
+-- Booking rules of flexible service at a stop, either directly or through a
+-- location group containing the stop.
+SELECT DISTINCT br.*
+FROM booking_rules br
+JOIN flex_stop_times fst
+    ON br.id IN (fst.pickup_booking_rule_id, fst.drop_off_booking_rule_id)
+WHERE fst.location_group_id IN (
+        SELECT lgs.location_group_id FROM location_group_stops lgs WHERE lgs.stop_id = @stop_id
+   )
+   OR fst.stop_id = @stop_id
+ORDER BY br.id;
+
+-- name: GetBookingRulesForRoute :many
+SELECT DISTINCT br.*
+FROM booking_rules br
+JOIN flex_stop_times fst
+    ON br.id IN (fst.pickup_booking_rule_id, fst.drop_off_booking_rule_id)
+WHERE fst.route_id = ?
+ORDER BY br.id;
+
+-- name: GetBookingRulesByIDs :many
+SELECT * FROM booking_rules
+WHERE id IN (sqlc.slice('ids'))
+ORDER BY id;
+
+-- name: GetFlexStopTimesForTrip :many
+SELECT * FROM flex_stop_times
+WHERE trip_id = ?
+ORDER BY stop_sequence;
+
+-- name: GetFlexStopTimesForStop :many
+-- Flexible service at a stop, either directly or through a location group
+-- containing the stop. Only trips with a service calendar are returned, as
+-- the others cannot be placed on a service date.
+SELECT
+    fst.trip_id,
+    fst.stop_sequence,
+    fst.route_id,
+    fst.start_pickup_drop_off_window,
+    fst.end_pickup_drop_off_window,
+    fst.pickup_booking_rule_id,
+    fst.drop_off_booking_rule_id,
+    t.service_id,
+    t.trip_headsign
+FROM flex_stop_times fst
+JOIN trips t ON t.id = fst.trip_id
+WHERE fst.stop_id = @stop_id
+   OR fst.location_group_id IN (
+        SELECT lgs.location_group_id FROM location_group_stops lgs WHERE lgs.stop_id = @stop_id
+   )
+ORDER BY fst.trip_id, fst.stop_sequence;
+
+-- name: GetShapeIDsWithinBounds :many
+SELECT DISTINCT shape_id
+FROM shapes
+WHERE lat >= @min_lat AND lat <= @max_lat
+  AND lon >= @min_lon AND lon <= @max_lon;
+
+-- name: GetRoutesForShapeIDs :many
+SELECT DISTINCT
+    t.shape_id,
+    r.id AS route_id,
+    r.agency_id,
+    r.short_name,
+    r.long_name,
+    r.type,
+    r.color
+FROM trips t
+JOIN routes r ON r.id = t.route_id
+WHERE t.shape_id IN (sqlc.slice('shape_ids'))
+ORDER BY t.shape_id, r.id;
+
+-- name: GetStopsByCode :many
+SELECT *
+FROM stops
+WHERE code = ?
+ORDER BY id;
+
+-- name: CreateFareAttribute :exec
+INSERT OR IGNORE INTO fare_attributes (
+    id,
+    price,
+    currency_type,
+    payment_method,
+    transfers,
+    agency_id,
+    transfer_duration
+) VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: CreateFareRule :exec
+INSERT INTO fare_rules (fare_id, route_id, origin_id, destination_id, contains_id) VALUES (?, ?, ?, ?, ?);
+
+-- name: CreateFareMedia :exec
+INSERT OR IGNORE INTO fare_media (id, name, fare_media_type) VALUES (?, ?, ?);
+
+-- name: CreateFareProduct :exec
+INSERT OR IGNORE INTO fare_products (fare_product_id, fare_media_id, rider_category_id, name, amount, currency) VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: CreateRiderCategory :exec
+INSERT OR IGNORE INTO rider_categories (id, name, is_default_fare_category, eligibility_url) VALUES (?, ?, ?, ?);
+
+-- name: CreateArea :exec
+INSERT OR IGNORE INTO areas (id, name) VALUES (?, ?);
+
+-- name: CreateStopArea :exec
+INSERT OR IGNORE INTO stop_areas (area_id, stop_id) VALUES (?, ?);
+
+-- name: CreateFareLegRule :exec
+INSERT INTO fare_leg_rules (
+    leg_group_id,
+    network_id,
+    from_area_id,
+    to_area_id,
+    fare_product_id,
+    rule_priority
+) VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: CreateRouteNetwork :exec
+INSERT OR IGNORE INTO route_networks (route_id, network_id) VALUES (?, ?);
+
+-- name: GetFareAttributesForRoute :many
+-- Fares v1 fares that can apply to a route: those with a rule for the route,
+-- with a zone-only rule, or with no rules at all, which apply agency-wide.
+SELECT fa.*
+FROM fare_attributes fa
+WHERE (fa.agency_id IS NULL OR fa.agency_id = @agency_id)
+  AND (
+        fa.id IN (
+            SELECT fr.fare_id FROM fare_rules fr WHERE fr.route_id = @route_id OR fr.route_id IS NULL
+        )
+     OR NOT EXISTS (SELECT 1 FROM fare_rules fr WHERE fr.fare_id = fa.id)
+  )
+ORDER BY fa.id;
+
+-- name: GetFareRulesForRoute :many
+SELECT *
+FROM fare_rules
+WHERE route_id = @route_id OR route_id IS NULL
+ORDER BY fare_id, id;
+
+-- name: GetFareLegRulesForRoute :many
+-- Fares v2 leg rules that can apply to a route: those for one of its networks
+-- and those without a network, which match every leg.
+SELECT *
+FROM fare_leg_rules
+WHERE network_id IS NULL
+   OR network_id IN (SELECT rn.network_id FROM route_networks rn WHERE rn.route_id = @route_id)
+ORDER BY rule_priority DESC, fare_product_id, id;
+
+-- name: GetFareLegRules :many
+SELECT *
+FROM fare_leg_rules
+ORDER BY rule_priority DESC, fare_product_id, id;
+
+-- name: GetNetworksForRoute :many
+SELECT network_id
+FROM route_networks
+WHERE route_id = @route_id
+ORDER BY network_id;
+
+-- name: GetAreasForStop :many
+-- Fare areas of a stop, including those of its parent station.
+SELECT DISTINCT sa.area_id
+FROM stop_areas sa
+WHERE sa.stop_id = @stop_id
+   OR sa.stop_id = (SELECT s.parent_station FROM stops s WHERE s.id = @stop_id)
+ORDER BY sa.area_id;
+
+-- name: GetFareProductsByIDs :many
+SELECT *
+FROM fare_products
+WHERE fare_product_id IN (sqlc.slice('fare_product_ids'))
+ORDER BY fare_product_id, rider_category_id, fare_media_id;
+
+-- name: GetFareMediaByIDs :many
+SELECT *
+FROM fare_media
+WHERE id IN (sqlc.slice('fare_media_ids'))
+ORDER BY id;
+
+-- name: GetRiderCategoriesByIDs :many
+SELECT *
+FROM rider_categories
+WHERE id IN (sqlc.slice('rider_category_ids'))
+ORDER BY id;
+
+-- name: CreateTransfer :exec
+INSERT INTO transfers (
+    from_stop_id,
+    to_stop_id,
+    from_route_id,
+    to_route_id,
+    from_trip_id,
+    to_trip_id,
+    transfer_type,
+    min_transfer_time,
+    generated,
+    distance
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetTransfersFromStop :many
+SELECT *
+FROM transfers
+WHERE from_stop_id = @stop_id
+ORDER BY to_stop_id, id;
+
+-- name: RecordArrivalHistory :exec
+INSERT OR REPLACE INTO arrival_history (
+    service_date,
+    trip_id,
+    stop_sequence,
+    stop_id,
+    vehicle_id,
+    actual_arrival,
+    actual_departure,
+    delay,
+    occupancy_status,
+    recorded_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListArrivalHistoryForTrip :many
+SELECT stop_sequence, delay, occupancy_status
+FROM arrival_history
+WHERE trip_id = @trip_id
+ORDER BY stop_sequence, service_date;
+
+-- name: DeleteArrivalHistoryBefore :execrows
+DELETE FROM arrival_history
+WHERE recorded_at < @recorded_at;
@@ -11,6 +11,116 @@ import (
 	"strings"
 )
 
+const activateDeveloperAPIKey = `-- name: ActivateDeveloperAPIKey :one
+UPDATE developer_api_keys
+SET status = 'active', api_key_hash = ?1, verified_at = ?2
+WHERE id = ?3 AND status = 'pending'
+RETURNING id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at
+`
+
+type ActivateDeveloperAPIKeyParams struct {
+	ApiKeyHash sql.NullString
+	VerifiedAt sql.NullInt64
+	ID         int64
+}
+
+func (q *Queries) ActivateDeveloperAPIKey(ctx context.Context, arg ActivateDeveloperAPIKeyParams) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.activateDeveloperAPIKeyStmt, activateDeveloperAPIKey, arg.ApiKeyHash, arg.VerifiedAt, arg.ID)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const addAPIKeyUsage = `-- name: AddAPIKeyUsage :exec
+INSERT INTO api_key_usage (day, key_fingerprint, endpoint, requests)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT(day, key_fingerprint, endpoint) DO UPDATE SET requests = requests + excluded.requests
+`
+
+type AddAPIKeyUsageParams struct {
+	Day            string
+	KeyFingerprint string
+	Endpoint       string
+	Requests       int64
+}
+
+func (q *Queries) AddAPIKeyUsage(ctx context.Context, arg AddAPIKeyUsageParams) error {
+	_, err := q.exec(ctx, q.addAPIKeyUsageStmt, addAPIKeyUsage,
+		arg.Day,
+		arg.KeyFingerprint,
+		arg.Endpoint,
+		arg.Requests,
+	)
+	return err
+}
+
+const buildBlockTripOrder = `-- name: BuildBlockTripOrder :exec
+INSERT OR REPLACE INTO block_trip_order (trip_id, block_id, service_id, block_sequence, start_time, end_time)
+SELECT
+    id,
+    block_id,
+    service_id,
+    ROW_NUMBER() OVER (PARTITION BY block_id ORDER BY min_arrival_time, id) - 1,
+    min_arrival_time,
+    max_departure_time
+FROM trips
+WHERE block_id IS NOT NULL
+  AND block_id != ''
+  AND min_arrival_time IS NOT NULL
+  AND max_departure_time IS NOT NULL
+`
+
+// Orders each block's trips by start time across all of its services, so a
+// trip's neighbours on a service date are the nearest trips in this order
+// whose services are active. Requires trip time bounds to be computed.
+func (q *Queries) BuildBlockTripOrder(ctx context.Context) error {
+	_, err := q.exec(ctx, q.buildBlockTripOrderStmt, buildBlockTripOrder)
+	return err
+}
+
+const buildRouteServiceSpans = `-- name: BuildRouteServiceSpans :exec
+INSERT OR REPLACE INTO route_service_spans (route_id, service_id, first_departure_time, last_departure_time)
+SELECT
+    route_id,
+    service_id,
+    MIN(start_time),
+    MAX(end_time)
+FROM (
+    SELECT
+        t.route_id,
+        t.service_id,
+        COALESCE(MIN(f.start_time), t.min_arrival_time) AS start_time,
+        COALESCE(MAX(f.end_time) + t.max_departure_time - t.min_arrival_time, t.max_departure_time) AS end_time
+    FROM trips t
+    LEFT JOIN frequencies f ON f.trip_id = t.id
+    WHERE t.min_arrival_time IS NOT NULL
+      AND t.max_departure_time IS NOT NULL
+    GROUP BY t.id
+)
+GROUP BY route_id, service_id
+`
+
+// Spans each route's trips per service ID. A frequency-based trip runs from
+// the start of its first frequency window to the end of its last window plus
+// the trip's duration. Requires trip time bounds to be computed.
+func (q *Queries) BuildRouteServiceSpans(ctx context.Context) error {
+	_, err := q.exec(ctx, q.buildRouteServiceSpansStmt, buildRouteServiceSpans)
+	return err
+}
+
 const bulkUpdateTripTimeBounds = `-- name: BulkUpdateTripTimeBounds :exec
 UPDATE trips
 SET
@@ -33,6 +143,15 @@ func (q *Queries) ClearAgencies(ctx context.Context) error {
 	return err
 }
 
+const clearAreas = `-- name: ClearAreas :exec
+DELETE FROM areas
+`
+
+func (q *Queries) ClearAreas(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearAreasStmt, clearAreas)
+	return err
+}
+
 const clearBlockLayovers = `-- name: ClearBlockLayovers :exec
 DELETE FROM block_layover
 `
@@ -60,6 +179,24 @@ func (q *Queries) ClearBlockTripIndices(ctx context.Context) error {
 	return err
 }
 
+const clearBlockTripOrder = `-- name: ClearBlockTripOrder :exec
+DELETE FROM block_trip_order
+`
+
+func (q *Queries) ClearBlockTripOrder(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearBlockTripOrderStmt, clearBlockTripOrder)
+	return err
+}
+
+const clearBookingRules = `-- name: ClearBookingRules :exec
+DELETE FROM booking_rules
+`
+
+func (q *Queries) ClearBookingRules(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearBookingRulesStmt, clearBookingRules)
+	return err
+}
+
 const clearCalendar = `-- name: ClearCalendar :exec
 DELETE FROM calendar
 `
@@ -78,6 +215,60 @@ func (q *Queries) ClearCalendarDates(ctx context.Context) error {
 	return err
 }
 
+const clearFareAttributes = `-- name: ClearFareAttributes :exec
+DELETE FROM fare_attributes
+`
+
+func (q *Queries) ClearFareAttributes(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearFareAttributesStmt, clearFareAttributes)
+	return err
+}
+
+const clearFareLegRules = `-- name: ClearFareLegRules :exec
+DELETE FROM fare_leg_rules
+`
+
+func (q *Queries) ClearFareLegRules(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearFareLegRulesStmt, clearFareLegRules)
+	return err
+}
+
+const clearFareMedia = `-- name: ClearFareMedia :exec
+DELETE FROM fare_media
+`
+
+func (q *Queries) ClearFareMedia(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearFareMediaStmt, clearFareMedia)
+	return err
+}
+
+const clearFareProducts = `-- name: ClearFareProducts :exec
+DELETE FROM fare_products
+`
+
+func (q *Queries) ClearFareProducts(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearFareProductsStmt, clearFareProducts)
+	return err
+}
+
+const clearFareRules = `-- name: ClearFareRules :exec
+DELETE FROM fare_rules
+`
+
+func (q *Queries) ClearFareRules(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearFareRulesStmt, clearFareRules)
+	return err
+}
+
+const clearFlexStopTimes = `-- name: ClearFlexStopTimes :exec
+DELETE FROM flex_stop_times
+`
+
+func (q *Queries) ClearFlexStopTimes(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearFlexStopTimesStmt, clearFlexStopTimes)
+	return err
+}
+
 const clearFrequencies = `-- name: ClearFrequencies :exec
 DELETE FROM frequencies
 `
@@ -87,6 +278,51 @@ func (q *Queries) ClearFrequencies(ctx context.Context) error {
 	return err
 }
 
+const clearLocationGroupStops = `-- name: ClearLocationGroupStops :exec
+DELETE FROM location_group_stops
+`
+
+func (q *Queries) ClearLocationGroupStops(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearLocationGroupStopsStmt, clearLocationGroupStops)
+	return err
+}
+
+const clearLocationGroups = `-- name: ClearLocationGroups :exec
+DELETE FROM location_groups
+`
+
+func (q *Queries) ClearLocationGroups(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearLocationGroupsStmt, clearLocationGroups)
+	return err
+}
+
+const clearRiderCategories = `-- name: ClearRiderCategories :exec
+DELETE FROM rider_categories
+`
+
+func (q *Queries) ClearRiderCategories(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearRiderCategoriesStmt, clearRiderCategories)
+	return err
+}
+
+const clearRouteNetworks = `-- name: ClearRouteNetworks :exec
+DELETE FROM route_networks
+`
+
+func (q *Queries) ClearRouteNetworks(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearRouteNetworksStmt, clearRouteNetworks)
+	return err
+}
+
+const clearRouteServiceSpans = `-- name: ClearRouteServiceSpans :exec
+DELETE FROM route_service_spans
+`
+
+func (q *Queries) ClearRouteServiceSpans(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearRouteServiceSpansStmt, clearRouteServiceSpans)
+	return err
+}
+
 const clearRoutes = `-- name: ClearRoutes :exec
 DELETE FROM routes
 `
@@ -105,6 +341,15 @@ func (q *Queries) ClearShapes(ctx context.Context) error {
 	return err
 }
 
+const clearStopAreas = `-- name: ClearStopAreas :exec
+DELETE FROM stop_areas
+`
+
+func (q *Queries) ClearStopAreas(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearStopAreasStmt, clearStopAreas)
+	return err
+}
+
 const clearStopTimes = `-- name: ClearStopTimes :exec
 DELETE FROM stop_times
 `
@@ -123,6 +368,15 @@ func (q *Queries) ClearStops(ctx context.Context) error {
 	return err
 }
 
+const clearTransfers = `-- name: ClearTransfers :exec
+DELETE FROM transfers
+`
+
+func (q *Queries) ClearTransfers(ctx context.Context) error {
+	_, err := q.exec(ctx, q.clearTransfersStmt, clearTransfers)
+	return err
+}
+
 const clearTrips = `-- name: ClearTrips :exec
 DELETE FROM trips
 `
@@ -143,6 +397,25 @@ func (q *Queries) CountAgencies(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countOpenDeveloperAPIKeysByEmail = `-- name: CountOpenDeveloperAPIKeysByEmail :one
+SELECT COUNT(*) FROM developer_api_keys
+WHERE email = ?1
+  AND (status = 'active' OR (status = 'pending' AND verification_expires_at > ?2))
+`
+
+type CountOpenDeveloperAPIKeysByEmailParams struct {
+	Email string
+	Now   int64
+}
+
+// Counts active keys plus signups still awaiting verification.
+func (q *Queries) CountOpenDeveloperAPIKeysByEmail(ctx context.Context, arg CountOpenDeveloperAPIKeysByEmailParams) (int64, error) {
+	row := q.queryRow(ctx, q.countOpenDeveloperAPIKeysByEmailStmt, countOpenDeveloperAPIKeysByEmail, arg.Email, arg.Now)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countRoutes = `-- name: CountRoutes :one
 SELECT COUNT(*) FROM routes
 `
@@ -176,6 +449,101 @@ func (q *Queries) CountTrips(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    key_hash,
+    contact_email,
+    description,
+    rate_limit,
+    created_at
+) VALUES (?, ?, ?, ?, ?)
+RETURNING id, key_hash, contact_email, description, rate_limit, created_at, revoked_at
+`
+
+type CreateAPIKeyParams struct {
+	KeyHash      string
+	ContactEmail string
+	Description  string
+	RateLimit    int64
+	CreatedAt    int64
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.queryRow(ctx, q.createAPIKeyStmt, createAPIKey,
+		arg.KeyHash,
+		arg.ContactEmail,
+		arg.Description,
+		arg.RateLimit,
+		arg.CreatedAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.KeyHash,
+		&i.ContactEmail,
+		&i.Description,
+		&i.RateLimit,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const createAdminAuditEntry = `-- name: CreateAdminAuditEntry :one
+INSERT INTO admin_audit_log (
+    actor,
+    action,
+    target,
+    previous_value,
+    new_value,
+    idempotency_key,
+    response_status,
+    response_body,
+    created_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, actor, "action", target, previous_value, new_value, idempotency_key, response_status, response_body, created_at
+`
+
+type CreateAdminAuditEntryParams struct {
+	Actor          string
+	Action         string
+	Target         string
+	PreviousValue  sql.NullString
+	NewValue       sql.NullString
+	IdempotencyKey sql.NullString
+	ResponseStatus int64
+	ResponseBody   []byte
+	CreatedAt      int64
+}
+
+func (q *Queries) CreateAdminAuditEntry(ctx context.Context, arg CreateAdminAuditEntryParams) (AdminAuditLog, error) {
+	row := q.queryRow(ctx, q.createAdminAuditEntryStmt, createAdminAuditEntry,
+		arg.Actor,
+		arg.Action,
+		arg.Target,
+		arg.PreviousValue,
+		arg.NewValue,
+		arg.IdempotencyKey,
+		arg.ResponseStatus,
+		arg.ResponseBody,
+		arg.CreatedAt,
+	)
+	var i AdminAuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.Actor,
+		&i.Action,
+		&i.Target,
+		&i.PreviousValue,
+		&i.NewValue,
+		&i.IdempotencyKey,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createAgency = `-- name: CreateAgency :one
 INSERT
 OR REPLACE INTO agencies (
@@ -228,6 +596,20 @@ func (q *Queries) CreateAgency(ctx context.Context, arg CreateAgencyParams) (Age
 	return i, err
 }
 
+const createArea = `-- name: CreateArea :exec
+INSERT OR IGNORE INTO areas (id, name) VALUES (?, ?)
+`
+
+type CreateAreaParams struct {
+	ID   string
+	Name sql.NullString
+}
+
+func (q *Queries) CreateArea(ctx context.Context, arg CreateAreaParams) error {
+	_, err := q.exec(ctx, q.createAreaStmt, createArea, arg.ID, arg.Name)
+	return err
+}
+
 const createBlockLayover = `-- name: CreateBlockLayover :exec
 INSERT INTO block_layover (
     block_id,
@@ -329,6 +711,65 @@ func (q *Queries) CreateBlockTripIndex(ctx context.Context, arg CreateBlockTripI
 	return id, err
 }
 
+const createBookingRule = `-- name: CreateBookingRule :exec
+INSERT OR IGNORE INTO booking_rules (
+    id,
+    booking_type,
+    prior_notice_duration_min,
+    prior_notice_duration_max,
+    prior_notice_last_day,
+    prior_notice_last_time,
+    prior_notice_start_day,
+    prior_notice_start_time,
+    prior_notice_service_id,
+    message,
+    pickup_message,
+    drop_off_message,
+    phone_number,
+    info_url,
+    booking_url
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateBookingRuleParams struct {
+	ID                     string
+	BookingType            int64
+	PriorNoticeDurationMin sql.NullInt64
+	PriorNoticeDurationMax sql.NullInt64
+	PriorNoticeLastDay     sql.NullInt64
+	PriorNoticeLastTime    sql.NullString
+	PriorNoticeStartDay    sql.NullInt64
+	PriorNoticeStartTime   sql.NullString
+	PriorNoticeServiceID   sql.NullString
+	Message                sql.NullString
+	PickupMessage          sql.NullString
+	DropOffMessage         sql.NullString
+	PhoneNumber            sql.NullString
+	InfoUrl                sql.NullString
+	BookingUrl             sql.NullString
+}
+
+func (q *Queries) CreateBookingRule(ctx context.Context, arg CreateBookingRuleParams) error {
+	_, err := q.exec(ctx, q.createBookingRuleStmt, createBookingRule,
+		arg.ID,
+		arg.BookingType,
+		arg.PriorNoticeDurationMin,
+		arg.PriorNoticeDurationMax,
+		arg.PriorNoticeLastDay,
+		arg.PriorNoticeLastTime,
+		arg.PriorNoticeStartDay,
+		arg.PriorNoticeStartTime,
+		arg.PriorNoticeServiceID,
+		arg.Message,
+		arg.PickupMessage,
+		arg.DropOffMessage,
+		arg.PhoneNumber,
+		arg.InfoUrl,
+		arg.BookingUrl,
+	)
+	return err
+}
+
 const createCalendar = `-- name: CreateCalendar :one
 INSERT
 OR REPLACE INTO calendar (
@@ -409,6 +850,226 @@ func (q *Queries) CreateCalendarDate(ctx context.Context, arg CreateCalendarDate
 	return i, err
 }
 
+const createDeveloperAPIKey = `-- name: CreateDeveloperAPIKey :one
+
+INSERT INTO developer_api_keys (
+    email,
+    name,
+    verification_token_hash,
+    status,
+    tier,
+    created_at,
+    verification_expires_at
+) VALUES (?, ?, ?, 'pending', 'standard', ?, ?)
+RETURNING id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at
+`
+
+type CreateDeveloperAPIKeyParams struct {
+	Email                 string
+	Name                  string
+	VerificationTokenHash string
+	CreatedAt             int64
+	VerificationExpiresAt int64
+}
+
+// Developer Portal Queries
+func (q *Queries) CreateDeveloperAPIKey(ctx context.Context, arg CreateDeveloperAPIKeyParams) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.createDeveloperAPIKeyStmt, createDeveloperAPIKey,
+		arg.Email,
+		arg.Name,
+		arg.VerificationTokenHash,
+		arg.CreatedAt,
+		arg.VerificationExpiresAt,
+	)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const createFareAttribute = `-- name: CreateFareAttribute :exec
+INSERT OR IGNORE INTO fare_attributes (
+    id,
+    price,
+    currency_type,
+    payment_method,
+    transfers,
+    agency_id,
+    transfer_duration
+) VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateFareAttributeParams struct {
+	ID               string
+	Price            float64
+	CurrencyType     string
+	PaymentMethod    int64
+	Transfers        sql.NullInt64
+	AgencyID         sql.NullString
+	TransferDuration sql.NullInt64
+}
+
+func (q *Queries) CreateFareAttribute(ctx context.Context, arg CreateFareAttributeParams) error {
+	_, err := q.exec(ctx, q.createFareAttributeStmt, createFareAttribute,
+		arg.ID,
+		arg.Price,
+		arg.CurrencyType,
+		arg.PaymentMethod,
+		arg.Transfers,
+		arg.AgencyID,
+		arg.TransferDuration,
+	)
+	return err
+}
+
+const createFareLegRule = `-- name: CreateFareLegRule :exec
+INSERT INTO fare_leg_rules (
+    leg_group_id,
+    network_id,
+    from_area_id,
+    to_area_id,
+    fare_product_id,
+    rule_priority
+) VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateFareLegRuleParams struct {
+	LegGroupID    sql.NullString
+	NetworkID     sql.NullString
+	FromAreaID    sql.NullString
+	ToAreaID      sql.NullString
+	FareProductID string
+	RulePriority  int64
+}
+
+func (q *Queries) CreateFareLegRule(ctx context.Context, arg CreateFareLegRuleParams) error {
+	_, err := q.exec(ctx, q.createFareLegRuleStmt, createFareLegRule,
+		arg.LegGroupID,
+		arg.NetworkID,
+		arg.FromAreaID,
+		arg.ToAreaID,
+		arg.FareProductID,
+		arg.RulePriority,
+	)
+	return err
+}
+
+const createFareMedia = `-- name: CreateFareMedia :exec
+INSERT OR IGNORE INTO fare_media (id, name, fare_media_type) VALUES (?, ?, ?)
+`
+
+type CreateFareMediaParams struct {
+	ID            string
+	Name          sql.NullString
+	FareMediaType int64
+}
+
+func (q *Queries) CreateFareMedia(ctx context.Context, arg CreateFareMediaParams) error {
+	_, err := q.exec(ctx, q.createFareMediaStmt, createFareMedia, arg.ID, arg.Name, arg.FareMediaType)
+	return err
+}
+
+const createFareProduct = `-- name: CreateFareProduct :exec
+INSERT OR IGNORE INTO fare_products (fare_product_id, fare_media_id, rider_category_id, name, amount, currency) VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateFareProductParams struct {
+	FareProductID   string
+	FareMediaID     string
+	RiderCategoryID string
+	Name            sql.NullString
+	Amount          float64
+	Currency        string
+}
+
+func (q *Queries) CreateFareProduct(ctx context.Context, arg CreateFareProductParams) error {
+	_, err := q.exec(ctx, q.createFareProductStmt, createFareProduct,
+		arg.FareProductID,
+		arg.FareMediaID,
+		arg.RiderCategoryID,
+		arg.Name,
+		arg.Amount,
+		arg.Currency,
+	)
+	return err
+}
+
+const createFareRule = `-- name: CreateFareRule :exec
+INSERT INTO fare_rules (fare_id, route_id, origin_id, destination_id, contains_id) VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateFareRuleParams struct {
+	FareID        string
+	RouteID       sql.NullString
+	OriginID      sql.NullString
+	DestinationID sql.NullString
+	ContainsID    sql.NullString
+}
+
+func (q *Queries) CreateFareRule(ctx context.Context, arg CreateFareRuleParams) error {
+	_, err := q.exec(ctx, q.createFareRuleStmt, createFareRule,
+		arg.FareID,
+		arg.RouteID,
+		arg.OriginID,
+		arg.DestinationID,
+		arg.ContainsID,
+	)
+	return err
+}
+
+const createFlexStopTime = `-- name: CreateFlexStopTime :exec
+INSERT OR IGNORE INTO flex_stop_times (
+    trip_id,
+    stop_sequence,
+    route_id,
+    stop_id,
+    location_group_id,
+    start_pickup_drop_off_window,
+    end_pickup_drop_off_window,
+    pickup_booking_rule_id,
+    drop_off_booking_rule_id
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateFlexStopTimeParams struct {
+	TripID                   string
+	StopSequence             int64
+	RouteID                  string
+	StopID                   sql.NullString
+	LocationGroupID          sql.NullString
+	StartPickupDropOffWindow sql.NullInt64
+	EndPickupDropOffWindow   sql.NullInt64
+	PickupBookingRuleID      sql.NullString
+	DropOffBookingRuleID     sql.NullString
+}
+
+func (q *Queries) CreateFlexStopTime(ctx context.Context, arg CreateFlexStopTimeParams) error {
+	_, err := q.exec(ctx, q.createFlexStopTimeStmt, createFlexStopTime,
+		arg.TripID,
+		arg.StopSequence,
+		arg.RouteID,
+		arg.StopID,
+		arg.LocationGroupID,
+		arg.StartPickupDropOffWindow,
+		arg.EndPickupDropOffWindow,
+		arg.PickupBookingRuleID,
+		arg.DropOffBookingRuleID,
+	)
+	return err
+}
+
 const createFrequency = `-- name: CreateFrequency :exec
 INSERT OR IGNORE INTO frequencies (
     trip_id,
@@ -438,9 +1099,37 @@ func (q *Queries) CreateFrequency(ctx context.Context, arg CreateFrequencyParams
 	return err
 }
 
-const createProblemReportStop = `-- name: CreateProblemReportStop :exec
-INSERT INTO problem_reports_stop (
-    stop_id,
+const createLocationGroup = `-- name: CreateLocationGroup :exec
+INSERT OR IGNORE INTO location_groups (id, name) VALUES (?, ?)
+`
+
+type CreateLocationGroupParams struct {
+	ID   string
+	Name sql.NullString
+}
+
+func (q *Queries) CreateLocationGroup(ctx context.Context, arg CreateLocationGroupParams) error {
+	_, err := q.exec(ctx, q.createLocationGroupStmt, createLocationGroup, arg.ID, arg.Name)
+	return err
+}
+
+const createLocationGroupStop = `-- name: CreateLocationGroupStop :exec
+INSERT OR IGNORE INTO location_group_stops (location_group_id, stop_id) VALUES (?, ?)
+`
+
+type CreateLocationGroupStopParams struct {
+	LocationGroupID string
+	StopID          string
+}
+
+func (q *Queries) CreateLocationGroupStop(ctx context.Context, arg CreateLocationGroupStopParams) error {
+	_, err := q.exec(ctx, q.createLocationGroupStopStmt, createLocationGroupStop, arg.LocationGroupID, arg.StopID)
+	return err
+}
+
+const createProblemReportStop = `-- name: CreateProblemReportStop :exec
+INSERT INTO problem_reports_stop (
+    stop_id,
     code,
     user_comment,
     user_lat,
@@ -531,6 +1220,27 @@ func (q *Queries) CreateProblemReportTrip(ctx context.Context, arg CreateProblem
 	return err
 }
 
+const createRiderCategory = `-- name: CreateRiderCategory :exec
+INSERT OR IGNORE INTO rider_categories (id, name, is_default_fare_category, eligibility_url) VALUES (?, ?, ?, ?)
+`
+
+type CreateRiderCategoryParams struct {
+	ID                    string
+	Name                  string
+	IsDefaultFareCategory int64
+	EligibilityUrl        sql.NullString
+}
+
+func (q *Queries) CreateRiderCategory(ctx context.Context, arg CreateRiderCategoryParams) error {
+	_, err := q.exec(ctx, q.createRiderCategoryStmt, createRiderCategory,
+		arg.ID,
+		arg.Name,
+		arg.IsDefaultFareCategory,
+		arg.EligibilityUrl,
+	)
+	return err
+}
+
 const createRoute = `-- name: CreateRoute :one
 INSERT
 OR REPLACE INTO routes (
@@ -544,10 +1254,11 @@ OR REPLACE INTO routes (
     color,
     text_color,
     continuous_pickup,
-    continuous_drop_off
+    continuous_drop_off,
+    sort_order
 )
 VALUES
-    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, agency_id, short_name, long_name, "desc", type, url, color, text_color, continuous_pickup, continuous_drop_off
+    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, agency_id, short_name, long_name, "desc", type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order
 `
 
 type CreateRouteParams struct {
@@ -562,6 +1273,7 @@ type CreateRouteParams struct {
 	TextColor         sql.NullString
 	ContinuousPickup  sql.NullInt64
 	ContinuousDropOff sql.NullInt64
+	SortOrder         sql.NullInt64
 }
 
 func (q *Queries) CreateRoute(ctx context.Context, arg CreateRouteParams) (Route, error) {
@@ -577,6 +1289,7 @@ func (q *Queries) CreateRoute(ctx context.Context, arg CreateRouteParams) (Route
 		arg.TextColor,
 		arg.ContinuousPickup,
 		arg.ContinuousDropOff,
+		arg.SortOrder,
 	)
 	var i Route
 	err := row.Scan(
@@ -591,10 +1304,25 @@ func (q *Queries) CreateRoute(ctx context.Context, arg CreateRouteParams) (Route
 		&i.TextColor,
 		&i.ContinuousPickup,
 		&i.ContinuousDropOff,
+		&i.SortOrder,
 	)
 	return i, err
 }
 
+const createRouteNetwork = `-- name: CreateRouteNetwork :exec
+INSERT OR IGNORE INTO route_networks (route_id, network_id) VALUES (?, ?)
+`
+
+type CreateRouteNetworkParams struct {
+	RouteID   string
+	NetworkID string
+}
+
+func (q *Queries) CreateRouteNetwork(ctx context.Context, arg CreateRouteNetworkParams) error {
+	_, err := q.exec(ctx, q.createRouteNetworkStmt, createRouteNetwork, arg.RouteID, arg.NetworkID)
+	return err
+}
+
 const createShape = `-- name: CreateShape :one
 INSERT
 OR REPLACE INTO shapes (shape_id, lat, lon, shape_pt_sequence, shape_dist_traveled)
@@ -706,6 +1434,20 @@ func (q *Queries) CreateStop(ctx context.Context, arg CreateStopParams) (Stop, e
 	return i, err
 }
 
+const createStopArea = `-- name: CreateStopArea :exec
+INSERT OR IGNORE INTO stop_areas (area_id, stop_id) VALUES (?, ?)
+`
+
+type CreateStopAreaParams struct {
+	AreaID string
+	StopID string
+}
+
+func (q *Queries) CreateStopArea(ctx context.Context, arg CreateStopAreaParams) error {
+	_, err := q.exec(ctx, q.createStopAreaStmt, createStopArea, arg.AreaID, arg.StopID)
+	return err
+}
+
 const createStopTime = `-- name: CreateStopTime :one
 INSERT
 OR REPLACE INTO stop_times (
@@ -766,6 +1508,50 @@ func (q *Queries) CreateStopTime(ctx context.Context, arg CreateStopTimeParams)
 	return i, err
 }
 
+const createTransfer = `-- name: CreateTransfer :exec
+INSERT INTO transfers (
+    from_stop_id,
+    to_stop_id,
+    from_route_id,
+    to_route_id,
+    from_trip_id,
+    to_trip_id,
+    transfer_type,
+    min_transfer_time,
+    generated,
+    distance
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateTransferParams struct {
+	FromStopID      string
+	ToStopID        string
+	FromRouteID     sql.NullString
+	ToRouteID       sql.NullString
+	FromTripID      sql.NullString
+	ToTripID        sql.NullString
+	TransferType    int64
+	MinTransferTime sql.NullInt64
+	Generated       int64
+	Distance        sql.NullFloat64
+}
+
+func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) error {
+	_, err := q.exec(ctx, q.createTransferStmt, createTransfer,
+		arg.FromStopID,
+		arg.ToStopID,
+		arg.FromRouteID,
+		arg.ToRouteID,
+		arg.FromTripID,
+		arg.ToTripID,
+		arg.TransferType,
+		arg.MinTransferTime,
+		arg.Generated,
+		arg.Distance,
+	)
+	return err
+}
+
 const createTrip = `-- name: CreateTrip :one
 INSERT
 OR REPLACE INTO trips (
@@ -834,6 +1620,169 @@ func (q *Queries) CreateTrip(ctx context.Context, arg CreateTripParams) (Trip, e
 	return i, err
 }
 
+const decideDeveloperAPIKeyQuota = `-- name: DecideDeveloperAPIKeyQuota :one
+UPDATE developer_api_keys
+SET tier = ?1, quota_request = NULL, quota_requested_at = NULL
+WHERE id = ?2 AND status = 'active' AND quota_request IS NOT NULL
+RETURNING id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at
+`
+
+type DecideDeveloperAPIKeyQuotaParams struct {
+	Tier string
+	ID   int64
+}
+
+func (q *Queries) DecideDeveloperAPIKeyQuota(ctx context.Context, arg DecideDeveloperAPIKeyQuotaParams) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.decideDeveloperAPIKeyQuotaStmt, decideDeveloperAPIKeyQuota, arg.Tier, arg.ID)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const deleteAPIKeyUsageBefore = `-- name: DeleteAPIKeyUsageBefore :execrows
+DELETE FROM api_key_usage
+WHERE day < ?1
+`
+
+func (q *Queries) DeleteAPIKeyUsageBefore(ctx context.Context, day string) (int64, error) {
+	result, err := q.exec(ctx, q.deleteAPIKeyUsageBeforeStmt, deleteAPIKeyUsageBefore, day)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteArrivalHistoryBefore = `-- name: DeleteArrivalHistoryBefore :execrows
+DELETE FROM arrival_history
+WHERE recorded_at < ?1
+`
+
+func (q *Queries) DeleteArrivalHistoryBefore(ctx context.Context, recordedAt int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteArrivalHistoryBeforeStmt, deleteArrivalHistoryBefore, recordedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredDeveloperAPIKeySignups = `-- name: DeleteExpiredDeveloperAPIKeySignups :execrows
+DELETE FROM developer_api_keys
+WHERE status = 'pending' AND verification_expires_at < ?1
+`
+
+// Removes signups whose verification link expired before @before.
+func (q *Queries) DeleteExpiredDeveloperAPIKeySignups(ctx context.Context, before int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteExpiredDeveloperAPIKeySignupsStmt, deleteExpiredDeveloperAPIKeySignups, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteProblemReportsStopBefore = `-- name: DeleteProblemReportsStopBefore :execrows
+DELETE FROM problem_reports_stop
+WHERE created_at < ?1
+`
+
+func (q *Queries) DeleteProblemReportsStopBefore(ctx context.Context, before int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteProblemReportsStopBeforeStmt, deleteProblemReportsStopBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteProblemReportsTripBefore = `-- name: DeleteProblemReportsTripBefore :execrows
+DELETE FROM problem_reports_trip
+WHERE created_at < ?1
+`
+
+func (q *Queries) DeleteProblemReportsTripBefore(ctx context.Context, before int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteProblemReportsTripBeforeStmt, deleteProblemReportsTripBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAPIKey = `-- name: GetAPIKey :one
+SELECT id, key_hash, contact_email, description, rate_limit, created_at, revoked_at FROM api_keys
+WHERE id = ?
+`
+
+func (q *Queries) GetAPIKey(ctx context.Context, id int64) (ApiKey, error) {
+	row := q.queryRow(ctx, q.getAPIKeyStmt, getAPIKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.KeyHash,
+		&i.ContactEmail,
+		&i.Description,
+		&i.RateLimit,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getActiveAPIKeyByHash = `-- name: GetActiveAPIKeyByHash :one
+SELECT id, key_hash, contact_email, description, rate_limit, created_at, revoked_at FROM api_keys
+WHERE key_hash = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.queryRow(ctx, q.getActiveAPIKeyByHashStmt, getActiveAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.KeyHash,
+		&i.ContactEmail,
+		&i.Description,
+		&i.RateLimit,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getActiveDeveloperAPIKey = `-- name: GetActiveDeveloperAPIKey :one
+SELECT id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at FROM developer_api_keys
+WHERE api_key_hash = ? AND status = 'active'
+`
+
+func (q *Queries) GetActiveDeveloperAPIKey(ctx context.Context, apiKeyHash sql.NullString) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.getActiveDeveloperAPIKeyStmt, getActiveDeveloperAPIKey, apiKeyHash)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
 const getActiveLayoverBlockIDsForRoute = `-- name: GetActiveLayoverBlockIDsForRoute :many
 SELECT DISTINCT block_id
 FROM block_layover
@@ -1235,6 +2184,29 @@ func (q *Queries) GetActiveTripsWithNullBlockForRoute(ctx context.Context, arg G
 	return items, nil
 }
 
+const getAdminAuditEntryByIdempotencyKey = `-- name: GetAdminAuditEntryByIdempotencyKey :one
+SELECT id, actor, "action", target, previous_value, new_value, idempotency_key, response_status, response_body, created_at FROM admin_audit_log
+WHERE idempotency_key = ?
+`
+
+func (q *Queries) GetAdminAuditEntryByIdempotencyKey(ctx context.Context, idempotencyKey sql.NullString) (AdminAuditLog, error) {
+	row := q.queryRow(ctx, q.getAdminAuditEntryByIdempotencyKeyStmt, getAdminAuditEntryByIdempotencyKey, idempotencyKey)
+	var i AdminAuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.Actor,
+		&i.Action,
+		&i.Target,
+		&i.PreviousValue,
+		&i.NewValue,
+		&i.IdempotencyKey,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getAgenciesByIDs = `-- name: GetAgenciesByIDs :many
 SELECT
     id, name, url, timezone, lang, phone, fare_url, email
@@ -1537,6 +2509,38 @@ func (q *Queries) GetAllTripsForRoute(ctx context.Context, routeID string) ([]Tr
 	return items, nil
 }
 
+const getAreasForStop = `-- name: GetAreasForStop :many
+SELECT DISTINCT sa.area_id
+FROM stop_areas sa
+WHERE sa.stop_id = ?1
+   OR sa.stop_id = (SELECT s.parent_station FROM stops s WHERE s.id = ?1)
+ORDER BY sa.area_id
+`
+
+// Fare areas of a stop, including those of its parent station.
+func (q *Queries) GetAreasForStop(ctx context.Context, stopID string) ([]string, error) {
+	rows, err := q.query(ctx, q.getAreasForStopStmt, getAreasForStop, stopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var area_id string
+		if err := rows.Scan(&area_id); err != nil {
+			return nil, err
+		}
+		items = append(items, area_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getArrivalsAndDeparturesForStop = `-- name: GetArrivalsAndDeparturesForStop :many
 SELECT
     st.trip_id,
@@ -1810,27 +2814,40 @@ func (q *Queries) GetBlockTripIndexIDsForRoute(ctx context.Context, arg GetBlock
 	return items, nil
 }
 
-const getBlockTripSequence = `-- name: GetBlockTripSequence :one
-WITH BlockTrips AS (
-    SELECT id, ROW_NUMBER() OVER (ORDER BY min_arrival_time) - 1 AS seq
-    FROM trips
-    WHERE block_id = ?2
-      AND service_id IN (/*SLICE:service_ids*/?)
-)
-SELECT seq FROM BlockTrips WHERE id = ?1
+const getBlockTripOrder = `-- name: GetBlockTripOrder :one
+SELECT trip_id, block_id, service_id, block_sequence, start_time, end_time FROM block_trip_order
+WHERE trip_id = ?
 `
 
-type GetBlockTripSequenceParams struct {
-	TripID     string
-	BlockID    sql.NullString
+func (q *Queries) GetBlockTripOrder(ctx context.Context, tripID string) (BlockTripOrder, error) {
+	row := q.queryRow(ctx, q.getBlockTripOrderStmt, getBlockTripOrder, tripID)
+	var i BlockTripOrder
+	err := row.Scan(
+		&i.TripID,
+		&i.BlockID,
+		&i.ServiceID,
+		&i.BlockSequence,
+		&i.StartTime,
+		&i.EndTime,
+	)
+	return i, err
+}
+
+const getBlockTripOrderForServices = `-- name: GetBlockTripOrderForServices :many
+SELECT trip_id, block_id, service_id, block_sequence, start_time, end_time FROM block_trip_order
+WHERE block_id = ?1
+  AND service_id IN (/*SLICE:service_ids*/?)
+ORDER BY block_sequence
+`
+
+type GetBlockTripOrderForServicesParams struct {
+	BlockID    string
 	ServiceIds []string
 }
 
-// Calculates a trip's zero-based index within its block's ordered sequence,
-func (q *Queries) GetBlockTripSequence(ctx context.Context, arg GetBlockTripSequenceParams) (int64, error) {
-	query := getBlockTripSequence
+func (q *Queries) GetBlockTripOrderForServices(ctx context.Context, arg GetBlockTripOrderForServicesParams) ([]BlockTripOrder, error) {
+	query := getBlockTripOrderForServices
 	var queryParams []interface{}
-	queryParams = append(queryParams, arg.TripID)
 	queryParams = append(queryParams, arg.BlockID)
 	if len(arg.ServiceIds) > 0 {
 		for _, v := range arg.ServiceIds {
@@ -1840,10 +2857,104 @@ func (q *Queries) GetBlockTripSequence(ctx context.Context, arg GetBlockTripSequ
 	} else {
 		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
 	}
-	row := q.queryRow(ctx, nil, query, queryParams...)
-	var seq int64
-	err := row.Scan(&seq)
-	return seq, err
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BlockTripOrder
+	for rows.Next() {
+		var i BlockTripOrder
+		if err := rows.Scan(
+			&i.TripID,
+			&i.BlockID,
+			&i.ServiceID,
+			&i.BlockSequence,
+			&i.StartTime,
+			&i.EndTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBlockTripsForAgency = `-- name: GetBlockTripsForAgency :many
+SELECT
+    t.id,
+    t.block_id,
+    t.route_id,
+    t.min_arrival_time,
+    t.max_departure_time
+FROM trips t
+JOIN routes r ON r.id = t.route_id
+WHERE r.agency_id = ?1
+  AND t.block_id IS NOT NULL
+  AND t.block_id != ''
+  AND t.service_id IN (/*SLICE:service_ids*/?)
+ORDER BY t.block_id, t.min_arrival_time, t.id
+`
+
+type GetBlockTripsForAgencyParams struct {
+	AgencyID   string
+	ServiceIds []string
+}
+
+type GetBlockTripsForAgencyRow struct {
+	ID               string
+	BlockID          sql.NullString
+	RouteID          string
+	MinArrivalTime   sql.NullInt64
+	MaxDepartureTime sql.NullInt64
+}
+
+// All block trips of an agency on the given services, grouped by block and
+// ordered by start time within each block.
+func (q *Queries) GetBlockTripsForAgency(ctx context.Context, arg GetBlockTripsForAgencyParams) ([]GetBlockTripsForAgencyRow, error) {
+	query := getBlockTripsForAgency
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.AgencyID)
+	if len(arg.ServiceIds) > 0 {
+		for _, v := range arg.ServiceIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBlockTripsForAgencyRow
+	for rows.Next() {
+		var i GetBlockTripsForAgencyRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlockID,
+			&i.RouteID,
+			&i.MinArrivalTime,
+			&i.MaxDepartureTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getBlocksForBlockTripIndexIDs = `-- name: GetBlocksForBlockTripIndexIDs :many
@@ -1914,21 +3025,177 @@ func (q *Queries) GetBlocksForBlockTripIndexIDs(ctx context.Context, arg GetBloc
 	return items, nil
 }
 
-const getCalendarByServiceID = `-- name: GetCalendarByServiceID :one
-SELECT
-    id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date
-FROM
-    calendar
-WHERE
-    id = ?
+const getBookingRulesByIDs = `-- name: GetBookingRulesByIDs :many
+SELECT id, booking_type, prior_notice_duration_min, prior_notice_duration_max, prior_notice_last_day, prior_notice_last_time, prior_notice_start_day, prior_notice_start_time, prior_notice_service_id, message, pickup_message, drop_off_message, phone_number, info_url, booking_url FROM booking_rules
+WHERE id IN (/*SLICE:ids*/?)
+ORDER BY id
 `
 
-func (q *Queries) GetCalendarByServiceID(ctx context.Context, id string) (Calendar, error) {
-	row := q.queryRow(ctx, q.getCalendarByServiceIDStmt, getCalendarByServiceID, id)
-	var i Calendar
-	err := row.Scan(
-		&i.ID,
-		&i.Monday,
+func (q *Queries) GetBookingRulesByIDs(ctx context.Context, ids []string) ([]BookingRule, error) {
+	query := getBookingRulesByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingRule
+	for rows.Next() {
+		var i BookingRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookingType,
+			&i.PriorNoticeDurationMin,
+			&i.PriorNoticeDurationMax,
+			&i.PriorNoticeLastDay,
+			&i.PriorNoticeLastTime,
+			&i.PriorNoticeStartDay,
+			&i.PriorNoticeStartTime,
+			&i.PriorNoticeServiceID,
+			&i.Message,
+			&i.PickupMessage,
+			&i.DropOffMessage,
+			&i.PhoneNumber,
+			&i.InfoUrl,
+			&i.BookingUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookingRulesForRoute = `-- name: GetBookingRulesForRoute :many
+SELECT DISTINCT br.id, br.booking_type, br.prior_notice_duration_min, br.prior_notice_duration_max, br.prior_notice_last_day, br.prior_notice_last_time, br.prior_notice_start_day, br.prior_notice_start_time, br.prior_notice_service_id, br.message, br.pickup_message, br.drop_off_message, br.phone_number, br.info_url, br.booking_url
+FROM booking_rules br
+JOIN flex_stop_times fst
+    ON br.id IN (fst.pickup_booking_rule_id, fst.drop_off_booking_rule_id)
+WHERE fst.route_id = ?
+ORDER BY br.id
+`
+
+func (q *Queries) GetBookingRulesForRoute(ctx context.Context, routeID string) ([]BookingRule, error) {
+	rows, err := q.query(ctx, q.getBookingRulesForRouteStmt, getBookingRulesForRoute, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingRule
+	for rows.Next() {
+		var i BookingRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookingType,
+			&i.PriorNoticeDurationMin,
+			&i.PriorNoticeDurationMax,
+			&i.PriorNoticeLastDay,
+			&i.PriorNoticeLastTime,
+			&i.PriorNoticeStartDay,
+			&i.PriorNoticeStartTime,
+			&i.PriorNoticeServiceID,
+			&i.Message,
+			&i.PickupMessage,
+			&i.DropOffMessage,
+			&i.PhoneNumber,
+			&i.InfoUrl,
+			&i.BookingUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookingRulesForStop = `-- name: GetBookingRulesForStop :many
+SELECT DISTINCT br.id, br.booking_type, br.prior_notice_duration_min, br.prior_notice_duration_max, br.prior_notice_last_day, br.prior_notice_last_time, br.prior_notice_start_day, br.prior_notice_start_time, br.prior_notice_service_id, br.message, br.pickup_message, br.drop_off_message, br.phone_number, br.info_url, br.booking_url
+FROM booking_rules br
+JOIN flex_stop_times fst
+    ON br.id IN (fst.pickup_booking_rule_id, fst.drop_off_booking_rule_id)
+WHERE fst.location_group_id IN (
+        SELECT lgs.location_group_id FROM location_group_stops lgs WHERE lgs.stop_id = ?1
+   )
+   OR fst.stop_id = ?1
+ORDER BY br.id
+`
+
+// Booking rules of flexible service at a stop, either directly or through a
+// location group containing the stop.
+func (q *Queries) GetBookingRulesForStop(ctx context.Context, stopID string) ([]BookingRule, error) {
+	rows, err := q.query(ctx, q.getBookingRulesForStopStmt, getBookingRulesForStop, stopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingRule
+	for rows.Next() {
+		var i BookingRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookingType,
+			&i.PriorNoticeDurationMin,
+			&i.PriorNoticeDurationMax,
+			&i.PriorNoticeLastDay,
+			&i.PriorNoticeLastTime,
+			&i.PriorNoticeStartDay,
+			&i.PriorNoticeStartTime,
+			&i.PriorNoticeServiceID,
+			&i.Message,
+			&i.PickupMessage,
+			&i.DropOffMessage,
+			&i.PhoneNumber,
+			&i.InfoUrl,
+			&i.BookingUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCalendarByServiceID = `-- name: GetCalendarByServiceID :one
+SELECT
+    id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date
+FROM
+    calendar
+WHERE
+    id = ?
+`
+
+func (q *Queries) GetCalendarByServiceID(ctx context.Context, id string) (Calendar, error) {
+	row := q.queryRow(ctx, q.getCalendarByServiceIDStmt, getCalendarByServiceID, id)
+	var i Calendar
+	err := row.Scan(
+		&i.ID,
+		&i.Monday,
 		&i.Tuesday,
 		&i.Wednesday,
 		&i.Thursday,
@@ -1973,6 +3240,309 @@ func (q *Queries) GetCalendarDateExceptionsForServiceID(ctx context.Context, ser
 	return items, nil
 }
 
+const getDeveloperAPIKey = `-- name: GetDeveloperAPIKey :one
+SELECT id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at FROM developer_api_keys
+WHERE id = ?
+`
+
+func (q *Queries) GetDeveloperAPIKey(ctx context.Context, id int64) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.getDeveloperAPIKeyStmt, getDeveloperAPIKey, id)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getDeveloperAPIKeyByVerificationToken = `-- name: GetDeveloperAPIKeyByVerificationToken :one
+SELECT id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at FROM developer_api_keys
+WHERE verification_token_hash = ?
+`
+
+func (q *Queries) GetDeveloperAPIKeyByVerificationToken(ctx context.Context, verificationTokenHash string) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.getDeveloperAPIKeyByVerificationTokenStmt, getDeveloperAPIKeyByVerificationToken, verificationTokenHash)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getFareAttributesForRoute = `-- name: GetFareAttributesForRoute :many
+SELECT fa.id, fa.price, fa.currency_type, fa.payment_method, fa.transfers, fa.agency_id, fa.transfer_duration
+FROM fare_attributes fa
+WHERE (fa.agency_id IS NULL OR fa.agency_id = ?1)
+  AND (
+        fa.id IN (
+            SELECT fr.fare_id FROM fare_rules fr WHERE fr.route_id = ?2 OR fr.route_id IS NULL
+        )
+     OR NOT EXISTS (SELECT 1 FROM fare_rules fr WHERE fr.fare_id = fa.id)
+  )
+ORDER BY fa.id
+`
+
+type GetFareAttributesForRouteParams struct {
+	AgencyID sql.NullString
+	RouteID  sql.NullString
+}
+
+// Fares v1 fares that can apply to a route: those with a rule for the route,
+// with a zone-only rule, or with no rules at all, which apply agency-wide.
+func (q *Queries) GetFareAttributesForRoute(ctx context.Context, arg GetFareAttributesForRouteParams) ([]FareAttribute, error) {
+	rows, err := q.query(ctx, q.getFareAttributesForRouteStmt, getFareAttributesForRoute, arg.AgencyID, arg.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FareAttribute
+	for rows.Next() {
+		var i FareAttribute
+		if err := rows.Scan(
+			&i.ID,
+			&i.Price,
+			&i.CurrencyType,
+			&i.PaymentMethod,
+			&i.Transfers,
+			&i.AgencyID,
+			&i.TransferDuration,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFareLegRules = `-- name: GetFareLegRules :many
+SELECT id, leg_group_id, network_id, from_area_id, to_area_id, fare_product_id, rule_priority
+FROM fare_leg_rules
+ORDER BY rule_priority DESC, fare_product_id, id
+`
+
+func (q *Queries) GetFareLegRules(ctx context.Context) ([]FareLegRule, error) {
+	rows, err := q.query(ctx, q.getFareLegRulesStmt, getFareLegRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FareLegRule
+	for rows.Next() {
+		var i FareLegRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.LegGroupID,
+			&i.NetworkID,
+			&i.FromAreaID,
+			&i.ToAreaID,
+			&i.FareProductID,
+			&i.RulePriority,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFareLegRulesForRoute = `-- name: GetFareLegRulesForRoute :many
+SELECT id, leg_group_id, network_id, from_area_id, to_area_id, fare_product_id, rule_priority
+FROM fare_leg_rules
+WHERE network_id IS NULL
+   OR network_id IN (SELECT rn.network_id FROM route_networks rn WHERE rn.route_id = ?1)
+ORDER BY rule_priority DESC, fare_product_id, id
+`
+
+// Fares v2 leg rules that can apply to a route: those for one of its networks
+// and those without a network, which match every leg.
+func (q *Queries) GetFareLegRulesForRoute(ctx context.Context, routeID string) ([]FareLegRule, error) {
+	rows, err := q.query(ctx, q.getFareLegRulesForRouteStmt, getFareLegRulesForRoute, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FareLegRule
+	for rows.Next() {
+		var i FareLegRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.LegGroupID,
+			&i.NetworkID,
+			&i.FromAreaID,
+			&i.ToAreaID,
+			&i.FareProductID,
+			&i.RulePriority,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFareMediaByIDs = `-- name: GetFareMediaByIDs :many
+SELECT id, name, fare_media_type
+FROM fare_media
+WHERE id IN (/*SLICE:fare_media_ids*/?)
+ORDER BY id
+`
+
+func (q *Queries) GetFareMediaByIDs(ctx context.Context, fareMediaIds []string) ([]FareMedium, error) {
+	query := getFareMediaByIDs
+	var queryParams []interface{}
+	if len(fareMediaIds) > 0 {
+		for _, v := range fareMediaIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:fare_media_ids*/?", strings.Repeat(",?", len(fareMediaIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:fare_media_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FareMedium
+	for rows.Next() {
+		var i FareMedium
+		if err := rows.Scan(&i.ID, &i.Name, &i.FareMediaType); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFareProductsByIDs = `-- name: GetFareProductsByIDs :many
+SELECT fare_product_id, fare_media_id, rider_category_id, name, amount, currency
+FROM fare_products
+WHERE fare_product_id IN (/*SLICE:fare_product_ids*/?)
+ORDER BY fare_product_id, rider_category_id, fare_media_id
+`
+
+func (q *Queries) GetFareProductsByIDs(ctx context.Context, fareProductIds []string) ([]FareProduct, error) {
+	query := getFareProductsByIDs
+	var queryParams []interface{}
+	if len(fareProductIds) > 0 {
+		for _, v := range fareProductIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:fare_product_ids*/?", strings.Repeat(",?", len(fareProductIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:fare_product_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FareProduct
+	for rows.Next() {
+		var i FareProduct
+		if err := rows.Scan(
+			&i.FareProductID,
+			&i.FareMediaID,
+			&i.RiderCategoryID,
+			&i.Name,
+			&i.Amount,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFareRulesForRoute = `-- name: GetFareRulesForRoute :many
+SELECT id, fare_id, route_id, origin_id, destination_id, contains_id
+FROM fare_rules
+WHERE route_id = ?1 OR route_id IS NULL
+ORDER BY fare_id, id
+`
+
+func (q *Queries) GetFareRulesForRoute(ctx context.Context, routeID sql.NullString) ([]FareRule, error) {
+	rows, err := q.query(ctx, q.getFareRulesForRouteStmt, getFareRulesForRoute, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FareRule
+	for rows.Next() {
+		var i FareRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.FareID,
+			&i.RouteID,
+			&i.OriginID,
+			&i.DestinationID,
+			&i.ContainsID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedEndDate = `-- name: GetFeedEndDate :one
 SELECT COALESCE(CAST(MAX(max_date) AS TEXT), '') AS feed_end_date
 FROM (
@@ -1989,56 +3559,111 @@ func (q *Queries) GetFeedEndDate(ctx context.Context) (interface{}, error) {
 	return feed_end_date, err
 }
 
-const getFirstStopOfNextTripInBlock = `-- name: GetFirstStopOfNextTripInBlock :one
-SELECT st.trip_id, st.arrival_time, st.departure_time, st.stop_id, st.stop_sequence, st.stop_headsign, st.pickup_type, st.drop_off_type, st.shape_dist_traveled, st.timepoint
-FROM stop_times st
-WHERE st.trip_id = (
-    SELECT next_trip_id FROM (
-        SELECT id, LEAD(id) OVER (ORDER BY min_arrival_time) AS next_trip_id
-        FROM trips
-        WHERE block_id = ?1
-          AND service_id IN (/*SLICE:service_ids*/?)
-    ) WHERE id = ?3
-)
-ORDER BY st.stop_sequence ASC
-LIMIT 1
+const getFlexStopTimesForStop = `-- name: GetFlexStopTimesForStop :many
+SELECT
+    fst.trip_id,
+    fst.stop_sequence,
+    fst.route_id,
+    fst.start_pickup_drop_off_window,
+    fst.end_pickup_drop_off_window,
+    fst.pickup_booking_rule_id,
+    fst.drop_off_booking_rule_id,
+    t.service_id,
+    t.trip_headsign
+FROM flex_stop_times fst
+JOIN trips t ON t.id = fst.trip_id
+WHERE fst.stop_id = ?1
+   OR fst.location_group_id IN (
+        SELECT lgs.location_group_id FROM location_group_stops lgs WHERE lgs.stop_id = ?1
+   )
+ORDER BY fst.trip_id, fst.stop_sequence
 `
 
-type GetFirstStopOfNextTripInBlockParams struct {
-	BlockID    sql.NullString
-	ServiceIds []string
-	TripID     string
+type GetFlexStopTimesForStopRow struct {
+	TripID                   string
+	StopSequence             int64
+	RouteID                  string
+	StartPickupDropOffWindow sql.NullInt64
+	EndPickupDropOffWindow   sql.NullInt64
+	PickupBookingRuleID      sql.NullString
+	DropOffBookingRuleID     sql.NullString
+	ServiceID                string
+	TripHeadsign             sql.NullString
+}
+
+// Flexible service at a stop, either directly or through a location group
+// containing the stop. Only trips with a service calendar are returned, as
+// the others cannot be placed on a service date.
+func (q *Queries) GetFlexStopTimesForStop(ctx context.Context, stopID sql.NullString) ([]GetFlexStopTimesForStopRow, error) {
+	rows, err := q.query(ctx, q.getFlexStopTimesForStopStmt, getFlexStopTimesForStop, stopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFlexStopTimesForStopRow
+	for rows.Next() {
+		var i GetFlexStopTimesForStopRow
+		if err := rows.Scan(
+			&i.TripID,
+			&i.StopSequence,
+			&i.RouteID,
+			&i.StartPickupDropOffWindow,
+			&i.EndPickupDropOffWindow,
+			&i.PickupBookingRuleID,
+			&i.DropOffBookingRuleID,
+			&i.ServiceID,
+			&i.TripHeadsign,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-// Uses LEAD() to find the next trip and directly fetches its first stop,
-func (q *Queries) GetFirstStopOfNextTripInBlock(ctx context.Context, arg GetFirstStopOfNextTripInBlockParams) (StopTime, error) {
-	query := getFirstStopOfNextTripInBlock
-	var queryParams []interface{}
-	queryParams = append(queryParams, arg.BlockID)
-	if len(arg.ServiceIds) > 0 {
-		for _, v := range arg.ServiceIds {
-			queryParams = append(queryParams, v)
+const getFlexStopTimesForTrip = `-- name: GetFlexStopTimesForTrip :many
+SELECT trip_id, stop_sequence, route_id, stop_id, location_group_id, start_pickup_drop_off_window, end_pickup_drop_off_window, pickup_booking_rule_id, drop_off_booking_rule_id FROM flex_stop_times
+WHERE trip_id = ?
+ORDER BY stop_sequence
+`
+
+func (q *Queries) GetFlexStopTimesForTrip(ctx context.Context, tripID string) ([]FlexStopTime, error) {
+	rows, err := q.query(ctx, q.getFlexStopTimesForTripStmt, getFlexStopTimesForTrip, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FlexStopTime
+	for rows.Next() {
+		var i FlexStopTime
+		if err := rows.Scan(
+			&i.TripID,
+			&i.StopSequence,
+			&i.RouteID,
+			&i.StopID,
+			&i.LocationGroupID,
+			&i.StartPickupDropOffWindow,
+			&i.EndPickupDropOffWindow,
+			&i.PickupBookingRuleID,
+			&i.DropOffBookingRuleID,
+		); err != nil {
+			return nil, err
 		}
-		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
-	} else {
-		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+		items = append(items, i)
 	}
-	queryParams = append(queryParams, arg.TripID)
-	row := q.queryRow(ctx, nil, query, queryParams...)
-	var i StopTime
-	err := row.Scan(
-		&i.TripID,
-		&i.ArrivalTime,
-		&i.DepartureTime,
-		&i.StopID,
-		&i.StopSequence,
-		&i.StopHeadsign,
-		&i.PickupType,
-		&i.DropOffType,
-		&i.ShapeDistTraveled,
-		&i.Timepoint,
-	)
-	return i, err
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getFrequenciesForTrip = `-- name: GetFrequenciesForTrip :many
@@ -2170,50 +3795,34 @@ func (q *Queries) GetImportMetadata(ctx context.Context) (ImportMetadatum, error
 	return i, err
 }
 
-const getNextAndPreviousTripsInBlock = `-- name: GetNextAndPreviousTripsInBlock :one
-WITH NavTrips AS (
-    SELECT
-        id,
-        LAG(id)  OVER (ORDER BY min_arrival_time) AS prev_trip_id,
-        LEAD(id) OVER (ORDER BY min_arrival_time) AS next_trip_id
-    FROM trips
-    WHERE block_id = ?2
-      AND service_id IN (/*SLICE:service_ids*/?)
-)
-SELECT prev_trip_id, next_trip_id
-FROM NavTrips
-WHERE id = ?1
+const getNetworksForRoute = `-- name: GetNetworksForRoute :many
+SELECT network_id
+FROM route_networks
+WHERE route_id = ?
+ORDER BY network_id
 `
 
-type GetNextAndPreviousTripsInBlockParams struct {
-	TripID     string
-	BlockID    sql.NullString
-	ServiceIds []string
-}
-
-type GetNextAndPreviousTripsInBlockRow struct {
-	PrevTripID interface{}
-	NextTripID interface{}
-}
-
-// Uses LAG/LEAD window functions to find prev/next trip IDs in one query,
-func (q *Queries) GetNextAndPreviousTripsInBlock(ctx context.Context, arg GetNextAndPreviousTripsInBlockParams) (GetNextAndPreviousTripsInBlockRow, error) {
-	query := getNextAndPreviousTripsInBlock
-	var queryParams []interface{}
-	queryParams = append(queryParams, arg.TripID)
-	queryParams = append(queryParams, arg.BlockID)
-	if len(arg.ServiceIds) > 0 {
-		for _, v := range arg.ServiceIds {
-			queryParams = append(queryParams, v)
+func (q *Queries) GetNetworksForRoute(ctx context.Context, routeID string) ([]string, error) {
+	rows, err := q.query(ctx, q.getNetworksForRouteStmt, getNetworksForRoute, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var network_id string
+		if err := rows.Scan(&network_id); err != nil {
+			return nil, err
 		}
-		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
-	} else {
-		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+		items = append(items, network_id)
 	}
-	row := q.queryRow(ctx, nil, query, queryParams...)
-	var i GetNextAndPreviousTripsInBlockRow
-	err := row.Scan(&i.PrevTripID, &i.NextTripID)
-	return i, err
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getNextStopInTrip = `-- name: GetNextStopInTrip :one
@@ -2244,6 +3853,47 @@ func (q *Queries) GetNextStopInTrip(ctx context.Context, arg GetNextStopInTripPa
 	return i, err
 }
 
+const getNextTripInBlock = `-- name: GetNextTripInBlock :one
+SELECT trip_id, block_id, service_id, block_sequence, start_time, end_time FROM block_trip_order
+WHERE block_id = ?1
+  AND block_sequence > ?2
+  AND service_id IN (/*SLICE:service_ids*/?)
+ORDER BY block_sequence
+LIMIT 1
+`
+
+type GetNextTripInBlockParams struct {
+	BlockID       string
+	BlockSequence int64
+	ServiceIds    []string
+}
+
+func (q *Queries) GetNextTripInBlock(ctx context.Context, arg GetNextTripInBlockParams) (BlockTripOrder, error) {
+	query := getNextTripInBlock
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.BlockID)
+	queryParams = append(queryParams, arg.BlockSequence)
+	if len(arg.ServiceIds) > 0 {
+		for _, v := range arg.ServiceIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+	}
+	row := q.queryRow(ctx, nil, query, queryParams...)
+	var i BlockTripOrder
+	err := row.Scan(
+		&i.TripID,
+		&i.BlockID,
+		&i.ServiceID,
+		&i.BlockSequence,
+		&i.StartTime,
+		&i.EndTime,
+	)
+	return i, err
+}
+
 const getOrderedStopIDsForRouteDirection = `-- name: GetOrderedStopIDsForRouteDirection :many
 SELECT st.stop_id
 FROM stop_times st
@@ -2326,6 +3976,47 @@ func (q *Queries) GetOrderedStopIDsForTrip(ctx context.Context, tripID string) (
 	return items, nil
 }
 
+const getPreviousTripInBlock = `-- name: GetPreviousTripInBlock :one
+SELECT trip_id, block_id, service_id, block_sequence, start_time, end_time FROM block_trip_order
+WHERE block_id = ?1
+  AND block_sequence < ?2
+  AND service_id IN (/*SLICE:service_ids*/?)
+ORDER BY block_sequence DESC
+LIMIT 1
+`
+
+type GetPreviousTripInBlockParams struct {
+	BlockID       string
+	BlockSequence int64
+	ServiceIds    []string
+}
+
+func (q *Queries) GetPreviousTripInBlock(ctx context.Context, arg GetPreviousTripInBlockParams) (BlockTripOrder, error) {
+	query := getPreviousTripInBlock
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.BlockID)
+	queryParams = append(queryParams, arg.BlockSequence)
+	if len(arg.ServiceIds) > 0 {
+		for _, v := range arg.ServiceIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+	}
+	row := q.queryRow(ctx, nil, query, queryParams...)
+	var i BlockTripOrder
+	err := row.Scan(
+		&i.TripID,
+		&i.BlockID,
+		&i.ServiceID,
+		&i.BlockSequence,
+		&i.StartTime,
+		&i.EndTime,
+	)
+	return i, err
+}
+
 const getProblemReportsByStop = `-- name: GetProblemReportsByStop :many
 SELECT id, stop_id, code, user_comment, user_lat, user_lon, user_location_accuracy, created_at, submitted_at FROM problem_reports_stop
 WHERE stop_id = ?
@@ -2409,9 +4100,54 @@ func (q *Queries) GetProblemReportsByTrip(ctx context.Context, tripID string) ([
 	return items, nil
 }
 
+const getRiderCategoriesByIDs = `-- name: GetRiderCategoriesByIDs :many
+SELECT id, name, is_default_fare_category, eligibility_url
+FROM rider_categories
+WHERE id IN (/*SLICE:rider_category_ids*/?)
+ORDER BY id
+`
+
+func (q *Queries) GetRiderCategoriesByIDs(ctx context.Context, riderCategoryIds []string) ([]RiderCategory, error) {
+	query := getRiderCategoriesByIDs
+	var queryParams []interface{}
+	if len(riderCategoryIds) > 0 {
+		for _, v := range riderCategoryIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:rider_category_ids*/?", strings.Repeat(",?", len(riderCategoryIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:rider_category_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RiderCategory
+	for rows.Next() {
+		var i RiderCategory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.IsDefaultFareCategory,
+			&i.EligibilityUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoute = `-- name: GetRoute :one
 SELECT
-    id, agency_id, short_name, long_name, "desc", type, url, color, text_color, continuous_pickup, continuous_drop_off
+    id, agency_id, short_name, long_name, "desc", type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order
 FROM
     routes
 WHERE
@@ -2433,6 +4169,7 @@ func (q *Queries) GetRoute(ctx context.Context, id string) (Route, error) {
 		&i.TextColor,
 		&i.ContinuousPickup,
 		&i.ContinuousDropOff,
+		&i.SortOrder,
 	)
 	return i, err
 }
@@ -2554,9 +4291,110 @@ func (q *Queries) GetRouteIDsForStops(ctx context.Context, stopIds []string) ([]
 	return items, nil
 }
 
+const getRouteServiceSpans = `-- name: GetRouteServiceSpans :many
+SELECT route_id, service_id, first_departure_time, last_departure_time FROM route_service_spans
+WHERE route_id = ?1
+  AND service_id IN (/*SLICE:service_ids*/?)
+`
+
+type GetRouteServiceSpansParams struct {
+	RouteID    string
+	ServiceIds []string
+}
+
+func (q *Queries) GetRouteServiceSpans(ctx context.Context, arg GetRouteServiceSpansParams) ([]RouteServiceSpan, error) {
+	query := getRouteServiceSpans
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.RouteID)
+	if len(arg.ServiceIds) > 0 {
+		for _, v := range arg.ServiceIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RouteServiceSpan
+	for rows.Next() {
+		var i RouteServiceSpan
+		if err := rows.Scan(
+			&i.RouteID,
+			&i.ServiceID,
+			&i.FirstDepartureTime,
+			&i.LastDepartureTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRouteServiceSpansForAgency = `-- name: GetRouteServiceSpansForAgency :many
+SELECT s.route_id, s.service_id, s.first_departure_time, s.last_departure_time FROM route_service_spans s
+JOIN routes r ON r.id = s.route_id
+WHERE r.agency_id = ?1
+  AND s.service_id IN (/*SLICE:service_ids*/?)
+`
+
+type GetRouteServiceSpansForAgencyParams struct {
+	AgencyID   string
+	ServiceIds []string
+}
+
+func (q *Queries) GetRouteServiceSpansForAgency(ctx context.Context, arg GetRouteServiceSpansForAgencyParams) ([]RouteServiceSpan, error) {
+	query := getRouteServiceSpansForAgency
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.AgencyID)
+	if len(arg.ServiceIds) > 0 {
+		for _, v := range arg.ServiceIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", strings.Repeat(",?", len(arg.ServiceIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:service_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RouteServiceSpan
+	for rows.Next() {
+		var i RouteServiceSpan
+		if err := rows.Scan(
+			&i.RouteID,
+			&i.ServiceID,
+			&i.FirstDepartureTime,
+			&i.LastDepartureTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoutesByIDs = `-- name: GetRoutesByIDs :many
 SELECT
-    id, agency_id, short_name, long_name, "desc", type, url, color, text_color, continuous_pickup, continuous_drop_off
+    id, agency_id, short_name, long_name, "desc", type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order
 FROM
     routes
 WHERE
@@ -2596,6 +4434,7 @@ func (q *Queries) GetRoutesByIDs(ctx context.Context, routeIds []string) ([]Rout
 			&i.TextColor,
 			&i.ContinuousPickup,
 			&i.ContinuousDropOff,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -2619,7 +4458,8 @@ SELECT
     routes.type,
     routes.url,
     routes.color,
-    routes.text_color
+    routes.text_color,
+    routes.sort_order
 FROM
     routes
 WHERE
@@ -2635,6 +4475,7 @@ type GetRoutesForAgencyRow struct {
 	Url       sql.NullString
 	Color     sql.NullString
 	TextColor sql.NullString
+	SortOrder sql.NullInt64
 }
 
 func (q *Queries) GetRoutesForAgency(ctx context.Context, agencyID string) ([]GetRoutesForAgencyRow, error) {
@@ -2655,6 +4496,73 @@ func (q *Queries) GetRoutesForAgency(ctx context.Context, agencyID string) ([]Ge
 			&i.Url,
 			&i.Color,
 			&i.TextColor,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoutesForShapeIDs = `-- name: GetRoutesForShapeIDs :many
+SELECT DISTINCT
+    t.shape_id,
+    r.id AS route_id,
+    r.agency_id,
+    r.short_name,
+    r.long_name,
+    r.type,
+    r.color
+FROM trips t
+JOIN routes r ON r.id = t.route_id
+WHERE t.shape_id IN (/*SLICE:shape_ids*/?)
+ORDER BY t.shape_id, r.id
+`
+
+type GetRoutesForShapeIDsRow struct {
+	ShapeID   sql.NullString
+	RouteID   string
+	AgencyID  string
+	ShortName sql.NullString
+	LongName  sql.NullString
+	Type      int64
+	Color     sql.NullString
+}
+
+func (q *Queries) GetRoutesForShapeIDs(ctx context.Context, shapeIds []sql.NullString) ([]GetRoutesForShapeIDsRow, error) {
+	query := getRoutesForShapeIDs
+	var queryParams []interface{}
+	if len(shapeIds) > 0 {
+		for _, v := range shapeIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:shape_ids*/?", strings.Repeat(",?", len(shapeIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:shape_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoutesForShapeIDsRow
+	for rows.Next() {
+		var i GetRoutesForShapeIDsRow
+		if err := rows.Scan(
+			&i.ShapeID,
+			&i.RouteID,
+			&i.AgencyID,
+			&i.ShortName,
+			&i.LongName,
+			&i.Type,
+			&i.Color,
 		); err != nil {
 			return nil, err
 		}
@@ -2679,7 +4587,8 @@ SELECT DISTINCT
     routes.type,
     routes.url,
     routes.color,
-    routes.text_color
+    routes.text_color,
+    routes.sort_order
 FROM
     stop_times
     JOIN trips ON stop_times.trip_id = trips.id
@@ -2698,6 +4607,7 @@ type GetRoutesForStopRow struct {
 	Url       sql.NullString
 	Color     sql.NullString
 	TextColor sql.NullString
+	SortOrder sql.NullInt64
 }
 
 func (q *Queries) GetRoutesForStop(ctx context.Context, stopID string) ([]GetRoutesForStopRow, error) {
@@ -2719,6 +4629,7 @@ func (q *Queries) GetRoutesForStop(ctx context.Context, stopID string) ([]GetRou
 			&i.Url,
 			&i.Color,
 			&i.TextColor,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -2745,6 +4656,7 @@ SELECT DISTINCT
     routes.url,
     routes.color,
     routes.text_color,
+    routes.sort_order,
     stop_times.stop_id
 FROM
     stop_times
@@ -2764,6 +4676,7 @@ type GetRoutesForStopsRow struct {
 	Url       sql.NullString
 	Color     sql.NullString
 	TextColor sql.NullString
+	SortOrder sql.NullInt64
 	StopID    string
 }
 
@@ -2797,6 +4710,7 @@ func (q *Queries) GetRoutesForStops(ctx context.Context, stopIds []string) ([]Ge
 			&i.Url,
 			&i.Color,
 			&i.TextColor,
+			&i.SortOrder,
 			&i.StopID,
 		); err != nil {
 			return nil, err
@@ -3068,6 +4982,50 @@ func (q *Queries) GetScheduleForStopOnDate(ctx context.Context, arg GetScheduleF
 	return items, nil
 }
 
+const getServiceExceptionsForAgency = `-- name: GetServiceExceptionsForAgency :many
+SELECT cd.service_id, cd.date, cd.exception_type
+FROM calendar_dates cd
+WHERE cd.date BETWEEN ?1 AND ?2
+  AND cd.service_id IN (
+    SELECT t.service_id
+    FROM trips t
+    JOIN routes r ON r.id = t.route_id
+    WHERE r.agency_id = ?3
+  )
+ORDER BY cd.date, cd.service_id
+`
+
+type GetServiceExceptionsForAgencyParams struct {
+	StartDate string
+	EndDate   string
+	AgencyID  string
+}
+
+// calendar_dates exceptions from start_date to end_date (YYYYMMDD, both
+// included) of the services running the agency's trips.
+func (q *Queries) GetServiceExceptionsForAgency(ctx context.Context, arg GetServiceExceptionsForAgencyParams) ([]CalendarDate, error) {
+	rows, err := q.query(ctx, q.getServiceExceptionsForAgencyStmt, getServiceExceptionsForAgency, arg.StartDate, arg.EndDate, arg.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CalendarDate
+	for rows.Next() {
+		var i CalendarDate
+		if err := rows.Scan(&i.ServiceID, &i.Date, &i.ExceptionType); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getShapeByID = `-- name: GetShapeByID :many
 SELECT
     id, shape_id, lat, lon, shape_pt_sequence, shape_dist_traveled
@@ -3109,6 +5067,48 @@ func (q *Queries) GetShapeByID(ctx context.Context, shapeID string) ([]Shape, er
 	return items, nil
 }
 
+const getShapeIDsWithinBounds = `-- name: GetShapeIDsWithinBounds :many
+SELECT DISTINCT shape_id
+FROM shapes
+WHERE lat >= ?1 AND lat <= ?2
+  AND lon >= ?3 AND lon <= ?4
+`
+
+type GetShapeIDsWithinBoundsParams struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
+}
+
+func (q *Queries) GetShapeIDsWithinBounds(ctx context.Context, arg GetShapeIDsWithinBoundsParams) ([]string, error) {
+	rows, err := q.query(ctx, q.getShapeIDsWithinBoundsStmt, getShapeIDsWithinBounds,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLon,
+		arg.MaxLon,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var shape_id string
+		if err := rows.Scan(&shape_id); err != nil {
+			return nil, err
+		}
+		items = append(items, shape_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getShapePointWindow = `-- name: GetShapePointWindow :many
 SELECT lat, lon, shape_pt_sequence, shape_dist_traveled
 FROM shapes
@@ -3713,6 +5713,8 @@ SELECT
     st.stop_id,
     st.stop_sequence,
     st.stop_headsign,
+    st.pickup_type,
+    st.drop_off_type,
     t.route_id,
     t.service_id,
     t.trip_headsign,
@@ -3741,6 +5743,8 @@ type GetStopTimesForStopInWindowRow struct {
 	StopID        string
 	StopSequence  int64
 	StopHeadsign  sql.NullString
+	PickupType    sql.NullInt64
+	DropOffType   sql.NullInt64
 	RouteID       string
 	ServiceID     string
 	TripHeadsign  sql.NullString
@@ -3763,6 +5767,8 @@ func (q *Queries) GetStopTimesForStopInWindow(ctx context.Context, arg GetStopTi
 			&i.StopID,
 			&i.StopSequence,
 			&i.StopHeadsign,
+			&i.PickupType,
+			&i.DropOffType,
 			&i.RouteID,
 			&i.ServiceID,
 			&i.TripHeadsign,
@@ -3876,6 +5882,51 @@ func (q *Queries) GetStopTimesForTripIDs(ctx context.Context, tripIds []string)
 	return items, nil
 }
 
+const getStopsByCode = `-- name: GetStopsByCode :many
+SELECT id, code, name, "desc", lat, lon, zone_id, url, location_type, timezone, wheelchair_boarding, platform_code, direction, parent_station
+FROM stops
+WHERE code = ?
+ORDER BY id
+`
+
+func (q *Queries) GetStopsByCode(ctx context.Context, code sql.NullString) ([]Stop, error) {
+	rows, err := q.query(ctx, q.getStopsByCodeStmt, getStopsByCode, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Stop
+	for rows.Next() {
+		var i Stop
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Desc,
+			&i.Lat,
+			&i.Lon,
+			&i.ZoneID,
+			&i.Url,
+			&i.LocationType,
+			&i.Timezone,
+			&i.WheelchairBoarding,
+			&i.PlatformCode,
+			&i.Direction,
+			&i.ParentStation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStopsByIDs = `-- name: GetStopsByIDs :many
 SELECT
     id, code, name, "desc", lat, lon, zone_id, url, location_type, timezone, wheelchair_boarding, platform_code, direction, parent_station
@@ -4302,6 +6353,48 @@ func (q *Queries) GetTargetStopTimeWithTotalStopsBySequence(ctx context.Context,
 	return i, err
 }
 
+const getTransfersFromStop = `-- name: GetTransfersFromStop :many
+SELECT id, from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id, transfer_type, min_transfer_time, generated, distance
+FROM transfers
+WHERE from_stop_id = ?1
+ORDER BY to_stop_id, id
+`
+
+func (q *Queries) GetTransfersFromStop(ctx context.Context, stopID string) ([]Transfer, error) {
+	rows, err := q.query(ctx, q.getTransfersFromStopStmt, getTransfersFromStop, stopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transfer
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromStopID,
+			&i.ToStopID,
+			&i.FromRouteID,
+			&i.ToRouteID,
+			&i.FromTripID,
+			&i.ToTripID,
+			&i.TransferType,
+			&i.MinTransferTime,
+			&i.Generated,
+			&i.Distance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTrip = `-- name: GetTrip :one
 SELECT
     id, route_id, service_id, trip_headsign, trip_short_name, direction_id, block_id, shape_id, wheelchair_accessible, bikes_allowed, min_arrival_time, max_departure_time
@@ -4331,6 +6424,59 @@ func (q *Queries) GetTrip(ctx context.Context, id string) (Trip, error) {
 	return i, err
 }
 
+const getTripTimeZones = `-- name: GetTripTimeZones :many
+SELECT
+    t.id AS trip_id,
+    CAST(COALESCE(NULLIF(s.timezone, ''), a.timezone) AS TEXT) AS timezone
+FROM trips t
+JOIN routes r ON r.id = t.route_id
+JOIN agencies a ON a.id = r.agency_id
+LEFT JOIN stop_times st ON st.trip_id = t.id
+    AND st.stop_sequence = (SELECT MIN(stop_sequence) FROM stop_times WHERE trip_id = t.id)
+LEFT JOIN stops s ON s.id = st.stop_id
+WHERE t.id IN (/*SLICE:trip_ids*/?)
+`
+
+type GetTripTimeZonesRow struct {
+	TripID   string
+	Timezone string
+}
+
+// The stop_timezone of the stop a trip departs from, when set, otherwise the
+// timezone of the agency operating its route.
+func (q *Queries) GetTripTimeZones(ctx context.Context, tripIds []string) ([]GetTripTimeZonesRow, error) {
+	query := getTripTimeZones
+	var queryParams []interface{}
+	if len(tripIds) > 0 {
+		for _, v := range tripIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:trip_ids*/?", strings.Repeat(",?", len(tripIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:trip_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTripTimeZonesRow
+	for rows.Next() {
+		var i GetTripTimeZonesRow
+		if err := rows.Scan(&i.TripID, &i.Timezone); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTripsByBlockID = `-- name: GetTripsByBlockID :many
 SELECT
     id,
@@ -4852,6 +6998,130 @@ func (q *Queries) GetTripsInBlock(ctx context.Context, arg GetTripsInBlockParams
 	return items, nil
 }
 
+const listAPIKeyUsage = `-- name: ListAPIKeyUsage :many
+SELECT day, key_fingerprint, endpoint, requests FROM api_key_usage
+WHERE day >= ?1 AND (CAST(?2 AS TEXT) = '' OR key_fingerprint = ?2)
+ORDER BY day DESC, key_fingerprint, endpoint
+`
+
+type ListAPIKeyUsageParams struct {
+	Since          string
+	KeyFingerprint string
+}
+
+// Newest day first; days before @since are skipped. An empty
+// @key_fingerprint matches every key.
+func (q *Queries) ListAPIKeyUsage(ctx context.Context, arg ListAPIKeyUsageParams) ([]ApiKeyUsage, error) {
+	rows, err := q.query(ctx, q.listAPIKeyUsageStmt, listAPIKeyUsage, arg.Since, arg.KeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKeyUsage
+	for rows.Next() {
+		var i ApiKeyUsage
+		if err := rows.Scan(
+			&i.Day,
+			&i.KeyFingerprint,
+			&i.Endpoint,
+			&i.Requests,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, key_hash, contact_email, description, rate_limit, created_at, revoked_at FROM api_keys
+ORDER BY id
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.query(ctx, q.listAPIKeysStmt, listAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.KeyHash,
+			&i.ContactEmail,
+			&i.Description,
+			&i.RateLimit,
+			&i.CreatedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAdminAuditEntries = `-- name: ListAdminAuditEntries :many
+SELECT id, actor, "action", target, previous_value, new_value, idempotency_key, response_status, response_body, created_at FROM admin_audit_log
+WHERE created_at >= ?1
+ORDER BY created_at DESC, id DESC
+LIMIT ?2
+`
+
+type ListAdminAuditEntriesParams struct {
+	Since int64
+	Limit int64
+}
+
+// Newest first; entries older than @since are skipped.
+func (q *Queries) ListAdminAuditEntries(ctx context.Context, arg ListAdminAuditEntriesParams) ([]AdminAuditLog, error) {
+	rows, err := q.query(ctx, q.listAdminAuditEntriesStmt, listAdminAuditEntries, arg.Since, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AdminAuditLog
+	for rows.Next() {
+		var i AdminAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.Target,
+			&i.PreviousValue,
+			&i.NewValue,
+			&i.IdempotencyKey,
+			&i.ResponseStatus,
+			&i.ResponseBody,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAgencies = `-- name: ListAgencies :many
 SELECT
     id, name, url, timezone, lang, phone, fare_url, email
@@ -4925,6 +7195,84 @@ func (q *Queries) ListAgencyIds(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
+const listArrivalHistoryForTrip = `-- name: ListArrivalHistoryForTrip :many
+SELECT stop_sequence, delay, occupancy_status
+FROM arrival_history
+WHERE trip_id = ?1
+ORDER BY stop_sequence, service_date
+`
+
+type ListArrivalHistoryForTripRow struct {
+	StopSequence    int64
+	Delay           int64
+	OccupancyStatus string
+}
+
+func (q *Queries) ListArrivalHistoryForTrip(ctx context.Context, tripID string) ([]ListArrivalHistoryForTripRow, error) {
+	rows, err := q.query(ctx, q.listArrivalHistoryForTripStmt, listArrivalHistoryForTrip, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListArrivalHistoryForTripRow
+	for rows.Next() {
+		var i ListArrivalHistoryForTripRow
+		if err := rows.Scan(&i.StopSequence, &i.Delay, &i.OccupancyStatus); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeveloperAPIKeyQuotaRequests = `-- name: ListDeveloperAPIKeyQuotaRequests :many
+SELECT id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at FROM developer_api_keys
+WHERE status = 'active' AND quota_request IS NOT NULL
+ORDER BY quota_requested_at, id
+`
+
+func (q *Queries) ListDeveloperAPIKeyQuotaRequests(ctx context.Context) ([]DeveloperApiKey, error) {
+	rows, err := q.query(ctx, q.listDeveloperAPIKeyQuotaRequestsStmt, listDeveloperAPIKeyQuotaRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeveloperApiKey
+	for rows.Next() {
+		var i DeveloperApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.ApiKeyHash,
+			&i.VerificationTokenHash,
+			&i.Status,
+			&i.Tier,
+			&i.QuotaRequest,
+			&i.QuotaRequestedAt,
+			&i.CreatedAt,
+			&i.VerificationExpiresAt,
+			&i.VerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRoutes = `-- name: ListRoutes :many
 SELECT
     id,
@@ -4937,7 +7285,8 @@ SELECT
     color,
     text_color,
     continuous_pickup,
-    continuous_drop_off
+    continuous_drop_off,
+    sort_order
 FROM
     routes
 ORDER BY
@@ -4966,6 +7315,7 @@ func (q *Queries) ListRoutes(ctx context.Context) ([]Route, error) {
 			&i.TextColor,
 			&i.ContinuousPickup,
 			&i.ContinuousDropOff,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -5114,6 +7464,110 @@ func (q *Queries) ListTripsWithLimit(ctx context.Context, limit int64) ([]Trip,
 	return items, nil
 }
 
+const recordArrivalHistory = `-- name: RecordArrivalHistory :exec
+INSERT OR REPLACE INTO arrival_history (
+    service_date,
+    trip_id,
+    stop_sequence,
+    stop_id,
+    vehicle_id,
+    actual_arrival,
+    actual_departure,
+    delay,
+    occupancy_status,
+    recorded_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type RecordArrivalHistoryParams struct {
+	ServiceDate     string
+	TripID          string
+	StopSequence    int64
+	StopID          string
+	VehicleID       string
+	ActualArrival   sql.NullInt64
+	ActualDeparture sql.NullInt64
+	Delay           int64
+	OccupancyStatus string
+	RecordedAt      int64
+}
+
+func (q *Queries) RecordArrivalHistory(ctx context.Context, arg RecordArrivalHistoryParams) error {
+	_, err := q.exec(ctx, q.recordArrivalHistoryStmt, recordArrivalHistory,
+		arg.ServiceDate,
+		arg.TripID,
+		arg.StopSequence,
+		arg.StopID,
+		arg.VehicleID,
+		arg.ActualArrival,
+		arg.ActualDeparture,
+		arg.Delay,
+		arg.OccupancyStatus,
+		arg.RecordedAt,
+	)
+	return err
+}
+
+const requestDeveloperAPIKeyQuota = `-- name: RequestDeveloperAPIKeyQuota :one
+UPDATE developer_api_keys
+SET quota_request = ?1, quota_requested_at = ?2
+WHERE id = ?3 AND status = 'active'
+RETURNING id, email, name, api_key_hash, verification_token_hash, status, tier, quota_request, quota_requested_at, created_at, verification_expires_at, verified_at
+`
+
+type RequestDeveloperAPIKeyQuotaParams struct {
+	QuotaRequest     sql.NullString
+	QuotaRequestedAt sql.NullInt64
+	ID               int64
+}
+
+func (q *Queries) RequestDeveloperAPIKeyQuota(ctx context.Context, arg RequestDeveloperAPIKeyQuotaParams) (DeveloperApiKey, error) {
+	row := q.queryRow(ctx, q.requestDeveloperAPIKeyQuotaStmt, requestDeveloperAPIKeyQuota, arg.QuotaRequest, arg.QuotaRequestedAt, arg.ID)
+	var i DeveloperApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.ApiKeyHash,
+		&i.VerificationTokenHash,
+		&i.Status,
+		&i.Tier,
+		&i.QuotaRequest,
+		&i.QuotaRequestedAt,
+		&i.CreatedAt,
+		&i.VerificationExpiresAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = ?1
+WHERE id = ?2 AND revoked_at IS NULL
+RETURNING id, key_hash, contact_email, description, rate_limit, created_at, revoked_at
+`
+
+type RevokeAPIKeyParams struct {
+	RevokedAt sql.NullInt64
+	ID        int64
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	row := q.queryRow(ctx, q.revokeAPIKeyStmt, revokeAPIKey, arg.RevokedAt, arg.ID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.KeyHash,
+		&i.ContactEmail,
+		&i.Description,
+		&i.RateLimit,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
 const routeHasFutureService = `-- name: RouteHasFutureService :one
 SELECT EXISTS (
     SELECT 1
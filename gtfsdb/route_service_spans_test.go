@@ -0,0 +1,90 @@
+package gtfsdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRouteServiceSpans_MatchesTripBounds(t *testing.T) {
+	client := newTestClientWithRABA(t)
+	ctx := context.Background()
+
+	var routeServices, spans int
+	require.NoError(t, client.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT DISTINCT route_id, service_id FROM trips
+			WHERE min_arrival_time IS NOT NULL AND max_departure_time IS NOT NULL
+		)`).Scan(&routeServices))
+	require.NoError(t, client.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM route_service_spans").Scan(&spans))
+	require.Greater(t, routeServices, 0)
+	assert.Equal(t, routeServices, spans)
+
+	// RABA has no frequencies, so every span is its trips' time bounds.
+	var mismatched int
+	require.NoError(t, client.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM route_service_spans s
+		JOIN (
+			SELECT route_id, service_id, MIN(min_arrival_time) AS lo, MAX(max_departure_time) AS hi
+			FROM trips GROUP BY route_id, service_id
+		) t ON t.route_id = s.route_id AND t.service_id = s.service_id
+		WHERE s.first_departure_time != t.lo OR s.last_departure_time != t.hi`).Scan(&mismatched))
+	assert.Zero(t, mismatched)
+
+	var routeID, serviceID string
+	require.NoError(t, client.DB.QueryRowContext(ctx,
+		"SELECT route_id, service_id FROM route_service_spans LIMIT 1").Scan(&routeID, &serviceID))
+	rows, err := client.Queries.GetRouteServiceSpans(ctx, GetRouteServiceSpansParams{RouteID: routeID, ServiceIds: []string{serviceID}})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Less(t, rows[0].FirstDepartureTime, rows[0].LastDepartureTime)
+
+	rows, err = client.Queries.GetRouteServiceSpans(ctx, GetRouteServiceSpansParams{RouteID: routeID})
+	require.NoError(t, err)
+	assert.Empty(t, rows, "no active services")
+
+	rows, err = client.Queries.GetRouteServiceSpansForAgency(ctx, GetRouteServiceSpansForAgencyParams{AgencyID: "25", ServiceIds: []string{serviceID}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, rows)
+}
+
+func TestBuildRouteServiceSpans_FrequencyTrips(t *testing.T) {
+	client := newTestClientWithRABA(t)
+	ctx := context.Background()
+
+	var tripID, routeID, serviceID string
+	var start, end int64
+	require.NoError(t, client.DB.QueryRowContext(ctx,
+		"SELECT id, route_id, service_id, min_arrival_time, max_departure_time FROM trips LIMIT 1",
+	).Scan(&tripID, &routeID, &serviceID, &start, &end))
+
+	// Run the trip every 10 minutes from 1:00 to 3:00, past the rest of its
+	// route's service.
+	const hour = int64(3600e9)
+	_, err := client.DB.ExecContext(ctx, `
+		INSERT INTO frequencies (trip_id, start_time, end_time, headway_secs, exact_times)
+		VALUES (?, ?, ?, 600, 0)`, tripID, 25*hour, 27*hour)
+	require.NoError(t, err)
+	require.NoError(t, client.Queries.ClearRouteServiceSpans(ctx))
+	require.NoError(t, client.Queries.BuildRouteServiceSpans(ctx))
+
+	rows, err := client.Queries.GetRouteServiceSpans(ctx, GetRouteServiceSpansParams{RouteID: routeID, ServiceIds: []string{serviceID}})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 27*hour+end-start, rows[0].LastDepartureTime, "the last window ends a trip duration after its end time")
+}
+
+func TestPerformDatabaseMigration_BackfillsRouteServiceSpans(t *testing.T) {
+	client := newTestClientWithRABA(t)
+	ctx := context.Background()
+
+	// A database imported before spans were built at import time.
+	require.NoError(t, client.Queries.ClearRouteServiceSpans(ctx))
+	require.NoError(t, performDatabaseMigration(ctx, client.DB))
+
+	var spans int
+	require.NoError(t, client.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM route_service_spans").Scan(&spans))
+	assert.Greater(t, spans, 0)
+}
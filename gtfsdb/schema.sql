@@ -42,6 +42,11 @@ CREATE TABLE
         FOREIGN KEY (agency_id) REFERENCES agencies (id)
     ) STRICT;
 
+-- route_sort_order from routes.txt. Added after the table was first released,
+-- so existing databases gain it through ALTER TABLE.
+-- migrate
+ALTER TABLE routes ADD COLUMN sort_order INTEGER;
+
 -- migrate
 -- FTS5 external content table for full-text route search.
 -- Data lives in 'routes' table; only the search index is stored here.
@@ -559,3 +564,329 @@ CREATE INDEX IF NOT EXISTS idx_trips_route_headsign ON trips (route_id, trip_hea
 
 -- migrate
 CREATE INDEX IF NOT EXISTS idx_trips_time_window ON trips (max_departure_time, min_arrival_time);
+
+-- GTFS-Flex: booking rules for demand-responsive and deviated fixed-route service.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS booking_rules (
+        id TEXT PRIMARY KEY,
+        booking_type INTEGER NOT NULL CHECK (booking_type IN (0, 1, 2)), -- 0 = real-time, 1 = up to same day, 2 = up to prior day(s)
+        prior_notice_duration_min INTEGER, -- Minutes
+        prior_notice_duration_max INTEGER, -- Minutes
+        prior_notice_last_day INTEGER,
+        prior_notice_last_time TEXT, -- HH:MM:SS on prior_notice_last_day
+        prior_notice_start_day INTEGER,
+        prior_notice_start_time TEXT, -- HH:MM:SS on prior_notice_start_day
+        prior_notice_service_id TEXT,
+        message TEXT,
+        pickup_message TEXT,
+        drop_off_message TEXT,
+        phone_number TEXT,
+        info_url TEXT,
+        booking_url TEXT
+    ) STRICT;
+
+-- GTFS-Flex: named groups of stops that flexible stop_times can serve as a whole.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS location_groups (
+        id TEXT PRIMARY KEY,
+        name TEXT
+    ) STRICT;
+
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS location_group_stops (
+        location_group_id TEXT NOT NULL,
+        stop_id TEXT NOT NULL,
+        PRIMARY KEY (location_group_id, stop_id),
+        FOREIGN KEY (location_group_id) REFERENCES location_groups (id),
+        FOREIGN KEY (stop_id) REFERENCES stops (id)
+    ) STRICT;
+
+-- GTFS-Flex fields of stop_times.txt rows. A row serves either a stop or a
+-- location group. route_id is taken from trips.txt because trips that only
+-- serve location groups are not imported into the trips table.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS flex_stop_times (
+        trip_id TEXT NOT NULL,
+        stop_sequence INTEGER NOT NULL,
+        route_id TEXT NOT NULL,
+        stop_id TEXT,
+        location_group_id TEXT,
+        start_pickup_drop_off_window INTEGER, -- Nanoseconds since midnight
+        end_pickup_drop_off_window INTEGER, -- Nanoseconds since midnight
+        pickup_booking_rule_id TEXT,
+        drop_off_booking_rule_id TEXT,
+        CHECK (stop_id IS NOT NULL OR location_group_id IS NOT NULL),
+        PRIMARY KEY (trip_id, stop_sequence)
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_flex_stop_times_stop_id ON flex_stop_times (stop_id);
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_flex_stop_times_location_group_id ON flex_stop_times (location_group_id);
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_flex_stop_times_route_id ON flex_stop_times (route_id);
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_location_group_stops_stop_id ON location_group_stops (stop_id);
+
+-- API keys issued through the developer portal. Only SHA-256 hashes of the
+-- key and of the emailed verification token are stored; api_key_hash is set
+-- once the signup is verified.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS developer_api_keys (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        email TEXT NOT NULL,
+        name TEXT NOT NULL DEFAULT '',
+        api_key_hash TEXT UNIQUE,
+        verification_token_hash TEXT NOT NULL UNIQUE,
+        status TEXT NOT NULL CHECK (status IN ('pending', 'active', 'revoked')),
+        tier TEXT NOT NULL CHECK (tier IN ('standard', 'elevated')),
+        quota_request TEXT, -- Reason given for a pending elevated quota request
+        quota_requested_at INTEGER,
+        created_at INTEGER NOT NULL,
+        verification_expires_at INTEGER NOT NULL,
+        verified_at INTEGER
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_developer_api_keys_email ON developer_api_keys (email);
+
+-- API keys issued by an admin. Keys are stored as SHA-256 hashes, so a key
+-- is only ever shown in the response that creates it. rate_limit is the
+-- key's own limit in requests per second. Revoked keys are kept for the
+-- record.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS api_keys (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        key_hash TEXT NOT NULL UNIQUE,
+        contact_email TEXT NOT NULL,
+        description TEXT NOT NULL DEFAULT '',
+        rate_limit INTEGER NOT NULL,
+        created_at INTEGER NOT NULL,
+        revoked_at INTEGER
+    ) STRICT;
+
+-- Requests served per API key, endpoint and UTC day (YYYY-MM-DD). Keys are
+-- identified by the fingerprint also used in the admin audit log, never by
+-- the key itself.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS api_key_usage (
+        day TEXT NOT NULL,
+        key_fingerprint TEXT NOT NULL,
+        endpoint TEXT NOT NULL,
+        requests INTEGER NOT NULL,
+        PRIMARY KEY (day, key_fingerprint, endpoint)
+    ) STRICT;
+
+-- Audit trail of admin mutations. actor is a SHA-256 fingerprint of the
+-- protected API key, never the key itself. previous_value and new_value hold
+-- JSON snapshots of the changed object. A request that carried an
+-- idempotency key also stores its response so a retry can be replayed
+-- instead of applied twice.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS admin_audit_log (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        actor TEXT NOT NULL,
+        action TEXT NOT NULL,
+        target TEXT NOT NULL DEFAULT '',
+        previous_value TEXT,
+        new_value TEXT,
+        idempotency_key TEXT UNIQUE,
+        response_status INTEGER NOT NULL,
+        response_body BLOB,
+        created_at INTEGER NOT NULL
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log (created_at);
+
+-- GTFS fares v1: fare_attributes.txt and fare_rules.txt.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS fare_attributes (
+        id TEXT PRIMARY KEY,
+        price REAL NOT NULL,
+        currency_type TEXT NOT NULL,
+        payment_method INTEGER NOT NULL CHECK (payment_method IN (0, 1)), -- 0 = paid on board, 1 = paid before boarding
+        transfers INTEGER CHECK (transfers IN (0, 1, 2)), -- NULL = unlimited
+        agency_id TEXT,
+        transfer_duration INTEGER -- Seconds
+    ) STRICT;
+
+-- A fare_rules.txt row. Rows without a route_id apply by zone alone.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS fare_rules (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        fare_id TEXT NOT NULL,
+        route_id TEXT,
+        origin_id TEXT,
+        destination_id TEXT,
+        contains_id TEXT,
+        FOREIGN KEY (fare_id) REFERENCES fare_attributes (id)
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_fare_rules_route_id ON fare_rules (route_id);
+
+-- GTFS fares v2: fare_media.txt, fare_products.txt, rider_categories.txt,
+-- fare_leg_rules.txt, areas.txt and stop_areas.txt.
+-- Routes belong to networks through routes.network_id or
+-- route_networks.txt; both are stored in route_networks.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS fare_media (
+        id TEXT PRIMARY KEY,
+        name TEXT,
+        fare_media_type INTEGER NOT NULL CHECK (fare_media_type BETWEEN 0 AND 4)
+    ) STRICT;
+
+-- fare_media_id and rider_category_id are '' for a product that does not
+-- depend on the media or the rider. Databases created before rider categories
+-- are rebuilt by migrateFareProductsRiderCategory.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS fare_products (
+        fare_product_id TEXT NOT NULL,
+        fare_media_id TEXT NOT NULL DEFAULT '',
+        rider_category_id TEXT NOT NULL DEFAULT '',
+        name TEXT,
+        amount REAL NOT NULL,
+        currency TEXT NOT NULL,
+        PRIMARY KEY (fare_product_id, rider_category_id, fare_media_id)
+    ) STRICT;
+
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS rider_categories (
+        id TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        is_default_fare_category INTEGER NOT NULL DEFAULT 0,
+        eligibility_url TEXT
+    ) STRICT;
+
+-- Fare areas from areas.txt and the stops in them from stop_areas.txt.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS areas (
+        id TEXT PRIMARY KEY,
+        name TEXT
+    ) STRICT;
+
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS stop_areas (
+        area_id TEXT NOT NULL,
+        stop_id TEXT NOT NULL,
+        PRIMARY KEY (area_id, stop_id)
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_stop_areas_stop_id ON stop_areas (stop_id);
+
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS fare_leg_rules (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        leg_group_id TEXT,
+        network_id TEXT, -- NULL matches every network
+        from_area_id TEXT,
+        to_area_id TEXT,
+        fare_product_id TEXT NOT NULL,
+        rule_priority INTEGER NOT NULL DEFAULT 0
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_fare_leg_rules_network_id ON fare_leg_rules (network_id);
+
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS route_networks (
+        route_id TEXT NOT NULL,
+        network_id TEXT NOT NULL,
+        PRIMARY KEY (route_id, network_id)
+    ) STRICT;
+
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS block_trip_order (
+        trip_id TEXT PRIMARY KEY,
+        block_id TEXT NOT NULL,
+        service_id TEXT NOT NULL,
+        block_sequence INTEGER NOT NULL, -- Order of the trip among all of its block's trips, across services
+        start_time INTEGER NOT NULL, -- trips.min_arrival_time
+        end_time INTEGER NOT NULL -- trips.max_departure_time
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_block_trip_order_block_sequence ON block_trip_order (block_id, block_sequence);
+
+-- Span of each route's service per service ID, from the start of its first
+-- trip to the end of its last, in nanoseconds since the service day's
+-- midnight like stop_times. Built at import time.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS route_service_spans (
+        route_id TEXT NOT NULL,
+        service_id TEXT NOT NULL,
+        first_departure_time INTEGER NOT NULL,
+        last_departure_time INTEGER NOT NULL,
+        PRIMARY KEY (route_id, service_id)
+    ) STRICT;
+
+-- transfers.txt, or transfers generated between nearby stops when the feed
+-- has none (generated = 1).
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS transfers (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        from_stop_id TEXT NOT NULL,
+        to_stop_id TEXT NOT NULL,
+        from_route_id TEXT,
+        to_route_id TEXT,
+        from_trip_id TEXT,
+        to_trip_id TEXT,
+        transfer_type INTEGER NOT NULL CHECK (transfer_type BETWEEN 0 AND 3),
+        min_transfer_time INTEGER, -- Seconds
+        generated INTEGER NOT NULL DEFAULT 0 CHECK (generated IN (0, 1)),
+        distance REAL -- Meters between the stops, for generated transfers
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_transfers_from_stop_id ON transfers (from_stop_id);
+
+-- Realized arrivals and departures of trips at their stops, recorded from
+-- the realtime feeds when arrival history is enabled. Times are Unix
+-- milliseconds; delay is in seconds against the scheduled arrival. Not
+-- cleared by static reloads, so history spans feed versions.
+-- migrate
+CREATE TABLE
+    IF NOT EXISTS arrival_history (
+        service_date TEXT NOT NULL, -- YYYYMMDD
+        trip_id TEXT NOT NULL,
+        stop_sequence INTEGER NOT NULL,
+        stop_id TEXT NOT NULL,
+        vehicle_id TEXT NOT NULL,
+        actual_arrival INTEGER,
+        actual_departure INTEGER,
+        delay INTEGER NOT NULL,
+        occupancy_status TEXT NOT NULL, -- '' when the vehicle reported none
+        recorded_at INTEGER NOT NULL,
+        PRIMARY KEY (service_date, trip_id, stop_sequence)
+    ) STRICT;
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_arrival_history_trip_id ON arrival_history (trip_id, stop_sequence);
+
+-- migrate
+CREATE INDEX IF NOT EXISTS idx_arrival_history_recorded_at ON arrival_history (recorded_at);
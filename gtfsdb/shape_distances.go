@@ -0,0 +1,105 @@
+package gtfsdb
+
+import (
+	"math"
+	"strings"
+
+	"github.com/OneBusAway/go-gtfs"
+)
+
+const (
+	// shapeMatchEarlyExit and shapeMatchGood bound the forward search for a
+	// stop's closest shape segment: once a segment within shapeMatchGood
+	// meters has been found, the search stops when segments get more than
+	// shapeMatchEarlyExit meters further away than the best one.
+	shapeMatchEarlyExit = 100.0
+	shapeMatchGood      = 500.0
+)
+
+// shapeDistanceCalculator computes the distance along its shape of every stop
+// of a trip that has no shape_dist_traveled in the feed. Trips sharing a shape
+// and a stop pattern are only computed once.
+type shapeDistanceCalculator struct {
+	cache map[string][]float64
+}
+
+func newShapeDistanceCalculator() *shapeDistanceCalculator {
+	return &shapeDistanceCalculator{cache: make(map[string][]float64)}
+}
+
+// stopDistances returns the distance in meters along the trip's shape of each
+// of its stop times, or nil if the trip has no usable shape, a stop has no
+// coordinates, or the feed gives any of its stop times a shape_dist_traveled.
+// Feed values are kept as they are, in whatever unit the feed uses, so a trip
+// never mixes them with computed meters.
+func (c *shapeDistanceCalculator) stopDistances(trip *gtfs.ScheduledTrip) []float64 {
+	if trip.Shape == nil || len(trip.Shape.Points) < 2 || len(trip.StopTimes) == 0 {
+		return nil
+	}
+	var key strings.Builder
+	key.WriteString(trip.Shape.ID)
+	for _, st := range trip.StopTimes {
+		if st.ShapeDistanceTraveled != nil || st.Stop == nil || st.Stop.Latitude == nil || st.Stop.Longitude == nil {
+			return nil
+		}
+		key.WriteByte(0)
+		key.WriteString(st.Stop.Id)
+	}
+	if distances, ok := c.cache[key.String()]; ok {
+		return distances
+	}
+
+	distances := projectStopsOntoShape(trip.StopTimes, trip.Shape.Points)
+	c.cache[key.String()] = distances
+	return distances
+}
+
+// projectStopsOntoShape returns the distance along points of the closest point
+// to each stop. Stops are matched in order, each no earlier along the shape
+// than the one before, so a shape that passes a stop twice is matched on the
+// pass the trip serves it.
+func projectStopsOntoShape(stopTimes []gtfs.ScheduledStopTime, points []gtfs.ShapePoint) []float64 {
+	cumulative := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		cumulative[i] = cumulative[i-1] + haversineMeters(
+			points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+
+	distances := make([]float64, len(stopTimes))
+	first, firstRatio := 0, 0.0
+	for i, st := range stopTimes {
+		lat, lon := *st.Stop.Latitude, *st.Stop.Longitude
+		best, bestRatio, bestDistance := first, firstRatio, math.Inf(1)
+		for j := first; j < len(points)-1; j++ {
+			minRatio := 0.0
+			if j == first {
+				minRatio = firstRatio
+			}
+			distance, ratio := projectOntoSegment(lat, lon, points[j], points[j+1], minRatio)
+			if distance < bestDistance {
+				best, bestRatio, bestDistance = j, ratio, distance
+			} else if bestDistance < shapeMatchGood && distance > bestDistance+shapeMatchEarlyExit {
+				break
+			}
+		}
+		distances[i] = cumulative[best] + bestRatio*(cumulative[best+1]-cumulative[best])
+		first, firstRatio = best, bestRatio
+	}
+	return distances
+}
+
+// projectOntoSegment returns the distance in meters from a point to the
+// closest point of the segment from a to b that is at least minRatio along it,
+// and how far along the segment, from 0 to 1, that point is. Over a single
+// segment an equirectangular projection is accurate enough.
+func projectOntoSegment(lat, lon float64, a, b gtfs.ShapePoint, minRatio float64) (distance, ratio float64) {
+	metersPerDegreeLon := metersPerDegreeLat * math.Cos(lat*math.Pi/180)
+	ax, ay := (a.Longitude-lon)*metersPerDegreeLon, (a.Latitude-lat)*metersPerDegreeLat
+	bx, by := (b.Longitude-lon)*metersPerDegreeLon, (b.Latitude-lat)*metersPerDegreeLat
+	dx, dy := bx-ax, by-ay
+	ratio = minRatio
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		ratio = math.Max(minRatio, math.Min(1, -(ax*dx+ay*dy)/lengthSquared))
+	}
+	return math.Hypot(ax+ratio*dx, ay+ratio*dy), ratio
+}
@@ -0,0 +1,95 @@
+package gtfsdb
+
+import (
+	"testing"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shapeDistanceTestTrip(shape *gtfs.Shape, stops ...gtfs.Stop) *gtfs.ScheduledTrip {
+	trip := &gtfs.ScheduledTrip{ID: "trip", Shape: shape}
+	for i := range stops {
+		trip.StopTimes = append(trip.StopTimes, gtfs.ScheduledStopTime{Stop: &stops[i], StopSequence: i})
+	}
+	return trip
+}
+
+func shapeDistanceTestStop(id string, lat, lon float64) gtfs.Stop {
+	return gtfs.Stop{Id: id, Latitude: &lat, Longitude: &lon}
+}
+
+func TestShapeDistanceCalculator_UnevenStopSpacing(t *testing.T) {
+	// A straight shape running 2km north; a degree of latitude is ~111.2km.
+	shape := &gtfs.Shape{ID: "north", Points: []gtfs.ShapePoint{
+		{Latitude: 47.600, Longitude: -122.33},
+		{Latitude: 47.610, Longitude: -122.33},
+		{Latitude: 47.618, Longitude: -122.33},
+	}}
+	trip := shapeDistanceTestTrip(shape,
+		shapeDistanceTestStop("start", 47.600, -122.33),
+		shapeDistanceTestStop("near", 47.601, -122.33),
+		shapeDistanceTestStop("end", 47.618, -122.33),
+	)
+
+	distances := newShapeDistanceCalculator().stopDistances(trip)
+
+	require.Len(t, distances, 3)
+	assert.InDelta(t, 0, distances[0], 1)
+	assert.InDelta(t, 111.2, distances[1], 1, "not halfway, as the stop's index would suggest")
+	assert.InDelta(t, 2001.5, distances[2], 2)
+}
+
+func TestShapeDistanceCalculator_LoopMatchesStopsInOrder(t *testing.T) {
+	// Out 1km east and back along the same street.
+	shape := &gtfs.Shape{ID: "loop", Points: []gtfs.ShapePoint{
+		{Latitude: 47.6, Longitude: -122.330},
+		{Latitude: 47.6, Longitude: -122.3167},
+		{Latitude: 47.6, Longitude: -122.330},
+	}}
+	trip := shapeDistanceTestTrip(shape,
+		shapeDistanceTestStop("depart", 47.6, -122.330),
+		shapeDistanceTestStop("turn", 47.6, -122.3167),
+		shapeDistanceTestStop("return", 47.6, -122.330),
+	)
+
+	distances := newShapeDistanceCalculator().stopDistances(trip)
+
+	require.Len(t, distances, 3)
+	assert.InDelta(t, 0, distances[0], 1)
+	assert.InDelta(t, 1000, distances[1], 5)
+	assert.InDelta(t, 2000, distances[2], 10, "the last stop is served on the way back")
+}
+
+func TestShapeDistanceCalculator_SkipsTrips(t *testing.T) {
+	shape := &gtfs.Shape{ID: "north", Points: []gtfs.ShapePoint{
+		{Latitude: 47.600, Longitude: -122.33},
+		{Latitude: 47.610, Longitude: -122.33},
+	}}
+	calc := newShapeDistanceCalculator()
+
+	assert.Nil(t, calc.stopDistances(shapeDistanceTestTrip(nil, shapeDistanceTestStop("a", 47.6, -122.33))), "no shape")
+	assert.Nil(t, calc.stopDistances(shapeDistanceTestTrip(shape, gtfs.Stop{Id: "a"})), "stop without coordinates")
+
+	fromFeed := shapeDistanceTestTrip(shape, shapeDistanceTestStop("a", 47.6, -122.33), shapeDistanceTestStop("b", 47.61, -122.33))
+	dist := 0.7
+	fromFeed.StopTimes[1].ShapeDistanceTraveled = &dist
+	assert.Nil(t, calc.stopDistances(fromFeed), "feed distances are not mixed with computed ones")
+}
+
+func TestShapeDistanceCalculator_ReusesSharedPatterns(t *testing.T) {
+	shape := &gtfs.Shape{ID: "north", Points: []gtfs.ShapePoint{
+		{Latitude: 47.600, Longitude: -122.33},
+		{Latitude: 47.610, Longitude: -122.33},
+	}}
+	calc := newShapeDistanceCalculator()
+	stops := []gtfs.Stop{shapeDistanceTestStop("a", 47.600, -122.33), shapeDistanceTestStop("b", 47.605, -122.33)}
+
+	first := calc.stopDistances(shapeDistanceTestTrip(shape, stops...))
+	second := calc.stopDistances(shapeDistanceTestTrip(shape, stops...))
+
+	require.Len(t, first, 2)
+	assert.Same(t, &first[0], &second[0])
+	assert.Len(t, calc.cache, 1)
+}
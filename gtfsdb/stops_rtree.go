@@ -2,6 +2,7 @@ package gtfsdb
 
 import (
 	"context"
+	"encoding/json"
 )
 
 // Implemented manually because sqlc doesn't support the virtual tables from the RTree module.
@@ -155,26 +156,38 @@ JOIN routes ON routes.id = sr.route_id
     -- COLLATE NOCASE gives case-insensitive equality without LIKE's wildcard
     -- semantics. Note: NOCASE only folds ASCII A-Z; non-ASCII short names
     -- will not match case-insensitively.
-WHERE ?7 == "" OR routes.short_name = ?7 COLLATE NOCASE
+WHERE (?7 == "" OR routes.short_name = ?7 COLLATE NOCASE)
+  -- ?9 is a JSON array of route types; an empty string disables the filter.
+  AND (?9 == "" OR routes.type IN (SELECT value FROM json_each(?9)))
 GROUP BY routes.id
 ORDER BY min_distance ASC
 LIMIT ?8
 `
 
 type GetActiveRoutesWithinBoundsParams struct {
-	Lat       float64
-	Lon       float64
-	MinLat    float64
-	MaxLat    float64
-	MinLon    float64
-	MaxLon    float64
-	ShortName string
-	MaxCount  int
+	Lat        float64
+	Lon        float64
+	MinLat     float64
+	MaxLat     float64
+	MinLon     float64
+	MaxLon     float64
+	ShortName  string
+	MaxCount   int
+	RouteTypes []int // Optional; when empty, routes of every type are returned
 }
 
 func (q *Queries) GetActiveRoutesWithinBounds(ctx context.Context, arg GetActiveRoutesWithinBoundsParams) ([]Route, error) {
+	routeTypesJSON := ""
+	if len(arg.RouteTypes) > 0 {
+		encoded, err := json.Marshal(arg.RouteTypes)
+		if err != nil {
+			return nil, err
+		}
+		routeTypesJSON = string(encoded)
+	}
+
 	rows, err := q.db.QueryContext(ctx, getActiveRoutesWithinBounds,
-		arg.Lat, arg.Lon, arg.MinLat, arg.MaxLat, arg.MinLon, arg.MaxLon, arg.ShortName, arg.MaxCount)
+		arg.Lat, arg.Lon, arg.MinLat, arg.MaxLat, arg.MinLon, arg.MaxLon, arg.ShortName, arg.MaxCount, routeTypesJSON)
 	if err != nil {
 		return nil, err
 	}
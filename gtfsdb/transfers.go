@@ -0,0 +1,149 @@
+package gtfsdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/OneBusAway/go-gtfs"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+const (
+	// transferWalkingSpeed is the walking speed, in meters per second, used
+	// for the minimum transfer time of generated transfers.
+	transferWalkingSpeed = 1.4
+	// metersPerDegreeLat is the length of one degree of latitude.
+	metersPerDegreeLat = 111_320.0
+	earthRadiusMeters  = 6_371_010.0
+)
+
+// insertTransfers stores the feed's transfers.txt. When the feed has none and
+// generateWithin is positive, transfers are generated instead between every
+// pair of boarding stops at most generateWithin meters apart.
+func insertTransfers(ctx context.Context, q *Queries, static *gtfs.Static, generateWithin float64) error {
+	if len(static.Transfers) == 0 {
+		if generateWithin <= 0 {
+			return nil
+		}
+		for _, params := range generateTransfers(static.Stops, generateWithin) {
+			if err := q.CreateTransfer(ctx, params); err != nil {
+				return fmt.Errorf("generated transfer from %q to %q: %w", params.FromStopID, params.ToStopID, err)
+			}
+		}
+		return nil
+	}
+
+	for _, transfer := range static.Transfers {
+		if transfer.From == nil || transfer.To == nil {
+			continue
+		}
+		params := CreateTransferParams{
+			FromStopID:   transfer.From.Id,
+			ToStopID:     transfer.To.Id,
+			TransferType: int64(transfer.Type),
+		}
+		if transfer.FromRoute != nil {
+			params.FromRouteID = nulls.String(transfer.FromRoute.Id)
+		}
+		if transfer.ToRoute != nil {
+			params.ToRouteID = nulls.String(transfer.ToRoute.Id)
+		}
+		if transfer.FromTrip != nil {
+			params.FromTripID = nulls.String(transfer.FromTrip.ID)
+		}
+		if transfer.ToTrip != nil {
+			params.ToTripID = nulls.String(transfer.ToTrip.ID)
+		}
+		if transfer.MinTransferTime != nil {
+			params.MinTransferTime = nulls.Int64(int64(*transfer.MinTransferTime))
+		}
+		if err := q.CreateTransfer(ctx, params); err != nil {
+			return fmt.Errorf("transfer from %q to %q: %w", params.FromStopID, params.ToStopID, err)
+		}
+	}
+	return nil
+}
+
+// generateTransfers returns a transfer in each direction between every pair of
+// boarding stops at most within meters apart, ordered by stop ID. They are
+// transfer_type 2 with the time it takes to walk the straight-line distance
+// as the minimum transfer time.
+//
+// Stops are bucketed into a grid of cells at least within meters wide, so
+// each stop is only compared with the stops of its own and the neighbouring
+// cells.
+func generateTransfers(stops []gtfs.Stop, within float64) []CreateTransferParams {
+	var candidates []gtfs.Stop
+	maxAbsLat := 0.0
+	for _, stop := range stops {
+		if stop.Latitude == nil || stop.Longitude == nil {
+			continue
+		}
+		if stop.Type != gtfs.StopType_Stop && stop.Type != gtfs.StopType_Platform {
+			continue
+		}
+		candidates = append(candidates, stop)
+		maxAbsLat = max(maxAbsLat, math.Abs(*stop.Latitude))
+	}
+	slices.SortFunc(candidates, func(a, b gtfs.Stop) int { return strings.Compare(a.Id, b.Id) })
+
+	// A degree of longitude is narrowest at the highest latitude, so cells
+	// sized for it are wide enough everywhere else.
+	latStep := within / metersPerDegreeLat
+	lonStep := latStep / max(math.Cos(maxAbsLat*math.Pi/180), 0.01)
+	type cell struct{ lat, lon int64 }
+	cellOf := func(stop gtfs.Stop) cell {
+		return cell{int64(math.Floor(*stop.Latitude / latStep)), int64(math.Floor(*stop.Longitude / lonStep))}
+	}
+	grid := make(map[cell][]int)
+	for i, stop := range candidates {
+		c := cellOf(stop)
+		grid[c] = append(grid[c], i)
+	}
+
+	var transfers []CreateTransferParams
+	for i, from := range candidates {
+		c := cellOf(from)
+		var near []int
+		for dLat := int64(-1); dLat <= 1; dLat++ {
+			for dLon := int64(-1); dLon <= 1; dLon++ {
+				near = append(near, grid[cell{c.lat + dLat, c.lon + dLon}]...)
+			}
+		}
+		slices.Sort(near)
+
+		for _, j := range near {
+			if j == i {
+				continue
+			}
+			to := candidates[j]
+			distance := haversineMeters(*from.Latitude, *from.Longitude, *to.Latitude, *to.Longitude)
+			if distance > within {
+				continue
+			}
+			transfers = append(transfers, CreateTransferParams{
+				FromStopID:      from.Id,
+				ToStopID:        to.Id,
+				TransferType:    int64(gtfs.TransferType_RequiresTime),
+				MinTransferTime: nulls.Int64(int64(math.Ceil(distance / transferWalkingSpeed))),
+				Generated:       1,
+				Distance:        sql.NullFloat64{Float64: distance, Valid: true},
+			})
+		}
+	}
+	return transfers
+}
+
+// haversineMeters is the great-circle distance between two points. gtfsdb
+// cannot use internal/utils, which depends on it.
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(math.Min(1, a)))
+}
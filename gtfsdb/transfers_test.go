@@ -0,0 +1,97 @@
+package gtfsdb
+
+import (
+	"testing"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+func TestGenerateTransfers(t *testing.T) {
+	stop := func(id string, lat, lon float64, stopType gtfs.StopType) gtfs.Stop {
+		return gtfs.Stop{Id: id, Latitude: &lat, Longitude: &lon, Type: stopType}
+	}
+	stops := []gtfs.Stop{
+		stop("c", 47.6000, -122.3300, gtfs.StopType_Stop),
+		stop("a", 47.6010, -122.3300, gtfs.StopType_Stop), // ~111m north of c
+		stop("b", 47.6100, -122.3300, gtfs.StopType_Stop), // ~1.1km north of c
+		stop("station", 47.6005, -122.3300, gtfs.StopType_Station),
+		{Id: "no_location", Type: gtfs.StopType_Stop},
+	}
+
+	transfers := generateTransfers(stops, 200)
+
+	require.Len(t, transfers, 2, "one transfer each way between a and c")
+	assert.Equal(t, "a", transfers[0].FromStopID)
+	assert.Equal(t, "c", transfers[0].ToStopID)
+	assert.Equal(t, "c", transfers[1].FromStopID)
+	assert.Equal(t, "a", transfers[1].ToStopID)
+
+	for _, transfer := range transfers {
+		assert.Equal(t, int64(gtfs.TransferType_RequiresTime), transfer.TransferType)
+		assert.Equal(t, int64(1), transfer.Generated)
+		assert.InDelta(t, 111.2, transfer.Distance.Float64, 1)
+		assert.Equal(t, int64(80), transfer.MinTransferTime.Int64, "111m at 1.4m/s, rounded up")
+	}
+
+	assert.Empty(t, generateTransfers(stops, 50))
+	assert.Len(t, generateTransfers(stops, 2000), 6, "every pair of the three boarding stops")
+}
+
+func TestSyntheticGTFS_GeneratedTransfers(t *testing.T) {
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test, TransferRadiusMeters: 1500})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	ctx := t.Context()
+	parsed, err := ParseGtfsData(buildSyntheticGTFSZip(t, false), "synthetic-transfers")
+	require.NoError(t, err)
+	_, err = client.StoreGtfsData(ctx, parsed)
+	require.NoError(t, err)
+
+	transfers, err := client.Queries.GetTransfersFromStop(ctx, "stop_2")
+	require.NoError(t, err)
+	require.Len(t, transfers, 2, "the stops on either side are about 1.4km away")
+	assert.Equal(t, "stop_1", transfers[0].ToStopID)
+	assert.Equal(t, "stop_3", transfers[1].ToStopID)
+	assert.Equal(t, int64(1), transfers[0].Generated)
+
+	transfers, err = client.Queries.GetTransfersFromStop(ctx, "stop_1")
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, "stop_2", transfers[0].ToStopID)
+}
+
+func TestInsertTransfers_FeedTransfersAreKept(t *testing.T) {
+	client, err := NewClient(Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	lat, lon := 47.6, -122.33
+	stops := []gtfs.Stop{
+		{Id: "a", Latitude: &lat, Longitude: &lon},
+		{Id: "b", Latitude: &lat, Longitude: &lon},
+	}
+	minTime := int32(120)
+	static := &gtfs.Static{
+		Stops:     stops,
+		Transfers: []gtfs.Transfer{{From: &stops[0], To: &stops[1], Type: gtfs.TransferType_Timed, MinTransferTime: &minTime}},
+	}
+
+	ctx := t.Context()
+	require.NoError(t, insertTransfers(ctx, client.Queries, static, 500))
+
+	transfers, err := client.Queries.GetTransfersFromStop(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, "b", transfers[0].ToStopID)
+	assert.Equal(t, int64(gtfs.TransferType_Timed), transfers[0].TransferType)
+	assert.Equal(t, int64(120), transfers[0].MinTransferTime.Int64)
+	assert.Equal(t, int64(0), transfers[0].Generated, "nothing is generated for a feed with transfers")
+
+	transfers, err = client.Queries.GetTransfersFromStop(ctx, "b")
+	require.NoError(t, err)
+	assert.Empty(t, transfers)
+}
@@ -0,0 +1,154 @@
+// Package apikeys implements the persistent store of API keys issued by an
+// admin. Each key has its own rate limit, a contact email and creation and
+// revocation times. Keys configured with the api-keys flag are not stored
+// here; they remain valid alongside stored keys as a bootstrap mechanism.
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/time/rate"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/issuedkeys"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// MaxDescriptionLength bounds a key's description, in runes.
+const MaxDescriptionLength = 200
+
+var (
+	ErrInvalidEmail     = errors.New("contact email must be a valid address")
+	ErrInvalidRateLimit = errors.New("rate limit must be a positive number of requests per second")
+	ErrUnknownKey       = errors.New("API key is unknown or revoked")
+	ErrNotFound         = errors.New("no API key with this id")
+	ErrAlreadyRevoked   = errors.New("API key is already revoked")
+)
+
+// Store issues, revokes and validates API keys persisted in the GTFS
+// database, so they survive restarts and static data reloads.
+type Store struct {
+	queries *gtfsdb.Queries
+	clock   clock.Clock
+	logger  *slog.Logger
+
+	// limiters drops the limiter of a key when it is revoked.
+	limiters issuedkeys.Limiters
+}
+
+// New creates a Store backed by queries.
+func New(queries *gtfsdb.Queries, c clock.Clock, logger *slog.Logger) *Store {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Store{
+		queries: queries,
+		clock:   c,
+		logger:  logger.With(slog.String("component", "api_keys")),
+	}
+}
+
+// Create issues a new key allowing rateLimit requests per second and returns
+// it. The key itself is included only in this result.
+func (s *Store) Create(ctx context.Context, contactEmail, description string, rateLimit int) (models.APIKey, error) {
+	address, ok := issuedkeys.NormalizeEmail(contactEmail)
+	if !ok {
+		return models.APIKey{}, ErrInvalidEmail
+	}
+	if rateLimit <= 0 {
+		return models.APIKey{}, ErrInvalidRateLimit
+	}
+	description = issuedkeys.Truncate(strings.TrimSpace(description), MaxDescriptionLength)
+
+	key, err := issuedkeys.NewKey()
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	row, err := s.queries.CreateAPIKey(ctx, gtfsdb.CreateAPIKeyParams{
+		KeyHash:      issuedkeys.Hash(key),
+		ContactEmail: address,
+		Description:  description,
+		RateLimit:    int64(rateLimit),
+		CreatedAt:    s.clock.Now().UnixMilli(),
+	})
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	s.logger.Info("API key created", slog.Int64("id", row.ID), slog.String("email", row.ContactEmail))
+	created := models.NewAPIKey(row)
+	created.Key = key
+	return created, nil
+}
+
+// Revoke revokes the key with id. Requests using it are rejected from then
+// on.
+func (s *Store) Revoke(ctx context.Context, id int64) (models.APIKey, error) {
+	row, err := s.queries.RevokeAPIKey(ctx, gtfsdb.RevokeAPIKeyParams{
+		RevokedAt: sql.NullInt64{Int64: s.clock.Now().UnixMilli(), Valid: true},
+		ID:        id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Tell a missing key from one that was revoked before.
+		if _, getErr := s.Key(ctx, id); getErr != nil {
+			return models.APIKey{}, getErr
+		}
+		return models.APIKey{}, ErrAlreadyRevoked
+	}
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	s.limiters.Forget(row.KeyHash)
+
+	s.logger.Info("API key revoked", slog.Int64("id", row.ID))
+	return models.NewAPIKey(row), nil
+}
+
+// Key returns the key with id, without the key itself.
+func (s *Store) Key(ctx context.Context, id int64) (models.APIKey, error) {
+	row, err := s.queries.GetAPIKey(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	return models.NewAPIKey(row), nil
+}
+
+// List returns every stored key, revoked ones included, oldest first.
+func (s *Store) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.queries.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]models.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, models.NewAPIKey(row))
+	}
+	return keys, nil
+}
+
+// Limiter returns the rate limiter of an active stored key, or ErrUnknownKey
+// when key is not stored or has been revoked.
+func (s *Store) Limiter(ctx context.Context, key string) (*rate.Limiter, error) {
+	if key == "" {
+		return nil, ErrUnknownKey
+	}
+	hash := issuedkeys.Hash(key)
+	return s.limiters.Get(hash, func() (int, error) {
+		row, err := s.queries.GetActiveAPIKeyByHash(ctx, hash)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrUnknownKey
+		}
+		if err != nil {
+			return 0, err
+		}
+		return int(row.RateLimit), nil
+	})
+}
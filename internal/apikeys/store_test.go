@@ -0,0 +1,93 @@
+package apikeys
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+)
+
+func newTestStore(t *testing.T) (*Store, *clock.MockClock) {
+	t.Helper()
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	mockClock := clock.NewMockClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	return New(client.Queries, mockClock, nil), mockClock
+}
+
+func TestCreateAndLimit(t *testing.T) {
+	store, mockClock := newTestStore(t)
+	ctx := context.Background()
+
+	key, err := store.Create(ctx, " Ops@Example.org ", "  station signs ", 3)
+	require.NoError(t, err)
+	require.Len(t, key.Key, 40)
+	assert.Equal(t, "ops@example.org", key.ContactEmail)
+	assert.Equal(t, "station signs", key.Description)
+	assert.Equal(t, 3, key.RateLimit)
+	assert.Equal(t, mockClock.Now().UnixMilli(), key.CreatedAt)
+
+	limiter, err := store.Limiter(ctx, key.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, limiter.Burst())
+
+	again, err := store.Limiter(ctx, key.Key)
+	require.NoError(t, err)
+	assert.Same(t, limiter, again, "a key keeps one limiter")
+
+	_, err = store.Limiter(ctx, "not-a-key")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	_, err = store.Limiter(ctx, "")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	stored, err := store.Key(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Key, "the key is only returned on creation")
+}
+
+func TestCreateValidation(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	_, err := store.Create(ctx, "not-an-email", "", 5)
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+	_, err = store.Create(ctx, "Ops <ops@example.org>", "", 5)
+	assert.ErrorIs(t, err, ErrInvalidEmail, "only bare addresses are accepted")
+	_, err = store.Create(ctx, "ops@example.org", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidRateLimit)
+}
+
+func TestRevoke(t *testing.T) {
+	store, mockClock := newTestStore(t)
+	ctx := context.Background()
+
+	key, err := store.Create(ctx, "ops@example.org", "", 5)
+	require.NoError(t, err)
+	_, err = store.Limiter(ctx, key.Key)
+	require.NoError(t, err)
+
+	mockClock.Advance(time.Hour)
+	revoked, err := store.Revoke(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, mockClock.Now().UnixMilli(), revoked.RevokedAt)
+
+	_, err = store.Limiter(ctx, key.Key)
+	assert.ErrorIs(t, err, ErrUnknownKey, "a cached limiter does not outlive revocation")
+
+	_, err = store.Revoke(ctx, key.ID)
+	assert.ErrorIs(t, err, ErrAlreadyRevoked)
+	_, err = store.Revoke(ctx, key.ID+1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	keys, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, revoked, keys[0])
+}
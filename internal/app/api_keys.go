@@ -15,7 +15,7 @@ func (app *Application) IsInvalidAPIKey(key string) bool {
 		return true
 	}
 
-	validKeys := app.Config.ApiKeys
+	validKeys := app.CurrentConfig().ApiKeys
 	for _, validKey := range validKeys {
 		// Use constant-time comparison to prevent timing attacks
 		if subtle.ConstantTimeCompare([]byte(key), []byte(validKey)) == 1 {
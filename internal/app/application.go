@@ -2,6 +2,8 @@ package app
 
 import (
 	"log/slog"
+	"sync"
+	"sync/atomic"
 
 	"maglev.onebusaway.org/internal/appconf"
 	"maglev.onebusaway.org/internal/clock"
@@ -12,12 +14,39 @@ import (
 // Application holds the dependencies for our HTTP handlers, helpers,
 // and middleware. At the moment this only contains a copy of the Config struct and a
 // logger, but it will grow to include a lot more as our build progresses.
+//
+// Config is the configuration the application started with. Settings that can
+// be reloaded at runtime must be read through CurrentConfig instead.
 type Application struct {
-	Config              appconf.Config
+	Config appconf.Config
+	// ConfigPath is the JSON config file ReloadConfig re-reads; empty when
+	// configured with command-line flags.
+	ConfigPath          string
 	GtfsConfig          gtfs.Config
 	Logger              *slog.Logger
 	GtfsManager         *gtfs.Manager
 	DirectionCalculator *gtfs.AdvancedDirectionCalculator
 	Clock               clock.Clock
 	Metrics             *metrics.Metrics
+
+	liveConfig      atomic.Pointer[appconf.Config]
+	configMu        sync.Mutex // Serializes ApplyConfig and guards configListeners
+	configListeners []*func(appconf.Config)
+}
+
+// WithGtfsManager returns an Application that serves the data of manager,
+// with directionCalculator over its database, and is otherwise a copy of app.
+// It uses the configuration currently in effect and does not follow later
+// reloads.
+func (app *Application) WithGtfsManager(manager *gtfs.Manager, directionCalculator *gtfs.AdvancedDirectionCalculator) *Application {
+	return &Application{
+		Config:              app.CurrentConfig(),
+		ConfigPath:          app.ConfigPath,
+		GtfsConfig:          app.GtfsConfig,
+		Logger:              app.Logger,
+		GtfsManager:         manager,
+		DirectionCalculator: directionCalculator,
+		Clock:               app.Clock,
+		Metrics:             app.Metrics,
+	}
 }
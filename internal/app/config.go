@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/gtfs"
+)
+
+// ErrConfigNotReloadable is returned by ReloadConfig when the application was
+// configured with command-line flags rather than a config file.
+var ErrConfigNotReloadable = errors.New("configuration was not loaded from a file")
+
+// CurrentConfig returns the configuration in effect, including changes
+// applied by ReloadConfig since startup. It is safe for concurrent use.
+func (app *Application) CurrentConfig() appconf.Config {
+	if cfg := app.liveConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return app.Config
+}
+
+// OnConfigChange registers fn to be called with the new configuration each
+// time ApplyConfig changes it. Calling remove unregisters fn.
+func (app *Application) OnConfigChange(fn func(appconf.Config)) (remove func()) {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+	listener := &fn
+	app.configListeners = append(app.configListeners, listener)
+	return func() {
+		app.configMu.Lock()
+		defer app.configMu.Unlock()
+		app.configListeners = slices.DeleteFunc(app.configListeners, func(l *func(appconf.Config)) bool {
+			return l == listener
+		})
+	}
+}
+
+// ApplyConfig puts the settings of next that can change at runtime into
+// effect: API keys, rate limits, location search limits and suppressed stops
+// and routes. Other settings keep their startup values and only change on
+// restart; restartRequired reports whether next differs from the current
+// configuration in any of them.
+func (app *Application) ApplyConfig(next appconf.Config) (restartRequired bool) {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	current := app.CurrentConfig()
+	updated := current
+	updated.ApiKeys = next.ApiKeys
+	updated.ProtectedApiKeys = next.ProtectedApiKeys
+	updated.ExemptApiKeys = next.ExemptApiKeys
+	updated.ExemptIPRanges = next.ExemptIPRanges
+	updated.RateLimit = next.RateLimit
+	updated.Search = next.Search
+	updated.Suppression = next.Suppression
+
+	// next with the runtime settings copied over equals updated exactly when
+	// nothing else changed.
+	restartRequired = !reflect.DeepEqual(withRuntimeSettings(next, updated), updated)
+
+	app.liveConfig.Store(&updated)
+	for _, fn := range app.configListeners {
+		(*fn)(updated)
+	}
+	return restartRequired
+}
+
+// withRuntimeSettings returns cfg with the settings ApplyConfig changes at
+// runtime taken from from.
+func withRuntimeSettings(cfg, from appconf.Config) appconf.Config {
+	cfg.ApiKeys = from.ApiKeys
+	cfg.ProtectedApiKeys = from.ProtectedApiKeys
+	cfg.ExemptApiKeys = from.ExemptApiKeys
+	cfg.ExemptIPRanges = from.ExemptIPRanges
+	cfg.RateLimit = from.RateLimit
+	cfg.Search = from.Search
+	cfg.Suppression = from.Suppression
+	return cfg
+}
+
+// ReloadConfig re-reads the config file the application was started with and
+// applies its API keys, rate limits, search limits, suppressed stops and
+// routes, realtime feeds and realtime-disabled agencies without a restart. An
+// invalid file leaves the running configuration unchanged.
+func (app *Application) ReloadConfig() (restartRequired bool, err error) {
+	if app.ConfigPath == "" {
+		return false, ErrConfigNotReloadable
+	}
+	jsonConfig, err := appconf.LoadFromFile(app.ConfigPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to load config file: %w", err)
+	}
+	gtfsCfgData, err := jsonConfig.ToGtfsConfigData()
+	if err != nil {
+		return false, fmt.Errorf("failed to convert config: %w", err)
+	}
+
+	restartRequired = app.ApplyConfig(jsonConfig.ToAppConfig())
+	if app.GtfsManager != nil {
+		app.GtfsManager.UpdateRealtimeFeeds(RTFeedConfigs(gtfsCfgData.RTFeeds))
+		app.GtfsManager.SetRealtimeDisabledAgencies(gtfsCfgData.RealtimeDisabledAgencies)
+	}
+	return restartRequired, nil
+}
+
+// RTFeedConfigs converts validated realtime feed settings into the GTFS
+// manager's feed configs.
+func RTFeedConfigs(feeds []appconf.RTFeedConfigData) []gtfs.RTFeedConfig {
+	configs := make([]gtfs.RTFeedConfig, 0, len(feeds))
+	for _, feedData := range feeds {
+		configs = append(configs, gtfs.RTFeedConfig{
+			ID:                  feedData.ID,
+			AgencyIDs:           feedData.AgencyIDs,
+			TripUpdatesURL:      feedData.TripUpdatesURL,
+			VehiclePositionsURL: feedData.VehiclePositionsURL,
+			ServiceAlertsURL:    feedData.ServiceAlertsURL,
+			Headers:             feedData.Headers,
+			RefreshInterval:     feedData.RefreshInterval,
+			Enabled:             feedData.Enabled,
+		})
+	}
+	return configs
+}
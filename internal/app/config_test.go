@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+func TestApplyConfig_UpdatesRuntimeSettings(t *testing.T) {
+	app := &Application{Config: appconf.Config{Port: 4000, ApiKeys: []string{"old"}, RateLimit: 10}}
+
+	var notified []appconf.Config
+	app.OnConfigChange(func(cfg appconf.Config) { notified = append(notified, cfg) })
+
+	restartRequired := app.ApplyConfig(appconf.Config{Port: 4000, ApiKeys: []string{"new"}, RateLimit: 20})
+	assert.False(t, restartRequired)
+
+	assert.True(t, app.IsInvalidAPIKey("old"))
+	assert.False(t, app.IsInvalidAPIKey("new"))
+	assert.Equal(t, 20, app.CurrentConfig().RateLimit)
+	assert.Equal(t, []string{"old"}, app.Config.ApiKeys, "the startup configuration is kept")
+	require.Len(t, notified, 1)
+	assert.Equal(t, []string{"new"}, notified[0].ApiKeys)
+}
+
+func TestOnConfigChange_Remove(t *testing.T) {
+	app := &Application{Config: appconf.Config{Port: 4000, RateLimit: 10}}
+
+	var first, second int
+	remove := app.OnConfigChange(func(appconf.Config) { first++ })
+	app.OnConfigChange(func(appconf.Config) { second++ })
+
+	app.ApplyConfig(appconf.Config{Port: 4000, RateLimit: 20})
+	remove()
+	remove()
+	app.ApplyConfig(appconf.Config{Port: 4000, RateLimit: 30})
+
+	assert.Equal(t, 1, first, "removed listeners are not called")
+	assert.Equal(t, 2, second)
+}
+
+func TestApplyConfig_UpdatesSearchLimits(t *testing.T) {
+	app := &Application{Config: appconf.Config{Port: 4000, RateLimit: 10}}
+
+	search := appconf.SearchConfig{DefaultRadius: 5000}
+	restartRequired := app.ApplyConfig(appconf.Config{Port: 4000, RateLimit: 10, Search: search})
+	assert.False(t, restartRequired)
+	assert.Equal(t, search, app.CurrentConfig().Search)
+}
+
+func TestApplyConfig_UpdatesSuppression(t *testing.T) {
+	app := &Application{Config: appconf.Config{Port: 4000, RateLimit: 10}}
+
+	suppression := appconf.SuppressionConfig{StopIDs: []string{"1_75403"}}
+	restartRequired := app.ApplyConfig(appconf.Config{Port: 4000, RateLimit: 10, Suppression: suppression})
+	assert.False(t, restartRequired)
+	assert.Equal(t, suppression, app.CurrentConfig().Suppression)
+}
+
+func TestApplyConfig_ReportsSettingsThatNeedRestart(t *testing.T) {
+	app := &Application{Config: appconf.Config{Port: 4000, RateLimit: 10}}
+
+	restartRequired := app.ApplyConfig(appconf.Config{Port: 5000, RateLimit: 10})
+	assert.True(t, restartRequired)
+	assert.Equal(t, 4000, app.CurrentConfig().Port, "the port only changes on restart")
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Run("requires a config file", func(t *testing.T) {
+		app := &Application{}
+		_, err := app.ReloadConfig()
+		assert.ErrorIs(t, err, ErrConfigNotReloadable)
+	})
+
+	t.Run("applies the file's API keys and rate limit", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"port": 3000, "api-keys": ["reloaded"], "rate-limit": 7}`), 0o600))
+
+		jsonConfig, err := appconf.LoadFromFile(path)
+		require.NoError(t, err)
+		startup := jsonConfig.ToAppConfig()
+		startup.ApiKeys = []string{"original"}
+		app := &Application{Config: startup, ConfigPath: path}
+
+		restartRequired, err := app.ReloadConfig()
+		require.NoError(t, err)
+		assert.False(t, restartRequired)
+		assert.False(t, app.IsInvalidAPIKey("reloaded"))
+		assert.True(t, app.IsInvalidAPIKey("original"))
+		assert.Equal(t, 7, app.CurrentConfig().RateLimit)
+	})
+
+	t.Run("keeps the running configuration when the file is invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"port": `), 0o600))
+		app := &Application{Config: appconf.Config{ApiKeys: []string{"original"}}, ConfigPath: path}
+
+		_, err := app.ReloadConfig()
+		assert.Error(t, err)
+		assert.False(t, app.IsInvalidAPIKey("original"))
+	})
+}
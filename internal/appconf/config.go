@@ -1,5 +1,7 @@
 package appconf
 
+import "time"
+
 // Config holds all the configuration settings for our Application.
 // For now, the only configuration settings will be the network port that we want the
 // server to listen on, and the name of the current operating environment for the
@@ -11,11 +13,301 @@ type Config struct {
 	ApiKeys          []string
 	ProtectedApiKeys []string
 	ExemptApiKeys    []string
-	RateLimit        int // Requests per second across the entire service (global shared bucket; exempt keys bypass it)
+	ExemptIPRanges   []string // CIDR ranges or single addresses whose requests bypass rate limiting
+	RateLimit        int      // Requests per second across the entire service (global shared bucket; exempt keys bypass it)
+	RateLimitBackend RateLimitBackendConfig
 	LogLevel         string
 	LogFormat        string
 	TLSCertPath      string
 	TLSKeyPath       string
+	MetricsEnabled   bool          // Serve Prometheus metrics on /metrics
+	ShutdownTimeout  time.Duration // How long shutdown waits for in-flight requests and subsystems; zero uses DefaultShutdownTimeout
+	LoadShedding     LoadSheddingConfig
+	SLO              SLOConfig
+	Canary           CanaryConfig
+	SMS              SMSConfig
+	Portal           PortalConfig
+	Search           SearchConfig
+	Compression      CompressionConfig
+	Suppression      SuppressionConfig
+	Interpolation    InterpolationConfig
+	Regions          []RegionConfig
+	Jobs             map[string]JobConfig // Scheduled maintenance job name to overrides of its defaults
+}
+
+// DefaultShutdownTimeout is the drain deadline used when ShutdownTimeout is
+// unset.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// LoadSheddingConfig controls adaptive shedding of API requests under overload.
+// Shedding is disabled when both MaxInFlight and TargetP99 are zero.
+type LoadSheddingConfig struct {
+	MaxInFlight int               // In-flight API requests at which low-priority requests start being shed
+	TargetP99   time.Duration     // Recent p99 latency at which low-priority requests start being shed
+	Priorities  map[string]string // Endpoint name (e.g. "trips-for-location") to "low", "normal" or "critical"
+}
+
+// SLOConfig sets the service level objectives API requests are tracked
+// against. Zero fields fall back to the defaults below.
+type SLOConfig struct {
+	AvailabilityTarget float64       // Percentage of requests that must not fail with a 5xx
+	LatencyThreshold   time.Duration // Requests slower than this count against the latency objective
+	LatencyTarget      float64       // Percentage of requests that must finish within LatencyThreshold
+	Window             time.Duration // Rolling window the objectives are evaluated over
+}
+
+// Default service level objectives: 99.9% availability and p99 under 300ms
+// over a rolling 30 days.
+const (
+	DefaultSLOAvailabilityTarget = 99.9
+	DefaultSLOLatencyThreshold   = 300 * time.Millisecond
+	DefaultSLOLatencyTarget      = 99.0
+	DefaultSLOWindow             = 30 * 24 * time.Hour
+)
+
+// WithDefaults returns c with zero fields replaced by the default objectives.
+func (c SLOConfig) WithDefaults() SLOConfig {
+	if c.AvailabilityTarget == 0 {
+		c.AvailabilityTarget = DefaultSLOAvailabilityTarget
+	}
+	if c.LatencyThreshold == 0 {
+		c.LatencyThreshold = DefaultSLOLatencyThreshold
+	}
+	if c.LatencyTarget == 0 {
+		c.LatencyTarget = DefaultSLOLatencyTarget
+	}
+	if c.Window == 0 {
+		c.Window = DefaultSLOWindow
+	}
+	return c
+}
+
+// CanaryConfig enables the synthetic prober, which periodically calls the API
+// for a representative stop and route. It is disabled when both IDs are empty.
+type CanaryConfig struct {
+	Interval time.Duration // Time between probe runs; DefaultCanaryInterval when zero
+	StopID   string        // Combined stop ID probed with arrivals-and-departures-for-stop
+	RouteID  string        // Combined ID of a busy route probed with trips-for-route and trip-details
+}
+
+// DefaultCanaryInterval is how often the canary probes run unless configured.
+const DefaultCanaryInterval = time.Minute
+
+// Enabled reports whether any canary probe is configured.
+func (c CanaryConfig) Enabled() bool {
+	return c.StopID != "" || c.RouteID != ""
+}
+
+// Names of the scheduled maintenance jobs.
+const (
+	JobFeedReload         = "feed-reload"         // Reloads the static GTFS feed
+	JobArchivePruning     = "archive-pruning"     // Deletes old problem reports, expired portal signups and usage counts
+	JobOnTimePerformance  = "on-time-performance" // Recomputes trip start punctuality metrics
+	JobStatsRecomputation = "stats-recomputation" // Refreshes the database query planner statistics
+	JobAPIKeyUsageFlush   = "api-key-usage-flush" // Saves the buffered per-key request counts
+)
+
+// JobNames lists the scheduled maintenance jobs in the order they are reported.
+var JobNames = []string{JobFeedReload, JobArchivePruning, JobOnTimePerformance, JobStatsRecomputation, JobAPIKeyUsageFlush}
+
+// JobConfig overrides the schedule of a maintenance job. Zero fields keep the
+// job's defaults.
+type JobConfig struct {
+	Enabled   *bool
+	Interval  time.Duration
+	Jitter    time.Duration
+	Retention time.Duration // Age of rows archive-pruning deletes
+}
+
+// SMSConfig holds the message templates of the SMS gateway endpoint. Templates
+// use text/template syntax with the fields of DefaultSMSTemplate plus
+// .StopCode and .AgencyName.
+type SMSConfig struct {
+	Template        string            // Template for agencies without their own; DefaultSMSTemplate when empty
+	AgencyTemplates map[string]string // Agency ID to template
+}
+
+// DefaultSMSTemplate renders the stop name followed by its departures.
+const DefaultSMSTemplate = "{{.StopName}}: {{.Departures}}"
+
+// TemplateFor returns the template used for stops of agencyID.
+func (c SMSConfig) TemplateFor(agencyID string) string {
+	if t, ok := c.AgencyTemplates[agencyID]; ok && t != "" {
+		return t
+	}
+	if c.Template != "" {
+		return c.Template
+	}
+	return DefaultSMSTemplate
+}
+
+// PortalConfig controls the optional developer portal, which issues API keys
+// through self-service signup with email verification. Portal keys are
+// accepted alongside ApiKeys and are rate limited per key.
+type PortalConfig struct {
+	Enabled           bool
+	BaseURL           string        // Public URL of this server, used to build verification links
+	RateLimit         int           // Requests per second for each standard key; DefaultPortalRateLimit when zero
+	ElevatedRateLimit int           // Requests per second once an admin approves an elevated quota; DefaultPortalElevatedRateLimit when zero
+	VerificationTTL   time.Duration // How long verification links stay valid; DefaultPortalVerificationTTL when zero
+	MaxKeysPerEmail   int           // Active or pending keys allowed per address; DefaultPortalMaxKeysPerEmail when zero
+	SMTP              SMTPConfig
+}
+
+// SMTPConfig is the mail server verification emails are sent through. When
+// Host is empty, messages are logged instead of sent, which is only suitable
+// for development.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Developer portal defaults.
+const (
+	DefaultPortalRateLimit         = 5
+	DefaultPortalElevatedRateLimit = 50
+	DefaultPortalVerificationTTL   = 24 * time.Hour
+	DefaultPortalMaxKeysPerEmail   = 3
+)
+
+// WithDefaults returns c with zero limits replaced by the defaults above.
+func (c PortalConfig) WithDefaults() PortalConfig {
+	if c.RateLimit == 0 {
+		c.RateLimit = DefaultPortalRateLimit
+	}
+	if c.ElevatedRateLimit == 0 {
+		c.ElevatedRateLimit = DefaultPortalElevatedRateLimit
+	}
+	if c.VerificationTTL == 0 {
+		c.VerificationTTL = DefaultPortalVerificationTTL
+	}
+	if c.MaxKeysPerEmail == 0 {
+		c.MaxKeysPerEmail = DefaultPortalMaxKeysPerEmail
+	}
+	return c
+}
+
+// SearchConfig sets the default radius and result counts of location searches
+// such as stops-for-location and routes-for-location. Zero fields fall back to
+// the defaults below.
+type SearchConfig struct {
+	DefaultRadius         float64 // Meters searched around lat/lon when neither radius nor spans are given
+	QueryRadius           float64 // Meters searched by routes-for-location when a text query is given without a radius
+	MaxRadius             float64 // Larger radii are clamped to this many meters
+	DefaultMaxCountStops  int     // Stops returned when maxCount is not given
+	DefaultMaxCountRoutes int     // Routes returned when maxCount is not given
+	MaxCount              int     // Largest maxCount a request may ask for
+}
+
+// Default location search limits.
+const (
+	DefaultSearchRadius          = 600
+	DefaultQuerySearchRadius     = 10000
+	DefaultMaxSearchRadius       = 20000
+	DefaultSearchMaxCountStops   = 100
+	DefaultSearchMaxCountRoutes  = 50
+	DefaultSearchMaxAllowedCount = 250
+)
+
+// WithDefaults returns c with zero fields replaced by the defaults above.
+func (c SearchConfig) WithDefaults() SearchConfig {
+	if c.DefaultRadius == 0 {
+		c.DefaultRadius = DefaultSearchRadius
+	}
+	if c.QueryRadius == 0 {
+		c.QueryRadius = DefaultQuerySearchRadius
+	}
+	if c.MaxRadius == 0 {
+		c.MaxRadius = DefaultMaxSearchRadius
+	}
+	if c.DefaultMaxCountStops == 0 {
+		c.DefaultMaxCountStops = DefaultSearchMaxCountStops
+	}
+	if c.DefaultMaxCountRoutes == 0 {
+		c.DefaultMaxCountRoutes = DefaultSearchMaxCountRoutes
+	}
+	if c.MaxCount == 0 {
+		c.MaxCount = DefaultSearchMaxAllowedCount
+	}
+	return c
+}
+
+// CompressionConfig tunes the compression of API responses. Zero fields fall
+// back to the defaults below.
+type CompressionConfig struct {
+	MinSize int // Responses smaller than this many bytes are sent uncompressed
+	Level   int // Compression level from 1 (fastest) to 9 (smallest)
+}
+
+// Default response compression settings: balanced speed and size, skipping
+// responses too small to benefit.
+const (
+	DefaultCompressionMinSize = 1024
+	DefaultCompressionLevel   = 6
+)
+
+// WithDefaults returns c with zero fields replaced by the defaults above.
+func (c CompressionConfig) WithDefaults() CompressionConfig {
+	if c.MinSize == 0 {
+		c.MinSize = DefaultCompressionMinSize
+	}
+	if c.Level == 0 {
+		c.Level = DefaultCompressionLevel
+	}
+	return c
+}
+
+// InterpolationConfig controls advancing vehicle positions along their trip's
+// shape between realtime updates, for feeds that report every 30 to 60
+// seconds. It is off unless Enabled is set.
+type InterpolationConfig struct {
+	Enabled    bool
+	MaxElapsed time.Duration // A position is advanced for at most this long after its update; zero uses the default below
+}
+
+// DefaultInterpolationMaxElapsed stops advancing a vehicle two minutes after
+// its last update, so a vehicle whose feed stalls does not run on unseen.
+const DefaultInterpolationMaxElapsed = 2 * time.Minute
+
+// WithDefaults returns c with a zero MaxElapsed replaced by the default.
+func (c InterpolationConfig) WithDefaults() InterpolationConfig {
+	if c.MaxElapsed == 0 {
+		c.MaxElapsed = DefaultInterpolationMaxElapsed
+	}
+	return c
+}
+
+// SuppressionConfig lists stops and routes hidden from public API responses
+// while their data stays in the database, e.g. a stop that is temporarily
+// closed before the agency can update its feed. IDs are combined IDs as
+// returned by the API, such as "1_75403".
+type SuppressionConfig struct {
+	StopIDs  []string
+	RouteIDs []string
+}
+
+// RegionConfig names a group of the loaded agencies that requests can be
+// routed to with an X-Region header or a /regions/{id} path prefix. Requests
+// routed to a region are refused IDs of agencies outside it.
+type RegionConfig struct {
+	ID        string
+	AgencyIDs []string
+}
+
+// Rate limit backends: where the global RateLimit bucket is kept.
+const (
+	RateLimitBackendMemory = "memory" // In this process; each replica enforces the limit on its own
+	RateLimitBackendRedis  = "redis"  // In Redis; every replica sharing the server and key shares the limit
+)
+
+// RateLimitBackendConfig selects where the global rate limit bucket is kept.
+type RateLimitBackendConfig struct {
+	Type     string // RateLimitBackendMemory (the default when empty) or RateLimitBackendRedis
+	RedisURL string // redis:// or rediss:// URL of the server, for the Redis backend
+	RedisKey string // Key the bucket is stored under; replicas sharing it share the limit
 }
 
 // Environment is an enumerated type representing various stages or configurations in the system's lifecycle.
@@ -0,0 +1,32 @@
+package appconf
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"10.20.0.0/16", "10.20.0.0/16"},
+		{"10.20.30.40/16", "10.20.0.0/16"},
+		{" 192.168.1.7 ", "192.168.1.7/32"},
+		{"::ffff:192.168.1.7", "192.168.1.7/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			prefix, err := ParseIPRange(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, netip.MustParsePrefix(tt.expected), prefix)
+		})
+	}
+
+	_, err := ParseIPRange("not-an-ip")
+	assert.Error(t, err)
+}
@@ -4,9 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/mail"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 )
 
 // GtfsStaticFeed represents the static GTFS feed configuration
@@ -31,21 +36,151 @@ type GtfsRtFeed struct {
 	Enabled                 *bool             `json:"enabled"`
 }
 
+// MinRealtimeRefreshInterval is the shortest refresh-interval, in seconds, a
+// realtime feed may be polled at. Faster polling mostly re-downloads the same
+// snapshot and risks being throttled by the provider.
+const MinRealtimeRefreshInterval = 5
+
+// LoadShedding represents the load shedding configuration
+type LoadShedding struct {
+	MaxInFlight int               `json:"max-in-flight"`
+	TargetP99Ms int               `json:"target-p99-ms"`
+	Priorities  map[string]string `json:"priorities"`
+}
+
+// SLO represents the service level objective configuration
+type SLO struct {
+	AvailabilityTargetPercent float64 `json:"availability-target-percent"`
+	LatencyThresholdMs        int     `json:"latency-threshold-ms"`
+	LatencyTargetPercent      float64 `json:"latency-target-percent"`
+	WindowDays                int     `json:"window-days"`
+}
+
+// Canary represents the synthetic prober configuration
+type Canary struct {
+	IntervalSeconds int    `json:"interval-seconds"`
+	StopID          string `json:"stop-id"`
+	RouteID         string `json:"route-id"`
+}
+
+// SMS represents the SMS gateway endpoint configuration
+type SMS struct {
+	Template        string            `json:"template"`
+	AgencyTemplates map[string]string `json:"agency-templates"`
+}
+
+// Portal represents the developer portal configuration
+type Portal struct {
+	Enabled              bool   `json:"enabled"`
+	BaseURL              string `json:"base-url"`
+	RateLimit            int    `json:"rate-limit"`
+	ElevatedRateLimit    int    `json:"elevated-rate-limit"`
+	VerificationTTLHours int    `json:"verification-ttl-hours"`
+	MaxKeysPerEmail      int    `json:"max-keys-per-email"`
+	SMTP                 SMTP   `json:"smtp"`
+}
+
+// SMTP represents the mail server used by the developer portal
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// Search represents the location search defaults and limits
+type Search struct {
+	DefaultRadiusMeters   float64 `json:"default-radius-meters"`
+	QueryRadiusMeters     float64 `json:"query-radius-meters"`
+	MaxRadiusMeters       float64 `json:"max-radius-meters"`
+	DefaultMaxCountStops  int     `json:"default-max-count-stops"`
+	DefaultMaxCountRoutes int     `json:"default-max-count-routes"`
+	MaxCount              int     `json:"max-count"`
+}
+
+// Compression represents the response compression settings
+type Compression struct {
+	MinSizeBytes int `json:"min-size-bytes"`
+	Level        int `json:"level"`
+}
+
+// Interpolation represents the vehicle position interpolation settings
+type Interpolation struct {
+	Enabled           bool `json:"enabled"`
+	MaxElapsedSeconds int  `json:"max-elapsed-seconds"`
+}
+
+// Suppression represents the stops and routes hidden from public responses
+type Suppression struct {
+	StopIDs  []string `json:"stop-ids"`
+	RouteIDs []string `json:"route-ids"`
+}
+
+// Region represents a group of agencies requests can be routed to
+type Region struct {
+	ID        string   `json:"id"`
+	AgencyIDs []string `json:"agency-ids"`
+}
+
+// RateLimitBackend represents where the global rate limit bucket is kept
+type RateLimitBackend struct {
+	Type     string `json:"type"`
+	RedisURL string `json:"redis-url"`
+	RedisKey string `json:"redis-key"`
+}
+
+// ScheduledJob represents the schedule overrides of one maintenance job
+type ScheduledJob struct {
+	Enabled         *bool `json:"enabled"`
+	IntervalSeconds int   `json:"interval-seconds"`
+	JitterSeconds   int   `json:"jitter-seconds"`
+	RetentionDays   int   `json:"retention-days"`
+}
+
 // JSONConfig represents the JSON configuration file structure
 type JSONConfig struct {
-	Port             int            `json:"port"`
-	Env              string         `json:"env"`
-	ApiKeys          []string       `json:"api-keys"`
-	ProtectedApiKeys []string       `json:"protected-api-keys"`
-	ExemptApiKeys    []string       `json:"exempt-api-keys"`
-	RateLimit        int            `json:"rate-limit"`
-	GtfsStaticFeed   GtfsStaticFeed `json:"gtfs-static-feed"`
-	GtfsRtFeeds      []GtfsRtFeed   `json:"gtfs-rt-feeds"`
-	DataPath         string         `json:"data-path"`
-	LogLevel         string         `json:"log-level"`
-	LogFormat        string         `json:"log-format"`
-	TLSCertPath      string         `json:"tls-cert-path"`
-	TLSKeyPath       string         `json:"tls-key-path"`
+	Port                      int                     `json:"port"`
+	Env                       string                  `json:"env"`
+	ApiKeys                   []string                `json:"api-keys"`
+	ProtectedApiKeys          []string                `json:"protected-api-keys"`
+	ExemptApiKeys             []string                `json:"exempt-api-keys"`
+	ExemptIPRanges            []string                `json:"exempt-ip-ranges"`
+	RateLimit                 int                     `json:"rate-limit"`
+	RateLimitBackend          RateLimitBackend        `json:"rate-limit-backend"`
+	GtfsStaticFeed            GtfsStaticFeed          `json:"gtfs-static-feed"`
+	AdditionalGtfsStaticFeeds []GtfsStaticFeed        `json:"additional-gtfs-static-feeds"`
+	GtfsRtFeeds               []GtfsRtFeed            `json:"gtfs-rt-feeds"`
+	RealtimeDisabledAgencies  []string                `json:"realtime-disabled-agencies"`
+	DataPath                  string                  `json:"data-path"`
+	DataEncryptionKey         string                  `json:"data-encryption-key"`
+	MirrorDir                 string                  `json:"mirror-dir"`
+	AmenitiesPath             string                  `json:"amenities-path"`
+	PostImportProcessors      []string                `json:"post-import-processors"`
+	SlowQueryThresholdMs      int                     `json:"slow-query-threshold-ms"`
+	ReloadGuardMaxDropPercent float64                 `json:"reload-guard-max-drop-percent"`
+	FeedExpiryWarningDays     int                     `json:"feed-expiry-warning-days"`
+	TransferRadiusMeters      float64                 `json:"transfer-radius-meters"`
+	ArrivalHistoryDays        int                     `json:"arrival-history-days"`
+	DatasetSnapshots          int                     `json:"dataset-snapshots"`
+	DeviationSampleSeconds    int                     `json:"deviation-sample-interval-seconds"`
+	LogLevel                  string                  `json:"log-level"`
+	LogFormat                 string                  `json:"log-format"`
+	TLSCertPath               string                  `json:"tls-cert-path"`
+	TLSKeyPath                string                  `json:"tls-key-path"`
+	MetricsEnabled            *bool                   `json:"metrics-enabled"`
+	ShutdownTimeoutSeconds    int                     `json:"shutdown-timeout-seconds"`
+	LoadShedding              LoadShedding            `json:"load-shedding"`
+	SLO                       SLO                     `json:"slo"`
+	Canary                    Canary                  `json:"canary"`
+	SMS                       SMS                     `json:"sms"`
+	Portal                    Portal                  `json:"developer-portal"`
+	Search                    Search                  `json:"search"`
+	Compression               Compression             `json:"compression"`
+	Suppression               Suppression             `json:"suppression"`
+	Interpolation             Interpolation           `json:"vehicle-interpolation"`
+	Regions                   []Region                `json:"regions"`
+	ScheduledJobs             map[string]ScheduledJob `json:"scheduled-jobs"`
 }
 
 // setDefaults applies default values to the JSON config if fields are missing or zero
@@ -141,6 +276,12 @@ func (j *JSONConfig) Validate() error {
 		seenProtected[key] = true
 	}
 
+	for _, ipRange := range j.ExemptIPRanges {
+		if _, err := ParseIPRange(ipRange); err != nil {
+			return fmt.Errorf("exempt-ip-ranges: %w", err)
+		}
+	}
+
 	validLogLevels := map[string]bool{
 		"debug": true,
 		"info":  true,
@@ -164,6 +305,127 @@ func (j *JSONConfig) Validate() error {
 		return err
 	}
 
+	if err := validatePath(j.MirrorDir, "mirror-dir"); err != nil {
+		return err
+	}
+
+	if err := validatePath(j.AmenitiesPath, "amenities-path"); err != nil {
+		return err
+	}
+
+	for i, feed := range j.GtfsRtFeeds {
+		// Zero uses the default interval.
+		if feed.RefreshInterval < 0 || (feed.RefreshInterval > 0 && feed.RefreshInterval < MinRealtimeRefreshInterval) {
+			return fmt.Errorf("gtfs-rt-feeds[%d].refresh-interval must be at least %d seconds, got %d", i, MinRealtimeRefreshInterval, feed.RefreshInterval)
+		}
+	}
+
+	staticURLs := map[string]bool{j.GtfsStaticFeed.URL: true}
+	for i, feed := range j.AdditionalGtfsStaticFeeds {
+		if strings.TrimSpace(feed.URL) == "" {
+			return fmt.Errorf("additional-gtfs-static-feeds[%d].url cannot be empty", i)
+		}
+		if staticURLs[feed.URL] {
+			return fmt.Errorf("static GTFS feed %q is configured more than once", feed.URL)
+		}
+		staticURLs[feed.URL] = true
+	}
+
+	for _, id := range j.RealtimeDisabledAgencies {
+		if strings.TrimSpace(id) == "" {
+			return fmt.Errorf("realtime-disabled-agencies cannot contain empty agency IDs")
+		}
+	}
+
+	// Names are resolved against the processor registry when the GTFS manager starts.
+	for _, name := range j.PostImportProcessors {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("post-import-processors cannot contain empty names")
+		}
+	}
+
+	if j.SlowQueryThresholdMs < 0 {
+		return fmt.Errorf("slow-query-threshold-ms cannot be negative, got %d", j.SlowQueryThresholdMs)
+	}
+
+	if j.ReloadGuardMaxDropPercent < 0 || j.ReloadGuardMaxDropPercent >= 100 {
+		return fmt.Errorf("reload-guard-max-drop-percent must be at least 0 and below 100, got %g", j.ReloadGuardMaxDropPercent)
+	}
+
+	if j.FeedExpiryWarningDays < 0 {
+		return fmt.Errorf("feed-expiry-warning-days cannot be negative, got %d", j.FeedExpiryWarningDays)
+	}
+
+	if j.TransferRadiusMeters < 0 {
+		return fmt.Errorf("transfer-radius-meters cannot be negative, got %g", j.TransferRadiusMeters)
+	}
+
+	if j.ArrivalHistoryDays < 0 {
+		return fmt.Errorf("arrival-history-days cannot be negative, got %d", j.ArrivalHistoryDays)
+	}
+
+	if j.DatasetSnapshots < 0 {
+		return fmt.Errorf("dataset-snapshots cannot be negative, got %d", j.DatasetSnapshots)
+	}
+
+	if j.DeviationSampleSeconds < 0 {
+		return fmt.Errorf("deviation-sample-interval-seconds cannot be negative, got %d", j.DeviationSampleSeconds)
+	}
+
+	if err := j.LoadShedding.validate(); err != nil {
+		return err
+	}
+
+	if err := j.SLO.validate(); err != nil {
+		return err
+	}
+
+	if j.Canary.IntervalSeconds < 0 {
+		return fmt.Errorf("canary.interval-seconds cannot be negative, got %d", j.Canary.IntervalSeconds)
+	}
+
+	if err := j.SMS.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Portal.validate(j.Env); err != nil {
+		return err
+	}
+
+	if err := j.Search.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Compression.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Suppression.validate(); err != nil {
+		return err
+	}
+
+	if err := j.Interpolation.validate(); err != nil {
+		return err
+	}
+
+	if err := validateRegions(j.Regions); err != nil {
+		return err
+	}
+
+	if err := j.RateLimitBackend.validate(); err != nil {
+		return err
+	}
+
+	for name, job := range j.ScheduledJobs {
+		if err := job.validate(name); err != nil {
+			return err
+		}
+	}
+
+	if j.ShutdownTimeoutSeconds < 0 {
+		return fmt.Errorf("shutdown-timeout-seconds cannot be negative, got %d", j.ShutdownTimeoutSeconds)
+	}
+
 	// TLS: both cert and key must be provided together
 	if (j.TLSCertPath != "" && j.TLSKeyPath == "") || (j.TLSCertPath == "" && j.TLSKeyPath != "") {
 		return fmt.Errorf("both tls-cert-path and tls-key-path must be provided together")
@@ -175,27 +437,11 @@ func (j *JSONConfig) Validate() error {
 		return err
 	}
 
-	// Validate that both auth header fields are provided together or neither
-	if (j.GtfsStaticFeed.AuthHeaderName != "" && j.GtfsStaticFeed.AuthHeaderValue == "") ||
-		(j.GtfsStaticFeed.AuthHeaderName == "" && j.GtfsStaticFeed.AuthHeaderValue != "") {
-		return fmt.Errorf("both auth-header-name and auth-header-value must be provided together for gtfs-static-feed")
+	if err := j.GtfsStaticFeed.validate("gtfs-static-feed"); err != nil {
+		return err
 	}
-
-	// Validate GtfsStaticFeed.URL to prevent file:// URLs and other security issues
-	if j.GtfsStaticFeed.URL != "" {
-		// Block file:// URLs (case-insensitive)
-		if strings.HasPrefix(strings.ToLower(j.GtfsStaticFeed.URL), "file://") {
-			return fmt.Errorf("file:// URLs are not allowed for gtfs-static-feed.url for security reasons")
-		}
-
-		// For HTTP(S) URLs, no path checks needed
-		if strings.HasPrefix(j.GtfsStaticFeed.URL, "http://") ||
-			strings.HasPrefix(j.GtfsStaticFeed.URL, "https://") {
-			return nil
-		}
-
-		// For file paths, validate for path traversal
-		if err := validatePath(j.GtfsStaticFeed.URL, "gtfs-static-feed.url"); err != nil {
+	for i, feed := range j.AdditionalGtfsStaticFeeds {
+		if err := feed.validate(fmt.Sprintf("additional-gtfs-static-feeds[%d]", i)); err != nil {
 			return err
 		}
 	}
@@ -203,6 +449,30 @@ func (j *JSONConfig) Validate() error {
 	return nil
 }
 
+// validate checks a static feed's auth headers and blocks file:// URLs and
+// path traversal in local feed paths.
+func (f GtfsStaticFeed) validate(field string) error {
+	// Validate that both auth header fields are provided together or neither
+	if (f.AuthHeaderName != "" && f.AuthHeaderValue == "") ||
+		(f.AuthHeaderName == "" && f.AuthHeaderValue != "") {
+		return fmt.Errorf("both auth-header-name and auth-header-value must be provided together for %s", field)
+	}
+
+	if f.URL == "" {
+		return nil
+	}
+	// Block file:// URLs (case-insensitive)
+	if strings.HasPrefix(strings.ToLower(f.URL), "file://") {
+		return fmt.Errorf("file:// URLs are not allowed for %s.url for security reasons", field)
+	}
+	// For HTTP(S) URLs, no path checks needed
+	if strings.HasPrefix(f.URL, "http://") || strings.HasPrefix(f.URL, "https://") {
+		return nil
+	}
+	// For file paths, validate for path traversal
+	return validatePath(f.URL, field+".url")
+}
+
 // validatePath checks a file path for security issues
 func validatePath(path, fieldName string) error {
 	if path == "" {
@@ -239,14 +509,333 @@ func (j *JSONConfig) ToAppConfig() Config {
 		ApiKeys:          j.ApiKeys,
 		ProtectedApiKeys: j.ProtectedApiKeys,
 		ExemptApiKeys:    j.ExemptApiKeys,
+		ExemptIPRanges:   j.ExemptIPRanges,
 		RateLimit:        j.RateLimit,
 		LogLevel:         j.LogLevel,
 		LogFormat:        j.LogFormat,
 		TLSCertPath:      j.TLSCertPath,
 		TLSKeyPath:       j.TLSKeyPath,
+		MetricsEnabled:   j.MetricsEnabled == nil || *j.MetricsEnabled,
+		ShutdownTimeout:  time.Duration(j.ShutdownTimeoutSeconds) * time.Second,
+		LoadShedding: LoadSheddingConfig{
+			MaxInFlight: j.LoadShedding.MaxInFlight,
+			TargetP99:   time.Duration(j.LoadShedding.TargetP99Ms) * time.Millisecond,
+			Priorities:  j.LoadShedding.Priorities,
+		},
+		SLO: SLOConfig{
+			AvailabilityTarget: j.SLO.AvailabilityTargetPercent,
+			LatencyThreshold:   time.Duration(j.SLO.LatencyThresholdMs) * time.Millisecond,
+			LatencyTarget:      j.SLO.LatencyTargetPercent,
+			Window:             time.Duration(j.SLO.WindowDays) * 24 * time.Hour,
+		},
+		Canary: CanaryConfig{
+			Interval: time.Duration(j.Canary.IntervalSeconds) * time.Second,
+			StopID:   j.Canary.StopID,
+			RouteID:  j.Canary.RouteID,
+		},
+		SMS: SMSConfig{
+			Template:        j.SMS.Template,
+			AgencyTemplates: j.SMS.AgencyTemplates,
+		},
+		Portal: PortalConfig{
+			Enabled:           j.Portal.Enabled,
+			BaseURL:           strings.TrimSuffix(j.Portal.BaseURL, "/"),
+			RateLimit:         j.Portal.RateLimit,
+			ElevatedRateLimit: j.Portal.ElevatedRateLimit,
+			VerificationTTL:   time.Duration(j.Portal.VerificationTTLHours) * time.Hour,
+			MaxKeysPerEmail:   j.Portal.MaxKeysPerEmail,
+			SMTP: SMTPConfig{
+				Host:     j.Portal.SMTP.Host,
+				Port:     j.Portal.SMTP.Port,
+				Username: j.Portal.SMTP.Username,
+				Password: j.Portal.SMTP.Password,
+				From:     j.Portal.SMTP.From,
+			},
+		},
+		Search: j.Search.toSearchConfig(),
+		Compression: CompressionConfig{
+			MinSize: j.Compression.MinSizeBytes,
+			Level:   j.Compression.Level,
+		},
+		Suppression: SuppressionConfig{
+			StopIDs:  j.Suppression.StopIDs,
+			RouteIDs: j.Suppression.RouteIDs,
+		},
+		Interpolation: InterpolationConfig{
+			Enabled:    j.Interpolation.Enabled,
+			MaxElapsed: time.Duration(j.Interpolation.MaxElapsedSeconds) * time.Second,
+		},
+		Regions: j.regionConfigs(),
+		RateLimitBackend: RateLimitBackendConfig{
+			Type:     j.RateLimitBackend.Type,
+			RedisURL: j.RateLimitBackend.RedisURL,
+			RedisKey: j.RateLimitBackend.RedisKey,
+		},
+		Jobs: j.jobConfigs(),
+	}
+}
+
+// jobConfigs converts the scheduled job overrides; nil when there are none.
+func (j *JSONConfig) jobConfigs() map[string]JobConfig {
+	if len(j.ScheduledJobs) == 0 {
+		return nil
+	}
+	jobs := make(map[string]JobConfig, len(j.ScheduledJobs))
+	for name, job := range j.ScheduledJobs {
+		jobs[name] = JobConfig{
+			Enabled:   job.Enabled,
+			Interval:  time.Duration(job.IntervalSeconds) * time.Second,
+			Jitter:    time.Duration(job.JitterSeconds) * time.Second,
+			Retention: time.Duration(job.RetentionDays) * 24 * time.Hour,
+		}
+	}
+	return jobs
+}
+
+func (l LoadShedding) validate() error {
+	if l.MaxInFlight < 0 {
+		return fmt.Errorf("load-shedding.max-in-flight cannot be negative, got %d", l.MaxInFlight)
+	}
+	if l.TargetP99Ms < 0 {
+		return fmt.Errorf("load-shedding.target-p99-ms cannot be negative, got %d", l.TargetP99Ms)
+	}
+	for endpoint, priority := range l.Priorities {
+		switch priority {
+		case "low", "normal", "critical":
+		default:
+			return fmt.Errorf("load-shedding.priorities[%q] must be one of [low, normal, critical], got %q", endpoint, priority)
+		}
+	}
+	return nil
+}
+
+func (s SLO) validate() error {
+	if s.AvailabilityTargetPercent < 0 || s.AvailabilityTargetPercent >= 100 {
+		return fmt.Errorf("slo.availability-target-percent must be at least 0 and below 100, got %g", s.AvailabilityTargetPercent)
+	}
+	if s.LatencyTargetPercent < 0 || s.LatencyTargetPercent >= 100 {
+		return fmt.Errorf("slo.latency-target-percent must be at least 0 and below 100, got %g", s.LatencyTargetPercent)
+	}
+	if s.LatencyThresholdMs < 0 {
+		return fmt.Errorf("slo.latency-threshold-ms cannot be negative, got %d", s.LatencyThresholdMs)
+	}
+	if s.WindowDays < 0 {
+		return fmt.Errorf("slo.window-days cannot be negative, got %d", s.WindowDays)
+	}
+	return nil
+}
+
+func (c Compression) validate() error {
+	if c.MinSizeBytes < 0 {
+		return fmt.Errorf("compression.min-size-bytes cannot be negative, got %d", c.MinSizeBytes)
+	}
+	// Zero uses the default level.
+	if c.Level < 0 || c.Level > 9 {
+		return fmt.Errorf("compression.level must be between 1 and 9, got %d", c.Level)
+	}
+	return nil
+}
+
+func (i Interpolation) validate() error {
+	if i.MaxElapsedSeconds < 0 {
+		return fmt.Errorf("vehicle-interpolation.max-elapsed-seconds cannot be negative, got %d", i.MaxElapsedSeconds)
+	}
+	return nil
+}
+
+func (s Suppression) validate() error {
+	for _, id := range s.StopIDs {
+		if strings.TrimSpace(id) == "" {
+			return fmt.Errorf("suppression.stop-ids cannot contain empty IDs")
+		}
+	}
+	for _, id := range s.RouteIDs {
+		if strings.TrimSpace(id) == "" {
+			return fmt.Errorf("suppression.route-ids cannot contain empty IDs")
+		}
+	}
+	return nil
+}
+
+// validateRegions checks that region IDs are unique and usable as a path
+// segment, and that each agency belongs to at most one region.
+func validateRegions(regions []Region) error {
+	ids := make(map[string]bool, len(regions))
+	agencies := make(map[string]string)
+	for i, region := range regions {
+		if region.ID == "" || strings.ContainsAny(region.ID, "/ ") {
+			return fmt.Errorf("regions[%d].id must be a non-empty ID without slashes or spaces, got %q", i, region.ID)
+		}
+		if ids[region.ID] {
+			return fmt.Errorf("regions[%d].id %q is used by another region", i, region.ID)
+		}
+		ids[region.ID] = true
+		if len(region.AgencyIDs) == 0 {
+			return fmt.Errorf("region %q must list at least one agency in agency-ids", region.ID)
+		}
+		for _, agencyID := range region.AgencyIDs {
+			if strings.TrimSpace(agencyID) == "" {
+				return fmt.Errorf("region %q agency-ids cannot contain empty IDs", region.ID)
+			}
+			if other, ok := agencies[agencyID]; ok {
+				return fmt.Errorf("agency %q is in both region %q and region %q", agencyID, other, region.ID)
+			}
+			agencies[agencyID] = region.ID
+		}
+	}
+	return nil
+}
+
+func (j *JSONConfig) regionConfigs() []RegionConfig {
+	var regions []RegionConfig
+	for _, region := range j.Regions {
+		regions = append(regions, RegionConfig{ID: region.ID, AgencyIDs: region.AgencyIDs})
+	}
+	return regions
+}
+
+func (s Search) toSearchConfig() SearchConfig {
+	return SearchConfig{
+		DefaultRadius:         s.DefaultRadiusMeters,
+		QueryRadius:           s.QueryRadiusMeters,
+		MaxRadius:             s.MaxRadiusMeters,
+		DefaultMaxCountStops:  s.DefaultMaxCountStops,
+		DefaultMaxCountRoutes: s.DefaultMaxCountRoutes,
+		MaxCount:              s.MaxCount,
+	}
+}
+
+// validate checks the search limits, including that the default radii and
+// counts fit within the maximums once unset fields take their defaults.
+func (s Search) validate() error {
+	if s.DefaultRadiusMeters < 0 {
+		return fmt.Errorf("search.default-radius-meters cannot be negative, got %g", s.DefaultRadiusMeters)
+	}
+	if s.QueryRadiusMeters < 0 {
+		return fmt.Errorf("search.query-radius-meters cannot be negative, got %g", s.QueryRadiusMeters)
+	}
+	if s.MaxRadiusMeters < 0 {
+		return fmt.Errorf("search.max-radius-meters cannot be negative, got %g", s.MaxRadiusMeters)
+	}
+	if s.DefaultMaxCountStops < 0 {
+		return fmt.Errorf("search.default-max-count-stops cannot be negative, got %d", s.DefaultMaxCountStops)
+	}
+	if s.DefaultMaxCountRoutes < 0 {
+		return fmt.Errorf("search.default-max-count-routes cannot be negative, got %d", s.DefaultMaxCountRoutes)
+	}
+	if s.MaxCount < 0 {
+		return fmt.Errorf("search.max-count cannot be negative, got %d", s.MaxCount)
+	}
+
+	cfg := s.toSearchConfig().WithDefaults()
+	if cfg.DefaultRadius > cfg.MaxRadius {
+		return fmt.Errorf("search.default-radius-meters (%g) cannot exceed search.max-radius-meters (%g)", cfg.DefaultRadius, cfg.MaxRadius)
+	}
+	if cfg.QueryRadius > cfg.MaxRadius {
+		return fmt.Errorf("search.query-radius-meters (%g) cannot exceed search.max-radius-meters (%g)", cfg.QueryRadius, cfg.MaxRadius)
+	}
+	if cfg.DefaultMaxCountStops > cfg.MaxCount {
+		return fmt.Errorf("search.default-max-count-stops (%d) cannot exceed search.max-count (%d)", cfg.DefaultMaxCountStops, cfg.MaxCount)
+	}
+	if cfg.DefaultMaxCountRoutes > cfg.MaxCount {
+		return fmt.Errorf("search.default-max-count-routes (%d) cannot exceed search.max-count (%d)", cfg.DefaultMaxCountRoutes, cfg.MaxCount)
+	}
+	return nil
+}
+
+// validate checks the backend type and, for Redis, that a usable URL is set.
+func (b RateLimitBackend) validate() error {
+	switch b.Type {
+	case "", RateLimitBackendMemory:
+		return nil
+	case RateLimitBackendRedis:
+		if b.RedisURL == "" {
+			return fmt.Errorf("rate-limit-backend.redis-url is required when rate-limit-backend.type is redis")
+		}
+		u, err := url.Parse(b.RedisURL)
+		if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Hostname() == "" {
+			return fmt.Errorf("rate-limit-backend.redis-url must be a redis:// or rediss:// URL with a host")
+		}
+		return nil
+	default:
+		return fmt.Errorf("rate-limit-backend.type must be one of [memory, redis], got %q", b.Type)
 	}
 }
 
+func (s ScheduledJob) validate(name string) error {
+	if !slices.Contains(JobNames, name) {
+		return fmt.Errorf("scheduled-jobs: unknown job %q, must be one of [%s]", name, strings.Join(JobNames, ", "))
+	}
+	if s.IntervalSeconds < 0 {
+		return fmt.Errorf("scheduled-jobs[%q].interval-seconds cannot be negative, got %d", name, s.IntervalSeconds)
+	}
+	if s.JitterSeconds < 0 {
+		return fmt.Errorf("scheduled-jobs[%q].jitter-seconds cannot be negative, got %d", name, s.JitterSeconds)
+	}
+	if s.RetentionDays < 0 {
+		return fmt.Errorf("scheduled-jobs[%q].retention-days cannot be negative, got %d", name, s.RetentionDays)
+	}
+	if s.RetentionDays > 0 && name != JobArchivePruning {
+		return fmt.Errorf("scheduled-jobs[%q].retention-days only applies to %s", name, JobArchivePruning)
+	}
+	return nil
+}
+
+func (s SMS) validate() error {
+	if s.Template != "" {
+		if _, err := template.New("sms").Parse(s.Template); err != nil {
+			return fmt.Errorf("sms.template is not a valid template: %w", err)
+		}
+	}
+	for agencyID, t := range s.AgencyTemplates {
+		if _, err := template.New("sms").Parse(t); err != nil {
+			return fmt.Errorf("sms.agency-templates[%q] is not a valid template: %w", agencyID, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the developer portal settings. A portal that is not enabled
+// is not checked. Production deployments must configure SMTP, since the
+// development fallback only logs verification links.
+func (p Portal) validate(env string) error {
+	if !p.Enabled {
+		return nil
+	}
+	u, err := url.Parse(p.BaseURL)
+	if p.BaseURL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("developer-portal.base-url must be an absolute http or https URL when the portal is enabled")
+	}
+	if p.RateLimit < 0 {
+		return fmt.Errorf("developer-portal.rate-limit cannot be negative, got %d", p.RateLimit)
+	}
+	if p.ElevatedRateLimit < 0 {
+		return fmt.Errorf("developer-portal.elevated-rate-limit cannot be negative, got %d", p.ElevatedRateLimit)
+	}
+	if p.VerificationTTLHours < 0 {
+		return fmt.Errorf("developer-portal.verification-ttl-hours cannot be negative, got %d", p.VerificationTTLHours)
+	}
+	if p.MaxKeysPerEmail < 0 {
+		return fmt.Errorf("developer-portal.max-keys-per-email cannot be negative, got %d", p.MaxKeysPerEmail)
+	}
+	if p.SMTP.Host == "" {
+		if env == "production" {
+			return fmt.Errorf("developer-portal.smtp.host is required in production")
+		}
+		return nil
+	}
+	if p.SMTP.Port < 1 || p.SMTP.Port > 65535 {
+		return fmt.Errorf("developer-portal.smtp.port must be between 1 and 65535, got %d", p.SMTP.Port)
+	}
+	if _, err := mail.ParseAddress(p.SMTP.From); err != nil {
+		return fmt.Errorf("developer-portal.smtp.from must be an email address: %w", err)
+	}
+	if (p.SMTP.Username == "") != (p.SMTP.Password == "") {
+		return fmt.Errorf("both developer-portal.smtp.username and developer-portal.smtp.password must be provided together")
+	}
+	return nil
+}
+
 // RTFeedConfigData holds per-feed GTFS-RT configuration
 type RTFeedConfigData struct {
 	ID                  string   // Note it will be generated if missing
@@ -259,16 +848,44 @@ type RTFeedConfigData struct {
 	Enabled             bool // default true
 }
 
+// StaticFeedConfigData holds the configuration of an additional static feed
+type StaticFeedConfigData struct {
+	URL             string
+	AuthHeaderKey   string
+	AuthHeaderValue string
+	EnableGTFSTidy  bool
+}
+
 // GtfsConfigData holds GTFS configuration data without importing gtfs package
 // This avoids import cycles
 type GtfsConfigData struct {
 	GtfsURL               string
 	StaticAuthHeaderKey   string
 	StaticAuthHeaderValue string
+	AdditionalStaticFeeds []StaticFeedConfigData
 	RTFeeds               []RTFeedConfigData
 	GTFSDataPath          string
+	DataEncryptionKey     string
 	Env                   Environment
 	EnableGTFSTidy        bool
+	MirrorDir             string
+	AmenitiesPath         string
+	PostImportProcessors  []string
+	SlowQueryThreshold    time.Duration
+	// Zero disables the reload guard.
+	ReloadGuardMaxDropPercent float64
+	// Zero uses the GTFS manager's default warning window.
+	FeedExpiryWarningDays int
+	// Zero disables transfer generation for feeds without transfers.txt.
+	TransferRadiusMeters float64
+	// Zero disables recording arrival history.
+	ArrivalHistoryDays int
+	// Zero keeps no earlier datasets.
+	DatasetSnapshots int
+	// Zero disables sampling schedule deviations.
+	DeviationSampleInterval time.Duration
+	// Agencies served schedule-only while their realtime feeds keep polling.
+	RealtimeDisabledAgencies []string
 }
 
 // ToGtfsConfigData converts JSONConfig to GtfsConfigData
@@ -278,8 +895,30 @@ func (j *JSONConfig) ToGtfsConfigData() (GtfsConfigData, error) {
 		StaticAuthHeaderKey:   j.GtfsStaticFeed.AuthHeaderName,
 		StaticAuthHeaderValue: j.GtfsStaticFeed.AuthHeaderValue,
 		GTFSDataPath:          j.DataPath,
+		DataEncryptionKey:     j.DataEncryptionKey,
 		Env:                   EnvFlagToEnvironment(j.Env),
 		EnableGTFSTidy:        j.GtfsStaticFeed.EnableGTFSTidy,
+		MirrorDir:             j.MirrorDir,
+		AmenitiesPath:         j.AmenitiesPath,
+		PostImportProcessors:  j.PostImportProcessors,
+		SlowQueryThreshold:    time.Duration(j.SlowQueryThresholdMs) * time.Millisecond,
+
+		ReloadGuardMaxDropPercent: j.ReloadGuardMaxDropPercent,
+		FeedExpiryWarningDays:     j.FeedExpiryWarningDays,
+		RealtimeDisabledAgencies:  j.RealtimeDisabledAgencies,
+		TransferRadiusMeters:      j.TransferRadiusMeters,
+		ArrivalHistoryDays:        j.ArrivalHistoryDays,
+		DatasetSnapshots:          j.DatasetSnapshots,
+		DeviationSampleInterval:   time.Duration(j.DeviationSampleSeconds) * time.Second,
+	}
+
+	for _, feed := range j.AdditionalGtfsStaticFeeds {
+		cfg.AdditionalStaticFeeds = append(cfg.AdditionalStaticFeeds, StaticFeedConfigData{
+			URL:             feed.URL,
+			AuthHeaderKey:   feed.AuthHeaderName,
+			AuthHeaderValue: feed.AuthHeaderValue,
+			EnableGTFSTidy:  feed.EnableGTFSTidy,
+		})
 	}
 
 	seen := make(map[string]struct{})
@@ -422,6 +1061,21 @@ func LoadFromFile(path string) (*JSONConfig, error) {
 		config.GtfsStaticFeed.AuthHeaderValue = staticValue
 	}
 
+	// Override the database encryption key so it can be kept out of the file
+	if dataKey := os.Getenv("GTFS_DATA_ENCRYPTION_KEY"); dataKey != "" {
+		config.DataEncryptionKey = dataKey
+	}
+
+	// Override the developer portal's SMTP password so it can be kept out of the file
+	if smtpPassword := os.Getenv("MAGLEV_SMTP_PASSWORD"); smtpPassword != "" {
+		config.Portal.SMTP.Password = smtpPassword
+	}
+
+	// Override the rate limit Redis URL, which may carry a password
+	if redisURL := os.Getenv("MAGLEV_REDIS_URL"); redisURL != "" {
+		config.RateLimitBackend.RedisURL = redisURL
+	}
+
 	// Override Realtime Feed Auth (Name + Value)
 	// Note: Currently only overrides the first configured realtime feed explicitly
 	rtName := os.Getenv("GTFS_REALTIME_AUTH_NAME")
@@ -2,7 +2,9 @@ package appconf
 
 import (
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -203,11 +205,12 @@ func TestValidate_DuplicateApiKeys(t *testing.T) {
 
 func TestToAppConfig(t *testing.T) {
 	jsonConfig := &JSONConfig{
-		Port:          8080,
-		Env:           "production",
-		ApiKeys:       []string{"key1", "key2"},
-		RateLimit:     50,
-		ExemptApiKeys: []string{"exempt-key-1"},
+		Port:           8080,
+		Env:            "production",
+		ApiKeys:        []string{"key1", "key2"},
+		RateLimit:      50,
+		ExemptApiKeys:  []string{"exempt-key-1"},
+		ExemptIPRanges: []string{"10.0.0.0/8"},
 	}
 
 	appConfig := jsonConfig.ToAppConfig()
@@ -217,6 +220,7 @@ func TestToAppConfig(t *testing.T) {
 	assert.Equal(t, []string{"key1", "key2"}, appConfig.ApiKeys)
 	assert.Equal(t, 50, appConfig.RateLimit)
 	assert.Equal(t, []string{"exempt-key-1"}, appConfig.ExemptApiKeys)
+	assert.Equal(t, []string{"10.0.0.0/8"}, appConfig.ExemptIPRanges)
 }
 
 func TestToAppConfig_EnvironmentConversion(t *testing.T) {
@@ -245,6 +249,735 @@ func TestToAppConfig_EnvironmentConversion(t *testing.T) {
 	}
 }
 
+func TestValidate_NegativeSlowQueryThreshold(t *testing.T) {
+	config := &JSONConfig{
+		Port:                 4000,
+		Env:                  "development",
+		ApiKeys:              []string{"test"},
+		ProtectedApiKeys:     []string{"test"},
+		RateLimit:            100,
+		LogLevel:             "info",
+		LogFormat:            "text",
+		SlowQueryThresholdMs: -1,
+	}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "slow-query-threshold-ms cannot be negative")
+}
+
+func TestValidate_RealtimeRefreshInterval(t *testing.T) {
+	config := &JSONConfig{
+		Port:             4000,
+		Env:              "development",
+		ApiKeys:          []string{"test"},
+		ProtectedApiKeys: []string{"test"},
+		RateLimit:        100,
+		LogLevel:         "info",
+		LogFormat:        "text",
+		GtfsRtFeeds:      []GtfsRtFeed{{ID: "fast"}, {ID: "slow"}},
+	}
+	for _, interval := range []int{-1, 1, MinRealtimeRefreshInterval - 1} {
+		config.GtfsRtFeeds[1].RefreshInterval = interval
+		err := config.Validate()
+		require.Error(t, err, "interval %d", interval)
+		assert.Contains(t, err.Error(), "gtfs-rt-feeds[1].refresh-interval must be at least")
+	}
+	for _, interval := range []int{0, MinRealtimeRefreshInterval, 120} {
+		config.GtfsRtFeeds[1].RefreshInterval = interval
+		assert.NoError(t, config.Validate(), "interval %d", interval)
+	}
+}
+
+func TestValidate_NegativeFeedExpiryWarningDays(t *testing.T) {
+	config := &JSONConfig{
+		Port:                  4000,
+		Env:                   "development",
+		ApiKeys:               []string{"test"},
+		ProtectedApiKeys:      []string{"test"},
+		RateLimit:             100,
+		LogLevel:              "info",
+		LogFormat:             "text",
+		FeedExpiryWarningDays: -1,
+	}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "feed-expiry-warning-days cannot be negative")
+
+	config.FeedExpiryWarningDays = 14
+	require.NoError(t, config.Validate())
+	gtfsConfig, err := config.ToGtfsConfigData()
+	require.NoError(t, err)
+	assert.Equal(t, 14, gtfsConfig.FeedExpiryWarningDays)
+}
+
+func TestValidate_NegativeTransferRadius(t *testing.T) {
+	config := &JSONConfig{
+		Port:                 4000,
+		Env:                  "development",
+		ApiKeys:              []string{"test"},
+		ProtectedApiKeys:     []string{"test"},
+		RateLimit:            100,
+		LogLevel:             "info",
+		LogFormat:            "text",
+		TransferRadiusMeters: -1,
+	}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "transfer-radius-meters cannot be negative")
+
+	config.TransferRadiusMeters = 250
+	require.NoError(t, config.Validate())
+	gtfsConfig, err := config.ToGtfsConfigData()
+	require.NoError(t, err)
+	assert.Equal(t, 250.0, gtfsConfig.TransferRadiusMeters)
+}
+
+func TestValidate_NegativeArrivalHistoryDays(t *testing.T) {
+	config := &JSONConfig{
+		Port:               4000,
+		Env:                "development",
+		ApiKeys:            []string{"test"},
+		ProtectedApiKeys:   []string{"test"},
+		RateLimit:          100,
+		LogLevel:           "info",
+		LogFormat:          "text",
+		ArrivalHistoryDays: -1,
+	}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "arrival-history-days cannot be negative")
+
+	config.ArrivalHistoryDays = 30
+	require.NoError(t, config.Validate())
+	gtfsConfig, err := config.ToGtfsConfigData()
+	require.NoError(t, err)
+	assert.Equal(t, 30, gtfsConfig.ArrivalHistoryDays)
+}
+
+func TestValidate_NegativeDeviationSampleInterval(t *testing.T) {
+	config := &JSONConfig{
+		Port:                   4000,
+		Env:                    "development",
+		ApiKeys:                []string{"test"},
+		ProtectedApiKeys:       []string{"test"},
+		RateLimit:              100,
+		LogLevel:               "info",
+		LogFormat:              "text",
+		DeviationSampleSeconds: -1,
+	}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "deviation-sample-interval-seconds cannot be negative")
+
+	config.DeviationSampleSeconds = 30
+	require.NoError(t, config.Validate())
+	gtfsConfig, err := config.ToGtfsConfigData()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, gtfsConfig.DeviationSampleInterval)
+}
+
+func TestValidate_NegativeDatasetSnapshots(t *testing.T) {
+	config := &JSONConfig{
+		Port:             4000,
+		Env:              "development",
+		ApiKeys:          []string{"test"},
+		ProtectedApiKeys: []string{"test"},
+		RateLimit:        100,
+		LogLevel:         "info",
+		LogFormat:        "text",
+		DatasetSnapshots: -1,
+	}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dataset-snapshots cannot be negative")
+
+	config.DatasetSnapshots = 3
+	require.NoError(t, config.Validate())
+	gtfsConfig, err := config.ToGtfsConfigData()
+	require.NoError(t, err)
+	assert.Equal(t, 3, gtfsConfig.DatasetSnapshots)
+}
+
+func TestValidate_ReloadGuardMaxDropPercent(t *testing.T) {
+	for _, percent := range []float64{-1, 100, 150} {
+		config := &JSONConfig{
+			Port:                      4000,
+			Env:                       "development",
+			ApiKeys:                   []string{"test"},
+			ProtectedApiKeys:          []string{"test"},
+			RateLimit:                 100,
+			LogLevel:                  "info",
+			LogFormat:                 "text",
+			ReloadGuardMaxDropPercent: percent,
+		}
+		err := config.Validate()
+		assert.Error(t, err, "percent %g", percent)
+		assert.Contains(t, err.Error(), "reload-guard-max-drop-percent must be at least 0 and below 100")
+	}
+}
+
+func TestValidate_ExemptIPRanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		ranges      []string
+		expectedErr string
+	}{
+		{name: "none", ranges: nil},
+		{name: "cidr and bare addresses", ranges: []string{"10.20.0.0/16", "192.168.1.7", "2001:db8::/32"}},
+		{name: "bad cidr", ranges: []string{"10.20.0.0/33"}, expectedErr: "exempt-ip-ranges: invalid IP range"},
+		{name: "bad address", ranges: []string{"signage"}, expectedErr: "exempt-ip-ranges: invalid IP address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				ExemptIPRanges:   tt.ranges,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestValidate_LoadShedding(t *testing.T) {
+	tests := []struct {
+		name        string
+		shedding    LoadShedding
+		expectedErr string
+	}{
+		{name: "disabled", shedding: LoadShedding{}},
+		{name: "valid", shedding: LoadShedding{MaxInFlight: 100, TargetP99Ms: 500, Priorities: map[string]string{"stop": "low"}}},
+		{name: "negative max in flight", shedding: LoadShedding{MaxInFlight: -1}, expectedErr: "max-in-flight cannot be negative"},
+		{name: "negative target", shedding: LoadShedding{TargetP99Ms: -1}, expectedErr: "target-p99-ms cannot be negative"},
+		{name: "unknown priority", shedding: LoadShedding{Priorities: map[string]string{"stop": "urgent"}}, expectedErr: "must be one of [low, normal, critical]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				LoadShedding:     tt.shedding,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestToAppConfig_LoadShedding(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		LoadShedding: LoadShedding{MaxInFlight: 50, TargetP99Ms: 750, Priorities: map[string]string{"stop": "critical"}},
+	}
+
+	appConfig := jsonConfig.ToAppConfig()
+
+	assert.Equal(t, 50, appConfig.LoadShedding.MaxInFlight)
+	assert.Equal(t, 750*time.Millisecond, appConfig.LoadShedding.TargetP99)
+	assert.Equal(t, map[string]string{"stop": "critical"}, appConfig.LoadShedding.Priorities)
+}
+
+func TestValidate_SLO(t *testing.T) {
+	tests := []struct {
+		name        string
+		slo         SLO
+		expectedErr string
+	}{
+		{name: "defaults", slo: SLO{}},
+		{name: "valid", slo: SLO{AvailabilityTargetPercent: 99.5, LatencyThresholdMs: 500, LatencyTargetPercent: 95, WindowDays: 7}},
+		{name: "availability of 100", slo: SLO{AvailabilityTargetPercent: 100}, expectedErr: "slo.availability-target-percent must be at least 0 and below 100"},
+		{name: "negative latency target", slo: SLO{LatencyTargetPercent: -1}, expectedErr: "slo.latency-target-percent must be at least 0 and below 100"},
+		{name: "negative threshold", slo: SLO{LatencyThresholdMs: -1}, expectedErr: "slo.latency-threshold-ms cannot be negative"},
+		{name: "negative window", slo: SLO{WindowDays: -1}, expectedErr: "slo.window-days cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				SLO:              tt.slo,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestValidate_Search(t *testing.T) {
+	tests := []struct {
+		name        string
+		search      Search
+		expectedErr string
+	}{
+		{name: "defaults", search: Search{}},
+		{name: "wider rural defaults", search: Search{DefaultRadiusMeters: 5000, QueryRadiusMeters: 40000, MaxRadiusMeters: 50000, DefaultMaxCountStops: 300, MaxCount: 500}},
+		{name: "negative radius", search: Search{DefaultRadiusMeters: -1}, expectedErr: "search.default-radius-meters cannot be negative"},
+		{name: "negative max count", search: Search{MaxCount: -1}, expectedErr: "search.max-count cannot be negative"},
+		{name: "default radius above max", search: Search{DefaultRadiusMeters: 30000}, expectedErr: "search.default-radius-meters (30000) cannot exceed search.max-radius-meters (20000)"},
+		{name: "query radius above max", search: Search{MaxRadiusMeters: 5000}, expectedErr: "search.query-radius-meters (10000) cannot exceed search.max-radius-meters (5000)"},
+		{name: "default count above max", search: Search{DefaultMaxCountRoutes: 300}, expectedErr: "search.default-max-count-routes (300) cannot exceed search.max-count (250)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				Search:           tt.search,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestToAppConfig_Search(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		Search: Search{DefaultRadiusMeters: 5000, MaxCount: 500},
+	}
+
+	search := jsonConfig.ToAppConfig().Search
+	assert.Equal(t, 5000.0, search.DefaultRadius)
+	assert.Equal(t, 500, search.MaxCount)
+
+	search = search.WithDefaults()
+	assert.Equal(t, 5000.0, search.DefaultRadius)
+	assert.Equal(t, float64(DefaultQuerySearchRadius), search.QueryRadius)
+	assert.Equal(t, DefaultSearchMaxCountStops, search.DefaultMaxCountStops)
+	assert.Equal(t, 500, search.MaxCount)
+}
+
+func TestValidate_Compression(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression Compression
+		expectedErr string
+	}{
+		{name: "defaults", compression: Compression{}},
+		{name: "valid", compression: Compression{MinSizeBytes: 4096, Level: 9}},
+		{name: "negative min size", compression: Compression{MinSizeBytes: -1}, expectedErr: "compression.min-size-bytes cannot be negative"},
+		{name: "level too high", compression: Compression{Level: 10}, expectedErr: "compression.level must be between 1 and 9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				Compression:      tt.compression,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestToAppConfig_Compression(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		Compression: Compression{MinSizeBytes: 4096},
+	}
+
+	compression := jsonConfig.ToAppConfig().Compression.WithDefaults()
+	assert.Equal(t, 4096, compression.MinSize)
+	assert.Equal(t, DefaultCompressionLevel, compression.Level)
+}
+
+func TestToAppConfig_Interpolation(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		Interpolation: Interpolation{Enabled: true},
+	}
+	interpolation := jsonConfig.ToAppConfig().Interpolation.WithDefaults()
+	assert.True(t, interpolation.Enabled)
+	assert.Equal(t, DefaultInterpolationMaxElapsed, interpolation.MaxElapsed)
+
+	jsonConfig.Interpolation.MaxElapsedSeconds = 90
+	assert.Equal(t, 90*time.Second, jsonConfig.ToAppConfig().Interpolation.WithDefaults().MaxElapsed)
+
+	jsonConfig.Interpolation.MaxElapsedSeconds = -1
+	err := jsonConfig.Interpolation.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vehicle-interpolation.max-elapsed-seconds cannot be negative")
+}
+
+func TestValidate_Suppression(t *testing.T) {
+	tests := []struct {
+		name        string
+		suppression Suppression
+		expectedErr string
+	}{
+		{name: "empty", suppression: Suppression{}},
+		{name: "valid", suppression: Suppression{StopIDs: []string{"1_75403"}, RouteIDs: []string{"1_100"}}},
+		{name: "empty stop ID", suppression: Suppression{StopIDs: []string{" "}}, expectedErr: "suppression.stop-ids cannot contain empty IDs"},
+		{name: "empty route ID", suppression: Suppression{RouteIDs: []string{""}}, expectedErr: "suppression.route-ids cannot contain empty IDs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				Suppression:      tt.suppression,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestValidate_Regions(t *testing.T) {
+	tests := []struct {
+		name        string
+		regions     []Region
+		expectedErr string
+	}{
+		{name: "none"},
+		{name: "valid", regions: []Region{{ID: "puget-sound", AgencyIDs: []string{"1", "40"}}, {ID: "tampa", AgencyIDs: []string{"hart"}}}},
+		{name: "empty ID", regions: []Region{{AgencyIDs: []string{"1"}}}, expectedErr: "regions[0].id must be a non-empty ID"},
+		{name: "slash in ID", regions: []Region{{ID: "a/b", AgencyIDs: []string{"1"}}}, expectedErr: "regions[0].id must be a non-empty ID"},
+		{name: "duplicate ID", regions: []Region{{ID: "a", AgencyIDs: []string{"1"}}, {ID: "a", AgencyIDs: []string{"2"}}}, expectedErr: `regions[1].id "a" is used by another region`},
+		{name: "no agencies", regions: []Region{{ID: "a"}}, expectedErr: `region "a" must list at least one agency`},
+		{name: "shared agency", regions: []Region{{ID: "a", AgencyIDs: []string{"1"}}, {ID: "b", AgencyIDs: []string{"1"}}}, expectedErr: `agency "1" is in both region "a" and region "b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				Regions:          tt.regions,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.Len(t, config.ToAppConfig().Regions, len(tt.regions))
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestValidate_RateLimitBackend(t *testing.T) {
+	tests := []struct {
+		name        string
+		backend     RateLimitBackend
+		expectedErr string
+	}{
+		{name: "default memory", backend: RateLimitBackend{}},
+		{name: "redis", backend: RateLimitBackend{Type: "redis", RedisURL: "rediss://user:pw@cache.internal:6380/1", RedisKey: "maglev:prod"}},
+		{name: "unknown type", backend: RateLimitBackend{Type: "memcached"}, expectedErr: `rate-limit-backend.type must be one of [memory, redis], got "memcached"`},
+		{name: "redis without url", backend: RateLimitBackend{Type: "redis"}, expectedErr: "rate-limit-backend.redis-url is required"},
+		{name: "redis with http url", backend: RateLimitBackend{Type: "redis", RedisURL: "http://cache.internal"}, expectedErr: "rate-limit-backend.redis-url must be a redis:// or rediss:// URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &JSONConfig{
+				Port:             4000,
+				Env:              "development",
+				ApiKeys:          []string{"test"},
+				ProtectedApiKeys: []string{"test"},
+				RateLimit:        100,
+				LogLevel:         "info",
+				LogFormat:        "text",
+				RateLimitBackend: tt.backend,
+			}
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestToAppConfig_SLO(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		SLO: SLO{AvailabilityTargetPercent: 99.5, LatencyThresholdMs: 500, WindowDays: 7},
+	}
+
+	slo := jsonConfig.ToAppConfig().SLO
+
+	assert.Equal(t, 99.5, slo.AvailabilityTarget)
+	assert.Equal(t, 500*time.Millisecond, slo.LatencyThreshold)
+	assert.Equal(t, 7*24*time.Hour, slo.Window)
+	assert.Equal(t, DefaultSLOLatencyTarget, slo.WithDefaults().LatencyTarget)
+}
+
+func TestValidate_CanaryNegativeInterval(t *testing.T) {
+	config := &JSONConfig{
+		Port:             4000,
+		Env:              "development",
+		ApiKeys:          []string{"test"},
+		ProtectedApiKeys: []string{"test"},
+		RateLimit:        100,
+		LogLevel:         "info",
+		LogFormat:        "text",
+		Canary:           Canary{IntervalSeconds: -1, StopID: "1_75403"},
+	}
+
+	err := config.Validate()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "canary.interval-seconds cannot be negative")
+}
+
+func TestToAppConfig_MetricsEnabled(t *testing.T) {
+	assert.True(t, (&JSONConfig{}).ToAppConfig().MetricsEnabled, "metrics are served unless disabled")
+
+	disabled := false
+	assert.False(t, (&JSONConfig{MetricsEnabled: &disabled}).ToAppConfig().MetricsEnabled)
+}
+
+func TestToAppConfig_Canary(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		Canary: Canary{IntervalSeconds: 30, StopID: "1_75403", RouteID: "1_100479"},
+	}
+
+	canary := jsonConfig.ToAppConfig().Canary
+
+	assert.True(t, canary.Enabled())
+	assert.Equal(t, 30*time.Second, canary.Interval)
+	assert.Equal(t, "1_75403", canary.StopID)
+	assert.Equal(t, "1_100479", canary.RouteID)
+	assert.False(t, CanaryConfig{}.Enabled())
+}
+
+func TestValidate_SMSTemplates(t *testing.T) {
+	base := func(sms SMS) *JSONConfig {
+		return &JSONConfig{
+			Port:             4000,
+			Env:              "development",
+			ApiKeys:          []string{"test"},
+			ProtectedApiKeys: []string{"test"},
+			RateLimit:        100,
+			LogLevel:         "info",
+			LogFormat:        "text",
+			SMS:              sms,
+		}
+	}
+
+	assert.NoError(t, base(SMS{Template: "{{.StopName}} {{.Departures}}"}).Validate())
+
+	err := base(SMS{Template: "{{.StopName"}).Validate()
+	assert.ErrorContains(t, err, "sms.template is not a valid template")
+
+	err = base(SMS{AgencyTemplates: map[string]string{"1": "{{end}}"}}).Validate()
+	assert.ErrorContains(t, err, `sms.agency-templates["1"] is not a valid template`)
+}
+
+func TestValidate_DeveloperPortal(t *testing.T) {
+	base := func(env string, portal Portal) *JSONConfig {
+		return &JSONConfig{
+			Port:             4000,
+			Env:              env,
+			ApiKeys:          []string{"test"},
+			ProtectedApiKeys: []string{"test"},
+			RateLimit:        100,
+			LogLevel:         "info",
+			LogFormat:        "text",
+			Portal:           portal,
+		}
+	}
+	smtp := SMTP{Host: "smtp.example.org", Port: 587, Username: "portal", Password: "secret", From: "portal@example.org"}
+
+	assert.NoError(t, base("production", Portal{BaseURL: "not validated while disabled"}).Validate())
+	assert.NoError(t, base("development", Portal{Enabled: true, BaseURL: "http://localhost:4000"}).Validate())
+	assert.NoError(t, base("production", Portal{Enabled: true, BaseURL: "https://api.example.org", SMTP: smtp}).Validate())
+
+	tests := []struct {
+		name   string
+		env    string
+		portal Portal
+		want   string
+	}{
+		{"missing base URL", "development", Portal{Enabled: true}, "developer-portal.base-url"},
+		{"relative base URL", "development", Portal{Enabled: true, BaseURL: "/portal"}, "developer-portal.base-url"},
+		{"negative rate limit", "development", Portal{Enabled: true, BaseURL: "https://a.org", RateLimit: -1}, "developer-portal.rate-limit cannot be negative"},
+		{"production without SMTP", "production", Portal{Enabled: true, BaseURL: "https://a.org"}, "developer-portal.smtp.host is required in production"},
+		{"bad SMTP port", "development", Portal{Enabled: true, BaseURL: "https://a.org", SMTP: SMTP{Host: "smtp.a.org", From: "p@a.org"}}, "developer-portal.smtp.port"},
+		{"bad sender", "development", Portal{Enabled: true, BaseURL: "https://a.org", SMTP: SMTP{Host: "smtp.a.org", Port: 25, From: "nobody"}}, "developer-portal.smtp.from"},
+		{"username without password", "development", Portal{Enabled: true, BaseURL: "https://a.org", SMTP: SMTP{Host: "smtp.a.org", Port: 25, From: "p@a.org", Username: "u"}}, "must be provided together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, base(tt.env, tt.portal).Validate(), tt.want)
+		})
+	}
+}
+
+func TestToAppConfig_DeveloperPortal(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		Portal: Portal{Enabled: true, BaseURL: "https://api.example.org/", VerificationTTLHours: 2, SMTP: SMTP{Host: "smtp.example.org", Port: 25}},
+	}
+
+	cfg := jsonConfig.ToAppConfig().Portal
+
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "https://api.example.org", cfg.BaseURL, "trailing slash is trimmed so links join cleanly")
+	assert.Equal(t, 2*time.Hour, cfg.VerificationTTL)
+	assert.Equal(t, "smtp.example.org", cfg.SMTP.Host)
+
+	defaults := cfg.WithDefaults()
+	assert.Equal(t, DefaultPortalRateLimit, defaults.RateLimit)
+	assert.Equal(t, DefaultPortalElevatedRateLimit, defaults.ElevatedRateLimit)
+	assert.Equal(t, 2*time.Hour, defaults.VerificationTTL)
+	assert.Equal(t, DefaultPortalMaxKeysPerEmail, defaults.MaxKeysPerEmail)
+}
+
+func TestValidate_ScheduledJobs(t *testing.T) {
+	base := func(jobs map[string]ScheduledJob) *JSONConfig {
+		return &JSONConfig{
+			Port:             4000,
+			Env:              "development",
+			ApiKeys:          []string{"test"},
+			ProtectedApiKeys: []string{"test"},
+			RateLimit:        100,
+			LogLevel:         "info",
+			LogFormat:        "text",
+			ScheduledJobs:    jobs,
+		}
+	}
+	disabled := false
+
+	assert.NoError(t, base(map[string]ScheduledJob{
+		JobFeedReload:     {Enabled: &disabled},
+		JobArchivePruning: {IntervalSeconds: 3600, JitterSeconds: 60, RetentionDays: 30},
+	}).Validate())
+
+	tests := []struct {
+		name string
+		jobs map[string]ScheduledJob
+		want string
+	}{
+		{"unknown job", map[string]ScheduledJob{"vacuum": {}}, `unknown job "vacuum"`},
+		{"negative interval", map[string]ScheduledJob{JobFeedReload: {IntervalSeconds: -1}}, "interval-seconds cannot be negative"},
+		{"negative jitter", map[string]ScheduledJob{JobFeedReload: {JitterSeconds: -1}}, "jitter-seconds cannot be negative"},
+		{"negative retention", map[string]ScheduledJob{JobArchivePruning: {RetentionDays: -1}}, "retention-days cannot be negative"},
+		{"retention on another job", map[string]ScheduledJob{JobOnTimePerformance: {RetentionDays: 7}}, "retention-days only applies to archive-pruning"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, base(tt.jobs).Validate(), tt.want)
+		})
+	}
+}
+
+func TestToAppConfig_ScheduledJobs(t *testing.T) {
+	disabled := false
+	jsonConfig := &JSONConfig{
+		ScheduledJobs: map[string]ScheduledJob{
+			JobFeedReload:     {Enabled: &disabled},
+			JobArchivePruning: {IntervalSeconds: 3600, JitterSeconds: 60, RetentionDays: 30},
+		},
+	}
+
+	jobs := jsonConfig.ToAppConfig().Jobs
+
+	require.Len(t, jobs, 2)
+	require.NotNil(t, jobs[JobFeedReload].Enabled)
+	assert.False(t, *jobs[JobFeedReload].Enabled)
+	assert.Equal(t, JobConfig{Interval: time.Hour, Jitter: time.Minute, Retention: 30 * 24 * time.Hour}, jobs[JobArchivePruning])
+	assert.Nil(t, (&JSONConfig{}).ToAppConfig().Jobs)
+}
+
+func TestSMSConfigTemplateFor(t *testing.T) {
+	assert.Equal(t, DefaultSMSTemplate, SMSConfig{}.TemplateFor("1"))
+
+	cfg := (&JSONConfig{SMS: SMS{Template: "default", AgencyTemplates: map[string]string{"1": "agency"}}}).ToAppConfig().SMS
+	assert.Equal(t, "agency", cfg.TemplateFor("1"))
+	assert.Equal(t, "default", cfg.TemplateFor("40"))
+}
+
+func TestToGtfsConfigData_SlowQueryThreshold(t *testing.T) {
+	jsonConfig := &JSONConfig{SlowQueryThresholdMs: 250}
+
+	gtfsConfig, err := jsonConfig.ToGtfsConfigData()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, gtfsConfig.SlowQueryThreshold)
+}
+
+func TestToGtfsConfigData_ReloadGuardMaxDropPercent(t *testing.T) {
+	jsonConfig := &JSONConfig{ReloadGuardMaxDropPercent: 25}
+
+	gtfsConfig, err := jsonConfig.ToGtfsConfigData()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 25.0, gtfsConfig.ReloadGuardMaxDropPercent)
+}
+
 func TestToGtfsConfigData_NoFeeds(t *testing.T) {
 	jsonConfig := &JSONConfig{
 		Port: 4000,
@@ -591,6 +1324,8 @@ func TestLoadFromFile_EnvVarOverrides(t *testing.T) {
 		t.Setenv("GTFS_REALTIME_AUTH_VALUE", "env-rt-secret")
 		t.Setenv("MAGLEV_LOG_LEVEL", "debug")
 		t.Setenv("MAGLEV_LOG_FORMAT", "json")
+		t.Setenv("GTFS_DATA_ENCRYPTION_KEY", "env-data-key")
+		t.Setenv("MAGLEV_REDIS_URL", "redis://:env-redis-secret@cache:6379")
 
 		config, err := LoadFromFile(tmpFile.Name())
 		require.NoError(t, err)
@@ -605,6 +1340,8 @@ func TestLoadFromFile_EnvVarOverrides(t *testing.T) {
 		assert.Equal(t, "env-rt-secret", config.GtfsRtFeeds[0].RealTimeAuthHeaderValue)
 		assert.Equal(t, "debug", config.LogLevel)
 		assert.Equal(t, "json", config.LogFormat)
+		assert.Equal(t, "env-data-key", config.DataEncryptionKey)
+		assert.Equal(t, "redis://:env-redis-secret@cache:6379", config.RateLimitBackend.RedisURL)
 	})
 
 	t.Run("Parsing Edge Cases - Spaces and Empty Segments", func(t *testing.T) {
@@ -674,3 +1411,100 @@ func TestToGtfsConfigData_DuplicateIDs(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "duplicate feed ID")
 }
+
+func TestValidate_PostImportProcessors(t *testing.T) {
+	config := &JSONConfig{
+		Port:                 4000,
+		Env:                  "development",
+		ApiKeys:              []string{"test"},
+		ProtectedApiKeys:     []string{"test"},
+		RateLimit:            100,
+		LogLevel:             "info",
+		LogFormat:            "text",
+		PostImportProcessors: []string{"sqlite-optimize"},
+	}
+	assert.NoError(t, config.Validate())
+
+	config.PostImportProcessors = []string{"sqlite-optimize", " "}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "post-import-processors cannot contain empty names")
+}
+
+func TestValidate_RealtimeDisabledAgencies(t *testing.T) {
+	config := &JSONConfig{
+		Port:                     4000,
+		Env:                      "development",
+		ApiKeys:                  []string{"test"},
+		ProtectedApiKeys:         []string{"test"},
+		RateLimit:                100,
+		LogLevel:                 "info",
+		LogFormat:                "text",
+		RealtimeDisabledAgencies: []string{"40", "1"},
+	}
+	assert.NoError(t, config.Validate())
+
+	gtfsCfg, err := config.ToGtfsConfigData()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"40", "1"}, gtfsCfg.RealtimeDisabledAgencies)
+
+	config.RealtimeDisabledAgencies = []string{"40", ""}
+	err = config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "realtime-disabled-agencies cannot contain empty agency IDs")
+}
+
+func TestValidate_AdditionalGtfsStaticFeeds(t *testing.T) {
+	config := &JSONConfig{
+		Port:             4000,
+		Env:              "development",
+		ApiKeys:          []string{"test"},
+		ProtectedApiKeys: []string{"test"},
+		RateLimit:        100,
+		LogLevel:         "info",
+		LogFormat:        "text",
+		GtfsStaticFeed:   GtfsStaticFeed{URL: "https://example.com/a.zip"},
+		AdditionalGtfsStaticFeeds: []GtfsStaticFeed{
+			{URL: "https://example.com/b.zip", AuthHeaderName: "X-Key", AuthHeaderValue: "secret"},
+		},
+	}
+	assert.NoError(t, config.Validate())
+
+	tests := []struct {
+		name string
+		feed GtfsStaticFeed
+		want string
+	}{
+		{"empty URL", GtfsStaticFeed{URL: " "}, "additional-gtfs-static-feeds[1].url cannot be empty"},
+		{"duplicate URL", GtfsStaticFeed{URL: "https://example.com/a.zip"}, "configured more than once"},
+		{"partial auth", GtfsStaticFeed{URL: "https://example.com/c.zip", AuthHeaderName: "X-Key"}, "must be provided together for additional-gtfs-static-feeds[1]"},
+		{"file URL", GtfsStaticFeed{URL: "file:///etc/gtfs.zip"}, "file:// URLs are not allowed for additional-gtfs-static-feeds[1].url"},
+		{"path traversal", GtfsStaticFeed{URL: "../gtfs.zip"}, "additional-gtfs-static-feeds[1].url cannot start with '..'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *config
+			c.AdditionalGtfsStaticFeeds = append(slices.Clone(config.AdditionalGtfsStaticFeeds), tt.feed)
+			err := c.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestToGtfsConfigData_AdditionalStaticFeeds(t *testing.T) {
+	jsonConfig := &JSONConfig{
+		GtfsStaticFeed: GtfsStaticFeed{URL: "https://example.com/a.zip"},
+		AdditionalGtfsStaticFeeds: []GtfsStaticFeed{
+			{URL: "https://example.com/b.zip", AuthHeaderName: "X-Key", AuthHeaderValue: "secret", EnableGTFSTidy: true},
+		},
+		DataPath: "/data/gtfs.db",
+	}
+
+	gtfsConfig, err := jsonConfig.ToGtfsConfigData()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a.zip", gtfsConfig.GtfsURL)
+	assert.Equal(t, []StaticFeedConfigData{
+		{URL: "https://example.com/b.zip", AuthHeaderKey: "X-Key", AuthHeaderValue: "secret", EnableGTFSTidy: true},
+	}, gtfsConfig.AdditionalStaticFeeds)
+}
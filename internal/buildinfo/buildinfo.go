@@ -1,3 +1,6 @@
+// Package buildinfo holds the build stamp set at compile time with
+// -ldflags "-X maglev.onebusaway.org/internal/buildinfo.<Var>=..." by the
+// Makefile and Dockerfile. Unstamped builds report the defaults below.
 package buildinfo
 
 var (
@@ -13,3 +16,44 @@ var (
 	CommitMessage = "unknown"
 	Host          = "unknown"
 )
+
+// Info is the build stamp reported by /healthz and the debug pages.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	Dirty     bool   `json:"dirty,omitempty"`
+}
+
+// Current returns the stamp of the running binary.
+func Current() Info {
+	return Info{
+		Version:   Version,
+		Commit:    CommitHash,
+		BuildTime: BuildTime,
+		Dirty:     Dirty == "true",
+	}
+}
+
+// ShortCommit returns the abbreviated commit hash, or "unknown" for builds
+// made without ldflags.
+func ShortCommit() string {
+	if len(CommitHash) < 7 {
+		return "unknown"
+	}
+	return CommitHash[:7]
+}
+
+// HeaderValue returns the X-Maglev-Version header value: the version, then
+// "+" and the short commit when known, then ".dirty" for builds of a modified
+// tree, e.g. "v1.4.0+1a2b3c4".
+func HeaderValue() string {
+	value := Version
+	if commit := ShortCommit(); commit != "unknown" {
+		value += "+" + commit
+		if Dirty == "true" {
+			value += ".dirty"
+		}
+	}
+	return value
+}
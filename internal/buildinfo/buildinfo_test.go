@@ -31,3 +31,18 @@ func TestDefaultValues(t *testing.T) {
 		})
 	}
 }
+
+func TestHeaderValue(t *testing.T) {
+	originalVersion, originalCommit, originalDirty := Version, CommitHash, Dirty
+	defer func() { Version, CommitHash, Dirty = originalVersion, originalCommit, originalDirty }()
+
+	assert.Equal(t, "dev", HeaderValue(), "unstamped builds report only the version")
+	assert.Equal(t, "unknown", ShortCommit())
+
+	Version, CommitHash = "v1.4.0", "1a2b3c4d5e6f"
+	assert.Equal(t, "v1.4.0+1a2b3c4", HeaderValue())
+
+	Dirty = "true"
+	assert.Equal(t, "v1.4.0+1a2b3c4.dirty", HeaderValue())
+	assert.True(t, Current().Dirty)
+}
@@ -499,3 +499,45 @@ func TestFeedVehicleRetentionWithAgencyFilter(t *testing.T) {
 
 	assert.Len(t, manager.GetRealTimeVehicles(), 1, "seeded vehicle should be present")
 }
+
+func TestRealtimeDisabledAgenciesServeScheduleOnly(t *testing.T) {
+	routes := map[string]*gtfs.Route{
+		"R1": {Id: "R1", Agency: &gtfs.Agency{Id: "agency-A"}},
+		"R2": {Id: "R2", Agency: &gtfs.Agency{Id: "agency-B"}},
+	}
+	manager := newTestManagerWithRoutes(routes)
+
+	manager.realTimeMutex.Lock()
+	manager.feedTrips["feed-a"] = []gtfs.Trip{
+		{ID: gtfs.TripID{ID: "T1", RouteID: "R1"}},   // agency-A
+		{ID: gtfs.TripID{ID: "T2", RouteID: "R2"}},   // agency-B
+		{ID: gtfs.TripID{ID: "T3", RouteID: "R999"}}, // unknown route
+	}
+	manager.feedVehicles["feed-a"] = []gtfs.Vehicle{
+		{ID: &gtfs.VehicleID{ID: "V1"}, Trip: &gtfs.Trip{ID: gtfs.TripID{ID: "T1", RouteID: "R1"}}},
+		{ID: &gtfs.VehicleID{ID: "V2"}, Trip: &gtfs.Trip{ID: gtfs.TripID{ID: "T2", RouteID: "R2"}}},
+		{ID: &gtfs.VehicleID{ID: "V3"}},
+	}
+	manager.rebuildMergedRealtimeLocked()
+	manager.realTimeMutex.Unlock()
+
+	manager.SetRealtimeDisabledAgencies([]string{"agency-B"})
+
+	tripIDs := make(map[string]bool)
+	for _, trip := range manager.GetRealTimeTrips() {
+		tripIDs[trip.ID.ID] = true
+	}
+	assert.Equal(t, map[string]bool{"T1": true, "T3": true}, tripIDs, "agency-B trips should be dropped, unresolvable ones kept")
+	_, err := manager.GetTripUpdateByID("T2")
+	assert.Error(t, err)
+
+	vehicleIDs := make(map[string]bool)
+	for _, vehicle := range manager.GetRealTimeVehicles() {
+		vehicleIDs[vehicle.ID.ID] = true
+	}
+	assert.Equal(t, map[string]bool{"V1": true, "V3": true}, vehicleIDs)
+
+	manager.SetRealtimeDisabledAgencies(nil)
+	assert.Len(t, manager.GetRealTimeTrips(), 3, "clearing the list should restore realtime immediately")
+	assert.Len(t, manager.GetRealTimeVehicles(), 3)
+}
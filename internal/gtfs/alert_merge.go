@@ -0,0 +1,112 @@
+package gtfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/OneBusAway/go-gtfs"
+)
+
+// alertFingerprint identifies an alert by what it says rather than by its ID:
+// its header, description, active periods and informed entities. Feeds that
+// publish the same alert, or a feed that renumbers its alerts on reload, give
+// copies with different IDs but the same fingerprint. The order of
+// translations, periods and entities does not matter.
+func alertFingerprint(alert gtfs.Alert) string {
+	var b strings.Builder
+	writeSorted := func(section string, parts []string) {
+		slices.Sort(parts)
+		b.WriteString(section)
+		for _, part := range parts {
+			b.WriteByte(0)
+			b.WriteString(part)
+		}
+		b.WriteByte('\n')
+	}
+
+	writeSorted("header", alertTexts(alert.Header))
+	writeSorted("description", alertTexts(alert.Description))
+
+	periods := make([]string, 0, len(alert.ActivePeriods))
+	for _, period := range alert.ActivePeriods {
+		var start, end int64
+		if period.StartsAt != nil {
+			start = period.StartsAt.Unix()
+		}
+		if period.EndsAt != nil {
+			end = period.EndsAt.Unix()
+		}
+		periods = append(periods, strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10))
+	}
+	writeSorted("periods", periods)
+
+	entities := make([]string, 0, len(alert.InformedEntities))
+	for _, entity := range alert.InformedEntities {
+		fields := []string{
+			derefString(entity.AgencyID),
+			derefString(entity.RouteID),
+			strconv.Itoa(int(entity.RouteType)),
+			strconv.Itoa(int(entity.DirectionID)),
+			derefString(entity.StopID),
+			"",
+		}
+		if entity.TripID != nil {
+			fields[5] = entity.TripID.ID
+		}
+		entities = append(entities, strings.Join(fields, "\x1f"))
+	}
+	writeSorted("entities", entities)
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
+
+func alertTexts(texts []gtfs.AlertText) []string {
+	out := make([]string, 0, len(texts))
+	for _, text := range texts {
+		out = append(out, text.Language+"\x1f"+text.Text)
+	}
+	return out
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// mergeAlerts returns the alerts of the given feeds, in order, with copies of
+// the same alert merged into the first one seen. The first copy keeps its ID;
+// URL translations missing from it are taken from later copies. Alerts
+// without an ID are dropped, as they cannot be referenced as situations.
+func mergeAlerts(feeds [][]gtfs.Alert) []gtfs.Alert {
+	var merged []gtfs.Alert
+	byFingerprint := make(map[string]int)
+	for _, alerts := range feeds {
+		for _, alert := range alerts {
+			if alert.ID == "" {
+				continue
+			}
+			fingerprint := alertFingerprint(alert)
+			i, ok := byFingerprint[fingerprint]
+			if !ok {
+				byFingerprint[fingerprint] = len(merged)
+				merged = append(merged, alert)
+				continue
+			}
+			for _, url := range alert.URL {
+				hasLanguage := slices.ContainsFunc(merged[i].URL, func(t gtfs.AlertText) bool {
+					return t.Language == url.Language
+				})
+				if !hasLanguage {
+					merged[i].URL = append(slices.Clip(merged[i].URL), url)
+				}
+			}
+		}
+	}
+	return merged
+}
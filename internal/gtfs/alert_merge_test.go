@@ -0,0 +1,100 @@
+package gtfs
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func detourAlert(id string) gtfs.Alert {
+	route := "route1"
+	stop := "stop1"
+	start := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	end := start.Add(12 * time.Hour)
+	return gtfs.Alert{
+		ID:            id,
+		ActivePeriods: []gtfs.AlertActivePeriod{{StartsAt: &start, EndsAt: &end}},
+		InformedEntities: []gtfs.AlertInformedEntity{
+			{RouteID: &route},
+			{StopID: &stop},
+		},
+		Header: []gtfs.AlertText{
+			{Text: "Detour on route 1", Language: "en"},
+			{Text: "Desvío en la ruta 1", Language: "es"},
+		},
+		Description: []gtfs.AlertText{{Text: "Buses skip Main St.", Language: "en"}},
+	}
+}
+
+func TestAlertFingerprint(t *testing.T) {
+	alert := detourAlert("a1")
+	fingerprint := alertFingerprint(alert)
+
+	renumbered := detourAlert("feed2-77")
+	assert.Equal(t, fingerprint, alertFingerprint(renumbered), "the ID is not part of the fingerprint")
+
+	reordered := detourAlert("a1")
+	reordered.Header[0], reordered.Header[1] = reordered.Header[1], reordered.Header[0]
+	reordered.InformedEntities[0], reordered.InformedEntities[1] = reordered.InformedEntities[1], reordered.InformedEntities[0]
+	assert.Equal(t, fingerprint, alertFingerprint(reordered), "order does not matter")
+
+	reworded := detourAlert("a1")
+	reworded.Description[0].Text = "Buses skip Elm St."
+	assert.NotEqual(t, fingerprint, alertFingerprint(reworded))
+
+	extended := detourAlert("a1")
+	later := extended.ActivePeriods[0].EndsAt.Add(time.Hour)
+	extended.ActivePeriods[0].EndsAt = &later
+	assert.NotEqual(t, fingerprint, alertFingerprint(extended))
+
+	otherStop := detourAlert("a1")
+	stop := "stop2"
+	otherStop.InformedEntities[1].StopID = &stop
+	assert.NotEqual(t, fingerprint, alertFingerprint(otherStop))
+}
+
+func TestMergeAlerts(t *testing.T) {
+	first := detourAlert("a1")
+	first.URL = []gtfs.AlertText{{Text: "https://example.com/en", Language: "en"}}
+	second := detourAlert("feed2-77")
+	second.URL = []gtfs.AlertText{
+		{Text: "https://example.com/other", Language: "en"},
+		{Text: "https://example.com/es", Language: "es"},
+	}
+	unrelated := detourAlert("a2")
+	unrelated.Header = []gtfs.AlertText{{Text: "Elevator outage", Language: "en"}}
+
+	merged := mergeAlerts([][]gtfs.Alert{{first, unrelated}, {second, {}}})
+
+	require.Len(t, merged, 2)
+	assert.Equal(t, "a1", merged[0].ID, "the first copy keeps its ID")
+	assert.Equal(t, []gtfs.AlertText{
+		{Text: "https://example.com/en", Language: "en"},
+		{Text: "https://example.com/es", Language: "es"},
+	}, merged[0].URL, "missing URL translations come from later copies")
+	assert.Equal(t, "a2", merged[1].ID)
+	assert.Len(t, first.URL, 1, "the feed's alert is not modified")
+}
+
+func TestRebuildMergedRealtime_DeduplicatesAlertsAcrossFeeds(t *testing.T) {
+	manager := &Manager{
+		realTimeMutex: sync.RWMutex{},
+		feedAlerts: map[string][]gtfs.Alert{
+			"feed-0": {detourAlert("a1")},
+			"feed-1": {detourAlert("feed1-a1")},
+		},
+	}
+	manager.rebuildMergedRealtimeLocked()
+
+	stopAlerts := manager.GetAlertsForStop("stop1")
+	require.Len(t, stopAlerts, 1)
+	assert.Equal(t, "a1", stopAlerts[0].ID)
+
+	routeAlerts := manager.GetAlertsForRoute("route1")
+	require.Len(t, routeAlerts, 1)
+	assert.Equal(t, "a1", routeAlerts[0].ID)
+}
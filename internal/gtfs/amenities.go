@@ -0,0 +1,200 @@
+package gtfs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// readAmenitiesFile reads the supplementary amenities dataset at path and groups
+// it by raw GTFS stop ID. The format is chosen by extension: ".csv" files need
+// stop_id and type columns, with optional name, description, capacity, lat and
+// lon; ".geojson" and ".json" files are FeatureCollections of Point features
+// with the same names as properties.
+func readAmenitiesFile(path string) (map[string][]models.Amenity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseAmenitiesCSV(f)
+	case ".geojson", ".json":
+		return parseAmenitiesGeoJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported amenities file %q: expected .csv, .geojson or .json", path)
+	}
+}
+
+func parseAmenitiesCSV(r io.Reader) (map[string][]models.Amenity, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading amenities header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))] = i
+	}
+	for _, required := range []string{"stop_id", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("amenities file is missing the %s column", required)
+		}
+	}
+
+	amenities := make(map[string][]models.Amenity)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return amenities, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading amenities: %w", err)
+		}
+		get := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		amenity := models.Amenity{
+			Type:        get("type"),
+			Name:        get("name"),
+			Description: get("description"),
+		}
+		if err := setAmenityNumbers(&amenity, get("capacity"), get("lat"), get("lon")); err != nil {
+			return nil, fmt.Errorf("amenities line %d: %w", line, err)
+		}
+		if err := addAmenity(amenities, get("stop_id"), amenity); err != nil {
+			return nil, fmt.Errorf("amenities line %d: %w", line, err)
+		}
+	}
+}
+
+type amenityFeatureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry *struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties struct {
+			StopID      string `json:"stop_id"`
+			Type        string `json:"type"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Capacity    *int   `json:"capacity"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+func parseAmenitiesGeoJSON(r io.Reader) (map[string][]models.Amenity, error) {
+	var collection amenityFeatureCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("error decoding amenities GeoJSON: %w", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("amenities GeoJSON must be a FeatureCollection, got %q", collection.Type)
+	}
+
+	amenities := make(map[string][]models.Amenity)
+	for i, feature := range collection.Features {
+		props := feature.Properties
+		if props.Capacity != nil && *props.Capacity < 0 {
+			return nil, fmt.Errorf("amenities feature %d: capacity cannot be negative", i)
+		}
+		amenity := models.Amenity{
+			Type:        props.Type,
+			Name:        props.Name,
+			Description: props.Description,
+			Capacity:    props.Capacity,
+		}
+		if g := feature.Geometry; g != nil {
+			// GeoJSON positions are [longitude, latitude].
+			var position []float64
+			if g.Type != "Point" || json.Unmarshal(g.Coordinates, &position) != nil || len(position) < 2 {
+				return nil, fmt.Errorf("amenities feature %d: geometry must be a Point", i)
+			}
+			lon, lat := position[0], position[1]
+			amenity.Lat, amenity.Lon = &lat, &lon
+		}
+		if err := addAmenity(amenities, props.StopID, amenity); err != nil {
+			return nil, fmt.Errorf("amenities feature %d: %w", i, err)
+		}
+	}
+	return amenities, nil
+}
+
+func setAmenityNumbers(amenity *models.Amenity, capacity, lat, lon string) error {
+	if capacity != "" {
+		v, err := strconv.Atoi(capacity)
+		if err != nil || v < 0 {
+			return fmt.Errorf("invalid capacity %q", capacity)
+		}
+		amenity.Capacity = &v
+	}
+	if (lat == "") != (lon == "") {
+		return errors.New("lat and lon must be given together")
+	}
+	if lat != "" {
+		latV, err := strconv.ParseFloat(lat, 64)
+		if err != nil {
+			return fmt.Errorf("invalid lat %q", lat)
+		}
+		lonV, err := strconv.ParseFloat(lon, 64)
+		if err != nil {
+			return fmt.Errorf("invalid lon %q", lon)
+		}
+		amenity.Lat, amenity.Lon = &latV, &lonV
+	}
+	return nil
+}
+
+func addAmenity(amenities map[string][]models.Amenity, stopID string, amenity models.Amenity) error {
+	if stopID == "" {
+		return errors.New("stop_id is required")
+	}
+	if amenity.Type == "" {
+		return errors.New("type is required")
+	}
+	amenities[stopID] = append(amenities[stopID], amenity)
+	return nil
+}
+
+// loadAmenities loads the dataset at config.AmenitiesPath, if configured.
+func (manager *Manager) loadAmenities() error {
+	path := manager.config.AmenitiesPath
+	if path == "" {
+		return nil
+	}
+	amenities, err := readAmenitiesFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load amenities: %w", err)
+	}
+	manager.amenities = amenities
+	manager.config.logger().With(slog.String("component", "gtfs_manager")).Info("amenities loaded",
+		slog.String("path", path),
+		slog.Int("stops", len(amenities)))
+	return nil
+}
+
+// GetAmenitiesForStop returns the amenities at a stop, identified by its raw
+// GTFS stop ID, or nil when none are known or no dataset is configured.
+func (manager *Manager) GetAmenitiesForStop(stopID string) []models.Amenity {
+	return manager.amenities[stopID]
+}
@@ -0,0 +1,83 @@
+package gtfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAmenitiesFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestReadAmenitiesFile_CSV(t *testing.T) {
+	path := writeAmenitiesFile(t, "amenities.csv",
+		"stop_id,type,name,capacity,lat,lon\n"+
+			"1001,parking,North Lot,250,47.61,-122.33\n"+
+			"1001,bike-rack,,12,,\n"+
+			"1002,ticket-machine,,,,\n")
+
+	amenities, err := readAmenitiesFile(path)
+	require.NoError(t, err)
+	require.Len(t, amenities["1001"], 2)
+
+	lot := amenities["1001"][0]
+	assert.Equal(t, "parking", lot.Type)
+	assert.Equal(t, "North Lot", lot.Name)
+	require.NotNil(t, lot.Capacity)
+	assert.Equal(t, 250, *lot.Capacity)
+	require.NotNil(t, lot.Lat)
+	assert.InDelta(t, 47.61, *lot.Lat, 1e-9)
+
+	machine := amenities["1002"][0]
+	assert.Equal(t, "ticket-machine", machine.Type)
+	assert.Nil(t, machine.Capacity)
+	assert.Nil(t, machine.Lat)
+}
+
+func TestReadAmenitiesFile_GeoJSON(t *testing.T) {
+	path := writeAmenitiesFile(t, "amenities.geojson", `{
+		"type": "FeatureCollection",
+		"features": [{
+			"type": "Feature",
+			"geometry": {"type": "Point", "coordinates": [-122.33, 47.61]},
+			"properties": {"stop_id": "1001", "type": "parking", "name": "North Lot", "capacity": 250}
+		}]
+	}`)
+
+	amenities, err := readAmenitiesFile(path)
+	require.NoError(t, err)
+	require.Len(t, amenities["1001"], 1)
+	lot := amenities["1001"][0]
+	assert.Equal(t, "North Lot", lot.Name)
+	require.NotNil(t, lot.Lat)
+	require.NotNil(t, lot.Lon)
+	assert.InDelta(t, 47.61, *lot.Lat, 1e-9, "GeoJSON coordinates are [lon, lat]")
+	assert.InDelta(t, -122.33, *lot.Lon, 1e-9)
+}
+
+func TestReadAmenitiesFile_Errors(t *testing.T) {
+	tests := []struct {
+		name, file, content, wantErr string
+	}{
+		{"missing type column", "a.csv", "stop_id,name\n1,x\n", "missing the type column"},
+		{"missing stop id", "a.csv", "stop_id,type\n,parking\n", "stop_id is required"},
+		{"negative capacity", "a.csv", "stop_id,type,capacity\n1,parking,-1\n", "invalid capacity"},
+		{"lat without lon", "a.csv", "stop_id,type,lat\n1,parking,47.6\n", "lat and lon"},
+		{"not a collection", "a.geojson", `{"type": "Feature"}`, "FeatureCollection"},
+		{"non-point geometry", "a.json", `{"type": "FeatureCollection", "features": [{"geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}, "properties": {"stop_id": "1", "type": "parking"}}]}`, "geometry must be a Point"},
+		{"unknown extension", "a.txt", "", "unsupported amenities file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readAmenitiesFile(writeAmenitiesFile(t, tt.file, tt.content))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package gtfs
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/logging"
+)
+
+const (
+	// arrivalHistoryPruneInterval is how often rows older than the configured
+	// number of days are deleted.
+	arrivalHistoryPruneInterval = time.Hour
+	// historicalArrivalsTTL is how long aggregated history is served from
+	// memory. History accumulates over days, so minutes of staleness are fine.
+	historicalArrivalsTTL = 10 * time.Minute
+)
+
+// arrivalHistoryRecorder records realized arrivals and departures at each
+// stop of the trips in the realtime feeds to the arrival_history table, along
+// with the occupancy the vehicle reported when it left the stop.
+type arrivalHistoryRecorder struct {
+	mu sync.Mutex
+	// Stop times of each trip, resolved from static data (tripID -> schedule).
+	// Cleared when a static reload changes the data.
+	schedules map[string]tripSchedule
+	// Stops already recorded, so each is written once per trip instance.
+	recorded  map[recordedStop]time.Time
+	lastPrune time.Time
+
+	// Aggregated history by trip, computed on demand.
+	historical map[string]cachedHistoricalArrivals
+}
+
+type recordedStop struct {
+	tripInstance
+	stopSequence int64
+}
+
+type tripSchedule struct {
+	stopTimes []gtfsdb.StopTime
+	origin    tripOrigin
+}
+
+type cachedHistoricalArrivals struct {
+	arrivals   map[int64]HistoricalArrival
+	computedAt time.Time
+}
+
+// HistoricalArrival summarizes the recorded arrivals of a trip at one stop.
+type HistoricalArrival struct {
+	// Most often reported GTFS-RT occupancy status, or "" when none was.
+	Occupancy string
+	// Median delay against the scheduled arrival.
+	TypicalDelay time.Duration
+	Observations int
+}
+
+func (r *arrivalHistoryRecorder) clearSchedules() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedules = nil
+	r.historical = nil
+}
+
+func (manager *Manager) arrivalHistoryEnabled() bool {
+	return manager.config.ArrivalHistoryDays > 0 && manager.GtfsDB != nil
+}
+
+// observeArrivalHistory records the stops the trips in the realtime feeds
+// have left since the previous update. Only the most recently departed stop
+// of a trip gets the vehicle's occupancy, as the vehicle reports its current
+// load rather than the load at earlier stops.
+func (manager *Manager) observeArrivalHistory(ctx context.Context, now time.Time) {
+	if !manager.arrivalHistoryEnabled() {
+		return
+	}
+	trips := manager.GetRealTimeTrips()
+	vehicles := make(map[string]*gtfs.Vehicle)
+	for _, vehicle := range manager.GetRealTimeVehicles() {
+		if vehicle.Trip != nil {
+			vehicles[vehicle.Trip.ID.ID] = &vehicle
+		}
+	}
+
+	r := &manager.arrivalHistory
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recorded == nil {
+		r.recorded = make(map[recordedStop]time.Time)
+	}
+
+	var rows []gtfsdb.RecordArrivalHistoryParams
+	for _, trip := range trips {
+		schedule := manager.tripScheduleLocked(ctx, trip.ID.ID)
+		if !schedule.origin.ok {
+			continue
+		}
+		instance := schedule.origin.instance(trip.ID, now)
+		serviceDate, err := time.ParseInLocation("20060102", instance.serviceDate, schedule.origin.location)
+		if err != nil {
+			continue
+		}
+		vehicle := vehicles[trip.ID.ID]
+		var vehicleID string
+		if vehicle != nil && vehicle.ID != nil {
+			vehicleID = vehicle.ID.ID
+		} else if trip.Vehicle != nil && trip.Vehicle.ID != nil {
+			vehicleID = trip.Vehicle.ID.ID
+		}
+
+		latest := -1
+		for _, update := range trip.StopTimeUpdates {
+			if update.ScheduleRelationship == gtfsrt.TripUpdate_StopTimeUpdate_SKIPPED {
+				continue
+			}
+			stopTime, ok := scheduledStopTime(schedule.stopTimes, update.StopSequence, update.StopID)
+			if !ok {
+				continue
+			}
+			arrival := realizedTime(update.GetArrival(), serviceDate, stopTime.ArrivalTime)
+			departure := realizedTime(update.GetDeparture(), serviceDate, stopTime.DepartureTime)
+			// Only an event in the past is an observation rather than a prediction.
+			left := departure
+			if left == nil {
+				left = arrival
+			}
+			if left == nil || left.After(now) {
+				continue
+			}
+			key := recordedStop{tripInstance: instance, stopSequence: stopTime.StopSequence}
+			if _, done := r.recorded[key]; done {
+				continue
+			}
+			r.recorded[key] = *left
+
+			scheduled, actual := stopTime.ArrivalTime, arrival
+			if actual == nil {
+				scheduled, actual = stopTime.DepartureTime, departure
+			}
+			rows = append(rows, gtfsdb.RecordArrivalHistoryParams{
+				ServiceDate:     instance.serviceDate,
+				TripID:          trip.ID.ID,
+				StopSequence:    stopTime.StopSequence,
+				StopID:          stopTime.StopID,
+				VehicleID:       vehicleID,
+				ActualArrival:   nullUnixMilli(arrival),
+				ActualDeparture: nullUnixMilli(departure),
+				Delay:           int64(actual.Sub(serviceDate.Add(time.Duration(scheduled))) / time.Second),
+				RecordedAt:      now.UnixMilli(),
+			})
+			latest = len(rows) - 1
+		}
+		if latest >= 0 {
+			rows[latest].OccupancyStatus = VehicleOccupancyStatus(vehicle)
+		}
+	}
+
+	for key, left := range r.recorded {
+		if now.Sub(left) > tripStartRetention {
+			delete(r.recorded, key)
+		}
+	}
+
+	if err := manager.writeArrivalHistory(ctx, rows); err != nil {
+		slog.WarnContext(ctx, "failed to record arrival history",
+			slog.Int("rows", len(rows)),
+			slog.Any("error", err))
+	}
+
+	if now.Sub(r.lastPrune) >= arrivalHistoryPruneInterval {
+		r.lastPrune = now
+		before := now.AddDate(0, 0, -manager.config.ArrivalHistoryDays)
+		if _, err := manager.GtfsDB.Queries.DeleteArrivalHistoryBefore(ctx, before.UnixMilli()); err != nil {
+			slog.WarnContext(ctx, "failed to prune arrival history", slog.Any("error", err))
+		}
+	}
+}
+
+func (manager *Manager) writeArrivalHistory(ctx context.Context, rows []gtfsdb.RecordArrivalHistoryParams) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := manager.GtfsDB.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer logging.SafeRollbackWithLogging(tx, manager.config.logger(), "record_arrival_history")
+	queries := manager.GtfsDB.Queries.WithTx(tx)
+	for _, row := range rows {
+		if err := queries.RecordArrivalHistory(ctx, row); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// tripScheduleLocked returns the stop times of a trip, caching them until the
+// next static reload. The caller must hold manager.arrivalHistory.mu.
+func (manager *Manager) tripScheduleLocked(ctx context.Context, tripID string) tripSchedule {
+	r := &manager.arrivalHistory
+	if schedule, ok := r.schedules[tripID]; ok {
+		return schedule
+	}
+
+	var schedule tripSchedule
+	stopTimes, err := manager.GtfsDB.Queries.GetStopTimesForTrip(ctx, tripID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get stop times for arrival history",
+			slog.String("trip_id", tripID),
+			slog.Any("error", err))
+		return schedule
+	}
+	agencyID := manager.tripAlertScope(ctx, tripID).agencyID
+	if len(stopTimes) > 0 && agencyID != "" {
+		agency, err := manager.GtfsDB.Queries.GetAgency(ctx, agencyID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "failed to get agency for arrival history",
+				slog.String("trip_id", tripID),
+				slog.Any("error", err))
+			return schedule
+		}
+		if loc, locErr := time.LoadLocation(agency.Timezone); err == nil && locErr == nil {
+			schedule = tripSchedule{
+				stopTimes: stopTimes,
+				origin: tripOrigin{
+					stopID:       stopTimes[0].StopID,
+					stopSequence: uint32(stopTimes[0].StopSequence),
+					departure:    time.Duration(stopTimes[0].DepartureTime),
+					location:     loc,
+					ok:           true,
+				},
+			}
+		}
+	}
+
+	if r.schedules == nil {
+		r.schedules = make(map[string]tripSchedule)
+	}
+	r.schedules[tripID] = schedule
+	return schedule
+}
+
+// scheduledStopTime finds the stop time a stop time update refers to, by stop
+// sequence or, when absent, by stop ID.
+func scheduledStopTime(stopTimes []gtfsdb.StopTime, stopSequence *uint32, stopID *string) (gtfsdb.StopTime, bool) {
+	for _, st := range stopTimes {
+		if stopSequence != nil {
+			if st.StopSequence == int64(*stopSequence) {
+				return st, true
+			}
+		} else if stopID != nil && st.StopID == *stopID {
+			return st, true
+		}
+	}
+	return gtfsdb.StopTime{}, false
+}
+
+// realizedTime returns the time of a stop time event, from its absolute time
+// or its delay against the scheduled time, or nil when it has neither.
+func realizedTime(event gtfs.StopTimeEvent, serviceDate time.Time, scheduled int64) *time.Time {
+	if event.Time != nil {
+		return event.Time
+	}
+	if event.Delay != nil {
+		t := serviceDate.Add(time.Duration(scheduled) + *event.Delay)
+		return &t
+	}
+	return nil
+}
+
+func nullUnixMilli(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixMilli(), Valid: true}
+}
+
+// HistoricalArrivals summarizes the recorded arrivals of a trip by stop
+// sequence. It returns nil when arrival history is disabled or nothing has
+// been recorded for the trip.
+func (manager *Manager) HistoricalArrivals(ctx context.Context, tripID string) (map[int64]HistoricalArrival, error) {
+	if !manager.arrivalHistoryEnabled() {
+		return nil, nil
+	}
+	r := &manager.arrivalHistory
+	r.mu.Lock()
+	cached, ok := r.historical[tripID]
+	r.mu.Unlock()
+	if ok && time.Since(cached.computedAt) < historicalArrivalsTTL {
+		return cached.arrivals, nil
+	}
+
+	rows, err := manager.GtfsDB.Queries.ListArrivalHistoryForTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	arrivals := summarizeArrivalHistory(rows)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.historical == nil {
+		r.historical = make(map[string]cachedHistoricalArrivals)
+	}
+	r.historical[tripID] = cachedHistoricalArrivals{arrivals: arrivals, computedAt: time.Now()}
+	return arrivals, nil
+}
+
+// summarizeArrivalHistory reduces history rows, ordered by stop sequence, to
+// the most common occupancy and the median delay at each stop. Ties between
+// occupancy statuses go to the first in alphabetical order so the result is
+// stable.
+func summarizeArrivalHistory(rows []gtfsdb.ListArrivalHistoryForTripRow) map[int64]HistoricalArrival {
+	if len(rows) == 0 {
+		return nil
+	}
+	arrivals := make(map[int64]HistoricalArrival)
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].StopSequence == rows[start].StopSequence {
+			end++
+		}
+		stopRows := rows[start:end]
+
+		delays := make([]int64, len(stopRows))
+		counts := make(map[string]int)
+		for i, row := range stopRows {
+			delays[i] = row.Delay
+			if row.OccupancyStatus != "" {
+				counts[row.OccupancyStatus]++
+			}
+		}
+		slices.Sort(delays)
+
+		var occupancy string
+		for status, count := range counts {
+			if best := counts[occupancy]; count > best || (count == best && cmp.Less(status, occupancy)) {
+				occupancy = status
+			}
+		}
+
+		arrivals[rows[start].StopSequence] = HistoricalArrival{
+			Occupancy:    occupancy,
+			TypicalDelay: time.Duration(delays[len(delays)/2]) * time.Second,
+			Observations: len(stopRows),
+		}
+		start = end
+	}
+	return arrivals
+}
@@ -0,0 +1,141 @@
+package gtfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+func arrivalHistoryTrip(serviceDate time.Time, updates ...gtfs.StopTimeUpdate) gtfs.Trip {
+	return gtfs.Trip{
+		ID:              gtfs.TripID{ID: "t1", HasStartDate: true, StartDate: serviceDate},
+		StopTimeUpdates: updates,
+	}
+}
+
+func arrivalHistoryUpdate(sequence uint32, delay time.Duration) gtfs.StopTimeUpdate {
+	return gtfs.StopTimeUpdate{
+		StopSequence: &sequence,
+		Arrival:      &gtfs.StopTimeEvent{Delay: &delay},
+		Departure:    &gtfs.StopTimeEvent{Delay: &delay},
+	}
+}
+
+func countArrivalHistory(t *testing.T, manager *Manager) int {
+	t.Helper()
+	var n int
+	require.NoError(t, manager.GtfsDB.DB.QueryRow("SELECT COUNT(*) FROM arrival_history").Scan(&n))
+	return n
+}
+
+func TestObserveArrivalHistory_RecordsDepartedStops(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	manager.config.ArrivalHistoryDays = 30
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	status := gtfsrt.VehiclePosition_STANDING_ROOM_ONLY
+	manager.realTimeVehicles = []gtfs.Vehicle{{
+		ID:              &gtfs.VehicleID{ID: "v1"},
+		Trip:            &gtfs.Trip{ID: gtfs.TripID{ID: "t1"}},
+		OccupancyStatus: &status,
+	}}
+	manager.realTimeTrips = []gtfs.Trip{arrivalHistoryTrip(monday,
+		arrivalHistoryUpdate(1, 2*time.Minute),
+		arrivalHistoryUpdate(2, 3*time.Minute),
+	)}
+
+	manager.observeArrivalHistory(ctx, monday.Add(8*time.Hour+5*time.Minute))
+	assert.Equal(t, 1, countArrivalHistory(t, manager), "the predicted arrival at s2 is not recorded")
+
+	manager.observeArrivalHistory(ctx, monday.Add(8*time.Hour+15*time.Minute))
+	assert.Equal(t, 2, countArrivalHistory(t, manager), "each stop is recorded once")
+
+	var delay int64
+	var occupancy, vehicleID string
+	require.NoError(t, manager.GtfsDB.DB.QueryRow(
+		"SELECT delay, occupancy_status, vehicle_id FROM arrival_history WHERE stop_sequence = 2").Scan(&delay, &occupancy, &vehicleID))
+	assert.Equal(t, int64(180), delay)
+	assert.Equal(t, "STANDING_ROOM_ONLY", occupancy)
+	assert.Equal(t, "v1", vehicleID)
+
+	arrivals, err := manager.HistoricalArrivals(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, HistoricalArrival{Occupancy: "STANDING_ROOM_ONLY", TypicalDelay: 3 * time.Minute, Observations: 1}, arrivals[2])
+}
+
+func TestObserveArrivalHistory_OccupancyOnlyForLatestStop(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	manager.config.ArrivalHistoryDays = 30
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	status := gtfsrt.VehiclePosition_FULL
+	manager.realTimeVehicles = []gtfs.Vehicle{{
+		Trip:            &gtfs.Trip{ID: gtfs.TripID{ID: "t1"}},
+		OccupancyStatus: &status,
+	}}
+	manager.realTimeTrips = []gtfs.Trip{arrivalHistoryTrip(monday,
+		arrivalHistoryUpdate(1, 0),
+		arrivalHistoryUpdate(2, 0),
+	)}
+
+	// First seen after both stops: only the load leaving s2 is known.
+	manager.observeArrivalHistory(context.Background(), monday.Add(9*time.Hour))
+
+	rows, err := manager.GtfsDB.Queries.ListArrivalHistoryForTrip(context.Background(), "t1")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "", rows[0].OccupancyStatus)
+	assert.Equal(t, "FULL", rows[1].OccupancyStatus)
+}
+
+func TestObserveArrivalHistory_Disabled(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	manager.realTimeTrips = []gtfs.Trip{arrivalHistoryTrip(monday, arrivalHistoryUpdate(1, 0))}
+
+	manager.observeArrivalHistory(context.Background(), monday.Add(9*time.Hour))
+
+	assert.Equal(t, 0, countArrivalHistory(t, manager))
+	arrivals, err := manager.HistoricalArrivals(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Nil(t, arrivals)
+}
+
+func TestObserveArrivalHistory_PrunesOldRows(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	manager.config.ArrivalHistoryDays = 7
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	manager.realTimeTrips = []gtfs.Trip{arrivalHistoryTrip(monday, arrivalHistoryUpdate(1, 0))}
+	manager.observeArrivalHistory(ctx, monday.Add(9*time.Hour))
+	require.Equal(t, 1, countArrivalHistory(t, manager))
+
+	manager.realTimeTrips = nil
+	manager.observeArrivalHistory(ctx, monday.AddDate(0, 0, 6))
+	assert.Equal(t, 1, countArrivalHistory(t, manager))
+	manager.observeArrivalHistory(ctx, monday.AddDate(0, 0, 8))
+	assert.Equal(t, 0, countArrivalHistory(t, manager))
+}
+
+func TestSummarizeArrivalHistory(t *testing.T) {
+	rows := []gtfsdb.ListArrivalHistoryForTripRow{
+		{StopSequence: 1, Delay: 60, OccupancyStatus: "FULL"},
+		{StopSequence: 1, Delay: -30, OccupancyStatus: "MANY_SEATS_AVAILABLE"},
+		{StopSequence: 1, Delay: 120, OccupancyStatus: "FULL"},
+		{StopSequence: 2, Delay: 0, OccupancyStatus: "FULL"},
+		{StopSequence: 2, Delay: 10, OccupancyStatus: "EMPTY"},
+		{StopSequence: 3, Delay: 5},
+	}
+
+	arrivals := summarizeArrivalHistory(rows)
+
+	assert.Equal(t, HistoricalArrival{Occupancy: "FULL", TypicalDelay: time.Minute, Observations: 3}, arrivals[1])
+	assert.Equal(t, "EMPTY", arrivals[2].Occupancy, "ties go to the first status alphabetically")
+	assert.Equal(t, HistoricalArrival{TypicalDelay: 5 * time.Second, Observations: 1}, arrivals[3])
+	assert.Nil(t, summarizeArrivalHistory(nil))
+}
@@ -0,0 +1,150 @@
+package gtfs
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+// BlockAssignment is one active block instance and the realtime vehicles
+// assigned to it. A block is active while referenceTime falls between the
+// start of its first trip and the end of its last trip on ServiceDate.
+type BlockAssignment struct {
+	BlockID string
+	// Midnight of the service day in the agency's timezone.
+	ServiceDate time.Time
+	// The trip the block should be running: the trip in progress, or the next
+	// trip when the block is laying over between trips.
+	ExpectedTripID string
+	RouteID        string
+	// InProgress is false while the block is laying over between trips.
+	InProgress bool
+	// Vehicles whose realtime trip belongs to this block instance, sorted.
+	// Empty for unassigned blocks.
+	VehicleIDs []string
+}
+
+// GetBlockAssignments returns the agency's blocks active at referenceTime,
+// which must be in the agency's timezone, together with the vehicles serving
+// them. Unassigned blocks are listed first, then blocks are ordered by ID.
+// The previous service day is included for blocks running past midnight.
+func (manager *Manager) GetBlockAssignments(ctx context.Context, agencyID string, referenceTime time.Time) ([]BlockAssignment, error) {
+	year, month, day := referenceTime.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, referenceTime.Location())
+	// Wall-clock time since midnight, matching GTFS times.
+	sinceMidnight := time.Duration(referenceTime.Hour())*time.Hour +
+		time.Duration(referenceTime.Minute())*time.Minute +
+		time.Duration(referenceTime.Second())*time.Second
+
+	var assignments []BlockAssignment
+	// Block instance of each trip, as indexes into assignments.
+	tripInstances := make(map[string][]int)
+
+	for i, serviceDate := range []time.Time{today, today.AddDate(0, 0, -1)} {
+		currentTime := int64(sinceMidnight + time.Duration(i)*24*time.Hour)
+
+		serviceIDs, err := manager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, serviceDate.Format("20060102"))
+		if err != nil {
+			return nil, err
+		}
+		if len(serviceIDs) == 0 {
+			continue
+		}
+		trips, err := manager.GtfsDB.Queries.GetBlockTripsForAgency(ctx, gtfsdb.GetBlockTripsForAgencyParams{
+			AgencyID:   agencyID,
+			ServiceIds: serviceIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Rows are grouped by block and ordered by start time.
+		for start := 0; start < len(trips); {
+			end := start + 1
+			for end < len(trips) && trips[end].BlockID == trips[start].BlockID {
+				end++
+			}
+			if assignment, ok := activeBlockAssignment(trips[start:end], currentTime); ok {
+				assignment.ServiceDate = serviceDate
+				for _, trip := range trips[start:end] {
+					tripInstances[trip.ID] = append(tripInstances[trip.ID], len(assignments))
+				}
+				assignments = append(assignments, assignment)
+			}
+			start = end
+		}
+	}
+
+	for _, vehicle := range manager.GetRealTimeVehicles() {
+		if vehicle.ID == nil || vehicle.Trip == nil {
+			continue
+		}
+		for _, idx := range tripInstances[vehicle.Trip.ID.ID] {
+			// A trip ID can run on both service days; the trip descriptor's start
+			// date, when present, tells which instance the vehicle is serving.
+			if vehicle.Trip.ID.HasStartDate && !sameDate(vehicle.Trip.ID.StartDate, assignments[idx].ServiceDate) {
+				continue
+			}
+			assignments[idx].VehicleIDs = append(assignments[idx].VehicleIDs, vehicle.ID.ID)
+			break
+		}
+	}
+
+	for i := range assignments {
+		slices.Sort(assignments[i].VehicleIDs)
+		assignments[i].VehicleIDs = slices.Compact(assignments[i].VehicleIDs)
+	}
+	slices.SortStableFunc(assignments, func(a, b BlockAssignment) int {
+		if c := cmp.Compare(min(len(a.VehicleIDs), 1), min(len(b.VehicleIDs), 1)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.BlockID, b.BlockID); c != 0 {
+			return c
+		}
+		return b.ServiceDate.Compare(a.ServiceDate)
+	})
+	return assignments, nil
+}
+
+// activeBlockAssignment picks the expected trip of a block whose trips are
+// ordered by start time, if the block's span contains currentTime (GTFS
+// nanoseconds since the service day's midnight).
+func activeBlockAssignment(trips []gtfsdb.GetBlockTripsForAgencyRow, currentTime int64) (BlockAssignment, bool) {
+	var spanStart, spanEnd int64
+	var expected *gtfsdb.GetBlockTripsForAgencyRow
+	first := true
+	for i := range trips {
+		trip := &trips[i]
+		if !trip.MinArrivalTime.Valid || !trip.MaxDepartureTime.Valid {
+			continue
+		}
+		if first || trip.MinArrivalTime.Int64 < spanStart {
+			spanStart = trip.MinArrivalTime.Int64
+		}
+		if first || trip.MaxDepartureTime.Int64 > spanEnd {
+			spanEnd = trip.MaxDepartureTime.Int64
+		}
+		first = false
+		if expected == nil && trip.MaxDepartureTime.Int64 >= currentTime {
+			expected = trip
+		}
+	}
+	if first || expected == nil || currentTime < spanStart || currentTime > spanEnd {
+		return BlockAssignment{}, false
+	}
+	return BlockAssignment{
+		BlockID:        expected.BlockID.String,
+		ExpectedTripID: expected.ID,
+		RouteID:        expected.RouteID,
+		InProgress:     expected.MinArrivalTime.Int64 <= currentTime,
+	}, true
+}
+
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
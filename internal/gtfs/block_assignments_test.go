@@ -0,0 +1,92 @@
+package gtfs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+// newBlockAssignmentTestManager sets up two weekday blocks: b1 runs 08:00-09:00
+// and 10:00-11:00, b2 runs 23:30-25:00 and so spills into the next day.
+func newBlockAssignmentTestManager(t *testing.T) *Manager {
+	t.Helper()
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	trip := func(id, block string, start, end time.Duration) string {
+		return fmt.Sprintf("INSERT INTO trips (id, route_id, service_id, block_id, min_arrival_time, max_departure_time) VALUES ('%s', 'r1', 'weekday', '%s', %d, %d)",
+			id, block, int64(start), int64(end))
+	}
+	for _, stmt := range []string{
+		"INSERT INTO agencies (id, name, url, timezone) VALUES ('a1', 'Agency', '', 'UTC')",
+		"INSERT INTO routes (id, agency_id, type) VALUES ('r1', 'a1', 3)",
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('weekday', 1, 1, 1, 1, 1, 0, 0, '20240101', '20241231')",
+		trip("b1-first", "b1", 8*time.Hour, 9*time.Hour),
+		trip("b1-second", "b1", 10*time.Hour, 11*time.Hour),
+		trip("b2-night", "b2", 23*time.Hour+30*time.Minute, 25*time.Hour),
+	} {
+		_, err := client.DB.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+	return newManager(Config{}, client)
+}
+
+func TestGetBlockAssignments(t *testing.T) {
+	manager := newBlockAssignmentTestManager(t)
+	ctx := context.Background()
+	manager.MockAddVehicle("v1", "b1-first", "r1")
+
+	// Monday 08:30: b1 is on its first trip with v1.
+	assignments, err := manager.GetBlockAssignments(ctx, "a1", time.Date(2024, 11, 4, 8, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "b1", assignments[0].BlockID)
+	assert.Equal(t, "b1-first", assignments[0].ExpectedTripID)
+	assert.True(t, assignments[0].InProgress)
+	assert.Equal(t, []string{"v1"}, assignments[0].VehicleIDs)
+
+	// Monday 09:30: b1 lays over before its second trip, which v1 has not
+	// started yet but is still assigned to through the block.
+	assignments, err = manager.GetBlockAssignments(ctx, "a1", time.Date(2024, 11, 4, 9, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "b1-second", assignments[0].ExpectedTripID)
+	assert.False(t, assignments[0].InProgress)
+	assert.Equal(t, []string{"v1"}, assignments[0].VehicleIDs)
+
+	// Tuesday 00:30: b2 of Monday's service day is still running, unassigned.
+	assignments, err = manager.GetBlockAssignments(ctx, "a1", time.Date(2024, 11, 5, 0, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "b2", assignments[0].BlockID)
+	assert.Equal(t, time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC), assignments[0].ServiceDate)
+	assert.True(t, assignments[0].InProgress)
+	assert.Empty(t, assignments[0].VehicleIDs)
+
+	// Saturday: no service.
+	assignments, err = manager.GetBlockAssignments(ctx, "a1", time.Date(2024, 11, 9, 8, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, assignments)
+}
+
+func TestGetBlockAssignments_UnassignedBlocksFirst(t *testing.T) {
+	manager := newBlockAssignmentTestManager(t)
+	_, err := manager.GtfsDB.DB.Exec("INSERT INTO trips (id, route_id, service_id, block_id, min_arrival_time, max_departure_time) VALUES ('b0-trip', 'r1', 'weekday', 'b0', ?, ?)",
+		int64(8*time.Hour), int64(9*time.Hour))
+	require.NoError(t, err)
+	manager.MockAddVehicle("v1", "b0-trip", "r1")
+
+	assignments, err := manager.GetBlockAssignments(context.Background(), "a1", time.Date(2024, 11, 4, 8, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, "b1", assignments[0].BlockID, "unassigned blocks are listed first")
+	assert.Empty(t, assignments[0].VehicleIDs)
+	assert.Equal(t, "b0", assignments[1].BlockID)
+}
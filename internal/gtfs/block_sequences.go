@@ -0,0 +1,253 @@
+package gtfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+// maxCachedServiceDates bounds the block sequence cache. Requests almost
+// always ask about yesterday, today or tomorrow, so a handful of dates covers
+// them while keeping memory flat over a long uptime.
+const maxCachedServiceDates = 4
+
+// blockSequenceCache holds, per service date, the ordered trip sequence of
+// every block resolved so far. All of it derives
+// from static data, so it is only cleared when a static reload changes it.
+type blockSequenceCache struct {
+	mu    sync.Mutex
+	dates map[string]*serviceDateBlocks
+	order []string // service dates, oldest insertion first
+	// Bumped by clear so results computed against replaced data are dropped.
+	generation uint64
+}
+
+type serviceDateBlocks struct {
+	blocks map[string]map[string]int // blockID -> tripID -> sequence
+}
+
+// date returns the cache entry for a service date, evicting the oldest date
+// when the cache is full. The caller must hold c.mu.
+func (c *blockSequenceCache) date(serviceDate string) (*serviceDateBlocks, bool) {
+	if entry, ok := c.dates[serviceDate]; ok {
+		return entry, true
+	}
+	if c.dates == nil {
+		c.dates = make(map[string]*serviceDateBlocks)
+	}
+	if len(c.order) >= maxCachedServiceDates {
+		delete(c.dates, c.order[0])
+		c.order = c.order[1:]
+	}
+	entry := &serviceDateBlocks{blocks: make(map[string]map[string]int)}
+	c.dates[serviceDate] = entry
+	c.order = append(c.order, serviceDate)
+	return entry, false
+}
+
+func (c *blockSequenceCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dates = nil
+	c.order = nil
+	c.generation++
+}
+
+// BlockTripSequence returns the zero-based index of a trip within its block's
+// trips active on serviceDate, ordered by start time, and whether it was
+// resolved. The ordering of a block is computed once per service date and
+// shared by every trip of the block, so arrivals for busy stops do not repeat
+// the same block queries for each row.
+func (manager *Manager) BlockTripSequence(ctx context.Context, tripID string, serviceDate time.Time) (int, bool) {
+	trip, err := manager.GtfsDB.Queries.GetTrip(ctx, tripID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+			manager.config.logger().Warn("BlockTripSequence: failed to get trip",
+				slog.String("trip_id", tripID),
+				slog.String("error", err.Error()))
+		}
+		return 0, false
+	}
+	if !trip.BlockID.Valid {
+		return 0, false
+	}
+
+	sequences, err := manager.blockSequences(ctx, trip.BlockID.String, serviceDate.Format("20060102"))
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		manager.config.logger().Warn("BlockTripSequence: failed to get block trip sequence",
+			slog.String("trip_id", tripID),
+			slog.String("block_id", trip.BlockID.String),
+			slog.String("error", err.Error()))
+		return 0, false
+	}
+	seq, ok := sequences[tripID]
+	return seq, ok
+}
+
+// blockSequences returns the trip sequence of a block on a service date,
+// computing and caching it on first use. Database errors are not cached.
+func (manager *Manager) blockSequences(ctx context.Context, blockID, serviceDate string) (map[string]int, error) {
+	cache := &manager.blockSequenceCache
+	cache.mu.Lock()
+	entry, _ := cache.date(serviceDate)
+	sequences, blockCached := entry.blocks[blockID]
+	generation := cache.generation
+	cache.mu.Unlock()
+	if blockCached {
+		return sequences, nil
+	}
+
+	serviceIDs, err := manager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, serviceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences = make(map[string]int)
+	if len(serviceIDs) > 0 {
+		trips, err := manager.GtfsDB.Queries.GetBlockTripOrderForServices(ctx, gtfsdb.GetBlockTripOrderForServicesParams{
+			BlockID:    blockID,
+			ServiceIds: serviceIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, trip := range trips {
+			sequences[trip.TripID] = i
+		}
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.generation != generation {
+		return sequences, nil
+	}
+	// The date may have been evicted meanwhile; storing into a fresh entry is
+	// harmless since the data would be recomputed identically.
+	entry, _ = cache.date(serviceDate)
+	entry.blocks[blockID] = sequences
+	return sequences, nil
+}
+
+// maxCrossDayLayover bounds the gap between a block's last trip of one service
+// day and its first trip of the next for the two to be linked. Larger gaps are
+// an overnight lay-up rather than a vehicle continuing in service.
+const maxCrossDayLayover = time.Hour
+
+// BlockNeighbors returns the trips run by the same vehicle immediately before
+// and after tripID on serviceDate, following the precomputed block order. Trips
+// of every service active on the date take part, and a block that continues
+// past the end of the service day is linked to its first trip on the following
+// day (and symmetrically for the previous day). Empty IDs mean there is no such
+// trip, including when tripID has no block or does not run on serviceDate.
+func (manager *Manager) BlockNeighbors(ctx context.Context, tripID string, serviceDate time.Time) (previousTripID, nextTripID string, err error) {
+	queries := manager.GtfsDB.Queries
+	order, err := queries.GetBlockTripOrder(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+
+	serviceIDs, err := queries.GetActiveServiceIDsForDate(ctx, serviceDate.Format("20060102"))
+	if err != nil {
+		return "", "", err
+	}
+	if !slices.Contains(serviceIDs, order.ServiceID) {
+		return "", "", nil
+	}
+
+	previous, err := queries.GetPreviousTripInBlock(ctx, gtfsdb.GetPreviousTripInBlockParams{
+		BlockID:       order.BlockID,
+		BlockSequence: order.BlockSequence,
+		ServiceIds:    serviceIDs,
+	})
+	switch {
+	case err == nil:
+		previousTripID = previous.TripID
+	case errors.Is(err, sql.ErrNoRows):
+		previousTripID, err = manager.crossDayBlockNeighbor(ctx, order, serviceDate.AddDate(0, 0, -1), false)
+		if err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", err
+	}
+
+	next, err := queries.GetNextTripInBlock(ctx, gtfsdb.GetNextTripInBlockParams{
+		BlockID:       order.BlockID,
+		BlockSequence: order.BlockSequence,
+		ServiceIds:    serviceIDs,
+	})
+	switch {
+	case err == nil:
+		nextTripID = next.TripID
+	case errors.Is(err, sql.ErrNoRows):
+		nextTripID, err = manager.crossDayBlockNeighbor(ctx, order, serviceDate.AddDate(0, 0, 1), true)
+		if err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", err
+	}
+
+	return previousTripID, nextTripID, nil
+}
+
+// crossDayBlockNeighbor returns the block's first trip on the following service
+// day (after is true) or its last trip on the preceding one, provided it
+// connects to trip within maxCrossDayLayover once both are expressed relative
+// to the same midnight.
+func (manager *Manager) crossDayBlockNeighbor(ctx context.Context, trip gtfsdb.BlockTripOrder, serviceDate time.Time, after bool) (string, error) {
+	queries := manager.GtfsDB.Queries
+	serviceIDs, err := queries.GetActiveServiceIDsForDate(ctx, serviceDate.Format("20060102"))
+	if err != nil || len(serviceIDs) == 0 {
+		return "", err
+	}
+
+	var candidate gtfsdb.BlockTripOrder
+	if after {
+		candidate, err = queries.GetNextTripInBlock(ctx, gtfsdb.GetNextTripInBlockParams{
+			BlockID:       trip.BlockID,
+			BlockSequence: -1,
+			ServiceIds:    serviceIDs,
+		})
+	} else {
+		candidate, err = queries.GetPreviousTripInBlock(ctx, gtfsdb.GetPreviousTripInBlockParams{
+			BlockID:       trip.BlockID,
+			BlockSequence: math.MaxInt64,
+			ServiceIds:    serviceIDs,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	if candidate.TripID == trip.TripID {
+		return "", nil
+	}
+
+	day := int64(24 * time.Hour)
+	var layover int64
+	if after {
+		layover = candidate.StartTime + day - trip.EndTime
+	} else {
+		layover = trip.StartTime - (candidate.EndTime - day)
+	}
+	if layover < 0 || layover > int64(maxCrossDayLayover) {
+		return "", nil
+	}
+	return candidate.TripID, nil
+}
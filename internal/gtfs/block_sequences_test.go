@@ -0,0 +1,179 @@
+package gtfs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+func newBlockSequenceTestManager(t *testing.T) *Manager {
+	t.Helper()
+	return newBlockTestManager(t,
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('weekday', 1, 1, 1, 1, 1, 0, 0, '20240101', '20241231')",
+		"INSERT INTO trips (id, route_id, service_id, block_id, min_arrival_time, max_departure_time) VALUES ('late', 'r1', 'weekday', 'b1', 36000, 37000)",
+		"INSERT INTO trips (id, route_id, service_id, block_id, min_arrival_time, max_departure_time) VALUES ('early', 'r1', 'weekday', 'b1', 28800, 29800)",
+		"INSERT INTO trips (id, route_id, service_id, min_arrival_time, max_departure_time) VALUES ('no-block', 'r1', 'weekday', 28800, 29800)",
+	)
+}
+
+// newBlockTestManager seeds an agency and route, runs stmts and builds the
+// block trip order from the resulting trips.
+func newBlockTestManager(t *testing.T, stmts ...string) *Manager {
+	t.Helper()
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	stmts = append([]string{
+		"INSERT INTO agencies (id, name, url, timezone) VALUES ('a1', 'Agency', '', 'UTC')",
+		"INSERT INTO routes (id, agency_id, type) VALUES ('r1', 'a1', 3)",
+	}, stmts...)
+	for _, stmt := range stmts {
+		_, err := client.DB.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+	require.NoError(t, client.Queries.BuildBlockTripOrder(context.Background()))
+	return newManager(Config{}, client)
+}
+
+func TestBlockTripSequence(t *testing.T) {
+	manager := newBlockSequenceTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	seq, ok := manager.BlockTripSequence(ctx, "early", monday)
+	assert.True(t, ok)
+	assert.Equal(t, 0, seq)
+	seq, ok = manager.BlockTripSequence(ctx, "late", monday)
+	assert.True(t, ok)
+	assert.Equal(t, 1, seq)
+
+	_, ok = manager.BlockTripSequence(ctx, "no-block", monday)
+	assert.False(t, ok, "trips without a block have no sequence")
+	_, ok = manager.BlockTripSequence(ctx, "missing", monday)
+	assert.False(t, ok)
+	_, ok = manager.BlockTripSequence(ctx, "early", monday.AddDate(0, 0, 5))
+	assert.False(t, ok, "the trip does not run on Saturday")
+}
+
+func TestBlockTripSequence_CachesBlockOrderingUntilCleared(t *testing.T) {
+	manager := newBlockSequenceTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	seq, ok := manager.BlockTripSequence(ctx, "late", monday)
+	require.True(t, ok)
+	require.Equal(t, 1, seq)
+
+	// Removing the earlier trip's block does not change the cached ordering.
+	_, err := manager.GtfsDB.DB.Exec("UPDATE trips SET block_id = NULL WHERE id = 'early'")
+	require.NoError(t, err)
+	require.NoError(t, manager.GtfsDB.Queries.ClearBlockTripOrder(context.Background()))
+	require.NoError(t, manager.GtfsDB.Queries.BuildBlockTripOrder(context.Background()))
+	seq, _ = manager.BlockTripSequence(ctx, "late", monday)
+	assert.Equal(t, 1, seq)
+
+	manager.blockSequenceCache.clear()
+	seq, ok = manager.BlockTripSequence(ctx, "late", monday)
+	assert.True(t, ok)
+	assert.Equal(t, 0, seq, "a cleared cache reflects the current data")
+}
+
+func TestBlockSequenceCache_EvictsOldestServiceDate(t *testing.T) {
+	var cache blockSequenceCache
+	for i := range maxCachedServiceDates + 1 {
+		entry, _ := cache.date(fmt.Sprintf("202411%02d", i+1))
+		entry.blocks["b1"] = map[string]int{"t": i}
+	}
+
+	assert.Len(t, cache.dates, maxCachedServiceDates)
+	assert.NotContains(t, cache.dates, "20241101")
+	entry, ok := cache.date(fmt.Sprintf("202411%02d", maxCachedServiceDates+1))
+	assert.True(t, ok)
+	assert.Equal(t, maxCachedServiceDates, entry.blocks["b1"]["t"])
+}
+
+func tripInsert(id, serviceID, blockID string, start, end time.Duration) string {
+	return fmt.Sprintf("INSERT INTO trips (id, route_id, service_id, block_id, min_arrival_time, max_departure_time) VALUES ('%s', 'r1', '%s', '%s', %d, %d)",
+		id, serviceID, blockID, int64(start), int64(end))
+}
+
+func TestBlockNeighbors_LinksTripsAcrossServices(t *testing.T) {
+	manager := newBlockTestManager(t,
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('weekday', 1, 1, 1, 1, 1, 0, 0, '20240101', '20241231')",
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('school', 1, 1, 1, 1, 1, 0, 0, '20240101', '20241231')",
+		tripInsert("w1", "weekday", "b1", 8*time.Hour, 9*time.Hour),
+		tripInsert("s1", "school", "b1", 9*time.Hour+10*time.Minute, 10*time.Hour),
+		tripInsert("w2", "weekday", "b1", 10*time.Hour+10*time.Minute, 11*time.Hour),
+	)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	prev, next, err := manager.BlockNeighbors(ctx, "s1", monday)
+	require.NoError(t, err)
+	assert.Equal(t, "w1", prev)
+	assert.Equal(t, "w2", next)
+
+	prev, next, err = manager.BlockNeighbors(ctx, "w1", monday)
+	require.NoError(t, err)
+	assert.Empty(t, prev)
+	assert.Equal(t, "s1", next, "the school trip sits between the weekday trips")
+
+	prev, next, err = manager.BlockNeighbors(ctx, "s1", monday.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Empty(t, prev, "the trip does not run on Saturday")
+	assert.Empty(t, next)
+
+	prev, next, err = manager.BlockNeighbors(ctx, "missing", monday)
+	require.NoError(t, err)
+	assert.Empty(t, prev)
+	assert.Empty(t, next)
+}
+
+func TestBlockNeighbors_LinksAcrossServiceDays(t *testing.T) {
+	manager := newBlockTestManager(t,
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('weekday', 1, 1, 1, 1, 1, 0, 0, '20240101', '20241231')",
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('saturday', 0, 0, 0, 0, 0, 1, 0, '20240101', '20241231')",
+		tripInsert("evening", "weekday", "night", 20*time.Hour, 21*time.Hour),
+		tripInsert("owl", "weekday", "night", 23*time.Hour, 24*time.Hour+30*time.Minute),
+		tripInsert("dawn", "saturday", "night", 45*time.Minute, 90*time.Minute),
+		tripInsert("noon", "saturday", "night", 12*time.Hour, 13*time.Hour),
+	)
+	ctx := context.Background()
+	friday := time.Date(2024, 11, 8, 0, 0, 0, 0, time.UTC)
+	saturday := friday.AddDate(0, 0, 1)
+
+	prev, next, err := manager.BlockNeighbors(ctx, "owl", friday)
+	require.NoError(t, err)
+	assert.Equal(t, "evening", prev)
+	assert.Equal(t, "dawn", next, "Friday's owl continues into Saturday's first trip")
+
+	prev, next, err = manager.BlockNeighbors(ctx, "dawn", saturday)
+	require.NoError(t, err)
+	assert.Equal(t, "owl", prev)
+	assert.Equal(t, "noon", next)
+
+	_, next, err = manager.BlockNeighbors(ctx, "owl", friday.AddDate(0, 0, -4))
+	require.NoError(t, err)
+	assert.Empty(t, next, "the Saturday service does not run on Tuesday")
+}
+
+func TestBlockNeighbors_IgnoresOvernightLayUp(t *testing.T) {
+	manager := newBlockTestManager(t,
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('daily', 1, 1, 1, 1, 1, 1, 1, '20240101', '20241231')",
+		tripInsert("am", "daily", "b1", 6*time.Hour, 7*time.Hour),
+		tripInsert("pm", "daily", "b1", 17*time.Hour, 18*time.Hour),
+	)
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	prev, next, err := manager.BlockNeighbors(context.Background(), "pm", monday)
+	require.NoError(t, err)
+	assert.Equal(t, "am", prev)
+	assert.Empty(t, next, "the next morning's trip is not a continuation")
+}
@@ -1,6 +1,7 @@
 package gtfs
 
 import (
+	"log/slog"
 	"strings"
 	"time"
 
@@ -20,17 +21,76 @@ type RTFeedConfig struct {
 	Enabled             bool
 }
 
+// StaticFeedConfig configures a static feed combined with the primary one.
+type StaticFeedConfig struct {
+	URL             string
+	AuthHeaderKey   string
+	AuthHeaderValue string
+	EnableGTFSTidy  bool
+}
+
 // Config holds GTFS configuration for the manager.
 type Config struct {
 	GtfsURL               string
 	StaticAuthHeaderKey   string
 	StaticAuthHeaderValue string
+	AdditionalStaticFeeds []StaticFeedConfig // Combined with GtfsURL's feed at load time
 	RTFeeds               []RTFeedConfig
 	GTFSDataPath          string
+	DataEncryptionKey     string // SQLCipher key for GTFSDataPath; empty leaves it unencrypted
 	Env                   appconf.Environment
 	EnableGTFSTidy        bool
+	MirrorDir             string        // When set, last good feed downloads are mirrored here for offline boot
+	AmenitiesPath         string        // Optional CSV or GeoJSON file of stop amenities (parking, bike racks, ...)
+	PostImportProcessors  []string      // Names of registered PostImportProcessors to run after each import, in order
+	SlowQueryThreshold    time.Duration // Queries at least this slow are logged; zero disables
+	// Reloads removing more than this percentage of trips or stops are refused
+	// until approved; zero disables the guard.
+	ReloadGuardMaxDropPercent float64
+	// Feeds without transfers.txt get transfers generated between stops at
+	// most this many meters apart; zero disables generation.
+	TransferRadiusMeters float64
+	// Realized arrivals are recorded from the realtime feeds and kept this
+	// many days to derive historical occupancy and typical delays; zero
+	// disables recording.
+	ArrivalHistoryDays int
+	// Schedule deviations of the trips in the realtime feeds are sampled
+	// this often for deviation history; zero disables sampling.
+	DeviationSampleInterval time.Duration
+	// This many datasets replaced by reloads are kept next to GTFSDataPath
+	// for requests that ask for an earlier dataset version; zero keeps none.
+	DatasetSnapshots int
+	// Feeds whose service ends within this many days log escalating warnings
+	// and are reported as expiring soon; zero uses the default of 7.
+	FeedExpiryWarningDays int
+	// Agencies whose trips are served schedule-only: their trip updates and
+	// vehicle positions are dropped while their feeds keep polling.
+	RealtimeDisabledAgencies []string
+	// When set, the manager does not reload the static feed daily on its own
+	// and the caller schedules ReloadStatic instead.
+	ExternalStaticRefresh bool
 	StartupRetries        []time.Duration
 	Metrics               *metrics.Metrics
+	Logger                *slog.Logger // Defaults to slog.Default() when nil
+}
+
+// logger returns the injected logger, falling back to the process default.
+func (config Config) logger() *slog.Logger {
+	if config.Logger != nil {
+		return config.Logger
+	}
+	return slog.Default()
+}
+
+// defaultFeedExpiryWarningDays is the warning window used when
+// FeedExpiryWarningDays is unset.
+const defaultFeedExpiryWarningDays = 7
+
+func (config Config) feedExpiryWarningDays() int {
+	if config.FeedExpiryWarningDays > 0 {
+		return config.FeedExpiryWarningDays
+	}
+	return defaultFeedExpiryWarningDays
 }
 
 // enabledFeeds returns only the enabled feeds that have at least one URL configured.
@@ -0,0 +1,186 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/internal/logging"
+)
+
+// ErrUnknownDatasetVersion is returned by DatasetSnapshot for versions that
+// are not retained.
+var ErrUnknownDatasetVersion = errors.New("unknown dataset version")
+
+const datasetSnapshotExt = ".db"
+
+// datasetSnapshots holds the retained datasets opened for reading, keyed by
+// version.
+type datasetSnapshots struct {
+	mu   sync.Mutex
+	open map[string]*Manager
+}
+
+// datasetSnapshotDir is where the datasets replaced by reloads are kept, or
+// "" when none are retained. Snapshots need a database file to copy.
+func (manager *Manager) datasetSnapshotDir() string {
+	path := manager.config.GTFSDataPath
+	if manager.config.DatasetSnapshots <= 0 || path == "" || strings.HasPrefix(path, ":memory:") {
+		return ""
+	}
+	return path + ".snapshots"
+}
+
+// retainDataset copies the dataset being served into the snapshot directory
+// before a reload replaces it with the dataset hashed newHash, then drops the
+// oldest snapshots beyond DatasetSnapshots. A snapshot that cannot be taken
+// is logged and does not hold up the reload.
+func (manager *Manager) retainDataset(ctx context.Context, newHash string, logger *slog.Logger) {
+	dir := manager.datasetSnapshotDir()
+	if dir == "" {
+		return
+	}
+	version := manager.GetSystemETag(ctx)
+	if version == "" || version == newHash {
+		return
+	}
+	if err := manager.snapshotDataset(ctx, dir, version); err != nil {
+		logging.LogError(logger, "Failed to retain dataset snapshot", err, slog.String("version", version))
+		return
+	}
+	logging.LogOperation(logger, "dataset_snapshot_retained", slog.String("version", version))
+	manager.pruneDatasetSnapshots(dir, logger)
+}
+
+func (manager *Manager) snapshotDataset(ctx context.Context, dir, version string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, version+datasetSnapshotExt)
+	if _, err := os.Stat(path); err == nil {
+		// The dataset came back after a rollback; keep the existing copy as
+		// the newest.
+		now := time.Now()
+		return os.Chtimes(path, now, now)
+	}
+	tmpPath := path + ".tmp"
+	_ = os.Remove(tmpPath)
+	if _, err := manager.GtfsDB.DB.ExecContext(ctx, "VACUUM INTO ?", tmpPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy dataset: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// pruneDatasetSnapshots removes the snapshots beyond the newest
+// DatasetSnapshots, closing any that are open.
+func (manager *Manager) pruneDatasetSnapshots(dir string, logger *slog.Logger) {
+	versions := listDatasetSnapshots(dir)
+	if len(versions) <= manager.config.DatasetSnapshots {
+		return
+	}
+	s := &manager.datasetSnapshots
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, version := range versions[manager.config.DatasetSnapshots:] {
+		if open := s.open[version]; open != nil {
+			open.Shutdown()
+			delete(s.open, version)
+		}
+		if err := os.Remove(filepath.Join(dir, version+datasetSnapshotExt)); err != nil {
+			logging.LogError(logger, "Failed to remove dataset snapshot", err, slog.String("version", version))
+		}
+	}
+}
+
+// listDatasetSnapshots returns the versions in dir, newest first.
+func listDatasetSnapshots(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type snapshot struct {
+		version string
+		modTime time.Time
+	}
+	var snapshots []snapshot
+	for _, entry := range entries {
+		version, ok := strings.CutSuffix(entry.Name(), datasetSnapshotExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{version: version, modTime: info.ModTime()})
+	}
+	slices.SortFunc(snapshots, func(a, b snapshot) int {
+		return b.modTime.Compare(a.modTime)
+	})
+	versions := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		versions[i] = s.version
+	}
+	return versions
+}
+
+// RetainedDatasetVersions returns the versions of the datasets kept from
+// earlier reloads, newest first. The dataset being served is not included;
+// its version is GetSystemETag.
+func (manager *Manager) RetainedDatasetVersions() []string {
+	dir := manager.datasetSnapshotDir()
+	if dir == "" {
+		return nil
+	}
+	return listDatasetSnapshots(dir)
+}
+
+// DatasetSnapshot returns a manager serving the retained dataset version,
+// opening it on first use. It has no realtime data and does not refresh.
+// The manager is closed when the snapshot is pruned or this manager shuts
+// down, so callers must not keep it.
+func (manager *Manager) DatasetSnapshot(ctx context.Context, version string) (*Manager, error) {
+	if !slices.Contains(manager.RetainedDatasetVersions(), version) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDatasetVersion, version)
+	}
+
+	s := &manager.datasetSnapshots
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if open := s.open[version]; open != nil {
+		return open, nil
+	}
+
+	config := manager.config
+	config.GTFSDataPath = filepath.Join(manager.datasetSnapshotDir(), version+datasetSnapshotExt)
+	config.DatasetSnapshots = 0
+	config.RTFeeds = nil
+	snapshot, err := OpenGTFSManager(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if s.open == nil {
+		s.open = make(map[string]*Manager)
+	}
+	s.open[version] = snapshot
+	return snapshot, nil
+}
+
+// closeDatasetSnapshots shuts down the open snapshots.
+func (manager *Manager) closeDatasetSnapshots() {
+	s := &manager.datasetSnapshots
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for version, open := range s.open {
+		open.Shutdown()
+		delete(s.open, version)
+	}
+}
@@ -0,0 +1,66 @@
+package gtfs
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func TestDatasetSnapshots(t *testing.T) {
+	ctx := context.Background()
+	manager, err := InitGTFSManager(ctx, Config{
+		GtfsURL:          models.GetFixturePath(t, "raba.zip"),
+		GTFSDataPath:     filepath.Join(t.TempDir(), "gtfs.db"),
+		Env:              appconf.Development,
+		DatasetSnapshots: 1,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	raba := manager.GetSystemETag(ctx)
+	require.NotEmpty(t, raba)
+	assert.Empty(t, manager.RetainedDatasetVersions())
+
+	manager.SetGtfsURL(models.GetFixturePath(t, "gtfs.zip"))
+	_, err = manager.ReloadStatic(ctx)
+	require.NoError(t, err)
+	other := manager.GetSystemETag(ctx)
+	require.NotEqual(t, raba, other)
+	assert.Equal(t, []string{raba}, manager.RetainedDatasetVersions())
+
+	snapshot, err := manager.DatasetSnapshot(ctx, raba)
+	require.NoError(t, err)
+	assert.Equal(t, raba, snapshot.GetSystemETag(ctx))
+	agencies, err := snapshot.GetAgencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, agencies, 1)
+	assert.Equal(t, "25", agencies[0].ID, "the snapshot serves the replaced dataset")
+
+	again, err := manager.DatasetSnapshot(ctx, raba)
+	require.NoError(t, err)
+	assert.Same(t, snapshot, again, "open snapshots are reused")
+
+	_, err = manager.DatasetSnapshot(ctx, "../gtfs")
+	assert.ErrorIs(t, err, ErrUnknownDatasetVersion)
+
+	// Going back to raba retains the other dataset and prunes raba's copy,
+	// as only one snapshot is kept.
+	manager.SetGtfsURL(models.GetFixturePath(t, "raba.zip"))
+	_, err = manager.ReloadStatic(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, raba, manager.GetSystemETag(ctx))
+	assert.Equal(t, []string{other}, manager.RetainedDatasetVersions())
+	_, err = manager.DatasetSnapshot(ctx, raba)
+	assert.ErrorIs(t, err, ErrUnknownDatasetVersion)
+}
+
+func TestDatasetSnapshots_DisabledInMemory(t *testing.T) {
+	manager := &Manager{config: Config{GTFSDataPath: ":memory:", DatasetSnapshots: 3}}
+	assert.Empty(t, manager.datasetSnapshotDir())
+	assert.Nil(t, manager.RetainedDatasetVersions())
+}
@@ -0,0 +1,144 @@
+package gtfs
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+)
+
+const (
+	// deviationHistorySize bounds the samples kept per trip; older samples
+	// are overwritten.
+	deviationHistorySize = 120
+	// deviationHistoryIdle is how long a trip can go unsampled before its
+	// history is dropped. A trip runs once a service day, so the next run
+	// starts a new history.
+	deviationHistoryIdle = 2 * time.Hour
+)
+
+// DeviationSample is the schedule deviation of a trip observed at one time.
+type DeviationSample struct {
+	Time time.Time
+	// Positive when late, negative when early.
+	Deviation time.Duration
+	VehicleID string
+}
+
+// deviationHistory keeps a bounded ring of schedule deviation samples for
+// each trip in the realtime feeds, sampled every DeviationSampleInterval.
+type deviationHistory struct {
+	mu    sync.Mutex
+	trips map[string]*deviationRing
+}
+
+type deviationRing struct {
+	samples []DeviationSample
+	// Index of the oldest sample once the ring is full.
+	start int
+}
+
+func (r *deviationRing) add(sample DeviationSample) {
+	if len(r.samples) < deviationHistorySize {
+		r.samples = append(r.samples, sample)
+		return
+	}
+	r.samples[r.start] = sample
+	r.start = (r.start + 1) % deviationHistorySize
+}
+
+func (r *deviationRing) last() DeviationSample {
+	return r.samples[(r.start+len(r.samples)-1)%len(r.samples)]
+}
+
+// ordered returns the samples oldest first.
+func (r *deviationRing) ordered() []DeviationSample {
+	return slices.Concat(r.samples[r.start:], r.samples[:r.start])
+}
+
+// TripScheduleDeviation returns how late trip is running (negative when
+// early) and whether its update says so. The trip-level delay is preferred;
+// without one the first stop-level arrival or departure delay is used.
+func TripScheduleDeviation(trip gtfs.Trip) (time.Duration, bool) {
+	if trip.Delay != nil {
+		return *trip.Delay, true
+	}
+	for _, stu := range trip.StopTimeUpdates {
+		if stu.Arrival != nil && stu.Arrival.Delay != nil {
+			return *stu.Arrival.Delay, true
+		}
+		if stu.Departure != nil && stu.Departure.Delay != nil {
+			return *stu.Departure.Delay, true
+		}
+	}
+	return 0, false
+}
+
+// DeviationHistoryEnabled reports whether schedule deviations are sampled.
+func (manager *Manager) DeviationHistoryEnabled() bool {
+	return manager.config.DeviationSampleInterval > 0
+}
+
+// sampleScheduleDeviations records the current deviation of each trip in the
+// realtime feeds whose last sample is at least DeviationSampleInterval old.
+// It runs after each realtime update, so samples are never closer together
+// than the feed's refresh interval.
+func (manager *Manager) sampleScheduleDeviations(now time.Time) {
+	if !manager.DeviationHistoryEnabled() {
+		return
+	}
+	interval := manager.config.DeviationSampleInterval
+	trips := manager.GetRealTimeTrips()
+	vehicles := make(map[string]string)
+	for _, vehicle := range manager.GetRealTimeVehicles() {
+		if vehicle.Trip != nil && vehicle.ID != nil {
+			vehicles[vehicle.Trip.ID.ID] = vehicle.ID.ID
+		}
+	}
+
+	h := &manager.deviationHistory
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.trips == nil {
+		h.trips = make(map[string]*deviationRing)
+	}
+
+	for _, trip := range trips {
+		deviation, ok := TripScheduleDeviation(trip)
+		if !ok {
+			continue
+		}
+		ring := h.trips[trip.ID.ID]
+		if ring == nil {
+			ring = &deviationRing{}
+			h.trips[trip.ID.ID] = ring
+		} else if now.Sub(ring.last().Time) < interval {
+			continue
+		}
+		vehicleID := vehicles[trip.ID.ID]
+		if vehicleID == "" && trip.Vehicle != nil && trip.Vehicle.ID != nil {
+			vehicleID = trip.Vehicle.ID.ID
+		}
+		ring.add(DeviationSample{Time: now, Deviation: deviation, VehicleID: vehicleID})
+	}
+
+	for tripID, ring := range h.trips {
+		if now.Sub(ring.last().Time) > deviationHistoryIdle {
+			delete(h.trips, tripID)
+		}
+	}
+}
+
+// ScheduleDeviationHistory returns the recent deviation samples of a trip,
+// oldest first, or nil when it has none or sampling is disabled.
+func (manager *Manager) ScheduleDeviationHistory(tripID string) []DeviationSample {
+	h := &manager.deviationHistory
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ring := h.trips[tripID]
+	if ring == nil {
+		return nil
+	}
+	return ring.ordered()
+}
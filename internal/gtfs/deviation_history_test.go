@@ -0,0 +1,79 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func delayedTrip(tripID string, delay time.Duration) gtfs.Trip {
+	return gtfs.Trip{ID: gtfs.TripID{ID: tripID}, Delay: &delay}
+}
+
+func TestSampleScheduleDeviations_HonorsInterval(t *testing.T) {
+	manager := &Manager{config: Config{DeviationSampleInterval: time.Minute}}
+	manager.realTimeVehicles = []gtfs.Vehicle{{
+		ID:   &gtfs.VehicleID{ID: "v1"},
+		Trip: &gtfs.Trip{ID: gtfs.TripID{ID: "t1"}},
+	}}
+	start := time.Date(2024, 11, 4, 8, 0, 0, 0, time.UTC)
+
+	manager.realTimeTrips = []gtfs.Trip{delayedTrip("t1", 30*time.Second), {ID: gtfs.TripID{ID: "t2"}}}
+	manager.sampleScheduleDeviations(start)
+	manager.realTimeTrips = []gtfs.Trip{delayedTrip("t1", 45*time.Second)}
+	manager.sampleScheduleDeviations(start.Add(30 * time.Second))
+	manager.sampleScheduleDeviations(start.Add(time.Minute))
+
+	assert.Equal(t, []DeviationSample{
+		{Time: start, Deviation: 30 * time.Second, VehicleID: "v1"},
+		{Time: start.Add(time.Minute), Deviation: 45 * time.Second, VehicleID: "v1"},
+	}, manager.ScheduleDeviationHistory("t1"))
+	assert.Nil(t, manager.ScheduleDeviationHistory("t2"), "trips without a delay are not sampled")
+}
+
+func TestSampleScheduleDeviations_BoundedAndPruned(t *testing.T) {
+	manager := &Manager{config: Config{DeviationSampleInterval: time.Second}}
+	start := time.Date(2024, 11, 4, 8, 0, 0, 0, time.UTC)
+
+	for i := range deviationHistorySize + 5 {
+		manager.realTimeTrips = []gtfs.Trip{delayedTrip("t1", time.Duration(i)*time.Second)}
+		manager.sampleScheduleDeviations(start.Add(time.Duration(i) * time.Second))
+	}
+	history := manager.ScheduleDeviationHistory("t1")
+	require.Len(t, history, deviationHistorySize)
+	assert.Equal(t, 5*time.Second, history[0].Deviation, "the oldest samples are overwritten")
+	assert.Equal(t, time.Duration(deviationHistorySize+4)*time.Second, history[len(history)-1].Deviation)
+
+	manager.realTimeTrips = nil
+	manager.sampleScheduleDeviations(start.Add(deviationHistoryIdle + 5*time.Minute))
+	assert.Nil(t, manager.ScheduleDeviationHistory("t1"))
+}
+
+func TestSampleScheduleDeviations_Disabled(t *testing.T) {
+	manager := &Manager{}
+	manager.realTimeTrips = []gtfs.Trip{delayedTrip("t1", time.Minute)}
+
+	manager.sampleScheduleDeviations(time.Now())
+
+	assert.False(t, manager.DeviationHistoryEnabled())
+	assert.Nil(t, manager.ScheduleDeviationHistory("t1"))
+}
+
+func TestTripScheduleDeviation(t *testing.T) {
+	late := 2 * time.Minute
+	early := -30 * time.Second
+
+	deviation, ok := TripScheduleDeviation(gtfs.Trip{Delay: &late, StopTimeUpdates: []gtfs.StopTimeUpdate{{Arrival: &gtfs.StopTimeEvent{Delay: &early}}}})
+	assert.True(t, ok)
+	assert.Equal(t, late, deviation, "the trip-level delay wins")
+
+	deviation, ok = TripScheduleDeviation(gtfs.Trip{StopTimeUpdates: []gtfs.StopTimeUpdate{{}, {Departure: &gtfs.StopTimeEvent{Delay: &early}}}})
+	assert.True(t, ok)
+	assert.Equal(t, early, deviation)
+
+	_, ok = TripScheduleDeviation(gtfs.Trip{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package gtfs
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// feedPoller is the polling goroutine of one realtime feed.
+type feedPoller struct {
+	config RTFeedConfig
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when the goroutine has exited
+}
+
+// startFeedPollerLocked starts polling feedCfg. The caller must hold
+// feedPollersMu.
+func (manager *Manager) startFeedPollerLocked(feedCfg RTFeedConfig) {
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := &feedPoller{config: feedCfg, cancel: cancel, done: make(chan struct{})}
+	manager.feedPollers[feedCfg.ID] = poller
+
+	manager.wg.Add(1)
+	go func() {
+		defer close(poller.done)
+		manager.pollFeed(ctx, feedCfg)
+	}()
+}
+
+// UpdateRealtimeFeeds replaces the polled realtime feeds with feeds, as when
+// the configuration is reloaded. Pollers of unchanged feeds keep running. Feeds
+// that were removed, disabled or changed are stopped and their data cleared,
+// and new or changed feeds start polling after one refresh interval. It does
+// nothing once the manager is shutting down.
+func (manager *Manager) UpdateRealtimeFeeds(feeds []RTFeedConfig) {
+	enabled := Config{RTFeeds: feeds}.enabledFeeds()
+	wanted := make(map[string]RTFeedConfig, len(enabled))
+	for _, feedCfg := range enabled {
+		wanted[feedCfg.ID] = feedCfg
+	}
+
+	manager.feedPollersMu.Lock()
+	defer manager.feedPollersMu.Unlock()
+	select {
+	case <-manager.shutdownChan:
+		return
+	default:
+	}
+
+	logger := manager.config.logger().With(slog.String("component", "gtfs_realtime_updater"))
+	for id, poller := range manager.feedPollers {
+		if feedCfg, ok := wanted[id]; ok && reflect.DeepEqual(feedCfg, poller.config) {
+			continue
+		}
+		poller.cancel()
+		<-poller.done
+		delete(manager.feedPollers, id)
+		manager.clearFeedData(id)
+		logger.Info("stopped realtime feed after configuration change", slog.String("feed", id))
+	}
+
+	manager.realTimeMutex.Lock()
+	manager.feedAgencyFilter = feedAgencyFilters(feeds)
+	manager.realTimeMutex.Unlock()
+	manager.rtFeeds = enabled
+
+	for _, feedCfg := range enabled {
+		if _, running := manager.feedPollers[feedCfg.ID]; !running {
+			manager.startFeedPollerLocked(feedCfg)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateRealtimeFeeds(t *testing.T) {
+	kept := RTFeedConfig{ID: "kept", TripUpdatesURL: "http://127.0.0.1:1/tu", RefreshInterval: 60, Enabled: true}
+	changed := RTFeedConfig{ID: "changed", TripUpdatesURL: "http://127.0.0.1:1/old", RefreshInterval: 60, Enabled: true}
+	removed := RTFeedConfig{ID: "removed", VehiclePositionsURL: "http://127.0.0.1:1/vp", RefreshInterval: 60, Enabled: true}
+
+	manager := newManager(Config{RTFeeds: []RTFeedConfig{kept, changed, removed}}, nil)
+	t.Cleanup(manager.Shutdown)
+	manager.feedPollersMu.Lock()
+	for _, feedCfg := range manager.rtFeeds {
+		manager.startFeedPollerLocked(feedCfg)
+	}
+	keptPoller := manager.feedPollers["kept"]
+	manager.feedPollersMu.Unlock()
+
+	manager.realTimeMutex.Lock()
+	manager.feedTrips["removed"] = []gtfs.Trip{{ID: gtfs.TripID{ID: "t1"}}}
+	manager.realTimeMutex.Unlock()
+	manager.SetFeedUpdateTimeForTest("removed", time.Now())
+
+	changed.TripUpdatesURL = "http://127.0.0.1:1/new"
+	changed.AgencyIDs = []string{"a1"}
+	added := RTFeedConfig{ID: "added", ServiceAlertsURL: "http://127.0.0.1:1/sa", RefreshInterval: 60, Enabled: true}
+	manager.UpdateRealtimeFeeds([]RTFeedConfig{kept, changed, added})
+
+	manager.feedPollersMu.Lock()
+	pollers := manager.feedPollers
+	assert.Len(t, pollers, 3)
+	assert.Same(t, keptPoller, pollers["kept"], "unchanged feeds keep polling")
+	assert.Equal(t, "http://127.0.0.1:1/new", pollers["changed"].config.TripUpdatesURL)
+	assert.Contains(t, pollers, "added")
+	assert.NotContains(t, pollers, "removed")
+	manager.feedPollersMu.Unlock()
+
+	manager.realTimeMutex.RLock()
+	assert.Empty(t, manager.feedTrips["removed"], "a removed feed's data is cleared")
+	assert.Equal(t, map[string]bool{"a1": true}, manager.feedAgencyFilter["changed"])
+	manager.realTimeMutex.RUnlock()
+
+	var ids []string
+	for _, status := range manager.RealtimeFeedStatuses(time.Now()) {
+		ids = append(ids, status.ID)
+	}
+	assert.Equal(t, []string{"kept", "changed", "added"}, ids)
+}
+
+func TestUpdateRealtimeFeeds_AfterShutdown(t *testing.T) {
+	manager := newManager(Config{}, nil)
+	manager.Shutdown()
+
+	manager.UpdateRealtimeFeeds([]RTFeedConfig{{ID: "late", TripUpdatesURL: "http://127.0.0.1:1/tu", Enabled: true}})
+
+	manager.feedPollersMu.Lock()
+	defer manager.feedPollersMu.Unlock()
+	require.Empty(t, manager.feedPollers, "no poller starts once the manager has shut down")
+}
@@ -15,10 +15,12 @@ import (
 
 	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/metrics"
+	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
 
 	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
 	"maglev.onebusaway.org/internal/logging"
 )
 
@@ -46,6 +48,7 @@ type Manager struct {
 	realTimeTripLookup             map[string]int
 	realTimeVehicleLookupByTrip    map[string]int
 	realTimeVehicleLookupByVehicle map[string]int
+	realTimeVehicleLookupByFeed    map[string]map[string]int // feedID -> vehicleID -> index
 	duplicatedVehicleByRoute       map[string][]gtfs.Vehicle
 	alertIdx                       alertIndex
 	staticUpdateMutex              sync.Mutex // Protects against concurrent ReloadStatic calls
@@ -62,9 +65,15 @@ type Manager struct {
 	feedVehicles map[string][]gtfs.Vehicle
 	feedAlerts   map[string][]gtfs.Alert
 	// Per-feed agency filter: feedID -> set of allowed agency IDs.
-	// Populated once during InitGTFSManager before goroutines start; read-only thereafter.
-	// No lock is required for reads.
+	// Replaced wholesale by UpdateRealtimeFeeds; read and written under realTimeMutex.
 	feedAgencyFilter map[string]map[string]bool
+	// Agencies whose trip updates and vehicles are left out of the merged
+	// realtime view, so their trips are served from the schedule. Read and
+	// written under realTimeMutex.
+	realtimeDisabledAgencies map[string]bool
+	// Closed and cleared when the merged realtime view is rebuilt; see
+	// RealtimeChanged. Read and written under realTimeMutex.
+	realtimeChanged chan struct{}
 	// Per-feed, per-vehicle last-seen timestamps for stale vehicle expiry
 	feedVehicleLastSeen map[string]map[string]time.Time // feedID -> vehicleID -> lastSeen
 
@@ -79,6 +88,43 @@ type Manager struct {
 
 	// Tracks the last successful update time per feed
 	feedLastUpdate map[string]time.Time
+
+	// May be nil when feed mirroring is disabled.
+	mirror *feedMirror
+	// Sources currently served from the mirror rather than upstream: source name -> struct{}
+	degradedSources sync.Map
+
+	// Supplementary amenities by raw stop ID, loaded once from
+	// config.AmenitiesPath and read-only afterwards. Nil when not configured.
+	amenities map[string][]models.Amenity
+
+	// Resolved from config.PostImportProcessors at startup.
+	postImportProcessors []PostImportProcessor
+
+	// Cached route and agency of trips for alert matching (tripID -> tripAlertScope).
+	// Cleared when a static reload changes the data.
+	tripAlertScopes sync.Map
+
+	// Per-service-date block trip orderings, cleared with tripAlertScopes.
+	blockSequenceCache blockSequenceCache
+
+	// Trip start times observed in the realtime feeds.
+	tripStarts tripStartTracker
+	// Realized arrivals recorded when ArrivalHistoryDays is set.
+	arrivalHistory arrivalHistoryRecorder
+	// Recent schedule deviations sampled when DeviationSampleInterval is set.
+	deviationHistory deviationHistory
+	// Earlier datasets opened for reading when DatasetSnapshots is set.
+	datasetSnapshots datasetSnapshots
+
+	// Dataset refused by the reload guard, if any, and its admin approval.
+	reloadGuard reloadGuard
+
+	// Running realtime feed pollers by feed ID and the enabled feeds in
+	// configuration order, both replaced by UpdateRealtimeFeeds.
+	feedPollersMu sync.Mutex
+	feedPollers   map[string]*feedPoller
+	rtFeeds       []RTFeedConfig
 }
 
 // clearFeedData removes stale data for a specific feed when the staleness threshold is crossed
@@ -108,10 +154,36 @@ func (manager *Manager) MarkReady() {
 	manager.isReady.Store(true)
 }
 
+// IsDegraded reports whether any static or realtime data is currently being
+// served from the local feed mirror because its upstream was unreachable.
+func (manager *Manager) IsDegraded() bool {
+	return len(manager.DegradedSources()) > 0
+}
+
+// DegradedSources returns the sorted names of the feeds currently being served
+// from the local mirror ("static", or "<feedID>/<kind>" for realtime feeds).
+func (manager *Manager) DegradedSources() []string {
+	var sources []string
+	manager.degradedSources.Range(func(key, _ any) bool {
+		sources = append(sources, key.(string))
+		return true
+	})
+	slices.Sort(sources)
+	return sources
+}
+
+func (manager *Manager) setDegraded(source string, degraded bool) {
+	if degraded {
+		manager.degradedSources.Store(source, struct{}{})
+	} else {
+		manager.degradedSources.Delete(source)
+	}
+}
+
 // InitGTFSManager initializes the Manager with the GTFS data from the given source
 // The source can be either a URL or a local file path
 func InitGTFSManager(ctx context.Context, config Config) (*Manager, error) {
-	logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+	logger := config.logger().With(slog.String("component", "gtfs_manager"))
 
 	// Use configurable backoffs or default to production values
 	backoffs := config.StartupRetries
@@ -130,40 +202,22 @@ func InitGTFSManager(ctx context.Context, config Config) (*Manager, error) {
 		return nil, fmt.Errorf("failed to open GTFS database: %w", err)
 	}
 
-	manager := &Manager{
-		GtfsDB:                         gtfsDB,
-		config:                         config,
-		shutdownChan:                   make(chan struct{}),
-		realTimeTripLookup:             make(map[string]int),
-		realTimeVehicleLookupByTrip:    make(map[string]int),
-		realTimeVehicleLookupByVehicle: make(map[string]int),
-		duplicatedVehicleByRoute:       make(map[string][]gtfs.Vehicle),
-		feedTrips:                      make(map[string][]gtfs.Trip),
-		feedVehicles:                   make(map[string][]gtfs.Vehicle),
-		feedAlerts:                     make(map[string][]gtfs.Alert),
-		feedLastUpdate:                 make(map[string]time.Time),
-		feedAgencyFilter:               make(map[string]map[string]bool),
-		feedVehicleLastSeen:            make(map[string]map[string]time.Time),
-		feedVehicleTimestamp:           make(map[string]uint64),
-		Metrics:                        config.Metrics,
+	manager := newManager(config, gtfsDB)
+	if err := manager.loadAmenities(); err != nil {
+		_ = gtfsDB.Close()
+		return nil, err
 	}
-
-	// Build per-feed agency filters from config
-	for _, feedCfg := range config.RTFeeds {
-		if len(feedCfg.AgencyIDs) > 0 {
-			filter := make(map[string]bool, len(feedCfg.AgencyIDs))
-			for _, id := range feedCfg.AgencyIDs {
-				filter[id] = true
-			}
-			manager.feedAgencyFilter[feedCfg.ID] = filter
-		}
+	if manager.postImportProcessors, err = resolvePostImportProcessors(config.PostImportProcessors); err != nil {
+		_ = gtfsDB.Close()
+		return nil, err
 	}
 
 	var attemptsMade int
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		attemptsMade = attempt
 		_, reloadErr := manager.ReloadStatic(ctx)
-		if reloadErr == nil {
+		// A refused reload leaves the existing database in service.
+		if reloadErr == nil || errors.Is(reloadErr, ErrStaticReloadRefused) {
 			break
 		}
 		if attempt < maxAttempts {
@@ -226,7 +280,13 @@ func InitGTFSManager(ctx context.Context, config Config) (*Manager, error) {
 	// to "warm" the cache before marking the manager as ready.
 	for _, feedCfg := range enabledFeeds {
 		initCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		initCtx = logging.WithLogger(initCtx, config.logger())
 		success := manager.updateFeedRealtime(initCtx, feedCfg)
+		if success {
+			manager.observeTripStarts(initCtx, time.Now())
+			manager.observeArrivalHistory(initCtx, time.Now())
+			manager.sampleScheduleDeviations(time.Now())
+		}
 		if !success {
 			logger.Warn("initial realtime fetch failed; feed starting in degraded state",
 				slog.String("feed", feedCfg.ID))
@@ -237,20 +297,90 @@ func InitGTFSManager(ctx context.Context, config Config) (*Manager, error) {
 	// Everything is now warm and ready for traffic
 	manager.MarkReady()
 
-	if !config.isLocalFile() {
+	if !config.isLocalFile() && !config.ExternalStaticRefresh {
 		manager.wg.Add(1)
 		go manager.updateStaticGTFS()
 	}
 
 	// Start one poller goroutine per enabled feed
+	manager.feedPollersMu.Lock()
 	for _, feedCfg := range enabledFeeds {
-		manager.wg.Add(1)
-		go manager.pollFeed(feedCfg)
+		manager.startFeedPollerLocked(feedCfg)
 	}
+	manager.feedPollersMu.Unlock()
 
 	return manager, nil
 }
 
+// newManager creates a Manager around an open database, with empty realtime
+// state and the per-feed agency filters from config.
+func newManager(config Config, gtfsDB *gtfsdb.Client) *Manager {
+	manager := &Manager{
+		GtfsDB:                         gtfsDB,
+		config:                         config,
+		shutdownChan:                   make(chan struct{}),
+		realTimeTripLookup:             make(map[string]int),
+		realTimeVehicleLookupByTrip:    make(map[string]int),
+		realTimeVehicleLookupByVehicle: make(map[string]int),
+		realTimeVehicleLookupByFeed:    make(map[string]map[string]int),
+		duplicatedVehicleByRoute:       make(map[string][]gtfs.Vehicle),
+		feedTrips:                      make(map[string][]gtfs.Trip),
+		feedVehicles:                   make(map[string][]gtfs.Vehicle),
+		feedAlerts:                     make(map[string][]gtfs.Alert),
+		feedLastUpdate:                 make(map[string]time.Time),
+		feedAgencyFilter:               make(map[string]map[string]bool),
+		feedVehicleLastSeen:            make(map[string]map[string]time.Time),
+		feedVehicleTimestamp:           make(map[string]uint64),
+		Metrics:                        config.Metrics,
+		mirror:                         newFeedMirror(config.MirrorDir),
+		feedPollers:                    make(map[string]*feedPoller),
+		rtFeeds:                        config.enabledFeeds(),
+	}
+	manager.feedAgencyFilter = feedAgencyFilters(config.RTFeeds)
+	manager.realtimeDisabledAgencies = agencySet(config.RealtimeDisabledAgencies)
+
+	return manager
+}
+
+// agencySet returns ids as a set, or nil when ids is empty.
+func agencySet(ids []string) map[string]bool {
+	if len(ids) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// SetRealtimeDisabledAgencies replaces the agencies whose realtime data is
+// ignored and rebuilds the merged realtime view, so the change applies
+// immediately instead of on the next feed poll. Feeds keep polling either way.
+func (manager *Manager) SetRealtimeDisabledAgencies(ids []string) {
+	manager.realTimeMutex.Lock()
+	defer manager.realTimeMutex.Unlock()
+
+	manager.realtimeDisabledAgencies = agencySet(ids)
+	manager.rebuildMergedRealtimeLocked()
+}
+
+// feedAgencyFilters builds the per-feed agency filters of feeds restricted to
+// particular agencies.
+func feedAgencyFilters(feeds []RTFeedConfig) map[string]map[string]bool {
+	filters := make(map[string]map[string]bool)
+	for _, feedCfg := range feeds {
+		if len(feedCfg.AgencyIDs) > 0 {
+			filter := make(map[string]bool, len(feedCfg.AgencyIDs))
+			for _, id := range feedCfg.AgencyIDs {
+				filter[id] = true
+			}
+			filters[feedCfg.ID] = filter
+		}
+	}
+	return filters
+}
+
 // SetGtfsURL updates the GTFS URL in the configuration.
 // It uses a mutex to ensure thread safety.
 func (manager *Manager) SetGtfsURL(url string) {
@@ -262,11 +392,16 @@ func (manager *Manager) SetGtfsURL(url string) {
 // Shutdown gracefully shuts down the manager and its background goroutines
 func (manager *Manager) Shutdown() {
 	manager.shutdownOnce.Do(func() {
+		// Holding feedPollersMu keeps UpdateRealtimeFeeds from starting a
+		// poller once shutdown has begun waiting for them.
+		manager.feedPollersMu.Lock()
 		close(manager.shutdownChan)
+		manager.feedPollersMu.Unlock()
 		manager.wg.Wait()
+		manager.closeDatasetSnapshots()
 		if manager.GtfsDB != nil {
 			if err := manager.GtfsDB.Close(); err != nil {
-				logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+				logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 				logging.LogError(logger, "failed to close GTFS database", err)
 			}
 		}
@@ -329,7 +464,7 @@ func (manager *Manager) GetStopsForLocation(
 
 	stops, err := manager.queryStopsInBounds(ctx, bounds)
 	if err != nil {
-		logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+		logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 		logging.LogError(logger, "could not query stops within bounds", err)
 		return []gtfsdb.Stop{}, false
 	}
@@ -365,6 +500,12 @@ func (manager *Manager) GetStopsForLocation(
 			stopIDs = append(stopIDs, stop.ID)
 		}
 
+		// Compare raw codes against the expanded filter so extended route types
+		// (e.g. 700 for bus) match their basic equivalents.
+		matchingRouteTypes := make(map[int]bool)
+		for _, rt := range models.ExpandRouteTypeFilter(routeTypes) {
+			matchingRouteTypes[rt] = true
+		}
 		routesForStops, err := manager.GtfsDB.Queries.GetRoutesForStops(ctx, stopIDs)
 		if err == nil {
 			stopRouteTypes := make(map[string][]int)
@@ -374,7 +515,7 @@ func (manager *Manager) GetStopsForLocation(
 			filteredStops := make([]gtfsdb.Stop, 0, len(stops))
 			for _, stop := range stops {
 				for _, rt := range stopRouteTypes[stop.ID] {
-					if slices.Contains(routeTypes, rt) {
+					if matchingRouteTypes[rt] {
 						filteredStops = append(filteredStops, stop)
 						break
 					}
@@ -403,7 +544,7 @@ func (manager *Manager) GetStopsInBounds(
 	bounds := BoundsFromParams(loc, clamp...)
 	stops, err := manager.queryStopsInBounds(ctx, bounds)
 	if err != nil {
-		logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+		logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 		logging.LogError(logger, "could not query stops within bounds", err)
 		return nil
 	}
@@ -427,7 +568,7 @@ func (manager *Manager) GetStopIDsWithinBounds(
 		MaxLon: bounds.MaxLon,
 	})
 	if err != nil {
-		logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+		logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 		logging.LogError(logger, "could not query stop IDs within bounds", err)
 		return nil
 	}
@@ -462,11 +603,12 @@ func (manager *Manager) GetRoutesForLocation(
 	routeShortName string,
 	maxCount int,
 	queryTime time.Time,
+	routeTypes []int,
 ) ([]gtfsdb.Route, bool) {
 	bounds := BoundsFromParams(loc)
-	routes, limitExceeded, err := manager.queryRoutesInBounds(ctx, bounds, loc.Lat, loc.Lon, maxCount, routeShortName)
+	routes, limitExceeded, err := manager.queryRoutesInBounds(ctx, bounds, loc.Lat, loc.Lon, maxCount, routeShortName, routeTypes)
 	if err != nil {
-		logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+		logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 		logging.LogError(logger, "could not query routes within bounds", err)
 		return []gtfsdb.Route{}, false
 	}
@@ -483,6 +625,7 @@ func (manager *Manager) queryRoutesInBounds(ctx context.Context, bounds utils.Co
 	lat, lon float64,
 	maxCount int,
 	shortNameQuery string,
+	routeTypes []int,
 ) ([]gtfsdb.Route, bool, error) {
 	if bounds.MinLat > bounds.MaxLat {
 		return nil, false, fmt.Errorf("query min lat %f exceeds max lat %f", bounds.MinLat, bounds.MaxLat)
@@ -498,8 +641,9 @@ func (manager *Manager) queryRoutesInBounds(ctx context.Context, bounds utils.Co
 		Lat:    lat,
 		Lon:    lon,
 		// Ask for an extra element so that we can determine if we hit the max count.
-		MaxCount:  maxCount + 1,
-		ShortName: shortNameQuery,
+		MaxCount:   maxCount + 1,
+		ShortName:  shortNameQuery,
+		RouteTypes: models.ExpandRouteTypeFilter(routeTypes),
 	})
 	if err != nil {
 		return nil, false, err
@@ -556,6 +700,64 @@ func (manager *Manager) GetDuplicatedVehiclesForRoute(routeID string) []gtfs.Veh
 	return out
 }
 
+// IsTripCanceled reports whether the real-time data cancels tripID
+// (GTFS-RT schedule_relationship=CANCELED), either in the trip's update or in
+// the trip descriptor of a vehicle serving it.
+func (manager *Manager) IsTripCanceled(tripID string) bool {
+	manager.realTimeMutex.RLock()
+	defer manager.realTimeMutex.RUnlock()
+
+	if index, exists := manager.realTimeTripLookup[tripID]; exists &&
+		manager.realTimeTrips[index].ID.ScheduleRelationship == gtfsrt.TripDescriptor_CANCELED {
+		return true
+	}
+	if index, exists := manager.realTimeVehicleLookupByTrip[tripID]; exists {
+		if trip := manager.realTimeVehicles[index].Trip; trip != nil && trip.ID.ScheduleRelationship == gtfsrt.TripDescriptor_CANCELED {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAddedTrips returns the real-time ADDED trips (GTFS-RT
+// schedule_relationship=ADDED). Like DUPLICATED trips they only exist in the
+// real-time feed and have no static DB entry. A trip reported by both a trip
+// update and a vehicle is returned once, from its update, with Vehicle set to
+// the vehicle serving it and RouteID filled in from the vehicle when the
+// update omits it.
+func (manager *Manager) GetAddedTrips() []gtfs.Trip {
+	manager.realTimeMutex.RLock()
+	defer manager.realTimeMutex.RUnlock()
+
+	var added []gtfs.Trip
+	seen := make(map[string]bool)
+	for _, trip := range manager.realTimeTrips {
+		if trip.ID.ScheduleRelationship != gtfsrt.TripDescriptor_ADDED || trip.ID.ID == "" || seen[trip.ID.ID] {
+			continue
+		}
+		seen[trip.ID.ID] = true
+		if index, exists := manager.realTimeVehicleLookupByTrip[trip.ID.ID]; exists {
+			vehicle := manager.realTimeVehicles[index]
+			trip.Vehicle = &vehicle
+			if trip.ID.RouteID == "" && vehicle.Trip != nil {
+				trip.ID.RouteID = vehicle.Trip.ID.RouteID
+			}
+		}
+		added = append(added, trip)
+	}
+	for _, vehicle := range manager.realTimeVehicles {
+		if vehicle.Trip == nil || vehicle.Trip.ID.ScheduleRelationship != gtfsrt.TripDescriptor_ADDED ||
+			vehicle.Trip.ID.ID == "" || seen[vehicle.Trip.ID.ID] {
+			continue
+		}
+		seen[vehicle.Trip.ID.ID] = true
+		trip := gtfs.Trip{ID: vehicle.Trip.ID}
+		trip.Vehicle = &vehicle
+		added = append(added, trip)
+	}
+	return added
+}
+
 // GetVehicleForTrip retrieves a vehicle for a specific trip ID or finds the first vehicle that is part of the block
 // for that trip. Note we depend on getting the vehicle that may not match the trip ID exactly,
 // but is part of the same block.
@@ -573,7 +775,7 @@ func (manager *Manager) GetVehicleForTrip(ctx context.Context, tripID string) *g
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+	logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 
 	requestedTrip, err := manager.GtfsDB.Queries.GetTrip(ctx, tripID)
 	if err != nil {
@@ -630,6 +832,54 @@ func (manager *Manager) GetVehicleByID(vehicleID string) (*gtfs.Vehicle, error)
 	return nil, fmt.Errorf("vehicle with ID %s not found", vehicleID)
 }
 
+// GetVehicleForAgency retrieves a vehicle by ID from the realtime feeds
+// serving agencyID, so providers that reuse vehicle IDs do not shadow each
+// other. Feeds restricted to agencyID are searched before unrestricted feeds.
+func (manager *Manager) GetVehicleForAgency(agencyID, vehicleID string) (*gtfs.Vehicle, error) {
+	manager.realTimeMutex.RLock()
+	defer manager.realTimeMutex.RUnlock()
+
+	feedIDs := make([]string, 0, len(manager.realTimeVehicleLookupByFeed))
+	for feedID := range manager.realTimeVehicleLookupByFeed {
+		feedIDs = append(feedIDs, feedID)
+	}
+	slices.SortFunc(feedIDs, func(a, b string) int {
+		// Restricted feeds sort first, then by ID for a stable choice.
+		_, aRestricted := manager.feedAgencyFilter[a]
+		_, bRestricted := manager.feedAgencyFilter[b]
+		if aRestricted != bRestricted {
+			if aRestricted {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a, b)
+	})
+
+	seen := false
+	for _, feedID := range feedIDs {
+		index, exists := manager.realTimeVehicleLookupByFeed[feedID][vehicleID]
+		if !exists {
+			continue
+		}
+		seen = true
+		if filter, restricted := manager.feedAgencyFilter[feedID]; restricted && !filter[agencyID] {
+			continue
+		}
+		vehicle := manager.realTimeVehicles[index]
+		return &vehicle, nil
+	}
+	if !seen {
+		// Vehicles set without a feed, as in tests, only have the merged lookup.
+		if index, exists := manager.realTimeVehicleLookupByVehicle[vehicleID]; exists {
+			vehicle := manager.realTimeVehicles[index]
+			return &vehicle, nil
+		}
+	}
+
+	return nil, fmt.Errorf("vehicle with ID %s not found for agency %s", vehicleID, agencyID)
+}
+
 func (manager *Manager) GetTripUpdatesForTrip(tripID string) []gtfs.Trip {
 	manager.realTimeMutex.RLock()
 	defer manager.realTimeMutex.RUnlock()
@@ -671,7 +921,7 @@ func (manager *Manager) PrintStatistics() {
 	}
 
 	ctx := context.Background()
-	logger := slog.Default().With(slog.String("component", "gtfs_manager"))
+	logger := manager.config.logger().With(slog.String("component", "gtfs_manager"))
 
 	countOrZero := func(n int64, err error) int64 {
 		if err != nil {
@@ -754,6 +1004,16 @@ func (manager *Manager) FeedExpiresAt(ctx context.Context) time.Time {
 	return time.Unix(metadata.FeedExpiresAt.Int64, 0)
 }
 
+// FeedExpiringSoon reports whether the feed's service ends within the
+// configured warning window of now without having ended yet.
+func (manager *Manager) FeedExpiringSoon(expiresAt, now time.Time) bool {
+	if expiresAt.IsZero() || now.After(expiresAt) {
+		return false
+	}
+	window := time.Duration(manager.config.feedExpiryWarningDays()) * 24 * time.Hour
+	return expiresAt.Sub(now) <= window
+}
+
 // SetFeedExpiresAtForTest sets the feed expiry time in the database for testing purposes.
 func (manager *Manager) SetFeedExpiresAtForTest(ctx context.Context, t time.Time) {
 	var v sql.NullInt64
@@ -797,6 +1057,39 @@ func (manager *Manager) GetFeedUpdateTimes() map[string]time.Time {
 	return result
 }
 
+// RealtimeFeedStatus describes the latest successful fetch of one enabled
+// realtime feed.
+type RealtimeFeedStatus struct {
+	ID string
+	// LastUpdated is zero until the feed has been fetched successfully.
+	LastUpdated time.Time
+	// Stale is set when no fetch has succeeded for three refresh intervals.
+	Stale bool
+}
+
+// RealtimeFeedStatuses returns the status of each enabled realtime feed, in
+// configuration order.
+func (manager *Manager) RealtimeFeedStatuses(now time.Time) []RealtimeFeedStatus {
+	updated := manager.GetFeedUpdateTimes()
+	manager.feedPollersMu.Lock()
+	feeds := manager.rtFeeds
+	manager.feedPollersMu.Unlock()
+	statuses := make([]RealtimeFeedStatus, 0, len(feeds))
+	for _, feed := range feeds {
+		interval := time.Duration(feed.RefreshInterval) * time.Second
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+		last := updated[feed.ID]
+		statuses = append(statuses, RealtimeFeedStatus{
+			ID:          feed.ID,
+			LastUpdated: last,
+			Stale:       !last.IsZero() && now.Sub(last) > 3*interval,
+		})
+	}
+	return statuses
+}
+
 // SetFeedUpdateTimeForTest safely records the time a feed was successfully updated.
 func (manager *Manager) SetFeedUpdateTimeForTest(feedID string, t time.Time) {
 	manager.realTimeMutex.Lock()
@@ -826,3 +1119,8 @@ func (m *Manager) AddAlertForTest(alert gtfs.Alert) {
 	m.feedAlerts["_test"] = append(m.feedAlerts["_test"], alert)
 	m.rebuildMergedRealtimeLocked()
 }
+
+// SetDegradedSourceForTest marks a feed source as being served from the mirror for testing purposes.
+func (manager *Manager) SetDegradedSourceForTest(source string) {
+	manager.setDegraded(source, true)
+}
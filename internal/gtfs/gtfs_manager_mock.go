@@ -6,6 +6,7 @@ import (
 
 	"github.com/OneBusAway/go-gtfs"
 	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/nulls"
 )
 
@@ -34,6 +35,7 @@ func (m *Manager) MockAddRoute(id, agencyID, name string) {
 		AgencyID:  agencyID,
 		ShortName: nulls.String(name),
 	})
+	m.tripAlertScopes.Clear()
 }
 func (m *Manager) MockAddVehicle(vehicleID, tripID, routeID string) {
 	m.realTimeMutex.Lock()
@@ -61,6 +63,7 @@ func (m *Manager) MockAddVehicle(vehicleID, tripID, routeID string) {
 	if tripID != "" {
 		m.realTimeVehicleLookupByTrip[tripID] = idx
 	}
+	m.notifyRealtimeChangedLocked()
 }
 
 type MockVehicleOptions struct {
@@ -69,10 +72,13 @@ type MockVehicleOptions struct {
 	StopID              *string
 	CurrentStatus       *gtfs.CurrentStatus
 	OccupancyStatus     *gtfs.OccupancyStatus
+	OccupancyPercentage *uint32
 	NoTrip              bool       // NoTrip creates a vehicle with Trip == nil, simulating a GTFS-RT vehicle with no current trip assignment, which VehiclesForAgencyID filters out.
 	NoID                bool       // NoID creates a vehicle with ID == nil, simulating a GTFS-RT vehicle that omits the vehicle descriptor.
 	NoTimestamp         bool       // NoTimestamp creates a vehicle with Timestamp == nil, simulating a GTFS-RT vehicle with no update time.
 	Timestamp           *time.Time // Timestamp overrides the vehicle's last-update time; defaults to time.Now() when nil.
+	// ScheduleRelationship sets the relationship of the vehicle's trip descriptor, e.g. CANCELED or ADDED.
+	ScheduleRelationship gtfs.TripScheduleRelationship
 }
 
 func (m *Manager) MockAddVehicleWithOptions(vehicleID, tripID, routeID string, opts MockVehicleOptions) {
@@ -93,8 +99,9 @@ func (m *Manager) MockAddVehicleWithOptions(vehicleID, tripID, routeID string, o
 	if !opts.NoTrip {
 		trip = &gtfs.Trip{
 			ID: gtfs.TripID{
-				ID:      tripID,
-				RouteID: routeID,
+				ID:                   tripID,
+				RouteID:              routeID,
+				ScheduleRelationship: opts.ScheduleRelationship,
 			},
 		}
 	}
@@ -118,6 +125,7 @@ func (m *Manager) MockAddVehicleWithOptions(vehicleID, tripID, routeID string, o
 		StopID:              opts.StopID,
 		CurrentStatus:       opts.CurrentStatus,
 		OccupancyStatus:     opts.OccupancyStatus,
+		OccupancyPercentage: opts.OccupancyPercentage,
 	}
 	m.realTimeVehicles = append(m.realTimeVehicles, v)
 
@@ -128,6 +136,7 @@ func (m *Manager) MockAddVehicleWithOptions(vehicleID, tripID, routeID string, o
 	if tripID != "" && !opts.NoTrip {
 		m.realTimeVehicleLookupByTrip[tripID] = idx
 	}
+	m.notifyRealtimeChangedLocked()
 }
 
 func (m *Manager) MockAddTrip(tripID, agencyID, routeID string) {
@@ -137,6 +146,10 @@ func (m *Manager) MockAddTrip(tripID, agencyID, routeID string) {
 		RouteID:   routeID,
 		ServiceID: "",
 	})
+	m.tripAlertScopes.Clear()
+	m.blockSequenceCache.clear()
+	m.tripStarts.clearOrigins()
+	m.arrivalHistory.clearSchedules()
 }
 
 func (m *Manager) MockAddTripUpdate(tripID string, delay *time.Duration, stopTimeUpdates []gtfs.StopTimeUpdate) {
@@ -153,6 +166,21 @@ func (m *Manager) MockAddTripUpdate(tripID string, delay *time.Duration, stopTim
 		m.realTimeTripLookup = make(map[string]int)
 	}
 	m.realTimeTripLookup[tripID] = len(m.realTimeTrips) - 1
+	m.notifyRealtimeChangedLocked()
+}
+
+// MockAddRealtimeTrip adds a trip update with a full trip descriptor, e.g.
+// to cancel a scheduled trip or add one that is not in the static data.
+func (m *Manager) MockAddRealtimeTrip(trip gtfs.Trip) {
+	m.realTimeMutex.Lock()
+	defer m.realTimeMutex.Unlock()
+
+	m.realTimeTrips = append(m.realTimeTrips, trip)
+	if m.realTimeTripLookup == nil {
+		m.realTimeTripLookup = make(map[string]int)
+	}
+	m.realTimeTripLookup[trip.ID.ID] = len(m.realTimeTrips) - 1
+	m.notifyRealtimeChangedLocked()
 }
 
 func (m *Manager) MockAddAlert(feedID string, alert gtfs.Alert) {
@@ -166,17 +194,56 @@ func (m *Manager) MockAddAlert(feedID string, alert gtfs.Alert) {
 	m.rebuildMergedRealtimeLocked()
 }
 
-// MockResetRealTimeData clears all mock real-time vehicles, trip updates, and alerts.
+// MockResetRealTimeData clears all mock real-time vehicles, trip updates, alerts
+// and observed trip starts.
 func (m *Manager) MockResetRealTimeData() {
 	m.realTimeMutex.Lock()
 	defer m.realTimeMutex.Unlock()
 
 	m.realTimeVehicles = nil
 	m.realTimeVehicleLookupByVehicle = make(map[string]int)
+	m.realTimeVehicleLookupByFeed = make(map[string]map[string]int)
 	m.realTimeVehicleLookupByTrip = make(map[string]int)
 	m.duplicatedVehicleByRoute = make(map[string][]gtfs.Vehicle)
 	m.realTimeTrips = nil
 	m.realTimeTripLookup = make(map[string]int)
 	m.feedAlerts = make(map[string][]gtfs.Alert)
 	m.rebuildMergedRealtimeLocked()
+
+	m.tripStarts.mu.Lock()
+	m.tripStarts.starts = nil
+	m.tripStarts.pending = nil
+	m.tripStarts.mu.Unlock()
+}
+
+// MockObserveTripStarts records trip starts from the current mock real-time
+// data, as a realtime feed update does.
+func (m *Manager) MockObserveTripStarts(now time.Time) {
+	m.observeTripStarts(context.Background(), now)
+}
+
+// MockSetArrivalHistoryDays enables arrival history with the given retention,
+// or disables it when days is 0, and drops the aggregated history cache.
+func (m *Manager) MockSetArrivalHistoryDays(days int) {
+	m.config.ArrivalHistoryDays = days
+	m.arrivalHistory.clearSchedules()
+}
+
+// MockSampleScheduleDeviations sets the deviation sampling interval, dropping
+// the samples taken so far, and samples the current mock real-time data at
+// now, as a realtime feed update does. An interval of 0 disables sampling.
+func (m *Manager) MockSampleScheduleDeviations(interval time.Duration, now time.Time) {
+	if interval != m.config.DeviationSampleInterval {
+		m.config.DeviationSampleInterval = interval
+		m.deviationHistory.mu.Lock()
+		m.deviationHistory.trips = nil
+		m.deviationHistory.mu.Unlock()
+	}
+	m.sampleScheduleDeviations(now)
+}
+
+// MockSetAmenities replaces the amenities dataset, keyed by raw stop ID. Pass
+// nil to clear it.
+func (m *Manager) MockSetAmenities(amenities map[string][]models.Amenity) {
+	m.amenities = amenities
 }
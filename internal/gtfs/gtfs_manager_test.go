@@ -1,9 +1,11 @@
 package gtfs
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"testing"
 	"time"
@@ -510,3 +512,38 @@ func TestManager_DataFreshnessTracking(t *testing.T) {
 	feedTimes2 := manager.GetFeedUpdateTimes()
 	assert.Equal(t, now, feedTimes2["feed-1"])
 }
+
+// lockedBuffer lets the manager's background goroutines share a log buffer.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestInitGTFSManager_UsesInjectedLogger(t *testing.T) {
+	var logs lockedBuffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	manager, err := InitGTFSManager(context.Background(), Config{
+		GtfsURL:      models.GetFixturePath(t, "raba.zip"),
+		GTFSDataPath: ":memory:",
+		Env:          appconf.Test,
+		Logger:       logger,
+	})
+	require.NoError(t, err)
+	manager.Shutdown()
+
+	assert.Contains(t, logs.String(), `"component":"gtfs_manager"`)
+	assert.Contains(t, logs.String(), `"component":"direction_precomputer"`)
+}
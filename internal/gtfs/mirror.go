@@ -0,0 +1,85 @@
+package gtfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	staticMirrorFileName = "static.zip"
+	// staticMirrorSource is the degraded-source name reported for the static feed.
+	staticMirrorSource = "static"
+)
+
+// feedMirror persists the last successfully downloaded static zip and realtime
+// snapshots to a local directory so the server can boot from them when the
+// upstream feeds are unreachable.
+type feedMirror struct {
+	dir string
+}
+
+// newFeedMirror returns nil when dir is empty, which disables mirroring.
+func newFeedMirror(dir string) *feedMirror {
+	if dir == "" {
+		return nil
+	}
+	return &feedMirror{dir: dir}
+}
+
+func (m *feedMirror) saveStatic(data []byte) error {
+	return m.write(staticMirrorFileName, data)
+}
+
+func (m *feedMirror) loadStatic() ([]byte, error) {
+	return os.ReadFile(filepath.Join(m.dir, staticMirrorFileName))
+}
+
+func (m *feedMirror) saveRealtime(feedID, kind string, data []byte) error {
+	return m.write(realtimeMirrorFileName(feedID, kind), data)
+}
+
+func (m *feedMirror) loadRealtime(feedID, kind string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(m.dir, realtimeMirrorFileName(feedID, kind)))
+}
+
+// write replaces name atomically so a crash mid-write never leaves a truncated
+// snapshot behind for the next boot to pick up.
+func (m *feedMirror) write(name string, data []byte) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(m.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create mirror temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write mirror file %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close mirror file %s: %w", name, err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(m.dir, name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace mirror file %s: %w", name, err)
+	}
+	return nil
+}
+
+// realtimeMirrorFileName keeps feed IDs from escaping the mirror directory.
+func realtimeMirrorFileName(feedID, kind string) string {
+	safeID := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, feedID)
+	return fmt.Sprintf("rt-%s-%s.pb", safeID, kind)
+}
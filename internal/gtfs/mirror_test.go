@@ -0,0 +1,124 @@
+package gtfs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func TestFeedMirror_RoundTrip(t *testing.T) {
+	mirror := newFeedMirror(filepath.Join(t.TempDir(), "mirror"))
+
+	require.NoError(t, mirror.saveStatic([]byte("static-v1")))
+	require.NoError(t, mirror.saveStatic([]byte("static-v2")))
+	got, err := mirror.loadStatic()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("static-v2"), got)
+
+	require.NoError(t, mirror.saveRealtime("../feed", "trip-updates", []byte("rt")))
+	got, err = mirror.loadRealtime("../feed", "trip-updates")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rt"), got)
+
+	entries, err := os.ReadDir(mirror.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "temp files should not be left behind and feed IDs must stay inside the mirror dir")
+}
+
+func TestNewFeedMirror_DisabledWhenDirEmpty(t *testing.T) {
+	assert.Nil(t, newFeedMirror(""))
+}
+
+func TestInitGTFSManager_BootsFromMirrorWhenUpstreamUnreachable(t *testing.T) {
+	ctx := context.Background()
+	mirrorDir := t.TempDir()
+
+	zipBytes, err := os.ReadFile(models.GetFixturePath(t, "raba.zip"))
+	require.NoError(t, err)
+
+	upstreamUp := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !upstreamUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(zipBytes)
+	}))
+	defer server.Close()
+
+	config := Config{
+		GtfsURL:        server.URL + "/gtfs.zip",
+		GTFSDataPath:   ":memory:",
+		Env:            appconf.Test,
+		MirrorDir:      mirrorDir,
+		StartupRetries: []time.Duration{time.Millisecond},
+	}
+
+	first, err := InitGTFSManager(ctx, config)
+	require.NoError(t, err)
+	assert.False(t, first.IsDegraded())
+	first.Shutdown()
+
+	mirrored, err := os.ReadFile(filepath.Join(mirrorDir, staticMirrorFileName))
+	require.NoError(t, err)
+	assert.Equal(t, zipBytes, mirrored)
+
+	upstreamUp = false
+	second, err := InitGTFSManager(ctx, config)
+	require.NoError(t, err, "startup should fall back to the mirror instead of failing")
+	defer second.Shutdown()
+
+	assert.True(t, second.IsDegraded())
+	assert.Equal(t, []string{staticMirrorSource}, second.DegradedSources())
+
+	agencies, err := second.GtfsDB.Queries.ListAgencies(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, agencies)
+}
+
+func TestInitGTFSManager_FailsWithoutMirrorWhenUpstreamUnreachable(t *testing.T) {
+	config := Config{
+		GtfsURL:        "http://127.0.0.1:9099/gtfs.zip",
+		GTFSDataPath:   ":memory:",
+		Env:            appconf.Test,
+		MirrorDir:      t.TempDir(),
+		StartupRetries: []time.Duration{time.Millisecond},
+	}
+
+	manager, err := InitGTFSManager(context.Background(), config)
+	require.Error(t, err)
+	assert.Nil(t, manager)
+}
+
+func TestLoadMirroredRealtimeData_FallsBackBeforeReady(t *testing.T) {
+	ctx := context.Background()
+	mirror := newFeedMirror(t.TempDir())
+	manager := &Manager{mirror: mirror}
+
+	feedBytes, err := os.ReadFile(models.GetFixturePath(t, "raba-vehicle-positions.pb"))
+	require.NoError(t, err)
+	require.NoError(t, mirror.saveRealtime("feed-0", "vehicle-positions", feedBytes))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	data, err := manager.loadMirroredRealtimeData(ctx, "feed-0", "vehicle-positions", server.URL, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, data.Vehicles)
+	assert.Equal(t, []string{"feed-0/vehicle-positions"}, manager.DegradedSources())
+
+	manager.MarkReady()
+	_, err = manager.loadMirroredRealtimeData(ctx, "feed-0", "vehicle-positions", server.URL, nil)
+	assert.Error(t, err, "the mirror is only used to boot, not to mask outages once running")
+}
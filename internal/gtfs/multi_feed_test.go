@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/OneBusAway/go-gtfs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -83,6 +84,40 @@ func TestMultiFeedDataMerging(t *testing.T) {
 	}
 }
 
+// TestVehicleIDsCollidingAcrossFeeds verifies that two providers reusing a
+// vehicle ID each resolve to their own vehicle for the agencies they serve.
+func TestVehicleIDsCollidingAcrossFeeds(t *testing.T) {
+	manager := newTestManager()
+	vehicle := func(tripID string) gtfs.Vehicle {
+		return gtfs.Vehicle{ID: &gtfs.VehicleID{ID: "101"}, Trip: &gtfs.Trip{ID: gtfs.TripID{ID: tripID}}}
+	}
+	manager.feedAgencyFilter["bus"] = map[string]bool{"metro": true}
+	manager.feedAgencyFilter["ferry"] = map[string]bool{"ferries": true}
+	manager.feedVehicles["bus"] = []gtfs.Vehicle{vehicle("bus-trip")}
+	manager.feedVehicles["ferry"] = []gtfs.Vehicle{vehicle("ferry-trip")}
+	manager.feedVehicles["shared"] = []gtfs.Vehicle{vehicle("shared-trip"), {ID: &gtfs.VehicleID{ID: "202"}}}
+	manager.realTimeMutex.Lock()
+	manager.rebuildMergedRealtimeLocked()
+	manager.realTimeMutex.Unlock()
+
+	for agencyID, tripID := range map[string]string{"metro": "bus-trip", "ferries": "ferry-trip", "other": "shared-trip"} {
+		v, err := manager.GetVehicleForAgency(agencyID, "101")
+		require.NoError(t, err, agencyID)
+		assert.Equal(t, tripID, v.Trip.ID.ID, "agency %s", agencyID)
+	}
+
+	v, err := manager.GetVehicleForAgency("metro", "202")
+	require.NoError(t, err, "unrestricted feeds serve every agency")
+	assert.Equal(t, "202", v.ID.ID)
+
+	delete(manager.feedVehicles, "shared")
+	manager.realTimeMutex.Lock()
+	manager.rebuildMergedRealtimeLocked()
+	manager.realTimeMutex.Unlock()
+	_, err = manager.GetVehicleForAgency("other", "101")
+	assert.Error(t, err, "a vehicle is not served to agencies its feed excludes")
+}
+
 func TestStaleVehicleExpiry(t *testing.T) {
 	realServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		data, err := os.ReadFile(filepath.Join("../../testdata", "raba-vehicle-positions.pb"))
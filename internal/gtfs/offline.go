@@ -0,0 +1,82 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/OneBusAway/go-gtfs"
+)
+
+// OpenGTFSManager opens the previously imported database at config.GTFSDataPath
+// without fetching or importing a static feed, and without starting any
+// background refresh or realtime polling. It is intended for offline tools
+// that answer queries against an existing database; realtime data can be
+// supplied with LoadRealtimeSnapshot. The caller must call Shutdown.
+func OpenGTFSManager(ctx context.Context, config Config) (*Manager, error) {
+	gtfsDB, err := openGtfsDB(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GTFS database: %w", err)
+	}
+
+	agencyIDs, err := gtfsDB.Queries.ListAgencyIds(ctx)
+	if err == nil && len(agencyIDs) == 0 {
+		err = errors.New("database contains no GTFS data; start the server once to import a feed")
+	}
+	if err != nil {
+		_ = gtfsDB.Close()
+		return nil, fmt.Errorf("failed to read GTFS database %s: %w", config.GTFSDataPath, err)
+	}
+
+	manager := newManager(config, gtfsDB)
+	if err := manager.loadAmenities(); err != nil {
+		_ = gtfsDB.Close()
+		return nil, err
+	}
+	manager.regionBounds = computeRegionBounds(ctx, gtfsDB)
+	manager.MarkReady()
+	return manager, nil
+}
+
+// LoadRealtimeSnapshot replaces the realtime data of feedID with the given raw
+// GTFS-RT protobuf snapshots. A nil snapshot leaves that kind of data empty.
+// Unlike polling, the snapshot is applied as-is: no staleness checks run, so
+// the result matches what the API served when the snapshot was captured.
+func (manager *Manager) LoadRealtimeSnapshot(feedID string, tripUpdates, vehiclePositions, serviceAlerts []byte) error {
+	parse := func(kind string, body []byte) (*gtfs.Realtime, error) {
+		if body == nil {
+			return &gtfs.Realtime{}, nil
+		}
+		data, err := gtfs.ParseRealtime(body, &gtfs.ParseRealtimeOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse GTFS-RT %s: %w", kind, err)
+		}
+		return data, nil
+	}
+
+	tripData, err := parse("trip updates", tripUpdates)
+	if err != nil {
+		return err
+	}
+	vehicleData, err := parse("vehicle positions", vehiclePositions)
+	if err != nil {
+		return err
+	}
+	alertData, err := parse("service alerts", serviceAlerts)
+	if err != nil {
+		return err
+	}
+
+	// Polling drops vehicles without an ID as well.
+	vehicles := slices.DeleteFunc(vehicleData.Vehicles, func(v gtfs.Vehicle) bool { return v.ID == nil })
+
+	manager.realTimeMutex.Lock()
+	defer manager.realTimeMutex.Unlock()
+
+	manager.feedTrips[feedID] = tripData.Trips
+	manager.feedVehicles[feedID] = vehicles
+	manager.feedAlerts[feedID] = alertData.Alerts
+	manager.rebuildMergedRealtimeLocked()
+	return nil
+}
@@ -0,0 +1,50 @@
+package gtfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func TestOpenGTFSManager_UsesExistingDatabase(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "gtfs.db")
+
+	imported, err := InitGTFSManager(ctx, Config{GtfsURL: models.GetFixturePath(t, "raba.zip"), GTFSDataPath: dbPath})
+	require.NoError(t, err)
+	imported.Shutdown()
+
+	// No static source: the manager must serve what is already in the database.
+	manager, err := OpenGTFSManager(ctx, Config{GTFSDataPath: dbPath})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	assert.True(t, manager.IsReady())
+	agencies, err := manager.GetAgencies(ctx)
+	require.NoError(t, err)
+	require.Len(t, agencies, 1)
+	assert.Equal(t, "25", agencies[0].ID)
+	assert.Empty(t, manager.GetRealTimeVehicles())
+
+	tripUpdates, err := os.ReadFile(models.GetFixturePath(t, "raba-trip-updates.pb"))
+	require.NoError(t, err)
+	vehiclePositions, err := os.ReadFile(models.GetFixturePath(t, "raba-vehicle-positions.pb"))
+	require.NoError(t, err)
+
+	require.NoError(t, manager.LoadRealtimeSnapshot("snapshot", tripUpdates, vehiclePositions, nil))
+	assert.NotEmpty(t, manager.GetRealTimeTrips())
+	assert.NotEmpty(t, manager.GetRealTimeVehicles())
+
+	assert.Error(t, manager.LoadRealtimeSnapshot("snapshot", []byte("not a protobuf"), nil, nil))
+}
+
+func TestOpenGTFSManager_EmptyDatabase(t *testing.T) {
+	_, err := OpenGTFSManager(context.Background(), Config{GTFSDataPath: filepath.Join(t.TempDir(), "empty.db")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "contains no GTFS data")
+}
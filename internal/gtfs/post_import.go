@@ -0,0 +1,108 @@
+package gtfs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/logging"
+)
+
+// PostImportProcessor is an extension point that runs after a static feed has
+// been imported into the database, for work such as building custom indices
+// or enriching stops. Processors run in the order they are configured, only
+// when the import changed the database, and after stop directions have been
+// precomputed. The import is already committed when they run: a processor
+// that fails is logged and the remaining processors still run.
+type PostImportProcessor interface {
+	Name() string
+	Process(ctx context.Context, db *gtfsdb.Client) error
+}
+
+type postImportFunc struct {
+	name string
+	fn   func(ctx context.Context, db *gtfsdb.Client) error
+}
+
+func (p postImportFunc) Name() string { return p.name }
+
+func (p postImportFunc) Process(ctx context.Context, db *gtfsdb.Client) error {
+	return p.fn(ctx, db)
+}
+
+// NewPostImportProcessor adapts a function into a PostImportProcessor.
+func NewPostImportProcessor(name string, fn func(ctx context.Context, db *gtfsdb.Client) error) PostImportProcessor {
+	return postImportFunc{name: name, fn: fn}
+}
+
+var (
+	postImportRegistryMu sync.RWMutex
+	postImportRegistry   = make(map[string]PostImportProcessor)
+)
+
+// RegisterPostImportProcessor makes a processor available by name to the
+// post-import-processors setting. Forks typically call it from an init
+// function. It panics if the name is empty or already registered.
+func RegisterPostImportProcessor(p PostImportProcessor) {
+	postImportRegistryMu.Lock()
+	defer postImportRegistryMu.Unlock()
+
+	name := p.Name()
+	if name == "" {
+		panic("gtfs: post-import processor name cannot be empty")
+	}
+	if _, dup := postImportRegistry[name]; dup {
+		panic("gtfs: post-import processor registered twice: " + name)
+	}
+	postImportRegistry[name] = p
+}
+
+// resolvePostImportProcessors looks up the configured processor names.
+func resolvePostImportProcessors(names []string) ([]PostImportProcessor, error) {
+	postImportRegistryMu.RLock()
+	defer postImportRegistryMu.RUnlock()
+
+	processors := make([]PostImportProcessor, 0, len(names))
+	for _, name := range names {
+		p, ok := postImportRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown post-import processor %q (registered: %v)",
+				name, slices.Sorted(maps.Keys(postImportRegistry)))
+		}
+		processors = append(processors, p)
+	}
+	return processors, nil
+}
+
+// runPostImportProcessors runs each processor in turn, logging failures.
+func runPostImportProcessors(ctx context.Context, client *gtfsdb.Client, processors []PostImportProcessor, logger *slog.Logger) {
+	logger = logger.With(slog.String("component", "gtfs_post_import"))
+	for _, p := range processors {
+		if ctx.Err() != nil {
+			return
+		}
+		start := time.Now()
+		if err := p.Process(ctx, client); err != nil {
+			logging.LogError(logger, "Post-import processor failed", err,
+				slog.String("processor", p.Name()))
+			continue
+		}
+		logging.LogOperation(logger, "post_import_processor_completed",
+			slog.String("processor", p.Name()),
+			slog.Duration("duration", time.Since(start)))
+	}
+}
+
+func init() {
+	// sqlite-optimize refreshes the query planner statistics after the bulk
+	// insert, which otherwise only happens when the connection is closed.
+	RegisterPostImportProcessor(NewPostImportProcessor("sqlite-optimize", func(ctx context.Context, db *gtfsdb.Client) error {
+		_, err := db.DB.ExecContext(ctx, "PRAGMA optimize")
+		return err
+	}))
+}
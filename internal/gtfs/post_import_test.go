@@ -0,0 +1,86 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func TestResolvePostImportProcessors(t *testing.T) {
+	processors, err := resolvePostImportProcessors([]string{"sqlite-optimize"})
+	require.NoError(t, err)
+	require.Len(t, processors, 1)
+	assert.Equal(t, "sqlite-optimize", processors[0].Name())
+
+	_, err = resolvePostImportProcessors([]string{"sqlite-optimize", "no-such-processor"})
+	assert.ErrorContains(t, err, `unknown post-import processor "no-such-processor"`)
+}
+
+func TestRegisterPostImportProcessor_PanicsOnDuplicate(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterPostImportProcessor(NewPostImportProcessor("sqlite-optimize", nil))
+	})
+	assert.Panics(t, func() {
+		RegisterPostImportProcessor(NewPostImportProcessor("", nil))
+	})
+}
+
+func TestRunPostImportProcessors_ContinuesAfterFailure(t *testing.T) {
+	var ran []string
+	record := func(name string, err error) PostImportProcessor {
+		return NewPostImportProcessor(name, func(ctx context.Context, db *gtfsdb.Client) error {
+			ran = append(ran, name)
+			return err
+		})
+	}
+
+	runPostImportProcessors(context.Background(), nil, []PostImportProcessor{
+		record("first", nil),
+		record("failing", errors.New("boom")),
+		record("last", nil),
+	}, slog.Default())
+	assert.Equal(t, []string{"first", "failing", "last"}, ran)
+}
+
+func TestInitGTFSManager_RunsPostImportProcessors(t *testing.T) {
+	var stopCounts []int64
+	RegisterPostImportProcessor(NewPostImportProcessor("test-count-stops", func(ctx context.Context, db *gtfsdb.Client) error {
+		count, err := db.Queries.CountStops(ctx)
+		stopCounts = append(stopCounts, count)
+		return err
+	}))
+
+	manager, err := InitGTFSManager(context.Background(), Config{
+		GtfsURL:              models.GetFixturePath(t, "raba.zip"),
+		GTFSDataPath:         t.TempDir() + "/gtfs.db",
+		Env:                  appconf.Development,
+		PostImportProcessors: []string{"sqlite-optimize", "test-count-stops"},
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+
+	require.Len(t, stopCounts, 1, "processors run once per changed import")
+	assert.Positive(t, stopCounts[0], "processors see the imported data")
+
+	changed, err := manager.ReloadStatic(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Len(t, stopCounts, 1, "processors do not run when the feed is unchanged")
+}
+
+func TestInitGTFSManager_UnknownPostImportProcessor(t *testing.T) {
+	_, err := InitGTFSManager(context.Background(), Config{
+		GtfsURL:              models.GetFixturePath(t, "raba.zip"),
+		GTFSDataPath:         t.TempDir() + "/gtfs.db",
+		Env:                  appconf.Development,
+		PostImportProcessors: []string{"no-such-processor"},
+	})
+	assert.ErrorContains(t, err, "unknown post-import processor")
+}
@@ -119,6 +119,36 @@ func (manager *Manager) GetRealTimeVehicles() []gtfs.Vehicle {
 	return manager.realTimeVehicles
 }
 
+// VehicleOccupancyStatus returns the GTFS-RT occupancy status a vehicle
+// reports, such as "MANY_SEATS_AVAILABLE", or "" when it reports none. A
+// vehicle that only reports occupancy_percentage gets the status closest to it.
+func VehicleOccupancyStatus(vehicle *gtfs.Vehicle) string {
+	if vehicle == nil {
+		return ""
+	}
+	if vehicle.OccupancyStatus != nil && *vehicle.OccupancyStatus != gtfsrt.VehiclePosition_NO_DATA_AVAILABLE {
+		return vehicle.OccupancyStatus.String()
+	}
+	if vehicle.OccupancyPercentage == nil {
+		return ""
+	}
+	// 100 is the most passengers the vehicle was designed for.
+	var status gtfsrt.VehiclePosition_OccupancyStatus
+	switch percentage := *vehicle.OccupancyPercentage; {
+	case percentage == 0:
+		status = gtfsrt.VehiclePosition_EMPTY
+	case percentage < 50:
+		status = gtfsrt.VehiclePosition_MANY_SEATS_AVAILABLE
+	case percentage < 80:
+		status = gtfsrt.VehiclePosition_FEW_SEATS_AVAILABLE
+	case percentage < 100:
+		status = gtfsrt.VehiclePosition_STANDING_ROOM_ONLY
+	default:
+		status = gtfsrt.VehiclePosition_FULL
+	}
+	return status.String()
+}
+
 // It acquires the realTimeMutex internally; callers must NOT hold it.
 func (manager *Manager) GetAlertsByIDs(tripID, routeID, agencyID string) []gtfs.Alert {
 	manager.realTimeMutex.RLock()
@@ -149,35 +179,78 @@ func (manager *Manager) GetAlertsByIDs(tripID, routeID, agencyID string) []gtfs.
 	return alerts
 }
 
-// GetAlertsForTrip returns alerts matching the trip, its route, or agency.
+// TripAlerts are the service alerts affecting a trip, together with the
+// agency that operates it. AgencyID is empty when the trip is not in the
+// static data, in which case only alerts targeting the trip ID match.
+type TripAlerts struct {
+	AgencyID string
+	Alerts   []gtfs.Alert
+}
+
+// tripAlertScope is the static route and agency of a trip, which decide the
+// route- and agency-wide alerts that also apply to it.
+type tripAlertScope struct {
+	routeID  string
+	agencyID string
+}
+
+// GetTripAlerts returns alerts matching the trip, its route, or agency.
 // It acquires the realTimeMutex internally via GetAlertsByIDs.
+func (manager *Manager) GetTripAlerts(ctx context.Context, tripID string) TripAlerts {
+	scope := manager.tripAlertScope(ctx, tripID)
+	return TripAlerts{
+		AgencyID: scope.agencyID,
+		Alerts:   manager.GetAlertsByIDs(tripID, scope.routeID, scope.agencyID),
+	}
+}
+
+// GetAlertsForTrip returns alerts matching the trip, its route, or agency.
 func (manager *Manager) GetAlertsForTrip(ctx context.Context, tripID string) []gtfs.Alert {
-	var routeID string
-	var agencyID string
-
-	if manager.GtfsDB != nil {
-		trip, err := manager.GtfsDB.Queries.GetTrip(ctx, tripID)
-		if err == nil {
-			routeID = trip.RouteID
-			route, err := manager.GtfsDB.Queries.GetRoute(ctx, routeID)
-			if err == nil {
-				agencyID = route.AgencyID
-			} else if !errors.Is(err, sql.ErrNoRows) {
-				slog.WarnContext(ctx, "Failed to fetch route for alerts; degrading to trip+route matching only",
-					slog.String("trip_id", tripID),
-					slog.String("route_id", routeID),
-					slog.Any("error", err),
-				)
-			}
-		} else if !errors.Is(err, sql.ErrNoRows) {
-			slog.WarnContext(ctx, "Failed to fetch trip for alerts",
+	return manager.GetTripAlerts(ctx, tripID).Alerts
+}
+
+// tripAlertScope looks up the route and agency of a trip. Results come from
+// static data, so they are cached until the next static reload; lookups that
+// fail with a database error degrade to trip-only matching and are retried
+// on the next call.
+func (manager *Manager) tripAlertScope(ctx context.Context, tripID string) tripAlertScope {
+	if cached, ok := manager.tripAlertScopes.Load(tripID); ok {
+		return cached.(tripAlertScope)
+	}
+	if manager.GtfsDB == nil {
+		return tripAlertScope{}
+	}
+
+	var scope tripAlertScope
+	trip, err := manager.GtfsDB.Queries.GetTrip(ctx, tripID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "Failed to fetch trip for alerts; degrading to trip matching only",
 				slog.String("trip_id", tripID),
 				slog.Any("error", err),
 			)
+			return scope
 		}
+		manager.tripAlertScopes.Store(tripID, scope)
+		return scope
 	}
 
-	return manager.GetAlertsByIDs(tripID, routeID, agencyID)
+	scope.routeID = trip.RouteID
+	route, err := manager.GtfsDB.Queries.GetRoute(ctx, trip.RouteID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "Failed to fetch route for alerts; degrading to trip+route matching only",
+				slog.String("trip_id", tripID),
+				slog.String("route_id", trip.RouteID),
+				slog.Any("error", err),
+			)
+			return scope
+		}
+	} else {
+		scope.agencyID = route.AgencyID
+	}
+	manager.tripAlertScopes.Store(tripID, scope)
+	return scope
 }
 
 // GetAlertsForStop returns deduplicated alerts for the given stopID.
@@ -202,7 +275,16 @@ func (manager *Manager) GetAlertsForStop(stopID string) []gtfs.Alert {
 }
 
 // Fetches GTFS-RT data from a URL with per-feed headers.
-func loadRealtimeData(ctx context.Context, source string, headers map[string]string) (*gtfs.Realtime, error) {
+func loadRealtimeData(ctx context.Context, source string, headers map[string]string, logger *slog.Logger) (*gtfs.Realtime, error) {
+	body, err := fetchRealtimeBody(ctx, source, headers, logger)
+	if err != nil {
+		return nil, err
+	}
+	return gtfs.ParseRealtime(body, &gtfs.ParseRealtimeOptions{})
+}
+
+// fetchRealtimeBody downloads the raw GTFS-RT protobuf from a URL with per-feed headers.
+func fetchRealtimeBody(ctx context.Context, source string, headers map[string]string, logger *slog.Logger) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", source, nil)
 	if err != nil {
 		return nil, err
@@ -218,7 +300,7 @@ func loadRealtimeData(ctx context.Context, source string, headers map[string]str
 	}
 
 	defer logging.SafeCloseWithLogging(resp.Body,
-		slog.Default().With(slog.String("component", "gtfs_realtime_downloader")),
+		logger.With(slog.String("component", "gtfs_realtime_downloader")),
 		"http_response_body")
 
 	if resp.StatusCode != http.StatusOK {
@@ -235,7 +317,48 @@ func loadRealtimeData(ctx context.Context, source string, headers map[string]str
 		return nil, fmt.Errorf("GTFS-RT response exceeds size limit of %d bytes", maxBodySize)
 	}
 
-	return gtfs.ParseRealtime(body, &gtfs.ParseRealtimeOptions{})
+	return body, nil
+}
+
+// loadMirroredRealtimeData fetches a realtime feed and mirrors the snapshot when a
+// mirror is configured. If the upstream fetch fails before the manager is ready,
+// it falls back to the last mirrored snapshot and flags that source as degraded.
+func (manager *Manager) loadMirroredRealtimeData(ctx context.Context, feedID, kind, source string, headers map[string]string) (*gtfs.Realtime, error) {
+	if manager.mirror == nil {
+		return loadRealtimeData(ctx, source, headers, manager.config.logger())
+	}
+
+	degradedSource := feedID + "/" + kind
+	body, err := fetchRealtimeBody(ctx, source, headers, manager.config.logger())
+	if err == nil {
+		data, parseErr := gtfs.ParseRealtime(body, &gtfs.ParseRealtimeOptions{})
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if saveErr := manager.mirror.saveRealtime(feedID, kind, body); saveErr != nil {
+			logging.LogError(logging.FromContext(ctx), "Failed to mirror GTFS-RT snapshot", saveErr,
+				slog.String("feed", feedID),
+				slog.String("kind", kind))
+		}
+		manager.setDegraded(degradedSource, false)
+		return data, nil
+	}
+
+	if manager.IsReady() || ctx.Err() != nil {
+		return nil, err
+	}
+
+	mirrored, mirrorErr := manager.mirror.loadRealtime(feedID, kind)
+	if mirrorErr != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Warn("GTFS-RT upstream unreachable; using mirrored snapshot in degraded mode",
+		slog.String("feed", feedID),
+		slog.String("kind", kind),
+		slog.Any("error", err))
+	manager.setDegraded(degradedSource, true)
+	return gtfs.ParseRealtime(mirrored, &gtfs.ParseRealtimeOptions{})
 }
 
 // updateFeedRealtime fetches and processes realtime data for a single feed.
@@ -254,7 +377,7 @@ func (manager *Manager) updateFeedRealtime(ctx context.Context, feedCfg RTFeedCo
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			tripData, tripErr = loadRealtimeData(ctx, feedCfg.TripUpdatesURL, feedCfg.Headers)
+			tripData, tripErr = manager.loadMirroredRealtimeData(ctx, feedID, "trip-updates", feedCfg.TripUpdatesURL, feedCfg.Headers)
 			if tripErr != nil {
 				logging.LogError(logger, "Error loading GTFS-RT trip updates data", tripErr,
 					slog.String("feed", feedID),
@@ -267,7 +390,7 @@ func (manager *Manager) updateFeedRealtime(ctx context.Context, feedCfg RTFeedCo
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			vehicleData, vehicleErr = loadRealtimeData(ctx, feedCfg.VehiclePositionsURL, feedCfg.Headers)
+			vehicleData, vehicleErr = manager.loadMirroredRealtimeData(ctx, feedID, "vehicle-positions", feedCfg.VehiclePositionsURL, feedCfg.Headers)
 			if vehicleErr != nil {
 				logging.LogError(logger, "Error loading GTFS-RT vehicle positions data", vehicleErr,
 					slog.String("feed", feedID),
@@ -280,7 +403,7 @@ func (manager *Manager) updateFeedRealtime(ctx context.Context, feedCfg RTFeedCo
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			alertData, alertErr = loadRealtimeData(ctx, feedCfg.ServiceAlertsURL, feedCfg.Headers)
+			alertData, alertErr = manager.loadMirroredRealtimeData(ctx, feedID, "service-alerts", feedCfg.ServiceAlertsURL, feedCfg.Headers)
 			if alertErr != nil {
 				logging.LogError(logger, "Error loading GTFS-RT service alerts data", alertErr,
 					slog.String("feed", feedID),
@@ -298,7 +421,9 @@ func (manager *Manager) updateFeedRealtime(ctx context.Context, feedCfg RTFeedCo
 
 	// Apply agency-based filtering if configured for this feed.
 	// This runs before acquiring realTimeMutex to keep the critical section short.
+	manager.realTimeMutex.RLock()
 	agencyFilter := manager.feedAgencyFilter[feedID]
+	manager.realTimeMutex.RUnlock()
 	if len(agencyFilter) > 0 {
 		if tripData != nil && tripErr == nil {
 			tripData.Trips = manager.filterTripsByAgency(tripData.Trips, agencyFilter)
@@ -586,6 +711,37 @@ func alertMatchesAgency(ctx context.Context, manager *Manager, alert gtfs.Alert,
 	return false
 }
 
+// realtimeDisabledAgencyCheck returns a function reporting whether a trip
+// belongs to an agency with realtime disabled. Trips whose agency cannot be
+// resolved are kept. Agencies are looked up once per route. The caller must
+// hold realTimeMutex.
+func (manager *Manager) realtimeDisabledAgencyCheck() func(gtfs.TripID) bool {
+	if len(manager.realtimeDisabledAgencies) == 0 || manager.GtfsDB == nil {
+		return func(gtfs.TripID) bool { return false }
+	}
+	ctx := context.TODO()
+	disabledByRoute := make(map[string]bool)
+	return func(tripID gtfs.TripID) bool {
+		routeID := tripID.RouteID
+		if routeID == "" && tripID.ID != "" {
+			if trip, err := manager.GtfsDB.Queries.GetTrip(ctx, tripID.ID); err == nil {
+				routeID = trip.RouteID
+			}
+		}
+		if routeID == "" {
+			return false
+		}
+		disabled, ok := disabledByRoute[routeID]
+		if !ok {
+			if route, err := manager.GtfsDB.Queries.GetRoute(ctx, routeID); err == nil {
+				disabled = manager.realtimeDisabledAgencies[route.AgencyID]
+			}
+			disabledByRoute[routeID] = disabled
+		}
+		return disabled
+	}
+}
+
 func (manager *Manager) rebuildMergedRealtimeLocked() {
 	feedIDs := make([]string, 0, len(manager.feedTrips))
 	totalTrips := 0
@@ -595,9 +751,15 @@ func (manager *Manager) rebuildMergedRealtimeLocked() {
 	}
 	slices.Sort(feedIDs)
 
+	disabled := manager.realtimeDisabledAgencyCheck()
+
 	allTrips := make([]gtfs.Trip, 0, totalTrips)
 	for _, id := range feedIDs {
-		allTrips = append(allTrips, manager.feedTrips[id]...)
+		for _, trip := range manager.feedTrips[id] {
+			if !disabled(trip.ID) {
+				allTrips = append(allTrips, trip)
+			}
+		}
 	}
 
 	vehicleFeedIDs := make([]string, 0, len(manager.feedVehicles))
@@ -609,8 +771,20 @@ func (manager *Manager) rebuildMergedRealtimeLocked() {
 	slices.Sort(vehicleFeedIDs)
 
 	allVehicles := make([]gtfs.Vehicle, 0, totalVehicles)
+	// Providers may reuse vehicle IDs, so each feed also gets its own lookup.
+	vehicleLookupByFeed := make(map[string]map[string]int, len(vehicleFeedIDs))
 	for _, id := range vehicleFeedIDs {
-		allVehicles = append(allVehicles, manager.feedVehicles[id]...)
+		lookup := make(map[string]int, len(manager.feedVehicles[id]))
+		for _, vehicle := range manager.feedVehicles[id] {
+			if vehicle.Trip != nil && disabled(vehicle.Trip.ID) {
+				continue
+			}
+			if vehicle.ID != nil && vehicle.ID.ID != "" {
+				lookup[vehicle.ID.ID] = len(allVehicles)
+			}
+			allVehicles = append(allVehicles, vehicle)
+		}
+		vehicleLookupByFeed[id] = lookup
 	}
 
 	alertFeedIDs := make([]string, 0, len(manager.feedAlerts))
@@ -657,41 +831,44 @@ func (manager *Manager) rebuildMergedRealtimeLocked() {
 		byAgency: make(map[string][]gtfs.Alert),
 		byStop:   make(map[string][]gtfs.Alert),
 	}
+	feedAlerts := make([][]gtfs.Alert, 0, len(alertFeedIDs))
 	for _, id := range alertFeedIDs {
-		for _, alert := range manager.feedAlerts[id] {
-			if alert.InformedEntities == nil || alert.ID == "" {
-				continue
+		feedAlerts = append(feedAlerts, manager.feedAlerts[id])
+	}
+	// The same alert can arrive from several feeds under different IDs.
+	for _, alert := range mergeAlerts(feedAlerts) {
+		if alert.InformedEntities == nil {
+			continue
+		}
+		// Per-alert per-bucket dedup: prevents the same alert from being appended
+		// to the same bucket more than once when it has multiple InformedEntities
+		// referencing the same key (e.g. two entities both pointing to stop "S1").
+		// Capacity is set to the entity count so the map never needs to grow.
+		n := len(alert.InformedEntities)
+		seenTrip := make(map[string]bool, n)
+		seenRoute := make(map[string]bool, n)
+		seenAgency := make(map[string]bool, n)
+		seenStop := make(map[string]bool, n)
+		for _, entity := range alert.InformedEntities {
+			if entity.TripID != nil && !seenTrip[entity.TripID.ID] {
+				seenTrip[entity.TripID.ID] = true
+				idx.byTrip[entity.TripID.ID] = append(idx.byTrip[entity.TripID.ID], alert)
 			}
-			// Per-alert per-bucket dedup: prevents the same alert from being appended
-			// to the same bucket more than once when it has multiple InformedEntities
-			// referencing the same key (e.g. two entities both pointing to stop "S1").
-			// Capacity is set to the entity count so the map never needs to grow.
-			n := len(alert.InformedEntities)
-			seenTrip := make(map[string]bool, n)
-			seenRoute := make(map[string]bool, n)
-			seenAgency := make(map[string]bool, n)
-			seenStop := make(map[string]bool, n)
-			for _, entity := range alert.InformedEntities {
-				if entity.TripID != nil && !seenTrip[entity.TripID.ID] {
-					seenTrip[entity.TripID.ID] = true
-					idx.byTrip[entity.TripID.ID] = append(idx.byTrip[entity.TripID.ID], alert)
-				}
-				// Only match route-level entities that have no stop or trip restriction.
-				// Entities with {routeId + stopId} are stop-specific alerts and are filed
-				// in byStop only (matching Java's inverted index bucket behaviour).
-				if entity.RouteID != nil && entity.StopID == nil && entity.TripID == nil && !seenRoute[*entity.RouteID] {
-					seenRoute[*entity.RouteID] = true
-					idx.byRoute[*entity.RouteID] = append(idx.byRoute[*entity.RouteID], alert)
-				}
-				// Only match agency-wide alerts: entity has agencyId but no route or trip restriction.
-				if entity.AgencyID != nil && entity.RouteID == nil && entity.TripID == nil && !seenAgency[*entity.AgencyID] {
-					seenAgency[*entity.AgencyID] = true
-					idx.byAgency[*entity.AgencyID] = append(idx.byAgency[*entity.AgencyID], alert)
-				}
-				if entity.StopID != nil && !seenStop[*entity.StopID] {
-					seenStop[*entity.StopID] = true
-					idx.byStop[*entity.StopID] = append(idx.byStop[*entity.StopID], alert)
-				}
+			// Only match route-level entities that have no stop or trip restriction.
+			// Entities with {routeId + stopId} are stop-specific alerts and are filed
+			// in byStop only (matching Java's inverted index bucket behaviour).
+			if entity.RouteID != nil && entity.StopID == nil && entity.TripID == nil && !seenRoute[*entity.RouteID] {
+				seenRoute[*entity.RouteID] = true
+				idx.byRoute[*entity.RouteID] = append(idx.byRoute[*entity.RouteID], alert)
+			}
+			// Only match agency-wide alerts: entity has agencyId but no route or trip restriction.
+			if entity.AgencyID != nil && entity.RouteID == nil && entity.TripID == nil && !seenAgency[*entity.AgencyID] {
+				seenAgency[*entity.AgencyID] = true
+				idx.byAgency[*entity.AgencyID] = append(idx.byAgency[*entity.AgencyID], alert)
+			}
+			if entity.StopID != nil && !seenStop[*entity.StopID] {
+				seenStop[*entity.StopID] = true
+				idx.byStop[*entity.StopID] = append(idx.byStop[*entity.StopID], alert)
 			}
 		}
 	}
@@ -701,8 +878,32 @@ func (manager *Manager) rebuildMergedRealtimeLocked() {
 	manager.realTimeTripLookup = tripLookup
 	manager.realTimeVehicleLookupByTrip = vehicleLookupByTrip
 	manager.realTimeVehicleLookupByVehicle = vehicleLookupByVehicle
+	manager.realTimeVehicleLookupByFeed = vehicleLookupByFeed
 	manager.duplicatedVehicleByRoute = duplicatedVehicleByRoute
 	manager.alertIdx = idx
+
+	manager.notifyRealtimeChangedLocked()
+}
+
+// notifyRealtimeChangedLocked wakes everyone waiting on RealtimeChanged.
+// Callers must hold realTimeMutex.
+func (manager *Manager) notifyRealtimeChangedLocked() {
+	if manager.realtimeChanged != nil {
+		close(manager.realtimeChanged)
+		manager.realtimeChanged = nil
+	}
+}
+
+// RealtimeChanged returns a channel that is closed the next time the merged
+// realtime view is rebuilt. Feeds are merged after every fetch, so the data
+// may be unchanged when it is closed.
+func (manager *Manager) RealtimeChanged() <-chan struct{} {
+	manager.realTimeMutex.Lock()
+	defer manager.realTimeMutex.Unlock()
+	if manager.realtimeChanged == nil {
+		manager.realtimeChanged = make(chan struct{})
+	}
+	return manager.realtimeChanged
 }
 
 // calculateBackoff computes the next polling interval using exponential backoff with jitter
@@ -721,21 +922,27 @@ func calculateBackoff(baseInterval time.Duration, consecutiveErrors int, maxInte
 		nextInterval = maxInterval
 	}
 
-	// +/- 10% Jitter prevents thundering herd behavior across failing feeds
-	jitter := time.Duration((rand.Float64() - 0.5) * 0.2 * float64(nextInterval))
-	return nextInterval + jitter
+	// Jitter prevents thundering herd behavior across failing feeds
+	return withJitter(nextInterval)
+}
+
+// withJitter shifts an interval by a random +/- 10%, so feeds polled at the
+// same interval spread out instead of fetching in lockstep.
+func withJitter(interval time.Duration) time.Duration {
+	return interval + time.Duration((rand.Float64()-0.5)*0.2*float64(interval))
 }
 
-// pollFeed runs the polling loop for a single feed. Each feed gets its own
-// goroutine with exponential backoff on errors, reporting to prometheus metrics.
-func (manager *Manager) pollFeed(feedCfg RTFeedConfig) {
+// pollFeed runs the polling loop for a single feed until shutdown or until
+// ctx is cancelled. Each feed gets its own goroutine with exponential backoff
+// on errors, reporting to prometheus metrics.
+func (manager *Manager) pollFeed(ctx context.Context, feedCfg RTFeedConfig) {
 	defer manager.wg.Done()
 
 	if feedCfg.RefreshInterval <= 0 {
 		feedCfg.RefreshInterval = 30
 	}
 
-	logger := slog.Default().With(slog.String("component", "gtfs_realtime_updater"))
+	logger := manager.config.logger().With(slog.String("component", "gtfs_realtime_updater"))
 	baseInterval := time.Duration(feedCfg.RefreshInterval) * time.Second
 	maxInterval := 5 * time.Minute
 
@@ -753,7 +960,7 @@ func (manager *Manager) pollFeed(feedCfg RTFeedConfig) {
 	)
 
 	// Use a Timer instead of Ticker to dynamically control intervals (backoff/jitter)
-	timer := time.NewTimer(baseInterval) // Wait one interval before first poll (prevent double fetch)
+	timer := time.NewTimer(withJitter(baseInterval)) // Wait one interval before first poll (prevent double fetch)
 	defer timer.Stop()
 
 	for {
@@ -762,11 +969,15 @@ func (manager *Manager) pollFeed(feedCfg RTFeedConfig) {
 			logging.LogOperation(logger, "shutting_down_realtime_feed_poller",
 				slog.String("feed", feedCfg.ID))
 			return
+		case <-ctx.Done():
+			logging.LogOperation(logger, "stopped_realtime_feed_poller",
+				slog.String("feed", feedCfg.ID))
+			return
 		case <-timer.C:
 			func() {
 				start := time.Now()
 
-				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+				ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 				defer cancel()
 				ctx = logging.WithLogger(ctx, logger)
 
@@ -780,7 +991,18 @@ func (manager *Manager) pollFeed(feedCfg RTFeedConfig) {
 					manager.Metrics.FeedFetchDuration.WithLabelValues(feedCfg.ID).Observe(duration.Seconds())
 				}
 
+				if manager.Metrics != nil {
+					status := "failure"
+					if hasNewData {
+						status = "success"
+					}
+					manager.Metrics.FeedFetchTotal.WithLabelValues(feedCfg.ID, status).Inc()
+				}
+
 				if hasNewData {
+					manager.observeTripStarts(ctx, time.Now())
+					manager.observeArrivalHistory(ctx, time.Now())
+					manager.sampleScheduleDeviations(time.Now())
 					consecutiveErrors = 0
 					lastSuccessfulFetch = time.Now()
 					feedCleared = false // Reset clearing flag on success
@@ -790,7 +1012,7 @@ func (manager *Manager) pollFeed(feedCfg RTFeedConfig) {
 						manager.Metrics.FeedConsecutiveErrors.WithLabelValues(feedCfg.ID).Set(0)
 					}
 
-					timer.Reset(baseInterval) // Reset to standard interval on success
+					timer.Reset(withJitter(baseInterval)) // Reset to standard interval on success
 				} else {
 					consecutiveErrors++
 
@@ -18,6 +18,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
 	logging "maglev.onebusaway.org/internal/logging"
 )
 
@@ -46,6 +48,42 @@ func TestGetAlertsForTrip(t *testing.T) {
 	assert.Equal(t, "alert1", alerts[0].ID)
 }
 
+func TestGetTripAlerts_MatchesRouteAndCachesScope(t *testing.T) {
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	// MockAddTrip uses an empty service ID, which must exist in calendar.
+	_, err = client.DB.Exec("INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('', 1, 1, 1, 1, 1, 1, 1, '20240101', '20241231')")
+	require.NoError(t, err)
+
+	manager := newManager(Config{}, client)
+	manager.MockAddAgency("agency1", "Agency")
+	manager.MockAddRoute("route1", "agency1", "R1")
+	manager.MockAddTrip("trip1", "agency1", "route1")
+
+	routeID := "route1"
+	manager.MockAddAlert("feed-0", gtfs.Alert{
+		ID:               "route-alert",
+		InformedEntities: []gtfs.AlertInformedEntity{{RouteID: &routeID}},
+	})
+
+	tripAlerts := manager.GetTripAlerts(context.Background(), "trip1")
+	assert.Equal(t, "agency1", tripAlerts.AgencyID)
+	require.Len(t, tripAlerts.Alerts, 1)
+	assert.Equal(t, "route-alert", tripAlerts.Alerts[0].ID)
+
+	// The scope is cached until the static data changes.
+	_, err = client.DB.Exec("DELETE FROM trips WHERE id = ?", "trip1")
+	require.NoError(t, err)
+	assert.Equal(t, "agency1", manager.GetTripAlerts(context.Background(), "trip1").AgencyID)
+
+	manager.tripAlertScopes.Clear()
+	tripAlerts = manager.GetTripAlerts(context.Background(), "trip1")
+	assert.Empty(t, tripAlerts.AgencyID)
+	assert.Empty(t, tripAlerts.Alerts, "unknown trips only match trip-targeted alerts")
+}
+
 func TestGetAlertsForStop(t *testing.T) {
 	stopID := "stop123"
 	manager := &Manager{
@@ -94,6 +132,29 @@ func TestRebuildRealTimeTripLookup(t *testing.T) {
 	assert.Equal(t, 1, manager.realTimeTripLookup["trip2"])
 }
 
+func TestRealtimeChanged_ClosedOnRebuild(t *testing.T) {
+	manager := &Manager{}
+
+	changed := manager.RealtimeChanged()
+	assert.Equal(t, changed, manager.RealtimeChanged(), "waiters share a channel until the next rebuild")
+	select {
+	case <-changed:
+		t.Fatal("closed before any rebuild")
+	default:
+	}
+
+	manager.realTimeMutex.Lock()
+	manager.rebuildMergedRealtimeLocked()
+	manager.realTimeMutex.Unlock()
+
+	select {
+	case <-changed:
+	default:
+		t.Fatal("not closed by the rebuild")
+	}
+	assert.NotEqual(t, changed, manager.RealtimeChanged())
+}
+
 func TestRebuildRealTimeVehicleLookupByTrip(t *testing.T) {
 	trip1 := &gtfs.Trip{
 		ID: gtfs.TripID{ID: "trip1"},
@@ -196,7 +257,7 @@ func TestLoadRealtimeData_Non200StatusCode(t *testing.T) {
 			}))
 			defer server.Close()
 
-			result, err := loadRealtimeData(context.Background(), server.URL, nil)
+			result, err := loadRealtimeData(context.Background(), server.URL, nil, slog.Default())
 			assert.Error(t, err)
 			assert.Nil(t, result)
 			assert.Contains(t, err.Error(), fmt.Sprintf("%d", tt.statusCode))
@@ -1240,6 +1301,18 @@ func TestCalculateBackoff(t *testing.T) {
 	}
 }
 
+func TestWithJitter(t *testing.T) {
+	interval := 30 * time.Second
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		result := withJitter(interval)
+		assert.GreaterOrEqual(t, result, 27*time.Second)
+		assert.LessOrEqual(t, result, 33*time.Second)
+		seen[result] = true
+	}
+	assert.Greater(t, len(seen), 1, "polls should not all land on the same instant")
+}
+
 func TestUpdateFeedRealtime_SubFeedSuccess_OrLogic(t *testing.T) {
 	// A server that returns 200 OK AND a valid GTFS-RT protobuf payload
 	goodServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1509,3 +1582,93 @@ func TestGetDuplicatedVehiclesForRoute_NoMatchReturnsEmpty(t *testing.T) {
 
 	assert.Empty(t, result, "route miss should return empty result")
 }
+
+func TestVehicleOccupancyStatus(t *testing.T) {
+	status := func(s gtfsrt.VehiclePosition_OccupancyStatus) *gtfs.OccupancyStatus { return &s }
+	percentage := func(p uint32) *uint32 { return &p }
+
+	tests := []struct {
+		name    string
+		vehicle *gtfs.Vehicle
+		want    string
+	}{
+		{"nil vehicle", nil, ""},
+		{"no occupancy", &gtfs.Vehicle{}, ""},
+		{"status", &gtfs.Vehicle{OccupancyStatus: status(gtfsrt.VehiclePosition_STANDING_ROOM_ONLY)}, "STANDING_ROOM_ONLY"},
+		{"status wins over percentage", &gtfs.Vehicle{
+			OccupancyStatus:     status(gtfsrt.VehiclePosition_FULL),
+			OccupancyPercentage: percentage(10),
+		}, "FULL"},
+		{"no data falls back to percentage", &gtfs.Vehicle{
+			OccupancyStatus:     status(gtfsrt.VehiclePosition_NO_DATA_AVAILABLE),
+			OccupancyPercentage: percentage(60),
+		}, "FEW_SEATS_AVAILABLE"},
+		{"no data alone", &gtfs.Vehicle{OccupancyStatus: status(gtfsrt.VehiclePosition_NO_DATA_AVAILABLE)}, ""},
+		{"empty", &gtfs.Vehicle{OccupancyPercentage: percentage(0)}, "EMPTY"},
+		{"many seats", &gtfs.Vehicle{OccupancyPercentage: percentage(49)}, "MANY_SEATS_AVAILABLE"},
+		{"standing", &gtfs.Vehicle{OccupancyPercentage: percentage(80)}, "STANDING_ROOM_ONLY"},
+		{"over capacity", &gtfs.Vehicle{OccupancyPercentage: percentage(130)}, "FULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VehicleOccupancyStatus(tt.vehicle))
+		})
+	}
+}
+
+func TestGetAddedTripsAndIsTripCanceled(t *testing.T) {
+	manager := &Manager{
+		realTimeMutex: sync.RWMutex{},
+		feedVehicles: map[string][]gtfs.Vehicle{
+			"feed-0": {
+				{
+					ID: &gtfs.VehicleID{ID: "v1"},
+					Trip: &gtfs.Trip{ID: gtfs.TripID{
+						ID:                   "added-1",
+						RouteID:              "route-A",
+						ScheduleRelationship: gtfsrt.TripDescriptor_ADDED,
+					}},
+				},
+				{
+					ID: &gtfs.VehicleID{ID: "v2"},
+					Trip: &gtfs.Trip{ID: gtfs.TripID{
+						ID:                   "added-2",
+						RouteID:              "route-B",
+						ScheduleRelationship: gtfsrt.TripDescriptor_ADDED,
+					}},
+				},
+				{
+					ID: &gtfs.VehicleID{ID: "v3"},
+					Trip: &gtfs.Trip{ID: gtfs.TripID{
+						ID:                   "canceled-by-vehicle",
+						ScheduleRelationship: gtfsrt.TripDescriptor_CANCELED,
+					}},
+				},
+			},
+		},
+		feedTrips: map[string][]gtfs.Trip{
+			"feed-0": {
+				// The update omits the route, which the vehicle serving it gives.
+				{ID: gtfs.TripID{ID: "added-1", ScheduleRelationship: gtfsrt.TripDescriptor_ADDED}},
+				{ID: gtfs.TripID{ID: "canceled-by-update", ScheduleRelationship: gtfsrt.TripDescriptor_CANCELED}},
+				{ID: gtfs.TripID{ID: "scheduled"}},
+			},
+		},
+	}
+	manager.rebuildMergedRealtimeLocked()
+
+	added := manager.GetAddedTrips()
+	require.Len(t, added, 2, "a trip with both an update and a vehicle is listed once")
+	assert.Equal(t, "added-1", added[0].ID.ID)
+	assert.Equal(t, "route-A", added[0].ID.RouteID)
+	require.NotNil(t, added[0].Vehicle)
+	assert.Equal(t, "v1", added[0].Vehicle.ID.ID)
+	assert.Equal(t, "added-2", added[1].ID.ID)
+	assert.Equal(t, "v2", added[1].Vehicle.ID.ID)
+
+	assert.True(t, manager.IsTripCanceled("canceled-by-update"))
+	assert.True(t, manager.IsTripCanceled("canceled-by-vehicle"))
+	assert.False(t, manager.IsTripCanceled("scheduled"))
+	assert.False(t, manager.IsTripCanceled("added-1"))
+	assert.False(t, manager.IsTripCanceled("unknown"))
+}
@@ -0,0 +1,167 @@
+package gtfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/logging"
+)
+
+// ErrStaticReloadRefused is returned by ReloadStatic when the incoming dataset
+// removes more trips or stops than Config.ReloadGuardMaxDropPercent allows.
+// The current dataset stays in service.
+var ErrStaticReloadRefused = errors.New("static GTFS reload refused")
+
+// RefusedReload describes a static dataset kept out of service by the reload
+// guard, typically an upstream feed that was truncated by mistake.
+type RefusedReload struct {
+	Source    string    `json:"source"`
+	Hash      string    `json:"hash"`
+	RefusedAt time.Time `json:"refusedAt"`
+	OldTrips  int       `json:"oldTrips"`
+	NewTrips  int       `json:"newTrips"`
+	OldStops  int       `json:"oldStops"`
+	NewStops  int       `json:"newStops"`
+}
+
+// reloadGuard tracks the dataset refused last and the one an admin approved.
+type reloadGuard struct {
+	mu           sync.Mutex
+	refused      *RefusedReload
+	approvedHash string
+}
+
+// RefusedStaticReload returns the dataset the reload guard is currently
+// refusing, or nil when the latest reload was accepted.
+func (manager *Manager) RefusedStaticReload() *RefusedReload {
+	manager.reloadGuard.mu.Lock()
+	defer manager.reloadGuard.mu.Unlock()
+	if manager.reloadGuard.refused == nil {
+		return nil
+	}
+	refused := *manager.reloadGuard.refused
+	return &refused
+}
+
+// ApproveStaticReload overrides the reload guard for the refused dataset with
+// the given hash and reloads in the background. Approving by hash ensures the
+// dataset that goes live is the one the admin reviewed.
+func (manager *Manager) ApproveStaticReload(hash string) error {
+	guard := &manager.reloadGuard
+	guard.mu.Lock()
+	if guard.refused == nil || guard.refused.Hash != hash {
+		guard.mu.Unlock()
+		return fmt.Errorf("no refused static reload with hash %q", hash)
+	}
+	guard.approvedHash = hash
+	guard.mu.Unlock()
+
+	logger := manager.config.logger().With(slog.String("component", "gtfs_updater"))
+	logging.LogOperation(logger, "static_reload_approved", slog.String("hash", hash))
+
+	manager.wg.Add(1)
+	go func() {
+		defer manager.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if _, err := manager.ReloadStatic(ctx); err != nil {
+			logging.LogError(logger, "Error reloading approved GTFS data", err)
+		}
+	}()
+	return nil
+}
+
+// checkReloadGuard refuses data that drops more than the configured share of
+// the current dataset's trips or stops, unless an admin approved it. A first
+// import, an unchanged dataset and a disabled guard always pass.
+func (manager *Manager) checkReloadGuard(ctx context.Context, data *gtfsdb.GtfsData, logger *slog.Logger) error {
+	maxDrop := manager.config.ReloadGuardMaxDropPercent
+	if maxDrop <= 0 {
+		return nil
+	}
+	metadata, err := manager.GtfsDB.Queries.GetImportMetadata(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error checking import metadata: %w", err)
+	}
+	if metadata.FileHash == data.Hash && metadata.FileSource == data.Source {
+		return nil
+	}
+
+	oldTrips, err := manager.GtfsDB.Queries.CountTrips(ctx)
+	if err != nil {
+		return err
+	}
+	oldStops, err := manager.GtfsDB.Queries.CountStops(ctx)
+	if err != nil {
+		return err
+	}
+	newTrips, newStops := len(data.Static.Trips), len(data.Static.Stops)
+	tripDrop := dropPercent(int(oldTrips), newTrips)
+	stopDrop := dropPercent(int(oldStops), newStops)
+	if tripDrop <= maxDrop && stopDrop <= maxDrop {
+		return nil
+	}
+
+	guard := &manager.reloadGuard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	if guard.approvedHash == data.Hash {
+		logging.LogOperation(logger, "static_reload_guard_overridden",
+			slog.String("hash", data.Hash),
+			slog.Float64("trip_drop_percent", tripDrop),
+			slog.Float64("stop_drop_percent", stopDrop))
+		return nil
+	}
+
+	guard.refused = &RefusedReload{
+		Source:    data.Source,
+		Hash:      data.Hash,
+		RefusedAt: time.Now(),
+		OldTrips:  int(oldTrips),
+		NewTrips:  newTrips,
+		OldStops:  int(oldStops),
+		NewStops:  newStops,
+	}
+	if manager.Metrics != nil && manager.Metrics.StaticReloadRefused != nil {
+		manager.Metrics.StaticReloadRefused.Set(1)
+	}
+	logger.Error("static GTFS reload refused: dataset drops too many trips or stops",
+		slog.String("source", data.Source),
+		slog.String("hash", data.Hash),
+		slog.Int("old_trips", int(oldTrips)),
+		slog.Int("new_trips", newTrips),
+		slog.Int("old_stops", int(oldStops)),
+		slog.Int("new_stops", newStops),
+		slog.Float64("max_drop_percent", maxDrop))
+	return fmt.Errorf("%w: trips %d -> %d, stops %d -> %d exceeds the %.1f%% limit",
+		ErrStaticReloadRefused, oldTrips, newTrips, oldStops, newStops, maxDrop)
+}
+
+// clearRefusedReload records that the latest reload was accepted.
+func (manager *Manager) clearRefusedReload() {
+	guard := &manager.reloadGuard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.refused = nil
+	guard.approvedHash = ""
+	if manager.Metrics != nil && manager.Metrics.StaticReloadRefused != nil {
+		manager.Metrics.StaticReloadRefused.Set(0)
+	}
+}
+
+// dropPercent is the share of old removed in new, or 0 when nothing was removed.
+func dropPercent(old, new int) float64 {
+	if old <= 0 || new >= old {
+		return 0
+	}
+	return float64(old-new) * 100 / float64(old)
+}
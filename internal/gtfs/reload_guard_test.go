@@ -0,0 +1,92 @@
+package gtfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// newGuardedManager loads RABA, then points the static feed at gtfs.zip, which
+// has about half as many stops.
+func newGuardedManager(t *testing.T, maxDropPercent float64) *Manager {
+	t.Helper()
+	manager, err := InitGTFSManager(context.Background(), Config{
+		GtfsURL:                   models.GetFixturePath(t, "raba.zip"),
+		GTFSDataPath:              t.TempDir() + "/gtfs.db",
+		Env:                       appconf.Development,
+		ReloadGuardMaxDropPercent: maxDropPercent,
+	})
+	require.NoError(t, err)
+	t.Cleanup(manager.Shutdown)
+	manager.config.GtfsURL = models.GetFixturePath(t, "gtfs.zip")
+	return manager
+}
+
+func TestReloadGuard_RefusesLargeDrop(t *testing.T) {
+	manager := newGuardedManager(t, 20)
+	ctx := context.Background()
+	stopsBefore, err := manager.GtfsDB.Queries.CountStops(ctx)
+	require.NoError(t, err)
+
+	changed, err := manager.ReloadStatic(ctx)
+	assert.ErrorIs(t, err, ErrStaticReloadRefused)
+	assert.False(t, changed)
+
+	stopsAfter, err := manager.GtfsDB.Queries.CountStops(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, stopsBefore, stopsAfter, "the current dataset stays in service")
+
+	refused := manager.RefusedStaticReload()
+	require.NotNil(t, refused)
+	assert.Equal(t, int(stopsBefore), refused.OldStops)
+	assert.Less(t, refused.NewStops, refused.OldStops)
+	assert.NotEmpty(t, refused.Hash)
+
+	// A second attempt with the same data stays refused.
+	_, err = manager.ReloadStatic(ctx)
+	assert.ErrorIs(t, err, ErrStaticReloadRefused)
+}
+
+func TestReloadGuard_Approve(t *testing.T) {
+	manager := newGuardedManager(t, 20)
+	ctx := context.Background()
+
+	_, err := manager.ReloadStatic(ctx)
+	require.ErrorIs(t, err, ErrStaticReloadRefused)
+	refused := manager.RefusedStaticReload()
+	require.NotNil(t, refused)
+
+	assert.Error(t, manager.ApproveStaticReload("not-the-hash"))
+	require.NoError(t, manager.ApproveStaticReload(refused.Hash))
+
+	require.Eventually(t, func() bool {
+		return manager.RefusedStaticReload() == nil
+	}, 60*time.Second, 50*time.Millisecond)
+	stops, err := manager.GtfsDB.Queries.CountStops(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(refused.NewStops), stops)
+
+	assert.Error(t, manager.ApproveStaticReload(refused.Hash), "nothing is refused any more")
+}
+
+func TestReloadGuard_Disabled(t *testing.T) {
+	manager := newGuardedManager(t, 0)
+
+	changed, err := manager.ReloadStatic(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, manager.RefusedStaticReload())
+}
+
+func TestDropPercent(t *testing.T) {
+	assert.Equal(t, 0.0, dropPercent(0, 10))
+	assert.Equal(t, 0.0, dropPercent(10, 10))
+	assert.Equal(t, 0.0, dropPercent(10, 20))
+	assert.Equal(t, 25.0, dropPercent(100, 75))
+	assert.Equal(t, 100.0, dropPercent(100, 0))
+}
@@ -0,0 +1,185 @@
+package gtfs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// gtfsRealtimeVersion is the GTFS-RT version of the republished feeds.
+const gtfsRealtimeVersion = "2.0"
+
+// TripUpdatesFeed returns the merged trip updates of all realtime feeds, with
+// realtime-disabled agencies already left out, as one full-dataset GTFS-RT
+// message timestamped now. Trips only known from vehicle positions or alerts
+// have no update and are not included.
+func (manager *Manager) TripUpdatesFeed(now time.Time) *gtfsrt.FeedMessage {
+	manager.realTimeMutex.RLock()
+	defer manager.realTimeMutex.RUnlock()
+
+	message := newFeedMessage(now)
+	ids := make(map[string]bool, len(manager.realTimeTrips))
+	for i, trip := range manager.realTimeTrips {
+		if !trip.IsEntityInMessage && trip.Delay == nil && len(trip.StopTimeUpdates) == 0 {
+			continue
+		}
+		update := &gtfsrt.TripUpdate{
+			Trip:           tripDescriptor(trip.ID),
+			StopTimeUpdate: make([]*gtfsrt.TripUpdate_StopTimeUpdate, 0, len(trip.StopTimeUpdates)),
+		}
+		if trip.Vehicle != nil && trip.Vehicle.ID != nil {
+			update.Vehicle = vehicleDescriptor(*trip.Vehicle.ID)
+		}
+		if trip.Delay != nil {
+			update.Delay = proto.Int32(int32(trip.Delay.Seconds()))
+		}
+		for _, stu := range trip.StopTimeUpdates {
+			update.StopTimeUpdate = append(update.StopTimeUpdate, &gtfsrt.TripUpdate_StopTimeUpdate{
+				StopSequence:         stu.StopSequence,
+				StopId:               stu.StopID,
+				Arrival:              stopTimeEvent(stu.Arrival),
+				Departure:            stopTimeEvent(stu.Departure),
+				ScheduleRelationship: stu.ScheduleRelationship.Enum(),
+			})
+		}
+		message.Entity = append(message.Entity, &gtfsrt.FeedEntity{
+			Id:         proto.String(feedEntityID(ids, trip.ID.ID, i)),
+			TripUpdate: update,
+		})
+	}
+	return message
+}
+
+// VehiclePositionsFeed returns the merged vehicle positions of all realtime
+// feeds, after stale vehicles have expired, as one full-dataset GTFS-RT
+// message timestamped now.
+func (manager *Manager) VehiclePositionsFeed(now time.Time) *gtfsrt.FeedMessage {
+	manager.realTimeMutex.RLock()
+	defer manager.realTimeMutex.RUnlock()
+
+	message := newFeedMessage(now)
+	ids := make(map[string]bool, len(manager.realTimeVehicles))
+	for i, vehicle := range manager.realTimeVehicles {
+		position := &gtfsrt.VehiclePosition{
+			CurrentStopSequence: vehicle.CurrentStopSequence,
+			StopId:              vehicle.StopID,
+			CurrentStatus:       vehicle.CurrentStatus,
+			OccupancyStatus:     vehicle.OccupancyStatus,
+			OccupancyPercentage: vehicle.OccupancyPercentage,
+		}
+		var vehicleID string
+		if vehicle.ID != nil {
+			vehicleID = vehicle.ID.ID
+			position.Vehicle = vehicleDescriptor(*vehicle.ID)
+		}
+		if vehicle.Trip != nil {
+			position.Trip = tripDescriptor(vehicle.Trip.ID)
+		}
+		if p := vehicle.Position; p != nil && p.Latitude != nil && p.Longitude != nil {
+			position.Position = &gtfsrt.Position{
+				Latitude:  p.Latitude,
+				Longitude: p.Longitude,
+				Bearing:   p.Bearing,
+				Odometer:  p.Odometer,
+				Speed:     p.Speed,
+			}
+		}
+		if vehicle.Timestamp != nil {
+			position.Timestamp = proto.Uint64(uint64(vehicle.Timestamp.Unix()))
+		}
+		if vehicle.CongestionLevel != gtfsrt.VehiclePosition_UNKNOWN_CONGESTION_LEVEL {
+			position.CongestionLevel = vehicle.CongestionLevel.Enum()
+		}
+		message.Entity = append(message.Entity, &gtfsrt.FeedEntity{
+			Id:      proto.String(feedEntityID(ids, vehicleID, i)),
+			Vehicle: position,
+		})
+	}
+	return message
+}
+
+func newFeedMessage(now time.Time) *gtfsrt.FeedMessage {
+	return &gtfsrt.FeedMessage{
+		Header: &gtfsrt.FeedHeader{
+			GtfsRealtimeVersion: proto.String(gtfsRealtimeVersion),
+			Incrementality:      gtfsrt.FeedHeader_FULL_DATASET.Enum(),
+			Timestamp:           proto.Uint64(uint64(now.Unix())),
+		},
+		Entity: []*gtfsrt.FeedEntity{},
+	}
+}
+
+// feedEntityID returns id as the ID of the index-th entity, unless it is
+// empty or already taken. Upstream feeds may reuse trip and vehicle IDs, but
+// entity IDs must be unique within the merged feed.
+func feedEntityID(taken map[string]bool, id string, index int) string {
+	if id == "" || taken[id] {
+		id = "entity_" + strconv.Itoa(index)
+	}
+	taken[id] = true
+	return id
+}
+
+func tripDescriptor(id gtfs.TripID) *gtfsrt.TripDescriptor {
+	descriptor := &gtfsrt.TripDescriptor{
+		ScheduleRelationship: id.ScheduleRelationship.Enum(),
+	}
+	if id.ID != "" {
+		descriptor.TripId = proto.String(id.ID)
+	}
+	if id.RouteID != "" {
+		descriptor.RouteId = proto.String(id.RouteID)
+	}
+	switch id.DirectionID {
+	case gtfs.DirectionID_False:
+		descriptor.DirectionId = proto.Uint32(0)
+	case gtfs.DirectionID_True:
+		descriptor.DirectionId = proto.Uint32(1)
+	}
+	if id.HasStartTime {
+		descriptor.StartTime = proto.String(formatGtfsTime(id.StartTime))
+	}
+	if id.HasStartDate {
+		descriptor.StartDate = proto.String(id.StartDate.Format("20060102"))
+	}
+	return descriptor
+}
+
+// formatGtfsTime formats a time since the start of the service day as
+// HH:MM:SS, with hours past 23 for trips starting after midnight.
+func formatGtfsTime(d time.Duration) string {
+	seconds := int64(d.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+func vehicleDescriptor(id gtfs.VehicleID) *gtfsrt.VehicleDescriptor {
+	descriptor := &gtfsrt.VehicleDescriptor{}
+	if id.ID != "" {
+		descriptor.Id = proto.String(id.ID)
+	}
+	if id.Label != "" {
+		descriptor.Label = proto.String(id.Label)
+	}
+	if id.LicensePlate != "" {
+		descriptor.LicensePlate = proto.String(id.LicensePlate)
+	}
+	return descriptor
+}
+
+func stopTimeEvent(event *gtfs.StopTimeEvent) *gtfsrt.TripUpdate_StopTimeEvent {
+	if event == nil {
+		return nil
+	}
+	result := &gtfsrt.TripUpdate_StopTimeEvent{Uncertainty: event.Uncertainty}
+	if event.Time != nil {
+		result.Time = proto.Int64(event.Time.Unix())
+	}
+	if event.Delay != nil {
+		result.Delay = proto.Int32(int32(event.Delay.Seconds()))
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package gtfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func parseRealtimeTestdata(t *testing.T, name string) *gtfs.Realtime {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("../../testdata", name))
+	require.NoError(t, err)
+	realtime, err := gtfs.ParseRealtime(data, &gtfs.ParseRealtimeOptions{})
+	require.NoError(t, err)
+	return realtime
+}
+
+// republish marshals a republished feed and parses it back like any other
+// upstream feed.
+func republish(t *testing.T, message *gtfsrt.FeedMessage) *gtfs.Realtime {
+	t.Helper()
+	data, err := proto.Marshal(message)
+	require.NoError(t, err)
+	realtime, err := gtfs.ParseRealtime(data, &gtfs.ParseRealtimeOptions{})
+	require.NoError(t, err)
+	return realtime
+}
+
+func TestRepublishedFeedsRoundTrip(t *testing.T) {
+	manager := newTestManager()
+	tripUpdates := parseRealtimeTestdata(t, "raba-trip-updates.pb")
+	vehiclePositions := parseRealtimeTestdata(t, "raba-vehicle-positions.pb")
+	manager.feedTrips["trips"] = tripUpdates.Trips
+	manager.feedVehicles["vehicles"] = vehiclePositions.Vehicles
+	manager.realTimeMutex.Lock()
+	manager.rebuildMergedRealtimeLocked()
+	manager.realTimeMutex.Unlock()
+
+	now := time.Unix(1700000000, 0)
+	trips := manager.TripUpdatesFeed(now)
+	assert.Equal(t, "2.0", trips.GetHeader().GetGtfsRealtimeVersion())
+	assert.Equal(t, gtfsrt.FeedHeader_FULL_DATASET, trips.GetHeader().GetIncrementality())
+	assert.Equal(t, uint64(now.Unix()), trips.GetHeader().GetTimestamp())
+
+	parsedTrips := republish(t, trips)
+	var wantTrips []gtfs.Trip
+	for _, trip := range tripUpdates.Trips {
+		if trip.IsEntityInMessage {
+			wantTrips = append(wantTrips, trip)
+		}
+	}
+	require.NotEmpty(t, wantTrips)
+	require.Len(t, parsedTrips.Trips, len(wantTrips))
+	for i, trip := range parsedTrips.Trips {
+		assert.Equal(t, wantTrips[i].ID, trip.ID)
+		assert.Equal(t, wantTrips[i].StopTimeUpdates, trip.StopTimeUpdates)
+		assert.Equal(t, wantTrips[i].Delay, trip.Delay)
+	}
+
+	parsedVehicles := republish(t, manager.VehiclePositionsFeed(now))
+	require.Len(t, parsedVehicles.Vehicles, len(vehiclePositions.Vehicles))
+	byID := make(map[string]gtfs.Vehicle, len(parsedVehicles.Vehicles))
+	for _, vehicle := range parsedVehicles.Vehicles {
+		byID[vehicle.GetID().ID] = vehicle
+	}
+	for _, want := range vehiclePositions.Vehicles {
+		got, ok := byID[want.GetID().ID]
+		require.True(t, ok, "vehicle %s", want.GetID().ID)
+		assert.Equal(t, want.Position, got.Position)
+		assert.Equal(t, want.Timestamp, got.Timestamp)
+		assert.Equal(t, want.GetTrip().ID, got.GetTrip().ID)
+	}
+}
+
+func TestRepublishedFeedsHaveUniqueEntityIDs(t *testing.T) {
+	manager := newTestManager()
+	vehicle := gtfs.Vehicle{ID: &gtfs.VehicleID{ID: "101"}}
+	manager.feedVehicles["a"] = []gtfs.Vehicle{vehicle}
+	manager.feedVehicles["b"] = []gtfs.Vehicle{vehicle, {}}
+	manager.realTimeMutex.Lock()
+	manager.rebuildMergedRealtimeLocked()
+	manager.realTimeMutex.Unlock()
+
+	message := manager.VehiclePositionsFeed(time.Now())
+	require.Len(t, message.Entity, 3)
+	ids := make(map[string]bool)
+	for _, entity := range message.Entity {
+		ids[entity.GetId()] = true
+	}
+	assert.Len(t, ids, 3, "vehicle IDs reused across feeds or missing get their own entity IDs")
+	assert.True(t, ids["101"])
+}
+
+func TestFormatGtfsTime(t *testing.T) {
+	assert.Equal(t, "05:07:09", formatGtfsTime(5*time.Hour+7*time.Minute+9*time.Second))
+	assert.Equal(t, "25:30:00", formatGtfsTime(25*time.Hour+30*time.Minute))
+}
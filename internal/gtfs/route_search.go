@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/utils"
 )
 
 // buildRouteSearchQuery normalizes user input into an FTS5-safe prefix search query.
@@ -30,27 +31,105 @@ func buildRouteSearchQuery(input string) string {
 	return strings.Join(safeTerms, " AND ")
 }
 
-// SearchRoutes performs a full text search against routes using SQLite FTS5.
+// searchProximityRadiusInMeters bounds the stops considered when ranking
+// route search results by distance and no radius is given.
+const searchProximityRadiusInMeters = 5000.0
+
+// SearchRoutes performs a full text search against routes using SQLite FTS5,
+// ordered by text relevance.
 func (manager *Manager) SearchRoutes(ctx context.Context, input string, maxCount int) ([]gtfsdb.Route, error) {
+	return manager.SearchRoutesNear(ctx, input, maxCount, nil)
+}
+
+// SearchRoutesNear is SearchRoutes ranked by text relevance and by the
+// distance from near to the closest stop each route serves. Routes without a
+// stop within near.Radius (searchProximityRadiusInMeters by default) rank as
+// if they served one at the edge of it. A nil near ranks by relevance alone.
+func (manager *Manager) SearchRoutesNear(ctx context.Context, input string, maxCount int, near *LocationParams) ([]gtfsdb.Route, error) {
 	limit := maxCount
 	if limit <= 0 {
 		limit = 20
 	}
+	candidates := limit
+	if near != nil {
+		candidates = utils.SearchCandidateLimit(limit)
+	}
 
 	query := buildRouteSearchQuery(input)
 	if query == "" {
 		return []gtfsdb.Route{}, nil
 	}
 
-	logger := slog.Default().With(slog.String("component", "route_search"))
+	logger := manager.config.logger().With(slog.String("component", "route_search"))
 	logger.Debug("route search", slog.String("input", input), slog.String("query", query), slog.Int("limit", limit))
 
 	routes, err := manager.GtfsDB.Queries.SearchRoutesByFullText(ctx, gtfsdb.SearchRoutesByFullTextParams{
 		Query: query,
-		Limit: int64(limit),
+		Limit: int64(candidates),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("route search failed for query %q: %w", query, err)
 	}
+	if near == nil || len(routes) == 0 {
+		return routes, nil
+	}
+
+	distances, err := manager.routeDistancesFrom(ctx, near)
+	if err != nil {
+		return nil, fmt.Errorf("route search failed to rank by distance: %w", err)
+	}
+	radius := near.Radius
+	if radius <= 0 {
+		radius = searchProximityRadiusInMeters
+	}
+	utils.SortByRelevanceAndDistance(routes, func(route gtfsdb.Route) float64 {
+		if d, ok := distances[utils.FormCombinedID(route.AgencyID, route.ID)]; ok {
+			return d
+		}
+		return radius
+	})
+	if len(routes) > limit {
+		routes = routes[:limit]
+	}
 	return routes, nil
 }
+
+// routeDistancesFrom maps the combined ID of every route serving a stop
+// within the proximity radius of loc to the distance of its closest such stop.
+func (manager *Manager) routeDistancesFrom(ctx context.Context, loc *LocationParams) (map[string]float64, error) {
+	area := *loc
+	if area.Radius <= 0 {
+		area.Radius = searchProximityRadiusInMeters
+	}
+	stops, err := manager.queryStopsInBounds(ctx, BoundsFromParams(&area, true))
+	if err != nil {
+		return nil, err
+	}
+	if len(stops) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	stopDistances := make(map[string]float64, len(stops))
+	stopIDs := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		stopDistances[stop.ID] = utils.Distance(loc.Lat, loc.Lon, stop.Lat, stop.Lon)
+		stopIDs = append(stopIDs, stop.ID)
+	}
+
+	rows, err := manager.GtfsDB.Queries.GetRouteIDsForStops(ctx, stopIDs)
+	if err != nil {
+		return nil, err
+	}
+	distances := make(map[string]float64)
+	for _, row := range rows {
+		routeID, ok := row.RouteID.(string)
+		if !ok {
+			continue
+		}
+		d := stopDistances[row.StopID]
+		if current, seen := distances[routeID]; !seen || d < current {
+			distances[routeID] = d
+		}
+	}
+	return distances, nil
+}
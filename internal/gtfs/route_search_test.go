@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/utils"
 )
 
 func TestBuildRouteSearchQuery(t *testing.T) {
@@ -140,3 +141,34 @@ func TestSearchRoutes_DefaultLimit(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotEmpty(t, routes, "Default limit should still return results")
 }
+
+func TestSearchRoutesNear_RanksByClosestStop(t *testing.T) {
+	ctx := context.Background()
+	manager, _ := getSharedTestComponents(t)
+	require.NotNil(t, manager)
+
+	all, err := manager.SearchRoutes(ctx, "1", 100)
+	require.NoError(t, err)
+	require.Greater(t, len(all), 1)
+
+	// Search from a stop of the least relevant match.
+	target := all[len(all)-1]
+	stops, err := manager.GtfsDB.Queries.GetStopsForRoute(ctx, target.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, stops)
+	near := &LocationParams{Lat: stops[0].Lat, Lon: stops[0].Lon}
+
+	ranked, err := manager.SearchRoutesNear(ctx, "1", 100, near)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, all, ranked, "ranking reorders but never drops matches")
+
+	distances, err := manager.routeDistancesFrom(ctx, near)
+	require.NoError(t, err)
+	assert.Zero(t, distances[utils.FormCombinedID(target.AgencyID, target.ID)])
+	assert.Zero(t, distances[utils.FormCombinedID(ranked[0].AgencyID, ranked[0].ID)],
+		"the top result serves the stop searched from")
+
+	limited, err := manager.SearchRoutesNear(ctx, "1", 1, near)
+	require.NoError(t, err)
+	assert.Equal(t, ranked[:1], limited)
+}
@@ -2,11 +2,13 @@ package gtfs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,7 +21,7 @@ func rawGtfsData(ctx context.Context, source string, config Config) ([]byte, err
 	var b []byte
 	var err error
 
-	logger := slog.Default().With(slog.String("component", "gtfs_loader"))
+	logger := config.logger().With(slog.String("component", "gtfs_loader"))
 
 	if config.isLocalFile() {
 		b, err = os.ReadFile(source)
@@ -50,7 +52,7 @@ func rawGtfsData(ctx context.Context, source string, config Config) ([]byte, err
 			return nil, fmt.Errorf("error downloading GTFS data: %w", err)
 		}
 		defer logging.SafeCloseWithLogging(resp.Body,
-			slog.Default().With(slog.String("component", "gtfs_downloader")),
+			config.logger().With(slog.String("component", "gtfs_downloader")),
 			"http_response_body")
 
 		if resp.StatusCode != http.StatusOK {
@@ -93,9 +95,9 @@ func openGtfsDB(config Config) (*gtfsdb.Client, error) {
 
 // importStaticIntoDB imports the already-parsed GTFS data into the provided client.
 // Returns (changed, err): changed is true when the DB was actually updated. When changed,
-// it also precomputes stop directions. Trip time bounds are now computed inside the
-// import transaction by ImportParsedGTFS itself.
-func importStaticIntoDB(ctx context.Context, client *gtfsdb.Client, data *gtfsdb.GtfsData) (bool, error) {
+// it also precomputes stop directions and then runs the post-import processors. Trip
+// time bounds are now computed inside the import transaction by ImportParsedGTFS itself.
+func importStaticIntoDB(ctx context.Context, client *gtfsdb.Client, data *gtfsdb.GtfsData, processors []PostImportProcessor, logger *slog.Logger) (bool, error) {
 	changed, err := client.StoreGtfsData(ctx, data)
 	if err != nil {
 		return false, err
@@ -105,13 +107,16 @@ func importStaticIntoDB(ctx context.Context, client *gtfsdb.Client, data *gtfsdb
 		return false, nil
 	}
 
-	logger := slog.Default().With(slog.String("component", "gtfs_db_builder"))
 	precomputer := NewDirectionPrecomputer(client.Queries, client.DB)
+	precomputer.logger = logger.With(slog.String("component", "direction_precomputer"))
+	logger = logger.With(slog.String("component", "gtfs_db_builder"))
 	if err := precomputer.PrecomputeAllDirections(ctx); err != nil {
 		// Log error but don't fail the entire import
 		logging.LogError(logger, "Failed to precompute stop directions - API will fallback to on-demand calculation", err)
 	}
 
+	runPostImportProcessors(ctx, client, processors, logger)
+
 	return true, nil
 }
 
@@ -120,6 +125,9 @@ func newGTFSDBConfig(dbPath string, config Config) gtfsdb.Config {
 	if config.Metrics != nil {
 		dbConfig.QueryMetricsRecorder = config.Metrics
 	}
+	dbConfig.SlowQueryThreshold = config.SlowQueryThreshold
+	dbConfig.EncryptionKey = config.DataEncryptionKey
+	dbConfig.TransferRadiusMeters = config.TransferRadiusMeters
 	return dbConfig
 }
 
@@ -130,7 +138,12 @@ func loadGTFSData(ctx context.Context, config Config) (*gtfsdb.GtfsData, error)
 		return nil, fmt.Errorf("error reading GTFS data: %w", err)
 	}
 
-	data, err := gtfsdb.ParseGtfsData(b, config.GtfsURL)
+	return parseGTFSData(b, config.GtfsURL)
+}
+
+// parseGTFSData parses, hashes, and validates raw GTFS zip bytes.
+func parseGTFSData(b []byte, source string) (*gtfsdb.GtfsData, error) {
+	data, err := gtfsdb.ParseGtfsData(b, source)
 	if err != nil {
 		return nil, err
 	}
@@ -158,15 +171,165 @@ func validateStaticAgencyTimezones(staticData *gtfs.Static) error {
 	return nil
 }
 
+const (
+	staticRefreshInterval         = 24 * time.Hour
+	degradedStaticRefreshInterval = time.Hour
+	// reloadPinDrainTimeout bounds how long a reload waits for requests
+	// pinned to the replaced dataset. It is above the server's write timeout,
+	// so only a request already cut off can outlast it.
+	reloadPinDrainTimeout = 15 * time.Second
+)
+
+// loadStaticData loads the configured static feed, combined with any
+// additional static feeds.
+func (manager *Manager) loadStaticData(ctx context.Context, logger *slog.Logger) (*gtfsdb.GtfsData, error) {
+	data, err := manager.loadPrimaryStaticData(ctx, logger)
+	if err != nil || len(manager.config.AdditionalStaticFeeds) == 0 {
+		return data, err
+	}
+
+	feeds := []*gtfsdb.GtfsData{data}
+	for _, feed := range manager.config.AdditionalStaticFeeds {
+		additional, err := loadAdditionalStaticFeed(ctx, manager.config, feed)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, additional)
+	}
+	start := time.Now()
+	combined, err := gtfsdb.CombineGtfsData(feeds)
+	if err != nil {
+		if manager.Metrics != nil {
+			manager.Metrics.GTFSMergeFailuresTotal.Inc()
+		}
+		return nil, fmt.Errorf("error combining static GTFS feeds: %w", err)
+	}
+	manager.recordMergeMetrics(combined.Merge, time.Since(start))
+	return combined, nil
+}
+
+// recordMergeMetrics exports how the latest merge of the static feeds went.
+func (manager *Manager) recordMergeMetrics(stats *gtfsdb.MergeStats, duration time.Duration) {
+	if manager.Metrics == nil || stats == nil {
+		return
+	}
+	m := manager.Metrics
+	m.GTFSMergeDuration.Set(duration.Seconds())
+	m.GTFSMergeDuplicates.WithLabelValues("agency").Set(float64(stats.DuplicateAgencies))
+	m.GTFSMergeDuplicates.WithLabelValues("shape").Set(float64(stats.DuplicateShapes))
+	for _, kind := range []string{"service", "shape", "block"} {
+		m.GTFSMergeRenamedIDs.WithLabelValues(kind).Set(float64(stats.Renamed[kind]))
+	}
+	// Feeds are labeled by position rather than URL, which may carry
+	// credentials. Stale positions go away when feeds are removed.
+	m.GTFSMergeFeedEntities.Reset()
+	for i, feed := range stats.Feeds {
+		position := strconv.Itoa(i)
+		m.GTFSMergeFeedEntities.WithLabelValues(position, "agencies").Set(float64(feed.Agencies))
+		m.GTFSMergeFeedEntities.WithLabelValues(position, "routes").Set(float64(feed.Routes))
+		m.GTFSMergeFeedEntities.WithLabelValues(position, "stops").Set(float64(feed.Stops))
+		m.GTFSMergeFeedEntities.WithLabelValues(position, "trips").Set(float64(feed.Trips))
+	}
+	for _, phase := range gtfsdb.MergePhases {
+		m.GTFSMergePhaseDuration.WithLabelValues(phase).Set(stats.Phases[phase].Seconds())
+	}
+	m.GTFSMergePeakHeapBytes.Set(float64(stats.PeakHeapBytes))
+	for entity, perSecond := range stats.Throughput() {
+		m.GTFSMergeThroughput.WithLabelValues(entity).Set(perSecond)
+	}
+}
+
+// loadAdditionalStaticFeed loads one of config.AdditionalStaticFeeds. These
+// feeds are not mirrored, so an unreachable one fails the load.
+func loadAdditionalStaticFeed(ctx context.Context, config Config, feed StaticFeedConfig) (*gtfsdb.GtfsData, error) {
+	feedConfig := config
+	feedConfig.GtfsURL = feed.URL
+	feedConfig.StaticAuthHeaderKey = feed.AuthHeaderKey
+	feedConfig.StaticAuthHeaderValue = feed.AuthHeaderValue
+	feedConfig.EnableGTFSTidy = feed.EnableGTFSTidy
+
+	b, err := rawGtfsData(ctx, feed.URL, feedConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GTFS data from %s: %w", feed.URL, err)
+	}
+	return parseGTFSData(b, feed.URL)
+}
+
+// loadPrimaryStaticData loads the feed at config.GtfsURL. When the upstream
+// download fails before the manager is ready and a mirror is configured, it
+// boots from the last mirrored zip instead and flags the static source as
+// degraded.
+func (manager *Manager) loadPrimaryStaticData(ctx context.Context, logger *slog.Logger) (*gtfsdb.GtfsData, error) {
+	config := manager.config
+	b, err := rawGtfsData(ctx, config.GtfsURL, config)
+	if err == nil {
+		data, parseErr := parseGTFSData(b, config.GtfsURL)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		manager.saveStaticMirror(b, logger)
+		manager.setDegraded(staticMirrorSource, false)
+		return data, nil
+	}
+
+	canUseMirror := manager.mirror != nil && !config.isLocalFile() && !manager.IsReady() && ctx.Err() == nil
+	if !canUseMirror {
+		return nil, fmt.Errorf("error reading GTFS data: %w", err)
+	}
+
+	mirrored, mirrorErr := manager.mirror.loadStatic()
+	if mirrorErr != nil {
+		logging.LogError(logger, "No usable static GTFS mirror", mirrorErr)
+		return nil, fmt.Errorf("error reading GTFS data: %w", err)
+	}
+
+	logger.Warn("static GTFS upstream unreachable; booting from mirror in degraded mode",
+		slog.String("source", config.GtfsURL),
+		slog.String("mirror_dir", config.MirrorDir),
+		slog.Any("error", err))
+	manager.setDegraded(staticMirrorSource, true)
+	return parseGTFSData(mirrored, config.GtfsURL)
+}
+
+func (manager *Manager) saveStaticMirror(b []byte, logger *slog.Logger) {
+	if manager.mirror == nil || manager.config.isLocalFile() {
+		return
+	}
+	if err := manager.mirror.saveStatic(b); err != nil {
+		logging.LogError(logger, "Failed to mirror static GTFS data", err)
+	}
+}
+
+// staticRefreshDelay retries sooner while running from a mirrored static feed
+// so the server leaves degraded mode shortly after the upstream recovers.
+func (manager *Manager) staticRefreshDelay() time.Duration {
+	return manager.StaticRefreshDelay(staticRefreshInterval)
+}
+
+// StaticRefreshDelay returns the wait before the next periodic static reload
+// when reloads normally happen every interval. It is shorter while running
+// from a mirrored static feed.
+func (manager *Manager) StaticRefreshDelay(interval time.Duration) time.Duration {
+	if _, degraded := manager.degradedSources.Load(staticMirrorSource); degraded {
+		return min(interval, degradedStaticRefreshInterval)
+	}
+	return interval
+}
+
+// RefreshesStatic reports whether the static feed is downloaded and so
+// worth reloading periodically. A local file is only loaded at startup.
+func (manager *Manager) RefreshesStatic() bool {
+	return !manager.config.isLocalFile()
+}
+
 // UpdateGTFSPeriodically updates the GTFS data on a regular schedule
 func (manager *Manager) updateStaticGTFS() { // nolint
 	defer manager.wg.Done()
 
 	// Create a logger for this goroutine
-	logger := slog.Default().With(slog.String("component", "gtfs_static_updater"))
+	logger := manager.config.logger().With(slog.String("component", "gtfs_static_updater"))
 
-	// Update every 24 hours
-	ticker := time.NewTicker(24 * time.Hour)
+	ticker := time.NewTicker(manager.staticRefreshDelay())
 	defer ticker.Stop()
 
 	for { // nolint
@@ -182,6 +345,7 @@ func (manager *Manager) updateStaticGTFS() { // nolint
 						slog.String("source", manager.config.GtfsURL))
 				}
 			}()
+			ticker.Reset(manager.staticRefreshDelay())
 		case <-manager.shutdownChan:
 			logging.LogOperation(logger, "shutting_down_static_gtfs_updates")
 			return
@@ -196,9 +360,9 @@ func (manager *Manager) updateStaticGTFS() { // nolint
 func (manager *Manager) ReloadStatic(ctx context.Context) (bool, error) {
 	manager.staticUpdateMutex.Lock()
 	defer manager.staticUpdateMutex.Unlock()
-	logger := slog.Default().With(slog.String("component", "gtfs_updater"))
+	logger := manager.config.logger().With(slog.String("component", "gtfs_updater"))
 
-	newData, err := loadGTFSData(ctx, manager.config)
+	newData, err := manager.loadStaticData(ctx, logger)
 	if err != nil {
 		logging.LogError(logger, "Error loading GTFS data", err,
 			slog.String("source", manager.config.GtfsURL))
@@ -209,10 +373,23 @@ func (manager *Manager) ReloadStatic(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
-	changed, err := importStaticIntoDB(ctx, manager.GtfsDB, newData)
-	if err != nil {
-		logging.LogError(logger, "Error importing GTFS data", err)
-		return false, err
+	// A refused dataset is not imported, but the rest of the reload still runs
+	// so the current dataset keeps being served normally.
+	refusedErr := manager.checkReloadGuard(ctx, newData, logger)
+	if refusedErr != nil && !errors.Is(refusedErr, ErrStaticReloadRefused) {
+		logging.LogError(logger, "Error checking GTFS reload guard", refusedErr)
+		return false, refusedErr
+	}
+
+	var changed bool
+	if refusedErr == nil {
+		manager.retainDataset(ctx, newData.Hash, logger)
+		changed, err = importStaticIntoDB(ctx, manager.GtfsDB, newData, manager.postImportProcessors, manager.config.logger())
+		if err != nil {
+			logging.LogError(logger, "Error importing GTFS data", err)
+			return false, err
+		}
+		manager.clearRefusedReload()
 	}
 
 	if !changed {
@@ -220,6 +397,14 @@ func (manager *Manager) ReloadStatic(ctx context.Context) (bool, error) {
 			slog.String("source", newData.Source))
 	}
 
+	// Requests pinned to the replaced dataset would fill the caches reset
+	// below with it again. They are waited for before taking staticMutex,
+	// which they may need to finish.
+	if changed && !manager.GtfsDB.DrainPins(reloadPinDrainTimeout) {
+		logger.Warn("requests pinned to the replaced GTFS dataset outlasted the reload",
+			slog.Duration("timeout", reloadPinDrainTimeout))
+	}
+
 	newRegionBounds := computeRegionBounds(ctx, manager.GtfsDB)
 
 	manager.staticMutex.Lock()
@@ -232,6 +417,12 @@ func (manager *Manager) ReloadStatic(ctx context.Context) (bool, error) {
 	if changed && manager.DirectionCalculator != nil {
 		manager.DirectionCalculator.ClearCache()
 	}
+	if changed {
+		manager.tripAlertScopes.Clear()
+		manager.blockSequenceCache.clear()
+		manager.tripStarts.clearOrigins()
+		manager.arrivalHistory.clearSchedules()
+	}
 
 	if eTag := manager.GetSystemETag(ctx); eTag != "" {
 		logging.LogOperation(logger, "system_etag_updated_successfully", slog.String("etag", eTag))
@@ -244,14 +435,20 @@ func (manager *Manager) ReloadStatic(ctx context.Context) (bool, error) {
 
 	manager.PrintStatistics()
 	manager.logFeedExpiry(ctx, logger)
+	if manager.Metrics != nil {
+		if imported := manager.GetStaticLastUpdated(ctx); !imported.IsZero() {
+			manager.Metrics.StaticLastImportTime.Set(float64(imported.Unix()))
+		}
+	}
 
-	return changed, nil
+	return changed, refusedErr
 }
 
 // logFeedExpiry reads the feed_expires_at value persisted by StoreGtfsData
-// and updates the metrics gauge / emits warning logs about how soon the feed
-// will expire. The DB write itself happens atomically inside the import
-// transaction; this function is read-only.
+// and updates the metrics gauge / emits logs about how soon the feed will
+// expire. Feeds inside the warning window log warnings, and feeds expiring
+// within a day or already expired log errors. The DB write itself happens
+// atomically inside the import transaction; this function is read-only.
 func (manager *Manager) logFeedExpiry(ctx context.Context, logger *slog.Logger) {
 	if manager.Metrics != nil && manager.Metrics.FeedExpiresAt != nil {
 		manager.Metrics.FeedExpiresAt.Set(-1)
@@ -267,16 +464,19 @@ func (manager *Manager) logFeedExpiry(ctx context.Context, logger *slog.Logger)
 		manager.Metrics.FeedExpiresAt.Set(float64(expiresAt.Unix()))
 	}
 
-	daysUntil := int(time.Until(expiresAt).Hours() / 24)
+	remaining := time.Until(expiresAt)
+	daysUntil := int(remaining.Hours() / 24)
+	warningDays := manager.config.feedExpiryWarningDays()
 	switch {
-	case daysUntil < 0:
-		logger.Warn("GTFS feed has expired", slog.Time("expires_at", expiresAt), slog.Int("days_overdue", -daysUntil))
+	case remaining < 0:
+		logger.Error("GTFS feed has expired", slog.Time("expires_at", expiresAt), slog.Int("days_overdue", -daysUntil))
 	case daysUntil <= 1:
-		logger.Warn("GTFS feed expires in 1 day or less", slog.Time("expires_at", expiresAt))
-	case daysUntil <= 3:
+		logger.Error("GTFS feed expires in 1 day or less", slog.Time("expires_at", expiresAt))
+	case daysUntil <= 3 && daysUntil <= warningDays:
 		logger.Warn("GTFS feed expires in 3 days or less", slog.Time("expires_at", expiresAt))
-	case daysUntil <= 7:
-		logger.Warn("GTFS feed expires in 7 days or less", slog.Time("expires_at", expiresAt))
+	case daysUntil <= warningDays:
+		logger.Warn("GTFS feed expires soon", slog.Time("expires_at", expiresAt),
+			slog.Int("days_until_expiry", daysUntil), slog.Int("warning_days", warningDays))
 	default:
 		logger.Info("GTFS feed valid", slog.Time("expires_at", expiresAt), slog.Int("days_until_expiry", daysUntil))
 	}
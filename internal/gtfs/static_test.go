@@ -1,9 +1,15 @@
 package gtfs
 
 import (
+	"context"
+	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/appconf"
 	"maglev.onebusaway.org/internal/metrics"
 )
@@ -27,3 +33,98 @@ func TestNewGTFSDBConfig_QueryMetricsRecorder(t *testing.T) {
 		assert.Same(t, m, dbConfig.QueryMetricsRecorder)
 	})
 }
+
+func TestRecordMergeMetrics(t *testing.T) {
+	m := metrics.New()
+	manager := &Manager{Metrics: m}
+
+	manager.recordMergeMetrics(&gtfsdb.MergeStats{
+		DuplicateAgencies: 1,
+		DuplicateShapes:   3,
+		Renamed:           map[string]int{"service": 2, "block": 5},
+		Feeds: []gtfsdb.FeedCounts{
+			{Source: "a.zip", Agencies: 1, Routes: 10, Stops: 100, Trips: 1000},
+			{Source: "b.zip", Agencies: 2, Routes: 3, Stops: 30, Trips: 300},
+		},
+		Phases:        map[string]time.Duration{"claim_ids": 250 * time.Millisecond, "append": 750 * time.Millisecond},
+		PeakHeapBytes: 64 << 20,
+	}, 1500*time.Millisecond)
+
+	assert.Equal(t, 1.5, testutil.ToFloat64(m.GTFSMergeDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.GTFSMergeDuplicates.WithLabelValues("agency")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.GTFSMergeDuplicates.WithLabelValues("shape")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.GTFSMergeRenamedIDs.WithLabelValues("service")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.GTFSMergeRenamedIDs.WithLabelValues("shape")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.GTFSMergeRenamedIDs.WithLabelValues("block")))
+	assert.Equal(t, 100.0, testutil.ToFloat64(m.GTFSMergeFeedEntities.WithLabelValues("0", "stops")))
+	assert.Equal(t, 300.0, testutil.ToFloat64(m.GTFSMergeFeedEntities.WithLabelValues("1", "trips")))
+	assert.Equal(t, 0.75, testutil.ToFloat64(m.GTFSMergePhaseDuration.WithLabelValues("append")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.GTFSMergePhaseDuration.WithLabelValues("extensions")))
+	assert.Equal(t, float64(64<<20), testutil.ToFloat64(m.GTFSMergePeakHeapBytes))
+	assert.Equal(t, 1300.0, testutil.ToFloat64(m.GTFSMergeThroughput.WithLabelValues("trips")))
+
+	// Dropping a feed removes its series.
+	manager.recordMergeMetrics(&gtfsdb.MergeStats{Feeds: []gtfsdb.FeedCounts{{Routes: 10}}}, time.Second)
+	assert.Equal(t, 4, testutil.CollectAndCount(m.GTFSMergeFeedEntities))
+
+	// A single feed is not merged, and leaves the metrics alone.
+	manager.recordMergeMetrics(nil, 0)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.GTFSMergeDuration))
+}
+
+func TestLogFeedExpiry_EscalatesWithinWarningWindow(t *testing.T) {
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	manager := newManager(Config{FeedExpiryWarningDays: 14}, client)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name    string
+		expires time.Duration
+		level   string
+	}{
+		{"outside the window", 30 * 24 * time.Hour, `"level":"INFO"`},
+		{"inside the configured window", 10 * 24 * time.Hour, `"level":"WARN"`},
+		{"within a day", 12 * time.Hour, `"level":"ERROR"`},
+		{"expired", -time.Hour, `"level":"ERROR"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs lockedBuffer
+			manager.SetFeedExpiresAtForTest(ctx, time.Now().Add(tc.expires))
+			manager.logFeedExpiry(ctx, slog.New(slog.NewJSONHandler(&logs, nil)))
+			assert.Contains(t, logs.String(), tc.level)
+		})
+	}
+}
+
+func TestFeedExpiringSoon(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager := &Manager{}
+
+	assert.True(t, manager.FeedExpiringSoon(now.Add(7*24*time.Hour), now), "the default window is 7 days")
+	assert.False(t, manager.FeedExpiringSoon(now.Add(8*24*time.Hour), now))
+	assert.False(t, manager.FeedExpiringSoon(now.Add(-time.Hour), now), "an expired feed is no longer expiring")
+	assert.False(t, manager.FeedExpiringSoon(time.Time{}, now))
+
+	manager.config.FeedExpiryWarningDays = 14
+	assert.True(t, manager.FeedExpiringSoon(now.Add(8*24*time.Hour), now))
+}
+
+func TestRealtimeFeedStatuses(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager := newManager(Config{RTFeeds: []RTFeedConfig{
+		{ID: "fresh", TripUpdatesURL: "http://example.com/tu", Enabled: true},
+		{ID: "stale", TripUpdatesURL: "http://example.com/tu", RefreshInterval: 10, Enabled: true},
+		{ID: "pending", VehiclePositionsURL: "http://example.com/vp", Enabled: true},
+		{ID: "disabled", TripUpdatesURL: "http://example.com/tu"},
+	}}, nil)
+	manager.SetFeedUpdateTimeForTest("fresh", now.Add(-time.Minute))
+	manager.SetFeedUpdateTimeForTest("stale", now.Add(-time.Minute))
+
+	assert.Equal(t, []RealtimeFeedStatus{
+		{ID: "fresh", LastUpdated: now.Add(-time.Minute)},
+		{ID: "stale", LastUpdated: now.Add(-time.Minute), Stale: true},
+		{ID: "pending"},
+	}, manager.RealtimeFeedStatuses(now))
+}
@@ -0,0 +1,95 @@
+package gtfs
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// stopClusterCellsPerTile is the number of grid cells along each side of a
+// 256px web map tile, giving clusters roughly 64px apart on screen.
+const stopClusterCellsPerTile = 4
+
+// MaxStopClusterZoom is the highest zoom level at which stops are clustered.
+const MaxStopClusterZoom = 22
+
+// StopCluster summarizes the stops in one grid cell.
+type StopCluster struct {
+	Lat       float64 // Centroid of the stops in the cell
+	Lon       float64
+	StopCount int
+	StopID    string // Raw stop ID, only set when the cell holds a single stop
+	Bounds    utils.CoordinateBounds
+}
+
+// GetStopClusters groups the active stops within bounds into grid cells sized
+// for the given web map zoom level. The grid is fixed in Web Mercator space,
+// so a stop stays in the same cluster as the map is panned.
+func (manager *Manager) GetStopClusters(ctx context.Context, bounds utils.CoordinateBounds, zoom int) ([]StopCluster, error) {
+	stops, err := manager.queryStopsInBounds(ctx, bounds)
+	if err != nil {
+		return nil, err
+	}
+	return clusterStops(stops, zoom), nil
+}
+
+type stopClusterCell struct{ x, y int64 }
+
+func clusterStops(stops []gtfsdb.Stop, zoom int) []StopCluster {
+	cellSize := 360 / math.Exp2(float64(zoom)) / stopClusterCellsPerTile
+
+	cells := make(map[stopClusterCell]*StopCluster)
+	for _, stop := range stops {
+		cell := stopClusterCell{
+			x: int64(math.Floor((stop.Lon + 180) / cellSize)),
+			y: int64(math.Floor(mercatorY(stop.Lat) / cellSize)),
+		}
+		cluster, ok := cells[cell]
+		if !ok {
+			cluster = &StopCluster{
+				StopID: stop.ID,
+				Bounds: utils.CoordinateBounds{MinLat: stop.Lat, MaxLat: stop.Lat, MinLon: stop.Lon, MaxLon: stop.Lon},
+			}
+			cells[cell] = cluster
+		}
+		// Accumulate sums; they are turned into the centroid below.
+		cluster.Lat += stop.Lat
+		cluster.Lon += stop.Lon
+		cluster.StopCount++
+		cluster.Bounds.MinLat = min(cluster.Bounds.MinLat, stop.Lat)
+		cluster.Bounds.MaxLat = max(cluster.Bounds.MaxLat, stop.Lat)
+		cluster.Bounds.MinLon = min(cluster.Bounds.MinLon, stop.Lon)
+		cluster.Bounds.MaxLon = max(cluster.Bounds.MaxLon, stop.Lon)
+	}
+
+	clusters := make([]StopCluster, 0, len(cells))
+	for _, cluster := range cells {
+		cluster.Lat /= float64(cluster.StopCount)
+		cluster.Lon /= float64(cluster.StopCount)
+		if cluster.StopCount > 1 {
+			cluster.StopID = ""
+		}
+		clusters = append(clusters, *cluster)
+	}
+	// Largest clusters first, with a stable order for equal counts.
+	slices.SortFunc(clusters, func(a, b StopCluster) int {
+		return cmp.Or(
+			cmp.Compare(b.StopCount, a.StopCount),
+			cmp.Compare(a.Lat, b.Lat),
+			cmp.Compare(a.Lon, b.Lon),
+		)
+	})
+	return clusters
+}
+
+// mercatorY projects a latitude onto the Web Mercator y axis, scaled to
+// degrees so that it shares units with longitude.
+func mercatorY(lat float64) float64 {
+	// Web Mercator is undefined at the poles; clamp like web maps do.
+	lat = max(-85.05112878, min(85.05112878, lat))
+	return math.Log(math.Tan(math.Pi/4+lat*math.Pi/360)) * 180 / math.Pi
+}
@@ -0,0 +1,44 @@
+package gtfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+func TestClusterStops(t *testing.T) {
+	stops := []gtfsdb.Stop{
+		{ID: "a", Lat: 47.6000, Lon: -122.3300},
+		{ID: "b", Lat: 47.6010, Lon: -122.3310},
+		{ID: "c", Lat: 47.6020, Lon: -122.3290},
+		{ID: "far", Lat: 47.9000, Lon: -122.0000},
+	}
+
+	clusters := clusterStops(stops, 10)
+	require.Len(t, clusters, 2)
+
+	downtown := clusters[0]
+	assert.Equal(t, 3, downtown.StopCount, "largest cluster first")
+	assert.Empty(t, downtown.StopID)
+	assert.InDelta(t, 47.601, downtown.Lat, 1e-9)
+	assert.InDelta(t, -122.33, downtown.Lon, 1e-9)
+	assert.Equal(t, 47.6, downtown.Bounds.MinLat)
+	assert.Equal(t, -122.331, downtown.Bounds.MinLon)
+	assert.Equal(t, 47.602, downtown.Bounds.MaxLat)
+	assert.Equal(t, -122.329, downtown.Bounds.MaxLon)
+
+	assert.Equal(t, 1, clusters[1].StopCount)
+	assert.Equal(t, "far", clusters[1].StopID)
+}
+
+func TestClusterStops_HigherZoomSplitsClusters(t *testing.T) {
+	stops := []gtfsdb.Stop{
+		{ID: "a", Lat: 47.6000, Lon: -122.3300},
+		{ID: "b", Lat: 47.6100, Lon: -122.3100},
+	}
+	assert.Len(t, clusterStops(stops, 8), 1)
+	assert.Len(t, clusterStops(stops, 16), 2)
+	assert.Empty(t, clusterStops(nil, 8))
+}
@@ -0,0 +1,262 @@
+package gtfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+)
+
+// tripStartRetention bounds how long observed trip starts are kept. It covers
+// a service day running past midnight plus the following day's lookups.
+const tripStartRetention = 48 * time.Hour
+
+// tripStartTracker records when trips actually started, as observed in the
+// realtime feeds. A trip starts when the trip update reports a departure from
+// its first stop that has already happened, or when a vehicle that was seen
+// at the first stop is next seen past it.
+type tripStartTracker struct {
+	mu sync.Mutex
+	// First stop of each trip, resolved from static data (tripID -> origin).
+	// Cleared when a static reload changes the data.
+	origins map[string]tripOrigin
+	// Trip instances whose vehicle has been seen at the first stop.
+	pending map[tripInstance]time.Time
+	starts  map[tripInstance]time.Time
+}
+
+// tripInstance is a trip on one service date (YYYYMMDD).
+type tripInstance struct {
+	tripID      string
+	serviceDate string
+}
+
+type tripOrigin struct {
+	stopID       string
+	stopSequence uint32
+	// Scheduled departure from the first stop, since the service day's midnight.
+	departure time.Duration
+	location  *time.Location
+	// False when the trip has no stop times or agency timezone.
+	ok bool
+}
+
+func (t *tripStartTracker) clearOrigins() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.origins = nil
+}
+
+// TripStartTime returns when the trip was observed to start on serviceDate.
+func (manager *Manager) TripStartTime(tripID string, serviceDate time.Time) (time.Time, bool) {
+	t := &manager.tripStarts
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.starts[tripInstance{tripID: tripID, serviceDate: serviceDate.Format("20060102")}]
+	return start, ok
+}
+
+// On-time window for trip starts: no more than one minute early or five
+// minutes late, the definition most agencies report against.
+const (
+	onTimeStartMaxEarly = time.Minute
+	onTimeStartMaxLate  = 5 * time.Minute
+)
+
+// TripStartPerformance counts observed trip starts by punctuality.
+type TripStartPerformance struct {
+	Early  int `json:"early"`
+	OnTime int `json:"onTime"`
+	Late   int `json:"late"`
+}
+
+// TripStartPerformance compares the trip starts observed over the retention
+// window with their scheduled departures from the first stop. Starts of trips
+// whose schedule is not cached, such as right after a static reload, are not
+// counted.
+func (manager *Manager) TripStartPerformance() TripStartPerformance {
+	t := &manager.tripStarts
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var performance TripStartPerformance
+	for instance, start := range t.starts {
+		origin, ok := t.origins[instance.tripID]
+		if !ok || !origin.ok {
+			continue
+		}
+		serviceDate, err := time.ParseInLocation("20060102", instance.serviceDate, origin.location)
+		if err != nil {
+			continue
+		}
+		switch deviation := start.Sub(serviceDate.Add(origin.departure)); {
+		case deviation < -onTimeStartMaxEarly:
+			performance.Early++
+		case deviation > onTimeStartMaxLate:
+			performance.Late++
+		default:
+			performance.OnTime++
+		}
+	}
+	return performance
+}
+
+// observeTripStarts records the trips that have started since the previous
+// realtime update. now is used for vehicles without a timestamp.
+func (manager *Manager) observeTripStarts(ctx context.Context, now time.Time) {
+	trips := manager.GetRealTimeTrips()
+	vehicles := manager.GetRealTimeVehicles()
+
+	t := &manager.tripStarts
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.starts == nil {
+		t.starts = make(map[tripInstance]time.Time)
+		t.pending = make(map[tripInstance]time.Time)
+	}
+
+	for _, trip := range trips {
+		origin := manager.tripOriginLocked(ctx, trip.ID.ID)
+		if !origin.ok {
+			continue
+		}
+		instance := origin.instance(trip.ID, now)
+		if _, started := t.starts[instance]; started {
+			continue
+		}
+		for _, update := range trip.StopTimeUpdates {
+			if !origin.matches(update.StopSequence, update.StopID) {
+				continue
+			}
+			// Only a departure in the past is an observation rather than a prediction.
+			if departure := update.GetDeparture().Time; departure != nil && !departure.After(now) {
+				t.starts[instance] = *departure
+				delete(t.pending, instance)
+			}
+			break
+		}
+	}
+
+	for _, vehicle := range vehicles {
+		if vehicle.Trip == nil || (vehicle.CurrentStopSequence == nil && vehicle.StopID == nil) {
+			continue
+		}
+		origin := manager.tripOriginLocked(ctx, vehicle.Trip.ID.ID)
+		if !origin.ok {
+			continue
+		}
+		instance := origin.instance(vehicle.Trip.ID, now)
+		if _, started := t.starts[instance]; started {
+			continue
+		}
+		observed := now
+		if vehicle.Timestamp != nil {
+			observed = *vehicle.Timestamp
+		}
+		atOrigin := origin.matches(vehicle.CurrentStopSequence, vehicle.StopID) ||
+			(vehicle.CurrentStopSequence != nil && *vehicle.CurrentStopSequence < origin.stopSequence)
+		if atOrigin {
+			t.pending[instance] = observed
+			continue
+		}
+		// A vehicle first seen mid-trip gives no start time.
+		if _, ok := t.pending[instance]; ok {
+			t.starts[instance] = observed
+			delete(t.pending, instance)
+		}
+	}
+
+	for instance, start := range t.starts {
+		if now.Sub(start) > tripStartRetention {
+			delete(t.starts, instance)
+		}
+	}
+	for instance, seen := range t.pending {
+		if now.Sub(seen) > tripStartRetention {
+			delete(t.pending, instance)
+		}
+	}
+}
+
+// tripOriginLocked returns the first stop of a trip, caching it until the
+// next static reload. The caller must hold manager.tripStarts.mu.
+func (manager *Manager) tripOriginLocked(ctx context.Context, tripID string) tripOrigin {
+	t := &manager.tripStarts
+	if origin, ok := t.origins[tripID]; ok {
+		return origin
+	}
+
+	var origin tripOrigin
+	stopTimes, err := manager.GtfsDB.Queries.GetStopTimesForTrip(ctx, tripID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get stop times for trip start tracking",
+			slog.String("trip_id", tripID),
+			slog.Any("error", err))
+		return origin
+	}
+	agencyID := manager.tripAlertScope(ctx, tripID).agencyID
+	if len(stopTimes) > 0 && agencyID != "" {
+		agency, err := manager.GtfsDB.Queries.GetAgency(ctx, agencyID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "failed to get agency for trip start tracking",
+				slog.String("trip_id", tripID),
+				slog.Any("error", err))
+			return origin
+		}
+		if loc, locErr := time.LoadLocation(agency.Timezone); err == nil && locErr == nil {
+			first := stopTimes[0]
+			origin = tripOrigin{
+				stopID:       first.StopID,
+				stopSequence: uint32(first.StopSequence),
+				departure:    time.Duration(first.DepartureTime),
+				location:     loc,
+				ok:           true,
+			}
+		}
+	}
+
+	if t.origins == nil {
+		t.origins = make(map[string]tripOrigin)
+	}
+	t.origins[tripID] = origin
+	return origin
+}
+
+// matches reports whether a stop sequence or, when absent, a stop ID refers
+// to the trip's first stop.
+func (o tripOrigin) matches(stopSequence *uint32, stopID *string) bool {
+	if stopSequence != nil {
+		return *stopSequence == o.stopSequence
+	}
+	return stopID != nil && *stopID == o.stopID
+}
+
+// instance identifies the trip instance a realtime descriptor refers to. When
+// the descriptor has no start date, the service date is the one whose
+// scheduled start is closest to now.
+func (o tripOrigin) instance(id gtfs.TripID, now time.Time) tripInstance {
+	if id.HasStartDate {
+		return tripInstance{tripID: id.ID, serviceDate: id.StartDate.Format("20060102")}
+	}
+	local := now.In(o.location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, o.location)
+	serviceDate := today
+	best := absDuration(now.Sub(today.Add(o.departure)))
+	for _, candidate := range []time.Time{today.AddDate(0, 0, -1), today.AddDate(0, 0, 1)} {
+		if d := absDuration(now.Sub(candidate.Add(o.departure))); d < best {
+			serviceDate, best = candidate, d
+		}
+	}
+	return tripInstance{tripID: id.ID, serviceDate: serviceDate.Format("20060102")}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
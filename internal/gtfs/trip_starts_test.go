@@ -0,0 +1,178 @@
+package gtfs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+// newTripStartTestManager sets up trip t1, scheduled to leave stop s1
+// (sequence 1) at 08:00 and reach s2 (sequence 2) at 08:10, and trip t2
+// leaving s1 at 23:50.
+func newTripStartTestManager(t *testing.T) *Manager {
+	t.Helper()
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	stopTime := func(trip, stop string, seq int, at time.Duration) string {
+		return fmt.Sprintf("INSERT INTO stop_times (trip_id, arrival_time, departure_time, stop_id, stop_sequence) VALUES ('%s', %d, %d, '%s', %d)",
+			trip, int64(at), int64(at), stop, seq)
+	}
+	for _, stmt := range []string{
+		"INSERT INTO agencies (id, name, url, timezone) VALUES ('a1', 'Agency', '', 'UTC')",
+		"INSERT INTO routes (id, agency_id, type) VALUES ('r1', 'a1', 3)",
+		"INSERT INTO calendar (id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date) VALUES ('weekday', 1, 1, 1, 1, 1, 0, 0, '20240101', '20241231')",
+		"INSERT INTO stops (id, lat, lon) VALUES ('s1', 47.6, -122.3), ('s2', 47.7, -122.3)",
+		"INSERT INTO trips (id, route_id, service_id) VALUES ('t1', 'r1', 'weekday'), ('t2', 'r1', 'weekday')",
+		stopTime("t1", "s1", 1, 8*time.Hour),
+		stopTime("t1", "s2", 2, 8*time.Hour+10*time.Minute),
+		stopTime("t2", "s1", 1, 23*time.Hour+50*time.Minute),
+		stopTime("t2", "s2", 2, 24*time.Hour),
+	} {
+		_, err := client.DB.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+	return newManager(Config{}, client)
+}
+
+func tripStartVehicle(tripID string, sequence uint32, at time.Time) gtfs.Vehicle {
+	return gtfs.Vehicle{
+		ID:                  &gtfs.VehicleID{ID: "v1"},
+		Trip:                &gtfs.Trip{ID: gtfs.TripID{ID: tripID}},
+		CurrentStopSequence: &sequence,
+		Timestamp:           &at,
+	}
+}
+
+func TestObserveTripStarts_VehicleLeavesFirstStop(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	atOrigin := monday.Add(7*time.Hour + 58*time.Minute)
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t1", 1, atOrigin)}
+	manager.observeTripStarts(ctx, atOrigin)
+	_, ok := manager.TripStartTime("t1", monday)
+	assert.False(t, ok, "a vehicle waiting at the first stop has not started")
+
+	departed := monday.Add(8*time.Hour + 2*time.Minute)
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t1", 2, departed)}
+	manager.observeTripStarts(ctx, departed.Add(5*time.Second))
+	start, ok := manager.TripStartTime("t1", monday)
+	require.True(t, ok)
+	assert.Equal(t, departed, start)
+
+	// Later positions do not move the recorded start.
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t1", 2, departed.Add(time.Minute))}
+	manager.observeTripStarts(ctx, departed.Add(time.Minute))
+	start, _ = manager.TripStartTime("t1", monday)
+	assert.Equal(t, departed, start)
+
+	_, ok = manager.TripStartTime("t1", monday.AddDate(0, 0, 1))
+	assert.False(t, ok, "starts are recorded per service date")
+}
+
+func TestObserveTripStarts_VehicleFirstSeenMidTrip(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	now := monday.Add(8*time.Hour + 5*time.Minute)
+
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t1", 2, now)}
+	manager.observeTripStarts(context.Background(), now)
+
+	_, ok := manager.TripStartTime("t1", monday)
+	assert.False(t, ok, "the start was not observed")
+}
+
+func TestObserveTripStarts_TripUpdateDepartureFromFirstStop(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	departure := monday.Add(8*time.Hour + 3*time.Minute)
+	sequence := uint32(1)
+	manager.realTimeTrips = []gtfs.Trip{{
+		ID: gtfs.TripID{ID: "t1", HasStartDate: true, StartDate: monday},
+		StopTimeUpdates: []gtfs.StopTimeUpdate{{
+			StopSequence: &sequence,
+			Departure:    &gtfs.StopTimeEvent{Time: &departure},
+		}},
+	}}
+
+	manager.observeTripStarts(ctx, departure.Add(-time.Minute))
+	_, ok := manager.TripStartTime("t1", monday)
+	assert.False(t, ok, "a predicted departure is not a start")
+
+	manager.observeTripStarts(ctx, departure.Add(time.Minute))
+	start, ok := manager.TripStartTime("t1", monday)
+	require.True(t, ok)
+	assert.Equal(t, departure, start)
+}
+
+func TestObserveTripStarts_ServiceDateOfTripPastMidnight(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	atOrigin := monday.Add(23*time.Hour + 55*time.Minute)
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t2", 1, atOrigin)}
+	manager.observeTripStarts(ctx, atOrigin)
+	departed := monday.Add(24*time.Hour + 2*time.Minute)
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t2", 2, departed)}
+	manager.observeTripStarts(ctx, departed)
+
+	start, ok := manager.TripStartTime("t2", monday)
+	require.True(t, ok, "a late start after midnight belongs to the previous service date")
+	assert.Equal(t, departed, start)
+}
+
+func TestObserveTripStarts_ExpiresOldStarts(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+
+	atOrigin := monday.Add(7*time.Hour + 58*time.Minute)
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t1", 1, atOrigin)}
+	manager.observeTripStarts(ctx, atOrigin)
+	manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle("t1", 2, atOrigin.Add(5*time.Minute))}
+	manager.observeTripStarts(ctx, atOrigin.Add(5*time.Minute))
+	_, ok := manager.TripStartTime("t1", monday)
+	require.True(t, ok)
+
+	manager.realTimeVehicles = nil
+	manager.observeTripStarts(ctx, atOrigin.Add(tripStartRetention+time.Hour))
+	_, ok = manager.TripStartTime("t1", monday)
+	assert.False(t, ok)
+}
+
+func TestTripStartPerformance(t *testing.T) {
+	manager := newTripStartTestManager(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	assert.Equal(t, TripStartPerformance{}, manager.TripStartPerformance())
+
+	observe := func(tripID string, serviceDate time.Time, scheduled, deviation time.Duration) {
+		t.Helper()
+		start := serviceDate.Add(scheduled + deviation)
+		manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle(tripID, 1, start.Add(-time.Minute))}
+		manager.observeTripStarts(ctx, start.Add(-time.Minute))
+		manager.realTimeVehicles = []gtfs.Vehicle{tripStartVehicle(tripID, 2, start)}
+		manager.observeTripStarts(ctx, start)
+		_, ok := manager.TripStartTime(tripID, serviceDate)
+		require.True(t, ok)
+	}
+	observe("t1", monday, 8*time.Hour, 4*time.Minute)
+	observe("t2", monday, 23*time.Hour+50*time.Minute, 6*time.Minute)
+	observe("t1", tuesday, 8*time.Hour, -90*time.Second)
+
+	assert.Equal(t, TripStartPerformance{Early: 1, OnTime: 1, Late: 1}, manager.TripStartPerformance())
+}
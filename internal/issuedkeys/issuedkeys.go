@@ -0,0 +1,100 @@
+// Package issuedkeys holds what the two sources of runtime-issued API keys
+// share: keys issued by an admin (internal/apikeys) and keys issued by the
+// developer portal (internal/portal). Both generate keys the same way, store
+// only their hashes and give each active key its own rate limiter.
+package issuedkeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+)
+
+// NewKey returns a new random key of 40 hex characters.
+func NewKey() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Hash returns the hex SHA-256 of a key or another random secret. They are
+// random enough that an unsalted hash is safe to store.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeEmail accepts a bare address such as "dev@example.com" and returns
+// it lowercased, so one mailbox cannot pass for several by varying case. ok is
+// false for anything else, such as a display name or an empty string.
+func NormalizeEmail(email string) (address string, ok bool) {
+	email = strings.TrimSpace(email)
+	parsed, err := mail.ParseAddress(email)
+	if err != nil || parsed.Address != email {
+		return "", false
+	}
+	return strings.ToLower(parsed.Address), true
+}
+
+// Truncate cuts s to at most maxRunes runes.
+func Truncate(s string, maxRunes int) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	return string([]rune(s)[:maxRunes])
+}
+
+// Limiters holds the rate limiter of each active key seen so far, keyed by
+// key hash. The zero value is ready to use.
+type Limiters struct {
+	mu     sync.Mutex
+	byHash map[string]*rate.Limiter
+}
+
+// Get returns the limiter of the key with hash. The first time, it calls
+// lookup for the key's limit in requests per second and returns lookup's
+// error, if any, as is.
+func (l *Limiters) Get(hash string, lookup func() (int, error)) (*rate.Limiter, error) {
+	l.mu.Lock()
+	limiter, ok := l.byHash[hash]
+	l.mu.Unlock()
+	if ok {
+		return limiter, nil
+	}
+
+	limit, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	limiter = rate.NewLimiter(rate.Every(time.Second/time.Duration(limit)), limit)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Another request may have cached a limiter meanwhile; keep that one so
+	// its tokens are not reset.
+	if existing, ok := l.byHash[hash]; ok {
+		return existing, nil
+	}
+	if l.byHash == nil {
+		l.byHash = make(map[string]*rate.Limiter)
+	}
+	l.byHash[hash] = limiter
+	return limiter, nil
+}
+
+// Forget drops the limiter of the key with hash, so the next Get looks its
+// limit up again.
+func (l *Limiters) Forget(hash string) {
+	l.mu.Lock()
+	delete(l.byHash, hash)
+	l.mu.Unlock()
+}
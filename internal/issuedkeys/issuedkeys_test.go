@@ -0,0 +1,66 @@
+package issuedkeys
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyAndHash(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+	assert.Len(t, key, 40)
+
+	other, err := NewKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+
+	assert.Len(t, Hash(key), 64)
+	assert.Equal(t, Hash(key), Hash(key))
+	assert.NotEqual(t, Hash(key), Hash(other))
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	address, ok := NormalizeEmail("  Dev@Example.COM ")
+	assert.True(t, ok)
+	assert.Equal(t, "dev@example.com", address)
+
+	for _, email := range []string{"", "not an address", "Dev <dev@example.com>"} {
+		_, ok := NormalizeEmail(email)
+		assert.False(t, ok, email)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "héllo", Truncate("héllo", 5))
+	assert.Equal(t, "hé", Truncate("héllo", 2))
+}
+
+func TestLimiters(t *testing.T) {
+	var limiters Limiters
+	lookups := 0
+	lookup := func() (int, error) {
+		lookups++
+		return 3, nil
+	}
+
+	limiter, err := limiters.Get("a", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, 3, limiter.Burst())
+
+	again, err := limiters.Get("a", lookup)
+	require.NoError(t, err)
+	assert.Same(t, limiter, again, "a key keeps its limiter and its tokens")
+	assert.Equal(t, 1, lookups)
+
+	limiters.Forget("a")
+	_, err = limiters.Get("a", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups, "a forgotten key is looked up again")
+
+	errUnknown := errors.New("unknown")
+	_, err = limiters.Get("b", func() (int, error) { return 0, errUnknown })
+	assert.ErrorIs(t, err, errUnknown)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 // Metrics holds all Prometheus metrics for the application.
@@ -20,6 +21,10 @@ type Metrics struct {
 	// HTTP metrics
 	HTTPRequestsTotal   *prometheus.CounterVec
 	HTTPRequestDuration *prometheus.HistogramVec
+	// Requests refused with 429 by the shared or per-key rate limits
+	RateLimitRejectionsTotal *prometheus.CounterVec
+	// Requests with a valid API key, by key fingerprint and endpoint
+	APIKeyRequestsTotal *prometheus.CounterVec
 
 	// Database metrics
 	DBConnectionsOpen  prometheus.Gauge
@@ -27,14 +32,44 @@ type Metrics struct {
 	DBConnectionsIdle  prometheus.Gauge
 	DBWaitSecondsTotal prometheus.Counter
 	DBQueryTotal       *prometheus.CounterVec
+	DBQueryDuration    *prometheus.HistogramVec
 
 	// GTFS-RT metrics
 	FeedLastSuccessfulFetchTime *prometheus.GaugeVec
 	FeedConsecutiveErrors       *prometheus.GaugeVec
 	FeedFetchDuration           *prometheus.HistogramVec
+	FeedFetchTotal              *prometheus.CounterVec
 
 	// Static GTFS metrics
 	FeedExpiresAt prometheus.Gauge
+	// When the static dataset in service was imported; its age is
+	// time() - maglev_gtfs_static_last_import_time
+	StaticLastImportTime prometheus.Gauge
+	// 1 while the reload guard is refusing an incoming static dataset
+	StaticReloadRefused prometheus.Gauge
+
+	// Merging of several static feeds into one dataset; gauges describe the
+	// latest successful merge
+	GTFSMergeDuration      prometheus.Gauge
+	GTFSMergeDuplicates    *prometheus.GaugeVec
+	GTFSMergeRenamedIDs    *prometheus.GaugeVec
+	GTFSMergeFeedEntities  *prometheus.GaugeVec
+	GTFSMergeFailuresTotal prometheus.Counter
+	GTFSMergePhaseDuration *prometheus.GaugeVec
+	GTFSMergePeakHeapBytes prometheus.Gauge
+	GTFSMergeThroughput    *prometheus.GaugeVec
+
+	// SLO metrics over the rolling SLO window, per endpoint
+	SLOCompliance           *prometheus.GaugeVec
+	SLOErrorBudgetRemaining *prometheus.GaugeVec
+
+	// Canary metrics, per probe
+	CanarySuccess        *prometheus.GaugeVec
+	CanaryLatencySeconds *prometheus.GaugeVec
+
+	// Observed trip starts by punctuality, recomputed by the on-time
+	// performance job
+	TripStarts *prometheus.GaugeVec
 
 	// logger for error reporting
 	logger *slog.Logger
@@ -75,6 +110,22 @@ func NewWithLogger(logger *slog.Logger) *Metrics {
 		[]string{"method", "path"},
 	)
 
+	rateLimitRejectionsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "maglev_rate_limit_rejections_total",
+			Help: "Total number of requests rejected by rate limiting",
+		},
+		[]string{"path"},
+	)
+
+	apiKeyRequestsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "maglev_api_key_requests_total",
+			Help: "Total number of API requests with a valid key, by key fingerprint and endpoint",
+		},
+		[]string{"key", "endpoint"},
+	)
+
 	dbConnectionsOpen := prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "maglev_db_connections_open",
 		Help: "Number of open database connections",
@@ -103,6 +154,16 @@ func NewWithLogger(logger *slog.Logger) *Metrics {
 		[]string{"query_name", "op", "status"},
 	)
 
+	dbQueryDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "maglev_db_query_duration_seconds",
+			Help: "Database query latency distribution by query name and operation",
+			// SQLite queries are mostly sub-millisecond, so start well below DefBuckets.
+			Buckets: []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		},
+		[]string{"query_name", "op"},
+	)
+
 	feedLastSuccessfulFetchTime := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "maglev_feed_last_successful_fetch_time",
@@ -128,6 +189,14 @@ func NewWithLogger(logger *slog.Logger) *Metrics {
 		[]string{"feed"},
 	)
 
+	feedFetchTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "maglev_feed_fetch_total",
+			Help: "Total number of GTFS-RT fetches for a feed, by status (success or failure)",
+		},
+		[]string{"feed", "status"},
+	)
+
 	feedExpiresAt := prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "maglev_gtfs_feed_expires_at",
@@ -138,40 +207,195 @@ func NewWithLogger(logger *slog.Logger) *Metrics {
 	// Default to -1 so that it doesn't trigger alerts before actual feed expiry is loaded
 	feedExpiresAt.Set(-1)
 
+	staticLastImportTime := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_static_last_import_time",
+			Help: "Unix timestamp when the static GTFS dataset in service was imported",
+		},
+	)
+
+	staticReloadRefused := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_static_reload_refused",
+			Help: "1 while an incoming static GTFS dataset is refused for removing too many trips or stops",
+		},
+	)
+
+	gtfsMergeDuration := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_duration_seconds",
+			Help: "Time taken by the latest merge of the static GTFS feeds",
+		},
+	)
+
+	gtfsMergeDuplicates := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_duplicates",
+			Help: "Entities defined by more than one static feed and kept once in the latest merge, by kind",
+		},
+		[]string{"kind"},
+	)
+
+	gtfsMergeRenamedIDs := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_renamed_ids",
+			Help: "Feed-internal IDs renamed to avoid collisions in the latest merge, by kind (service, shape or block)",
+		},
+		[]string{"kind"},
+	)
+
+	gtfsMergeFeedEntities := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_feed_entities",
+			Help: "Entities in each static feed of the latest merge, by feed position (0 is the primary feed) and entity",
+		},
+		[]string{"feed", "entity"},
+	)
+
+	gtfsMergeFailuresTotal := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "maglev_gtfs_merge_failures_total",
+			Help: "Total number of static GTFS feed merges that failed, e.g. on conflicting IDs",
+		},
+	)
+
+	gtfsMergePhaseDuration := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_phase_duration_seconds",
+			Help: "Time spent in each phase of the latest merge of the static GTFS feeds, summed over feeds",
+		},
+		[]string{"phase"},
+	)
+
+	gtfsMergePeakHeapBytes := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_peak_heap_bytes",
+			Help: "Largest Go heap size sampled during the latest merge of the static GTFS feeds",
+		},
+	)
+
+	gtfsMergeThroughput := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_gtfs_merge_entities_per_second",
+			Help: "Entities merged per second in the latest merge of the static GTFS feeds, by entity",
+		},
+		[]string{"entity"},
+	)
+
+	sloCompliance := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_slo_compliance_ratio",
+			Help: "Fraction of API requests meeting the objective over the SLO window, by endpoint and SLI (availability or latency)",
+		},
+		[]string{"endpoint", "sli"},
+	)
+
+	sloErrorBudgetRemaining := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_slo_error_budget_remaining_ratio",
+			Help: "Fraction of the error budget left over the SLO window, by endpoint and SLI; negative once exhausted",
+		},
+		[]string{"endpoint", "sli"},
+	)
+
+	canarySuccess := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_canary_success",
+			Help: "1 if the latest synthetic canary probe succeeded, 0 if it failed",
+		},
+		[]string{"probe"},
+	)
+
+	canaryLatencySeconds := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_canary_latency_seconds",
+			Help: "Latency of the latest synthetic canary probe in seconds",
+		},
+		[]string{"probe"},
+	)
+
+	tripStarts := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "maglev_trip_starts",
+			Help: "Trips observed starting over the last 48 hours, by punctuality (early, on_time or late)",
+		},
+		[]string{"punctuality"},
+	)
+
 	// Register all metrics with the custom registry
 	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		httpRequestsTotal,
 		httpRequestDuration,
+		rateLimitRejectionsTotal,
+		apiKeyRequestsTotal,
 		dbConnectionsOpen,
 		dbConnectionsInUse,
 		dbConnectionsIdle,
 		dbWaitSecondsTotal,
 		dbQueryTotal,
+		dbQueryDuration,
 		feedLastSuccessfulFetchTime,
 		feedConsecutiveErrors,
 		feedFetchDuration,
+		feedFetchTotal,
 		feedExpiresAt,
+		staticLastImportTime,
+		staticReloadRefused,
+		gtfsMergeDuration,
+		gtfsMergeDuplicates,
+		gtfsMergeRenamedIDs,
+		gtfsMergeFeedEntities,
+		gtfsMergeFailuresTotal,
+		gtfsMergePhaseDuration,
+		gtfsMergePeakHeapBytes,
+		gtfsMergeThroughput,
+		sloCompliance,
+		sloErrorBudgetRemaining,
+		canarySuccess,
+		canaryLatencySeconds,
+		tripStarts,
 	)
 
 	return &Metrics{
 		Registry:                    registry,
 		HTTPRequestsTotal:           httpRequestsTotal,
 		HTTPRequestDuration:         httpRequestDuration,
+		RateLimitRejectionsTotal:    rateLimitRejectionsTotal,
+		APIKeyRequestsTotal:         apiKeyRequestsTotal,
 		DBConnectionsOpen:           dbConnectionsOpen,
 		DBConnectionsInUse:          dbConnectionsInUse,
 		DBConnectionsIdle:           dbConnectionsIdle,
 		DBWaitSecondsTotal:          dbWaitSecondsTotal,
 		DBQueryTotal:                dbQueryTotal,
+		DBQueryDuration:             dbQueryDuration,
 		FeedLastSuccessfulFetchTime: feedLastSuccessfulFetchTime,
 		FeedConsecutiveErrors:       feedConsecutiveErrors,
 		FeedFetchDuration:           feedFetchDuration,
+		FeedFetchTotal:              feedFetchTotal,
 		FeedExpiresAt:               feedExpiresAt,
+		StaticLastImportTime:        staticLastImportTime,
+		StaticReloadRefused:         staticReloadRefused,
+		GTFSMergeDuration:           gtfsMergeDuration,
+		GTFSMergeDuplicates:         gtfsMergeDuplicates,
+		GTFSMergeRenamedIDs:         gtfsMergeRenamedIDs,
+		GTFSMergeFeedEntities:       gtfsMergeFeedEntities,
+		GTFSMergeFailuresTotal:      gtfsMergeFailuresTotal,
+		GTFSMergePhaseDuration:      gtfsMergePhaseDuration,
+		GTFSMergePeakHeapBytes:      gtfsMergePeakHeapBytes,
+		GTFSMergeThroughput:         gtfsMergeThroughput,
+		SLOCompliance:               sloCompliance,
+		SLOErrorBudgetRemaining:     sloErrorBudgetRemaining,
+		CanarySuccess:               canarySuccess,
+		CanaryLatencySeconds:        canaryLatencySeconds,
+		TripStarts:                  tripStarts,
 		logger:                      logger,
 	}
 }
 
-// RecordDBQuery records per-query DB counters.
-func (m *Metrics) RecordDBQuery(queryName, op string, err error) {
+// RecordDBQuery records per-query DB counters and latency.
+func (m *Metrics) RecordDBQuery(queryName, op string, duration time.Duration, err error) {
 	if m == nil || m.DBQueryTotal == nil {
 		return
 	}
@@ -189,6 +413,9 @@ func (m *Metrics) RecordDBQuery(queryName, op string, err error) {
 	}
 
 	m.DBQueryTotal.WithLabelValues(queryName, op, status).Inc()
+	if m.DBQueryDuration != nil {
+		m.DBQueryDuration.WithLabelValues(queryName, op).Observe(duration.Seconds())
+	}
 }
 
 // StartDBStatsCollector starts a goroutine that periodically collects database
@@ -208,9 +208,9 @@ func TestHTTPMetrics_RecordRequest(t *testing.T) {
 func TestRecordDBQuery(t *testing.T) {
 	m := New()
 
-	m.RecordDBQuery("GetTrip", "query", nil)
-	m.RecordDBQuery("GetTrip", "query", assert.AnError)
-	m.RecordDBQuery("", "", nil)
+	m.RecordDBQuery("GetTrip", "query", 2*time.Millisecond, nil)
+	m.RecordDBQuery("GetTrip", "query", 30*time.Millisecond, assert.AnError)
+	m.RecordDBQuery("", "", time.Millisecond, nil)
 
 	okTotal := testutil.ToFloat64(m.DBQueryTotal.WithLabelValues("GetTrip", "query", "ok"))
 	errTotal := testutil.ToFloat64(m.DBQueryTotal.WithLabelValues("GetTrip", "query", "error"))
@@ -219,9 +219,12 @@ func TestRecordDBQuery(t *testing.T) {
 	assert.Equal(t, float64(1), okTotal)
 	assert.Equal(t, float64(1), errTotal)
 	assert.Equal(t, float64(1), unknownTotal)
+
+	// Latency is not split by status, so both GetTrip calls share one series.
+	assert.Equal(t, 2, testutil.CollectAndCount(m.DBQueryDuration))
 }
 
 func TestRecordDBQuery_NilReceiverNoPanic(t *testing.T) {
 	var m *Metrics
-	m.RecordDBQuery("GetTrip", "query", nil)
+	m.RecordDBQuery("GetTrip", "query", time.Millisecond, nil)
 }
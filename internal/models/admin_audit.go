@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+// AuditLogEntry describes one applied admin mutation.
+type AuditLogEntry struct {
+	ID int64 `json:"id"`
+	// Actor is a fingerprint of the protected API key that made the change:
+	// the first 16 hex digits of the key's SHA-256 hash.
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	Target string `json:"target,omitempty"`
+	// PreviousValue and NewValue are JSON snapshots of the changed object.
+	PreviousValue  json.RawMessage `json:"previousValue,omitempty"`
+	NewValue       json.RawMessage `json:"newValue,omitempty"`
+	IdempotencyKey string          `json:"idempotencyKey,omitempty"`
+	Time           int64           `json:"time"`
+}
+
+// NewAuditLogEntry converts a database AdminAuditLog row to an API response
+// model.
+func NewAuditLogEntry(row gtfsdb.AdminAuditLog) AuditLogEntry {
+	entry := AuditLogEntry{
+		ID:             row.ID,
+		Actor:          row.Actor,
+		Action:         row.Action,
+		Target:         row.Target,
+		IdempotencyKey: row.IdempotencyKey.String,
+		Time:           row.CreatedAt,
+	}
+	if row.PreviousValue.Valid {
+		entry.PreviousValue = json.RawMessage(row.PreviousValue.String)
+	}
+	if row.NewValue.Valid {
+		entry.NewValue = json.RawMessage(row.NewValue.String)
+	}
+	return entry
+}
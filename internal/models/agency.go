@@ -36,6 +36,9 @@ type AgencyReference struct {
 	PrivateService bool   `json:"privateService"`
 	Timezone       string `json:"timezone"`
 	URL            string `json:"url"`
+	// ServiceHours is only populated by the agency endpoint, spanning all of
+	// the agency's routes on the current service day.
+	ServiceHours *ServiceHours `json:"serviceHours,omitempty"`
 }
 
 // NewAgencyReference creates a new AgencyReference instance with the provided values
@@ -0,0 +1,21 @@
+package models
+
+// Amenity is a facility at or near a stop, such as a park-and-ride lot, a
+// bike rack or a ticket machine. Amenities are not part of GTFS; they come
+// from the supplementary dataset configured with amenities-path.
+type Amenity struct {
+	// Type is a short kebab-case kind, e.g. "parking", "bike-rack" or "ticket-machine".
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Capacity is the number of parking or bike spaces, when known.
+	Capacity *int     `json:"capacity,omitempty"`
+	Lat      *float64 `json:"lat,omitempty"`
+	Lon      *float64 `json:"lon,omitempty"`
+}
+
+// AmenitiesForStop is the entry of the amenities-for-stop endpoint.
+type AmenitiesForStop struct {
+	StopID    string    `json:"stopId"`
+	Amenities []Amenity `json:"amenities"`
+}
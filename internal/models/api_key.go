@@ -0,0 +1,55 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+// APIKey describes an API key issued by an admin.
+type APIKey struct {
+	ID int64 `json:"id"`
+	// Fingerprint identifies the key in the admin audit log and usage
+	// reports: the first 16 hex digits of the key's SHA-256 hash.
+	Fingerprint  string `json:"fingerprint"`
+	ContactEmail string `json:"contactEmail"`
+	Description  string `json:"description,omitempty"`
+	// Key is set only in the response to creation. The store keeps a hash,
+	// so the key cannot be shown again.
+	Key string `json:"key,omitempty"`
+	// RateLimit is the number of requests per second the key allows.
+	RateLimit int   `json:"rateLimit"`
+	CreatedAt int64 `json:"createdAt"`
+	RevokedAt int64 `json:"revokedAt,omitempty"`
+}
+
+// NewAPIKey converts a database ApiKey to an API response model.
+func NewAPIKey(key gtfsdb.ApiKey) APIKey {
+	return APIKey{
+		ID:           key.ID,
+		Fingerprint:  APIKeyFingerprintFromHash(key.KeyHash),
+		ContactEmail: key.ContactEmail,
+		Description:  key.Description,
+		RateLimit:    int(key.RateLimit),
+		CreatedAt:    key.CreatedAt,
+		RevokedAt:    key.RevokedAt.Int64,
+	}
+}
+
+// apiKeyFingerprintLength is the number of hex digits of a key's SHA-256 hash
+// that make up its fingerprint.
+const apiKeyFingerprintLength = 16
+
+// APIKeyFingerprint returns the fingerprint of key, which identifies it in
+// logs and reports without revealing it.
+func APIKeyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return APIKeyFingerprintFromHash(hex.EncodeToString(sum[:]))
+}
+
+// APIKeyFingerprintFromHash returns the fingerprint of the key whose hex
+// SHA-256 hash is keyHash.
+func APIKeyFingerprintFromHash(keyHash string) string {
+	return keyHash[:min(apiKeyFingerprintLength, len(keyHash))]
+}
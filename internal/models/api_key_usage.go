@@ -0,0 +1,50 @@
+package models
+
+import "maglev.onebusaway.org/gtfsdb"
+
+// APIKeyUsage is the number of requests one API key made to one endpoint on
+// one UTC day.
+type APIKeyUsage struct {
+	Day string `json:"day"` // YYYY-MM-DD
+	// KeyFingerprint identifies the key; see APIKeyFingerprint.
+	KeyFingerprint string `json:"keyFingerprint"`
+	Endpoint       string `json:"endpoint"`
+	Requests       int64  `json:"requests"`
+}
+
+// NewAPIKeyUsage converts a database ApiKeyUsage row to an API response model.
+func NewAPIKeyUsage(row gtfsdb.ApiKeyUsage) APIKeyUsage {
+	return APIKeyUsage{
+		Day:            row.Day,
+		KeyFingerprint: row.KeyFingerprint,
+		Endpoint:       row.Endpoint,
+		Requests:       row.Requests,
+	}
+}
+
+// KeyUsage is what the key making a usage.json request has used of its
+// limits, so developers can tell why they are being throttled.
+type KeyUsage struct {
+	KeyFingerprint string `json:"keyFingerprint"`
+	// RateLimit is the bucket every key that is not exempt draws from.
+	RateLimit RateLimitWindow `json:"rateLimit"`
+	// KeyRateLimit is the key's own bucket, for keys issued by an admin or
+	// the developer portal.
+	KeyRateLimit *RateLimitWindow `json:"keyRateLimit,omitempty"`
+	// Day is the current UTC day (YYYY-MM-DD). Requests are not capped per
+	// day; the counts show how the key's traffic is spread.
+	Day                string           `json:"day"`
+	RequestsToday      int64            `json:"requestsToday"`
+	RequestsByEndpoint map[string]int64 `json:"requestsByEndpoint"`
+}
+
+// RateLimitWindow is the state of one token bucket.
+type RateLimitWindow struct {
+	Exempt    bool `json:"exempt"`
+	Unlimited bool `json:"unlimited"`
+	// Limit is the bucket size: the number of requests allowed in a burst.
+	Limit           int     `json:"limit"`
+	RefillPerSecond float64 `json:"refillPerSecond"`
+	// Remaining is the number of whole requests currently available.
+	Remaining int `json:"remaining"`
+}
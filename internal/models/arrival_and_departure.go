@@ -5,11 +5,19 @@ import (
 )
 
 type ArrivalAndDeparture struct {
-	ActualTrack                string      `json:"actualTrack"`
-	ArrivalEnabled             bool        `json:"arrivalEnabled"`
-	BlockTripSequence          int         `json:"blockTripSequence"`
-	DepartureEnabled           bool        `json:"departureEnabled"`
-	DistanceFromStop           float64     `json:"distanceFromStop"`
+	ActualTrack       string `json:"actualTrack"`
+	ArrivalEnabled    bool   `json:"arrivalEnabled"`
+	BlockTripSequence int    `json:"blockTripSequence"`
+	// BookingRules tells riders how to request a flexible pickup or drop-off.
+	BookingRules     []BookingRule `json:"bookingRules,omitempty"`
+	DepartureEnabled bool          `json:"departureEnabled"`
+	DistanceFromStop float64       `json:"distanceFromStop"`
+	// FlagStop is set when the route has continuous stopping, so riders can
+	// also board or alight anywhere along its path rather than only at stops.
+	FlagStop bool `json:"flagStop,omitempty"`
+	// EndPickupDropOffWindow and StartPickupDropOffWindow bound when a
+	// GTFS-Flex trip serves the stop on request; zero for timed stops.
+	EndPickupDropOffWindow     ModelTime   `json:"endPickupDropOffWindow,omitzero"`
 	Frequency                  *Frequency  `json:"frequency"`
 	HistoricalOccupancy        string      `json:"historicalOccupancy"`
 	LastUpdateTime             ModelTime   `json:"lastUpdateTime,omitzero"`
@@ -31,6 +39,7 @@ type ArrivalAndDeparture struct {
 	ScheduledTrack             string      `json:"scheduledTrack"`
 	ServiceDate                ModelTime   `json:"serviceDate"`
 	SituationIDs               []string    `json:"situationIds"`
+	StartPickupDropOffWindow   ModelTime   `json:"startPickupDropOffWindow,omitzero"`
 	Status                     string      `json:"status"`
 	StopID                     string      `json:"stopId"`
 	StopSequence               int         `json:"stopSequence"`
@@ -95,3 +104,20 @@ func NewArrivalAndDeparture(
 		VehicleID:                  vehicleID,
 	}
 }
+
+// ArrivalsSpeechEntry is the format=speech form of arrivals-and-departures,
+// a short spoken-style summary for IVR systems and voice assistants.
+type ArrivalsSpeechEntry struct {
+	StopID   string `json:"stopId"`
+	StopName string `json:"stopName"`
+	Text     string `json:"text"`
+}
+
+// ArrivalsAndDeparturesForLocationEntry combines the arrivals of every stop
+// near a location, ordered by departure time.
+type ArrivalsAndDeparturesForLocationEntry struct {
+	ArrivalsAndDepartures []ArrivalAndDeparture `json:"arrivalsAndDepartures"`
+	StopIDs               []string              `json:"stopIds"`
+	SituationIDs          []string              `json:"situationIds"`
+	LimitExceeded         bool                  `json:"limitExceeded"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// BlockAssignment is an active block and the vehicles serving it, as returned
+// by block-assignments-for-agency for dispatchers' service delivery reports.
+type BlockAssignment struct {
+	BlockID     string `json:"blockId"`
+	ServiceDate int64  `json:"serviceDate"`
+	// ExpectedTripID is the trip in progress, or the next trip of the block
+	// while it lays over between trips.
+	ExpectedTripID string `json:"expectedTripId"`
+	RouteID        string `json:"routeId"`
+	// Status is "inProgress" or "layover".
+	Status     string   `json:"status"`
+	Assigned   bool     `json:"assigned"`
+	VehicleIDs []string `json:"vehicleIds"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+// BookingRule describes how to book a GTFS-Flex (demand-responsive) trip.
+// BookingType follows GTFS: 0 real-time booking, 1 up to same-day booking
+// with advance notice, 2 up to prior-day(s) booking. Prior notice durations
+// are in minutes; days and times are as given in the feed.
+type BookingRule struct {
+	ID                     string `json:"id"`
+	BookingType            int    `json:"bookingType"`
+	PriorNoticeDurationMin *int64 `json:"priorNoticeDurationMin,omitempty"`
+	PriorNoticeDurationMax *int64 `json:"priorNoticeDurationMax,omitempty"`
+	PriorNoticeLastDay     *int64 `json:"priorNoticeLastDay,omitempty"`
+	PriorNoticeLastTime    string `json:"priorNoticeLastTime,omitempty"`
+	PriorNoticeStartDay    *int64 `json:"priorNoticeStartDay,omitempty"`
+	PriorNoticeStartTime   string `json:"priorNoticeStartTime,omitempty"`
+	Message                string `json:"message,omitempty"`
+	PickupMessage          string `json:"pickupMessage,omitempty"`
+	DropOffMessage         string `json:"dropOffMessage,omitempty"`
+	PhoneNumber            string `json:"phoneNumber,omitempty"`
+	InfoURL                string `json:"infoUrl,omitempty"`
+	BookingURL             string `json:"bookingUrl,omitempty"`
+}
+
+// NewBookingRulesFromDB converts booking_rules rows into API models. It
+// returns nil for no rows so the field is omitted for fixed-route service.
+func NewBookingRulesFromDB(rows []gtfsdb.BookingRule) []BookingRule {
+	if len(rows) == 0 {
+		return nil
+	}
+	optional := func(v sql.NullInt64) *int64 {
+		if !v.Valid {
+			return nil
+		}
+		return &v.Int64
+	}
+	rules := make([]BookingRule, len(rows))
+	for i, row := range rows {
+		rules[i] = BookingRule{
+			ID:                     row.ID,
+			BookingType:            int(row.BookingType),
+			PriorNoticeDurationMin: optional(row.PriorNoticeDurationMin),
+			PriorNoticeDurationMax: optional(row.PriorNoticeDurationMax),
+			PriorNoticeLastDay:     optional(row.PriorNoticeLastDay),
+			PriorNoticeLastTime:    nulls.StringOrEmpty(row.PriorNoticeLastTime),
+			PriorNoticeStartDay:    optional(row.PriorNoticeStartDay),
+			PriorNoticeStartTime:   nulls.StringOrEmpty(row.PriorNoticeStartTime),
+			Message:                nulls.StringOrEmpty(row.Message),
+			PickupMessage:          nulls.StringOrEmpty(row.PickupMessage),
+			DropOffMessage:         nulls.StringOrEmpty(row.DropOffMessage),
+			PhoneNumber:            nulls.StringOrEmpty(row.PhoneNumber),
+			InfoURL:                nulls.StringOrEmpty(row.InfoUrl),
+			BookingURL:             nulls.StringOrEmpty(row.BookingUrl),
+		}
+	}
+	return rules
+}
+
+// FlexStopTime is a GTFS-Flex stop time of a trip: a window during which the
+// trip picks up or drops off riders on request, at a stop or anywhere within
+// a location group. Windows are durations since the service date's midnight,
+// like StopTime arrival and departure times.
+type FlexStopTime struct {
+	StopSequence             int            `json:"stopSequence"`
+	StopID                   string         `json:"stopId,omitempty"`
+	LocationGroupID          string         `json:"locationGroupId,omitempty"`
+	StartPickupDropOffWindow *ModelDuration `json:"startPickupDropOffWindow,omitempty"`
+	EndPickupDropOffWindow   *ModelDuration `json:"endPickupDropOffWindow,omitempty"`
+	PickupBookingRuleID      string         `json:"pickupBookingRuleId,omitempty"`
+	DropOffBookingRuleID     string         `json:"dropOffBookingRuleId,omitempty"`
+}
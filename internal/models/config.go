@@ -24,9 +24,40 @@ type GitProperties struct {
 }
 
 type ConfigModel struct {
-	GitProperties   GitProperties `json:"gitProperties"`
-	Id              string        `json:"id"`
-	Name            string        `json:"name"`
-	ServiceDateFrom string        `json:"serviceDateFrom"`
-	ServiceDateTo   string        `json:"serviceDateTo"`
+	GitProperties   GitProperties      `json:"gitProperties"`
+	Id              string             `json:"id"`
+	Name            string             `json:"name"`
+	ServiceDateFrom string             `json:"serviceDateFrom"`
+	ServiceDateTo   string             `json:"serviceDateTo"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+}
+
+// ServerCapabilities describes what this server supports, so clients can
+// feature-detect rather than assume. It is reported by config.json and logged
+// at startup.
+type ServerCapabilities struct {
+	Version    string                `json:"version"`    // Server release, "dev" for local builds
+	APIVersion int                   `json:"apiVersion"` // Response format version, see APIVersion
+	Features   map[string]bool       `json:"features"`   // Optional feature name to whether it is enabled
+	Dataset    DatasetInfo           `json:"dataset"`
+	Parameters ParameterCapabilities `json:"parameters"`
+}
+
+// DatasetInfo identifies the static GTFS dataset being served.
+type DatasetInfo struct {
+	Version    string `json:"version"`    // Hash of the imported feed; empty before the first import
+	ImportedAt int64  `json:"importedAt"` // Milliseconds since the epoch; 0 before the first import
+	ExpiresAt  int64  `json:"expiresAt"`  // End of the feed's service in milliseconds; 0 when unknown
+	// Versions of earlier datasets static endpoints can answer for with
+	// datasetVersion, newest first.
+	RetainedVersions []string `json:"retainedVersions,omitempty"`
+}
+
+// ParameterCapabilities reports the limits applied to request parameters.
+type ParameterCapabilities struct {
+	MaxCount              int     `json:"maxCount"`              // Largest maxCount accepted
+	DefaultMaxCountStops  int     `json:"defaultMaxCountStops"`  // Stops returned when maxCount is not given
+	DefaultMaxCountRoutes int     `json:"defaultMaxCountRoutes"` // Routes returned when maxCount is not given
+	DefaultRadius         float64 `json:"defaultRadius"`         // Meters searched when neither radius nor spans are given
+	MaxRadius             float64 `json:"maxRadius"`             // Larger radii are clamped to this many meters
 }
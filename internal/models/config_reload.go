@@ -0,0 +1,9 @@
+package models
+
+// ConfigReload reports the outcome of reloading the configuration file.
+type ConfigReload struct {
+	// RestartRequired is true when the file also changed settings that only
+	// take effect on restart; API keys, rate limits and realtime feeds were
+	// applied regardless.
+	RestartRequired bool `json:"restartRequired"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "maglev.onebusaway.org/internal/appconf"
+
 // Common constants used across the application
 const (
 	// UnknownValue is the fallback value when data is unavailable or calculation fails
@@ -10,10 +12,12 @@ const (
 	NotAccessible = "NOT_ACCESSIBLE"
 )
 
+// Built-in location search limits; the search section of the config file
+// overrides them for the location endpoints.
 const (
-	DefaultSearchRadiusInMeters = 600
-	QuerySearchRadiusInMeters   = 10000
-	MaxSearchRadiusInMeters     = 20000
+	DefaultSearchRadiusInMeters = appconf.DefaultSearchRadius
+	QuerySearchRadiusInMeters   = appconf.DefaultQuerySearchRadius
+	MaxSearchRadiusInMeters     = appconf.DefaultMaxSearchRadius
 )
 
 // Cache durations (in seconds) for different API data types.
@@ -24,9 +28,9 @@ const (
 )
 
 const (
-	DefaultMaxCountForRoutes = 50
-	DefaultMaxCountForStops  = 100
-	MaxAllowedCount          = 250
+	DefaultMaxCountForRoutes = appconf.DefaultSearchMaxCountRoutes
+	DefaultMaxCountForStops  = appconf.DefaultSearchMaxCountStops
+	MaxAllowedCount          = appconf.DefaultSearchMaxAllowedCount
 )
 
 // RangeSearchBufferMeters provides a 50m tolerance for GPS inaccuracy and curve approximation.
@@ -0,0 +1,39 @@
+package models
+
+import "maglev.onebusaway.org/gtfsdb"
+
+// DeveloperKey describes an API key issued through the developer portal.
+type DeveloperKey struct {
+	ID     int64  `json:"id"`
+	Email  string `json:"email"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	Tier   string `json:"tier"`
+	// Key is set only in the response to verification. The portal stores a
+	// hash, so the key cannot be shown again.
+	Key string `json:"key,omitempty"`
+	// RateLimit is the number of requests per second the key's tier allows.
+	RateLimit int `json:"rateLimit"`
+	// QuotaRequest is the reason given for a pending elevated quota request.
+	QuotaRequest     string `json:"quotaRequest,omitempty"`
+	QuotaRequestedAt int64  `json:"quotaRequestedAt,omitempty"`
+	CreatedAt        int64  `json:"createdAt"`
+	VerifiedAt       int64  `json:"verifiedAt,omitempty"`
+}
+
+// NewDeveloperKey converts a database DeveloperApiKey to an API response
+// model. rateLimit is the limit of the key's tier.
+func NewDeveloperKey(key gtfsdb.DeveloperApiKey, rateLimit int) DeveloperKey {
+	return DeveloperKey{
+		ID:               key.ID,
+		Email:            key.Email,
+		Name:             key.Name,
+		Status:           key.Status,
+		Tier:             key.Tier,
+		RateLimit:        rateLimit,
+		QuotaRequest:     key.QuotaRequest.String,
+		QuotaRequestedAt: key.QuotaRequestedAt.Int64,
+		CreatedAt:        key.CreatedAt,
+		VerifiedAt:       key.VerifiedAt.Int64,
+	}
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"database/sql"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+// RouteFares lists the fares that can apply to a ride on a route, in both
+// GTFS fare models a feed may use. A feed usually has only one of them, so
+// the other list is empty.
+type RouteFares struct {
+	RouteID string `json:"routeId"`
+	// FareAttributes are Fares v1 fares with the rules that tie them to the
+	// route; a fare without rules applies to every route of its agency.
+	FareAttributes []FareAttribute `json:"fareAttributes"`
+	// FareLegRules are the Fares v2 leg rules matching the route's networks,
+	// highest rule priority first.
+	FareLegRules []FareLegRule `json:"fareLegRules"`
+}
+
+// LegFares lists the Fares v2 leg rules that price a ride on a route from one
+// stop to another: those matching the route's network and the stops' fare
+// areas with the highest rule priority.
+type LegFares struct {
+	RouteID      string        `json:"routeId"`
+	FromStopID   string        `json:"fromStopId"`
+	ToStopID     string        `json:"toStopId"`
+	FareLegRules []FareLegRule `json:"fareLegRules"`
+}
+
+// FareAttribute is a fare_attributes.txt fare. PaymentMethod follows GTFS: 0
+// paid on board, 1 paid before boarding. Transfers is omitted when unlimited;
+// TransferDuration is in seconds.
+type FareAttribute struct {
+	ID               string     `json:"id"`
+	Price            float64    `json:"price"`
+	CurrencyType     string     `json:"currencyType"`
+	PaymentMethod    int        `json:"paymentMethod"`
+	Transfers        *int64     `json:"transfers,omitempty"`
+	TransferDuration *int64     `json:"transferDuration,omitempty"`
+	Rules            []FareRule `json:"rules,omitempty"`
+}
+
+// FareRule restricts a fare to a route and/or to rides between or through
+// fare zones. Zone IDs are the stops' zone_id values.
+type FareRule struct {
+	RouteID       string `json:"routeId,omitempty"`
+	OriginID      string `json:"originId,omitempty"`
+	DestinationID string `json:"destinationId,omitempty"`
+	ContainsID    string `json:"containsId,omitempty"`
+}
+
+// FareLegRule prices a leg within a network, optionally between two fare
+// areas, with one or more fare products.
+type FareLegRule struct {
+	LegGroupID   string        `json:"legGroupId,omitempty"`
+	NetworkID    string        `json:"networkId,omitempty"`
+	FromAreaID   string        `json:"fromAreaId,omitempty"`
+	ToAreaID     string        `json:"toAreaId,omitempty"`
+	RulePriority int           `json:"rulePriority"`
+	FareProducts []FareProduct `json:"fareProducts"`
+}
+
+// FareProduct is a fare_products.txt product. A product priced differently
+// per fare media or rider category appears once for each; RiderCategory is
+// omitted for a price every rider pays.
+type FareProduct struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name,omitempty"`
+	Amount        float64        `json:"amount"`
+	Currency      string         `json:"currency"`
+	FareMedia     *FareMedia     `json:"fareMedia,omitempty"`
+	RiderCategory *RiderCategory `json:"riderCategory,omitempty"`
+}
+
+// FareMedia is how a fare product is held or paid for. Type follows GTFS: 0
+// none, 1 paper ticket, 2 transit card, 3 contactless bank card, 4 mobile
+// app.
+type FareMedia struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type int    `json:"type"`
+}
+
+// RiderCategory is a rider_categories.txt category, such as seniors or
+// youth. The default category is the one to show riders who have not picked
+// one.
+type RiderCategory struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	IsDefault      bool   `json:"isDefault"`
+	EligibilityURL string `json:"eligibilityUrl,omitempty"`
+}
+
+// NewFareAttributesFromDB converts fare_attributes rows into API models,
+// attaching the given fare_rules rows to their fares. routeID converts a
+// rule's route ID into the ID clients see.
+func NewFareAttributesFromDB(rows []gtfsdb.FareAttribute, ruleRows []gtfsdb.FareRule, routeID func(string) string) []FareAttribute {
+	optional := func(v sql.NullInt64) *int64 {
+		if !v.Valid {
+			return nil
+		}
+		return &v.Int64
+	}
+	rulesByFare := make(map[string][]FareRule)
+	for _, row := range ruleRows {
+		rule := FareRule{
+			OriginID:      nulls.StringOrEmpty(row.OriginID),
+			DestinationID: nulls.StringOrEmpty(row.DestinationID),
+			ContainsID:    nulls.StringOrEmpty(row.ContainsID),
+		}
+		if row.RouteID.Valid {
+			rule.RouteID = routeID(row.RouteID.String)
+		}
+		rulesByFare[row.FareID] = append(rulesByFare[row.FareID], rule)
+	}
+
+	fares := make([]FareAttribute, len(rows))
+	for i, row := range rows {
+		fares[i] = FareAttribute{
+			ID:               row.ID,
+			Price:            row.Price,
+			CurrencyType:     row.CurrencyType,
+			PaymentMethod:    int(row.PaymentMethod),
+			Transfers:        optional(row.Transfers),
+			TransferDuration: optional(row.TransferDuration),
+			Rules:            rulesByFare[row.ID],
+		}
+	}
+	return fares
+}
+
+// NewFareLegRulesFromDB converts fare_leg_rules rows into API models with
+// their fare products, media and rider categories. Rules whose products are
+// missing are dropped.
+func NewFareLegRulesFromDB(rows []gtfsdb.FareLegRule, productRows []gtfsdb.FareProduct, mediaRows []gtfsdb.FareMedium, categoryRows []gtfsdb.RiderCategory) []FareLegRule {
+	media := make(map[string]*FareMedia, len(mediaRows))
+	for _, row := range mediaRows {
+		media[row.ID] = &FareMedia{
+			ID:   row.ID,
+			Name: nulls.StringOrEmpty(row.Name),
+			Type: int(row.FareMediaType),
+		}
+	}
+	categories := make(map[string]*RiderCategory, len(categoryRows))
+	for _, row := range categoryRows {
+		categories[row.ID] = &RiderCategory{
+			ID:             row.ID,
+			Name:           row.Name,
+			IsDefault:      row.IsDefaultFareCategory == 1,
+			EligibilityURL: nulls.StringOrEmpty(row.EligibilityUrl),
+		}
+	}
+	products := make(map[string][]FareProduct)
+	for _, row := range productRows {
+		products[row.FareProductID] = append(products[row.FareProductID], FareProduct{
+			ID:            row.FareProductID,
+			Name:          nulls.StringOrEmpty(row.Name),
+			Amount:        row.Amount,
+			Currency:      row.Currency,
+			FareMedia:     media[row.FareMediaID],
+			RiderCategory: categories[row.RiderCategoryID],
+		})
+	}
+
+	rules := make([]FareLegRule, 0, len(rows))
+	for _, row := range rows {
+		ruleProducts, ok := products[row.FareProductID]
+		if !ok {
+			continue
+		}
+		rules = append(rules, FareLegRule{
+			LegGroupID:   nulls.StringOrEmpty(row.LegGroupID),
+			NetworkID:    nulls.StringOrEmpty(row.NetworkID),
+			FromAreaID:   nulls.StringOrEmpty(row.FromAreaID),
+			ToAreaID:     nulls.StringOrEmpty(row.ToAreaID),
+			RulePriority: int(row.RulePriority),
+			FareProducts: ruleProducts,
+		})
+	}
+	return rules
+}
@@ -0,0 +1,21 @@
+package models
+
+// RateLimitStatus describes how rate limiting applies to an API key, for
+// debugging throttling complaints. Non-exempt keys all draw from one shared
+// bucket, so Remaining reflects traffic from every client.
+type RateLimitStatus struct {
+	APIKey string `json:"apiKey"`
+	// IP is the client address that was checked against the exempt IP ranges, if any.
+	IP     string `json:"ip,omitempty"`
+	Exempt bool   `json:"exempt"`
+	// ExemptReason is "api-key" or "ip-range" when Exempt is true.
+	ExemptReason string `json:"exemptReason,omitempty"`
+	// Unlimited is true when the service has no global rate limit.
+	Unlimited bool `json:"unlimited"`
+	// Limit is the bucket size: the number of requests allowed in a burst.
+	Limit int `json:"limit"`
+	// RefillPerSecond is the rate at which the bucket refills.
+	RefillPerSecond float64 `json:"refillPerSecond"`
+	// Remaining is the number of whole requests currently available in the bucket.
+	Remaining int `json:"remaining"`
+}
@@ -2,6 +2,85 @@ package models
 
 type RouteType int
 
+// Basic GTFS route_type codes that extended route types are normalized to.
+const (
+	RouteTypeTram       RouteType = 0
+	RouteTypeSubway     RouteType = 1
+	RouteTypeRail       RouteType = 2
+	RouteTypeBus        RouteType = 3
+	RouteTypeFerry      RouteType = 4
+	RouteTypeCableTram  RouteType = 5
+	RouteTypeAerialLift RouteType = 6
+	RouteTypeFunicular  RouteType = 7
+	RouteTypeTrolleybus RouteType = 11
+	RouteTypeMonorail   RouteType = 12
+)
+
+// The range of GTFS extended route types, from 100 railway service to 1702
+// horse-drawn carriage.
+const (
+	MinExtendedRouteType = 100
+	MaxExtendedRouteType = 1702
+)
+
+// Normalize maps a GTFS extended route type (100-1702, as used by many European
+// feeds) to the closest basic route_type. Basic codes, and extended codes without
+// a basic equivalent (e.g. 1100 air service or 1500 taxi), are returned unchanged.
+func (t RouteType) Normalize() RouteType {
+	if t < MinExtendedRouteType || t > MaxExtendedRouteType {
+		return t
+	}
+	switch t {
+	case 405:
+		return RouteTypeMonorail
+	case 1701:
+		return RouteTypeCableTram
+	}
+
+	switch t / 100 * 100 {
+	case 100, 300:
+		return RouteTypeRail
+	case 200, 700:
+		return RouteTypeBus
+	case 400, 500, 600:
+		return RouteTypeSubway
+	case 800:
+		return RouteTypeTrolleybus
+	case 900:
+		return RouteTypeTram
+	case 1000, 1200:
+		return RouteTypeFerry
+	case 1300:
+		return RouteTypeAerialLift
+	case 1400:
+		return RouteTypeFunicular
+	default:
+		return t
+	}
+}
+
+// ExpandRouteTypeFilter returns every raw route_type code, basic or extended, whose
+// normalized type matches the normalized type of one of the requested codes. This
+// lets a filter such as "3" match extended bus codes like 700 and 704.
+func ExpandRouteTypeFilter(routeTypes []int) []int {
+	if len(routeTypes) == 0 {
+		return nil
+	}
+
+	wanted := make(map[RouteType]bool, len(routeTypes))
+	for _, routeType := range routeTypes {
+		wanted[RouteType(routeType).Normalize()] = true
+	}
+
+	var expanded []int
+	for code := RouteType(0); code <= MaxExtendedRouteType; code++ {
+		if wanted[code.Normalize()] {
+			expanded = append(expanded, int(code))
+		}
+	}
+	return expanded
+}
+
 type Route struct {
 	AgencyID    string `json:"agencyId"`
 	Color       string `json:"color"`
@@ -15,15 +94,35 @@ type Route struct {
 	ShortName         string    `json:"shortName"`
 	TextColor         string    `json:"textColor"`
 	Type              RouteType `json:"type"`
-	URL               string    `json:"url"`
+	// RawType is the feed's original extended route_type. It is only set when
+	// Type was normalized from an extended code.
+	RawType RouteType `json:"rawType,omitempty"`
+	URL     string    `json:"url"`
+	// BookingRules is only populated by the route endpoint, for routes with
+	// GTFS-Flex (demand-responsive) trips.
+	BookingRules []BookingRule `json:"bookingRules,omitempty"`
+	// ServiceHours is only populated by the route endpoint, for the current
+	// service day. It is omitted when the route does not run that day.
+	ServiceHours *ServiceHours `json:"serviceHours,omitempty"`
+	// SortOrder is the feed's route_sort_order, nil when unset. It orders
+	// route lists and is not serialized.
+	SortOrder *int `json:"-"`
 }
 
+// NewRoute builds a route model. routeType is the raw value from the feed; extended
+// codes are normalized into Type and preserved in RawType.
 func NewRoute(id, agencyID, shortName, longName, description string, routeType RouteType, url, color, textColor string) Route {
 	nullSafeShortName := shortName
 	if nullSafeShortName == "" {
 		nullSafeShortName = id
 	}
 
+	normalizedType := routeType.Normalize()
+	var rawType RouteType
+	if normalizedType != routeType {
+		rawType = routeType
+	}
+
 	return Route{
 		AgencyID:          agencyID,
 		Color:             color,
@@ -33,7 +132,8 @@ func NewRoute(id, agencyID, shortName, longName, description string, routeType R
 		NullSafeShortName: nullSafeShortName,
 		ShortName:         shortName,
 		TextColor:         textColor,
-		Type:              routeType,
+		Type:              normalizedType,
+		RawType:           rawType,
 		URL:               url,
 	}
 }
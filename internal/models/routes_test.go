@@ -178,3 +178,64 @@ func TestRouteNullSafeShortNameFallback(t *testing.T) {
 	route2 := NewRoute("25_200", "agency-1", "DX", "Downtown Express", "", 3, "", "", "")
 	assert.Equal(t, "DX", route2.NullSafeShortName)
 }
+
+func TestRouteTypeNormalize(t *testing.T) {
+	tests := []struct {
+		raw  RouteType
+		want RouteType
+	}{
+		{raw: 3, want: RouteTypeBus},
+		{raw: 12, want: RouteTypeMonorail},
+		{raw: 102, want: RouteTypeRail},
+		{raw: 200, want: RouteTypeBus},
+		{raw: 401, want: RouteTypeSubway},
+		{raw: 405, want: RouteTypeMonorail},
+		{raw: 700, want: RouteTypeBus},
+		{raw: 715, want: RouteTypeBus},
+		{raw: 800, want: RouteTypeTrolleybus},
+		{raw: 900, want: RouteTypeTram},
+		{raw: 1000, want: RouteTypeFerry},
+		{raw: 1100, want: 1100},
+		{raw: 1200, want: RouteTypeFerry},
+		{raw: 1300, want: RouteTypeAerialLift},
+		{raw: 1400, want: RouteTypeFunicular},
+		{raw: 1500, want: 1500},
+		{raw: 1700, want: 1700},
+		{raw: 1701, want: RouteTypeCableTram},
+		{raw: 1702, want: 1702},
+		{raw: 1799, want: 1799},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.raw.Normalize(), "route type %d", tt.raw)
+	}
+}
+
+func TestNewRoutePreservesRawExtendedType(t *testing.T) {
+	extended := NewRoute("1", "agency", "A", "", "", RouteType(704), "", "", "")
+	assert.Equal(t, RouteTypeBus, extended.Type)
+	assert.Equal(t, RouteType(704), extended.RawType)
+
+	basic := NewRoute("2", "agency", "B", "", "", RouteTypeBus, "", "", "")
+	assert.Equal(t, RouteTypeBus, basic.Type)
+	assert.Zero(t, basic.RawType)
+
+	data, err := json.Marshal(basic)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "rawType")
+}
+
+func TestExpandRouteTypeFilter(t *testing.T) {
+	assert.Nil(t, ExpandRouteTypeFilter(nil))
+
+	bus := ExpandRouteTypeFilter([]int{3})
+	assert.Contains(t, bus, 3)
+	assert.Contains(t, bus, 700)
+	assert.Contains(t, bus, 200)
+	assert.NotContains(t, bus, 0)
+	assert.NotContains(t, bus, 900)
+
+	extendedTram := ExpandRouteTypeFilter([]int{900})
+	assert.Contains(t, extendedTram, 0, "an extended code should also match its basic equivalent")
+	assert.Contains(t, extendedTram, 906)
+}
@@ -0,0 +1,10 @@
+package models
+
+// ScheduleDeviationSample is the schedule deviation of a trip observed in
+// the realtime feeds at one time.
+type ScheduleDeviationSample struct {
+	Time int64 `json:"time"` // Unix milliseconds
+	// ScheduleDeviation is in seconds, positive when the trip is late.
+	ScheduleDeviation int    `json:"scheduleDeviation"`
+	VehicleID         string `json:"vehicleId,omitempty"`
+}
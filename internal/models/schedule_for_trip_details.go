@@ -1,11 +1,14 @@
 package models
 
 type Schedule struct {
-	Frequency      *Frequency `json:"frequency"`
-	NextTripID     string     `json:"nextTripId"`
-	PreviousTripID string     `json:"previousTripId"`
-	StopTimes      []StopTime `json:"stopTimes"`
-	TimeZone       string     `json:"timeZone"`
+	// FlexStopTimes are the trip's GTFS-Flex stop times, served on request
+	// within a window rather than at a scheduled time.
+	FlexStopTimes  []FlexStopTime `json:"flexStopTimes,omitempty"`
+	Frequency      *Frequency     `json:"frequency"`
+	NextTripID     string         `json:"nextTripId"`
+	PreviousTripID string         `json:"previousTripId"`
+	StopTimes      []StopTime     `json:"stopTimes"`
+	TimeZone       string         `json:"timeZone"`
 }
 
 func NewSchedule(frequency *Frequency, nextTripID, previousTripID string, stopTimes []StopTime, timeZone string) *Schedule {
@@ -0,0 +1,12 @@
+package models
+
+// ServiceException is a date on which calendar_dates adds or removes service
+// of an agency, typically a holiday running a different schedule. Date is
+// yyyy-MM-dd in the agency's time zone and ServiceDate its midnight in Unix
+// milliseconds.
+type ServiceException struct {
+	Date              string   `json:"date"`
+	ServiceDate       int64    `json:"serviceDate"`
+	AddedServiceIDs   []string `json:"addedServiceIds"`
+	RemovedServiceIDs []string `json:"removedServiceIds"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+// ServiceHours is the span of a day's service, from the start of the first
+// trip to the end of the last, so clients can show "Runs 5:30 AM – 1:15 AM"
+// without the full schedule. Times are Unix milliseconds; LastDeparture falls
+// on the next calendar day for service running past midnight.
+type ServiceHours struct {
+	ServiceDate    int64 `json:"serviceDate"`
+	FirstDeparture int64 `json:"firstDeparture"`
+	LastDeparture  int64 `json:"lastDeparture"`
+}
+
+// NewServiceHours combines the spans of the services active on the day
+// starting at serviceMidnight. It returns nil when nothing runs that day.
+func NewServiceHours(serviceMidnight time.Time, spans []gtfsdb.RouteServiceSpan) *ServiceHours {
+	if len(spans) == 0 {
+		return nil
+	}
+	first, last := spans[0].FirstDepartureTime, spans[0].LastDepartureTime
+	for _, span := range spans[1:] {
+		first = min(first, span.FirstDepartureTime)
+		last = max(last, span.LastDepartureTime)
+	}
+	return &ServiceHours{
+		ServiceDate:    serviceMidnight.UnixMilli(),
+		FirstDeparture: serviceMidnight.Add(time.Duration(first)).UnixMilli(),
+		LastDeparture:  serviceMidnight.Add(time.Duration(last)).UnixMilli(),
+	}
+}
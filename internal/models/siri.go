@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Siri is the root of a SIRI 2.0 response from the /siri endpoints. The same
+// value marshals as a <Siri> XML document and, wrapped in a "Siri" member, as
+// the JSON used by SIRI-Lite feeds. Optional elements are omitted when empty.
+type Siri struct {
+	XMLName         xml.Name            `xml:"http://www.siri.org.uk/siri Siri" json:"-"`
+	Version         string              `xml:"version,attr" json:"-"`
+	ServiceDelivery SiriServiceDelivery `xml:"ServiceDelivery" json:"ServiceDelivery"`
+}
+
+// NewSiri returns a response delivering at responseTime. The caller adds the
+// stop or vehicle monitoring delivery.
+func NewSiri(responseTime time.Time) Siri {
+	return Siri{
+		Version:         "2.0",
+		ServiceDelivery: SiriServiceDelivery{ResponseTimestamp: responseTime},
+	}
+}
+
+type SiriServiceDelivery struct {
+	ResponseTimestamp         time.Time                       `xml:"ResponseTimestamp" json:"ResponseTimestamp"`
+	StopMonitoringDelivery    []SiriStopMonitoringDelivery    `xml:"StopMonitoringDelivery,omitempty" json:"StopMonitoringDelivery,omitempty"`
+	VehicleMonitoringDelivery []SiriVehicleMonitoringDelivery `xml:"VehicleMonitoringDelivery,omitempty" json:"VehicleMonitoringDelivery,omitempty"`
+}
+
+// SiriStopMonitoringDelivery lists the upcoming visits to a stop (SIRI-SM).
+type SiriStopMonitoringDelivery struct {
+	ResponseTimestamp  time.Time                `xml:"ResponseTimestamp" json:"ResponseTimestamp"`
+	MonitoredStopVisit []SiriMonitoredStopVisit `xml:"MonitoredStopVisit" json:"MonitoredStopVisit"`
+}
+
+type SiriMonitoredStopVisit struct {
+	RecordedAtTime          time.Time          `xml:"RecordedAtTime" json:"RecordedAtTime"`
+	MonitoringRef           string             `xml:"MonitoringRef" json:"MonitoringRef"`
+	MonitoredVehicleJourney SiriVehicleJourney `xml:"MonitoredVehicleJourney" json:"MonitoredVehicleJourney"`
+}
+
+// SiriVehicleMonitoringDelivery lists the vehicles of an operator (SIRI-VM).
+type SiriVehicleMonitoringDelivery struct {
+	ResponseTimestamp time.Time             `xml:"ResponseTimestamp" json:"ResponseTimestamp"`
+	VehicleActivity   []SiriVehicleActivity `xml:"VehicleActivity" json:"VehicleActivity"`
+}
+
+type SiriVehicleActivity struct {
+	RecordedAtTime          time.Time          `xml:"RecordedAtTime" json:"RecordedAtTime"`
+	MonitoredVehicleJourney SiriVehicleJourney `xml:"MonitoredVehicleJourney" json:"MonitoredVehicleJourney"`
+}
+
+// SiriVehicleJourney describes a trip and, when it is tracked, the vehicle
+// running it. Refs are the IDs the OBA API uses: combined IDs for lines,
+// journeys and stops, agency IDs for operators. Bearing is in degrees
+// clockwise from north.
+type SiriVehicleJourney struct {
+	LineRef                 string                       `xml:"LineRef" json:"LineRef"`
+	DirectionRef            string                       `xml:"DirectionRef,omitempty" json:"DirectionRef,omitempty"`
+	FramedVehicleJourneyRef *SiriFramedVehicleJourneyRef `xml:"FramedVehicleJourneyRef,omitempty" json:"FramedVehicleJourneyRef,omitempty"`
+	PublishedLineName       string                       `xml:"PublishedLineName,omitempty" json:"PublishedLineName,omitempty"`
+	OperatorRef             string                       `xml:"OperatorRef" json:"OperatorRef"`
+	DestinationName         string                       `xml:"DestinationName,omitempty" json:"DestinationName,omitempty"`
+	Monitored               bool                         `xml:"Monitored" json:"Monitored"`
+	VehicleLocation         *SiriLocation                `xml:"VehicleLocation,omitempty" json:"VehicleLocation,omitempty"`
+	Bearing                 *float64                     `xml:"Bearing,omitempty" json:"Bearing,omitempty"`
+	VehicleRef              string                       `xml:"VehicleRef,omitempty" json:"VehicleRef,omitempty"`
+	MonitoredCall           *SiriMonitoredCall           `xml:"MonitoredCall,omitempty" json:"MonitoredCall,omitempty"`
+}
+
+// SiriFramedVehicleJourneyRef identifies a trip on a service date, given as
+// yyyy-MM-dd in DataFrameRef.
+type SiriFramedVehicleJourneyRef struct {
+	DataFrameRef           string `xml:"DataFrameRef" json:"DataFrameRef"`
+	DatedVehicleJourneyRef string `xml:"DatedVehicleJourneyRef" json:"DatedVehicleJourneyRef"`
+}
+
+type SiriLocation struct {
+	Longitude float64 `xml:"Longitude" json:"Longitude"`
+	Latitude  float64 `xml:"Latitude" json:"Latitude"`
+}
+
+// SiriMonitoredCall is the journey's call at the monitored stop. Expected
+// times are only given for predicted calls.
+type SiriMonitoredCall struct {
+	StopPointRef          string     `xml:"StopPointRef" json:"StopPointRef"`
+	AimedArrivalTime      *time.Time `xml:"AimedArrivalTime,omitempty" json:"AimedArrivalTime,omitempty"`
+	ExpectedArrivalTime   *time.Time `xml:"ExpectedArrivalTime,omitempty" json:"ExpectedArrivalTime,omitempty"`
+	AimedDepartureTime    *time.Time `xml:"AimedDepartureTime,omitempty" json:"AimedDepartureTime,omitempty"`
+	ExpectedDepartureTime *time.Time `xml:"ExpectedDepartureTime,omitempty" json:"ExpectedDepartureTime,omitempty"`
+}
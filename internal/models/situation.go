@@ -6,7 +6,7 @@ type Situation struct {
 	ActiveWindows      []ActiveWindow    `json:"activeWindows"`
 	AllAffects         []AffectedEntity  `json:"allAffects"`
 	ConsequenceMessage string            `json:"consequenceMessage"`
-	Consequences       []any             `json:"consequences"`
+	Consequences       []Consequence     `json:"consequences"`
 	PublicationWindows []any             `json:"publicationWindows"`
 	Reason             string            `json:"reason"`
 	Severity           string            `json:"severity"`
@@ -29,6 +29,12 @@ type AffectedEntity struct {
 	TripID        string `json:"tripId"`
 }
 
+// Consequence describes how a situation affects service. Condition is the
+// GTFS-RT effect in lower case, such as "detour" or "no_service".
+type Consequence struct {
+	Condition string `json:"condition"`
+}
+
 type TranslatedString struct {
 	Value string `json:"value,omitempty"`
 	Lang  string `json:"lang,omitempty"`
@@ -0,0 +1,29 @@
+package models
+
+// SLOReport describes how the API performed against its service level
+// objectives over the rolling SLO window.
+type SLOReport struct {
+	WindowDays                float64 `json:"windowDays"`
+	AvailabilityTargetPercent float64 `json:"availabilityTargetPercent"`
+	LatencyThresholdMs        int64   `json:"latencyThresholdMs"`
+	LatencyTargetPercent      float64 `json:"latencyTargetPercent"`
+	// Overall aggregates every endpoint; its Endpoint is empty.
+	Overall   SLOEndpointReport   `json:"overall"`
+	Endpoints []SLOEndpointReport `json:"endpoints"`
+}
+
+// SLOEndpointReport holds the SLIs and remaining error budgets of one endpoint.
+type SLOEndpointReport struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	Requests int64  `json:"requests"`
+	// Failed requests returned a 5xx; Slow ones exceeded the latency threshold.
+	Failed int64 `json:"failed"`
+	Slow   int64 `json:"slow"`
+	// AvailabilityPercent and LatencyPercent are the share of good requests.
+	AvailabilityPercent float64 `json:"availabilityPercent"`
+	LatencyPercent      float64 `json:"latencyPercent"`
+	// Budget fractions are 1 when untouched and negative once overspent.
+	AvailabilityBudgetRemaining float64 `json:"availabilityBudgetRemaining"`
+	LatencyBudgetRemaining      float64 `json:"latencyBudgetRemaining"`
+	Met                         bool    `json:"met"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// StopCluster is a group of nearby stops returned by stop-clusters, for
+// drawing stops on a map at zoom levels where individual stops would overlap.
+type StopCluster struct {
+	// Lat and Lon are the centroid of the clustered stops.
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	StopCount int     `json:"stopCount"`
+	// StopID is only set when the cluster holds a single stop.
+	StopID string `json:"stopId,omitempty"`
+	// Bounds encloses every stop in the cluster, for zooming in on it.
+	Bounds StopClusterBounds `json:"bounds"`
+}
+
+type StopClusterBounds struct {
+	MinLat float64 `json:"minLat"`
+	MinLon float64 `json:"minLon"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLon float64 `json:"maxLon"`
+}
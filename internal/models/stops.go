@@ -12,6 +12,17 @@ type Stop struct {
 	RouteIDs           []string `json:"routeIds"`
 	StaticRouteIDs     []string `json:"staticRouteIds"`
 	WheelchairBoarding string   `json:"wheelchairBoarding"`
+	// ActiveToday is only populated by stops-for-location; nil omits it elsewhere.
+	ActiveToday *bool `json:"activeToday,omitempty"`
+	// BookingRules is only populated by the stop endpoint, for stops served
+	// by GTFS-Flex (demand-responsive) trips.
+	BookingRules []BookingRule `json:"bookingRules,omitempty"`
+	// Amenities is only populated by the stop endpoint, when an amenities
+	// dataset is configured.
+	Amenities []Amenity `json:"amenities,omitempty"`
+	// FlagStop is set when a route serving the stop has continuous stopping,
+	// so its vehicles can also be hailed between stops.
+	FlagStop bool `json:"flagStop,omitempty"`
 }
 
 func NewStop(code, direction, id, name, parent, wheelchairBoarding string, lat, lon float64, locationType int, routeIDs, staticRouteIDs []string) Stop {
@@ -16,9 +16,15 @@ func NewModelTime(t time.Time) ModelTime {
 	return ModelTime{Time: t}
 }
 
+// zeroJSON is returned for every zero ModelTime, which are common enough in
+// large responses (unpredicted arrivals, trips not yet started) that
+// allocating each is noticeable. encoding/json copies what MarshalJSON
+// returns, so sharing it is safe.
+var zeroJSON = []byte("0")
+
 func (t ModelTime) MarshalJSON() ([]byte, error) {
 	if t.IsZero() {
-		return []byte("0"), nil
+		return zeroJSON, nil
 	}
 	return strconv.AppendInt(nil, t.UnixMilli(), 10), nil
 }
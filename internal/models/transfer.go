@@ -0,0 +1,52 @@
+package models
+
+import (
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+// Transfer is a transfers.txt connection from one stop to another.
+// TransferType follows GTFS: 0 recommended, 1 timed, 2 requires
+// MinTransferTime seconds, 3 not possible. Generated transfers were derived
+// from stop proximity because the feed has none; their Distance is the
+// straight-line distance between the stops in meters.
+type Transfer struct {
+	FromStopID      string   `json:"fromStopId"`
+	ToStopID        string   `json:"toStopId"`
+	FromRouteID     string   `json:"fromRouteId,omitempty"`
+	ToRouteID       string   `json:"toRouteId,omitempty"`
+	FromTripID      string   `json:"fromTripId,omitempty"`
+	ToTripID        string   `json:"toTripId,omitempty"`
+	TransferType    int      `json:"transferType"`
+	MinTransferTime *int64   `json:"minTransferTime,omitempty"`
+	Generated       bool     `json:"generated"`
+	Distance        *float64 `json:"distance,omitempty"`
+}
+
+// NewTransferFromDB converts a transfers row into the API model. combinedID
+// converts the row's stop, route and trip IDs into the IDs clients see.
+func NewTransferFromDB(row gtfsdb.Transfer, combinedID func(string) string) Transfer {
+	optionalID := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		return combinedID(id)
+	}
+	transfer := Transfer{
+		FromStopID:   combinedID(row.FromStopID),
+		ToStopID:     combinedID(row.ToStopID),
+		FromRouteID:  optionalID(nulls.StringOrEmpty(row.FromRouteID)),
+		ToRouteID:    optionalID(nulls.StringOrEmpty(row.ToRouteID)),
+		FromTripID:   optionalID(nulls.StringOrEmpty(row.FromTripID)),
+		ToTripID:     optionalID(nulls.StringOrEmpty(row.ToTripID)),
+		TransferType: int(row.TransferType),
+		Generated:    row.Generated == 1,
+	}
+	if row.MinTransferTime.Valid {
+		transfer.MinTransferTime = &row.MinTransferTime.Int64
+	}
+	if row.Distance.Valid {
+		transfer.Distance = &row.Distance.Float64
+	}
+	return transfer
+}
@@ -3,12 +3,15 @@ package models
 import "time"
 
 type TripDetails struct {
-	Frequency    *Frequency  `json:"frequency,omitempty"` // omitempty intentional: trip-details callers expect the field absent when the trip is not frequency-based
-	Schedule     *Schedule   `json:"schedule,omitempty"`
-	ServiceDate  ModelTime   `json:"serviceDate"`
-	SituationIDs []string    `json:"situationIds"`
-	Status       *TripStatus `json:"status,omitempty"`
-	TripID       string      `json:"tripId"`
+	// BookingRules are the GTFS-Flex booking rules referenced by the trip's
+	// flexible stop times; see Schedule.FlexStopTimes.
+	BookingRules []BookingRule `json:"bookingRules,omitempty"`
+	Frequency    *Frequency    `json:"frequency,omitempty"` // omitempty intentional: trip-details callers expect the field absent when the trip is not frequency-based
+	Schedule     *Schedule     `json:"schedule,omitempty"`
+	ServiceDate  ModelTime     `json:"serviceDate"`
+	SituationIDs []string      `json:"situationIds"`
+	Status       *TripStatus   `json:"status,omitempty"`
+	TripID       string        `json:"tripId"`
 }
 
 func NewTripDetails(tripID string, serviceDate time.Time, frequency *Frequency, status *TripStatus, schedule *Schedule, situationIDs []string) *TripDetails {
@@ -36,6 +39,7 @@ func NewEmptyTripDetails() *TripDetails {
 
 type TripStatus struct {
 	ActiveTripID               string     `json:"activeTripId"`
+	ActualStartTime            ModelTime  `json:"actualStartTime"` // 0 until the trip is observed leaving its first stop
 	BlockTripSequence          int        `json:"blockTripSequence"`
 	ClosestStop                string     `json:"closestStop"`
 	ClosestStopTimeOffset      int        `json:"closestStopTimeOffset"`
@@ -54,9 +58,11 @@ type TripStatus struct {
 	Orientation                float64    `json:"orientation"`
 	Phase                      string     `json:"phase"`
 	Position                   Location   `json:"position"`
+	PositionInterpolated       bool       `json:"positionInterpolated,omitempty"` // Position was advanced along the shape since the vehicle's last update
 	Predicted                  bool       `json:"predicted"`
 	ScheduleDeviation          int        `json:"scheduleDeviation"`
 	ScheduledDistanceAlongTrip float64    `json:"scheduledDistanceAlongTrip"`
+	ScheduledStartTime         ModelTime  `json:"scheduledStartTime"` // 0 for frequency-based trips
 	ServiceDate                ModelTime  `json:"serviceDate"`
 	SituationIDs               []string   `json:"situationIds"`
 	Status                     string     `json:"status"`
@@ -5,6 +5,8 @@ type VehicleStatus struct {
 	LastLocationUpdateTime ModelTime   `json:"lastLocationUpdateTime"`
 	LastUpdateTime         ModelTime   `json:"lastUpdateTime"`
 	Location               *Location   `json:"location"`
+	LocationInterpolated   bool        `json:"locationInterpolated,omitempty"` // Location was advanced along the trip's shape since the last update
+	Bearing                *float64    `json:"bearing,omitempty"`
 	TripID                 string      `json:"tripId"`
 	TripStatus             *TripStatus `json:"tripStatus"`
 	OccupancyCapacity      int         `json:"occupancyCapacity"`
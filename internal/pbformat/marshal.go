@@ -0,0 +1,301 @@
+package pbformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Marshal encodes v as the message md. Fields are matched to v's members by
+// their JSON name, so a response model encodes to the message mirroring its
+// JSON form: v may be a struct, matched through its json tags, or a
+// map[string]any such as a response's data. Members the message has no field
+// for are skipped. Scalar members implementing json.Marshaler, such as
+// models.ModelTime, are encoded as the number, string or boolean they
+// marshal to.
+func Marshal(md protoreflect.MessageDescriptor, v any) ([]byte, error) {
+	return appendMessage(nil, md, reflect.ValueOf(v))
+}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+func appendMessage(b []byte, md protoreflect.MessageDescriptor, v reflect.Value) ([]byte, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return b, nil
+	}
+	if v.Kind() != reflect.Struct && (v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String) {
+		return nil, fmt.Errorf("%s: cannot encode %s as a message", md.FullName(), v.Type())
+	}
+
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		member, ok := jsonMember(v, fd.JSONName())
+		if !ok {
+			continue
+		}
+		var err error
+		if b, err = appendField(b, fd, member); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// jsonMember returns the member of a struct or map that encoding/json would
+// write under name.
+func jsonMember(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() == reflect.Map {
+		member := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		return member, member.IsValid()
+	}
+	index, ok := jsonFieldIndexes(v.Type())[name]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	member, err := v.FieldByIndexErr(index)
+	if err != nil { // through a nil embedded pointer
+		return reflect.Value{}, false
+	}
+	return member, true
+}
+
+var jsonFieldCache sync.Map // reflect.Type -> map[string][]int
+
+// jsonFieldIndexes maps the JSON names of a struct's fields, including
+// promoted ones, to their field indexes.
+func jsonFieldIndexes(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	indexes := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(t) {
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" || (field.Anonymous && tag == "") {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		// As in encoding/json, the shallowest field of a name wins.
+		if taken, ok := indexes[name]; !ok || len(field.Index) < len(taken) {
+			indexes[name] = field.Index
+		}
+	}
+	jsonFieldCache.Store(t, indexes)
+	return indexes
+}
+
+// indirect follows pointers and interfaces, returning the zero Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func appendField(b []byte, fd protoreflect.FieldDescriptor, v reflect.Value) ([]byte, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return b, nil
+	}
+
+	if fd.IsList() {
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("%s: cannot encode %s as a list", fd.FullName(), v.Type())
+		}
+		packed := fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.StringKind && fd.Kind() != protoreflect.BytesKind
+		var items []byte
+		for i := range v.Len() {
+			var err error
+			if packed {
+				items, err = appendScalar(items, fd, v.Index(i))
+			} else {
+				b, err = appendSingular(b, fd, v.Index(i), true)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if packed && len(items) > 0 {
+			b = protowire.AppendTag(b, fd.Number(), protowire.BytesType)
+			b = protowire.AppendBytes(b, items)
+		}
+		return b, nil
+	}
+	return appendSingular(b, fd, v, false)
+}
+
+// appendSingular appends one tagged value of fd. Zero scalars are left out,
+// as proto3 does, unless they are list items.
+func appendSingular(b []byte, fd protoreflect.FieldDescriptor, v reflect.Value, listItem bool) ([]byte, error) {
+	if fd.Kind() == protoreflect.MessageKind {
+		v = indirect(v)
+		if !v.IsValid() {
+			return b, nil
+		}
+		message, err := appendMessage(nil, fd.Message(), v)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, fd.Number(), protowire.BytesType)
+		return protowire.AppendBytes(b, message), nil
+	}
+
+	value, err := scalarValue(fd, v)
+	if err != nil {
+		return nil, err
+	}
+	if !listItem && isZero(value) {
+		return b, nil
+	}
+	b = protowire.AppendTag(b, fd.Number(), wireType(fd.Kind()))
+	return appendValue(b, fd.Kind(), value), nil
+}
+
+// appendScalar appends an untagged scalar, for packed lists.
+func appendScalar(b []byte, fd protoreflect.FieldDescriptor, v reflect.Value) ([]byte, error) {
+	value, err := scalarValue(fd, v)
+	if err != nil {
+		return nil, err
+	}
+	return appendValue(b, fd.Kind(), value), nil
+}
+
+func wireType(kind protoreflect.Kind) protowire.Type {
+	switch kind {
+	case protoreflect.DoubleKind:
+		return protowire.Fixed64Type
+	case protoreflect.FloatKind:
+		return protowire.Fixed32Type
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return protowire.BytesType
+	default:
+		return protowire.VarintType
+	}
+}
+
+func isZero(value any) bool {
+	switch value := value.(type) {
+	case bool:
+		return !value
+	case int64:
+		return value == 0
+	case float64:
+		return value == 0
+	case string:
+		return value == ""
+	}
+	return false
+}
+
+func appendValue(b []byte, kind protoreflect.Kind, value any) []byte {
+	switch kind {
+	case protoreflect.BoolKind:
+		return protowire.AppendVarint(b, protowire.EncodeBool(value.(bool)))
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		return protowire.AppendVarint(b, uint64(value.(int64)))
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return protowire.AppendVarint(b, protowire.EncodeZigZag(value.(int64)))
+	case protoreflect.DoubleKind:
+		return protowire.AppendFixed64(b, math.Float64bits(value.(float64)))
+	case protoreflect.FloatKind:
+		return protowire.AppendFixed32(b, math.Float32bits(float32(value.(float64))))
+	default: // string and bytes
+		return protowire.AppendString(b, value.(string))
+	}
+}
+
+// scalarValue converts v to the Go type appendValue expects for fd's kind:
+// bool, int64, float64 or string.
+func scalarValue(fd protoreflect.FieldDescriptor, v reflect.Value) (any, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return zeroValue(fd.Kind()), nil
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		return jsonScalarValue(fd, v)
+	}
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if v.Kind() == reflect.Bool {
+			return v.Bool(), nil
+		}
+	case protoreflect.DoubleKind, protoreflect.FloatKind:
+		switch {
+		case v.CanFloat():
+			return v.Float(), nil
+		case v.CanInt():
+			return float64(v.Int()), nil
+		}
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+	default:
+		switch {
+		case v.CanInt():
+			return v.Int(), nil
+		case v.CanUint():
+			return int64(v.Uint()), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: cannot encode %s as %s", fd.FullName(), v.Type(), fd.Kind())
+}
+
+// jsonScalarValue converts a json.Marshaler's output to a scalar of fd's kind.
+func jsonScalarValue(fd protoreflect.FieldDescriptor, v reflect.Value) (any, error) {
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fd.FullName(), err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("%s: %w", fd.FullName(), err)
+	}
+	if decoded == nil {
+		return zeroValue(fd.Kind()), nil
+	}
+	return scalarValue(fd, reflect.ValueOf(jsonNumberValue(decoded)))
+}
+
+// jsonNumberValue turns a decoded json.Number into an int64 or float64.
+func jsonNumberValue(decoded any) any {
+	number, ok := decoded.(json.Number)
+	if !ok {
+		return decoded
+	}
+	if n, err := number.Int64(); err == nil {
+		return n
+	}
+	f, _ := number.Float64()
+	return f
+}
+
+func zeroValue(kind protoreflect.Kind) any {
+	switch kind {
+	case protoreflect.BoolKind:
+		return false
+	case protoreflect.DoubleKind, protoreflect.FloatKind:
+		return 0.0
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return ""
+	default:
+		return int64(0)
+	}
+}
@@ -0,0 +1,94 @@
+package pbformat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// unmarshal decodes b as md, with the stock protobuf runtime, so the tests
+// check the encoding against the schema rather than against this package.
+func unmarshal(t *testing.T, md protoreflect.MessageDescriptor, b []byte) protoreflect.Message {
+	t.Helper()
+	msg := dynamicpb.NewMessage(md)
+	require.NoError(t, proto.Unmarshal(b, msg))
+	return msg
+}
+
+func TestMarshal_ArrivalsResponse(t *testing.T) {
+	scheduled := time.UnixMilli(1_700_000_000_000)
+	status := models.NewTripStatus()
+	status.ScheduleDeviation = -90
+	status.Position = models.Location{Lat: 47.6, Lon: -122.3}
+	arrival := models.NewArrivalAndDeparture("1_100", "10", "", "1_trip", "Downtown", "1_75403", "",
+		scheduled, scheduled, scheduled, time.Time{}, time.Time{}, time.Time{},
+		false, true, true, 3, 20, 2, 0, 812.5, "default", "", "", "", status, []string{})
+	response := models.NewArrivalsAndDepartureResponse([]models.ArrivalAndDeparture{*arrival},
+		models.ReferencesModel{Stops: []models.Stop{{ID: "1_75403", Lat: 47.6, RouteIDs: []string{"1_100", "1_200"}}}},
+		[]string{"1_75404"}, []string{}, "1_75403", clock.NewMockClock(scheduled))
+
+	md := Message("ArrivalsAndDeparturesForStopResponse")
+	b, err := Marshal(md, response)
+	require.NoError(t, err)
+
+	msg := unmarshal(t, md, b)
+	assert.Equal(t, int64(200), msg.Get(md.Fields().ByName("code")).Int())
+	data := msg.Get(md.Fields().ByName("data")).Message()
+	entry := data.Get(data.Descriptor().Fields().ByName("entry")).Message()
+	entryFields := entry.Descriptor().Fields()
+	assert.Equal(t, "1_75403", entry.Get(entryFields.ByName("stop_id")).String())
+	assert.Equal(t, "1_75404", entry.Get(entryFields.ByName("nearby_stop_ids")).List().Get(0).String())
+
+	arrivals := entry.Get(entryFields.ByName("arrivals_and_departures")).List()
+	require.Equal(t, 1, arrivals.Len())
+	got := arrivals.Get(0).Message()
+	fields := got.Descriptor().Fields()
+	assert.Equal(t, "1_100", got.Get(fields.ByName("route_id")).String())
+	assert.Equal(t, int64(1_700_000_000_000), got.Get(fields.ByName("scheduled_arrival_time")).Int(), "ModelTime encodes as its JSON milliseconds")
+	assert.False(t, got.Has(fields.ByName("predicted_arrival_time")))
+	assert.Equal(t, 812.5, got.Get(fields.ByName("distance_from_stop")).Float())
+	assert.True(t, got.Get(fields.ByName("arrival_enabled")).Bool())
+
+	tripStatus := got.Get(fields.ByName("trip_status")).Message()
+	statusFields := tripStatus.Descriptor().Fields()
+	assert.Equal(t, int64(-90), tripStatus.Get(statusFields.ByName("schedule_deviation")).Int())
+	assert.Equal(t, int64(-1), tripStatus.Get(statusFields.ByName("occupancy_count")).Int())
+	assert.Equal(t, 47.6, tripStatus.Get(statusFields.ByName("position")).Message().Get(Message("Location").Fields().ByName("lat")).Float())
+
+	references := data.Get(data.Descriptor().Fields().ByName("references")).Message()
+	stops := references.Get(references.Descriptor().Fields().ByName("stops")).List()
+	require.Equal(t, 1, stops.Len())
+	routeIDs := stops.Get(0).Message().Get(Message("Stop").Fields().ByName("route_ids")).List()
+	assert.Equal(t, 2, routeIDs.Len())
+}
+
+func TestMarshal_PackedAndDurations(t *testing.T) {
+	type row struct {
+		Offsets []int                `json:"offsets"`
+		Time    models.ModelDuration `json:"time"`
+		Ignored string               `json:"ignored"`
+	}
+	md := mustParseSchema("test.proto", `syntax = "proto3"; message Row { repeated sint32 offsets = 1; int64 time = 2; }`).Messages().ByName("Row")
+
+	b, err := Marshal(md, row{Offsets: []int{0, -1, 300}, Time: models.NewModelDuration(90 * time.Second), Ignored: "x"})
+	require.NoError(t, err)
+
+	msg := unmarshal(t, md, b)
+	offsets := msg.Get(md.Fields().ByName("offsets")).List()
+	require.Equal(t, 3, offsets.Len(), "zeros are kept in lists")
+	assert.Equal(t, int64(-1), offsets.Get(1).Int())
+	assert.Equal(t, int64(90), msg.Get(md.Fields().ByName("time")).Int())
+}
+
+func TestMarshal_TypeMismatch(t *testing.T) {
+	md := mustParseSchema("test.proto", `syntax = "proto3"; message Row { int64 id = 1; }`).Messages().ByName("Row")
+	_, err := Marshal(md, map[string]any{"id": "not a number"})
+	assert.ErrorContains(t, err, "cannot encode string as int64")
+}
@@ -0,0 +1,279 @@
+// Package pbformat encodes API responses as protocol buffers, following the
+// messages of onebusaway.proto.
+//
+// The schema is read from the embedded .proto file when the package loads,
+// so the file checked into the repository is the only definition clients and
+// the server share. Only the subset of the language the schema uses is
+// understood: proto3 messages, nested messages and singular or repeated
+// fields of scalar and message types.
+package pbformat
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+//go:embed onebusaway.proto
+var schemaSource string
+
+// ContentType is the media type of protobuf responses.
+const ContentType = "application/x-protobuf"
+
+var schema = mustParseSchema("onebusaway.proto", schemaSource)
+
+// Message returns the schema's top-level message called name, or nil if
+// there is none.
+func Message(name string) protoreflect.MessageDescriptor {
+	return schema.Messages().ByName(protoreflect.Name(name))
+}
+
+func mustParseSchema(filename, src string) protoreflect.FileDescriptor {
+	fd, err := parseSchema(filename, src)
+	if err != nil {
+		panic(fmt.Sprintf("pbformat: %v", err))
+	}
+	return fd
+}
+
+// parseSchema parses a .proto file into a file descriptor.
+func parseSchema(filename, src string) (protoreflect.FileDescriptor, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	p := &schemaParser{tokens: tokens}
+	file := &descriptorpb.FileDescriptorProto{Name: proto.String(filename)}
+	if err := p.parseFile(file); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return fd, nil
+}
+
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double": descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":  descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int32":  descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint32": descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"sint32": descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+	"sint64": descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+	"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+}
+
+type token struct {
+	text string
+	line int
+}
+
+// tokenize splits a .proto file into identifiers, numbers, quoted strings
+// and punctuation, dropping comments.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"':
+			end := strings.IndexByte(src[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, token{src[i : i+end+2], line})
+			i += end + 2
+		case isIdentChar(c):
+			start := i
+			for i < len(src) && (isIdentChar(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{src[start:i], line})
+		case strings.IndexByte("{}=;", c) >= 0:
+			tokens = append(tokens, token{string(c), line})
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", line, c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+type schemaParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *schemaParser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of file")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+func (p *schemaParser) expect(text string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if tok.text != text {
+		return fmt.Errorf("line %d: expected %q, found %q", tok.line, text, tok.text)
+	}
+	return nil
+}
+
+func (p *schemaParser) parseFile(file *descriptorpb.FileDescriptorProto) error {
+	for p.pos < len(p.tokens) {
+		tok, _ := p.next()
+		switch tok.text {
+		case "syntax":
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			syntax, err := p.next()
+			if err != nil {
+				return err
+			}
+			if syntax.text != `"proto3"` {
+				return fmt.Errorf("line %d: only proto3 is supported, found %s", syntax.line, syntax.text)
+			}
+			file.Syntax = proto.String("proto3")
+		case "package":
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			file.Package = proto.String(name.text)
+		case "option":
+			// File options only matter to code generators.
+			for p.pos < len(p.tokens) && p.tokens[p.pos].text != ";" {
+				p.pos++
+			}
+		case "message":
+			message, err := p.parseMessage()
+			if err != nil {
+				return err
+			}
+			file.MessageType = append(file.MessageType, message)
+			continue
+		default:
+			return fmt.Errorf("line %d: unexpected %q", tok.line, tok.text)
+		}
+		if err := p.expect(";"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *schemaParser) parseMessage() (*descriptorpb.DescriptorProto, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	message := &descriptorpb.DescriptorProto{Name: proto.String(name.text)}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.text {
+		case "}":
+			return message, nil
+		case "message":
+			nested, err := p.parseMessage()
+			if err != nil {
+				return nil, err
+			}
+			message.NestedType = append(message.NestedType, nested)
+		default:
+			p.pos--
+			field, err := p.parseField()
+			if err != nil {
+				return nil, err
+			}
+			message.Field = append(message.Field, field)
+		}
+	}
+}
+
+// parseField parses "[repeated] type name = number;".
+func (p *schemaParser) parseField() (*descriptorpb.FieldDescriptorProto, error) {
+	field := &descriptorpb.FieldDescriptorProto{
+		Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	typ, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if typ.text == "repeated" {
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		if typ, err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if scalar, ok := scalarTypes[typ.text]; ok {
+		field.Type = scalar.Enum()
+	} else {
+		// Message names are resolved against the enclosing scopes by protodesc.
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(typ.text)
+	}
+
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field.Name = proto.String(name.text)
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	number, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.ParseInt(number.text, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: invalid field number %q", number.line, number.text)
+	}
+	field.Number = proto.Int32(int32(n))
+	return field, p.expect(";")
+}
@@ -0,0 +1,57 @@
+package pbformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestSchemaJSONNamesMatchResponses(t *testing.T) {
+	for _, name := range []string{"ArrivalsAndDeparturesForStopResponse", "ArrivalsAndDeparturesForLocationResponse", "TripsForLocationResponse"} {
+		require.NotNil(t, Message(name), name)
+	}
+	arrival := Message("ArrivalAndDeparture")
+	assert.Equal(t, "endPickupDropOffWindow", arrival.Fields().ByName("end_pickup_drop_off_window").JSONName())
+	assert.Equal(t, "peakOffpeak", Message("Trip").Fields().ByName("peak_offpeak").JSONName())
+	assert.Nil(t, Message("Data"), "only top-level messages are looked up")
+}
+
+func TestParseSchema(t *testing.T) {
+	fd, err := parseSchema("test.proto", `
+		// A comment
+		syntax = "proto3";
+		package test;
+		option go_package = "example.com/test";
+
+		/* Nested messages resolve against their enclosing scope. */
+		message Outer {
+		  repeated Inner items = 1;
+		  string name = 2;
+		  message Inner { sint32 offset = 1; }
+		}
+	`)
+	require.NoError(t, err)
+	outer := fd.Messages().ByName("Outer")
+	require.NotNil(t, outer)
+	items := outer.Fields().ByName("items")
+	assert.Equal(t, protoreflect.Repeated, items.Cardinality())
+	assert.Equal(t, protoreflect.FullName("test.Outer.Inner"), items.Message().FullName())
+}
+
+func TestParseSchema_Errors(t *testing.T) {
+	tests := map[string]string{
+		"proto2":        `syntax = "proto2";`,
+		"missing =":     `syntax = "proto3"; message A { string a 1; }`,
+		"unknown type":  `syntax = "proto3"; message A { Missing a = 1; }`,
+		"reused number": `syntax = "proto3"; message A { string a = 1; string b = 1; }`,
+		"unterminated":  `syntax = "proto3"; message A { string a = 1;`,
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseSchema("test.proto", src)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package portal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"maglev.onebusaway.org/internal/appconf"
+)
+
+// Mailer delivers the portal's plain-text emails.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// NewMailer returns an SMTP mailer for cfg, or a LogMailer when no SMTP host
+// is configured.
+func NewMailer(cfg appconf.SMTPConfig, logger *slog.Logger) Mailer {
+	if cfg.Host == "" {
+		return LogMailer{Logger: logger}
+	}
+	return SMTPMailer{cfg: cfg}
+}
+
+// SMTPMailer sends email through an SMTP server, using STARTTLS when the
+// server offers it.
+type SMTPMailer struct {
+	cfg appconf.SMTPConfig
+}
+
+// Send delivers one message. The context is not consulted because net/smtp
+// has no cancellation support.
+func (m SMTPMailer) Send(_ context.Context, to, subject, body string) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{to}, buildMessage(m.cfg.From, to, subject, body)); err != nil {
+		return fmt.Errorf("sending mail to %s: %w", to, err)
+	}
+	return nil
+}
+
+// buildMessage formats an RFC 5322 message. Callers pass addresses that have
+// already been validated, so no header can contain a line break.
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// LogMailer logs messages instead of sending them, so the signup flow can be
+// exercised in development without a mail server.
+type LogMailer struct {
+	Logger *slog.Logger
+}
+
+// Send logs the message at info level.
+func (m LogMailer) Send(_ context.Context, to, subject, body string) error {
+	logger := m.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info("developer portal email not sent: no SMTP server configured",
+		slog.String("to", to),
+		slog.String("subject", subject),
+		slog.String("body", body))
+	return nil
+}
@@ -0,0 +1,293 @@
+// Package portal implements the optional developer portal: self-service API
+// key signup with email verification, per-key rate limits, and an admin
+// approval workflow for elevated quotas.
+package portal
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/issuedkeys"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// Key statuses and quota tiers stored in developer_api_keys.
+const (
+	StatusPending = "pending"
+	StatusActive  = "active"
+
+	TierStandard = "standard"
+	TierElevated = "elevated"
+)
+
+// VerifyPath is the endpoint verification links point at.
+const VerifyPath = "/api/where/developer-verify.json"
+
+// MaxNameLength and MaxReasonLength bound the free-text fields, in runes.
+const (
+	MaxNameLength   = 100
+	MaxReasonLength = 1000
+)
+
+var (
+	ErrInvalidEmail     = errors.New("email must be a valid address")
+	ErrTooManyKeys      = errors.New("this email address already has the maximum number of API keys")
+	ErrInvalidToken     = errors.New("verification token is invalid, expired or already used")
+	ErrUnknownKey       = errors.New("API key was not issued by the developer portal")
+	ErrAlreadyElevated  = errors.New("API key already has an elevated quota")
+	ErrNoQuotaRequest   = errors.New("no pending quota request for this key")
+	ErrEmptyQuotaReason = errors.New("reason is required")
+)
+
+// Service issues and validates portal API keys. Keys are persisted in the
+// GTFS database alongside problem reports, so they survive restarts and
+// static data reloads.
+type Service struct {
+	cfg     appconf.PortalConfig
+	queries *gtfsdb.Queries
+	mailer  Mailer
+	clock   clock.Clock
+	logger  *slog.Logger
+
+	// limiters drops the limiter of a key when a quota decision changes its
+	// tier.
+	limiters issuedkeys.Limiters
+}
+
+// New creates a portal Service. Zero limits in cfg fall back to the defaults.
+func New(cfg appconf.PortalConfig, queries *gtfsdb.Queries, mailer Mailer, c clock.Clock, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		cfg:     cfg.WithDefaults(),
+		queries: queries,
+		mailer:  mailer,
+		clock:   c,
+		logger:  logger.With(slog.String("component", "developer_portal")),
+	}
+}
+
+// Signup records a pending key for email and sends it a verification link.
+// The key itself is only generated once the link is followed.
+func (s *Service) Signup(ctx context.Context, email, name string) error {
+	address, ok := issuedkeys.NormalizeEmail(email)
+	if !ok {
+		return ErrInvalidEmail
+	}
+	name = issuedkeys.Truncate(strings.TrimSpace(name), MaxNameLength)
+	now := s.clock.Now()
+
+	open, err := s.queries.CountOpenDeveloperAPIKeysByEmail(ctx, gtfsdb.CountOpenDeveloperAPIKeysByEmailParams{
+		Email: address,
+		Now:   now.UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+	if open >= int64(s.cfg.MaxKeysPerEmail) {
+		return ErrTooManyKeys
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.cfg.VerificationTTL)
+	if _, err := s.queries.CreateDeveloperAPIKey(ctx, gtfsdb.CreateDeveloperAPIKeyParams{
+		Email:                 address,
+		Name:                  name,
+		VerificationTokenHash: issuedkeys.Hash(token),
+		CreatedAt:             now.UnixMilli(),
+		VerificationExpiresAt: expiresAt.UnixMilli(),
+	}); err != nil {
+		return err
+	}
+
+	link := s.cfg.BaseURL + VerifyPath + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Follow this link to verify your email address and receive your OneBusAway API key:\n\n%s\n\n"+
+		"The link expires at %s. If you did not request an API key, you can ignore this message.\n",
+		link, expiresAt.UTC().Format(time.RFC1123))
+	if err := s.mailer.Send(ctx, address, "Verify your OneBusAway API key request", body); err != nil {
+		return err
+	}
+	s.logger.Info("developer portal signup pending verification", slog.String("email", address))
+	return nil
+}
+
+// Verify activates the signup that token was issued for and returns its new
+// key. The key is included only in this result; a token can be used once.
+func (s *Service) Verify(ctx context.Context, token string) (models.DeveloperKey, error) {
+	if token == "" {
+		return models.DeveloperKey{}, ErrInvalidToken
+	}
+	pending, err := s.queries.GetDeveloperAPIKeyByVerificationToken(ctx, issuedkeys.Hash(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DeveloperKey{}, ErrInvalidToken
+	}
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+	now := s.clock.Now()
+	if pending.Status != StatusPending || now.UnixMilli() > pending.VerificationExpiresAt {
+		return models.DeveloperKey{}, ErrInvalidToken
+	}
+
+	key, err := issuedkeys.NewKey()
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+	active, err := s.queries.ActivateDeveloperAPIKey(ctx, gtfsdb.ActivateDeveloperAPIKeyParams{
+		ApiKeyHash: sql.NullString{String: issuedkeys.Hash(key), Valid: true},
+		VerifiedAt: sql.NullInt64{Int64: now.UnixMilli(), Valid: true},
+		ID:         pending.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// A concurrent request used the token first.
+		return models.DeveloperKey{}, ErrInvalidToken
+	}
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+
+	s.logger.Info("developer portal key issued", slog.String("email", active.Email), slog.Int64("id", active.ID))
+	issued := s.toModel(active)
+	issued.Key = key
+	return issued, nil
+}
+
+// Limiter returns the rate limiter of an active portal key, or ErrUnknownKey
+// when key was not issued by the portal.
+func (s *Service) Limiter(ctx context.Context, key string) (*rate.Limiter, error) {
+	if key == "" {
+		return nil, ErrUnknownKey
+	}
+	hash := issuedkeys.Hash(key)
+	return s.limiters.Get(hash, func() (int, error) {
+		row, err := s.queries.GetActiveDeveloperAPIKey(ctx, sql.NullString{String: hash, Valid: true})
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrUnknownKey
+		}
+		if err != nil {
+			return 0, err
+		}
+		return s.rateLimitFor(row.Tier), nil
+	})
+}
+
+// RequestQuota asks an admin to raise key to the elevated tier.
+func (s *Service) RequestQuota(ctx context.Context, key, reason string) (models.DeveloperKey, error) {
+	reason = issuedkeys.Truncate(strings.TrimSpace(reason), MaxReasonLength)
+	if reason == "" {
+		return models.DeveloperKey{}, ErrEmptyQuotaReason
+	}
+	row, err := s.queries.GetActiveDeveloperAPIKey(ctx, sql.NullString{String: issuedkeys.Hash(key), Valid: key != ""})
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DeveloperKey{}, ErrUnknownKey
+	}
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+	if row.Tier == TierElevated {
+		return models.DeveloperKey{}, ErrAlreadyElevated
+	}
+
+	row, err = s.queries.RequestDeveloperAPIKeyQuota(ctx, gtfsdb.RequestDeveloperAPIKeyQuotaParams{
+		QuotaRequest:     sql.NullString{String: reason, Valid: true},
+		QuotaRequestedAt: sql.NullInt64{Int64: s.clock.Now().UnixMilli(), Valid: true},
+		ID:               row.ID,
+	})
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+	s.logger.Info("developer portal quota requested", slog.String("email", row.Email), slog.Int64("id", row.ID))
+	return s.toModel(row), nil
+}
+
+// Key returns the portal key with id, without the key itself.
+func (s *Service) Key(ctx context.Context, id int64) (models.DeveloperKey, error) {
+	row, err := s.queries.GetDeveloperAPIKey(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DeveloperKey{}, ErrUnknownKey
+	}
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+	return s.toModel(row), nil
+}
+
+// QuotaRequests lists the pending elevated quota requests, oldest first.
+func (s *Service) QuotaRequests(ctx context.Context) ([]models.DeveloperKey, error) {
+	rows, err := s.queries.ListDeveloperAPIKeyQuotaRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]models.DeveloperKey, 0, len(rows))
+	for _, row := range rows {
+		requests = append(requests, s.toModel(row))
+	}
+	return requests, nil
+}
+
+// DecideQuota approves or denies the pending quota request of the key with
+// id and emails the developer the outcome. A denied key keeps the standard
+// tier and may ask again.
+func (s *Service) DecideQuota(ctx context.Context, id int64, approve bool) (models.DeveloperKey, error) {
+	tier := TierStandard
+	if approve {
+		tier = TierElevated
+	}
+	row, err := s.queries.DecideDeveloperAPIKeyQuota(ctx, gtfsdb.DecideDeveloperAPIKeyQuotaParams{Tier: tier, ID: id})
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DeveloperKey{}, ErrNoQuotaRequest
+	}
+	if err != nil {
+		return models.DeveloperKey{}, err
+	}
+
+	// The next request rebuilds the limiter for the new tier.
+	s.limiters.Forget(row.ApiKeyHash.String)
+
+	outcome := "declined; your key keeps its current limit"
+	if approve {
+		outcome = fmt.Sprintf("approved; your key now allows %d requests per second", s.rateLimitFor(tier))
+	}
+	body := fmt.Sprintf("Your request for an elevated OneBusAway API quota was %s.\n", outcome)
+	if err := s.mailer.Send(ctx, row.Email, "Your OneBusAway API quota request", body); err != nil {
+		// The decision is already recorded; the email is a courtesy.
+		s.logger.Warn("failed to send quota decision email", slog.Int64("id", row.ID), slog.String("error", err.Error()))
+	}
+	s.logger.Info("developer portal quota decided", slog.Int64("id", row.ID), slog.String("tier", tier))
+	return s.toModel(row), nil
+}
+
+func (s *Service) rateLimitFor(tier string) int {
+	if tier == TierElevated {
+		return s.cfg.ElevatedRateLimit
+	}
+	return s.cfg.RateLimit
+}
+
+func (s *Service) toModel(row gtfsdb.DeveloperApiKey) models.DeveloperKey {
+	return models.NewDeveloperKey(row, s.rateLimitFor(row.Tier))
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
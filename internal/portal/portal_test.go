@@ -0,0 +1,224 @@
+package portal
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+)
+
+type sentMail struct {
+	to, subject, body string
+}
+
+type recordingMailer struct {
+	sent []sentMail
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, sentMail{to: to, subject: subject, body: body})
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken returns the verification token from the most recent email.
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	require.NotEmpty(t, m.sent)
+	match := tokenPattern.FindStringSubmatch(m.sent[len(m.sent)-1].body)
+	require.Len(t, match, 2, "email should contain a verification link")
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func newTestService(t *testing.T) (*Service, *recordingMailer, *clock.MockClock) {
+	t.Helper()
+	client, err := gtfsdb.NewClient(gtfsdb.Config{DBPath: ":memory:", Env: appconf.Test})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	mailer := &recordingMailer{}
+	mockClock := clock.NewMockClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	cfg := appconf.PortalConfig{
+		Enabled:           true,
+		BaseURL:           "https://api.example.org",
+		RateLimit:         2,
+		ElevatedRateLimit: 10,
+	}
+	return New(cfg, client.Queries, mailer, mockClock, nil), mailer, mockClock
+}
+
+func TestSignupAndVerify(t *testing.T) {
+	svc, mailer, _ := newTestService(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Signup(ctx, " Dev@Example.org ", "Trip planner"))
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "dev@example.org", mailer.sent[0].to)
+	assert.Contains(t, mailer.sent[0].body, "https://api.example.org"+VerifyPath+"?token=")
+
+	token := mailer.lastToken(t)
+
+	// The key is unknown until the signup is verified.
+	key, err := svc.Verify(ctx, token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, key.Key)
+	assert.Equal(t, "dev@example.org", key.Email)
+	assert.Equal(t, "Trip planner", key.Name)
+	assert.Equal(t, StatusActive, key.Status)
+	assert.Equal(t, TierStandard, key.Tier)
+	assert.Equal(t, 2, key.RateLimit)
+
+	limiter, err := svc.Limiter(ctx, key.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, limiter.Burst())
+
+	_, err = svc.Verify(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "a token can only be used once")
+}
+
+func TestVerifyRejectsExpiredAndUnknownTokens(t *testing.T) {
+	svc, mailer, mockClock := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Verify(ctx, "not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	require.NoError(t, svc.Signup(ctx, "dev@example.org", ""))
+	mockClock.Advance(appconf.DefaultPortalVerificationTTL + time.Minute)
+
+	_, err = svc.Verify(ctx, mailer.lastToken(t))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestSignupValidation(t *testing.T) {
+	svc, mailer, mockClock := newTestService(t)
+	ctx := context.Background()
+
+	for _, email := range []string{"", "not-an-email", "Dev <dev@example.org>", "dev@example.org\r\nBcc: x@example.org"} {
+		assert.ErrorIs(t, svc.Signup(ctx, email, ""), ErrInvalidEmail, "email %q", email)
+	}
+	assert.Empty(t, mailer.sent)
+
+	for range appconf.DefaultPortalMaxKeysPerEmail {
+		require.NoError(t, svc.Signup(ctx, "dev@example.org", ""))
+	}
+	assert.ErrorIs(t, svc.Signup(ctx, "DEV@example.org", ""), ErrTooManyKeys)
+
+	// Unverified signups stop counting once their link expires.
+	mockClock.Advance(appconf.DefaultPortalVerificationTTL + time.Minute)
+	assert.NoError(t, svc.Signup(ctx, "dev@example.org", ""))
+}
+
+func TestLimiterUnknownKey(t *testing.T) {
+	svc, _, _ := newTestService(t)
+
+	_, err := svc.Limiter(context.Background(), "")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	_, err = svc.Limiter(context.Background(), "TEST")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestQuotaApprovalWorkflow(t *testing.T) {
+	svc, mailer, _ := newTestService(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Signup(ctx, "dev@example.org", ""))
+	issued, err := svc.Verify(ctx, mailer.lastToken(t))
+	require.NoError(t, err)
+
+	_, err = svc.RequestQuota(ctx, issued.Key, "  ")
+	assert.ErrorIs(t, err, ErrEmptyQuotaReason)
+	_, err = svc.RequestQuota(ctx, "TEST", "more please")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	requested, err := svc.RequestQuota(ctx, issued.Key, "Real-time signs at 40 stations")
+	require.NoError(t, err)
+	assert.Equal(t, "Real-time signs at 40 stations", requested.QuotaRequest)
+
+	// Cache the standard limiter so the approval has to replace it.
+	limiter, err := svc.Limiter(ctx, issued.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, limiter.Burst())
+
+	pending, err := svc.QuotaRequests(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, issued.ID, pending[0].ID)
+	assert.Empty(t, pending[0].Key, "listings must never expose the key")
+
+	approved, err := svc.DecideQuota(ctx, issued.ID, true)
+	require.NoError(t, err)
+	assert.Equal(t, TierElevated, approved.Tier)
+	assert.Equal(t, 10, approved.RateLimit)
+	assert.Empty(t, approved.QuotaRequest)
+	assert.True(t, strings.Contains(mailer.sent[len(mailer.sent)-1].body, "approved"))
+
+	limiter, err = svc.Limiter(ctx, issued.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 10, limiter.Burst())
+
+	pending, err = svc.QuotaRequests(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = svc.DecideQuota(ctx, issued.ID, true)
+	assert.ErrorIs(t, err, ErrNoQuotaRequest)
+	_, err = svc.RequestQuota(ctx, issued.Key, "even more")
+	assert.ErrorIs(t, err, ErrAlreadyElevated)
+}
+
+func TestQuotaDenialKeepsStandardTier(t *testing.T) {
+	svc, mailer, _ := newTestService(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Signup(ctx, "dev@example.org", ""))
+	issued, err := svc.Verify(ctx, mailer.lastToken(t))
+	require.NoError(t, err)
+	_, err = svc.RequestQuota(ctx, issued.Key, "load testing")
+	require.NoError(t, err)
+
+	denied, err := svc.DecideQuota(ctx, issued.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, TierStandard, denied.Tier)
+	assert.Contains(t, mailer.sent[len(mailer.sent)-1].body, "declined")
+
+	_, err = svc.RequestQuota(ctx, issued.Key, "production launch")
+	assert.NoError(t, err, "a denied key may ask again")
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg := string(buildMessage("portal@example.org", "dev@example.org", "Subject line", "line one\nline two\n"))
+
+	assert.Contains(t, msg, "From: portal@example.org\r\n")
+	assert.Contains(t, msg, "To: dev@example.org\r\n")
+	assert.Contains(t, msg, "Subject: Subject line\r\n")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nline one\r\nline two\r\n"))
+}
+
+func TestKey(t *testing.T) {
+	svc, mailer, _ := newTestService(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Signup(ctx, "dev@example.org", ""))
+	issued, err := svc.Verify(ctx, mailer.lastToken(t))
+	require.NoError(t, err)
+
+	key, err := svc.Key(ctx, issued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, issued.Email, key.Email)
+	assert.Empty(t, key.Key)
+
+	_, err = svc.Key(ctx, issued.ID+1)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
@@ -0,0 +1,69 @@
+// Package ratelimit keeps the token bucket behind the server-wide rate limit.
+// The memory backend limits each process on its own; the Redis backend keeps
+// one bucket in Redis so every replica pointed at it shares the limit.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// Backend holds a token bucket that refills at limit tokens per second and
+// holds at most burst tokens. Callers pass the current limit and burst on
+// every call so configuration reloads take effect without rebuilding the
+// backend.
+type Backend interface {
+	// Allow takes a token when one is available, reporting whether it did and
+	// how many whole tokens are left.
+	Allow(ctx context.Context, limit rate.Limit, burst int) (allowed bool, remaining int, err error)
+	// Remaining reports how many whole tokens are available without taking one.
+	Remaining(ctx context.Context, limit rate.Limit, burst int) (int, error)
+	// Close releases any connections the backend holds.
+	Close() error
+}
+
+// Memory is a Backend that keeps the bucket in this process.
+type Memory struct {
+	mu      sync.Mutex
+	limiter *rate.Limiter
+}
+
+// NewMemory creates an in-memory backend.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// limiterFor returns the bucket for limit and burst, starting a full one when
+// either has changed.
+func (m *Memory) limiterFor(limit rate.Limit, burst int) *rate.Limiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.limiter == nil || m.limiter.Limit() != limit || m.limiter.Burst() != burst {
+		m.limiter = rate.NewLimiter(limit, burst)
+	}
+	return m.limiter
+}
+
+// Allow implements Backend.
+func (m *Memory) Allow(_ context.Context, limit rate.Limit, burst int) (bool, int, error) {
+	limiter := m.limiterFor(limit, burst)
+	allowed := limiter.Allow()
+	return allowed, wholeTokens(limiter.Tokens()), nil
+}
+
+// Remaining implements Backend.
+func (m *Memory) Remaining(_ context.Context, limit rate.Limit, burst int) (int, error) {
+	return wholeTokens(m.limiterFor(limit, burst).Tokens()), nil
+}
+
+// Close implements Backend.
+func (m *Memory) Close() error {
+	return nil
+}
+
+func wholeTokens(tokens float64) int {
+	return max(int(math.Floor(tokens)), 0)
+}
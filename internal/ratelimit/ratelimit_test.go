@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestMemoryTakesTokensAndRestartsOnNewLimit(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	limit := rate.Every(time.Hour)
+
+	for want := 2; want >= 0; want-- {
+		allowed, remaining, err := m.Allow(ctx, limit, 3)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, want, remaining)
+	}
+	allowed, remaining, err := m.Allow(ctx, limit, 3)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Zero(t, remaining)
+
+	remaining, err = m.Remaining(ctx, limit, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, remaining, "a new burst starts a full bucket")
+}
+
+// fakeRedis answers RESP commands with canned replies and records the
+// commands it received.
+type fakeRedis struct {
+	t        *testing.T
+	listener net.Listener
+	reply    func(args []string) string
+
+	mu       sync.Mutex
+	commands [][]string
+	conns    int
+}
+
+func newFakeRedis(t *testing.T, reply func(args []string) string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{t: t, listener: listener, reply: reply}
+	t.Cleanup(func() { _ = listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			header, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			args[i] = string(buf[:size])
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, args)
+		f.mu.Unlock()
+		if _, err := io.WriteString(conn, f.reply(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) received() ([][]string, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.commands, f.conns
+}
+
+func TestRedisAuthenticatesAndRunsBucketScript(t *testing.T) {
+	server := newFakeRedis(t, func(args []string) string {
+		if args[0] == "EVAL" {
+			return "*2\r\n:1\r\n:4\r\n"
+		}
+		return "+OK\r\n"
+	})
+	backend, err := NewRedis("redis://maglev:secret@"+server.listener.Addr().String()+"/2", "")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	for range 2 {
+		allowed, remaining, err := backend.Allow(context.Background(), 10, 5)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 4, remaining)
+	}
+
+	commands, conns := server.received()
+	assert.Equal(t, 1, conns, "the connection is reused")
+	require.Len(t, commands, 4)
+	assert.Equal(t, []string{"AUTH", "maglev", "secret"}, commands[0])
+	assert.Equal(t, []string{"SELECT", "2"}, commands[1])
+	assert.Equal(t, []string{"EVAL", tokenBucketScript, "1", DefaultRedisKey, "10", "5", "1"}, commands[2])
+}
+
+func TestRedisRemainingDoesNotTakeAToken(t *testing.T) {
+	server := newFakeRedis(t, func([]string) string { return "*2\r\n:0\r\n:3\r\n" })
+	backend, err := NewRedis("redis://"+server.listener.Addr().String(), "replicas")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	remaining, err := backend.Remaining(context.Background(), 0.5, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, remaining)
+
+	commands, _ := server.received()
+	require.Len(t, commands, 1)
+	assert.Equal(t, []string{"EVAL", tokenBucketScript, "1", "replicas", "0.5", "3", "0"}, commands[0])
+}
+
+func TestRedisReportsErrors(t *testing.T) {
+	server := newFakeRedis(t, func([]string) string { return "-NOSCRIPT scripting disabled\r\n" })
+	backend, err := NewRedis("redis://"+server.listener.Addr().String(), "")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	_, _, err = backend.Allow(context.Background(), 1, 1)
+	assert.EqualError(t, err, "redis: NOSCRIPT scripting disabled")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	unreachable, err := NewRedis("redis://"+addr, "")
+	require.NoError(t, err)
+	_, _, err = unreachable.Allow(context.Background(), 1, 1)
+	assert.ErrorContains(t, err, "connecting to Redis")
+}
+
+func TestParseRedisURL(t *testing.T) {
+	for _, valid := range []string{"redis://localhost", "rediss://user:pw@cache.internal:6380/1"} {
+		_, err := ParseRedisURL(valid)
+		assert.NoError(t, err, valid)
+	}
+	for _, invalid := range []string{"http://localhost", "redis://", "redis://localhost/db", "localhost:6379"} {
+		_, err := ParseRedisURL(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
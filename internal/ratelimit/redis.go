@@ -0,0 +1,330 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRedisKey is the key the shared bucket is stored under unless another
+// is configured.
+const DefaultRedisKey = "maglev:rate-limit"
+
+// Redis connection settings. Commands are bounded by redisTimeout so a slow or
+// unreachable server delays requests by at most that long before the
+// middleware lets them through.
+const (
+	redisTimeout      = 250 * time.Millisecond
+	redisMaxIdleConns = 16
+)
+
+// tokenBucketScript refills and optionally takes from the bucket in one atomic
+// step, using the server's clock so replicas with skewed clocks agree.
+// ARGV: refill per second, burst, "1" to take a token. Returns {allowed,
+// whole tokens left}.
+const tokenBucketScript = `
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(now - updated, 0) * rate / 1000)
+local allowed = 0
+if ARGV[3] == '1' then
+  if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+  end
+  redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+  local ttl = 60000
+  if rate > 0 then
+    ttl = math.ceil(burst * 1000 / rate) + 1000
+  end
+  redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return {allowed, math.floor(tokens)}
+`
+
+// Redis is a Backend that keeps the bucket in a Redis server, so every
+// replica using the same server and key shares one limit.
+type Redis struct {
+	addr      string
+	username  string
+	password  string
+	db        int
+	tlsConfig *tls.Config
+	key       string
+	timeout   time.Duration
+	idle      chan *redisConn
+}
+
+// NewRedis creates a Redis backend for a redis:// or rediss:// (TLS) URL of
+// the form redis://[user:password@]host[:port][/db], storing the bucket under
+// key (DefaultRedisKey when empty). Connections are made on first use.
+func NewRedis(rawURL, key string) (*Redis, error) {
+	u, err := ParseRedisURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	r := &Redis{
+		addr:    u.Host,
+		key:     key,
+		timeout: redisTimeout,
+		idle:    make(chan *redisConn, redisMaxIdleConns),
+	}
+	if r.key == "" {
+		r.key = DefaultRedisKey
+	}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		r.db, _ = strconv.Atoi(db)
+	}
+	if u.Scheme == "rediss" {
+		r.tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	return r, nil
+}
+
+// ParseRedisURL parses and checks a redis:// or rediss:// URL.
+func ParseRedisURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid Redis URL: scheme must be redis or rediss, got %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("invalid Redis URL: missing host")
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if n, err := strconv.Atoi(db); err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid Redis URL: database must be a non-negative number, got %q", db)
+		}
+	}
+	return u, nil
+}
+
+// Allow implements Backend.
+func (r *Redis) Allow(ctx context.Context, limit rate.Limit, burst int) (bool, int, error) {
+	return r.eval(ctx, limit, burst, true)
+}
+
+// Remaining implements Backend.
+func (r *Redis) Remaining(ctx context.Context, limit rate.Limit, burst int) (int, error) {
+	_, remaining, err := r.eval(ctx, limit, burst, false)
+	return remaining, err
+}
+
+func (r *Redis) eval(ctx context.Context, limit rate.Limit, burst int, take bool) (bool, int, error) {
+	takeArg := "0"
+	if take {
+		takeArg = "1"
+	}
+	reply, err := r.do(ctx, "EVAL", tokenBucketScript, "1", r.key,
+		strconv.FormatFloat(float64(limit), 'f', -1, 64), strconv.Itoa(burst), takeArg)
+	if err != nil {
+		return false, 0, err
+	}
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+	allowed, ok1 := values[0].(int64)
+	remaining, ok2 := values[1].(int64)
+	if !ok1 || !ok2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+	return allowed == 1, max(int(remaining), 0), nil
+}
+
+// Close implements Backend.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case conn := <-r.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends one command on a pooled connection and returns its reply. Server
+// error replies are returned as errors; the connection is reused after them
+// but dropped after any I/O or protocol error.
+func (r *Redis) do(ctx context.Context, args ...string) (any, error) {
+	conn, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(ctx, r.timeout, args...)
+	var serverErr redisError
+	if err != nil && !errors.As(err, &serverErr) {
+		conn.Close()
+		return nil, err
+	}
+	r.put(conn)
+	return reply, err
+}
+
+// get returns an idle connection or dials, authenticates and selects the
+// database on a new one.
+func (r *Redis) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-r.idle:
+		return conn, nil
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	var dialer interface {
+		DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+	} = &net.Dialer{}
+	if r.tlsConfig != nil {
+		dialer = &tls.Dialer{Config: r.tlsConfig}
+	}
+	netConn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Redis: %w", err)
+	}
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if r.password != "" {
+		args := []string{"AUTH", r.password}
+		if r.username != "" {
+			args = []string{"AUTH", r.username, r.password}
+		}
+		if _, err := conn.do(ctx, r.timeout, args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("authenticating with Redis: %w", err)
+		}
+	}
+	if r.db != 0 {
+		if _, err := conn.do(ctx, r.timeout, "SELECT", strconv.Itoa(r.db)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("selecting Redis database %d: %w", r.db, err)
+		}
+	}
+	return conn, nil
+}
+
+func (r *Redis) put(conn *redisConn) {
+	select {
+	case r.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisConn speaks RESP, the Redis protocol, over one connection.
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// do writes a command and reads its reply, giving up after timeout or when
+// ctx ends, whichever is first.
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) (any, error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// readReply reads one RESP reply: simple strings and bulk strings as string,
+// integers as int64, arrays as []any, nil replies as nil and error replies as
+// redisError.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed Redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed Redis bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed Redis array length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		values := make([]any, n)
+		for i := range values {
+			value, err := readReply(r)
+			var serverErr redisError
+			if err != nil && !errors.As(err, &serverErr) {
+				return nil, err
+			}
+			if err != nil {
+				value = serverErr
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported Redis reply type %q", kind)
+	}
+}
@@ -0,0 +1,186 @@
+package restapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// maxIdempotencyKeyLength bounds client-chosen idempotency keys; a UUID needs 36.
+const maxIdempotencyKeyLength = 255
+
+// auditChange describes what an audited handler changed. Handlers fill it in
+// through recordAuditChange.
+type auditChange struct {
+	target   string
+	previous any
+	next     any
+	// storedResponse, when set, is logged and replayed in place of the
+	// response sent; see recordAuditResponse.
+	storedResponse any
+}
+
+type auditChangeKey struct{}
+
+// recordAuditChange notes the object an audited admin handler changed and its
+// value before and after. It is a no-op outside of audited.
+func recordAuditChange(r *http.Request, target string, previous, next any) {
+	if change, ok := r.Context().Value(auditChangeKey{}).(*auditChange); ok {
+		change.target, change.previous, change.next = target, previous, next
+	}
+}
+
+// recordAuditResponse makes audited store response instead of the response
+// actually sent, for responses carrying a secret that must not be kept in the
+// audit log. An idempotent retry is answered with response. It is a no-op
+// outside of audited.
+func recordAuditResponse(r *http.Request, response any) {
+	if change, ok := r.Context().Value(auditChangeKey{}).(*auditChange); ok {
+		change.storedResponse = response
+	}
+}
+
+// audited records every successful run of an admin mutation in the audit log
+// and makes it safe to retry. A request carrying an Idempotency-Key header
+// (or idempotencyKey query parameter) whose key was already applied gets the
+// stored response again instead of repeating the change. Admin mutations are
+// rare, so they are serialized to make the check-then-apply atomic.
+func (api *RestAPI) audited(action string, handler handlerFunc) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.GtfsManager == nil || api.GtfsManager.GtfsDB == nil {
+			handler(w, r)
+			return
+		}
+		queries := api.GtfsManager.GtfsDB.Queries
+
+		idempotencyKey := r.Header.Get("Idempotency-Key")
+		if idempotencyKey == "" {
+			idempotencyKey = r.URL.Query().Get("idempotencyKey")
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			api.validationErrorResponse(w, r, map[string][]string{
+				"idempotencyKey": {"must not exceed 255 characters"},
+			})
+			return
+		}
+		// The fingerprint tells admins apart without storing their keys.
+		actor := models.APIKeyFingerprint(r.URL.Query().Get("key"))
+
+		api.auditMu.Lock()
+		defer api.auditMu.Unlock()
+
+		if idempotencyKey != "" {
+			previous, err := queries.GetAdminAuditEntryByIdempotencyKey(r.Context(), sql.NullString{String: idempotencyKey, Valid: true})
+			if err == nil {
+				if previous.Action != action || previous.Actor != actor {
+					api.sendError(w, r, http.StatusConflict, "idempotency key was already used for a different request")
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(int(previous.ResponseStatus))
+				_, _ = w.Write(previous.ResponseBody)
+				return
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				api.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+
+		change := &auditChange{}
+		rec := &coalescingRecorder{header: make(http.Header)}
+		handler(rec, r.WithContext(context.WithValue(r.Context(), auditChangeKey{}, change)))
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		if rec.status < http.StatusMultipleChoices {
+			responseBody := rec.body.Bytes()
+			if change.storedResponse != nil {
+				responseBody, _ = json.Marshal(change.storedResponse)
+			}
+			params := gtfsdb.CreateAdminAuditEntryParams{
+				Actor:          actor,
+				Action:         action,
+				Target:         change.target,
+				PreviousValue:  auditValue(change.previous),
+				NewValue:       auditValue(change.next),
+				IdempotencyKey: sql.NullString{String: idempotencyKey, Valid: idempotencyKey != ""},
+				ResponseStatus: int64(rec.status),
+				ResponseBody:   responseBody,
+				CreatedAt:      api.Clock.Now().UnixMilli(),
+			}
+			if _, err := queries.CreateAdminAuditEntry(context.WithoutCancel(r.Context()), params); err != nil {
+				// The change is already applied; losing its audit entry must not
+				// turn it into an error the client would retry.
+				api.Logger.Error("failed to record admin audit entry",
+					slog.String("action", action), slog.String("error", err.Error()))
+			}
+		}
+
+		for name, values := range rec.header {
+			w.Header()[name] = slices.Clone(values)
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+	}
+}
+
+// auditValue serializes a before or after snapshot; nil stays NULL.
+func auditValue(v any) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// adminAuditLogHandler lists applied admin mutations, newest first. since
+// (milliseconds since the epoch) skips older entries.
+func (api *RestAPI) adminAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	fieldErrors := make(map[string][]string)
+	maxCount, fieldErrors := utils.ParseMaxCount(queryParams, 100, fieldErrors)
+	var since int64
+	if v := queryParams.Get("since"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			fieldErrors["since"] = append(fieldErrors["since"], "must be milliseconds since the epoch")
+		} else {
+			since = parsed
+		}
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	rows, err := api.GtfsManager.GtfsDB.Queries.ListAdminAuditEntries(r.Context(), gtfsdb.ListAdminAuditEntriesParams{
+		Since: since,
+		Limit: int64(maxCount + 1),
+	})
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	rows, limitExceeded := utils.PaginateSlice(rows, 0, maxCount)
+
+	entries := make([]models.AuditLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, models.NewAuditLogEntry(row))
+	}
+	api.sendResponse(w, r, models.NewListResponse(entries, *models.NewEmptyReferences(), limitExceeded, api.Clock))
+}
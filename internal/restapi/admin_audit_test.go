@@ -0,0 +1,98 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/portal"
+)
+
+type auditLogResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List          []models.AuditLogEntry `json:"list"`
+		LimitExceeded bool                   `json:"limitExceeded"`
+	} `json:"data"`
+}
+
+func TestAuditedQuotaDecisionIsLoggedAndReplayed(t *testing.T) {
+	api, mailer := createPortalTestApi(t)
+	defer api.Shutdown()
+	// The test database is shared, so only look at entries from this test.
+	since := strconv.FormatInt(api.Clock.Now().UnixMilli(), 10)
+
+	key := signupAndVerify(t, api, mailer, "audit@example.org")
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/developer-quota-request.json?key="+key.Key+"&reason=signs")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	approve := "/api/where/approve-developer-quota.json?key=PROTECTED-TEST&idempotencyKey=retry-1&id=" + strconv.FormatInt(key.ID, 10)
+	resp, first := serveApiAndRetrieveEndpoint(t, api, approve)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+
+	// A retry gets the original response instead of a "no pending request" error.
+	resp, replay := serveApiAndRetrieveEndpoint(t, api, approve)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, first, replay)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api,
+		"/api/where/deny-developer-quota.json?key=PROTECTED-TEST&idempotencyKey=retry-1&id="+strconv.FormatInt(key.ID, 10))
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "a key cannot be reused for another action")
+
+	// Failed mutations change nothing and are not logged.
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/deny-developer-quota.json?key=PROTECTED-TEST&id=9999")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, log := callAPIHandler[auditLogResponse](t, api, "/api/where/admin-audit-log.json?key=PROTECTED-TEST&since="+since)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, log.Data.List, 1)
+	entry := log.Data.List[0]
+	assert.Equal(t, "approve-developer-quota", entry.Action)
+	assert.Equal(t, strconv.FormatInt(key.ID, 10), entry.Target)
+	assert.Equal(t, models.APIKeyFingerprint("PROTECTED-TEST"), entry.Actor)
+	assert.Equal(t, "retry-1", entry.IdempotencyKey)
+	assert.NotZero(t, entry.Time)
+
+	var previous, next models.DeveloperKey
+	require.NoError(t, json.Unmarshal(entry.PreviousValue, &previous))
+	require.NoError(t, json.Unmarshal(entry.NewValue, &next))
+	assert.Equal(t, portal.TierStandard, previous.Tier)
+	assert.Equal(t, "signs", previous.QuotaRequest)
+	assert.Equal(t, portal.TierElevated, next.Tier)
+	assert.Empty(t, next.Key, "snapshots never include the key")
+}
+
+func TestAdminAuditLogHandler(t *testing.T) {
+	api, _ := createPortalTestApi(t)
+	defer api.Shutdown()
+	since := strconv.FormatInt(api.Clock.Now().UnixMilli(), 10)
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/admin-audit-log.json?key=TEST")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/admin-audit-log.json?key=PROTECTED-TEST&since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	for range 3 {
+		resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/approve-static-reload.json?key=PROTECTED-TEST&hash=missing")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+	resp, log := callAPIHandler[auditLogResponse](t, api, "/api/where/admin-audit-log.json?key=PROTECTED-TEST&maxCount=2&since="+since)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, log.Data.List, "refused approvals are not logged")
+	assert.False(t, log.Data.LimitExceeded)
+}
+
+func TestAuditValue(t *testing.T) {
+	var refused *struct{ Hash string }
+
+	assert.False(t, auditValue(nil).Valid)
+	assert.False(t, auditValue(refused).Valid, "typed nil pointers stay NULL")
+	assert.Equal(t, `{"approvedHash":"abc"}`, auditValue(map[string]string{"approvedHash": "abc"}).String)
+}
@@ -2,7 +2,9 @@ package restapi
 
 import (
 	"net/http"
+	"slices"
 
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/utils"
 )
@@ -23,6 +25,10 @@ func (api *RestAPI) agenciesWithCoverageHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	agencies = slices.DeleteFunc(agencies, func(agency gtfsdb.Agency) bool {
+		return !api.inRequestRegion(ctx, agency.ID)
+	})
+
 	// Apply pagination
 	offset, limit := utils.ParsePaginationParams(r)
 	agencies, limitExceeded := utils.PaginateSlice(agencies, offset, limit)
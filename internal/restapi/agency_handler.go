@@ -3,6 +3,7 @@ package restapi
 import (
 	"net/http"
 
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
 )
 
@@ -13,7 +14,8 @@ func (api *RestAPI) agencyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	agency, err := api.GtfsManager.FindAgency(r.Context(), id)
+	ctx := r.Context()
+	agency, err := api.GtfsManager.FindAgency(ctx, id)
 	if err != nil {
 		api.serverErrorResponse(w, r, err)
 		return
@@ -23,7 +25,23 @@ func (api *RestAPI) agencyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
 	agencyData := models.AgencyReferenceFromDatabase(agency)
+	agencyData.ServiceHours, err = api.currentServiceHours(ctx, loc, func(serviceIDs []string) ([]gtfsdb.RouteServiceSpan, error) {
+		return api.GtfsManager.GtfsDB.Queries.GetRouteServiceSpansForAgency(ctx, gtfsdb.GetRouteServiceSpansForAgencyParams{
+			AgencyID:   agency.ID,
+			ServiceIds: serviceIDs,
+		})
+	})
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
 	response := models.NewEntryResponse(agencyData, *models.NewEmptyReferences(), api.Clock)
 	api.sendResponse(w, r, response)
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/restapi/testdata"
 )
@@ -43,3 +45,20 @@ func TestAgencyHandlerRequiresValidApiKey(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, model.Code)
 	assert.Equal(t, "permission denied", model.Text)
 }
+
+func TestAgencyHandlerServiceHours(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[AgencyEntryResponse](t, api, "/api/where/agency/"+testdata.Raba.ID+".json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	agencyHours := model.Data.Entry.ServiceHours
+	require.NotNil(t, agencyHours)
+
+	_, routeModel := callAPIHandler[RouteEntryResponse](t, api, routeURL(testdata.Route1.ID))
+	routeHours := routeModel.Data.Entry.ServiceHours
+	require.NotNil(t, routeHours)
+	assert.Equal(t, routeHours.ServiceDate, agencyHours.ServiceDate)
+	assert.LessOrEqual(t, agencyHours.FirstDeparture, routeHours.FirstDeparture, "the agency spans all of its routes")
+	assert.GreaterOrEqual(t, agencyHours.LastDeparture, routeHours.LastDeparture)
+}
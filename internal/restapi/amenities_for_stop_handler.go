@@ -0,0 +1,36 @@
+package restapi
+
+import (
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// amenitiesForStopHandler returns the supplementary amenities (parking, bike
+// racks, ticket machines, ...) recorded for a stop. The list is empty when the
+// stop has none or no amenities dataset is configured.
+func (api *RestAPI) amenitiesForStopHandler(w http.ResponseWriter, r *http.Request) {
+	agencyID, stopID, ok := api.extractAndValidateAgencyCodeID(w, r)
+	if !ok {
+		return
+	}
+
+	stop, err := api.GtfsManager.GtfsDB.Queries.GetStop(r.Context(), stopID)
+	if err != nil || stop.ID == "" {
+		api.sendNotFound(w, r)
+		return
+	}
+
+	amenities := api.GtfsManager.GetAmenitiesForStop(stop.ID)
+	if amenities == nil {
+		amenities = []models.Amenity{}
+	}
+
+	entry := models.AmenitiesForStop{
+		StopID:    utils.FormCombinedID(agencyID, stop.ID),
+		Amenities: amenities,
+	}
+	response := models.NewEntryResponse(entry, *models.NewEmptyReferences(), api.Clock)
+	api.sendResponse(w, r, response)
+}
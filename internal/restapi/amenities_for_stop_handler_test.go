@@ -0,0 +1,72 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// setTestAmenities configures a parking lot at Stop4062 on the shared test
+// manager and clears it when the test ends.
+func setTestAmenities(t *testing.T, api *RestAPI) models.Amenity {
+	t.Helper()
+	_, stopID, err := utils.ExtractAgencyIDAndCodeID(testdata.Stop4062.ID)
+	require.NoError(t, err)
+
+	capacity := 120
+	lot := models.Amenity{Type: "parking", Name: "Park & Ride", Capacity: &capacity}
+	api.GtfsManager.MockSetAmenities(map[string][]models.Amenity{stopID: {lot}})
+	t.Cleanup(func() { api.GtfsManager.MockSetAmenities(nil) })
+	return lot
+}
+
+func TestAmenitiesForStopHandler(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	lot := setTestAmenities(t, api)
+
+	resp, model := callAPIHandler[AmenitiesForStopEntryResponse](t, api,
+		"/api/where/amenities-for-stop/"+testdata.Stop4062.ID+".json?key=TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, testdata.Stop4062.ID, model.Data.Entry.StopID)
+	assert.Equal(t, []models.Amenity{lot}, model.Data.Entry.Amenities)
+}
+
+func TestAmenitiesForStopHandler_NoAmenities(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api,
+		"/api/where/amenities-for-stop/"+testdata.Stop4062.ID+".json?key=TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := model.Data.(map[string]any)["entry"].(map[string]any)
+	assert.Equal(t, []any{}, entry["amenities"], "an empty list rather than null")
+}
+
+func TestAmenitiesForStopHandler_UnknownStop(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api,
+		"/api/where/amenities-for-stop/"+utils.FormCombinedID(testdata.Raba.ID, "no-such-stop")+".json?key=TEST")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestStopHandler_IncludesAmenities(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	_, model := callAPIHandler[StopEntryResponse](t, api, stopURL(testdata.Stop4062.ID))
+	assert.Empty(t, model.Data.Entry.Amenities)
+
+	lot := setTestAmenities(t, api)
+	_, model = callAPIHandler[StopEntryResponse](t, api, stopURL(testdata.Stop4062.ID))
+	assert.Equal(t, []models.Amenity{lot}, model.Data.Entry.Amenities)
+}
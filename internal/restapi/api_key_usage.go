@@ -0,0 +1,183 @@
+package restapi
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/metrics"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// usageDayLayout is the format of the UTC days usage is counted in.
+const usageDayLayout = "2006-01-02"
+
+// Usage report window: the last defaultUsageDays days unless days is given,
+// which may be at most maxUsageDays.
+const (
+	defaultUsageDays = 7
+	maxUsageDays     = 366
+)
+
+// usageFlushTimeout bounds the final flush on shutdown.
+const usageFlushTimeout = 5 * time.Second
+
+type usageKey struct {
+	day, fingerprint, endpoint string
+}
+
+// UsageTracker counts requests per API key, endpoint and UTC day. Counts are
+// buffered in memory and added to the api_key_usage table by Flush, which the
+// api-key-usage-flush job runs every minute and Shutdown runs a last time.
+type UsageTracker struct {
+	queries *gtfsdb.Queries
+	clock   clock.Clock
+	metrics *metrics.Metrics
+
+	mu      sync.Mutex
+	pending map[usageKey]int64
+}
+
+// newUsageTracker creates the usage tracker, which stores its counts in the
+// GTFS database; usage is not tracked without one.
+func newUsageTracker(app *app.Application) *UsageTracker {
+	if app.GtfsManager == nil || app.GtfsManager.GtfsDB == nil {
+		return nil
+	}
+	return &UsageTracker{
+		queries: app.GtfsManager.GtfsDB.Queries,
+		clock:   app.Clock,
+		metrics: app.Metrics,
+		pending: make(map[usageKey]int64),
+	}
+}
+
+// record counts one request made with apiKey to endpoint.
+func (t *UsageTracker) record(apiKey, endpoint string) {
+	fingerprint := models.APIKeyFingerprint(apiKey)
+	key := usageKey{
+		day:         t.clock.Now().UTC().Format(usageDayLayout),
+		fingerprint: fingerprint,
+		endpoint:    endpoint,
+	}
+	t.mu.Lock()
+	t.pending[key]++
+	t.mu.Unlock()
+
+	if t.metrics != nil {
+		t.metrics.APIKeyRequestsTotal.WithLabelValues(fingerprint, endpoint).Inc()
+	}
+}
+
+// Flush adds the buffered counts to the database. Counts that could not be
+// saved stay buffered for the next flush.
+func (t *UsageTracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[usageKey]int64)
+	t.mu.Unlock()
+
+	for key, requests := range pending {
+		err := t.queries.AddAPIKeyUsage(ctx, gtfsdb.AddAPIKeyUsageParams{
+			Day:            key.day,
+			KeyFingerprint: key.fingerprint,
+			Endpoint:       key.endpoint,
+			Requests:       requests,
+		})
+		if err != nil {
+			t.mu.Lock()
+			for key, requests := range pending {
+				t.pending[key] += requests
+			}
+			t.mu.Unlock()
+			return err
+		}
+		delete(pending, key)
+	}
+	return nil
+}
+
+// Report returns the usage on days since (YYYY-MM-DD) and later, newest day
+// first, including counts not flushed yet. An empty fingerprint reports every
+// key.
+func (t *UsageTracker) Report(ctx context.Context, since, fingerprint string) ([]models.APIKeyUsage, error) {
+	rows, err := t.queries.ListAPIKeyUsage(ctx, gtfsdb.ListAPIKeyUsageParams{
+		Since:          since,
+		KeyFingerprint: fingerprint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[usageKey]int64, len(rows))
+	for _, row := range rows {
+		counts[usageKey{day: row.Day, fingerprint: row.KeyFingerprint, endpoint: row.Endpoint}] = row.Requests
+	}
+	t.mu.Lock()
+	for key, requests := range t.pending {
+		if key.day >= since && (fingerprint == "" || key.fingerprint == fingerprint) {
+			counts[key] += requests
+		}
+	}
+	t.mu.Unlock()
+
+	usage := make([]models.APIKeyUsage, 0, len(counts))
+	for key, requests := range counts {
+		usage = append(usage, models.APIKeyUsage{
+			Day:            key.day,
+			KeyFingerprint: key.fingerprint,
+			Endpoint:       key.endpoint,
+			Requests:       requests,
+		})
+	}
+	slices.SortFunc(usage, func(a, b models.APIKeyUsage) int {
+		return cmp.Or(
+			cmp.Compare(b.Day, a.Day),
+			cmp.Compare(a.KeyFingerprint, b.KeyFingerprint),
+			cmp.Compare(a.Endpoint, b.Endpoint),
+		)
+	})
+	return usage, nil
+}
+
+// recordUsage counts a request whose API key has been accepted. r.Pattern
+// is set by the mux before the handler chain runs.
+func (api *RestAPI) recordUsage(r *http.Request) {
+	if api.usage == nil || r.Pattern == "" {
+		return
+	}
+	api.usage.record(r.URL.Query().Get("key"), endpointFromPattern(r.Pattern))
+}
+
+// apiKeyUsageHandler reports requests per API key, endpoint and UTC day over
+// the last days days (7 by default), newest day first. fingerprint limits the
+// report to one key.
+func (api *RestAPI) apiKeyUsageHandler(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	days := defaultUsageDays
+	if v := queryParams.Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxUsageDays {
+			api.validationErrorResponse(w, r, map[string][]string{
+				"days": {"must be a whole number of days between 1 and " + strconv.Itoa(maxUsageDays)},
+			})
+			return
+		}
+		days = parsed
+	}
+
+	since := api.Clock.Now().UTC().AddDate(0, 0, 1-days).Format(usageDayLayout)
+	usage, err := api.usage.Report(r.Context(), since, queryParams.Get("fingerprint"))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	api.sendResponse(w, r, models.NewListResponse(usage, *models.NewEmptyReferences(), false, api.Clock))
+}
@@ -0,0 +1,66 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type apiKeyUsageResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List []models.APIKeyUsage `json:"list"`
+	} `json:"data"`
+}
+
+func TestAPIKeyUsageCountsRequestsPerKeyAndEndpoint(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	resp, created := callAPIHandler[apiKeyResponse](t, api,
+		"/api/where/create-api-key.json?key=PROTECTED-TEST&rateLimit=10&email="+url.QueryEscape("usage@example.org"))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	key := created.Data.Entry
+	assert.Equal(t, models.APIKeyFingerprint(key.Key), key.Fingerprint)
+
+	for range 3 {
+		resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key="+key.Key)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/agencies-with-coverage.json?key="+key.Key)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	today := api.Clock.Now().UTC().Format(usageDayLayout)
+	want := []models.APIKeyUsage{
+		{Day: today, KeyFingerprint: key.Fingerprint, Endpoint: "agencies-with-coverage", Requests: 1},
+		{Day: today, KeyFingerprint: key.Fingerprint, Endpoint: "current-time", Requests: 3},
+	}
+	endpoint := "/api/where/api-key-usage.json?key=PROTECTED-TEST&fingerprint=" + key.Fingerprint
+
+	resp, usage := callAPIHandler[apiKeyUsageResponse](t, api, endpoint)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, want, usage.Data.List, "unflushed counts are reported")
+
+	require.NoError(t, api.usage.Flush(context.Background()))
+	resp, usage = callAPIHandler[apiKeyUsageResponse](t, api, endpoint)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, want, usage.Data.List, "flushed counts are reported once")
+}
+
+func TestAPIKeyUsageRequiresProtectedKeyAndValidDays(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/api-key-usage.json?key=TEST")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	for _, days := range []string{"0", "367", "week"} {
+		resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/api-key-usage.json?key=PROTECTED-TEST&days="+days)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "days=%s", days)
+	}
+}
@@ -0,0 +1,133 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/time/rate"
+	"maglev.onebusaway.org/internal/apikeys"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/portal"
+)
+
+// allowIssuedKey admits a request whose key is not one of the configured
+// ApiKeys when an admin or the developer portal issued it and the key's own
+// rate limit allows the request. Otherwise it writes the error response and
+// returns false.
+func (api *RestAPI) allowIssuedKey(w http.ResponseWriter, r *http.Request) bool {
+	limiter, err := api.issuedKeyLimiter(r.Context(), r.URL.Query().Get("key"))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return false
+	}
+	if limiter == nil {
+		api.invalidAPIKeyResponse(w)
+		return false
+	}
+	if !limiter.Allow() {
+		sendRateLimitExceeded(w, limiter.Limit(), limiter.Burst())
+		return false
+	}
+	return true
+}
+
+// issuedKeyLimiter returns the per-key limiter of a key issued by an admin
+// or the developer portal, or nil for any other key.
+func (api *RestAPI) issuedKeyLimiter(ctx context.Context, apiKey string) (*rate.Limiter, error) {
+	if api.keyStore != nil {
+		limiter, err := api.keyStore.Limiter(ctx, apiKey)
+		if !errors.Is(err, apikeys.ErrUnknownKey) {
+			return limiter, err
+		}
+	}
+	if api.portal != nil {
+		limiter, err := api.portal.Limiter(ctx, apiKey)
+		if !errors.Is(err, portal.ErrUnknownKey) {
+			return limiter, err
+		}
+	}
+	return nil, nil
+}
+
+// apiKeysHandler lists the keys issued by an admin, revoked ones included.
+// The keys themselves are never shown.
+func (api *RestAPI) apiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := api.keyStore.List(r.Context())
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	api.sendResponse(w, r, models.NewListResponse(keys, *models.NewEmptyReferences(), false, api.Clock))
+}
+
+// createAPIKeyHandler issues a new key for the given contact email. rateLimit
+// defaults to the server's rate limit. The response is the only place the
+// key is shown.
+func (api *RestAPI) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rateLimit := api.CurrentConfig().RateLimit
+	if value := query.Get("rateLimit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			api.validationErrorResponse(w, r, map[string][]string{"rateLimit": {apikeys.ErrInvalidRateLimit.Error()}})
+			return
+		}
+		rateLimit = parsed
+	}
+
+	key, err := api.keyStore.Create(r.Context(), query.Get("email"), query.Get("description"), rateLimit)
+	switch {
+	case errors.Is(err, apikeys.ErrInvalidEmail):
+		api.validationErrorResponse(w, r, map[string][]string{"email": {err.Error()}})
+		return
+	case errors.Is(err, apikeys.ErrInvalidRateLimit):
+		api.validationErrorResponse(w, r, map[string][]string{"rateLimit": {err.Error()}})
+		return
+	case err != nil:
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Neither the audit log nor an idempotent retry gets the key itself.
+	redacted := key
+	redacted.Key = ""
+	recordAuditChange(r, strconv.FormatInt(key.ID, 10), nil, redacted)
+	recordAuditResponse(r, models.NewEntryResponse(redacted, *models.NewEmptyReferences(), api.Clock))
+	api.sendResponse(w, r, models.NewEntryResponse(key, *models.NewEmptyReferences(), api.Clock))
+}
+
+// revokeAPIKeyHandler revokes the key given by id. Requests using it are
+// rejected from then on.
+func (api *RestAPI) revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		api.validationErrorResponse(w, r, map[string][]string{"id": {"id must be a positive integer"}})
+		return
+	}
+	previous, err := api.keyStore.Key(r.Context(), id)
+	if errors.Is(err, apikeys.ErrNotFound) {
+		api.sendError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	key, err := api.keyStore.Revoke(r.Context(), id)
+	switch {
+	case errors.Is(err, apikeys.ErrNotFound):
+		api.sendError(w, r, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, apikeys.ErrAlreadyRevoked):
+		api.sendError(w, r, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	recordAuditChange(r, strconv.FormatInt(id, 10), previous, key)
+	api.sendResponse(w, r, models.NewEntryResponse(key, *models.NewEmptyReferences(), api.Clock))
+}
@@ -0,0 +1,152 @@
+package restapi
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type apiKeyResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		Entry models.APIKey `json:"entry"`
+	} `json:"data"`
+}
+
+type apiKeyListResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List []models.APIKey `json:"list"`
+	} `json:"data"`
+}
+
+// createKeyTestApi returns a test API whose shared rate limit does not get in
+// the way of the per-key limits under test.
+func createKeyTestApi(t *testing.T) *RestAPI {
+	t.Helper()
+	api := createTestApi(t)
+	api.rateLimiter = NewRateLimitMiddleware(100, time.Second, nil)
+	return api
+}
+
+func TestCreateAPIKeyIssuesRateLimitedKey(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	resp, created := callAPIHandler[apiKeyResponse](t, api,
+		"/api/where/create-api-key.json?key=PROTECTED-TEST&rateLimit=2&email="+url.QueryEscape("Ops@Example.org")+"&description=signs")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	key := created.Data.Entry
+	require.NotEmpty(t, key.Key)
+	assert.Equal(t, "ops@example.org", key.ContactEmail)
+	assert.Equal(t, "signs", key.Description)
+	assert.Equal(t, 2, key.RateLimit)
+	assert.NotZero(t, key.CreatedAt)
+	assert.Zero(t, key.RevokedAt)
+
+	endpoint := "/api/where/current-time.json?key=" + key.Key
+	for range 2 {
+		resp, _ := serveApiAndRetrieveEndpoint(t, api, endpoint)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, endpoint)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "stored keys have their own per-key limit")
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key=TEST")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "configured keys keep working as bootstrap keys")
+}
+
+func TestCreateAPIKeyDefaultsToServerRateLimit(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	resp, created := callAPIHandler[apiKeyResponse](t, api,
+		"/api/where/create-api-key.json?key=PROTECTED-TEST&email=default@example.org")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, api.CurrentConfig().RateLimit, created.Data.Entry.RateLimit)
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	for _, query := range []string{"email=not-an-email", "email=ok@example.org&rateLimit=0", "email=ok@example.org&rateLimit=abc"} {
+		resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/create-api-key.json?key=PROTECTED-TEST&"+query)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/create-api-key.json?key=TEST&email=ok@example.org")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "creating keys needs a protected key")
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	resp, created := callAPIHandler[apiKeyResponse](t, api,
+		"/api/where/create-api-key.json?key=PROTECTED-TEST&email=revoke@example.org")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	key := created.Data.Entry
+	id := strconv.FormatInt(key.ID, 10)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key="+key.Key)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, revoked := callAPIHandler[apiKeyResponse](t, api, "/api/where/revoke-api-key.json?key=PROTECTED-TEST&id="+id)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotZero(t, revoked.Data.Entry.RevokedAt)
+	assert.Empty(t, revoked.Data.Entry.Key)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key="+key.Key)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "a revoked key is rejected at once")
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/revoke-api-key.json?key=PROTECTED-TEST&id="+id)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/revoke-api-key.json?key=PROTECTED-TEST&id=999999")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/revoke-api-key.json?key=PROTECTED-TEST&id=abc")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, list := callAPIHandler[apiKeyListResponse](t, api, "/api/where/api-keys.json?key=PROTECTED-TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var listed *models.APIKey
+	for i := range list.Data.List {
+		assert.Empty(t, list.Data.List[i].Key, "listed keys never include the key")
+		if list.Data.List[i].ID == key.ID {
+			listed = &list.Data.List[i]
+		}
+	}
+	require.NotNil(t, listed, "revoked keys stay listed")
+	assert.NotZero(t, listed.RevokedAt)
+}
+
+func TestCreateAPIKeyAuditKeepsKeySecret(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+	// The test database is shared, so only look at entries from this test.
+	since := strconv.FormatInt(api.Clock.Now().UnixMilli(), 10)
+
+	create := "/api/where/create-api-key.json?key=PROTECTED-TEST&idempotencyKey=create-key-1&email=audit-key@example.org"
+	resp, created := callAPIHandler[apiKeyResponse](t, api, create)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, created.Data.Entry.Key)
+
+	// A retry learns which key was created but not the key itself.
+	resp, replay := callAPIHandler[apiKeyResponse](t, api, create)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, created.Data.Entry.ID, replay.Data.Entry.ID)
+	assert.Empty(t, replay.Data.Entry.Key)
+
+	resp, log := callAPIHandler[auditLogResponse](t, api, "/api/where/admin-audit-log.json?key=PROTECTED-TEST&since="+since)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, log.Data.List, 1)
+	assert.Equal(t, "create-api-key", log.Data.List[0].Action)
+	assert.NotContains(t, string(log.Data.List[0].NewValue), created.Data.Entry.Key)
+}
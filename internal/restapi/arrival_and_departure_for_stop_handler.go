@@ -9,126 +9,43 @@ import (
 	"time"
 
 	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
 	"maglev.onebusaway.org/gtfsdb"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
 )
 
 type ArrivalAndDepartureParams struct {
-	MinutesAfter  int
-	MinutesBefore int
-	Time          *time.Time
-	TripID        string
-	ServiceDate   *time.Time
-	VehicleID     string
-	StopSequence  *int
+	MinutesAfter  int        `query:"minutesAfter,min=0,max=240,clamp"`
+	MinutesBefore int        `query:"minutesBefore,min=0,max=60,clamp"`
+	Time          *time.Time `query:"time"`
+	TripID        string     `query:"tripId"`
+	ServiceDate   *time.Time `query:"serviceDate"`
+	VehicleID     string     `query:"vehicleId"`
+	StopSequence  *int       `query:"stopSequence"`
 }
 
 // parseArrivalAndDepartureParams parses and validates request parameters.
 // Returns parameters and a map of validation errors if any.
+//
+// If a timezone location is provided, serviceDate and time are returned in
+// it so that callers receive times in the agency's timezone by default and
+// Year()/Month()/Day()/Format() calls extract the agency's calendar date.
 func parseArrivalAndDepartureParams(r *http.Request, loc ...*time.Location) (ArrivalAndDepartureParams, map[string][]string) {
 	params := ArrivalAndDepartureParams{
 		MinutesAfter:  30, // Default 30 minutes after
 		MinutesBefore: 5,  // Default 5 minutes before
 	}
 
-	// Initialize errors map
-	fieldErrors := make(map[string][]string)
-
-	const maxMinutesAfter = 240
-	const maxMinutesBefore = 60
-
-	// Validate minutesAfter
-	if minutesAfterStr := r.URL.Query().Get("minutesAfter"); minutesAfterStr != "" {
-		if minutesAfter, err := strconv.Atoi(minutesAfterStr); err == nil {
-			if minutesAfter < 0 {
-				fieldErrors["minutesAfter"] = []string{"must be a non-negative integer"}
-			} else if minutesAfter > maxMinutesAfter {
-				params.MinutesAfter = maxMinutesAfter
-			} else {
-				params.MinutesAfter = minutesAfter
-			}
-		} else {
-			fieldErrors["minutesAfter"] = []string{"must be a valid integer"}
-		}
-	}
-
-	// Validate minutesBefore
-	if minutesBeforeStr := r.URL.Query().Get("minutesBefore"); minutesBeforeStr != "" {
-		if minutesBefore, err := strconv.Atoi(minutesBeforeStr); err == nil {
-			if minutesBefore < 0 {
-				fieldErrors["minutesBefore"] = []string{"must be a non-negative integer"}
-			} else if minutesBefore > maxMinutesBefore {
-				params.MinutesBefore = maxMinutesBefore
-			} else {
-				params.MinutesBefore = minutesBefore
-			}
-		} else {
-			fieldErrors["minutesBefore"] = []string{"must be a valid integer"}
-		}
-	}
-
-	// Validate time
-	if timeStr := r.URL.Query().Get("time"); timeStr != "" {
-		if timeMs, err := strconv.ParseInt(timeStr, 10, 64); err == nil {
-			timeParam := time.Unix(timeMs/1000, 0)
-			params.Time = &timeParam
-		} else {
-			fieldErrors["time"] = []string{"must be a valid Unix timestamp in milliseconds"}
-		}
-	}
-
-	// Check TripID (Assignment only, required check is in handler)
-	if tripIDStr := r.URL.Query().Get("tripId"); tripIDStr != "" {
-		params.TripID = tripIDStr
-	}
-
-	// Validate serviceDate
-	if serviceDateStr := r.URL.Query().Get("serviceDate"); serviceDateStr != "" {
-		if serviceDateMs, err := strconv.ParseInt(serviceDateStr, 10, 64); err == nil {
-			serviceDate := time.Unix(serviceDateMs/1000, 0)
-			params.ServiceDate = &serviceDate
-		} else {
-			fieldErrors["serviceDate"] = []string{"must be a valid Unix timestamp in milliseconds"}
-		}
+	var location *time.Location
+	if len(loc) > 0 {
+		location = loc[0]
 	}
-
-	// Optional vehicleId parameter
-	if vehicleIDStr := r.URL.Query().Get("vehicleId"); vehicleIDStr != "" {
-		params.VehicleID = vehicleIDStr
-	}
-
-	// Validate stopSequence
-	if stopSequenceStr := r.URL.Query().Get("stopSequence"); stopSequenceStr != "" {
-		if stopSequence, err := strconv.Atoi(stopSequenceStr); err == nil {
-			params.StopSequence = &stopSequence
-		} else {
-			fieldErrors["stopSequence"] = []string{"must be a valid integer"}
-		}
-	}
-
-	// Return errors if any existed
-	if len(fieldErrors) > 0 {
+	if fieldErrors := bindQuery(r.URL.Query(), &params, location); fieldErrors != nil {
 		return params, fieldErrors
 	}
-
-	// If a timezone location was provided, localize serviceDate and time so that
-	// callers receive times in the agency's timezone by default. This prevents the
-	// bug where time.Unix(ms/1000, 0) creates a UTC time and downstream
-	// Year()/Month()/Day()/Format() calls extract the wrong calendar date for
-	// agencies in positive UTC offsets.
-	if len(loc) > 0 && loc[0] != nil {
-		if params.ServiceDate != nil {
-			localized := params.ServiceDate.In(loc[0])
-			params.ServiceDate = &localized
-		}
-		if params.Time != nil {
-			localized := params.Time.In(loc[0])
-			params.Time = &localized
-		}
-	}
-
 	return params, nil
 }
 
@@ -295,14 +212,15 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 		tripStatus        *models.TripStatus
 		distanceFromStop  float64
 		numberOfStopsAway int
+		occupancyStatus   string
 	)
 
 	// If vehicleId is provided, validate it matches the trip
 	var vehicle *gtfs.Vehicle
 	if params.VehicleID != "" {
-		_, providedVehicleID, err := utils.ExtractAgencyIDAndCodeID(params.VehicleID)
+		vehicleAgencyID, providedVehicleID, err := utils.ExtractAgencyIDAndCodeID(params.VehicleID)
 		if err == nil {
-			v, err := api.GtfsManager.GetVehicleByID(providedVehicleID)
+			v, err := api.GtfsManager.GetVehicleForAgency(vehicleAgencyID, providedVehicleID)
 			// If vehicle is found, validate it matches the trip
 			if err == nil && v != nil && v.Trip != nil && v.Trip.ID.ID == tripID {
 				vehicle = v
@@ -324,9 +242,14 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 			api.Logger.Warn("vehicle with nil ID descriptor found for trip", "tripID", tripID)
 		}
 		predicted = true
+		occupancyStatus = internalgtfs.VehicleOccupancyStatus(vehicle)
 	}
 
 	status, statusErr := api.BuildTripStatus(ctx, route.AgencyID, tripID, nil, serviceDate, currentTime)
+	if ctx.Err() != nil {
+		api.clientCanceledResponse(w, r, ctx.Err())
+		return
+	}
 	if statusErr != nil {
 		api.Logger.Warn("BuildTripStatus failed",
 			"tripID", tripID, "error", statusErr)
@@ -341,7 +264,7 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 
 		// getPredictedTimes now returns 3 values (arr, dep, isPredicted)
 		// and includes trip-level Delay fallback for consistency with the plural handler
-		predictedArrival, predictedDeparture, isPredicted := api.getPredictedTimes(tripID, stopCode, targetStopTime.StopSequence, scheduledArrivalTime, scheduledDepartureTime)
+		predictedArrival, predictedDeparture, isPredicted := api.getPredictedTimes(ctx, tripID, stopCode, targetStopTime.StopSequence, scheduledArrivalTime, scheduledDepartureTime)
 
 		if isPredicted {
 			predictedArrivalTime = predictedArrival
@@ -363,12 +286,22 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 		}
 	}
 
+	// Feeds report the occupancy of the whole vehicle, so its latest reading
+	// is the prediction for each stop it has yet to serve.
+	predictedOccupancy := ""
+	if predicted && predictedDepartureTime.After(currentTime) {
+		predictedOccupancy = occupancyStatus
+	}
+	historicalOccupancy := api.historicalOccupancy(ctx, tripID, int64(targetStopTime.StopSequence))
+
 	totalStopsInTrip := int(targetRow.TotalStops)
 
 	blockTripSequence := api.calculateBlockTripSequence(ctx, tripID, serviceDate)
 
 	lastUpdateTime := api.GtfsManager.GetVehicleLastUpdateTime(vehicle)
-	situationIDs := api.GetSituationIDsForTrip(r.Context(), tripID)
+	situations := newSituationSet(requestLanguage(r))
+	situationIDs := situations.addTrip(api.GtfsManager.GetTripAlerts(r.Context(), tripID))
+	arrivalEnabled, departureEnabled := stopTimeEnabled(targetRow.PickupType, targetRow.DropOffType)
 
 	arrival := models.NewArrivalAndDeparture(
 		utils.FormCombinedID(route.AgencyID, route.ID), // routeID
@@ -385,21 +318,23 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 		predictedDepartureTime,                         // predictedDepartureTime
 		lastUpdateTime,                                 // lastUpdateTime
 		predicted,                                      // predicted
-		true,                                           // arrivalEnabled
-		true,                                           // departureEnabled
+		arrivalEnabled,                                 // arrivalEnabled
+		departureEnabled,                               // departureEnabled
 		int(targetStopTime.StopSequence)-1,             // stopSequence (Zero-based index)
 		totalStopsInTrip,                               // totalStopsInTrip
 		numberOfStopsAway,                              // numberOfStopsAway
 		blockTripSequence,                              // blockTripSequence
 		distanceFromStop,                               // distanceFromStop
 		"default",                                      // status
-		"",                                             // occupancyStatus
-		"",                                             // predictedOccupancy
-		"",                                             // historicalOccupancy
+		occupancyStatus,                                // occupancyStatus
+		predictedOccupancy,                             // predictedOccupancy
+		historicalOccupancy,                            // historicalOccupancy
 		tripStatus,                                     // tripStatus
 		situationIDs,                                   // situationIds
 	)
 
+	arrival.FlagStop = continuousStopping(route)
+
 	references := models.NewEmptyReferences()
 
 	// Add Stop Agency Reference
@@ -519,17 +454,7 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 		combinedRouteIDs := make([]string, len(routesForThisStop))
 		for i, route := range routesForThisStop {
 			combinedRouteIDs[i] = utils.FormCombinedID(route.AgencyID, route.ID)
-			routeCopy := gtfsdb.Route{
-				ID:        route.ID,
-				AgencyID:  route.AgencyID,
-				ShortName: route.ShortName,
-				LongName:  route.LongName,
-				Desc:      route.Desc,
-				Type:      route.Type,
-				Url:       route.Url,
-				Color:     route.Color,
-				TextColor: route.TextColor,
-			}
+			routeCopy := routeFromStopsRow(route)
 			routeIDSet[route.ID] = &routeCopy
 		}
 
@@ -552,28 +477,14 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 	routeRefs := make(map[string]models.Route, len(routeIDSet))
 	for _, route := range routeIDSet {
 		combinedID := utils.FormCombinedID(route.AgencyID, route.ID)
-		routeRefs[combinedID] = models.NewRoute(
-			combinedID,
-			route.AgencyID,
-			route.ShortName.String,
-			route.LongName.String,
-			route.Desc.String,
-			models.RouteType(route.Type),
-			route.Url.String,
-			route.Color.String,
-			route.TextColor.String)
+		routeRefs[combinedID] = utils.RouteFromDatabase(route.AgencyID, *route)
 	}
 	references.Routes = utils.MapValues(routeRefs)
 
-	if len(situationIDs) > 0 {
-		alerts := api.GtfsManager.GetAlertsForTrip(r.Context(), tripID)
-		if len(alerts) > 0 {
-			situations := api.BuildSituationReferences(alerts)
-			references.Situations = append(references.Situations, situations...)
-		}
-	}
+	references.Situations = append(references.Situations, situations.references()...)
 
 	response := models.NewEntryResponse(arrival, *references, api.Clock)
+	w.Header().Set("ETag", arrivalsETag(*arrival))
 	api.sendResponse(w, r, response)
 }
 
@@ -583,9 +494,16 @@ func (api *RestAPI) arrivalAndDepartureForStopHandler(w http.ResponseWriter, r *
 //  2. Propagated delay — uses delay from the closest prior stop in the trip
 //  3. Trip-level delay — falls back to TripUpdate.Delay when no per-stop data exists
 //
+// Propagation works from trip updates alone, without vehicle positions. Prior
+// updates that carry only a stop_id are placed by the trip's static stop times,
+// and updates that give absolute times instead of delays are measured against
+// the stop's scheduled time. SKIPPED stops pass the delay before them through;
+// a NO_DATA stop ends propagation, so later stops fall back to the schedule.
+//
 // Returns (predictedArrivalMs, predictedDepartureMs, isPredicted).
 // Returns (time.Time{}, time.Time{}, false) if no prediction can be made.
 func (api *RestAPI) getPredictedTimes(
+	ctx context.Context,
 	tripID string,
 	stopCode string,
 	targetStopSequence int64,
@@ -597,10 +515,15 @@ func (api *RestAPI) getPredictedTimes(
 		return time.Time{}, time.Time{}, false
 	}
 
+	// The static stop times are only loaded when an update needs them.
+	schedule := tripSchedule{ctx: ctx, api: api, tripID: tripID}
+
 	var arrivalOffset, departureOffset *time.Duration
 	var propagatedDelay time.Duration
 	var closestPriorSequence int64 = -1
+	var propagationStopped bool
 	var foundTarget bool
+	lastSequence := int64(-1)
 
 	for _, stu := range realTimeTrip.StopTimeUpdates {
 		seq := int64(-1)
@@ -631,15 +554,36 @@ func (api *RestAPI) getPredictedTimes(
 			break
 		}
 
-		if seq != -1 && seq < targetStopSequence && seq > closestPriorSequence {
+		if seq == -1 && stu.StopID != nil {
+			seq = schedule.sequenceAfter(*stu.StopID, lastSequence)
+		}
+		if seq == -1 {
+			continue
+		}
+		lastSequence = seq
+
+		if seq >= targetStopSequence || seq <= closestPriorSequence {
+			continue
+		}
+		switch stu.ScheduleRelationship {
+		case gtfsrt.TripUpdate_StopTimeUpdate_SKIPPED:
+			continue
+		case gtfsrt.TripUpdate_StopTimeUpdate_NO_DATA:
 			closestPriorSequence = seq
-			propagatedDelay = 0
-			if stu.Departure != nil && stu.Departure.Delay != nil {
-				propagatedDelay = *stu.Departure.Delay
-			} else if stu.Arrival != nil && stu.Arrival.Delay != nil {
-				propagatedDelay = *stu.Arrival.Delay
-			}
+			propagationStopped = true
+			continue
+		}
+		delay, ok := schedule.updateDelay(stu, seq, targetStopSequence, scheduledArrivalTime)
+		if !ok {
+			continue
 		}
+		closestPriorSequence = seq
+		propagatedDelay = delay
+		propagationStopped = false
+	}
+
+	if !foundTarget && propagationStopped {
+		return time.Time{}, time.Time{}, false
 	}
 
 	// CHANGED: Restructured fallback chain to include trip-level Delay (Tier 3)
@@ -2,6 +2,7 @@ package restapi
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -325,7 +326,7 @@ func TestGetPredictedTimes_NoRealTimeData(t *testing.T) {
 	scheduledArrival := time.Now()
 	scheduledDeparture := scheduledArrival.Add(2 * time.Minute)
 
-	predArrival, predDeparture, predicted := api.getPredictedTimes("nonexistent_trip", "nonexistent_stop", 1, scheduledArrival, scheduledDeparture)
+	predArrival, predDeparture, predicted := api.getPredictedTimes(t.Context(), "nonexistent_trip", "nonexistent_stop", 1, scheduledArrival, scheduledDeparture)
 
 	assert.True(t, predArrival.IsZero())
 	assert.True(t, predDeparture.IsZero())
@@ -338,7 +339,7 @@ func TestGetPredictedTimes_EqualArrivalDeparture(t *testing.T) {
 
 	scheduledTime := time.Now()
 
-	predArrival, predDeparture, predicted := api.getPredictedTimes("test_trip", "test_stop", 1, scheduledTime, scheduledTime)
+	predArrival, predDeparture, predicted := api.getPredictedTimes(t.Context(), "test_trip", "test_stop", 1, scheduledTime, scheduledTime)
 
 	assert.True(t, predArrival.IsZero())
 	assert.True(t, predDeparture.IsZero())
@@ -608,7 +609,7 @@ func TestGetPredictedTimes_DelayPropagationLogic(t *testing.T) {
 	api.GtfsManager.SetRealTimeTripsForTest([]gtfs.Trip{mockTrip})
 
 	scheduledTime := time.Now()
-	predArrival, predDeparture, predicted := api.getPredictedTimes(tripID, "test_stop", targetStopSequence, scheduledTime, scheduledTime)
+	predArrival, predDeparture, predicted := api.getPredictedTimes(t.Context(), tripID, "test_stop", targetStopSequence, scheduledTime, scheduledTime)
 
 	expectedTime := scheduledTime.Add(delayDuration)
 	assert.Equal(t, expectedTime, predArrival, "Arrival time should include 120s delay")
@@ -634,7 +635,7 @@ func TestGetPredictedTimes_TripLevelDelayFallback(t *testing.T) {
 	api.GtfsManager.SetRealTimeTripsForTest([]gtfs.Trip{mockTrip})
 
 	scheduledTime := time.Now()
-	predArrival, predDeparture, predicted := api.getPredictedTimes(tripID, "test_stop", targetStopSequence, scheduledTime, scheduledTime)
+	predArrival, predDeparture, predicted := api.getPredictedTimes(t.Context(), tripID, "test_stop", targetStopSequence, scheduledTime, scheduledTime)
 
 	expectedTime := scheduledTime.Add(delayDuration)
 	assert.True(t, predicted, "Should be predicted when trip-level delay is available")
@@ -844,3 +845,55 @@ func TestArrivalAndDepartureForStop_VehicleWithNilID(t *testing.T) {
 	assert.Equal(t, 200, model.Code)
 	assert.Equal(t, "", model.Data.Entry.VehicleID, "vehicleId should be empty for vehicle with nil ID")
 }
+
+func TestStopTimeEnabled(t *testing.T) {
+	tests := []struct {
+		name                       string
+		pickupType, dropOffType    sql.NullInt64
+		wantArrival, wantDeparture bool
+	}{
+		{"missing values are regular service", sql.NullInt64{}, sql.NullInt64{}, true, true},
+		{"regular service", nulls.Int64(0), nulls.Int64(0), true, true},
+		{"no pickup", nulls.Int64(1), nulls.Int64(0), true, false},
+		{"no drop-off", nulls.Int64(0), nulls.Int64(1), false, true},
+		{"arranged by phone or with the driver", nulls.Int64(2), nulls.Int64(3), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrival, departure := stopTimeEnabled(tt.pickupType, tt.dropOffType)
+			assert.Equal(t, tt.wantArrival, arrival)
+			assert.Equal(t, tt.wantDeparture, departure)
+		})
+	}
+}
+
+func TestArrivalAndDepartureForStopHandler_NoPickup(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	agency := mustGetAgencies(t, api)[0]
+	ctx := context.Background()
+
+	trips, err := api.GtfsManager.GetTrips(ctx, 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, trips)
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trips[0].ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, stopTimes)
+	stopTime := stopTimes[len(stopTimes)-1]
+
+	_, err = api.GtfsManager.GtfsDB.DB.ExecContext(ctx,
+		`UPDATE stop_times SET pickup_type = 1 WHERE trip_id = ? AND stop_sequence = ?`, stopTime.TripID, stopTime.StopSequence)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(),
+			`UPDATE stop_times SET pickup_type = ? WHERE trip_id = ? AND stop_sequence = ?`, stopTime.PickupType, stopTime.TripID, stopTime.StopSequence)
+	})
+
+	endpoint := fmt.Sprintf("/api/where/arrival-and-departure-for-stop/%s.json?key=TEST&tripId=%s&serviceDate=%d&stopSequence=%d",
+		utils.FormCombinedID(agency.ID, stopTime.StopID), utils.FormCombinedID(agency.ID, stopTime.TripID), time.Now().UnixMilli(), stopTime.StopSequence)
+	resp, model := callAPIHandler[ArrivalAndDepartureResponse](t, api, endpoint)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, model.Data.Entry.ArrivalEnabled)
+	assert.False(t, model.Data.Entry.DepartureEnabled, "riders cannot board where pickup_type is 1")
+}
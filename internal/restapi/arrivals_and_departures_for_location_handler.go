@@ -0,0 +1,128 @@
+package restapi
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// defaultMaxStopsForArrivals is how many of the closest stops contribute
+// arrivals when maxCount is not given. Each stop costs a full per-stop
+// arrivals lookup, so the default stays small.
+const defaultMaxStopsForArrivals = 10
+
+// arrivalsAndDeparturesForLocationHandler returns the combined arrivals of the
+// stops closest to a location, saving map clients a request per stop.
+// maxCount limits how many stops are included.
+func (api *RestAPI) arrivalsAndDeparturesForLocationHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors map[string][]string
+	loc, fieldErrors := api.parseLocationParams(r, fieldErrors)
+	maxStops, fieldErrors := utils.ParseMaxCount(r.URL.Query(), defaultMaxStopsForArrivals, fieldErrors)
+
+	params, paramErrors := api.parseArrivalsAndDeparturesParams(r)
+	for field, errs := range paramErrors {
+		fieldErrors[field] = append(fieldErrors[field], errs...)
+	}
+	if params.Format == arrivalsFormatSpeech {
+		fieldErrors["format"] = append(fieldErrors["format"], "speech is only supported for a single stop")
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+
+	stops, _ := api.GtfsManager.GetStopsForLocation(ctx, loc, "", models.DefaultMaxCountForStops, nil)
+	slices.SortStableFunc(stops, func(a, b gtfsdb.Stop) int {
+		return cmp.Compare(utils.Distance(loc.Lat, loc.Lon, a.Lat, a.Lon), utils.Distance(loc.Lat, loc.Lon, b.Lat, b.Lon))
+	})
+	limitExceeded := len(stops) > maxStops
+	if limitExceeded {
+		stops = stops[:maxStops]
+	}
+
+	stopIDs := make([]string, len(stops))
+	for i, stop := range stops {
+		stopIDs[i] = stop.ID
+	}
+	agencyRows, err := api.GtfsManager.GtfsDB.Queries.GetAgenciesForStops(ctx, stopIDs)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	// A stop served by multiple agencies uses the first one found, matching
+	// stops-for-location.
+	stopAgency := make(map[string]gtfsdb.Agency, len(agencyRows))
+	for _, row := range agencyRows {
+		if _, exists := stopAgency[row.StopID]; !exists {
+			stopAgency[row.StopID] = gtfsdb.Agency{
+				ID:       row.ID,
+				Name:     row.Name,
+				Url:      row.Url,
+				Timezone: row.Timezone,
+				Lang:     row.Lang,
+				Phone:    row.Phone,
+				FareUrl:  row.FareUrl,
+				Email:    row.Email,
+			}
+		}
+	}
+
+	c := newArrivalsCollector(requestLanguage(r))
+	var agencies []gtfsdb.Agency
+	seenAgencies := make(map[string]bool)
+	includedStopIDs := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		agency, ok := stopAgency[stop.ID]
+		if !ok {
+			// Stops without any stop_times have no agency and no arrivals.
+			continue
+		}
+		agencyLoc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+
+		stopParams := params
+		stopParams.Time = params.Time.In(agencyLoc)
+		found, err := api.collectArrivalsForStop(ctx, c, agency.ID, stop, agencyLoc, stopParams)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		if found {
+			c.addAlerts(api.GtfsManager.GetAlertsForStop(stop.ID), agency.ID)
+		}
+
+		includedStopIDs = append(includedStopIDs, utils.FormCombinedID(agency.ID, stop.ID))
+		if !seenAgencies[agency.ID] {
+			seenAgencies[agency.ID] = true
+			agencies = append(agencies, agency)
+		}
+	}
+
+	slices.SortStableFunc(c.arrivals, func(a, b models.ArrivalAndDeparture) int {
+		return arrivalDepartureTime(a).Compare(arrivalDepartureTime(b))
+	})
+
+	references, err := api.buildArrivalsReferences(ctx, c, agencies)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	entry := models.ArrivalsAndDeparturesForLocationEntry{
+		ArrivalsAndDepartures: c.arrivals,
+		StopIDs:               includedStopIDs,
+		SituationIDs:          c.situationIDs(),
+		LimitExceeded:         limitExceeded,
+	}
+	w.Header().Set("ETag", arrivalsETag(c.arrivals...))
+	api.sendResponse(w, r, models.NewEntryResponse(entry, *references, api.Clock))
+}
@@ -0,0 +1,89 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func arrivalsForLocationURL(lat, lon float64, extra string) string {
+	return fmt.Sprintf("/api/where/arrivals-and-departures-for-location.json?key=TEST&lat=%f&lon=%f%s", lat, lon, extra)
+}
+
+func TestArrivalsAndDeparturesForLocationCombinesNearbyStops(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[ArrivalsAndDeparturesForLocationResponse](t, api,
+		arrivalsForLocationURL(testdata.Stop4062.Lat, testdata.Stop4062.Lon, "&radius=500&minutesAfter=60"))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := model.Data.Entry
+	assert.Contains(t, entry.StopIDs, testdata.Stop4062.ID)
+	require.NotEmpty(t, entry.ArrivalsAndDepartures)
+
+	stopIDs := make(map[string]bool)
+	for i, arrival := range entry.ArrivalsAndDepartures {
+		stopIDs[arrival.StopID] = true
+		if i > 0 {
+			prev := entry.ArrivalsAndDepartures[i-1]
+			assert.False(t, arrivalDepartureTime(arrival).Before(arrivalDepartureTime(prev)), "arrivals must be ordered by departure time")
+		}
+	}
+	assert.True(t, stopIDs[testdata.Stop4062.ID])
+	for stopID := range stopIDs {
+		assert.Contains(t, entry.StopIDs, stopID)
+	}
+
+	refs := model.Data.References
+	assert.NotEmpty(t, refs.Agencies)
+	assert.NotEmpty(t, refs.Routes)
+	assert.NotEmpty(t, refs.Trips)
+	assert.NotEmpty(t, refs.Stops)
+}
+
+func TestArrivalsAndDeparturesForLocationMaxCountLimitsStops(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[ArrivalsAndDeparturesForLocationResponse](t, api,
+		arrivalsForLocationURL(testdata.Stop4062.Lat, testdata.Stop4062.Lon, "&radius=2000&maxCount=1"))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := model.Data.Entry
+	require.Len(t, entry.StopIDs, 1)
+	assert.True(t, entry.LimitExceeded)
+	for _, arrival := range entry.ArrivalsAndDepartures {
+		assert.Equal(t, entry.StopIDs[0], arrival.StopID)
+	}
+}
+
+func TestArrivalsAndDeparturesForLocationValidation(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	for _, query := range []string{
+		arrivalsForLocationURL(91, testdata.Stop4062.Lon, ""),
+		arrivalsForLocationURL(testdata.Stop4062.Lat, testdata.Stop4062.Lon, "&minutesAfter=abc"),
+		arrivalsForLocationURL(testdata.Stop4062.Lat, testdata.Stop4062.Lon, "&format=speech"),
+	} {
+		resp, _ := serveApiAndRetrieveEndpoint(t, api, query)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestArrivalsAndDeparturesForLocationNoStops(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[ArrivalsAndDeparturesForLocationResponse](t, api, arrivalsForLocationURL(0, 0, "&radius=100"))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, model.Data.Entry.StopIDs)
+	assert.Empty(t, model.Data.Entry.ArrivalsAndDepartures)
+}
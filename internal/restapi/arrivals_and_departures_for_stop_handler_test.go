@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"maglev.onebusaway.org/gtfsdb"
@@ -217,6 +218,19 @@ func TestParseArrivalsAndDeparturesParams_InvalidValues(t *testing.T) {
 	assert.Equal(t, "must be a valid Unix timestamp in milliseconds", errs["time"][0])
 }
 
+func TestParseArrivalsAndDeparturesParams_Format(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	params, errs := api.parseArrivalsAndDeparturesParams(httptest.NewRequest("GET", "/test?format=speech", nil))
+	assert.Nil(t, errs)
+	assert.Equal(t, arrivalsFormatSpeech, params.Format)
+
+	_, errs = api.parseArrivalsAndDeparturesParams(httptest.NewRequest("GET", "/test?format=xml", nil))
+	require.NotNil(t, errs)
+	assert.Equal(t, "must be json or speech", errs["format"][0])
+}
+
 func TestArrivalsAndDeparturesForStopHandlerWithInvalidParams(t *testing.T) {
 	api := createTestApi(t)
 	defer api.Shutdown()
@@ -502,6 +516,69 @@ func TestPluralArrivals_TripLevelDelayFallback(t *testing.T) {
 		"predicted departure should be scheduled + trip-level 120s delay")
 }
 
+// TestPluralArrivals_VehicleOccupancy verifies that the occupancy a vehicle
+// reports is returned as its current and predicted occupancy.
+func TestPluralArrivals_VehicleOccupancy(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 2, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	_, combinedStopID, tripID, _ := setupDelayPropTestData(t, api, 1)
+	occupancy := gtfs.OccupancyStatus(gtfsrt.VehiclePosition_FEW_SEATS_AVAILABLE)
+	api.GtfsManager.MockAddVehicleWithOptions("v1", tripID, "dp-route", internalgtfs.MockVehicleOptions{OccupancyStatus: &occupancy})
+	tripDelay := 120 * time.Second
+	api.GtfsManager.MockAddTripUpdate(tripID, &tripDelay, nil)
+
+	_, model := callAPIHandler[ArrivalsAndDeparturesResponse](t, api, arrivalsAndDeparturesURL(combinedStopID))
+
+	require.NotEmpty(t, model.Data.Entry.ArrivalsAndDepartures, "expected at least one arrival")
+	a := model.Data.Entry.ArrivalsAndDepartures[0]
+	require.True(t, a.Predicted)
+	assert.Equal(t, "FEW_SEATS_AVAILABLE", a.OccupancyStatus)
+	assert.Equal(t, "FEW_SEATS_AVAILABLE", a.PredictedOccupancy)
+	assert.Empty(t, a.HistoricalOccupancy, "there is no historical ridership data")
+}
+
+func TestPluralArrivals_HistoricalOccupancy(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 2, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+	ctx := context.Background()
+
+	_, combinedStopID, tripID, _ := setupDelayPropTestData(t, api, 1)
+	api.GtfsManager.MockSetArrivalHistoryDays(30)
+	t.Cleanup(func() {
+		api.GtfsManager.MockSetArrivalHistoryDays(0)
+		_, err := api.GtfsManager.GtfsDB.DB.Exec("DELETE FROM arrival_history WHERE trip_id = ?", tripID)
+		require.NoError(t, err)
+	})
+	for i, occupancy := range []string{"FULL", "STANDING_ROOM_ONLY", "FULL"} {
+		require.NoError(t, api.GtfsManager.GtfsDB.Queries.RecordArrivalHistory(ctx, gtfsdb.RecordArrivalHistoryParams{
+			ServiceDate:     fmt.Sprintf("200912%02d", 28+i),
+			TripID:          tripID,
+			StopSequence:    1,
+			StopID:          "dp-stop",
+			OccupancyStatus: occupancy,
+			RecordedAt:      mockClock.Now().UnixMilli(),
+		}))
+	}
+
+	_, model := callAPIHandler[ArrivalsAndDeparturesResponse](t, api, arrivalsAndDeparturesURL(combinedStopID))
+
+	// Other tests may have added later stop times to the trip at the same stop.
+	var found bool
+	for _, a := range model.Data.Entry.ArrivalsAndDepartures {
+		if a.StopSequence == 0 {
+			found = true
+			assert.Equal(t, "FULL", a.HistoricalOccupancy)
+		} else {
+			assert.Empty(t, a.HistoricalOccupancy, "no history was recorded at stop sequence %d", a.StopSequence+1)
+		}
+	}
+	assert.True(t, found, "expected the arrival at stop sequence 1")
+}
+
 // TestPluralArrivals_TripLevelDelayWithoutVehicle verifies that when a TripUpdate has a
 // trip-level Delay but no vehicle position exists, the prediction still applies.
 // Prediction is no longer gated on vehicle != nil.
@@ -637,21 +714,28 @@ func TestPluralArrivals_AbsoluteTimeStopEvent(t *testing.T) {
 		"predictedDepartureTime should equal the absolute departure timestamp")
 }
 
-// TestPluralArrivals_StalePropagatedDelayReset verifies that when the closest prior
-// stop has only absolute Time data (no Delay), propagatedDelayMs is reset to 0.
-func TestPluralArrivals_StalePropagatedDelayReset(t *testing.T) {
+// TestPluralArrivals_AbsoluteTimePriorStopPropagation verifies that when the closest
+// prior stop has only absolute Time data (no Delay), its delay is measured against
+// that stop's scheduled time and propagated.
+func TestPluralArrivals_AbsoluteTimePriorStopPropagation(t *testing.T) {
 	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 2, 0, 0, time.UTC))
 	api := createTestApiWithClock(t, mockClock)
 	defer api.Shutdown()
 	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
 
-	// Stop being queried is sequence 3.
-	_, combinedStopID, tripID, scheduledArrivalMs := setupDelayPropTestData(t, api, 3)
+	// Stop being queried is sequence 3; sequence 2 is scheduled at the same times.
+	stopCode, combinedStopID, tripID, scheduledArrivalMs := setupDelayPropTestData(t, api, 3)
+	_, err := api.GtfsManager.GtfsDB.Queries.CreateStopTime(t.Context(), gtfsdb.CreateStopTimeParams{
+		TripID: tripID, StopID: stopCode, StopSequence: 2,
+		ArrivalTime:   int64(8 * time.Hour),
+		DepartureTime: int64(8*time.Hour + 5*time.Minute),
+	})
+	require.NoError(t, err)
 	api.GtfsManager.MockAddVehicle("v1", tripID, "dp-route")
 
 	// Sequence 1: has a 90s delay.
-	// Sequence 2 (closer): has only an absolute Time, no Delay.
-	// Expected: propagatedDelayMs is reset to 0 when seq 2 becomes the closest prior.
+	// Sequence 2 (closer): has only an absolute Time, a minute before its 08:00 arrival.
+	// Expected: the closer stop wins, propagating -60s.
 	seq1 := uint32(1)
 	delay90s := 90 * time.Second
 	seq2 := uint32(2)
@@ -674,13 +758,135 @@ func TestPluralArrivals_StalePropagatedDelayReset(t *testing.T) {
 		}
 		found = true
 		assert.True(t, a.Predicted, "should be predicted via prior stop propagation")
-		assert.Equal(t, scheduledArrivalMs, a.PredictedArrivalTime.UnixMilli(),
-			"propagatedDelayMs should be 0 when closest prior stop only has absolute Time data")
+		assert.Equal(t, scheduledArrivalMs-60000, a.PredictedArrivalTime.UnixMilli(),
+			"the delay of an absolute Time should be measured against the prior stop's schedule")
 		break
 	}
 	assert.True(t, found, "expected to find arrival for seq=3 (stopSequence=2)")
 }
 
+// setupTripUpdatesOnlyTestData creates a trip through four stops, ten minutes
+// apart from 08:00 on 2010-01-01, for feeds that send trip updates without
+// vehicle positions. It returns the trip ID and the combined IDs of the stops.
+func setupTripUpdatesOnlyTestData(t *testing.T, api *RestAPI) (string, []string) {
+	t.Helper()
+	ctx := t.Context()
+	q := api.GtfsManager.GtfsDB.Queries
+
+	agencyID := "tu-agency"
+	tripID := "tu-trip"
+	_, err := q.CreateAgency(ctx, gtfsdb.CreateAgencyParams{
+		ID: agencyID, Name: "Trip Updates Agency", Url: "http://example.com", Timezone: "UTC",
+	})
+	require.NoError(t, err)
+	_, err = q.CreateRoute(ctx, gtfsdb.CreateRouteParams{
+		ID: "tu-route", AgencyID: agencyID, ShortName: nulls.String("TU"), Type: 3,
+	})
+	require.NoError(t, err)
+	_, err = q.CreateCalendar(ctx, gtfsdb.CreateCalendarParams{
+		ID: "tu-svc", Monday: 1, Tuesday: 1, Wednesday: 1, Thursday: 1, Friday: 1, Saturday: 1, Sunday: 1,
+		StartDate: "20100101", EndDate: "20301231",
+	})
+	require.NoError(t, err)
+	_, err = q.CreateTrip(ctx, gtfsdb.CreateTripParams{ID: tripID, RouteID: "tu-route", ServiceID: "tu-svc"})
+	require.NoError(t, err)
+
+	var stopIDs []string
+	for i := range 4 {
+		stopCode := fmt.Sprintf("tu-stop-%d", i+1)
+		_, err = q.CreateStop(ctx, gtfsdb.CreateStopParams{
+			ID: stopCode, Name: nulls.String(stopCode), Lat: 47.1, Lon: -122.1 + float64(i)*0.01,
+		})
+		require.NoError(t, err)
+		at := int64(8*time.Hour + time.Duration(i)*10*time.Minute)
+		_, err = q.CreateStopTime(ctx, gtfsdb.CreateStopTimeParams{
+			TripID: tripID, StopID: stopCode, StopSequence: int64(i + 1),
+			ArrivalTime: at, DepartureTime: at,
+		})
+		require.NoError(t, err)
+		stopIDs = append(stopIDs, utils.FormCombinedID(agencyID, stopCode))
+	}
+	return tripID, stopIDs
+}
+
+// TestPluralArrivals_TripUpdatesOnlyPropagation verifies that delays propagate
+// to later stops from trip updates alone, with no vehicle in the feed.
+func TestPluralArrivals_TripUpdatesOnlyPropagation(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 5, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	tripID, stopIDs := setupTripUpdatesOnlyTestData(t, api)
+	scheduledLastStop := time.Date(2010, 1, 1, 8, 30, 0, 0, time.UTC)
+
+	stop2, stop3 := "tu-stop-2", "tu-stop-3"
+	seq2, seq3 := uint32(2), uint32(3)
+	threeMinutes := 3 * time.Minute
+	fourMinutes := 4 * time.Minute
+	arrivedAt := time.Date(2010, 1, 1, 8, 12, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		updates   []gtfs.StopTimeUpdate
+		predicted bool
+		delay     time.Duration
+	}{
+		{
+			name:      "delay by stop sequence",
+			updates:   []gtfs.StopTimeUpdate{{StopSequence: &seq2, Arrival: &gtfs.StopTimeEvent{Delay: &threeMinutes}}},
+			predicted: true,
+			delay:     threeMinutes,
+		},
+		{
+			name:      "delay by stop ID only",
+			updates:   []gtfs.StopTimeUpdate{{StopID: &stop2, Departure: &gtfs.StopTimeEvent{Delay: &fourMinutes}}},
+			predicted: true,
+			delay:     fourMinutes,
+		},
+		{
+			name:      "absolute time by stop ID only",
+			updates:   []gtfs.StopTimeUpdate{{StopID: &stop2, Arrival: &gtfs.StopTimeEvent{Time: &arrivedAt}}},
+			predicted: true,
+			delay:     2 * time.Minute,
+		},
+		{
+			name: "skipped stop passes the delay through",
+			updates: []gtfs.StopTimeUpdate{
+				{StopSequence: &seq2, Arrival: &gtfs.StopTimeEvent{Delay: &threeMinutes}},
+				{StopSequence: &seq3, ScheduleRelationship: gtfsrt.TripUpdate_StopTimeUpdate_SKIPPED},
+			},
+			predicted: true,
+			delay:     threeMinutes,
+		},
+		{
+			name: "no data stops propagation",
+			updates: []gtfs.StopTimeUpdate{
+				{StopSequence: &seq2, Arrival: &gtfs.StopTimeEvent{Delay: &threeMinutes}},
+				{StopID: &stop3, ScheduleRelationship: gtfsrt.TripUpdate_StopTimeUpdate_NO_DATA},
+			},
+			predicted: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.GtfsManager.MockResetRealTimeData()
+			api.GtfsManager.MockAddTripUpdate(tripID, nil, tt.updates)
+
+			_, model := callAPIHandler[ArrivalsAndDeparturesResponse](t, api, arrivalsAndDeparturesURL(stopIDs[3]))
+
+			require.Len(t, model.Data.Entry.ArrivalsAndDepartures, 1)
+			a := model.Data.Entry.ArrivalsAndDepartures[0]
+			assert.Equal(t, tt.predicted, a.Predicted)
+			if tt.predicted {
+				assert.Equal(t, scheduledLastStop.Add(tt.delay).UnixMilli(), a.PredictedArrivalTime.UnixMilli())
+				assert.Equal(t, scheduledLastStop.Add(tt.delay).UnixMilli(), a.PredictedDepartureTime.UnixMilli())
+			}
+		})
+	}
+}
+
 func TestGetNearbyStopIDs_UsesResolvedAgency(t *testing.T) {
 	// Use MockClock within RABA service window (calendar ends 2025-12-31).
 	mockClock := clock.NewMockClock(time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC))
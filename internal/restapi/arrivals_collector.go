@@ -0,0 +1,650 @@
+package restapi
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"maglev.onebusaway.org/gtfsdb"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// arrivalsCollector accumulates arrivals for one or more stops together with
+// the trips, routes, stops and alerts they reference, so that references are
+// built once per response however many stops contributed.
+type arrivalsCollector struct {
+	arrivals []models.ArrivalAndDeparture
+
+	trips  map[string]*gtfsdb.Trip
+	routes map[string]*gtfsdb.Route
+	// stops maps each referenced stop to the agency used in its combined ID.
+	stops map[string]string
+	// situations tracks the alerts affecting the collected stops and trips.
+	situations *situationSet
+}
+
+// newArrivalsCollector creates an empty collector whose situations are
+// rendered in lang; see requestLanguage.
+func newArrivalsCollector(lang string) *arrivalsCollector {
+	return &arrivalsCollector{
+		arrivals:   make([]models.ArrivalAndDeparture, 0),
+		trips:      make(map[string]*gtfsdb.Trip),
+		routes:     make(map[string]*gtfsdb.Route),
+		stops:      make(map[string]string),
+		situations: newSituationSet(lang),
+	}
+}
+
+func (c *arrivalsCollector) addStop(stopID, agencyID string) {
+	if _, exists := c.stops[stopID]; !exists {
+		c.stops[stopID] = agencyID
+	}
+}
+
+// addAlerts records alerts affecting a stop in the namespace of agencyID.
+func (c *arrivalsCollector) addAlerts(alerts []gtfs.Alert, agencyID string) {
+	c.situations.add(agencyID, alerts...)
+}
+
+// situationIDs returns the combined IDs of every collected alert.
+func (c *arrivalsCollector) situationIDs() []string {
+	return c.situations.situationIDs()
+}
+
+// collectArrivalsForStop adds the arrivals at stop within the params window to
+// c. It reports false when the stop has no scheduled service in the window at
+// all. loc is the stop agency's time zone, and params.Time must be in it.
+func (api *RestAPI) collectArrivalsForStop(ctx context.Context, c *arrivalsCollector, stopAgencyID string, stop gtfsdb.Stop, loc *time.Location, params ArrivalsStopParams) (bool, error) {
+	stopCode := stop.ID
+	stopID := utils.FormCombinedID(stopAgencyID, stopCode)
+	windowStart := params.Time.Add(-params.Before)
+	windowEnd := params.Time.Add(params.After)
+
+	type activeStopTime struct {
+		gtfsdb.GetStopTimesForStopInWindowRow
+		ServiceDate time.Time
+	}
+	var allActiveStopTimes []activeStopTime
+
+	type serviceDay struct {
+		midnight   time.Time
+		serviceIDs map[string]bool
+	}
+	var serviceDays []serviceDay
+
+	for dayOffset := -1; dayOffset <= 1; dayOffset++ {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		targetDate := params.Time.AddDate(0, 0, dayOffset)
+		serviceMidnight := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 0, 0, 0, 0, loc)
+		serviceDateStr := targetDate.Format("20060102")
+
+		activeServiceIDs, err := api.GtfsManager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, serviceDateStr)
+		if err != nil {
+			api.Logger.Warn("failed to query active service IDs",
+				slog.String("date", serviceDateStr),
+				slog.Any("error", err))
+			continue
+		}
+		if len(activeServiceIDs) == 0 {
+			continue
+		}
+
+		activeServiceIDSet := make(map[string]bool, len(activeServiceIDs))
+		for _, sid := range activeServiceIDs {
+			activeServiceIDSet[sid] = true
+		}
+
+		startOffset := windowStart.Sub(serviceMidnight)
+		endOffset := windowEnd.Sub(serviceMidnight)
+		if endOffset < 0 {
+			continue
+		}
+		serviceDays = append(serviceDays, serviceDay{midnight: serviceMidnight, serviceIDs: activeServiceIDSet})
+
+		stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForStopInWindow(ctx, gtfsdb.GetStopTimesForStopInWindowParams{
+			StopID:           stopCode,
+			WindowStartNanos: startOffset.Nanoseconds(),
+			WindowEndNanos:   endOffset.Nanoseconds(),
+		})
+		if err != nil {
+			api.Logger.Warn("failed to query stop times in window",
+				slog.String("stopID", stopCode),
+				slog.Any("error", err))
+			continue
+		}
+
+		for _, st := range stopTimes {
+			if activeServiceIDSet[st.ServiceID] {
+				allActiveStopTimes = append(allActiveStopTimes, activeStopTime{
+					GetStopTimesForStopInWindowRow: st,
+					ServiceDate:                    serviceMidnight,
+				})
+			}
+		}
+	}
+
+	// GTFS-Flex service at the stop: booking rules for timed stop times, and
+	// pickup/drop-off windows that have no timed stop time at all.
+	flexRows, err := api.GtfsManager.GtfsDB.Queries.GetFlexStopTimesForStop(ctx, sql.NullString{String: stopCode, Valid: true})
+	if err != nil {
+		api.Logger.Warn("failed to query flex stop times",
+			slog.String("stopID", stopCode),
+			slog.Any("error", err))
+		flexRows = nil
+	}
+	flexByKey := make(map[flexStopTimeKey]gtfsdb.GetFlexStopTimesForStopRow, len(flexRows))
+	var flexWindows []activeFlexWindow
+	for _, row := range flexRows {
+		flexByKey[flexStopTimeKey{row.TripID, row.StopSequence}] = row
+		if !row.StartPickupDropOffWindow.Valid || !row.EndPickupDropOffWindow.Valid {
+			continue
+		}
+		for _, day := range serviceDays {
+			if !day.serviceIDs[row.ServiceID] {
+				continue
+			}
+			start := day.midnight.Add(time.Duration(row.StartPickupDropOffWindow.Int64))
+			end := day.midnight.Add(time.Duration(row.EndPickupDropOffWindow.Int64))
+			if start.After(windowEnd) || end.Before(windowStart) {
+				continue
+			}
+			flexWindows = append(flexWindows, activeFlexWindow{GetFlexStopTimesForStopRow: row, ServiceDate: day.midnight})
+		}
+	}
+
+	if len(allActiveStopTimes) == 0 && len(flexWindows) == 0 {
+		return false, nil
+	}
+
+	// Add the current stop
+	c.addStop(stop.ID, stopAgencyID)
+
+	batchRouteIDs := make(map[string]bool)
+	batchTripIDs := make(map[string]bool)
+
+	for _, ast := range allActiveStopTimes {
+		st := ast.GetStopTimesForStopInWindowRow
+		if st.RouteID != "" {
+			batchRouteIDs[st.RouteID] = true
+		}
+		if st.TripID != "" {
+			batchTripIDs[st.TripID] = true
+		}
+	}
+
+	for _, fw := range flexWindows {
+		batchRouteIDs[fw.RouteID] = true
+		batchTripIDs[fw.TripID] = true
+	}
+
+	// Booking rules are only looked up for the flex stop times in this response.
+	var ruleIDs []sql.NullString
+	for _, ast := range allActiveStopTimes {
+		if row, ok := flexByKey[flexStopTimeKey{ast.TripID, ast.StopSequence}]; ok {
+			ruleIDs = append(ruleIDs, row.PickupBookingRuleID, row.DropOffBookingRuleID)
+		}
+	}
+	for _, fw := range flexWindows {
+		ruleIDs = append(ruleIDs, fw.PickupBookingRuleID, fw.DropOffBookingRuleID)
+	}
+	bookingRules, err := api.bookingRulesByID(ctx, ruleIDs...)
+	if err != nil {
+		api.Logger.Warn("failed to fetch booking rules", slog.Any("error", err))
+	}
+
+	uniqueRouteIDs := make([]string, 0, len(batchRouteIDs))
+	for id := range batchRouteIDs {
+		uniqueRouteIDs = append(uniqueRouteIDs, id)
+	}
+
+	uniqueTripIDs := make([]string, 0, len(batchTripIDs))
+	for id := range batchTripIDs {
+		uniqueTripIDs = append(uniqueTripIDs, id)
+	}
+
+	allRoutes, err := api.GtfsManager.GtfsDB.Queries.GetRoutesByIDs(ctx, uniqueRouteIDs)
+	if err != nil {
+		return false, err
+	}
+
+	allTrips, err := api.GtfsManager.GtfsDB.Queries.GetTripsByIDs(ctx, uniqueTripIDs)
+	if err != nil {
+		return false, err
+	}
+
+	routesLookup := make(map[string]gtfsdb.Route)
+	for _, route := range allRoutes {
+		routesLookup[route.ID] = route
+	}
+
+	tripsLookup := make(map[string]gtfsdb.Trip)
+	for _, trip := range allTrips {
+		tripsLookup[trip.ID] = trip
+	}
+
+	// Batch-fetch stop counts per trip to avoid per-arrival N+1 queries for totalStopsInTrip.
+	tripStopCountMap := make(map[string]int, len(uniqueTripIDs))
+	if len(uniqueTripIDs) > 0 {
+		allStopTimesForTrips, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTripIDs(ctx, uniqueTripIDs)
+		if err != nil {
+			api.Logger.Warn("failed to batch fetch stop times for trips", slog.Any("error", err))
+		} else {
+			for _, st := range allStopTimesForTrips {
+				tripStopCountMap[st.TripID]++
+			}
+		}
+	}
+
+	for _, ast := range allActiveStopTimes {
+		st := ast.GetStopTimesForStopInWindowRow
+
+		serviceMidnight := ast.ServiceDate
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		route, routeExists := routesLookup[st.RouteID]
+		if !routeExists {
+			api.Logger.Debug("skipping stop time: route not found in batch fetch",
+				slog.String("routeID", st.RouteID),
+				slog.String("tripID", st.TripID))
+			continue
+		}
+
+		trip, tripExists := tripsLookup[st.TripID]
+		if !tripExists {
+			api.Logger.Debug("skipping stop time: trip not found in batch fetch",
+				slog.String("tripID", st.TripID),
+				slog.String("routeID", st.RouteID))
+			continue
+		}
+
+		rCopy := route
+		c.routes[route.ID] = &rCopy
+		tCopy := trip
+		c.trips[trip.ID] = &tCopy
+
+		scheduledArrivalTime := serviceMidnight.Add(time.Duration(st.ArrivalTime))
+		scheduledDepartureTime := serviceMidnight.Add(time.Duration(st.DepartureTime))
+
+		var (
+			predictedArrivalTime   = scheduledArrivalTime
+			predictedDepartureTime = scheduledDepartureTime
+			predicted              = false
+			vehicleID              string
+			tripStatus             *models.TripStatus
+			distanceFromStop       = 0.0
+			numberOfStopsAway      = 0
+			occupancyStatus        string
+		)
+
+		// Get vehicle if available
+		vehicle := api.GtfsManager.GetVehicleForTrip(ctx, st.TripID)
+		if vehicle != nil && vehicle.Trip != nil {
+			if vehicle.ID != nil {
+				vehicleID = vehicle.ID.ID
+			} else {
+				api.Logger.Warn("vehicle with nil ID descriptor found for trip", "tripID", st.TripID)
+			}
+			occupancyStatus = internalgtfs.VehicleOccupancyStatus(vehicle)
+		}
+
+		// Prepare scheduled times for the shared function
+		schedArrTime := serviceMidnight.Add(time.Duration(st.ArrivalTime))
+		schedDepTime := serviceMidnight.Add(time.Duration(st.DepartureTime))
+
+		// Call unified prediction logic
+		predArr, predDep, isPredicted := api.getPredictedTimes(
+			ctx,
+			st.TripID,
+			stopCode,
+			int64(st.StopSequence),
+			schedArrTime,
+			schedDepTime,
+		)
+
+		if isPredicted {
+			predicted = true
+			predictedArrivalTime = predArr
+			predictedDepartureTime = predDep
+		}
+
+		if vehicle != nil {
+			// Use route.AgencyID instead of stopAgencyID for BuildTripStatus
+			status, statusErr := api.BuildTripStatus(ctx, route.AgencyID, st.TripID, nil, serviceMidnight, params.Time)
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			if statusErr != nil {
+				api.Logger.Warn("BuildTripStatus failed for arrival",
+					"tripID", st.TripID, "error", statusErr)
+			}
+			if status != nil {
+				tripStatus = status
+
+				if status.NextStop != "" {
+					_, nextStopID, err := utils.ExtractAgencyIDAndCodeID(status.NextStop)
+					if err == nil {
+						c.addStop(nextStopID, stopAgencyID)
+					}
+				}
+				if status.ClosestStop != "" {
+					_, closestStopID, err := utils.ExtractAgencyIDAndCodeID(status.ClosestStop)
+					if err == nil {
+						c.addStop(closestStopID, stopAgencyID)
+					}
+				}
+
+				if vehicle.Position != nil {
+					distanceFromStop = api.getBlockDistanceToStop(ctx, st.TripID, stopCode, vehicle, params.Time)
+
+					numberOfStopsAwayPtr := api.getNumberOfStopsAway(ctx, st.TripID, int(st.StopSequence), vehicle, params.Time)
+					if numberOfStopsAwayPtr != nil {
+						numberOfStopsAway = *numberOfStopsAwayPtr
+					} else {
+						numberOfStopsAway = -1
+					}
+				}
+
+				// If there's an active trip that's different from the current trip, add it to references
+				if status.ActiveTripID != "" {
+					_, activeTripID, err := utils.ExtractAgencyIDAndCodeID(status.ActiveTripID)
+					if err == nil && activeTripID != st.TripID {
+						// Check cache for active trip
+						if _, exists := c.trips[activeTripID]; !exists {
+							activeTrip, err := api.GtfsManager.GtfsDB.Queries.GetTrip(ctx, activeTripID)
+							if err != nil {
+								api.Logger.Debug("skipping active trip reference: trip not found",
+									slog.String("activeTripID", activeTripID),
+									slog.String("scheduledTripID", st.TripID),
+									slog.Any("error", err))
+							} else {
+								c.trips[activeTrip.ID] = &activeTrip
+								activeRoute, err := api.GtfsManager.GtfsDB.Queries.GetRoute(ctx, activeTrip.RouteID)
+								if err == nil {
+									c.routes[activeRoute.ID] = &activeRoute
+								} else {
+									api.Logger.Warn("failed to fetch route for active trip reference",
+										"tripID", activeTripID, "routeID", activeTrip.RouteID, "error", err)
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+
+		predictedOccupancy := ""
+		if !predicted {
+			predictedArrivalTime = time.Time{}
+			predictedDepartureTime = time.Time{}
+		} else if predictedDepartureTime.After(params.Time) {
+			// Feeds report the occupancy of the whole vehicle, so its latest
+			// reading is the prediction for each stop it has yet to serve.
+			predictedOccupancy = occupancyStatus
+		}
+		historicalOccupancy := api.historicalOccupancy(ctx, st.TripID, int64(st.StopSequence))
+
+		totalStopsInTrip := tripStopCountMap[st.TripID]
+
+		blockTripSequence := api.calculateBlockTripSequence(ctx, st.TripID, serviceMidnight)
+
+		lastUpdateTime := api.GtfsManager.GetVehicleLastUpdateTime(vehicle)
+
+		situationIDs := c.situations.addTrip(api.GtfsManager.GetTripAlerts(ctx, st.TripID))
+
+		arrivalEnabled, departureEnabled := stopTimeEnabled(st.PickupType, st.DropOffType)
+
+		arrival := models.NewArrivalAndDeparture(
+			utils.FormCombinedID(route.AgencyID, route.ID),  // routeID
+			route.ShortName.String,                          // routeShortName
+			route.LongName.String,                           // routeLongName
+			utils.FormCombinedID(route.AgencyID, st.TripID), // tripID
+			st.TripHeadsign.String,                          // tripHeadsign
+			stopID,                                          // stopID
+			vehicleID,                                       // vehicleID
+			serviceMidnight,                                 // serviceDate
+			scheduledArrivalTime,                            // scheduledArrivalTime
+			scheduledDepartureTime,                          // scheduledDepartureTime
+			predictedArrivalTime,                            // predictedArrivalTime
+			predictedDepartureTime,                          // predictedDepartureTime
+			lastUpdateTime,                                  // lastUpdateTime
+			predicted,                                       // predicted
+			arrivalEnabled,                                  // arrivalEnabled
+			departureEnabled,                                // departureEnabled
+			int(st.StopSequence)-1,                          // stopSequence (Zero-based index)
+			totalStopsInTrip,                                // totalStopsInTrip
+			numberOfStopsAway,                               // numberOfStopsAway
+			blockTripSequence,                               // blockTripSequence
+			distanceFromStop,                                // distanceFromStop
+			"default",                                       // status
+			occupancyStatus,                                 // occupancyStatus
+			predictedOccupancy,                              // predicted occupancy
+			historicalOccupancy,                             // historical occupancy
+			tripStatus,                                      // tripStatus
+			situationIDs,                                    // situationIDs
+		)
+
+		arrival.FlagStop = continuousStopping(route)
+
+		if row, ok := flexByKey[flexStopTimeKey{st.TripID, st.StopSequence}]; ok {
+			arrival.BookingRules = bookingRulesFor(bookingRules, row.PickupBookingRuleID, row.DropOffBookingRuleID)
+		}
+
+		c.arrivals = append(c.arrivals, *arrival)
+	}
+
+	for _, fw := range flexWindows {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		route, routeExists := routesLookup[fw.RouteID]
+		trip, tripExists := tripsLookup[fw.TripID]
+		if !routeExists || !tripExists {
+			continue
+		}
+		rCopy := route
+		c.routes[route.ID] = &rCopy
+		tCopy := trip
+		c.trips[trip.ID] = &tCopy
+
+		// A flex window has no scheduled time and no prediction; the window
+		// stands in for the scheduled arrival and departure.
+		windowOpens := fw.ServiceDate.Add(time.Duration(fw.StartPickupDropOffWindow.Int64))
+		windowCloses := fw.ServiceDate.Add(time.Duration(fw.EndPickupDropOffWindow.Int64))
+		arrival := models.NewArrivalAndDeparture(
+			utils.FormCombinedID(route.AgencyID, route.ID),
+			route.ShortName.String,
+			route.LongName.String,
+			utils.FormCombinedID(route.AgencyID, fw.TripID),
+			fw.TripHeadsign.String,
+			stopID,
+			"",
+			fw.ServiceDate,
+			windowOpens,
+			windowCloses,
+			time.Time{},
+			time.Time{},
+			time.Time{},
+			false,
+			true,
+			true,
+			int(fw.StopSequence)-1,
+			tripStopCountMap[fw.TripID],
+			0,
+			api.calculateBlockTripSequence(ctx, fw.TripID, fw.ServiceDate),
+			0,
+			"default",
+			"",
+			"",
+			"",
+			nil,
+			c.situations.addTrip(api.GtfsManager.GetTripAlerts(ctx, fw.TripID)),
+		)
+		arrival.FlagStop = continuousStopping(route)
+		arrival.StartPickupDropOffWindow = models.NewModelTime(windowOpens)
+		arrival.EndPickupDropOffWindow = models.NewModelTime(windowCloses)
+		arrival.BookingRules = bookingRulesFor(bookingRules, fw.PickupBookingRuleID, fw.DropOffBookingRuleID)
+
+		c.arrivals = append(c.arrivals, *arrival)
+	}
+
+	return true, nil
+}
+
+// buildArrivalsReferences builds the trip, stop, route, agency and situation
+// references for everything c collected. agencies seeds the agency
+// references; route agencies not among them are looked up and appended.
+func (api *RestAPI) buildArrivalsReferences(ctx context.Context, c *arrivalsCollector, agencies []gtfsdb.Agency) (*models.ReferencesModel, error) {
+	references := models.NewEmptyReferences()
+
+	// Track which agencies we have already added to avoid duplicates
+	addedAgencyIDs := make(map[string]bool)
+	for i := range agencies {
+		if !addedAgencyIDs[agencies[i].ID] {
+			references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&agencies[i]))
+			addedAgencyIDs[agencies[i].ID] = true
+		}
+	}
+
+	for _, trip := range c.trips {
+		// Get the route to determine the correct agency for trip/route IDs
+		var route *gtfsdb.Route
+		var routeAgencyID string
+
+		if r, ok := c.routes[trip.RouteID]; ok {
+			route = r
+			routeAgencyID = route.AgencyID
+		} else {
+			fetchedRoute, err := api.GtfsManager.GtfsDB.Queries.GetRoute(ctx, trip.RouteID)
+			if err == nil {
+				route = &fetchedRoute
+				routeAgencyID = route.AgencyID
+				c.routes[trip.RouteID] = route
+			} else {
+				api.Logger.Warn("failed to fetch route for trip reference", "tripID", trip.ID, "routeID", trip.RouteID, "error", err)
+				continue // Skip instead of falling back to stopAgencyID
+			}
+		}
+
+		tripRef := models.NewTripReference(
+			utils.FormCombinedID(routeAgencyID, trip.ID),        // Use route agency for trip ID
+			utils.FormCombinedID(routeAgencyID, trip.RouteID),   // Use route agency for route ID
+			utils.FormCombinedID(routeAgencyID, trip.ServiceID), // Use route agency for service ID
+			trip.TripHeadsign.String,
+			"",
+			strconv.FormatInt(trip.DirectionID.Int64, 10),
+			utils.FormCombinedID(routeAgencyID, trip.BlockID.String), // Use route agency for block ID
+			utils.FormCombinedID(routeAgencyID, trip.ShapeID.String), // Use route agency for shape ID
+		)
+		references.Trips = append(references.Trips, *tripRef)
+	}
+
+	// Batch-fetch all stop references in one shot instead of one query per stop.
+	stopIDsSlice := make([]string, 0, len(c.stops))
+	for sid := range c.stops {
+		stopIDsSlice = append(stopIDsSlice, sid)
+	}
+
+	batchStops, err := api.GtfsManager.GtfsDB.Queries.GetStopsByIDs(ctx, stopIDsSlice)
+	if err != nil {
+		api.Logger.Warn("failed to batch fetch stop references", slog.Any("error", err))
+		batchStops = nil
+	}
+
+	batchRoutesForStops, err := api.GtfsManager.GtfsDB.Queries.GetRoutesForStops(ctx, stopIDsSlice)
+	if err != nil {
+		api.Logger.Warn("failed to batch fetch routes for stop references", slog.Any("error", err))
+		batchRoutesForStops = nil
+	}
+
+	stopsMap := make(map[string]gtfsdb.Stop, len(batchStops))
+	for _, s := range batchStops {
+		stopsMap[s.ID] = s
+	}
+
+	routesByStop := make(map[string][]gtfsdb.GetRoutesForStopsRow)
+	for _, row := range batchRoutesForStops {
+		routesByStop[row.StopID] = append(routesByStop[row.StopID], row)
+	}
+
+	for stopID, stopAgencyID := range c.stops {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		stopData, ok := stopsMap[stopID]
+		if !ok {
+			api.Logger.Debug("skipping stop reference: stop not found", slog.String("stopID", stopID))
+			continue
+		}
+
+		routesForThisStop := routesByStop[stopID]
+		combinedRouteIDs := make([]string, len(routesForThisStop))
+		for i, route := range routesForThisStop {
+			// Use route.AgencyID instead of stopAgencyID
+			combinedRouteIDs[i] = utils.FormCombinedID(route.AgencyID, route.ID)
+
+			if _, exists := c.routes[route.ID]; !exists {
+				routeCopy := routeFromStopsRow(route)
+				c.routes[route.ID] = &routeCopy
+			}
+		}
+
+		stopRef := models.Stop{
+			ID:                 utils.FormCombinedID(stopAgencyID, stopData.ID),
+			Name:               stopData.Name.String,
+			Lat:                stopData.Lat,
+			Lon:                stopData.Lon,
+			Code:               stopData.Code.String,
+			Direction:          api.DirectionCalculator.CalculateStopDirection(ctx, stopData.ID, stopData.Direction),
+			LocationType:       int(stopData.LocationType.Int64),
+			WheelchairBoarding: utils.MapWheelchairBoarding(nulls.WheelchairBoardingOrUnknown(stopData.WheelchairBoarding)),
+			RouteIDs:           combinedRouteIDs,
+			StaticRouteIDs:     combinedRouteIDs,
+		}
+		references.Stops = append(references.Stops, stopRef)
+	}
+
+	for _, route := range c.routes {
+		routeRef := utils.RouteFromDatabase(route.AgencyID, *route)
+
+		references.Routes = append(references.Routes, routeRef)
+
+		// Add route agency to references if not already added
+		if !addedAgencyIDs[route.AgencyID] {
+			routeAgency, err := api.GtfsManager.GtfsDB.Queries.GetAgency(ctx, route.AgencyID)
+			if err == nil {
+				references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&routeAgency))
+				addedAgencyIDs[route.AgencyID] = true
+			} else {
+				api.Logger.Warn("failed to fetch route agency for reference", "agencyID", route.AgencyID, "error", err)
+			}
+		}
+	}
+
+	references.Situations = append(references.Situations, c.situations.references()...)
+
+	return references, nil
+}
+
+// gtfsNoPickupOrDropOff is the pickup_type and drop_off_type value for a stop
+// time at which riders cannot board or alight.
+const gtfsNoPickupOrDropOff = 1
+
+// stopTimeEnabled reports whether riders can alight (arrivalEnabled) and board
+// (departureEnabled) at a stop time, from its drop_off_type and pickup_type.
+// Service that must be arranged by phone or with the driver still counts as
+// enabled, as does a missing value, which GTFS defines as regular service.
+func stopTimeEnabled(pickupType, dropOffType sql.NullInt64) (arrivalEnabled, departureEnabled bool) {
+	return dropOffType.Int64 != gtfsNoPickupOrDropOff, pickupType.Int64 != gtfsNoPickupOrDropOff
+}
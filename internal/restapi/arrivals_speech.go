@@ -0,0 +1,129 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// Values accepted by the format query parameter of arrivals-and-departures.
+const (
+	arrivalsFormatJSON   = "json"
+	arrivalsFormatSpeech = "speech"
+)
+
+const (
+	// speechMaxRoutes caps how many route/destination pairs are read out; a
+	// listener cannot hold more than a handful in mind.
+	speechMaxRoutes = 4
+	// speechMaxTimesPerRoute caps the departures read out for each pair.
+	speechMaxTimesPerRoute = 2
+)
+
+// departureGroup holds the minutes until the upcoming departures of one route
+// and destination.
+type departureGroup struct {
+	route    string
+	headsign string
+	minutes  []int
+}
+
+// groupUpcomingDepartures groups the departures not yet gone at now by route
+// and headsign, ordered by each group's first departure. Real-time
+// predictions are preferred over the schedule. At most maxGroups groups with
+// maxTimes departures each are returned.
+func groupUpcomingDepartures(arrivals []models.ArrivalAndDeparture, now time.Time, maxGroups, maxTimes int) []*departureGroup {
+	departures := make([]models.ArrivalAndDeparture, 0, len(arrivals))
+	for _, a := range arrivals {
+		if !arrivalDepartureTime(a).Before(now) {
+			departures = append(departures, a)
+		}
+	}
+	sort.SliceStable(departures, func(i, j int) bool {
+		return arrivalDepartureTime(departures[i]).Before(arrivalDepartureTime(departures[j]))
+	})
+
+	var groups []*departureGroup
+	byKey := make(map[[2]string]*departureGroup)
+	for _, a := range departures {
+		route := a.RouteShortName
+		if route == "" {
+			route = a.RouteLongName
+		}
+		key := [2]string{route, a.TripHeadsign}
+		g, ok := byKey[key]
+		if !ok {
+			if len(groups) == maxGroups {
+				continue
+			}
+			g = &departureGroup{route: route, headsign: a.TripHeadsign}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		if len(g.minutes) < maxTimes {
+			g.minutes = append(g.minutes, int(arrivalDepartureTime(a).Sub(now)/time.Minute))
+		}
+	}
+	return groups
+}
+
+// arrivalsSpeechSummary renders upcoming departures as a sentence per route
+// and destination, e.g. "Route 40 to Downtown in 3 minutes, then 12 minutes."
+func arrivalsSpeechSummary(arrivals []models.ArrivalAndDeparture, now time.Time, window time.Duration) string {
+	groups := groupUpcomingDepartures(arrivals, now, speechMaxRoutes, speechMaxTimesPerRoute)
+
+	if len(groups) == 0 {
+		return fmt.Sprintf("No departures in the next %s.", speechMinutes(int(window/time.Minute)))
+	}
+
+	sentences := make([]string, len(groups))
+	for i, g := range groups {
+		times := make([]string, len(g.minutes))
+		for j, m := range g.minutes {
+			switch {
+			case m == 0:
+				times[j] = "now"
+			case j == 0:
+				times[j] = "in " + speechMinutes(m)
+			default:
+				times[j] = speechMinutes(m)
+			}
+		}
+		name := "Route " + g.route
+		if g.headsign != "" {
+			name += " to " + g.headsign
+		}
+		sentences[i] = name + " " + strings.Join(times, ", then ") + "."
+	}
+	return strings.Join(sentences, " ")
+}
+
+// sendArrivalsSpeech responds with the format=speech summary of arrivals.
+func (api *RestAPI) sendArrivalsSpeech(w http.ResponseWriter, r *http.Request, stopID, stopName string, arrivals []models.ArrivalAndDeparture, params ArrivalsStopParams) {
+	entry := models.ArrivalsSpeechEntry{
+		StopID:   stopID,
+		StopName: stopName,
+		Text:     arrivalsSpeechSummary(arrivals, params.Time, params.After),
+	}
+	api.sendResponse(w, r, models.NewEntryResponse(entry, *models.NewEmptyReferences(), api.Clock))
+}
+
+// arrivalDepartureTime is when a vehicle is expected to leave, preferring the
+// real-time prediction over the schedule.
+func arrivalDepartureTime(a models.ArrivalAndDeparture) time.Time {
+	if a.Predicted && !a.PredictedDepartureTime.IsZero() {
+		return a.PredictedDepartureTime.Time
+	}
+	return a.ScheduledDepartureTime.Time
+}
+
+func speechMinutes(m int) string {
+	if m == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", m)
+}
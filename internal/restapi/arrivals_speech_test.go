@@ -0,0 +1,82 @@
+package restapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func speechArrival(route, headsign string, scheduled time.Time) models.ArrivalAndDeparture {
+	return models.ArrivalAndDeparture{
+		RouteShortName:         route,
+		TripHeadsign:           headsign,
+		ScheduledDepartureTime: models.NewModelTime(scheduled),
+	}
+}
+
+func TestArrivalsSpeechSummary(t *testing.T) {
+	now := time.Date(2025, 6, 13, 11, 0, 0, 0, time.UTC)
+
+	predicted := speechArrival("40", "Downtown", now.Add(10*time.Minute))
+	predicted.Predicted = true
+	predicted.PredictedDepartureTime = models.NewModelTime(now.Add(3 * time.Minute))
+
+	arrivals := []models.ArrivalAndDeparture{
+		speechArrival("8", "Airport", now.Add(-2*time.Minute)), // already departed
+		speechArrival("40", "Downtown", now.Add(12*time.Minute)),
+		speechArrival("8", "Airport", now.Add(time.Minute)),
+		predicted,
+		speechArrival("40", "Downtown", now.Add(30*time.Minute)),
+		speechArrival("", "", now),
+	}
+	arrivals[5].RouteLongName = "Night Owl"
+
+	summary := arrivalsSpeechSummary(arrivals, now, 35*time.Minute)
+
+	assert.Equal(t, "Route Night Owl now. Route 8 to Airport in 1 minute. Route 40 to Downtown in 3 minutes, then 12 minutes.", summary)
+}
+
+func TestArrivalsSpeechSummaryLimitsRoutes(t *testing.T) {
+	now := time.Date(2025, 6, 13, 11, 0, 0, 0, time.UTC)
+	var arrivals []models.ArrivalAndDeparture
+	for i, route := range []string{"1", "2", "3", "4", "5"} {
+		arrivals = append(arrivals, speechArrival(route, "", now.Add(time.Duration(i+1)*time.Minute)))
+	}
+
+	summary := arrivalsSpeechSummary(arrivals, now, 35*time.Minute)
+
+	assert.NotContains(t, summary, "Route 5")
+	assert.Contains(t, summary, "Route 4 in 4 minutes.")
+}
+
+func TestArrivalsSpeechSummaryNoDepartures(t *testing.T) {
+	assert.Equal(t, "No departures in the next 35 minutes.", arrivalsSpeechSummary(nil, time.Now(), 35*time.Minute))
+}
+
+type arrivalsSpeechResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		Entry models.ArrivalsSpeechEntry `json:"entry"`
+	} `json:"data"`
+}
+
+func TestArrivalsAndDeparturesForStopSpeechFormat(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[arrivalsSpeechResponse](t, api,
+		arrivalsAndDeparturesURL(arrivalsTestStopID, url.Values{"format": {"speech"}}))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := model.Data.Entry
+	assert.Equal(t, arrivalsTestStopID, entry.StopID)
+	assert.NotEmpty(t, entry.StopName)
+	assert.Contains(t, entry.Text, "Route ")
+	assert.Contains(t, entry.Text, " minutes")
+}
@@ -8,10 +8,11 @@ import (
 func (api *RestAPI) validateProtectedAPIKey(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		key := r.URL.Query().Get("key")
-		if !isProtectedAPIKey(key, api.Config.ProtectedApiKeys) {
+		if !isProtectedAPIKey(key, api.CurrentConfig().ProtectedApiKeys) {
 			api.invalidAPIKeyResponse(w)
 			return
 		}
+		api.recordUsage(r)
 		next.ServeHTTP(w, r)
 	})
 }
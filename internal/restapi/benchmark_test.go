@@ -1,11 +1,16 @@
 package restapi
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
 	"maglev.onebusaway.org/internal/utils"
 )
 
@@ -120,3 +125,147 @@ func BenchmarkTripDetails(b *testing.B) {
 		mux.ServeHTTP(w, req)
 	}
 }
+
+// Benchmark trips-for-route with real-time data.
+func BenchmarkTripsForRoute(b *testing.B) {
+	api, cleanup := createTestApiWithRealTimeData(b, clock.RealClock{})
+	defer cleanup()
+
+	trip := mustGetTrip(b, api)
+	routeID := utils.FormCombinedID(testdata.Raba.ID, trip.RouteID)
+
+	mux := http.NewServeMux()
+	api.SetRoutes(mux)
+	req := httptest.NewRequest(http.MethodGet, "/api/where/trips-for-route/"+routeID+".json?key=TEST&includeStatus=true&includeSchedule=true", nil)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		b.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+	}
+}
+
+// benchmarkArrivalsResponse is an arrivals-and-departures response the size
+// of a busy stop's: 300 predicted arrivals, each with its trip status.
+func benchmarkArrivalsResponse() models.ResponseModel {
+	now := time.Date(2024, 6, 15, 8, 0, 0, 0, time.UTC)
+	arrivals := make([]models.ArrivalAndDeparture, 300)
+	for i := range arrivals {
+		at := now.Add(time.Duration(i) * time.Minute)
+		status := models.NewTripStatus()
+		status.ActiveTripID = fmt.Sprintf("1_trip%d", i)
+		status.ServiceDate = models.NewModelTime(now)
+		status.LastUpdateTime = models.NewModelTime(now)
+		status.LastLocationUpdateTime = models.NewModelTime(now)
+		status.ScheduledStartTime = models.NewModelTime(now)
+		arrivals[i] = *models.NewArrivalAndDeparture(
+			"1_route", "10", "Downtown", status.ActiveTripID, "Downtown", "1_stop", fmt.Sprintf("1_vehicle%d", i),
+			now, at, at, at.Add(time.Minute), at.Add(time.Minute), now,
+			true, true, true,
+			i, 40, 3, 0,
+			1200.5,
+			"default", "MANY_SEATS_AVAILABLE", "MANY_SEATS_AVAILABLE", "",
+			status,
+			[]string{},
+		)
+	}
+	return models.NewArrivalsAndDepartureResponse(arrivals, *models.NewEmptyReferences(), []string{}, []string{}, "1_stop", clock.RealClock{})
+}
+
+// benchmarkTripsForRouteResponse is a trips-for-route response with status
+// and schedule for 100 trips of 40 stops.
+func benchmarkTripsForRouteResponse() models.ResponseModel {
+	now := time.Date(2024, 6, 15, 8, 0, 0, 0, time.UTC)
+	entries := make([]models.TripsForRouteListEntry, 100)
+	for i := range entries {
+		stopTimes := make([]models.StopTime, 40)
+		for j := range stopTimes {
+			offset := 8*time.Hour + time.Duration(i*10+j*2)*time.Minute
+			stopTimes[j] = models.NewStopTime(offset, offset, fmt.Sprintf("1_stop%d", j), "", float64(j)*400, "")
+		}
+		status := models.NewTripStatus()
+		status.ActiveTripID = fmt.Sprintf("1_trip%d", i)
+		status.ServiceDate = models.NewModelTime(now)
+		status.LastUpdateTime = models.NewModelTime(now)
+		entries[i] = models.TripsForRouteListEntry{
+			Schedule:     &models.TripsSchedule{StopTimes: stopTimes, TimeZone: "America/Los_Angeles"},
+			Status:       status,
+			ServiceDate:  now.UnixMilli(),
+			SituationIds: []string{},
+			TripId:       status.ActiveTripID,
+		}
+	}
+	return models.NewListResponse(entries, *models.NewEmptyReferences(), false, clock.RealClock{})
+}
+
+// discardResponseWriter is a ResponseWriter that drops what is written, so
+// benchmarks measure encoding rather than a recorder's growing body.
+type discardResponseWriter struct{ header http.Header }
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+// BenchmarkResponseEncoding measures writing large list responses on their
+// own, without the work of building them. The encoder and marshal cases are
+// how sendResponse and sendJSONP wrote responses before they shared pooled
+// buffers.
+func BenchmarkResponseEncoding(b *testing.B) {
+	api := &RestAPI{}
+	req := httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_stop.json?callback=cb", nil)
+	payloads := []struct {
+		name     string
+		response models.ResponseModel
+	}{
+		{"arrivals", benchmarkArrivalsResponse()},
+		{"trips-for-route", benchmarkTripsForRouteResponse()},
+	}
+
+	for _, payload := range payloads {
+		b.Run(payload.name+"/json/encoder", func(b *testing.B) {
+			w := &discardResponseWriter{header: http.Header{}}
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := json.NewEncoder(w).Encode(payload.response); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(payload.name+"/json/writeJSON", func(b *testing.B) {
+			w := &discardResponseWriter{header: http.Header{}}
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := writeJSON(w, payload.response); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(payload.name+"/jsonp/marshal", func(b *testing.B) {
+			w := &discardResponseWriter{header: http.Header{}}
+			b.ReportAllocs()
+			for b.Loop() {
+				body, err := json.Marshal(payload.response)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, _ = w.Write([]byte("/**/cb("))
+				_, _ = w.Write(body)
+				_, _ = w.Write([]byte(");\n"))
+			}
+		})
+		b.Run(payload.name+"/jsonp/sendJSONP", func(b *testing.B) {
+			w := &discardResponseWriter{header: http.Header{}}
+			b.ReportAllocs()
+			for b.Loop() {
+				api.sendJSONP(w, req, "cb", payload.response)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package restapi
+
+import (
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// blockAssignmentsForAgencyHandler lists the agency's active blocks with the
+// trip each should be running and the realtime vehicles assigned to it.
+// Unassigned blocks come first, since they are the service dispatchers need
+// to act on.
+func (api *RestAPI) blockAssignmentsForAgencyHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := api.extractAndValidateID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+
+	agency, err := api.GtfsManager.FindAgency(ctx, id)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	if agency == nil {
+		api.sendNotFound(w, r)
+		return
+	}
+
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	referenceTime := api.Clock.Now().In(loc)
+	if timeParam := r.URL.Query().Get("time"); timeParam != "" {
+		_, parsedTime, fieldErrors, ok := utils.ParseTimeParameter(timeParam, loc)
+		if !ok {
+			api.validationErrorResponse(w, r, fieldErrors)
+			return
+		}
+		referenceTime = parsedTime
+	}
+
+	assignments, err := api.GtfsManager.GetBlockAssignments(ctx, id, referenceTime)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	list := make([]models.BlockAssignment, 0, len(assignments))
+	routeIDSet := make(map[string]struct{})
+	tripRefs := make(map[string]models.Trip, len(assignments))
+	for _, assignment := range assignments {
+		status := "layover"
+		if assignment.InProgress {
+			status = "inProgress"
+		}
+		vehicleIDs := make([]string, 0, len(assignment.VehicleIDs))
+		for _, vehicleID := range assignment.VehicleIDs {
+			vehicleIDs = append(vehicleIDs, utils.FormCombinedID(id, vehicleID))
+		}
+		list = append(list, models.BlockAssignment{
+			BlockID:        utils.FormCombinedID(id, assignment.BlockID),
+			ServiceDate:    assignment.ServiceDate.UnixMilli(),
+			ExpectedTripID: utils.FormCombinedID(id, assignment.ExpectedTripID),
+			RouteID:        utils.FormCombinedID(id, assignment.RouteID),
+			Status:         status,
+			Assigned:       len(vehicleIDs) > 0,
+			VehicleIDs:     vehicleIDs,
+		})
+		routeIDSet[assignment.RouteID] = struct{}{}
+		tripRefs[assignment.ExpectedTripID] = models.Trip{
+			ID:      utils.FormCombinedID(id, assignment.ExpectedTripID),
+			RouteID: utils.FormCombinedID(id, assignment.RouteID),
+		}
+	}
+
+	references := models.NewEmptyReferences()
+	if ShouldIncludeReferences(r) {
+		routeIDs := make([]string, 0, len(routeIDSet))
+		for routeID := range routeIDSet {
+			routeIDs = append(routeIDs, routeID)
+		}
+		routes, err := api.GtfsManager.GtfsDB.Queries.GetRoutesByIDs(ctx, routeIDs)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		routeRefs := make(map[string]models.Route, len(routes))
+		for _, route := range routes {
+			addRouteReference(routeRefs, route)
+		}
+		references.Agencies = []models.AgencyReference{models.AgencyReferenceFromDatabase(agency)}
+		for _, routeRef := range routeRefs {
+			references.Routes = append(references.Routes, routeRef)
+		}
+		for _, tripRef := range tripRefs {
+			references.Trips = append(references.Trips, tripRef)
+		}
+	}
+
+	// Every active block is listed, so limitExceeded is always false.
+	response := models.NewListResponse(list, *references, false, api.Clock)
+	api.sendResponse(w, r, response)
+}
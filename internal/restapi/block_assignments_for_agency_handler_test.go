@@ -0,0 +1,74 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestBlockAssignmentsForAgencyHandler(t *testing.T) {
+	// Monday noon in RABA's timezone, within the feed's service period.
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	now := time.Date(2024, 11, 4, 12, 0, 0, 0, loc)
+	api := createTestApiWithClock(t, clock.NewMockClock(now))
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	assignments, err := api.GtfsManager.GetBlockAssignments(context.Background(), testdata.Raba.ID, now)
+	require.NoError(t, err)
+	require.NotEmpty(t, assignments, "need active blocks at noon in test data")
+	assigned := assignments[0]
+	api.GtfsManager.MockAddVehicleWithOptions("v_block_assignment", assigned.ExpectedTripID, assigned.RouteID, gtfs.MockVehicleOptions{})
+
+	resp, model := callAPIHandler[BlockAssignmentsForAgencyResponse](t, api,
+		"/api/where/block-assignments-for-agency/"+testdata.Raba.ID+".json?key=TEST")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, model.Data.List, len(assignments))
+
+	found, seenAssigned := false, false
+	for _, entry := range model.Data.List {
+		assert.True(t, strings.HasPrefix(entry.BlockID, testdata.Raba.ID+"_"))
+		assert.Contains(t, []string{"inProgress", "layover"}, entry.Status)
+		assert.Equal(t, len(entry.VehicleIDs) > 0, entry.Assigned)
+		if entry.Assigned {
+			seenAssigned = true
+		} else {
+			assert.False(t, seenAssigned, "unassigned blocks are listed first")
+		}
+		if entry.BlockID == utils.FormCombinedID(testdata.Raba.ID, assigned.BlockID) {
+			found = true
+			assert.Equal(t, []string{utils.FormCombinedID(testdata.Raba.ID, "v_block_assignment")}, entry.VehicleIDs)
+			assert.Equal(t, utils.FormCombinedID(testdata.Raba.ID, assigned.ExpectedTripID), entry.ExpectedTripID)
+			assert.Equal(t, time.Date(2024, 11, 4, 0, 0, 0, 0, loc).UnixMilli(), entry.ServiceDate)
+		}
+	}
+	assert.True(t, found, "the block served by the mock vehicle is reported as assigned")
+	assert.NotEmpty(t, model.Data.References.Trips)
+	assert.NotEmpty(t, model.Data.References.Routes)
+}
+
+func TestBlockAssignmentsForAgencyHandler_UnknownAgency(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/block-assignments-for-agency/unknown.json?key=TEST")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestBlockAssignmentsForAgencyHandler_InvalidTime(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/block-assignments-for-agency/"+testdata.Raba.ID+".json?key=TEST&time=notatime")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
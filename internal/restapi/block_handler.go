@@ -197,16 +197,7 @@ func (api *RestAPI) getReferences(ctx context.Context, agencyID string, block []
 			continue
 		}
 		routeSet[routeID] = struct{}{}
-		routes = append(routes, models.Route{
-			ID:          routeID,
-			AgencyID:    agencyID,
-			ShortName:   route.ShortName.String,
-			LongName:    route.LongName.String,
-			Description: route.Desc.String,
-			Type:        models.RouteType(route.Type),
-			Color:       route.Color.String,
-			TextColor:   route.TextColor.String,
-		})
+		routes = append(routes, utils.RouteFromDatabase(agencyID, routeFromStopsRow(route)))
 	}
 
 	// batch fetch
@@ -33,9 +33,12 @@ type cacheControlWriter struct {
 func (w *cacheControlWriter) WriteHeader(code int) {
 	if !w.headerWritten {
 		w.headerWritten = true
-		// 304 Not Modified must preserve cache headers
+		// 304 Not Modified must preserve cache headers. A handler that set its
+		// own, such as a long poll, keeps it.
 		if (code >= 200 && code < 300) || code == http.StatusNotModified {
-			w.ResponseWriter.Header().Set("Cache-Control", w.headerValue)
+			if w.ResponseWriter.Header().Get("Cache-Control") == "" {
+				w.ResponseWriter.Header().Set("Cache-Control", w.headerValue)
+			}
 		} else {
 			w.ResponseWriter.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 		}
@@ -50,6 +53,11 @@ func (w *cacheControlWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // ETagMiddleware handles Conditional HTTP requests by comparing the incoming
 // If-None-Match header against the current system ETag.
 func ETagMiddleware(getETag func(*http.Request) string) func(http.Handler) http.Handler {
@@ -57,24 +65,11 @@ func ETagMiddleware(getETag func(*http.Request) string) func(http.Handler) http.
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			etag := getETag(r)
 			if etag != "" {
-				inm := r.Header.Get("If-None-Match")
-				// Check for wildcard or iterate through parsed comma-separated parts
-				if inm != "" {
-					if inm == "*" {
-						w.Header().Set("ETag", etag)
-						w.WriteHeader(http.StatusNotModified)
-						return
-					}
-
-					parts := strings.Split(inm, ",")
-					for _, part := range parts {
-						if strings.TrimSpace(part) == etag {
-							// RFC 7232: 304 response MUST include the ETag header
-							w.Header().Set("ETag", etag)
-							w.WriteHeader(http.StatusNotModified)
-							return
-						}
-					}
+				if etagMatches(r.Header.Get("If-None-Match"), etag) {
+					// RFC 7232: 304 response MUST include the ETag header
+					w.Header().Set("ETag", etag)
+					w.WriteHeader(http.StatusNotModified)
+					return
 				}
 				// Otherwise, attach the ETag to the response and proceed normally
 				w.Header().Set("ETag", etag)
@@ -83,3 +78,20 @@ func ETagMiddleware(getETag func(*http.Request) string) func(http.Handler) http.
 		})
 	}
 }
+
+// etagMatches reports whether an If-None-Match header value matches etag,
+// either by wildcard or as one of its comma-separated entries.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if ifNoneMatch == "*" {
+		return true
+	}
+	for _, part := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimSpace(part) == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/logging"
+	"maglev.onebusaway.org/internal/metrics"
+)
+
+// canaryProbeTimeout bounds a single probe so a hung query cannot stall the
+// canary loop.
+const canaryProbeTimeout = 10 * time.Second
+
+// CanaryResult is the outcome of the latest run of one canary probe.
+type CanaryResult struct {
+	Probe     string    `json:"probe"`
+	OK        bool      `json:"ok"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Canary periodically calls the API in-process for a configured stop and
+// route. It catches data-level breakage, such as a stop or route vanishing
+// after a feed update, that a liveness check cannot see.
+type Canary struct {
+	cfg     appconf.CanaryConfig
+	handler http.Handler
+	apiKey  string
+	metrics *metrics.Metrics
+	logger  *slog.Logger
+
+	mu      sync.RWMutex
+	results []CanaryResult
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCanary returns a canary that sends its probes to handler, authenticated
+// with apiKey. m may be nil to skip exporting probe metrics.
+func NewCanary(cfg appconf.CanaryConfig, handler http.Handler, apiKey string, m *metrics.Metrics, logger *slog.Logger) *Canary {
+	if cfg.Interval <= 0 {
+		cfg.Interval = appconf.DefaultCanaryInterval
+	}
+	return &Canary{
+		cfg:     cfg,
+		handler: handler,
+		apiKey:  apiKey,
+		metrics: m,
+		logger:  logger.With(slog.String("component", "canary")),
+	}
+}
+
+// Start runs the probes immediately and then every configured interval until
+// Shutdown is called.
+func (c *Canary) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			c.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the probe loop and waits for an in-progress run to finish.
+func (c *Canary) Shutdown() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+// Results returns the outcome of the latest run of each probe.
+func (c *Canary) Results() []CanaryResult {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]CanaryResult(nil), c.results...)
+}
+
+// RunOnce runs every configured probe and records the results.
+func (c *Canary) RunOnce(ctx context.Context) []CanaryResult {
+	var results []CanaryResult
+	if c.cfg.StopID != "" {
+		results = append(results, c.probe(ctx, "arrivals-and-departures-for-stop", func(ctx context.Context) error {
+			_, err := c.get(ctx, "arrivals-and-departures-for-stop/"+url.PathEscape(c.cfg.StopID))
+			return err
+		}))
+	}
+	if c.cfg.RouteID != "" {
+		var tripID string
+		results = append(results, c.probe(ctx, "trips-for-route", func(ctx context.Context) error {
+			data, err := c.get(ctx, "trips-for-route/"+url.PathEscape(c.cfg.RouteID))
+			if err != nil {
+				return err
+			}
+			var list struct {
+				List []struct {
+					TripID string `json:"tripId"`
+				} `json:"list"`
+			}
+			if err := json.Unmarshal(data, &list); err != nil {
+				return fmt.Errorf("unexpected response: %w", err)
+			}
+			if len(list.List) > 0 {
+				tripID = list.List[0].TripID
+			}
+			return nil
+		}))
+		// A busy route with no active trips is normal overnight, so trip-details
+		// is only probed when there is a trip to ask about.
+		if tripID != "" {
+			results = append(results, c.probe(ctx, "trip-details", func(ctx context.Context) error {
+				_, err := c.get(ctx, "trip-details/"+url.PathEscape(tripID))
+				return err
+			}))
+		}
+	}
+
+	c.mu.Lock()
+	c.results = results
+	c.mu.Unlock()
+	return results
+}
+
+func (c *Canary) probe(ctx context.Context, name string, fn func(context.Context) error) CanaryResult {
+	ctx, cancel := context.WithTimeout(ctx, canaryProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	latency := time.Since(start)
+
+	result := CanaryResult{
+		Probe:     name,
+		OK:        err == nil,
+		LatencyMs: latency.Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		logging.LogError(c.logger, "canary probe failed", err, slog.String("probe", name))
+	}
+	if c.metrics != nil {
+		success := 0.0
+		if result.OK {
+			success = 1
+		}
+		c.metrics.CanarySuccess.WithLabelValues(name).Set(success)
+		c.metrics.CanaryLatencySeconds.WithLabelValues(name).Set(latency.Seconds())
+	}
+	return result
+}
+
+// get calls an /api/where endpoint and returns the response's data when both
+// the HTTP status and the OBA response code are 200.
+func (c *Canary) get(ctx context.Context, endpoint string) (json.RawMessage, error) {
+	target := "/api/where/" + endpoint + ".json?key=" + url.QueryEscape(c.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.RemoteAddr = "127.0.0.1:0"
+
+	w := &canaryResponseWriter{header: make(http.Header), status: http.StatusOK}
+	c.handler.ServeHTTP(w, req)
+
+	var response struct {
+		Code int             `json:"code"`
+		Text string          `json:"text"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.body.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("%s returned HTTP %d with an unreadable body: %w", endpoint, w.status, err)
+	}
+	if w.status != http.StatusOK || response.Code != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP %d: %s", endpoint, w.status, response.Text)
+	}
+	return response.Data, nil
+}
+
+// canaryResponseWriter buffers an in-process response for inspection.
+type canaryResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *canaryResponseWriter) Header() http.Header { return w.header }
+
+func (w *canaryResponseWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+func (w *canaryResponseWriter) WriteHeader(status int) { w.status = status }
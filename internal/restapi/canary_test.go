@@ -0,0 +1,78 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestCanaryRunOnceSucceedsForKnownStopAndRoute(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	cfg := appconf.CanaryConfig{
+		StopID:  utils.FormCombinedID(testdata.Raba.ID, mustGetStop(t, api).ID),
+		RouteID: utils.FormCombinedID(testdata.Raba.ID, mustGetRoutes(t, api)[0].ID),
+	}
+	canary := NewCanary(cfg, api.SetupAPIRoutes(), "TEST", nil, api.Logger)
+
+	results := canary.RunOnce(context.Background())
+
+	require.GreaterOrEqual(t, len(results), 2)
+	assert.Equal(t, "arrivals-and-departures-for-stop", results[0].Probe)
+	assert.Equal(t, "trips-for-route", results[1].Probe)
+	for _, result := range results {
+		assert.True(t, result.OK, "probe %s failed: %s", result.Probe, result.Error)
+		assert.False(t, result.CheckedAt.IsZero())
+	}
+	assert.Equal(t, results, canary.Results())
+}
+
+func TestCanaryRunOnceReportsMissingStop(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	cfg := appconf.CanaryConfig{StopID: utils.FormCombinedID(testdata.Raba.ID, "does-not-exist")}
+	canary := NewCanary(cfg, api.SetupAPIRoutes(), "TEST", nil, api.Logger)
+
+	results := canary.RunOnce(context.Background())
+
+	require.Len(t, results, 1)
+	assert.False(t, results[0].OK)
+	assert.Contains(t, results[0].Error, "HTTP 404")
+}
+
+func TestHealthHandlerReportsFailingCanary(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	api.canary = NewCanary(appconf.CanaryConfig{StopID: utils.FormCombinedID(testdata.Raba.ID, "does-not-exist")}, api.SetupAPIRoutes(), "TEST", nil, api.Logger)
+	api.canary.RunOnce(context.Background())
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	api.healthHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var healthResp HealthResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&healthResp))
+	assert.Equal(t, "degraded", healthResp.Status)
+	assert.True(t, healthResp.Degraded)
+	require.Len(t, healthResp.Canary, 1)
+	assert.False(t, healthResp.Canary[0].OK)
+}
+
+func TestCanaryAPIKeyPrefersExemptKey(t *testing.T) {
+	assert.Equal(t, "exempt", canaryAPIKey(appconf.Config{ApiKeys: []string{"normal"}, ExemptApiKeys: []string{"exempt"}}))
+	assert.Equal(t, "normal", canaryAPIKey(appconf.Config{ApiKeys: []string{"normal"}}))
+	assert.Empty(t, canaryAPIKey(appconf.Config{}))
+}
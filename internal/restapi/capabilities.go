@@ -0,0 +1,98 @@
+package restapi
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"maglev.onebusaway.org/internal/buildinfo"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// Optional features reported by Capabilities. Features this server does not
+// implement are reported as disabled so clients can rely on the key existing.
+const (
+	featureRealtime         = "realtime"
+	featureVectorTiles      = "vectorTiles"
+	featureSMS              = "sms"
+	featureAPIKeyManagement = "apiKeyManagement"
+	featureAPIKeyUsage      = "apiKeyUsage"
+	featureDeveloperPortal  = "developerPortal"
+	featureGraphQL          = "graphql"
+	featureSIRI             = "siri"
+	featureStreaming        = "streaming"
+	featureDeviationHistory = "deviationHistory"
+)
+
+// Capabilities reports the server version, enabled features, the dataset
+// being served and the limits applied to request parameters.
+func (api *RestAPI) Capabilities(ctx context.Context) models.ServerCapabilities {
+	search := api.searchConfig()
+	capabilities := models.ServerCapabilities{
+		Version:    buildinfo.Version,
+		APIVersion: models.APIVersion,
+		Features: map[string]bool{
+			featureRealtime:         false,
+			featureVectorTiles:      true,
+			featureSMS:              true,
+			featureAPIKeyManagement: api.keyStore != nil,
+			featureAPIKeyUsage:      api.usage != nil,
+			featureDeveloperPortal:  api.portal != nil,
+			featureGraphQL:          false,
+			featureSIRI:             false,
+			featureStreaming:        false,
+			featureDeviationHistory: false,
+		},
+		Parameters: models.ParameterCapabilities{
+			MaxCount:              search.MaxCount,
+			DefaultMaxCountStops:  search.DefaultMaxCountStops,
+			DefaultMaxCountRoutes: search.DefaultMaxCountRoutes,
+			DefaultRadius:         search.DefaultRadius,
+			MaxRadius:             search.MaxRadius,
+		},
+	}
+
+	if api.GtfsManager != nil {
+		capabilities.Features[featureRealtime] = len(api.GtfsManager.RealtimeFeedStatuses(api.Clock.Now())) > 0
+		capabilities.Features[featureDeviationHistory] = api.GtfsManager.DeviationHistoryEnabled()
+		capabilities.Dataset.Version = api.GtfsManager.GetSystemETag(ctx)
+		capabilities.Dataset.RetainedVersions = api.GtfsManager.RetainedDatasetVersions()
+		if imported := api.GtfsManager.GetStaticLastUpdated(ctx); !imported.IsZero() {
+			capabilities.Dataset.ImportedAt = imported.UnixMilli()
+		}
+		if expires := api.GtfsManager.FeedExpiresAt(ctx); !expires.IsZero() {
+			capabilities.Dataset.ExpiresAt = expires.UnixMilli()
+		}
+	}
+	return capabilities
+}
+
+// LogStartupBanner logs the server version, the dataset being served and the
+// enabled features once the server is ready to start listening.
+func (api *RestAPI) LogStartupBanner(ctx context.Context, addr string) {
+	if api.Logger == nil {
+		return
+	}
+	capabilities := api.Capabilities(ctx)
+	var enabled []string
+	for _, feature := range slices.Sorted(maps.Keys(capabilities.Features)) {
+		if capabilities.Features[feature] {
+			enabled = append(enabled, feature)
+		}
+	}
+	attrs := []any{
+		slog.String("version", capabilities.Version),
+		slog.String("commit", buildinfo.CommitHash),
+		slog.Int("api_version", capabilities.APIVersion),
+		slog.String("addr", addr),
+		slog.String("dataset_version", capabilities.Dataset.Version),
+		slog.String("features", strings.Join(enabled, ",")),
+	}
+	if capabilities.Dataset.ExpiresAt != 0 {
+		attrs = append(attrs, slog.Time("dataset_expires_at", time.UnixMilli(capabilities.Dataset.ExpiresAt).UTC()))
+	}
+	api.Logger.Info("maglev "+capabilities.Version, attrs...)
+}
@@ -0,0 +1,111 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// coalescedResponse is a buffered handler response that can be replayed to
+// every caller that shared the computation.
+type coalescedResponse struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+// coalescingRecorder buffers a handler's response so it can be shared.
+type coalescingRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *coalescingRecorder) Header() http.Header { return rec.header }
+
+func (rec *coalescingRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+}
+
+func (rec *coalescingRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(b)
+}
+
+// coalesced collapses identical concurrent requests into a single execution
+// of handler. Departure boards frequently poll the same stop at the same
+// moment; the first request computes the response and the others wait for it
+// and receive a copy. Requests are identical when their method, path and
+// query parameters match, ignoring the API key, and they prefer the same
+// language for situations. It runs inside rateLimitAndValidateAPIKey so every
+// caller is still authenticated and counted against its own rate limit.
+func coalesced(api *RestAPI, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		v, _, _ := api.requestGroup.Do(coalescingKey(r), func() (any, error) {
+			// The shared computation must not be aborted because the caller that
+			// happened to start it disconnected while others are still waiting.
+			rec := &coalescingRecorder{header: make(http.Header)}
+			handler(rec, r.WithContext(context.WithoutCancel(r.Context())))
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			return &coalescedResponse{header: rec.header, status: rec.status, body: rec.body.Bytes()}, nil
+		})
+
+		resp := v.(*coalescedResponse)
+		for name, values := range resp.header {
+			w.Header()[name] = slices.Clone(values)
+		}
+		w.WriteHeader(resp.status)
+		_, _ = w.Write(resp.body)
+	}
+}
+
+// coalescingKey normalizes a request into its coalescing key: method, path and
+// query parameters sorted by name, without the API key. Repeated values of a
+// parameter keep their order, since it may be significant. The language
+// situations are translated to is part of the key, and so is the region the
+// request was routed to, whose prefix is no longer in the path. Requests for protobuf
+// responses get their own key, as they are encoded differently, and so do .pb
+// requests, which fail where an Accept header falls back to JSON.
+func coalescingKey(r *http.Request) string {
+	query := r.URL.Query()
+	query.Del("key")
+
+	var sb strings.Builder
+	sb.WriteString(r.Method)
+	sb.WriteByte(' ')
+	sb.WriteString(r.URL.Path)
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range query[name] {
+			sb.WriteByte('&')
+			sb.WriteString(url.QueryEscape(name))
+			sb.WriteByte('=')
+			sb.WriteString(url.QueryEscape(value))
+		}
+	}
+	if region := requestRegion(r.Context()); region != "" {
+		sb.WriteString(" region=")
+		sb.WriteString(region)
+	}
+	if lang := requestLanguage(r); lang != "" {
+		sb.WriteString(" lang=")
+		sb.WriteString(lang)
+	}
+	if req, ok := requestedProtobuf(r); ok {
+		sb.WriteString(" protobuf")
+		if req.explicit {
+			sb.WriteString(" explicit")
+		}
+	}
+	return sb.String()
+}
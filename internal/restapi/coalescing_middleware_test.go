@@ -0,0 +1,160 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalescingKey(t *testing.T) {
+	key := func(target string) string {
+		return coalescingKey(httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	base := key("/api/where/arrivals-and-departures-for-stop/1_75403.json?key=TEST&minutesBefore=5&minutesAfter=35")
+	assert.Equal(t, base, key("/api/where/arrivals-and-departures-for-stop/1_75403.json?minutesAfter=35&minutesBefore=5&key=OTHER"),
+		"parameter order and API key must not affect the key")
+	assert.NotEqual(t, base, key("/api/where/arrivals-and-departures-for-stop/1_75403.json?key=TEST&minutesBefore=5&minutesAfter=60"))
+	assert.NotEqual(t, base, key("/api/where/arrivals-and-departures-for-stop/1_75404.json?key=TEST&minutesBefore=5&minutesAfter=35"))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_75403.json?key=TEST&minutesBefore=5&minutesAfter=35", nil)
+	r.Header.Set("Accept-Language", "fr-CA, en;q=0.5")
+	assert.NotEqual(t, base, coalescingKey(r), "situations are translated to the preferred language")
+}
+
+// TestCoalesced_SharesConcurrentIdenticalRequests holds the first request in
+// the handler until every other caller is waiting on it, then checks that they
+// all received its response without running the handler again.
+func TestCoalesced_SharesConcurrentIdenticalRequests(t *testing.T) {
+	api := &RestAPI{}
+	const callers = 5
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := coalesced(api, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"code":202}`))
+	})
+
+	recorders := make([]*httptest.ResponseRecorder, callers)
+	var wg sync.WaitGroup
+	serve := func(i int, apiKey string) {
+		defer wg.Done()
+		recorders[i] = httptest.NewRecorder()
+		handler(recorders[i], httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.json?key="+apiKey, nil))
+	}
+
+	wg.Add(1)
+	go serve(0, "TEST")
+	<-started
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go serve(i, "OTHER")
+	}
+	// Give the followers time to join the in-flight call before releasing it.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, rec := range recorders {
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"code":202}`, rec.Body.String())
+	}
+}
+
+// TestCoalesced_SeparatesLanguages holds an English request in the handler
+// while a French one for the same URL arrives, and checks that each got the
+// response in its own language.
+func TestCoalesced_SeparatesLanguages(t *testing.T) {
+	api := &RestAPI{}
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := coalesced(api, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		_, _ = w.Write([]byte(requestLanguage(r)))
+	})
+
+	languages := []string{"en", "fr"}
+	recorders := make([]*httptest.ResponseRecorder, len(languages))
+	var wg sync.WaitGroup
+	serve := func(i int) {
+		defer wg.Done()
+		req := httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.json?key=TEST", nil)
+		req.Header.Set("Accept-Language", languages[i])
+		recorders[i] = httptest.NewRecorder()
+		handler(recorders[i], req)
+	}
+
+	wg.Add(1)
+	go serve(0)
+	<-started
+	wg.Add(1)
+	go serve(1)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), calls.Load())
+	for i, rec := range recorders {
+		assert.Equal(t, languages[i], rec.Body.String())
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Language")
+	}
+}
+
+func TestCoalesced_SequentialRequestsRunSeparately(t *testing.T) {
+	api := &RestAPI{}
+	var calls atomic.Int32
+	handler := coalesced(api, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	})
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.json?key=TEST", nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "a handler that writes nothing still responds 200")
+	}
+	assert.Equal(t, int32(2), calls.Load(), "only concurrent requests are coalesced")
+}
+
+func TestCoalesced_LeaderCancellationDoesNotAbortSharedWork(t *testing.T) {
+	api := &RestAPI{}
+	handler := coalesced(api, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.Context().Err())
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.json?key=TEST", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestArrivalsEndpointIsCoalescedThroughMux(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api, "/api/where/arrivals-and-departures-for-stop/"+arrivalsTestStopID+".json?key=TEST")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, model.Code)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
@@ -1,45 +1,253 @@
 package restapi
 
 import (
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 
-	"github.com/klauspost/compress/gzhttp"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"maglev.onebusaway.org/internal/appconf"
 )
 
-// CompressionConfig holds configuration options for response compression
-type CompressionConfig struct {
-	// MinSize is the minimum response size in bytes to compress (default: 1024)
-	MinSize int
-	// Level is the compression level 1-9 (default: 6 for balanced speed/compression)
-	Level int
+// compressWriter is the part of gzip.Writer and flate.Writer the middleware
+// uses, so encoders can be pooled and reused across responses.
+type compressWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// contentEncoding is a content coding the middleware can produce.
+type contentEncoding struct {
+	name      string
+	newWriter func(level int) (compressWriter, error)
+}
+
+// contentEncodings lists the supported codings, most preferred first. It
+// decides between codings a client accepts with the same quality.
+var contentEncodings = []contentEncoding{
+	{name: "gzip", newWriter: func(level int) (compressWriter, error) { return gzip.NewWriterLevel(io.Discard, level) }},
+	{name: "deflate", newWriter: func(level int) (compressWriter, error) { return flate.NewWriter(io.Discard, level) }},
 }
 
 // DefaultCompressionConfig returns sensible defaults for compression
-func DefaultCompressionConfig() CompressionConfig {
-	return CompressionConfig{
-		MinSize: 1024, // 1KB minimum
-		Level:   6,    // Balanced compression
-	}
+func DefaultCompressionConfig() appconf.CompressionConfig {
+	return appconf.CompressionConfig{}.WithDefaults()
 }
 
-// NewCompressionMiddleware creates a compression middleware with the given configuration
-func NewCompressionMiddleware(config CompressionConfig) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		// Configure gzhttp with our settings
-		wrapper, err := gzhttp.NewWrapper(
-			gzhttp.MinSize(config.MinSize),
-			gzhttp.CompressionLevel(config.Level),
-		)
-		if err != nil {
-			// Fallback to default if configuration fails
-			return gzhttp.GzipHandler(next)
+// NewCompressionMiddleware creates a compression middleware with the given
+// configuration. The coding is negotiated from Accept-Encoding, and responses
+// below config.MinSize or already carrying a Content-Encoding are sent as is.
+// Encoders are pooled per coding.
+func NewCompressionMiddleware(config appconf.CompressionConfig) func(http.Handler) http.Handler {
+	config = config.WithDefaults()
+	pools := make(map[string]*sync.Pool, len(contentEncodings))
+	for _, encoding := range contentEncodings {
+		level := config.Level
+		if _, err := encoding.newWriter(level); err != nil {
+			// Fall back to the default level if the configured one is rejected
+			level = appconf.DefaultCompressionLevel
 		}
-		return wrapper(next)
+		pools[encoding.name] = &sync.Pool{New: func() any {
+			w, _ := encoding.newWriter(level)
+			return w
+		}}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressingResponseWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				pool:           pools[encoding],
+				minSize:        config.MinSize,
+			}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
 	}
 }
 
-// CompressionMiddleware applies gzip compression with default settings
+// CompressionMiddleware applies compression with default settings
 func CompressionMiddleware(next http.Handler) http.Handler {
-	config := DefaultCompressionConfig()
-	return NewCompressionMiddleware(config)(next)
+	return NewCompressionMiddleware(DefaultCompressionConfig())(next)
+}
+
+// negotiateEncoding picks the supported coding with the highest quality in an
+// Accept-Encoding header, or "" when the response should not be compressed. A
+// "*" entry applies to the codings not listed by name.
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+		} else if name != "" {
+			qualities[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range contentEncodings {
+		q, ok := qualities[encoding.name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = encoding.name, q
+		}
+	}
+	return best
+}
+
+// compressingResponseWriter buffers the start of a response until it is known
+// to reach minSize, then streams the rest through a pooled encoder. Smaller
+// responses are written through unchanged when the handler returns.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	minSize  int
+
+	status  int    // status held back until compression is decided
+	buf     []byte // body held back until compression is decided
+	decided bool
+	encoder compressWriter // non-nil once compressing
+}
+
+func (w *compressingResponseWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	// Responses without a body, and those the handler encoded itself, are
+	// never compressed.
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified ||
+		w.Header().Get("Content-Encoding") != "" {
+		w.passThrough()
+	}
+}
+
+func (w *compressingResponseWriter) Write(b []byte) (int, error) {
+	if !w.decided && w.Header().Get("Content-Encoding") != "" {
+		w.passThrough()
+	}
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush compresses whatever has been buffered so far, since a handler that
+// flushes expects its output to reach the client.
+func (w *compressingResponseWriter) Flush() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			w.passThrough()
+		} else if err := w.startCompression(); err != nil {
+			return
+		}
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressingResponseWriter) startCompression() error {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" {
+		// Sniff from the uncompressed body, as net/http would have
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", w.encoding)
+	w.ResponseWriter.WriteHeader(w.statusOrOK())
+
+	w.encoder = w.pool.Get().(compressWriter)
+	w.encoder.Reset(w.ResponseWriter)
+	buf := w.buf
+	w.buf = nil
+	_, err := w.encoder.Write(buf)
+	return err
+}
+
+// passThrough sends the held back status and body uncompressed.
+func (w *compressingResponseWriter) passThrough() {
+	w.decided = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(w.buf) > 0 {
+		buf := w.buf
+		w.buf = nil
+		_, _ = w.ResponseWriter.Write(buf)
+	}
+}
+
+func (w *compressingResponseWriter) statusOrOK() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// close finishes the response once the handler has returned and returns the
+// encoder to its pool.
+func (w *compressingResponseWriter) close() {
+	if !w.decided {
+		w.passThrough()
+		return
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.pool.Put(w.encoder)
+		w.encoder = nil
+	}
 }
@@ -0,0 +1,186 @@
+package restapi
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"br", ""},
+		{"gzip, deflate, br", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", "gzip"},
+		{"gzip;q=0, *", "deflate"},
+		{"GZIP", "gzip"},
+		{"gzip;q=bad, deflate;q=0.1", "deflate"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, negotiateEncoding(tt.acceptEncoding), "Accept-Encoding %q", tt.acceptEncoding)
+	}
+}
+
+func TestCompressionMiddleware_Deflate(t *testing.T) {
+	body := strings.Repeat(`{"test": "data"}`, 1000)
+	handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "deflate")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	decompressed, err := io.ReadAll(flate.NewReader(rr.Body))
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+}
+
+func TestCompressionMiddleware_MinSize(t *testing.T) {
+	middleware := NewCompressionMiddleware(appconf.CompressionConfig{MinSize: 100})
+	serve := func(body string, writes int) *httptest.ResponseRecorder {
+		handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for range writes {
+				_, _ = w.Write([]byte(body))
+			}
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	small := serve(strings.Repeat("a", 99), 1)
+	assert.Empty(t, small.Header().Get("Content-Encoding"))
+	assert.Equal(t, strings.Repeat("a", 99), small.Body.String())
+
+	// The threshold applies to the whole body, not to single writes
+	large := serve(strings.Repeat("a", 10), 10)
+	assert.Equal(t, "gzip", large.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/plain; charset=utf-8", large.Header().Get("Content-Type"))
+	reader, err := gzip.NewReader(large.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", 100), string(decompressed))
+}
+
+func TestCompressionMiddleware_KeepsStatusAndExistingEncoding(t *testing.T) {
+	body := strings.Repeat("x", 2048)
+
+	t.Run("status is preserved", func(t *testing.T) {
+		handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(body))
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("already encoded responses pass through", func(t *testing.T) {
+		handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte(body))
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, "br", rr.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, rr.Body.String())
+	})
+}
+
+// TestCompressedResponsesDecodeToSameModels fetches large API responses with
+// each supported coding and checks they decode to the same data as the
+// uncompressed response.
+func TestCompressedResponsesDecodeToSameModels(t *testing.T) {
+	// stops-for-location only finds stops with service on the clock's date
+	api := createTestApiWithClock(t, clock.NewMockClock(time.Date(2025, 12, 26, 14, 0, 0, 0, time.UTC)))
+	defer api.Shutdown()
+
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+	// Keep the transport from transparently decompressing gzip
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	fetch := func(t *testing.T, endpoint, acceptEncoding string) models.ResponseModel {
+		req, err := http.NewRequest(http.MethodGet, server.URL+endpoint, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body io.Reader = resp.Body
+		switch resp.Header.Get("Content-Encoding") {
+		case "gzip":
+			reader, err := gzip.NewReader(resp.Body)
+			require.NoError(t, err)
+			body = reader
+		case "deflate":
+			body = flate.NewReader(resp.Body)
+		case "":
+		default:
+			t.Fatalf("unexpected Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+		}
+		if acceptEncoding != "identity" {
+			assert.Equal(t, acceptEncoding, resp.Header.Get("Content-Encoding"))
+		}
+
+		var model models.ResponseModel
+		require.NoError(t, json.NewDecoder(body).Decode(&model))
+		return model
+	}
+
+	// The exempt key keeps the repeated requests clear of the test rate limit
+	endpoints := []string{
+		"/api/where/stops-for-agency/" + testdata.Raba.ID + ".json?key=org.onebusaway.iphone",
+		"/api/where/stops-for-location.json?key=org.onebusaway.iphone&lat=40.583321&lon=-122.426966&radius=2500",
+	}
+	for _, endpoint := range endpoints {
+		t.Run(endpoint, func(t *testing.T) {
+			want := fetch(t, endpoint, "identity")
+			require.Equal(t, http.StatusOK, want.Code)
+			for _, encoding := range []string{"gzip", "deflate"} {
+				got := fetch(t, endpoint, encoding)
+				assert.Equal(t, want.Code, got.Code)
+				assert.Equal(t, want.Data, got.Data, "%s response decoded to different data", encoding)
+			}
+		})
+	}
+}
@@ -7,12 +7,10 @@ import (
 	"maglev.onebusaway.org/internal/models"
 )
 
-// configHandler returns server configuration metadata, including the Git commit hash and build info.
+// configHandler returns server configuration metadata, including the Git
+// commit hash, build info and the capabilities clients can feature-detect.
 func (api *RestAPI) configHandler(w http.ResponseWriter, r *http.Request) {
-	shortHash := "unknown"
-	if len(buildinfo.CommitHash) >= 7 {
-		shortHash = buildinfo.CommitHash[:7]
-	}
+	shortHash := buildinfo.ShortCommit()
 
 	gitProps := models.GitProperties{
 		GitBranch:                buildinfo.Branch,
@@ -40,6 +38,7 @@ func (api *RestAPI) configHandler(w http.ResponseWriter, r *http.Request) {
 		Name:            "OneBusAway Go",
 		ServiceDateFrom: "",
 		ServiceDateTo:   "",
+		Capabilities:    api.Capabilities(r.Context()),
 	}
 
 	response := models.NewEntryResponse(
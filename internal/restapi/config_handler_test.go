@@ -1,11 +1,15 @@
 package restapi
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
 	"maglev.onebusaway.org/internal/buildinfo"
+	"maglev.onebusaway.org/internal/models"
 )
 
 func TestConfigHandler(t *testing.T) {
@@ -41,3 +45,31 @@ func TestConfigHandler(t *testing.T) {
 	assert.Equal(t, "1.0.0-test", gitProps["git.build.version"])
 	assert.Equal(t, "feature/testing", gitProps["git.branch"])
 }
+
+func TestConfigHandlerReportsCapabilities(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api, "/api/where/config.json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	entry := model.Data.(map[string]any)["entry"].(map[string]any)
+	capabilities, ok := entry["capabilities"].(map[string]any)
+	require.True(t, ok, "capabilities are reported")
+	assert.Equal(t, buildinfo.Version, capabilities["version"])
+	assert.Equal(t, float64(models.APIVersion), capabilities["apiVersion"])
+
+	features := capabilities["features"].(map[string]any)
+	for _, feature := range []string{featureGraphQL, featureSIRI, featureStreaming} {
+		assert.Equal(t, false, features[feature], feature)
+	}
+	assert.Equal(t, true, features[featureVectorTiles])
+
+	dataset := capabilities["dataset"].(map[string]any)
+	assert.Equal(t, api.GtfsManager.GetSystemETag(context.Background()), dataset["version"])
+	assert.NotZero(t, dataset["importedAt"])
+
+	parameters := capabilities["parameters"].(map[string]any)
+	assert.Equal(t, float64(appconf.DefaultSearchMaxAllowedCount), parameters["maxCount"])
+	assert.Equal(t, float64(appconf.DefaultMaxSearchRadius), parameters["maxRadius"])
+}
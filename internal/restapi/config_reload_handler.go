@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"errors"
+	"net/http"
+
+	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// reloadConfigHandler re-reads the config file and applies its API keys, rate
+// limits and realtime feeds without restarting the server.
+func (api *RestAPI) reloadConfigHandler(w http.ResponseWriter, r *http.Request) {
+	previous := api.CurrentConfig()
+	restartRequired, err := api.ReloadConfig()
+	if err != nil {
+		if errors.Is(err, app.ErrConfigNotReloadable) {
+			api.sendError(w, r, http.StatusConflict, err.Error())
+			return
+		}
+		api.Logger.Error("failed to reload configuration", "error", err)
+		api.sendError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	current := api.CurrentConfig()
+	recordAuditChange(r, api.ConfigPath, configReloadSummary(previous), configReloadSummary(current))
+
+	result := models.ConfigReload{RestartRequired: restartRequired}
+	response := models.NewEntryResponse(result, *models.NewEmptyReferences(), api.Clock)
+	api.sendResponse(w, r, response)
+}
+
+// configReloadSummary describes the reloadable settings for the audit log
+// without recording the keys themselves.
+func configReloadSummary(cfg appconf.Config) map[string]int {
+	return map[string]int{"rateLimit": cfg.RateLimit, "apiKeys": len(cfg.ApiKeys)}
+}
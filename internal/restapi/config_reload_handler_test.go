@@ -0,0 +1,69 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type configReloadResponse struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+	Data struct {
+		Entry models.ConfigReload `json:"entry"`
+	} `json:"data"`
+}
+
+func TestReloadConfigRequiresProtectedApiKey(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[configReloadResponse](t, api, "/api/where/reload-config.json?key=TEST")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestReloadConfigWithoutConfigFile(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[configReloadResponse](t, api, "/api/where/reload-config.json?key=PROTECTED-TEST")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Contains(t, model.Text, "not loaded from a file")
+}
+
+func TestReloadConfigAppliesKeysAndRateLimit(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	// The realtime feed is disabled so the shared test manager starts no poller.
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"env": "test",
+		"api-keys": ["TEST", "reloaded-key"],
+		"protected-api-keys": ["PROTECTED-TEST"],
+		"exempt-api-keys": ["reloaded-key"],
+		"rate-limit": 1,
+		"gtfs-rt-feeds": [{"trip-updates-url": "http://127.0.0.1:1/tu", "enabled": false}]
+	}`), 0o600))
+	api.ConfigPath = path
+
+	resp, _ := callAPIHandler[configReloadResponse](t, api, "/api/where/current-time.json?key=reloaded-key")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the key is unknown before the reload")
+
+	resp, model := callAPIHandler[configReloadResponse](t, api, "/api/where/reload-config.json?key=PROTECTED-TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, model.Data.Entry.RestartRequired, "the port and log settings differ from the test config")
+
+	for range 3 {
+		resp, _ = callAPIHandler[configReloadResponse](t, api, "/api/where/current-time.json?key=reloaded-key")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "reloaded exempt key bypasses the new limit")
+	}
+	assert.Equal(t, 1, api.rateLimiter.Status(context.Background(), "TEST", netip.Addr{}).Limit)
+}
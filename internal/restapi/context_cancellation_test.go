@@ -2,6 +2,7 @@ package restapi
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,7 +10,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/clock"
 	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/restapi/testdata"
 )
 
 func TestContextCancellationHandling(t *testing.T) {
@@ -167,3 +171,48 @@ func TestContextCancellationDuringDatabaseQueries(t *testing.T) {
 		}
 	})
 }
+
+func TestContextCancellationInRealtimeCalculations(t *testing.T) {
+	api, cleanup := createTestApiWithRealTimeData(t, clock.RealClock{})
+	defer cleanup()
+	api.Logger = slog.New(slog.DiscardHandler)
+
+	trip := mustGetTrip(t, api)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("trip status stops at the first check", func(t *testing.T) {
+		now := time.Now()
+		status, err := api.BuildTripStatus(ctx, testdata.Raba.ID, trip.ID, nil, now, now)
+
+		assert.Nil(t, status)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("scheduled distance falls back to the actual one", func(t *testing.T) {
+		stopTimes := []gtfsdb.StopTime{
+			{StopID: "a", ArrivalTime: secondsToNanos(0), DepartureTime: secondsToNanos(0)},
+			{StopID: "b", ArrivalTime: secondsToNanos(600), DepartureTime: secondsToNanos(600)},
+		}
+		now := time.Now()
+
+		d := api.calculateEffectiveDistanceAlongTrip(ctx, 42, 60, now, now, stopTimes, nil, nil)
+
+		assert.Equal(t, 42.0, d)
+	})
+
+	t.Run("realtime endpoints write nothing for a disconnected client", func(t *testing.T) {
+		for _, endpoint := range []string{
+			"/api/where/trips-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&latSpan=0.1&lonSpan=0.1&includeStatus=true",
+			"/api/where/trips-for-route/" + testdata.Raba.ID + "_" + trip.RouteID + ".json?key=TEST&includeStatus=true",
+			"/api/where/trip-details/" + testdata.Raba.ID + "_" + trip.ID + ".json?key=TEST",
+		} {
+			req := httptest.NewRequest(http.MethodGet, endpoint, nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			api.SetupAPIRoutes().ServeHTTP(w, req)
+
+			assert.Empty(t, w.Body.String(), endpoint)
+		}
+	})
+}
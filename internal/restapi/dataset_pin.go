@@ -0,0 +1,26 @@
+package restapi
+
+import (
+	"net/http"
+)
+
+// pinnedDataset serves handler with the static dataset being served pinned
+// to the request, so that a reload committing meanwhile cannot leave its
+// response with some entities from the old dataset and some from the new.
+// A pin holds a database connection from the request's first read until it
+// is served, so it is only for handlers that assemble a response, and its
+// references, from many queries; handlers that write, such as the problem
+// reports, must not be pinned. SetRoutes says which endpoints are. Inside
+// longPolled it pins each response a long poll computes rather than the
+// whole wait.
+func pinnedDataset(api *RestAPI, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.GtfsManager == nil || api.GtfsManager.GtfsDB == nil {
+			handler(w, r)
+			return
+		}
+		ctx, release := api.GtfsManager.GtfsDB.Pin(r.Context())
+		defer release()
+		handler(w, r.WithContext(ctx))
+	}
+}
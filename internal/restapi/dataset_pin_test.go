@@ -0,0 +1,155 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// createPinTestApi creates an API on a database file of its own, which tests
+// may write to and whose connection pool they may shrink.
+func createPinTestApi(t *testing.T) *RestAPI {
+	t.Helper()
+	manager, err := gtfs.InitGTFSManager(context.Background(), gtfs.Config{
+		GtfsURL:      filepath.Join("../../testdata", "raba.zip"),
+		GTFSDataPath: filepath.Join(t.TempDir(), "gtfs.db"),
+		Env:          appconf.Development,
+	})
+	require.NoError(t, err)
+	t.Cleanup(manager.Shutdown)
+
+	api := NewRestAPI(&app.Application{
+		Config: appconf.Config{
+			Env:       appconf.EnvFlagToEnvironment("test"),
+			ApiKeys:   []string{"TEST"},
+			RateLimit: 100,
+		},
+		GtfsManager:         manager,
+		DirectionCalculator: gtfs.NewAdvancedDirectionCalculator(manager.GtfsDB.Queries),
+		Clock:               clock.RealClock{},
+		Logger:              slog.Default(),
+	})
+	t.Cleanup(api.Shutdown)
+	return api
+}
+
+func TestPinnedDatasetReadsOneDataset(t *testing.T) {
+	api := createPinTestApi(t)
+	db := api.GtfsManager.GtfsDB
+
+	countAgencies := func(r *http.Request) int {
+		agencies, err := db.Queries.ListAgencies(r.Context())
+		require.NoError(t, err)
+		return len(agencies)
+	}
+	addAgency := func(id string) {
+		_, err := db.Queries.CreateAgency(context.Background(), gtfsdb.CreateAgencyParams{
+			ID: id, Name: id, Url: "https://example.com", Timezone: "UTC",
+		})
+		require.NoError(t, err)
+	}
+
+	var before, after int
+	handler := func(id string) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			before = countAgencies(r)
+			addAgency(id)
+			after = countAgencies(r)
+		}
+	}
+
+	pinnedDataset(api, handler("pinned"))(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, before, after, "a pinned request does not see a commit made while it is served")
+
+	handler("unpinned")(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, before+1, after, "an unpinned request does")
+	assert.Zero(t, db.DB.Stats().InUse, "pins give their connections back")
+}
+
+func TestPinnedDatasetSurvivesReload(t *testing.T) {
+	api := createPinTestApi(t)
+	manager := api.GtfsManager
+
+	// dataset describes the dataset a context reads by its agencies and how
+	// many routes it has.
+	dataset := func(ctx context.Context) string {
+		agencies, err := manager.GtfsDB.Queries.ListAgencies(ctx)
+		require.NoError(t, err)
+		routes, err := manager.GtfsDB.Queries.ListRoutes(ctx)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(agencies))
+		for _, agency := range agencies {
+			ids = append(ids, agency.ID)
+		}
+		return fmt.Sprintf("agencies %v, %d routes", ids, len(routes))
+	}
+	raba := dataset(context.Background())
+
+	var before, after string
+	reloaded := make(chan error, 1)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		before = dataset(r.Context())
+		manager.SetGtfsURL(filepath.Join("../../testdata", "gtfs.zip"))
+		go func() {
+			_, err := manager.ReloadStatic(context.Background())
+			reloaded <- err
+		}()
+		// The reload waits for this request once it has committed, which
+		// unpinned reads see.
+		require.Eventually(t, func() bool { return dataset(context.Background()) != raba }, 30*time.Second, 10*time.Millisecond)
+		after = dataset(r.Context())
+	}
+	pinnedDataset(api, handler)(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, <-reloaded)
+
+	assert.Equal(t, raba, before)
+	assert.Equal(t, raba, after, "a pinned request reads one dataset across a reload")
+	assert.NotEqual(t, raba, dataset(context.Background()), "the reload replaced the dataset")
+	assert.Zero(t, manager.GtfsDB.PinFailures())
+}
+
+func TestPinnedEndpointWaitsForConnectionWithinRequest(t *testing.T) {
+	api := createPinTestApi(t)
+	db := api.GtfsManager.GtfsDB.DB
+	mux := api.SetupAPIRoutes()
+	agency := mustGetAgencies(t, api)[0]
+	path := "/api/where/trip-details/" + utils.FormCombinedID(agency.ID, mustGetTrip(t, api).ID) + ".json?key=TEST"
+
+	// Every connection is taken, so the pin of the request cannot begin.
+	db.SetMaxOpenConns(1)
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request kept waiting for a connection after its context ended")
+	}
+
+	require.NoError(t, conn.Close())
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Zero(t, db.Stats().InUse, "the pin gives its connection back")
+}
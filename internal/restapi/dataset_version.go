@@ -0,0 +1,78 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"maglev.onebusaway.org/internal/gtfs"
+)
+
+// datasetVersionParam asks a static endpoint for an earlier dataset. It is
+// not called version because that parameter is the API version.
+const datasetVersionParam = "datasetVersion"
+
+// datasetViews holds an API over each retained dataset that has been asked
+// for, keyed by version.
+type datasetViews struct {
+	mu    sync.Mutex
+	views map[string]*RestAPI
+}
+
+// datasetView returns the API that answers for the dataset version: api
+// itself for the dataset being served, otherwise an API over the retained
+// snapshot. Errors wrap gtfs.ErrUnknownDatasetVersion for versions that are
+// not retained.
+func (api *RestAPI) datasetView(ctx context.Context, version string) (*RestAPI, error) {
+	if version == api.GtfsManager.GetSystemETag(ctx) {
+		return api, nil
+	}
+	snapshot, err := api.GtfsManager.DatasetSnapshot(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &api.datasetViews
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if view := d.views[version]; view != nil && view.GtfsManager == snapshot {
+		view.suppressed.Store(api.suppressed.Load())
+		return view, nil
+	}
+	view := &RestAPI{
+		Application:   api.WithGtfsManager(snapshot, gtfs.NewAdvancedDirectionCalculator(snapshot.GtfsDB.Queries)),
+		responseCache: newResponseCache(maxCachedResponses),
+		regions:       api.regions,
+	}
+	view.suppressed.Store(api.suppressed.Load())
+	if d.views == nil {
+		d.views = make(map[string]*RestAPI)
+	}
+	d.views[version] = view
+	return view, nil
+}
+
+// forDatasetVersion resolves the datasetVersion parameter of r to the API
+// that answers for it. Unknown versions are answered 404 with the retained
+// ones, and ok is false.
+func (api *RestAPI) forDatasetVersion(w http.ResponseWriter, r *http.Request) (view *RestAPI, ok bool) {
+	version := r.URL.Query().Get(datasetVersionParam)
+	if version == "" {
+		return api, true
+	}
+	view, err := api.datasetView(r.Context(), version)
+	if errors.Is(err, gtfs.ErrUnknownDatasetVersion) {
+		versions := append([]string{api.GtfsManager.GetSystemETag(r.Context())}, api.GtfsManager.RetainedDatasetVersions()...)
+		api.sendError(w, r, http.StatusNotFound,
+			fmt.Sprintf("unknown dataset version %q; this server has versions %s", version, strings.Join(versions, ", ")))
+		return nil, false
+	}
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+	return view, true
+}
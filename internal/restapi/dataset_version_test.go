@@ -0,0 +1,87 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/gtfs"
+)
+
+func TestDatasetVersionParameter(t *testing.T) {
+	ctx := context.Background()
+	manager, err := gtfs.InitGTFSManager(ctx, gtfs.Config{
+		GtfsURL:          filepath.Join("../../testdata", "raba.zip"),
+		GTFSDataPath:     filepath.Join(t.TempDir(), "gtfs.db"),
+		Env:              appconf.Development,
+		DatasetSnapshots: 2,
+	})
+	require.NoError(t, err)
+	defer manager.Shutdown()
+	raba := manager.GetSystemETag(ctx)
+
+	manager.SetGtfsURL(filepath.Join("../../testdata", "gtfs.zip"))
+	_, err = manager.ReloadStatic(ctx)
+	require.NoError(t, err)
+	current := manager.GetSystemETag(ctx)
+
+	api := NewRestAPI(&app.Application{
+		Config: appconf.Config{
+			Env:       appconf.EnvFlagToEnvironment("test"),
+			ApiKeys:   []string{"TEST"},
+			RateLimit: 100,
+		},
+		GtfsManager:         manager,
+		DirectionCalculator: gtfs.NewAdvancedDirectionCalculator(manager.GtfsDB.Queries),
+		Clock:               clock.RealClock{},
+	})
+	defer api.Shutdown()
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, AgencyEntryResponse) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var body AgencyEntryResponse
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+
+	t.Run("serves the current dataset by default", func(t *testing.T) {
+		resp, _ := get(t, "/api/where/agency/25.json?key=TEST")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "raba's agency was replaced by the reload")
+	})
+
+	t.Run("serves a retained dataset", func(t *testing.T) {
+		resp, body := get(t, "/api/where/agency/25.json?key=TEST&datasetVersion="+raba)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "25", body.Data.Entry.ID)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("ETag"), raba+"-"), "the agency's ETag is the dataset's and the hour's")
+	})
+
+	t.Run("accepts the current version", func(t *testing.T) {
+		resp, _ := get(t, "/api/where/agency/40.json?key=TEST&datasetVersion="+current)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("ETag"), current+"-"))
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		resp, body := get(t, "/api/where/agency/25.json?key=TEST&datasetVersion=nope")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Contains(t, body.Text, raba)
+	})
+
+	capabilities := api.Capabilities(ctx)
+	assert.Equal(t, []string{raba}, capabilities.Dataset.RetainedVersions)
+}
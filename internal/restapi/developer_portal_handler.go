@@ -0,0 +1,104 @@
+package restapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/portal"
+)
+
+// developerSignupHandler starts a self-service signup by emailing a
+// verification link to the given address. It needs no API key.
+func (api *RestAPI) developerSignupHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	err := api.portal.Signup(r.Context(), query.Get("email"), query.Get("name"))
+	switch {
+	case errors.Is(err, portal.ErrInvalidEmail):
+		api.validationErrorResponse(w, r, map[string][]string{"email": {err.Error()}})
+	case errors.Is(err, portal.ErrTooManyKeys):
+		api.sendError(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		api.serverErrorResponse(w, r, err)
+	default:
+		api.sendResponse(w, r, models.NewOKResponse(struct{}{}, api.Clock))
+	}
+}
+
+// developerVerifyHandler is the target of the emailed verification link. It
+// activates the signup and returns the new key, which is not shown again.
+func (api *RestAPI) developerVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	key, err := api.portal.Verify(r.Context(), r.URL.Query().Get("token"))
+	if errors.Is(err, portal.ErrInvalidToken) {
+		api.sendError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	api.sendResponse(w, r, models.NewEntryResponse(key, *models.NewEmptyReferences(), api.Clock))
+}
+
+// developerQuotaRequestHandler lets the holder of a portal key ask for the
+// elevated tier. The request waits for an admin decision.
+func (api *RestAPI) developerQuotaRequestHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	key, err := api.portal.RequestQuota(r.Context(), query.Get("key"), query.Get("reason"))
+	switch {
+	case errors.Is(err, portal.ErrEmptyQuotaReason):
+		api.validationErrorResponse(w, r, map[string][]string{"reason": {err.Error()}})
+	case errors.Is(err, portal.ErrUnknownKey):
+		api.sendError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, portal.ErrAlreadyElevated):
+		api.sendError(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		api.serverErrorResponse(w, r, err)
+	default:
+		api.sendResponse(w, r, models.NewEntryResponse(key, *models.NewEmptyReferences(), api.Clock))
+	}
+}
+
+// developerQuotaRequestsHandler lists the quota requests awaiting an admin
+// decision, oldest first.
+func (api *RestAPI) developerQuotaRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	requests, err := api.portal.QuotaRequests(r.Context())
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	api.sendResponse(w, r, models.NewListResponse(requests, *models.NewEmptyReferences(), false, api.Clock))
+}
+
+// decideDeveloperQuotaHandler returns the admin handler that approves or
+// denies the pending quota request of the portal key given by id.
+func (api *RestAPI) decideDeveloperQuotaHandler(approve bool) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id <= 0 {
+			api.validationErrorResponse(w, r, map[string][]string{"id": {"id must be a positive integer"}})
+			return
+		}
+		previous, err := api.portal.Key(r.Context(), id)
+		if errors.Is(err, portal.ErrUnknownKey) {
+			api.sendError(w, r, http.StatusNotFound, portal.ErrNoQuotaRequest.Error())
+			return
+		}
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		key, err := api.portal.DecideQuota(r.Context(), id, approve)
+		if errors.Is(err, portal.ErrNoQuotaRequest) {
+			api.sendError(w, r, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		recordAuditChange(r, strconv.FormatInt(id, 10), previous, key)
+		api.sendResponse(w, r, models.NewEntryResponse(key, *models.NewEmptyReferences(), api.Clock))
+	}
+}
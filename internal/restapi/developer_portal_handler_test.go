@@ -0,0 +1,151 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/portal"
+)
+
+type portalTestMailer struct {
+	bodies []string
+}
+
+func (m *portalTestMailer) Send(_ context.Context, _, _, body string) error {
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+var portalTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+type developerKeyResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		Entry models.DeveloperKey `json:"entry"`
+	} `json:"data"`
+}
+
+type developerKeyListResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List []models.DeveloperKey `json:"list"`
+	} `json:"data"`
+}
+
+// createPortalTestApi returns a test API with the developer portal enabled
+// and a mailer that records verification emails.
+func createPortalTestApi(t *testing.T) (*RestAPI, *portalTestMailer) {
+	t.Helper()
+	api := createTestApi(t)
+	// The flows below make more requests than the default test bucket holds.
+	api.rateLimiter = NewRateLimitMiddleware(100, time.Second, nil)
+	mailer := &portalTestMailer{}
+	api.Config.Portal = appconf.PortalConfig{Enabled: true, BaseURL: "https://api.example.org", RateLimit: 2}
+	api.portal = portal.New(api.Config.Portal, api.GtfsManager.GtfsDB.Queries, mailer, api.Clock, api.Logger)
+	return api, mailer
+}
+
+// signupAndVerify runs the signup flow for email and returns the issued key.
+func signupAndVerify(t *testing.T, api *RestAPI, mailer *portalTestMailer, email string) models.DeveloperKey {
+	t.Helper()
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/developer-signup.json?email="+url.QueryEscape(email))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, mailer.bodies)
+
+	match := portalTokenPattern.FindStringSubmatch(mailer.bodies[len(mailer.bodies)-1])
+	require.Len(t, match, 2)
+	resp, verified := callAPIHandler[developerKeyResponse](t, api, "/api/where/developer-verify.json?token="+match[1])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, verified.Data.Entry.Key)
+	return verified.Data.Entry
+}
+
+func TestDeveloperPortalDisabledByDefault(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	server := api.SetupAPIRoutes()
+	req, err := http.NewRequest(http.MethodGet, "/api/where/developer-signup.json?email=dev@example.org", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeveloperPortalSignupIssuesRateLimitedKey(t *testing.T) {
+	api, mailer := createPortalTestApi(t)
+	defer api.Shutdown()
+
+	key := signupAndVerify(t, api, mailer, "signup@example.org")
+	assert.Equal(t, portal.TierStandard, key.Tier)
+
+	endpoint := "/api/where/current-time.json?key=" + key.Key
+	for range 2 {
+		resp, _ := serveApiAndRetrieveEndpoint(t, api, endpoint)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, endpoint)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "portal keys have their own per-key limit")
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key=unknown-key")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestDeveloperPortalSignupValidation(t *testing.T) {
+	api, mailer := createPortalTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/developer-signup.json?email=not-an-email")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, mailer.bodies)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/developer-verify.json?token=bogus")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDeveloperPortalQuotaApproval(t *testing.T) {
+	api, mailer := createPortalTestApi(t)
+	defer api.Shutdown()
+
+	key := signupAndVerify(t, api, mailer, "quota@example.org")
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/developer-quota-request.json?key=TEST&reason=more")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "only portal keys can request a quota")
+
+	resp, requested := callAPIHandler[developerKeyResponse](t, api,
+		"/api/where/developer-quota-request.json?key="+key.Key+"&reason="+url.QueryEscape("Station displays"))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Station displays", requested.Data.Entry.QuotaRequest)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/developer-quota-requests.json?key=TEST")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, pending := callAPIHandler[developerKeyListResponse](t, api, "/api/where/developer-quota-requests.json?key=PROTECTED-TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, pending.Data.List, 1)
+	assert.Equal(t, key.ID, pending.Data.List[0].ID)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/approve-developer-quota.json?key=PROTECTED-TEST&id=abc")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, approved := callAPIHandler[developerKeyResponse](t, api,
+		"/api/where/approve-developer-quota.json?key=PROTECTED-TEST&id="+strconv.FormatInt(key.ID, 10))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, portal.TierElevated, approved.Data.Entry.Tier)
+	assert.Equal(t, appconf.DefaultPortalElevatedRateLimit, approved.Data.Entry.RateLimit)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api,
+		"/api/where/deny-developer-quota.json?key=PROTECTED-TEST&id="+strconv.FormatInt(key.ID, 10))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "the request was already decided")
+}
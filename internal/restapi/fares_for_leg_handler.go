@@ -0,0 +1,109 @@
+package restapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// faresForLegHandler returns the Fares v2 leg rules that price a ride on a
+// route between two stops, matching the route's network and the fare areas
+// of the stops. riderCategoryId narrows the prices to one rider category.
+func (api *RestAPI) faresForLegHandler(w http.ResponseWriter, r *http.Request) {
+	agencyID, routeID, ok := api.extractAndValidateAgencyCodeID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	fieldErrors := make(map[string][]string)
+	stopIDs := make(map[string]string, 2)
+	for _, field := range []string{"fromStopId", "toStopId"} {
+		value := query.Get(field)
+		if value == "" {
+			fieldErrors[field] = []string{"missingRequiredField"}
+			continue
+		}
+		_, stopID, err := utils.ExtractAgencyIDAndCodeID(value)
+		if err != nil {
+			fieldErrors[field] = []string{err.Error()}
+			continue
+		}
+		stopIDs[field] = stopID
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	route, err := queries.GetRoute(ctx, routeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.sendNotFound(w, r)
+			return
+		}
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	stopAreas := make(map[string][]string, 2)
+	for field, stopID := range stopIDs {
+		if _, err := queries.GetStop(ctx, stopID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				api.sendNotFound(w, r)
+				return
+			}
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		if stopAreas[field], err = queries.GetAreasForStop(ctx, stopID); err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	networks, err := queries.GetNetworksForRoute(ctx, route.ID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	allRules, err := queries.GetFareLegRules(ctx)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	matched := gtfsdb.MatchFareLegRules(allRules, networks, stopAreas["fromStopId"], stopAreas["toStopId"])
+	legRules, err := api.fareLegRules(ctx, matched, query.Get("riderCategoryId"))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	fares := models.LegFares{
+		RouteID:      utils.FormCombinedID(agencyID, route.ID),
+		FromStopID:   query.Get("fromStopId"),
+		ToStopID:     query.Get("toStopId"),
+		FareLegRules: legRules,
+	}
+
+	references := models.NewEmptyReferences()
+	if ShouldIncludeReferences(r) {
+		agency, err := queries.GetAgency(ctx, agencyID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		if err == nil {
+			references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&agency))
+		}
+	}
+
+	response := models.NewEntryResponse(fares, *references, api.Clock)
+	api.sendResponse(w, r, response)
+}
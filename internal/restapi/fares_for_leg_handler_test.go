@@ -0,0 +1,115 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func faresForLegURL(routeID string, params url.Values) string {
+	params.Set("key", "TEST")
+	return "/api/where/fares-for-leg/" + routeID + ".json?" + params.Encode()
+}
+
+func TestFaresForLegHandler(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	_, routeCode, err := utils.ExtractAgencyIDAndCodeID(testdata.Route1.ID)
+	require.NoError(t, err)
+	require.NoError(t, queries.CreateRiderCategory(ctx, gtfsdb.CreateRiderCategoryParams{ID: "test_senior", Name: "Senior"}))
+	for _, product := range []gtfsdb.CreateFareProductParams{
+		{FareProductID: "test_ride", Amount: 2, Currency: "USD"},
+		{FareProductID: "test_ride", RiderCategoryID: "test_senior", Amount: 1, Currency: "USD"},
+		{FareProductID: "test_downtown", Amount: 1.5, Currency: "USD"},
+	} {
+		require.NoError(t, queries.CreateFareProduct(ctx, product))
+	}
+	require.NoError(t, queries.CreateRouteNetwork(ctx, gtfsdb.CreateRouteNetworkParams{RouteID: routeCode, NetworkID: "test_local"}))
+	require.NoError(t, queries.CreateArea(ctx, gtfsdb.CreateAreaParams{ID: "test_downtown"}))
+	for _, stopID := range []string{"1001", "1002"} {
+		require.NoError(t, queries.CreateStopArea(ctx, gtfsdb.CreateStopAreaParams{AreaID: "test_downtown", StopID: stopID}))
+	}
+	for _, rule := range []gtfsdb.CreateFareLegRuleParams{
+		{NetworkID: nulls.String("test_local"), FareProductID: "test_ride"},
+		{NetworkID: nulls.String("test_local"), FromAreaID: nulls.String("test_downtown"), ToAreaID: nulls.String("test_downtown"), FareProductID: "test_downtown"},
+	} {
+		require.NoError(t, queries.CreateFareLegRule(ctx, rule))
+	}
+	t.Cleanup(func() {
+		db := api.GtfsManager.GtfsDB.DB
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM fare_leg_rules WHERE fare_product_id IN ('test_ride', 'test_downtown')`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM fare_products WHERE fare_product_id IN ('test_ride', 'test_downtown')`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM rider_categories WHERE id = 'test_senior'`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM stop_areas WHERE area_id = 'test_downtown'`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM areas WHERE id = 'test_downtown'`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM route_networks WHERE network_id = 'test_local'`)
+	})
+
+	leg := func(from, to string) url.Values {
+		return url.Values{
+			"fromStopId": {utils.FormCombinedID(testdata.Raba.ID, from)},
+			"toStopId":   {utils.FormCombinedID(testdata.Raba.ID, to)},
+		}
+	}
+
+	t.Run("within an area", func(t *testing.T) {
+		resp, model := callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(testdata.Route1.ID, leg("1001", "1002")))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entry := model.Data.Entry
+		assert.Equal(t, testdata.Route1.ID, entry.RouteID)
+		assert.Equal(t, "25_1001", entry.FromStopID)
+		require.Len(t, entry.FareLegRules, 1, "the rule naming the areas replaces the area-less one")
+		assert.Equal(t, "test_downtown", entry.FareLegRules[0].FromAreaID)
+	})
+
+	t.Run("outside the area", func(t *testing.T) {
+		_, model := callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(testdata.Route1.ID, leg("1003", "1004")))
+		rules := model.Data.Entry.FareLegRules
+		require.Len(t, rules, 1)
+		products := rules[0].FareProducts
+		require.Len(t, products, 2, "one price for every rider and one for seniors")
+		assert.Nil(t, products[0].RiderCategory)
+		require.NotNil(t, products[1].RiderCategory)
+		assert.Equal(t, "Senior", products[1].RiderCategory.Name)
+	})
+
+	t.Run("rider category", func(t *testing.T) {
+		params := leg("1003", "1004")
+		params.Set("riderCategoryId", "test_other")
+		_, model := callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(testdata.Route1.ID, params))
+		require.Len(t, model.Data.Entry.FareLegRules, 1)
+		products := model.Data.Entry.FareLegRules[0].FareProducts
+		require.Len(t, products, 1, "other categories' prices are left out")
+		assert.Equal(t, 2.0, products[0].Amount)
+	})
+
+	t.Run("other route", func(t *testing.T) {
+		_, model := callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(testdata.Route299x.ID, leg("1001", "1002")))
+		assert.Empty(t, model.Data.Entry.FareLegRules, "the route is in no network with leg rules")
+	})
+}
+
+func TestFaresForLegHandlerErrors(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(testdata.Route1.ID, url.Values{"fromStopId": {"25_1001"}}))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "toStopId is required")
+
+	resp, _ = callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(testdata.Route1.ID, url.Values{"fromStopId": {"25_1001"}, "toStopId": {"25_missing"}}))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = callAPIHandler[FaresForLegResponse](t, api, faresForLegURL(utils.FormCombinedID(testdata.Raba.ID, "missing"), url.Values{"fromStopId": {"25_1001"}, "toStopId": {"25_1002"}}))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
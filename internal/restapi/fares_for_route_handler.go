@@ -0,0 +1,130 @@
+package restapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// faresForRouteHandler returns the fares that can apply to a ride on a route,
+// from the feed's Fares v1 and Fares v2 files.
+func (api *RestAPI) faresForRouteHandler(w http.ResponseWriter, r *http.Request) {
+	agencyID, routeID, ok := api.extractAndValidateAgencyCodeID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	route, err := queries.GetRoute(ctx, routeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.sendNotFound(w, r)
+			return
+		}
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	fareRows, err := queries.GetFareAttributesForRoute(ctx, gtfsdb.GetFareAttributesForRouteParams{
+		AgencyID: nulls.String(route.AgencyID),
+		RouteID:  nulls.String(route.ID),
+	})
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	ruleRows, err := queries.GetFareRulesForRoute(ctx, nulls.String(route.ID))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	legRuleRows, err := queries.GetFareLegRulesForRoute(ctx, route.ID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	legRules, err := api.fareLegRules(ctx, legRuleRows, "")
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	combinedRouteID := func(id string) string { return utils.FormCombinedID(agencyID, id) }
+	fares := models.RouteFares{
+		RouteID:        combinedRouteID(route.ID),
+		FareAttributes: models.NewFareAttributesFromDB(fareRows, ruleRows, combinedRouteID),
+		FareLegRules:   legRules,
+	}
+
+	references := models.NewEmptyReferences()
+	if ShouldIncludeReferences(r) {
+		agency, err := queries.GetAgency(ctx, agencyID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		if err == nil {
+			references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&agency))
+		}
+	}
+
+	response := models.NewEntryResponse(fares, *references, api.Clock)
+	api.sendResponse(w, r, response)
+}
+
+// fareLegRules loads the fare products, media and rider categories of the
+// leg rules and converts them into API models. A non-empty riderCategoryID
+// keeps only the prices for that category and those every rider pays.
+func (api *RestAPI) fareLegRules(ctx context.Context, rows []gtfsdb.FareLegRule, riderCategoryID string) ([]models.FareLegRule, error) {
+	if len(rows) == 0 {
+		return []models.FareLegRule{}, nil
+	}
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	productIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		productIDs = append(productIDs, row.FareProductID)
+	}
+	productRows, err := queries.GetFareProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if riderCategoryID != "" {
+		productRows = slices.DeleteFunc(productRows, func(row gtfsdb.FareProduct) bool {
+			return row.RiderCategoryID != "" && row.RiderCategoryID != riderCategoryID
+		})
+	}
+
+	var mediaIDs, categoryIDs []string
+	for _, row := range productRows {
+		if row.FareMediaID != "" {
+			mediaIDs = append(mediaIDs, row.FareMediaID)
+		}
+		if row.RiderCategoryID != "" {
+			categoryIDs = append(categoryIDs, row.RiderCategoryID)
+		}
+	}
+	var mediaRows []gtfsdb.FareMedium
+	if len(mediaIDs) > 0 {
+		if mediaRows, err = queries.GetFareMediaByIDs(ctx, mediaIDs); err != nil {
+			return nil, err
+		}
+	}
+	var categoryRows []gtfsdb.RiderCategory
+	if len(categoryIDs) > 0 {
+		if categoryRows, err = queries.GetRiderCategoriesByIDs(ctx, categoryIDs); err != nil {
+			return nil, err
+		}
+	}
+	return models.NewFareLegRulesFromDB(rows, productRows, mediaRows, categoryRows), nil
+}
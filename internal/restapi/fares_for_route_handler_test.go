@@ -0,0 +1,108 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func faresForRouteURL(routeID string) string {
+	return "/api/where/fares-for-route/" + routeID + ".json?key=TEST"
+}
+
+func TestFaresForRouteHandler(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[FaresForRouteResponse](t, api, faresForRouteURL(testdata.Route1.ID))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := model.Data.Entry
+	assert.Equal(t, testdata.Route1.ID, entry.RouteID)
+	assert.Empty(t, entry.FareLegRules, "the RABA feed has no Fares v2 files")
+
+	// RABA prices each route with a single Fares v1 fare.
+	require.Len(t, entry.FareAttributes, 1)
+	fare := entry.FareAttributes[0]
+	assert.Equal(t, "63", fare.ID)
+	assert.Equal(t, 2.0, fare.Price)
+	assert.Equal(t, "USD", fare.CurrencyType)
+	assert.Nil(t, fare.Transfers, "unlimited transfers")
+	require.Len(t, fare.Rules, 1)
+	assert.Equal(t, testdata.Route1.ID, fare.Rules[0].RouteID)
+	assert.Equal(t, []models.AgencyReference{testdata.Raba}, model.Data.References.Agencies)
+
+	_, express := callAPIHandler[FaresForRouteResponse](t, api, faresForRouteURL(testdata.Route299x.ID))
+	require.Len(t, express.Data.Entry.FareAttributes, 1)
+	assert.Equal(t, 4.0, express.Data.Entry.FareAttributes[0].Price)
+}
+
+func TestFaresForRouteHandlerFaresV2(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	_, routeCode, err := utils.ExtractAgencyIDAndCodeID(testdata.Route1.ID)
+	require.NoError(t, err)
+	require.NoError(t, queries.CreateFareMedia(ctx, gtfsdb.CreateFareMediaParams{ID: "test_card", Name: nulls.String("Transit Card"), FareMediaType: 2}))
+	for _, product := range []gtfsdb.CreateFareProductParams{
+		{FareProductID: "test_ride", FareMediaID: "test_card", Name: nulls.String("Single Ride"), Amount: 1.75, Currency: "USD"},
+		{FareProductID: "test_pass", Name: nulls.String("Regional Pass"), Amount: 5, Currency: "USD"},
+	} {
+		require.NoError(t, queries.CreateFareProduct(ctx, product))
+	}
+	require.NoError(t, queries.CreateRouteNetwork(ctx, gtfsdb.CreateRouteNetworkParams{RouteID: routeCode, NetworkID: "test_local"}))
+	for _, rule := range []gtfsdb.CreateFareLegRuleParams{
+		{NetworkID: nulls.String("test_local"), FareProductID: "test_ride", RulePriority: 1},
+		{NetworkID: nulls.String("test_other"), FareProductID: "test_ride"},
+		{FareProductID: "test_pass"},
+	} {
+		require.NoError(t, queries.CreateFareLegRule(ctx, rule))
+	}
+	t.Cleanup(func() {
+		db := api.GtfsManager.GtfsDB.DB
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM fare_leg_rules WHERE fare_product_id IN ('test_ride', 'test_pass')`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM fare_products WHERE fare_product_id IN ('test_ride', 'test_pass')`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM fare_media WHERE id = 'test_card'`)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM route_networks WHERE network_id = 'test_local'`)
+	})
+
+	resp, model := callAPIHandler[FaresForRouteResponse](t, api, faresForRouteURL(testdata.Route1.ID))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	rules := model.Data.Entry.FareLegRules
+	require.Len(t, rules, 2, "rules for other networks do not apply")
+
+	assert.Equal(t, "test_local", rules[0].NetworkID)
+	require.Len(t, rules[0].FareProducts, 1)
+	ride := rules[0].FareProducts[0]
+	assert.Equal(t, 1.75, ride.Amount)
+	require.NotNil(t, ride.FareMedia)
+	assert.Equal(t, 2, ride.FareMedia.Type)
+
+	assert.Empty(t, rules[1].NetworkID, "a rule without a network applies everywhere")
+	require.Len(t, rules[1].FareProducts, 1)
+	assert.Nil(t, rules[1].FareProducts[0].FareMedia)
+
+	_, other := callAPIHandler[FaresForRouteResponse](t, api, faresForRouteURL(testdata.Route299x.ID))
+	require.Len(t, other.Data.Entry.FareLegRules, 1)
+	assert.Equal(t, "test_pass", other.Data.Entry.FareLegRules[0].FareProducts[0].ID)
+}
+
+func TestFaresForRouteHandlerNotFound(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[FaresForRouteResponse](t, api, faresForRouteURL(utils.FormCombinedID(testdata.Raba.ID, "missing")))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = callAPIHandler[FaresForRouteResponse](t, api, "/api/where/fares-for-route/"+testdata.Route1.ID+".json?key=invalid")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
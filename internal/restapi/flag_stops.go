@@ -0,0 +1,97 @@
+package restapi
+
+import (
+	"context"
+	"sync"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// gtfsNoContinuousStopping is the continuous_pickup and continuous_drop_off
+// value for a route whose vehicles only stop at its stops. Continuous stopping
+// that must be arranged by phone or with the driver still makes a flag stop.
+const gtfsNoContinuousStopping = 1
+
+// continuousStopping reports whether riders can board or alight anywhere
+// along route. A missing value means no continuous stopping.
+func continuousStopping(route gtfsdb.Route) bool {
+	return (route.ContinuousPickup.Valid && route.ContinuousPickup.Int64 != gtfsNoContinuousStopping) ||
+		(route.ContinuousDropOff.Valid && route.ContinuousDropOff.Int64 != gtfsNoContinuousStopping)
+}
+
+// flagStopRoutes holds the combined IDs of the routes with continuous
+// stopping for one static feed version.
+type flagStopRoutes struct {
+	mu     sync.Mutex
+	etag   string
+	routes map[string]struct{}
+}
+
+// continuousStoppingRoutes returns the combined IDs of the routes with
+// continuous stopping, loading them once per static feed version. It returns
+// nil if the routes cannot be read.
+func (api *RestAPI) continuousStoppingRoutes(ctx context.Context) map[string]struct{} {
+	if api.GtfsManager == nil {
+		return nil
+	}
+	etag := api.GtfsManager.GetSystemETag(ctx)
+
+	c := &api.flagStopRoutes
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.routes != nil && c.etag == etag {
+		return c.routes
+	}
+
+	routes, err := api.GtfsManager.GetRoutes(ctx)
+	if err != nil {
+		api.Logger.Warn("failed to load routes for flag stops", "error", err)
+		return nil
+	}
+	c.routes = make(map[string]struct{})
+	for _, route := range routes {
+		if continuousStopping(route) {
+			c.routes[utils.FormCombinedID(route.AgencyID, route.ID)] = struct{}{}
+		}
+	}
+	c.etag = etag
+	return c.routes
+}
+
+// markFlagStops sets FlagStop on the stops of a response's list, entry and
+// references that are served by a route with continuous stopping.
+func (api *RestAPI) markFlagStops(ctx context.Context, data map[string]any) {
+	_, hasList := data["list"].([]models.Stop)
+	_, hasEntry := data["entry"].(*models.Stop)
+	references, hasReferences := data["references"].(models.ReferencesModel)
+	if !hasList && !hasEntry && (!hasReferences || len(references.Stops) == 0) {
+		return
+	}
+
+	routes := api.continuousStoppingRoutes(ctx)
+	if len(routes) == 0 {
+		return
+	}
+	mark := func(stop *models.Stop) {
+		for _, routeID := range stop.RouteIDs {
+			if _, ok := routes[routeID]; ok {
+				stop.FlagStop = true
+				return
+			}
+		}
+	}
+
+	if list, ok := data["list"].([]models.Stop); ok {
+		for i := range list {
+			mark(&list[i])
+		}
+	}
+	if entry, ok := data["entry"].(*models.Stop); ok && entry != nil {
+		mark(entry)
+	}
+	for i := range references.Stops {
+		mark(&references.Stops[i])
+	}
+}
@@ -0,0 +1,55 @@
+package restapi
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestContinuousStopping(t *testing.T) {
+	tests := []struct {
+		name               string
+		pickup, dropOff    sql.NullInt64
+		wantContinuousStop bool
+	}{
+		{"missing values", sql.NullInt64{}, sql.NullInt64{}, false},
+		{"no continuous stopping", nulls.Int64(1), nulls.Int64(1), false},
+		{"continuous pickup", nulls.Int64(0), nulls.Int64(1), true},
+		{"continuous drop-off arranged with the driver", sql.NullInt64{}, nulls.Int64(3), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := gtfsdb.Route{ContinuousPickup: tt.pickup, ContinuousDropOff: tt.dropOff}
+			assert.Equal(t, tt.wantContinuousStop, continuousStopping(route))
+		})
+	}
+}
+
+func TestStopOnContinuousStoppingRouteIsFlagStop(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+
+	_, err := api.GtfsManager.GtfsDB.DB.ExecContext(ctx, `UPDATE routes SET continuous_pickup = 0 WHERE id = '154'`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(), `UPDATE routes SET continuous_pickup = 1 WHERE id = '154'`)
+	})
+
+	_, model := callAPIHandler[StopEntryResponse](t, api, "/api/where/stop/"+testdata.Stop4062.ID+".json?key=TEST")
+	assert.Equal(t, testdata.Stop4062.ID, model.Data.Entry.ID)
+	assert.True(t, model.Data.Entry.FlagStop, "route 154 serves the stop with continuous pickup")
+
+	_, stops := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-agency/"+testdata.Raba.ID+".json?key=TEST")
+	require.NotEmpty(t, stops.Data.List)
+	for _, stop := range stops.Data.List {
+		assert.Equal(t, slices.Contains(stop.RouteIDs, "25_154"), stop.FlagStop, "stop %s", stop.ID)
+	}
+}
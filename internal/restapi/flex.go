@@ -0,0 +1,113 @@
+package restapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// flexStopTimeKey identifies a stop time of a trip.
+type flexStopTimeKey struct {
+	tripID       string
+	stopSequence int64
+}
+
+// activeFlexWindow is a GTFS-Flex stop time at a stop whose pickup/drop-off
+// window falls on a service date with active service for its trip.
+type activeFlexWindow struct {
+	gtfsdb.GetFlexStopTimesForStopRow
+	ServiceDate time.Time
+}
+
+// bookingRulesByID fetches the booking rules with the given IDs. Blank IDs
+// are ignored.
+func (api *RestAPI) bookingRulesByID(ctx context.Context, ids ...sql.NullString) (map[string]models.BookingRule, error) {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id.Valid && id.String != "" && !seen[id.String] {
+			seen[id.String] = true
+			unique = append(unique, id.String)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, nil
+	}
+	rows, err := api.GtfsManager.GtfsDB.Queries.GetBookingRulesByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]models.BookingRule, len(rows))
+	for _, rule := range models.NewBookingRulesFromDB(rows) {
+		rules[rule.ID] = rule
+	}
+	return rules, nil
+}
+
+// bookingRulesFor returns the pickup and drop-off rules of a flex stop time,
+// once each, or nil when it has none.
+func bookingRulesFor(rules map[string]models.BookingRule, pickup, dropOff sql.NullString) []models.BookingRule {
+	var result []models.BookingRule
+	for _, id := range []sql.NullString{pickup, dropOff} {
+		rule, ok := rules[id.String]
+		if !ok || (len(result) > 0 && result[0].ID == rule.ID) {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// newFlexStopTimes converts a trip's flex_stop_times rows into API models,
+// with stop and location group IDs combined with agencyID. It returns nil for
+// no rows so the field is omitted for fixed-route trips.
+func newFlexStopTimes(rows []gtfsdb.FlexStopTime, agencyID string) []models.FlexStopTime {
+	if len(rows) == 0 {
+		return nil
+	}
+	window := func(v sql.NullInt64) *models.ModelDuration {
+		if !v.Valid {
+			return nil
+		}
+		d := models.NewModelDuration(time.Duration(v.Int64))
+		return &d
+	}
+	stopTimes := make([]models.FlexStopTime, len(rows))
+	for i, row := range rows {
+		stopTimes[i] = models.FlexStopTime{
+			StopSequence:             int(row.StopSequence),
+			StopID:                   utils.FormCombinedID(agencyID, row.StopID.String),
+			LocationGroupID:          utils.FormCombinedID(agencyID, row.LocationGroupID.String),
+			StartPickupDropOffWindow: window(row.StartPickupDropOffWindow),
+			EndPickupDropOffWindow:   window(row.EndPickupDropOffWindow),
+			PickupBookingRuleID:      row.PickupBookingRuleID.String,
+			DropOffBookingRuleID:     row.DropOffBookingRuleID.String,
+		}
+	}
+	return stopTimes
+}
+
+// flexBookingRulesForTrip returns the booking rules referenced by a trip's
+// flex stop times, or nil for a fixed-route trip.
+func (api *RestAPI) flexBookingRulesForTrip(ctx context.Context, rows []gtfsdb.FlexStopTime) ([]models.BookingRule, error) {
+	ids := make([]sql.NullString, 0, 2*len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.PickupBookingRuleID, row.DropOffBookingRuleID)
+	}
+	rules, err := api.bookingRulesByID(ctx, ids...)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	result := make([]models.BookingRule, 0, len(rules))
+	for _, id := range ids {
+		if rule, ok := rules[id.String]; ok {
+			result = append(result, rule)
+			delete(rules, id.String)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,154 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// insertTestFlexStopTimes adds a booking rule and the given flex stop times
+// to the shared test database, removing them when the test ends. A flex stop
+// time with a location group also gets a group containing groupStopID.
+func insertTestFlexStopTimes(t *testing.T, api *RestAPI, groupStopID string, stopTimes ...gtfsdb.CreateFlexStopTimeParams) {
+	t.Helper()
+	ctx := context.Background()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	require.NoError(t, queries.CreateBookingRule(ctx, gtfsdb.CreateBookingRuleParams{
+		ID:          "test_flex_rule",
+		BookingType: 0,
+		PhoneNumber: nulls.String("555-0100"),
+	}))
+	require.NoError(t, queries.CreateLocationGroup(ctx, gtfsdb.CreateLocationGroupParams{ID: "test_flex_zone"}))
+	require.NoError(t, queries.CreateLocationGroupStop(ctx, gtfsdb.CreateLocationGroupStopParams{
+		LocationGroupID: "test_flex_zone",
+		StopID:          groupStopID,
+	}))
+	for _, params := range stopTimes {
+		require.NoError(t, queries.CreateFlexStopTime(ctx, params))
+	}
+
+	t.Cleanup(func() {
+		db := api.GtfsManager.GtfsDB.DB
+		for _, params := range stopTimes {
+			_, _ = db.ExecContext(context.Background(), `DELETE FROM flex_stop_times WHERE trip_id = ? AND stop_sequence = ?`,
+				params.TripID, params.StopSequence)
+		}
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM location_group_stops WHERE location_group_id = ?`, "test_flex_zone")
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM location_groups WHERE id = ?`, "test_flex_zone")
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM booking_rules WHERE id = ?`, "test_flex_rule")
+	})
+}
+
+func TestArrivalsIncludeFlexService(t *testing.T) {
+	api, cleanup := createTestApiWithRealTimeData(t, clock.NewMockClock(arrivalsTestClock))
+	defer cleanup()
+
+	window := url.Values{"minutesBefore": {"60"}, "minutesAfter": {"240"}}
+	_, before := callAPIHandler[ArrivalsAndDeparturesResponse](t, api, arrivalsAndDeparturesURL(arrivalsTestStopID, window))
+	require.NotEmpty(t, before.Data.Entry.ArrivalsAndDepartures)
+	timed := before.Data.Entry.ArrivalsAndDepartures[0]
+	assert.Empty(t, timed.BookingRules)
+
+	_, tripID, err := utils.ExtractAgencyIDAndCodeID(timed.TripID)
+	require.NoError(t, err)
+	_, routeID, err := utils.ExtractAgencyIDAndCodeID(timed.RouteID)
+	require.NoError(t, err)
+	_, stopCode, err := utils.ExtractAgencyIDAndCodeID(arrivalsTestStopID)
+	require.NoError(t, err)
+
+	serviceDate := timed.ServiceDate.Time
+	opens, closes := 11*time.Hour+30*time.Minute, 12*time.Hour
+	insertTestFlexStopTimes(t, api, stopCode,
+		// The timed stop also takes requests for drop-offs...
+		gtfsdb.CreateFlexStopTimeParams{
+			TripID:               tripID,
+			StopSequence:         int64(timed.StopSequence) + 1,
+			RouteID:              routeID,
+			StopID:               nulls.String(stopCode),
+			DropOffBookingRuleID: nulls.String("test_flex_rule"),
+		},
+		// ...and the trip later deviates through a zone containing the stop.
+		gtfsdb.CreateFlexStopTimeParams{
+			TripID:                   tripID,
+			StopSequence:             1000,
+			RouteID:                  routeID,
+			LocationGroupID:          nulls.String("test_flex_zone"),
+			StartPickupDropOffWindow: nulls.Int64(int64(opens)),
+			EndPickupDropOffWindow:   nulls.Int64(int64(closes)),
+			PickupBookingRuleID:      nulls.String("test_flex_rule"),
+		},
+	)
+
+	resp, after := callAPIHandler[ArrivalsAndDeparturesResponse](t, api, arrivalsAndDeparturesURL(arrivalsTestStopID, window))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	arrivals := after.Data.Entry.ArrivalsAndDepartures
+	require.Len(t, arrivals, len(before.Data.Entry.ArrivalsAndDepartures)+1)
+
+	require.Len(t, arrivals[0].BookingRules, 1)
+	assert.Equal(t, "test_flex_rule", arrivals[0].BookingRules[0].ID)
+	assert.True(t, arrivals[0].StartPickupDropOffWindow.IsZero(), "timed stops have no window")
+
+	flex := arrivals[len(arrivals)-1]
+	assert.Equal(t, timed.TripID, flex.TripID)
+	assert.Equal(t, 999, flex.StopSequence)
+	assert.Equal(t, serviceDate.Add(opens).UnixMilli(), flex.StartPickupDropOffWindow.UnixMilli())
+	assert.Equal(t, serviceDate.Add(closes).UnixMilli(), flex.EndPickupDropOffWindow.UnixMilli())
+	assert.Equal(t, flex.StartPickupDropOffWindow.UnixMilli(), flex.ScheduledArrivalTime.UnixMilli())
+	assert.False(t, flex.Predicted)
+	require.Len(t, flex.BookingRules, 1)
+	assert.Equal(t, "555-0100", flex.BookingRules[0].PhoneNumber)
+}
+
+func TestTripDetailsIncludeFlexService(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	agency := mustGetAgencies(t, api)[0]
+	trip := mustGetTrip(t, api)
+	endpoint := "/api/where/trip-details/" + utils.FormCombinedID(agency.ID, trip.ID) + ".json?key=TEST"
+
+	_, before := callAPIHandler[TripDetailsResponse](t, api, endpoint)
+	assert.Empty(t, before.Data.Entry.BookingRules)
+	require.NotNil(t, before.Data.Entry.Schedule)
+	assert.Empty(t, before.Data.Entry.Schedule.FlexStopTimes)
+
+	_, stopCode, err := utils.ExtractAgencyIDAndCodeID(arrivalsTestStopID)
+	require.NoError(t, err)
+	insertTestFlexStopTimes(t, api, stopCode, gtfsdb.CreateFlexStopTimeParams{
+		TripID:                   trip.ID,
+		StopSequence:             1000,
+		RouteID:                  trip.RouteID,
+		LocationGroupID:          nulls.String("test_flex_zone"),
+		StartPickupDropOffWindow: nulls.Int64(int64(8 * time.Hour)),
+		EndPickupDropOffWindow:   nulls.Int64(int64(9 * time.Hour)),
+		PickupBookingRuleID:      nulls.String("test_flex_rule"),
+		DropOffBookingRuleID:     nulls.String("test_flex_rule"),
+	})
+
+	resp, after := callAPIHandler[TripDetailsResponse](t, api, endpoint)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := after.Data.Entry
+	require.Len(t, entry.BookingRules, 1, "a rule used for pickup and drop-off is listed once")
+	assert.Equal(t, "test_flex_rule", entry.BookingRules[0].ID)
+
+	require.NotNil(t, entry.Schedule)
+	require.Len(t, entry.Schedule.FlexStopTimes, 1)
+	flex := entry.Schedule.FlexStopTimes[0]
+	assert.Equal(t, 1000, flex.StopSequence)
+	assert.Equal(t, utils.FormCombinedID(agency.ID, "test_flex_zone"), flex.LocationGroupID)
+	assert.Empty(t, flex.StopID)
+	require.NotNil(t, flex.StartPickupDropOffWindow)
+	assert.Equal(t, 8*time.Hour, flex.StartPickupDropOffWindow.Duration)
+	assert.Equal(t, 9*time.Hour, flex.EndPickupDropOffWindow.Duration)
+	assert.Equal(t, "test_flex_rule", flex.PickupBookingRuleID)
+}
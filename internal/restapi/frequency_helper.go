@@ -0,0 +1,101 @@
+package restapi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// newTripFrequency converts a frequencies.txt row into the API model, filling in
+// the service date and combined service/trip IDs. serviceDate must be local midnight.
+func newTripFrequency(freq gtfsdb.Frequency, agencyID string, trip gtfsdb.Trip, serviceDate time.Time) *models.Frequency {
+	converted := models.NewFrequencyFromDB(freq, serviceDate)
+	converted.ServiceDate = models.NewModelTime(serviceDate)
+	converted.ServiceID = utils.FormCombinedID(agencyID, trip.ServiceID)
+	converted.TripID = utils.FormCombinedID(agencyID, trip.ID)
+	return &converted
+}
+
+// activeFrequency returns the headway window in freqs that contains currentTime,
+// falling back to the earliest window. ok is false when freqs is empty.
+func activeFrequency(freqs []gtfsdb.Frequency, serviceDate, currentTime time.Time) (freq gtfsdb.Frequency, ok bool) {
+	if len(freqs) == 0 {
+		return gtfsdb.Frequency{}, false
+	}
+	sinceMidnight := int64(currentTime.Sub(serviceDate))
+	for _, f := range freqs {
+		if sinceMidnight >= f.StartTime && sinceMidnight < f.EndTime {
+			return f, true
+		}
+	}
+	return freqs[0], true
+}
+
+// frequencyScheduleShift returns how far the current headway instance of a
+// frequency-based trip departs after the trip's template stop times. Stop times
+// for such trips only describe relative spacing. The instance is the latest
+// departure slot at or before currentTime within the window (or the first slot,
+// before the window opens); for exact_times=0 trips, which have no fixed
+// departures, this is the best available estimate of where service should be.
+func frequencyScheduleShift(freq gtfsdb.Frequency, stopTimes []gtfsdb.StopTime, serviceDate, currentTime time.Time) time.Duration {
+	if len(stopTimes) == 0 || freq.HeadwaySecs <= 0 || freq.EndTime <= freq.StartTime {
+		return 0
+	}
+
+	headway := time.Duration(freq.HeadwaySecs) * time.Second
+	windowStart := time.Duration(freq.StartTime)
+	windowEnd := time.Duration(freq.EndTime)
+	sinceMidnight := currentTime.Sub(serviceDate)
+
+	instanceStart := windowStart
+	if sinceMidnight > windowStart {
+		// end_time is exclusive, so the last departure is the final slot before it.
+		elapsed := min(sinceMidnight, windowEnd-1) - windowStart
+		instanceStart += elapsed / headway * headway
+	}
+
+	first := stopTimes[0]
+	templateStart := time.Duration(first.DepartureTime)
+	if templateStart == 0 {
+		templateStart = time.Duration(first.ArrivalTime)
+	}
+	return instanceStart - templateStart
+}
+
+// applyTripFrequency sets status.Frequency for frequency-based trips and returns
+// the time at which schedule lookups should be evaluated: currentTime moved into
+// the template's timeframe, so closest/next stop and scheduled distance reflect
+// the current headway instance. Non-frequency trips get currentTime unchanged.
+func (api *RestAPI) applyTripFrequency(
+	ctx context.Context,
+	status *models.TripStatus,
+	agencyID, tripID string,
+	serviceDate, currentTime time.Time,
+	stopTimes []gtfsdb.StopTime,
+) time.Time {
+	freqRows, err := api.GtfsManager.GtfsDB.Queries.GetFrequenciesForTrip(ctx, tripID)
+	if err != nil {
+		slog.Warn("applyTripFrequency: failed to get frequencies",
+			slog.String("trip_id", tripID),
+			slog.String("error", err.Error()))
+		return currentTime
+	}
+	freq, ok := activeFrequency(freqRows, serviceDate, currentTime)
+	if !ok {
+		return currentTime
+	}
+
+	trip, err := api.GtfsManager.GtfsDB.Queries.GetTrip(ctx, tripID)
+	if err != nil {
+		slog.Warn("applyTripFrequency: failed to get trip",
+			slog.String("trip_id", tripID),
+			slog.String("error", err.Error()))
+		return currentTime
+	}
+	status.Frequency = newTripFrequency(freq, agencyID, trip, serviceDate)
+	return currentTime.Add(-frequencyScheduleShift(freq, stopTimes, serviceDate, currentTime))
+}
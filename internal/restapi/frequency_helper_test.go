@@ -0,0 +1,132 @@
+package restapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestActiveFrequency(t *testing.T) {
+	serviceDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	morning := gtfsdb.Frequency{StartTime: int64(6 * time.Hour), EndTime: int64(9 * time.Hour), HeadwaySecs: 600}
+	evening := gtfsdb.Frequency{StartTime: int64(16 * time.Hour), EndTime: int64(19 * time.Hour), HeadwaySecs: 900}
+	freqs := []gtfsdb.Frequency{morning, evening}
+
+	tests := []struct {
+		name     string
+		freqs    []gtfsdb.Frequency
+		at       time.Duration
+		expected gtfsdb.Frequency
+		ok       bool
+	}{
+		{name: "no frequencies", freqs: nil, at: 7 * time.Hour, ok: false},
+		{name: "inside first window", freqs: freqs, at: 7 * time.Hour, expected: morning, ok: true},
+		{name: "inside second window", freqs: freqs, at: 17 * time.Hour, expected: evening, ok: true},
+		{name: "end time is exclusive", freqs: freqs, at: 19 * time.Hour, expected: morning, ok: true},
+		{name: "between windows falls back to earliest", freqs: freqs, at: 12 * time.Hour, expected: morning, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			freq, ok := activeFrequency(tt.freqs, serviceDate, serviceDate.Add(tt.at))
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, freq)
+		})
+	}
+}
+
+func TestFrequencyScheduleShift(t *testing.T) {
+	serviceDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	freq := gtfsdb.Frequency{StartTime: int64(6 * time.Hour), EndTime: int64(8 * time.Hour), HeadwaySecs: 1800}
+	stopTimes := []gtfsdb.StopTime{
+		{ArrivalTime: int64(5 * time.Hour), DepartureTime: int64(5 * time.Hour)},
+		{ArrivalTime: int64(5*time.Hour + 20*time.Minute), DepartureTime: int64(5*time.Hour + 20*time.Minute)},
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Duration
+		expected time.Duration
+	}{
+		{name: "before window uses first slot", at: 5 * time.Hour, expected: time.Hour},
+		{name: "on a slot", at: 6*time.Hour + 30*time.Minute, expected: 90 * time.Minute},
+		{name: "between slots uses latest departed slot", at: 6*time.Hour + 50*time.Minute, expected: 90 * time.Minute},
+		{name: "after window uses last slot", at: 9 * time.Hour, expected: 150 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift := frequencyScheduleShift(freq, stopTimes, serviceDate, serviceDate.Add(tt.at))
+			assert.Equal(t, tt.expected, shift)
+		})
+	}
+
+	t.Run("no stop times", func(t *testing.T) {
+		assert.Zero(t, frequencyScheduleShift(freq, nil, serviceDate, serviceDate.Add(7*time.Hour)))
+	})
+	t.Run("invalid headway", func(t *testing.T) {
+		invalid := freq
+		invalid.HeadwaySecs = 0
+		assert.Zero(t, frequencyScheduleShift(invalid, stopTimes, serviceDate, serviceDate.Add(7*time.Hour)))
+	})
+}
+
+// TestBuildTripStatus_FrequencyBasedTrip verifies that a headway-based trip
+// reports its active frequency window and resolves stops for the current
+// headway instance rather than the template times in stop_times.txt.
+func TestBuildTripStatus_FrequencyBasedTrip(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	ctx := context.Background()
+
+	agencyID := mustGetAgencies(t, api)[0].ID
+	trip := mustGetTrip(t, api)
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trip.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(stopTimes), 2)
+
+	serviceDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	templateStart := time.Duration(stopTimes[0].DepartureTime)
+	intoTrip := time.Duration(stopTimes[1].ArrivalTime-stopTimes[0].DepartureTime) / 2
+
+	templateStatus, err := api.BuildTripStatus(ctx, agencyID, trip.ID, nil, serviceDate, serviceDate.Add(templateStart+intoTrip))
+	require.NoError(t, err)
+	assert.Nil(t, templateStatus.Frequency, "non-frequency trips must not report a frequency")
+	require.NotEmpty(t, templateStatus.ClosestStop)
+
+	windowStart := templateStart + 3*time.Hour
+	headway := 20 * time.Minute
+	err = api.GtfsManager.GtfsDB.Queries.CreateFrequency(ctx, gtfsdb.CreateFrequencyParams{
+		TripID:      trip.ID,
+		StartTime:   int64(windowStart),
+		EndTime:     int64(windowStart + 2*time.Hour),
+		HeadwaySecs: int64(headway / time.Second),
+		ExactTimes:  0,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(), `DELETE FROM frequencies WHERE trip_id = ?`, trip.ID)
+	})
+
+	// Two headways into the window, the latest instance departed at windowStart+2*headway.
+	currentTime := serviceDate.Add(windowStart + 2*headway + intoTrip)
+	status, err := api.BuildTripStatus(ctx, agencyID, trip.ID, nil, serviceDate, currentTime)
+	require.NoError(t, err)
+
+	require.NotNil(t, status.Frequency)
+	assert.True(t, serviceDate.Add(windowStart).Equal(status.Frequency.StartTime.Time))
+	assert.Equal(t, headway, status.Frequency.Headway.Duration)
+	assert.Equal(t, utils.FormCombinedID(agencyID, trip.ID), status.Frequency.TripID)
+	assert.Equal(t, utils.FormCombinedID(agencyID, trip.ServiceID), status.Frequency.ServiceID)
+
+	assert.Equal(t, templateStatus.ClosestStop, status.ClosestStop)
+	assert.Equal(t, templateStatus.NextStop, status.NextStop)
+	assert.Equal(t, templateStatus.ClosestStopTimeOffset, status.ClosestStopTimeOffset)
+	assert.Equal(t, templateStatus.NextStopTimeOffset, status.NextStopTimeOffset)
+}
@@ -0,0 +1,71 @@
+package restapi
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// goroutineLeakTimeout is how long goroutines that are winding down get to
+// exit before they count as leaked.
+const goroutineLeakTimeout = 2 * time.Second
+
+// verifyNoGoroutineLeaks fails tb if, once the test and its deferred calls
+// are done, goroutines running maglev code are left that were not running
+// when it was called. Call it first so its check runs after every other
+// cleanup. It does what goleak.VerifyNone does, limited to this module's
+// goroutines so idle connections of the standard library and the SQLite
+// driver do not count.
+func verifyNoGoroutineLeaks(tb testing.TB) {
+	tb.Helper()
+	before := goroutineIDs(goroutineStacks())
+	tb.Cleanup(func() {
+		var leaked []string
+		deadline := time.Now().Add(goroutineLeakTimeout)
+		for {
+			leaked = leaked[:0]
+			for _, stack := range goroutineStacks() {
+				if !slices.Contains(before, goroutineID(stack)) && strings.Contains(stack, "maglev.onebusaway.org/") {
+					leaked = append(leaked, stack)
+				}
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		for _, stack := range leaked {
+			tb.Errorf("leaked goroutine:\n%s", stack)
+		}
+	})
+}
+
+// goroutineStacks returns the stack of every goroutine but the caller's.
+func goroutineStacks() []string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			// The caller's own stack comes first.
+			stacks := strings.Split(string(buf[:n]), "\n\n")
+			return stacks[1:]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func goroutineIDs(stacks []string) []string {
+	ids := make([]string, len(stacks))
+	for i, stack := range stacks {
+		ids[i] = goroutineID(stack)
+	}
+	return ids
+}
+
+// goroutineID returns the "goroutine N" header of a stack.
+func goroutineID(stack string) string {
+	id, _, _ := strings.Cut(stack, " [")
+	return id
+}
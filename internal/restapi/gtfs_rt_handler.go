@@ -0,0 +1,32 @@
+package restapi
+
+import (
+	"net/http"
+
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
+	"google.golang.org/protobuf/proto"
+	"maglev.onebusaway.org/internal/pbformat"
+)
+
+// gtfsRTTripUpdatesHandler republishes the merged trip updates of every
+// realtime feed as one GTFS-RT feed.
+func (api *RestAPI) gtfsRTTripUpdatesHandler(w http.ResponseWriter, r *http.Request) {
+	api.sendFeedMessage(w, r, api.GtfsManager.TripUpdatesFeed(api.Clock.Now()))
+}
+
+// gtfsRTVehiclePositionsHandler republishes the merged vehicle positions of
+// every realtime feed as one GTFS-RT feed.
+func (api *RestAPI) gtfsRTVehiclePositionsHandler(w http.ResponseWriter, r *http.Request) {
+	api.sendFeedMessage(w, r, api.GtfsManager.VehiclePositionsFeed(api.Clock.Now()))
+}
+
+func (api *RestAPI) sendFeedMessage(w http.ResponseWriter, r *http.Request, message *gtfsrt.FeedMessage) {
+	body, err := proto.Marshal(message)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", pbformat.ContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
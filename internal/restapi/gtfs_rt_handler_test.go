@@ -0,0 +1,61 @@
+package restapi
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	gogtfs "github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/pbformat"
+)
+
+func TestGtfsRTHandlers(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	trip := mustGetTrip(t, api)
+	lat, lon := float32(40.58), float32(-122.39)
+	api.GtfsManager.MockAddVehicleWithOptions("rt_v1", trip.ID, trip.RouteID, gtfs.MockVehicleOptions{
+		Position: &gogtfs.Position{Latitude: &lat, Longitude: &lon},
+	})
+	delay := 90 * time.Second
+	api.GtfsManager.MockAddTripUpdate(trip.ID, &delay, nil)
+
+	t.Run("vehicle positions", func(t *testing.T) {
+		resp, body := callRawEndpoint(t, api, "/gtfs-rt/vehicle-positions.pb", url.Values{})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, pbformat.ContentType, resp.Header.Get("Content-Type"))
+
+		realtime, err := gogtfs.ParseRealtime(body, &gogtfs.ParseRealtimeOptions{})
+		require.NoError(t, err)
+		i := slices.IndexFunc(realtime.Vehicles, func(v gogtfs.Vehicle) bool { return v.GetID().ID == "rt_v1" })
+		require.GreaterOrEqual(t, i, 0)
+		vehicle := realtime.Vehicles[i]
+		assert.Equal(t, trip.ID, vehicle.GetTrip().ID.ID)
+		require.NotNil(t, vehicle.Position)
+		assert.InDelta(t, lat, *vehicle.Position.Latitude, 1e-5)
+	})
+
+	t.Run("trip updates", func(t *testing.T) {
+		resp, body := callRawEndpoint(t, api, "/gtfs-rt/trip-updates.pb", url.Values{})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		realtime, err := gogtfs.ParseRealtime(body, &gogtfs.ParseRealtimeOptions{})
+		require.NoError(t, err)
+		i := slices.IndexFunc(realtime.Trips, func(rt gogtfs.Trip) bool { return rt.ID.ID == trip.ID })
+		require.GreaterOrEqual(t, i, 0)
+		require.NotNil(t, realtime.Trips[i].Delay)
+		assert.Equal(t, delay, *realtime.Trips[i].Delay)
+	})
+
+	t.Run("requires a key", func(t *testing.T) {
+		resp, _ := callRawEndpoint(t, api, "/gtfs-rt/trip-updates.pb", url.Values{"key": {"invalid"}})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -1,10 +1,12 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
+	"maglev.onebusaway.org/internal/buildinfo"
 	"maglev.onebusaway.org/internal/logging"
 )
 
@@ -16,46 +18,72 @@ type DataFreshness struct {
 
 // HealthResponse represents the JSON response from the health endpoint.
 type HealthResponse struct {
-	Status        string         `json:"status"`
-	Detail        string         `json:"detail,omitempty"`
-	FeedExpiresAt string         `json:"feed_expires_at,omitempty"`
-	DataExpired   bool           `json:"data_expired,omitempty"`
-	DataFreshness *DataFreshness `json:"dataFreshness,omitempty"`
+	Status        string `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	FeedExpiresAt string `json:"feed_expires_at,omitempty"`
+	DataExpired   bool   `json:"data_expired,omitempty"`
+	// FeedExpiringSoon is set while the feed's service ends within the
+	// configured warning window.
+	FeedExpiringSoon bool           `json:"feed_expiring_soon,omitempty"`
+	Degraded         bool           `json:"degraded,omitempty"`
+	MirrorSources    []string       `json:"mirror_sources,omitempty"`
+	DataFreshness    *DataFreshness `json:"dataFreshness,omitempty"`
+	Canary           []CanaryResult `json:"canary,omitempty"`
+	// Components reports each subsystem by name: "database", "static_gtfs",
+	// "spatial_index" and "realtime/<feed ID>" for each enabled realtime feed.
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+	// Build identifies the running binary for support triage.
+	Build *buildinfo.Info `json:"build,omitempty"`
+}
+
+// ComponentStatus reports the state of one subsystem on /healthz and /readyz.
+type ComponentStatus struct {
+	Status      string     `json:"status"`
+	Detail      string     `json:"detail,omitempty"`
+	LastUpdated *time.Time `json:"last_updated,omitempty"`
+	ValidUntil  *time.Time `json:"valid_until,omitempty"`
 }
 
 // healthHandler verifies database connectivity and readiness.
 // It returns 503 Service Unavailable if the manager is not fully initialized and indexed.
 func (api *RestAPI) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	build := buildinfo.Current()
 
 	// 1. Liveness Check: Is the basic infrastructure initialized?
-	if api.Application == nil || api.GtfsManager == nil || api.GtfsManager.GtfsDB == nil || api.GtfsManager.GtfsDB.DB == nil {
+	if !api.healthInfrastructureReady() {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		_ = json.NewEncoder(w).Encode(HealthResponse{
 			Status: "unavailable",
 			Detail: "manager or database not initialized",
+			Build:  &build,
 		})
 		return
 	}
 
+	components := api.componentStatuses(r.Context(), time.Now())
+
 	// 2. Readiness Check: Is the GTFS data indexed and ready for traffic?
 	// This prevents routing traffic to "cold" instances still building spatial indexes.
 	if !api.GtfsManager.IsReady() {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		_ = json.NewEncoder(w).Encode(HealthResponse{
-			Status: "starting",
-			Detail: "GTFS data is being indexed and initialized",
+			Status:     "starting",
+			Detail:     "GTFS data is being indexed and initialized",
+			Components: components,
+			Build:      &build,
 		})
 		return
 	}
 
 	// 3. Connectivity Check: Is the database actually reachable?
-	if err := api.GtfsManager.GtfsDB.DB.PingContext(r.Context()); err != nil {
-		logging.LogError(api.Logger, "GTFS DB ping failed", err)
+	if components["database"].Status != "ok" {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		_ = json.NewEncoder(w).Encode(HealthResponse{
-			Status: "unavailable",
-			Detail: "database connection failed",
+			Status:     "unavailable",
+			Detail:     "database connection failed",
+			Components: components,
+			Build:      &build,
 		})
 		return
 	}
@@ -78,14 +106,131 @@ func (api *RestAPI) healthHandler(w http.ResponseWriter, r *http.Request) {
 	response := HealthResponse{
 		Status:        "ok",
 		DataFreshness: freshness,
+		Components:    components,
+		Build:         &build,
+	}
+
+	// Serving from the feed mirror is still healthy enough to take traffic, so
+	// degraded mode is reported without changing the status code.
+	if mirrorSources := api.GtfsManager.DegradedSources(); len(mirrorSources) > 0 {
+		response.Status = "degraded"
+		response.Detail = "serving mirrored feed data because an upstream feed is unreachable"
+		response.Degraded = true
+		response.MirrorSources = mirrorSources
+	}
+
+	if api.canary != nil {
+		response.Canary = api.canary.Results()
+		for _, result := range response.Canary {
+			if result.OK {
+				continue
+			}
+			if !response.Degraded {
+				response.Status = "degraded"
+				response.Detail = "synthetic canary probe failing: " + result.Probe
+				response.Degraded = true
+			}
+			break
+		}
 	}
 
 	expiresAt := api.GtfsManager.FeedExpiresAt(r.Context())
 	if !expiresAt.IsZero() {
 		response.FeedExpiresAt = expiresAt.Format(time.RFC3339)
-		response.DataExpired = time.Now().After(expiresAt)
+		now := time.Now()
+		response.DataExpired = now.After(expiresAt)
+		response.FeedExpiringSoon = api.GtfsManager.FeedExpiringSoon(expiresAt, now)
 	}
 
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// readyHandler reports whether the instance should receive traffic. It
+// returns 503 until the initial data load completes and while the database is
+// unreachable; unlike /healthz it ignores degraded mirrors and canary probes.
+func (api *RestAPI) readyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !api.healthInfrastructureReady() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(HealthResponse{
+			Status: "unavailable",
+			Detail: "manager or database not initialized",
+		})
+		return
+	}
+
+	components := api.componentStatuses(r.Context(), time.Now())
+	response := HealthResponse{Status: "ready", Components: components}
+	status := http.StatusOK
+	switch {
+	case !api.GtfsManager.IsReady():
+		response.Status = "starting"
+		response.Detail = "GTFS data is being indexed and initialized"
+		status = http.StatusServiceUnavailable
+	case components["database"].Status != "ok":
+		response.Status = "unavailable"
+		response.Detail = "database connection failed"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (api *RestAPI) healthInfrastructureReady() bool {
+	return api.Application != nil && api.GtfsManager != nil && api.GtfsManager.GtfsDB != nil && api.GtfsManager.GtfsDB.DB != nil
+}
+
+// componentStatuses checks each subsystem. Realtime feeds that have not
+// succeeded for three refresh intervals are "stale"; feeds never fetched are
+// "pending".
+func (api *RestAPI) componentStatuses(ctx context.Context, now time.Time) map[string]ComponentStatus {
+	manager := api.GtfsManager
+	components := make(map[string]ComponentStatus)
+
+	if err := manager.GtfsDB.DB.PingContext(ctx); err != nil {
+		logging.LogError(api.Logger, "GTFS DB ping failed", err)
+		components["database"] = ComponentStatus{Status: "unavailable", Detail: "database connection failed"}
+		return components
+	}
+	components["database"] = ComponentStatus{Status: "ok"}
+
+	static := ComponentStatus{Status: "loading"}
+	if loaded := manager.GetStaticLastUpdated(ctx); !loaded.IsZero() {
+		static.Status = "ok"
+		static.LastUpdated = &loaded
+	}
+	if expiresAt := manager.FeedExpiresAt(ctx); !expiresAt.IsZero() {
+		static.ValidUntil = &expiresAt
+		switch {
+		case now.After(expiresAt):
+			static.Status = "expired"
+		case manager.FeedExpiringSoon(expiresAt, now):
+			static.Status = "expiring"
+		}
+	}
+	components["static_gtfs"] = static
+
+	if manager.IsReady() {
+		components["spatial_index"] = ComponentStatus{Status: "ok"}
+	} else {
+		components["spatial_index"] = ComponentStatus{Status: "building"}
+	}
+
+	for _, feed := range manager.RealtimeFeedStatuses(now) {
+		status := ComponentStatus{Status: "ok"}
+		switch {
+		case feed.LastUpdated.IsZero():
+			status.Status = "pending"
+		case feed.Stale:
+			status.Status = "stale"
+		}
+		if !feed.LastUpdated.IsZero() {
+			status.LastUpdated = &feed.LastUpdated
+		}
+		components["realtime/"+feed.ID] = status
+	}
+	return components
+}
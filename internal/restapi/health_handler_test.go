@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"maglev.onebusaway.org/internal/app"
 	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/buildinfo"
 )
 
 func TestHealthHandlerWithNilApplication(t *testing.T) {
@@ -66,6 +67,9 @@ func TestHealthHandlerReturnsOK(t *testing.T) {
 	assert.Equal(t, "ok", healthResp.Status)
 	assert.NotEmpty(t, healthResp.FeedExpiresAt)
 	assert.False(t, healthResp.DataExpired)
+	assert.True(t, healthResp.FeedExpiringSoon, "a feed ending tomorrow is inside the default 7 day window")
+	require.NotNil(t, healthResp.Build)
+	assert.Equal(t, buildinfo.Current(), *healthResp.Build)
 }
 
 func TestHealthHandlerReturnsExpired(t *testing.T) {
@@ -99,6 +103,32 @@ func TestHealthHandlerReturnsExpired(t *testing.T) {
 	assert.Equal(t, "ok", healthResp.Status)
 	assert.NotEmpty(t, healthResp.FeedExpiresAt)
 	assert.True(t, healthResp.DataExpired)
+	assert.False(t, healthResp.FeedExpiringSoon)
+}
+
+func TestHealthHandlerReportsDegradedMirror(t *testing.T) {
+	manager := newTestManagerNoData(t)
+	manager.MarkReady()
+	manager.SetDegradedSourceForTest("static")
+
+	api := NewRestAPI(&app.Application{
+		GtfsManager: manager,
+		Config: appconf.Config{
+			RateLimit: 100,
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	api.healthHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var healthResp HealthResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&healthResp))
+	assert.Equal(t, "degraded", healthResp.Status)
+	assert.True(t, healthResp.Degraded)
+	assert.Equal(t, []string{"static"}, healthResp.MirrorSources)
 }
 
 func TestHealthHandlerStarting(t *testing.T) {
@@ -176,3 +206,70 @@ func TestHealthHandlerVerboseMode(t *testing.T) {
 	assert.NotNil(t, healthRespVerbose.DataFreshness.StaticGtfsLastUpdated)
 	assert.Contains(t, healthRespVerbose.DataFreshness.RealtimeFeeds, "feed-1")
 }
+
+func TestReadyHandler(t *testing.T) {
+	manager := newTestManagerNoData(t)
+	api := NewRestAPI(&app.Application{
+		GtfsManager: manager,
+		Config: appconf.Config{
+			RateLimit: 100,
+		},
+	})
+	mux := http.NewServeMux()
+	api.SetRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func() (int, HealthResponse) {
+		resp, err := http.Get(server.URL + "/readyz")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var readyResp HealthResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&readyResp))
+		return resp.StatusCode, readyResp
+	}
+
+	code, readyResp := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code, "not ready until the initial load completes")
+	assert.Equal(t, "starting", readyResp.Status)
+	assert.Equal(t, "ok", readyResp.Components["database"].Status)
+	assert.Equal(t, "loading", readyResp.Components["static_gtfs"].Status)
+	assert.Equal(t, "building", readyResp.Components["spatial_index"].Status)
+
+	manager.SetStaticLastUpdatedForTest(context.Background(), time.Now().UTC())
+	manager.SetFeedExpiresAtForTest(context.Background(), time.Now().Add(60*24*time.Hour))
+	manager.SetDegradedSourceForTest("static")
+	manager.MarkReady()
+
+	code, readyResp = get()
+	assert.Equal(t, http.StatusOK, code, "a degraded mirror still takes traffic")
+	assert.Equal(t, "ready", readyResp.Status)
+	static := readyResp.Components["static_gtfs"]
+	assert.Equal(t, "ok", static.Status)
+	assert.NotNil(t, static.LastUpdated)
+	assert.NotNil(t, static.ValidUntil)
+	assert.Equal(t, "ok", readyResp.Components["spatial_index"].Status)
+}
+
+func TestHealthHandlerReportsComponents(t *testing.T) {
+	manager := newTestManagerNoData(t)
+	manager.SetFeedExpiresAtForTest(context.Background(), time.Now().Add(-time.Hour))
+	manager.MarkReady()
+
+	api := NewRestAPI(&app.Application{
+		GtfsManager: manager,
+		Config: appconf.Config{
+			RateLimit: 100,
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	api.healthHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var healthResp HealthResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&healthResp))
+	assert.Equal(t, "ok", healthResp.Components["database"].Status)
+	assert.Equal(t, "expired", healthResp.Components["static_gtfs"].Status)
+}
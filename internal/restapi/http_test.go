@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -188,6 +189,24 @@ func callAPIHandler[ResponseType any](t testing.TB, api *RestAPI, endpoint strin
 	return resp, response
 }
 
+// callRawEndpoint requests an endpoint whose response is not an OBA JSON
+// envelope, such as the SIRI and GTFS-RT feeds, and returns the response with
+// its body. key=TEST is added unless params sets a key.
+func callRawEndpoint(t testing.TB, api *RestAPI, path string, params url.Values) (*http.Response, []byte) {
+	t.Helper()
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+	if !params.Has("key") {
+		params.Set("key", "TEST")
+	}
+	resp, err := http.Get(server.URL + path + "?" + params.Encode())
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
 // mustGetRoutes returns all routes from the DB, failing the test immediately on error.
 func mustGetRoutes(t testing.TB, api *RestAPI) []gtfsdb.Route {
 	t.Helper()
@@ -361,7 +380,7 @@ func TestCompressionConfig(t *testing.T) {
 	})
 
 	t.Run("custom config is applied", func(t *testing.T) {
-		config := CompressionConfig{
+		config := appconf.CompressionConfig{
 			MinSize: 2048,
 			Level:   9,
 		}
@@ -0,0 +1,246 @@
+package restapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/logging"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// LoadShedPriority ranks endpoints for load shedding; lower priorities are shed first.
+type LoadShedPriority int
+
+const (
+	LoadShedPriorityLow LoadShedPriority = iota
+	LoadShedPriorityNormal
+	// LoadShedPriorityCritical endpoints are never shed.
+	LoadShedPriorityCritical
+)
+
+const (
+	// Pressure at which normal-priority requests are shed as well as low ones.
+	loadShedSeverePressure = 1.5
+	loadShedRetryAfter     = time.Second
+	// Latency samples kept for the p99 estimate, and how often it is recomputed.
+	// Samples older than loadShedLatencyMaxAge are ignored so the estimate
+	// recovers even when shedding has stopped new samples from arriving.
+	loadShedLatencyWindow  = 1000
+	loadShedLatencyMaxAge  = 10 * time.Second
+	loadShedP99RefreshRate = time.Second
+)
+
+// defaultLoadShedPriorities keeps arrivals responsive for departure boards and
+// sheds the expensive spatial and search queries first. Endpoints not listed
+// here are normal priority.
+var defaultLoadShedPriorities = map[string]LoadShedPriority{
+	"arrivals-and-departures-for-stop": LoadShedPriorityCritical,
+	"arrival-and-departure-for-stop":   LoadShedPriorityCritical,
+	"current-time":                     LoadShedPriorityCritical,
+	"trips-for-location":               LoadShedPriorityLow,
+	"stops-for-location":               LoadShedPriorityLow,
+	"routes-for-location":              LoadShedPriorityLow,
+	"search/stop":                      LoadShedPriorityLow,
+	"search/route":                     LoadShedPriorityLow,
+	"schedule-for-route":               LoadShedPriorityLow,
+}
+
+// ParseLoadShedPriority converts a configured priority name into a LoadShedPriority.
+func ParseLoadShedPriority(name string) (LoadShedPriority, bool) {
+	switch name {
+	case "low":
+		return LoadShedPriorityLow, true
+	case "normal":
+		return LoadShedPriorityNormal, true
+	case "critical":
+		return LoadShedPriorityCritical, true
+	default:
+		return 0, false
+	}
+}
+
+// LoadShedder rejects low-priority API requests with 503 when the server is
+// overloaded. Load is measured as pressure: the larger of the in-flight request
+// count relative to MaxInFlight and the recent p99 latency relative to TargetP99.
+// At pressure 1 low-priority requests are shed; at loadShedSeverePressure normal
+// ones are too. Critical endpoints are always served.
+type LoadShedder struct {
+	maxInFlight int64
+	targetP99   time.Duration
+	priorities  map[string]LoadShedPriority
+	clock       clock.Clock // only used for the response's currentTime; latency uses wall time
+
+	inFlight atomic.Int64
+
+	mu            sync.Mutex
+	latencies     []latencySample
+	next          int
+	p99           time.Duration
+	p99ComputedAt time.Time
+}
+
+// NewLoadShedder returns nil when cfg enables neither signal, which disables shedding.
+func NewLoadShedder(cfg appconf.LoadSheddingConfig, c clock.Clock) *LoadShedder {
+	if cfg.MaxInFlight <= 0 && cfg.TargetP99 <= 0 {
+		return nil
+	}
+
+	priorities := make(map[string]LoadShedPriority, len(defaultLoadShedPriorities)+len(cfg.Priorities))
+	for endpoint, priority := range defaultLoadShedPriorities {
+		priorities[endpoint] = priority
+	}
+	for endpoint, name := range cfg.Priorities {
+		// Priority names are validated when the config is loaded.
+		if priority, ok := ParseLoadShedPriority(name); ok {
+			priorities[endpoint] = priority
+		}
+	}
+
+	return &LoadShedder{
+		maxInFlight: int64(cfg.MaxInFlight),
+		targetP99:   cfg.TargetP99,
+		priorities:  priorities,
+		clock:       c,
+		latencies:   make([]latencySample, 0, loadShedLatencyWindow),
+	}
+}
+
+// Handler returns the load shedding middleware. It must run inside the mux so
+// that r.Pattern identifies the endpoint.
+func (ls *LoadShedder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ls.shouldShed(ls.priorityFor(r.Pattern)) {
+			ls.sendOverloaded(w)
+			return
+		}
+
+		// Long polls wait on purpose and would read as overload.
+		if isLongPoll(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ls.inFlight.Add(1)
+		start := time.Now()
+		defer func() {
+			ls.inFlight.Add(-1)
+			ls.recordLatency(start, time.Since(start))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ls *LoadShedder) shouldShed(priority LoadShedPriority) bool {
+	if priority == LoadShedPriorityCritical {
+		return false
+	}
+	pressure := ls.pressure()
+	if pressure >= loadShedSeverePressure {
+		return true
+	}
+	return pressure >= 1 && priority == LoadShedPriorityLow
+}
+
+func (ls *LoadShedder) pressure() float64 {
+	var pressure float64
+	if ls.maxInFlight > 0 {
+		pressure = float64(ls.inFlight.Load()) / float64(ls.maxInFlight)
+	}
+	if ls.targetP99 > 0 {
+		pressure = math.Max(pressure, float64(ls.currentP99())/float64(ls.targetP99))
+	}
+	return pressure
+}
+
+func (ls *LoadShedder) priorityFor(pattern string) LoadShedPriority {
+	if priority, ok := ls.priorities[endpointFromPattern(pattern)]; ok {
+		return priority
+	}
+	return LoadShedPriorityNormal
+}
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+func (ls *LoadShedder) recordLatency(at time.Time, d time.Duration) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	sample := latencySample{at: at, duration: d}
+	if len(ls.latencies) < loadShedLatencyWindow {
+		ls.latencies = append(ls.latencies, sample)
+		return
+	}
+	ls.latencies[ls.next] = sample
+	ls.next = (ls.next + 1) % loadShedLatencyWindow
+}
+
+// currentP99 returns the p99 of the recent latency window, recomputing it at
+// most once per loadShedP99RefreshRate so the hot path stays cheap.
+func (ls *LoadShedder) currentP99() time.Duration {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(ls.p99ComputedAt) < loadShedP99RefreshRate {
+		return ls.p99
+	}
+	ls.p99ComputedAt = now
+
+	recent := make([]time.Duration, 0, len(ls.latencies))
+	for _, sample := range ls.latencies {
+		if now.Sub(sample.at) <= loadShedLatencyMaxAge {
+			recent = append(recent, sample.duration)
+		}
+	}
+	ls.p99 = 0
+	if len(recent) > 0 {
+		slices.Sort(recent)
+		ls.p99 = recent[(len(recent)*99)/100]
+	}
+	return ls.p99
+}
+
+// endpointFromPattern reduces a mux pattern such as
+// "GET /api/where/stop/{id}" or "GET /api/where/search/stop.json" to the
+// endpoint name used for priorities ("stop", "search/stop").
+func endpointFromPattern(pattern string) string {
+	_, path, found := strings.Cut(pattern, " ")
+	if !found {
+		path = pattern
+	}
+	path = strings.TrimPrefix(path, "/api/where/")
+	path = strings.TrimSuffix(path, "/{id}")
+	return strings.TrimSuffix(path, ".json")
+}
+
+// sendOverloaded sends a 503 Service Unavailable response with Retry-After.
+func (ls *LoadShedder) sendOverloaded(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(int(loadShedRetryAfter.Seconds())))
+	w.WriteHeader(http.StatusServiceUnavailable)
+
+	response := models.ResponseModel{
+		Code:        http.StatusServiceUnavailable,
+		CurrentTime: models.ResponseCurrentTime(ls.clock),
+		Text:        "Server is overloaded. Please try again later.",
+		Version:     models.APIVersion,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger := slog.Default().With(slog.String("component", "load_shed_middleware"))
+		logging.LogError(logger, "failed to encode load shed response", err)
+	}
+}
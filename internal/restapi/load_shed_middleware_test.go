@@ -0,0 +1,152 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func serveThroughLoadShedder(ls *LoadShedder, pattern string) *httptest.ResponseRecorder {
+	handler := ls.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/api/where/test.json?key=TEST", nil)
+	req.Pattern = pattern
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewLoadShedder_DisabledWithoutTargets(t *testing.T) {
+	assert.Nil(t, NewLoadShedder(appconf.LoadSheddingConfig{}, clock.RealClock{}))
+	assert.NotNil(t, NewLoadShedder(appconf.LoadSheddingConfig{MaxInFlight: 10}, clock.RealClock{}))
+	assert.NotNil(t, NewLoadShedder(appconf.LoadSheddingConfig{TargetP99: time.Second}, clock.RealClock{}))
+}
+
+func TestEndpointFromPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"GET /api/where/stop/{id}", "stop"},
+		{"GET /api/where/trips-for-location.json", "trips-for-location"},
+		{"GET /api/where/search/stop.json", "search/stop"},
+		{"GET /api/where/arrivals-and-departures-for-stop/{id}", "arrivals-and-departures-for-stop"},
+		{"/api/where/current-time.json", "current-time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.expected, endpointFromPattern(tt.pattern))
+		})
+	}
+}
+
+func TestLoadShedder_ShedsByPriorityUnderInFlightPressure(t *testing.T) {
+	const (
+		lowPattern      = "GET /api/where/trips-for-location.json"
+		normalPattern   = "GET /api/where/stop/{id}"
+		criticalPattern = "GET /api/where/arrivals-and-departures-for-stop/{id}"
+	)
+
+	tests := []struct {
+		name     string
+		inFlight int64
+		pattern  string
+		expected int
+	}{
+		{"below target admits low", 9, lowPattern, http.StatusOK},
+		{"at target sheds low", 10, lowPattern, http.StatusServiceUnavailable},
+		{"at target admits normal", 10, normalPattern, http.StatusOK},
+		{"severe pressure sheds normal", 15, normalPattern, http.StatusServiceUnavailable},
+		{"severe pressure admits critical", 50, criticalPattern, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := NewLoadShedder(appconf.LoadSheddingConfig{MaxInFlight: 10}, clock.RealClock{})
+			ls.inFlight.Store(tt.inFlight)
+
+			w := serveThroughLoadShedder(ls, tt.pattern)
+
+			assert.Equal(t, tt.expected, w.Code)
+			assert.Equal(t, tt.inFlight, ls.inFlight.Load(), "in-flight count must be restored after the request")
+		})
+	}
+}
+
+func TestLoadShedder_ShedResponse(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	ls := NewLoadShedder(appconf.LoadSheddingConfig{MaxInFlight: 1}, mockClock)
+	ls.inFlight.Store(1)
+
+	w := serveThroughLoadShedder(ls, "GET /api/where/search/stop.json")
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response models.ResponseModel
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, mockClock.Now().UnixMilli(), response.CurrentTime)
+	assert.Equal(t, models.APIVersion, response.Version)
+}
+
+func TestLoadShedder_ShedsOnRecentP99Latency(t *testing.T) {
+	ls := NewLoadShedder(appconf.LoadSheddingConfig{TargetP99: 100 * time.Millisecond}, clock.RealClock{})
+	for range 100 {
+		ls.recordLatency(time.Now(), 200*time.Millisecond)
+	}
+
+	w := serveThroughLoadShedder(ls, "GET /api/where/stops-for-location.json")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestLoadShedder_IgnoresStaleLatencySamples(t *testing.T) {
+	ls := NewLoadShedder(appconf.LoadSheddingConfig{TargetP99: 100 * time.Millisecond}, clock.RealClock{})
+	stale := time.Now().Add(-2 * loadShedLatencyMaxAge)
+	for range 100 {
+		ls.recordLatency(stale, 200*time.Millisecond)
+	}
+
+	w := serveThroughLoadShedder(ls, "GET /api/where/stops-for-location.json")
+	assert.Equal(t, http.StatusOK, w.Code, "old slow requests must not keep shedding once load has passed")
+}
+
+func TestLoadShedder_ConfiguredPrioritiesOverrideDefaults(t *testing.T) {
+	ls := NewLoadShedder(appconf.LoadSheddingConfig{
+		MaxInFlight: 10,
+		Priorities: map[string]string{
+			"stop":               "low",
+			"trips-for-location": "critical",
+		},
+	}, clock.RealClock{})
+	ls.inFlight.Store(10)
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveThroughLoadShedder(ls, "GET /api/where/stop/{id}").Code)
+	assert.Equal(t, http.StatusOK, serveThroughLoadShedder(ls, "GET /api/where/trips-for-location.json").Code)
+}
+
+// TestLoadShedder_ResolvesEndpointThroughMux verifies that the mux pattern
+// reaches the shedder, so priorities apply to real routes.
+func TestLoadShedder_ResolvesEndpointThroughMux(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	api.loadShedder = NewLoadShedder(appconf.LoadSheddingConfig{MaxInFlight: 10}, api.Clock)
+	api.loadShedder.inFlight.Store(10)
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api, "/api/where/stops-for-location.json?key=TEST&lat=38.56&lon=-121.76")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, model.Code)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key=TEST")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "critical endpoints are never shed")
+}
@@ -2,7 +2,9 @@ package restapi
 
 import (
 	"net/http"
+	"net/url"
 
+	"maglev.onebusaway.org/internal/appconf"
 	"maglev.onebusaway.org/internal/gtfs"
 	"maglev.onebusaway.org/internal/utils"
 )
@@ -39,3 +41,49 @@ func (api *RestAPI) parseLocationParams(r *http.Request, fieldErrors map[string]
 		LonSpan: lonSpan,
 	}, nil
 }
+
+// searchConfig returns the location search radii and maxCount limits in effect.
+func (api *RestAPI) searchConfig() appconf.SearchConfig {
+	return api.CurrentConfig().Search.WithDefaults()
+}
+
+// parseSearchLocation reads the optional lat/lon (and radius) that search
+// endpoints rank results by. It returns nil when neither lat nor lon is given.
+func (api *RestAPI) parseSearchLocation(r *http.Request, fieldErrors map[string][]string) (*gtfs.LocationParams, map[string][]string) {
+	queryParams := r.URL.Query()
+	hasLat, hasLon := queryParams.Get("lat") != "", queryParams.Get("lon") != ""
+	if !hasLat && !hasLon {
+		return nil, fieldErrors
+	}
+	if hasLat != hasLon {
+		if fieldErrors == nil {
+			fieldErrors = make(map[string][]string)
+		}
+		missing := "lat"
+		if hasLat {
+			missing = "lon"
+		}
+		fieldErrors[missing] = append(fieldErrors[missing], "lat and lon must be given together")
+		return nil, fieldErrors
+	}
+	return api.parseLocationParams(r, fieldErrors)
+}
+
+// parseRouteTypeFilter reads the route types to filter location searches by. The
+// "routeTypes" parameter only accepts valid GTFS route type codes; the legacy
+// "routeType" parameter is still honored as-is for backwards compatibility.
+func parseRouteTypeFilter(queryParams url.Values, fieldErrors map[string][]string) ([]int, map[string][]string) {
+	legacyRouteTypes, fieldErrors := utils.ParseRouteTypes(queryParams, "routeType", fieldErrors)
+	routeTypes, fieldErrors := utils.ParseRouteTypes(queryParams, "routeTypes", fieldErrors)
+
+	for _, routeType := range routeTypes {
+		if err := utils.ValidateRouteType(routeType); err != nil {
+			if fieldErrors == nil {
+				fieldErrors = make(map[string][]string)
+			}
+			fieldErrors["routeTypes"] = append(fieldErrors["routeTypes"], err.Error())
+		}
+	}
+
+	return append(legacyRouteTypes, routeTypes...), fieldErrors
+}
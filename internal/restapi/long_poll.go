@@ -0,0 +1,146 @@
+package restapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+const (
+	defaultLongPollTimeoutSeconds = 25
+	// longPollWriteSlack is added to the write deadline of a held request so
+	// its response can still be written once the wait is over.
+	longPollWriteSlack = 10 * time.Second
+)
+
+// longPollParams are the query parameters of a long poll. timeout is in
+// seconds and is capped at a minute.
+type longPollParams struct {
+	WaitForChange bool `query:"waitForChange"`
+	Timeout       int  `query:"timeout,min=1,max=60,clamp"`
+}
+
+// isLongPoll reports whether r asks to be held open until its response
+// changes, or is a realtime stream. Load shedding and SLO tracking use it to
+// leave the deliberate wait out of their latency measurements.
+func isLongPoll(r *http.Request) bool {
+	if r.URL.Path == streamPath {
+		return true
+	}
+	wait, _ := strconv.ParseBool(r.URL.Query().Get("waitForChange"))
+	return wait
+}
+
+// longPolled lets departure boards wait for new predictions instead of
+// polling. With waitForChange=true the request is held open until the
+// response's ETag differs from the If-None-Match header, or, without one,
+// from the ETag of the response when the request arrived. Responses are
+// recomputed whenever the realtime feeds are merged, and the current one is
+// sent when timeout seconds (25 by default) pass without a change. Error
+// responses and responses without an ETag are sent straight away. handler
+// must set the ETag of its responses from their predictions; see
+// arrivalsETag.
+func longPolled(api *RestAPI, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := longPollParams{Timeout: defaultLongPollTimeoutSeconds}
+		if fieldErrors := bindQuery(r.URL.Query(), &params, nil); len(fieldErrors) > 0 {
+			api.validationErrorResponse(w, r, fieldErrors)
+			return
+		}
+		if !params.WaitForChange || api.GtfsManager == nil {
+			handler(w, r)
+			return
+		}
+
+		timeout := time.Duration(params.Timeout) * time.Second
+		// Otherwise the server's write timeout cuts the response off.
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + longPollWriteSlack))
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		baseline := r.Header.Get("If-None-Match")
+		for {
+			// Subscribe first so a merge during the computation is not missed.
+			changed := api.GtfsManager.RealtimeChanged()
+			rec := &coalescingRecorder{header: make(http.Header)}
+			handler(rec, r)
+			etag := rec.header.Get("ETag")
+			if baseline == "" {
+				baseline = etag
+			}
+			if etag == "" || (rec.status != 0 && rec.status != http.StatusOK) || !etagMatches(baseline, etag) {
+				rec.replay(w)
+				return
+			}
+
+			select {
+			case <-changed:
+			case <-timer.C:
+				handler(w, r)
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// replay writes the buffered response to w.
+func (rec *coalescingRecorder) replay(w http.ResponseWriter) {
+	for name, values := range rec.header {
+		w.Header()[name] = slices.Clone(values)
+	}
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body.Bytes())
+}
+
+// arrivalsETag identifies what a rider sees of a list of arrivals: which
+// trips arrive, when, whether the times are predicted, and the vehicles,
+// occupancy and alerts that go with them. Vehicle distances are left out, as
+// they change with every position report.
+func arrivalsETag(arrivals ...models.ArrivalAndDeparture) string {
+	h := sha256.New()
+	var buf []byte
+	for _, a := range arrivals {
+		buf = buf[:0]
+		buf = append(buf, a.TripID...)
+		buf = append(buf, 0)
+		buf = append(buf, a.StopID...)
+		buf = append(buf, 0)
+		buf = strconv.AppendInt(buf, a.ServiceDate.UnixMilli(), 10)
+		buf = append(buf, 0)
+		buf = strconv.AppendInt(buf, int64(a.StopSequence), 10)
+		buf = append(buf, 0)
+		buf = strconv.AppendBool(buf, a.Predicted)
+		buf = append(buf, 0)
+		buf = strconv.AppendInt(buf, a.PredictedArrivalTime.UnixMilli(), 10)
+		buf = append(buf, 0)
+		buf = strconv.AppendInt(buf, a.PredictedDepartureTime.UnixMilli(), 10)
+		buf = append(buf, 0)
+		buf = strconv.AppendInt(buf, int64(a.NumberOfStopsAway), 10)
+		buf = append(buf, 0)
+		buf = append(buf, a.VehicleID...)
+		buf = append(buf, 0)
+		buf = append(buf, a.Status...)
+		buf = append(buf, 0)
+		buf = append(buf, a.OccupancyStatus...)
+		buf = append(buf, 0)
+		buf = append(buf, a.PredictedOccupancy...)
+		for _, id := range a.SituationIDs {
+			buf = append(buf, 0)
+			buf = append(buf, id...)
+		}
+		buf = append(buf, '\n')
+		h.Write(buf)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
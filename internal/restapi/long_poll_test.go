@@ -0,0 +1,104 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func longPollRequest(t *testing.T, server *httptest.Server, endpoint, ifNoneMatch string) (*http.Response, time.Duration) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, server.URL+endpoint, nil)
+	require.NoError(t, err)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, resp.Body.Close())
+	return resp, time.Since(start)
+}
+
+func TestLongPolledArrivals(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 2, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	_, combinedStopID, tripID, _ := setupDelayPropTestData(t, api, 1)
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	plain, elapsed := longPollRequest(t, server, arrivalsAndDeparturesURL(combinedStopID), "")
+	require.Equal(t, http.StatusOK, plain.StatusCode)
+	etag := plain.Header.Get("ETag")
+	require.NotEmpty(t, etag, "arrivals responses carry an ETag of their predictions")
+	assert.Less(t, elapsed, time.Second)
+
+	waiting := url.Values{"waitForChange": {"true"}, "timeout": {"1"}}
+
+	t.Run("times out without a change", func(t *testing.T) {
+		resp, elapsed := longPollRequest(t, server, arrivalsAndDeparturesURL(combinedStopID, waiting), "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.GreaterOrEqual(t, elapsed, time.Second)
+		assert.Equal(t, "no-cache, no-store, must-revalidate", resp.Header.Get("Cache-Control"))
+	})
+
+	t.Run("returns at once when the client is behind", func(t *testing.T) {
+		resp, elapsed := longPollRequest(t, server, arrivalsAndDeparturesURL(combinedStopID, waiting), `"stale"`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.Less(t, elapsed, time.Second)
+	})
+
+	t.Run("returns when predictions change", func(t *testing.T) {
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			delay := 3 * time.Minute
+			api.GtfsManager.MockAddVehicle("v1", tripID, "dp-route")
+			api.GtfsManager.MockAddTripUpdate(tripID, &delay, nil)
+		}()
+		long := url.Values{"waitForChange": {"true"}, "timeout": {"10"}}
+		resp, elapsed := longPollRequest(t, server, arrivalsAndDeparturesURL(combinedStopID, long), etag)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+		assert.Less(t, elapsed, 5*time.Second)
+	})
+
+	t.Run("validates its parameters", func(t *testing.T) {
+		resp, _ := longPollRequest(t, server, arrivalsAndDeparturesURL(combinedStopID, url.Values{"waitForChange": {"maybe"}}), "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestArrivalsETag(t *testing.T) {
+	arrival := models.ArrivalAndDeparture{
+		TripID:               "1_t",
+		StopID:               "1_s",
+		Predicted:            true,
+		PredictedArrivalTime: models.NewModelTime(time.UnixMilli(1_700_000_000_000)),
+		DistanceFromStop:     1200,
+	}
+	etag := arrivalsETag(arrival)
+
+	moved := arrival
+	moved.DistanceFromStop = 900
+	assert.Equal(t, etag, arrivalsETag(moved), "vehicle movement alone is not a change")
+
+	later := arrival
+	later.PredictedArrivalTime = models.NewModelTime(time.UnixMilli(1_700_000_060_000))
+	assert.NotEqual(t, etag, arrivalsETag(later))
+
+	assert.NotEqual(t, etag, arrivalsETag(arrival, arrival), "an added arrival is a change")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+}
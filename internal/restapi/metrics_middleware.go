@@ -41,6 +41,9 @@ func MetricsHandler(m *metrics.Metrics) func(http.Handler) http.Handler {
 
 			m.HTTPRequestsTotal.WithLabelValues(r.Method, path, status).Inc()
 			m.HTTPRequestDuration.WithLabelValues(r.Method, path).Observe(duration)
+			if wrapped.statusCode == http.StatusTooManyRequests {
+				m.RateLimitRejectionsTotal.WithLabelValues(path).Inc()
+			}
 		})
 	}
 }
@@ -55,3 +58,8 @@ func (w *metricsResponseWriter) WriteHeader(code int) {
 	w.statusCode = code
 	w.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
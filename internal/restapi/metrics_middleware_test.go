@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"maglev.onebusaway.org/internal/metrics"
@@ -168,3 +169,20 @@ func TestMetricsHandler_VariousStatusCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricsHandler_CountsRateLimitRejections(t *testing.T) {
+	m := metrics.New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/where/stop/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "limited.json" {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+	wrapped := MetricsHandler(m)(mux)
+
+	for _, id := range []string{"limited.json", "ok.json", "limited.json"} {
+		wrapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/where/stop/"+id, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.RateLimitRejectionsTotal.WithLabelValues("GET /api/where/stop/{id}")))
+}
@@ -0,0 +1,470 @@
+package restapi
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"maglev.onebusaway.org/internal/buildinfo"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/pbformat"
+)
+
+// openAPIOperation documents one route of SetRoutes in the OpenAPI spec.
+type openAPIOperation struct {
+	summary string
+	tag     string
+	// response is a value of the route's JSON response type; nil documents the
+	// plain response envelope.
+	response any
+	// contentType is the media type of non-JSON responses.
+	contentType string
+	// query lists the query parameters besides the API key.
+	query []string
+	// public routes need no API key.
+	public bool
+	// protobuf routes can also be served as protocol buffers (see
+	// ProtobufFormatMiddleware).
+	protobuf bool
+}
+
+var locationQuery = []string{"lat", "lon", "radius", "latSpan", "lonSpan"}
+
+// openAPIOperations documents every route of SetRoutes by its pattern.
+// TestOpenAPIOperationsCoverRoutes fails for routes missing here.
+var openAPIOperations = map[string]openAPIOperation{
+	"GET /healthz":              {summary: "Liveness check", tag: "health", contentType: "application/json", query: []string{"verbose"}, public: true},
+	"GET /readyz":               {summary: "Readiness check: whether the GTFS data is loaded", tag: "health", contentType: "application/json", public: true},
+	"GET /api/openapi.json":     {summary: "This OpenAPI description of the API", tag: "meta", contentType: "application/json", public: true},
+	"GET /api/docs":             {summary: "Swagger UI for this API", tag: "meta", contentType: "text/html", public: true},
+	"GET /api/v2/metadata.json": {summary: "Server and feed metadata", tag: "meta"},
+
+	"GET /api/where/agencies-with-coverage.json":               {summary: "Agencies served, with the center and span of their coverage", tag: "agencies", response: CoverageResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/search/stop.json":                          {summary: "Search stops by name or code", tag: "stops", response: StopsResponse{}, query: []string{"input", "maxCount", "lat", "lon", "radius"}},
+	"GET /api/where/search/route.json":                         {summary: "Search routes by name", tag: "routes", response: RoutesResponse{}, query: []string{"input", "maxCount", "lat", "lon", "radius"}},
+	"GET /api/where/current-time.json":                         {summary: "The server's current time", tag: "meta"},
+	"GET /api/where/stops-for-location.json":                   {summary: "Stops near a location", tag: "stops", response: StopsResponse{}, query: slices.Concat(locationQuery, []string{"query", "maxCount", "routeTypes", "includeInactive", "time"})},
+	"GET /api/where/stop-clusters.json":                        {summary: "Stops within a bounding box, clustered for a map zoom level", tag: "stops", query: []string{"bbox", "zoom"}},
+	"GET /api/where/routes-for-location.json":                  {summary: "Routes serving stops near a location", tag: "routes", response: RoutesResponse{}, query: slices.Concat(locationQuery, []string{"query", "maxCount", "routeTypes"})},
+	"GET /api/where/arrivals-and-departures-for-location.json": {summary: "Arrivals and departures at the stops near a location", tag: "arrivals", response: ArrivalsAndDeparturesForLocationResponse{}, query: slices.Concat(locationQuery, []string{"maxCount", "minutesBefore", "minutesAfter", "time"}), protobuf: true},
+	"GET /api/where/sms.txt":                                   {summary: "Arrivals at a stop as an SMS reply", tag: "arrivals", contentType: "text/plain", query: []string{"stopCode", "agencyId"}},
+	"GET /api/where/trips-for-location.json":                   {summary: "Active trips near a location", tag: "trips", response: TripsForLocationResponse{}, query: slices.Concat(locationQuery, []string{"includeTrip", "includeSchedule", "includeStatus", "includeCanceled", "includeAdded", "time"}), protobuf: true},
+	"GET /api/where/config.json":                               {summary: "The bundle configuration of the server", tag: "meta"},
+	"GET /api/where/usage.json":                                {summary: "Rate limit and request counts of the calling API key", tag: "developers"},
+
+	"GET /api/where/static-reload-status.json":     {summary: "Status of the pending static feed reload", tag: "admin"},
+	"GET /api/where/slo-report.json":               {summary: "Latency and availability against the configured SLOs", tag: "admin"},
+	"GET /api/where/approve-static-reload.json":    {summary: "Approve a staged static feed by hash", tag: "admin", query: []string{"hash", "idempotencyKey"}},
+	"GET /api/where/scheduled-jobs.json":           {summary: "Scheduled maintenance jobs and their last runs", tag: "admin"},
+	"GET /api/where/run-scheduled-job.json":        {summary: "Run a scheduled job now", tag: "admin", query: []string{"name", "idempotencyKey"}},
+	"GET /api/where/reload-config.json":            {summary: "Reload the configuration file", tag: "admin", query: []string{"idempotencyKey"}},
+	"GET /api/where/api-key-usage.json":            {summary: "Requests per API key and endpoint", tag: "admin", query: []string{"days", "fingerprint"}},
+	"GET /api/where/admin-audit-log.json":          {summary: "Audit log of admin mutations", tag: "admin", query: []string{"since"}},
+	"GET /api/where/api-keys.json":                 {summary: "API keys issued by an admin", tag: "admin"},
+	"GET /api/where/create-api-key.json":           {summary: "Issue an API key", tag: "admin", query: []string{"email", "description", "rateLimit", "idempotencyKey"}},
+	"GET /api/where/revoke-api-key.json":           {summary: "Revoke an issued API key", tag: "admin", query: []string{"id", "idempotencyKey"}},
+	"GET /api/where/developer-signup.json":         {summary: "Sign up for an API key; the key is sent by email", tag: "developers", query: []string{"email", "name"}, public: true},
+	"GET /api/where/developer-verify.json":         {summary: "Verify a signup and receive its API key", tag: "developers", query: []string{"token"}, public: true},
+	"GET /api/where/developer-quota-request.json":  {summary: "Request a higher rate limit for an API key", tag: "developers", query: []string{"reason"}},
+	"GET /api/where/developer-quota-requests.json": {summary: "Pending quota requests", tag: "admin"},
+	"GET /api/where/approve-developer-quota.json":  {summary: "Approve a quota request", tag: "admin", query: []string{"id", "idempotencyKey"}},
+	"GET /api/where/deny-developer-quota.json":     {summary: "Deny a quota request", tag: "admin", query: []string{"id", "idempotencyKey"}},
+
+	"GET /tiles/{z}/{x}/{y}": {summary: "Mapbox vector tile of route shapes and stops; y ends in .mvt", tag: "maps", contentType: "application/vnd.mapbox-vector-tile"},
+
+	"GET /siri/stop-monitoring":    {summary: "Upcoming visits to a stop as SIRI stop monitoring; format=json for SIRI JSON", tag: "siri", contentType: "text/xml", query: []string{"MonitoringRef", "LineRef", "MaximumStopVisits", "minutesAfter", "time", "format"}},
+	"GET /siri/vehicle-monitoring": {summary: "Vehicles of an agency as SIRI vehicle monitoring; format=json for SIRI JSON", tag: "siri", contentType: "text/xml", query: []string{"OperatorRef", "LineRef", "VehicleRef", "format"}},
+
+	"GET /gtfs-rt/trip-updates.pb":      {summary: "Merged trip updates of all realtime feeds as GTFS-RT", tag: "gtfs-rt", contentType: "application/x-protobuf"},
+	"GET /gtfs-rt/vehicle-positions.pb": {summary: "Merged vehicle positions of all realtime feeds as GTFS-RT", tag: "gtfs-rt", contentType: "application/x-protobuf"},
+
+	"GET /api/where/stream": {summary: "Server-sent events with the arrivals, trips and vehicles of stops, routes and vehicles after every realtime update", tag: "arrivals", contentType: "text/event-stream", query: []string{"stopIds", "routeIds", "vehicleIds"}},
+
+	"GET /api/where/agency/{id}":                       {summary: "An agency", tag: "agencies", response: AgencyEntryResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/routes-for-agency/{id}":            {summary: "Routes of an agency", tag: "routes", response: RoutesResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/stop-ids-for-agency/{id}":          {summary: "Stop IDs of an agency", tag: "stops", response: StopIDsForAgencyResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/stops-for-agency/{id}":             {summary: "Stops of an agency", tag: "stops", response: StopsResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/route-ids-for-agency/{id}":         {summary: "Route IDs of an agency", tag: "routes", response: RouteIDsForAgencyResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/vehicles-for-agency/{id}":          {summary: "Vehicles of an agency reporting in real time", tag: "vehicles", response: VehiclesForAgencyResponse{}, query: []string{"ageInSeconds", "time"}},
+	"GET /api/where/block-assignments-for-agency/{id}": {summary: "Vehicles assigned to the blocks of an agency", tag: "vehicles", response: BlockAssignmentsForAgencyResponse{}, query: []string{"time"}},
+
+	"GET /api/where/service-exceptions-for-agency/{id}": {summary: "Dates on which calendar exceptions add or remove an agency's services", tag: "agencies", response: ServiceExceptionsForAgencyResponse{}, query: []string{"startDate", "endDate", "datasetVersion"}},
+
+	"GET /api/where/trip/{id}":               {summary: "A trip", tag: "trips", response: TripEntryResponse{}},
+	"GET /api/where/route/{id}":              {summary: "A route", tag: "routes", response: RouteEntryResponse{}},
+	"GET /api/where/stop/{id}":               {summary: "A stop", tag: "stops", response: StopEntryResponse{}},
+	"GET /api/where/amenities-for-stop/{id}": {summary: "Amenities at a stop", tag: "stops", response: AmenitiesForStopEntryResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/shape/{id}":              {summary: "A shape as an encoded polyline", tag: "routes", response: ShapeEntryResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/stops-for-route/{id}":    {summary: "Stops and stop groupings of a route", tag: "routes", response: StopsForRouteResponse{}, query: []string{"includePolylines", "time", "datasetVersion"}},
+	"GET /api/where/fares-for-route/{id}":    {summary: "Fares of a route", tag: "routes", response: FaresForRouteResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/fares-for-leg/{id}":      {summary: "Fares of a ride on a route between two stops", tag: "routes", response: FaresForLegResponse{}, query: []string{"fromStopId", "toStopId", "riderCategoryId", "datasetVersion"}},
+	"GET /api/where/transfers-for-stop/{id}": {summary: "Transfers from a stop", tag: "stops", response: TransfersForStopResponse{}, query: []string{"datasetVersion"}},
+	"GET /api/where/schedule-for-stop/{id}":  {summary: "The schedule of a stop for a day", tag: "stops", query: []string{"date"}},
+	"GET /api/where/schedule-for-route/{id}": {summary: "The schedule of a route for a day", tag: "routes", response: ScheduleForRouteResponse{}, query: []string{"date"}},
+	"GET /api/where/block/{id}":              {summary: "A block and its trips", tag: "trips", response: BlockEntryResponse{}},
+
+	"GET /api/where/report-problem-with-trip/{id}":            {summary: "Report a problem with a trip", tag: "problems", response: EmptyResponse{}, query: []string{"serviceDate", "vehicleId", "stopId", "code", "userComment", "userOnVehicle", "userVehicleNumber", "userLat", "userLon", "userLocationAccuracy"}},
+	"GET /api/where/report-problem-with-stop/{id}":            {summary: "Report a problem with a stop", tag: "problems", response: EmptyResponse{}, query: []string{"code", "userComment", "userLat", "userLon", "userLocationAccuracy"}},
+	"GET /api/where/problem-reports-for-trip/{id}":            {summary: "Problem reports for a trip", tag: "admin", response: ProblemReportsForTripResponse{}},
+	"GET /api/where/problem-reports-for-stop/{id}":            {summary: "Problem reports for a stop", tag: "admin", response: ProblemReportsForStopResponse{}},
+	"GET /api/where/rate-limit-status/{id}":                   {summary: "Rate limit state of an API key", tag: "admin", query: []string{"ip"}},
+	"GET /api/where/trip-details/{id}":                        {summary: "A trip with its schedule and real-time status", tag: "trips", response: TripDetailsResponse{}, query: []string{"serviceDate", "vehicleId", "includeTrip", "includeSchedule", "includeStatus", "time"}},
+	"GET /api/where/schedule-deviation-history-for-trip/{id}": {summary: "Recently sampled schedule deviations of a trip", tag: "trips"},
+	"GET /api/where/trip-for-vehicle/{id}":                    {summary: "The trip a vehicle is serving", tag: "trips", response: TripDetailsResponse{}, query: []string{"includeTrip", "includeSchedule", "includeStatus", "time"}},
+	"GET /api/where/arrival-and-departure-for-stop/{id}":      {summary: "One arrival and departure of a trip at a stop", tag: "arrivals", response: ArrivalAndDepartureResponse{}, query: []string{"tripId", "serviceDate", "vehicleId", "stopSequence", "time"}},
+	"GET /api/where/trips-for-route/{id}":                     {summary: "Active trips of a route", tag: "trips", response: TripsForRouteResponse{}, query: []string{"includeSchedule", "includeStatus", "includeCanceled", "includeAdded", "time"}},
+	"GET /api/where/arrivals-and-departures-for-stop/{id}":    {summary: "Arrivals and departures at a stop", tag: "arrivals", response: ArrivalsAndDeparturesResponse{}, query: []string{"minutesBefore", "minutesAfter", "time", "format"}, protobuf: true},
+}
+
+// openAPIQueryTypes gives the schema type of query parameters that are not
+// strings.
+var openAPIQueryTypes = map[string]func() *openapi3.Schema{
+	"lat":                  openapi3.NewFloat64Schema,
+	"lon":                  openapi3.NewFloat64Schema,
+	"radius":               openapi3.NewFloat64Schema,
+	"latSpan":              openapi3.NewFloat64Schema,
+	"lonSpan":              openapi3.NewFloat64Schema,
+	"userLat":              openapi3.NewFloat64Schema,
+	"userLon":              openapi3.NewFloat64Schema,
+	"userLocationAccuracy": openapi3.NewFloat64Schema,
+	"maxCount":             openapi3.NewInt64Schema,
+	"MaximumStopVisits":    openapi3.NewInt64Schema,
+	"minutesBefore":        openapi3.NewInt64Schema,
+	"minutesAfter":         openapi3.NewInt64Schema,
+	"time":                 openapi3.NewInt64Schema,
+	"serviceDate":          openapi3.NewInt64Schema,
+	"stopSequence":         openapi3.NewInt64Schema,
+	"ageInSeconds":         openapi3.NewInt64Schema,
+	"zoom":                 openapi3.NewInt64Schema,
+	"days":                 openapi3.NewInt64Schema,
+	"rateLimit":            openapi3.NewInt64Schema,
+	"id":                   openapi3.NewInt64Schema,
+	"since":                openapi3.NewInt64Schema,
+	"includeAdded":         openapi3.NewBoolSchema,
+	"includeCanceled":      openapi3.NewBoolSchema,
+	"includeInactive":      openapi3.NewBoolSchema,
+	"includePolylines":     openapi3.NewBoolSchema,
+	"includeSchedule":      openapi3.NewBoolSchema,
+	"includeStatus":        openapi3.NewBoolSchema,
+	"includeTrip":          openapi3.NewBoolSchema,
+	"userOnVehicle":        openapi3.NewBoolSchema,
+	"verbose":              openapi3.NewBoolSchema,
+}
+
+// routeRecorder is a Router that only records the patterns registered.
+type routeRecorder struct {
+	patterns []string
+}
+
+func (rec *routeRecorder) Handle(pattern string, _ http.Handler) {
+	rec.patterns = append(rec.patterns, pattern)
+}
+
+func (rec *routeRecorder) HandleFunc(pattern string, _ func(http.ResponseWriter, *http.Request)) {
+	rec.patterns = append(rec.patterns, pattern)
+}
+
+// routePatterns returns the patterns SetRoutes registers with this
+// configuration.
+func (api *RestAPI) routePatterns() []string {
+	var rec routeRecorder
+	api.SetRoutes(&rec)
+	return rec.patterns
+}
+
+// pathWildcard matches the {name} wildcards of a route pattern.
+var pathWildcard = regexp.MustCompile(`\{(\w+)\}`)
+
+// OpenAPISpec builds the OpenAPI 3 description of the routes SetRoutes
+// registers, with the response schemas derived from the Go response types.
+func (api *RestAPI) OpenAPISpec() *openapi3.T {
+	schemas := newOpenAPISchemas()
+	envelope := schemas.ref(reflect.TypeFor[models.ResponseModel]())
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "OneBusAway",
+			Description: "The OneBusAway REST API as served by maglev. IDs combine the agency ID and the GTFS ID as agency_id. Times are Unix milliseconds.",
+			Version:     buildinfo.Current().Version,
+		},
+		Paths: openapi3.NewPaths(),
+		Security: *openapi3.NewSecurityRequirements().
+			With(openapi3.NewSecurityRequirement().Authenticate("ApiKeyAuth")),
+	}
+
+	for _, pattern := range api.routePatterns() {
+		method, path, _ := strings.Cut(pattern, " ")
+		docs := openAPIOperations[pattern]
+
+		// The last wildcard of an /api/where route carries the .json extension.
+		if strings.HasPrefix(path, "/api/where/") && strings.HasSuffix(path, "}") {
+			path += ".json"
+		}
+
+		op := openapi3.NewOperation()
+		op.Summary = docs.summary
+		op.OperationID = operationID(path)
+		if docs.tag != "" {
+			op.Tags = []string{docs.tag}
+		}
+		if docs.public {
+			op.Security = openapi3.NewSecurityRequirements()
+		}
+		for _, match := range pathWildcard.FindAllStringSubmatch(path, -1) {
+			op.AddParameter(openapi3.NewPathParameter(match[1]).WithSchema(openapi3.NewStringSchema()))
+		}
+		for _, name := range docs.query {
+			schema := openapi3.NewStringSchema()
+			if newSchema, ok := openAPIQueryTypes[name]; ok {
+				schema = newSchema()
+			}
+			op.AddParameter(openapi3.NewQueryParameter(name).WithSchema(schema))
+		}
+
+		content := openapi3.NewContentWithJSONSchemaRef(envelope)
+		switch {
+		case docs.contentType != "":
+			content = openapi3.NewContentWithSchema(nil, []string{docs.contentType})
+		case docs.response != nil:
+			content = openapi3.NewContentWithJSONSchemaRef(schemas.ref(reflect.TypeOf(docs.response)))
+		}
+		if docs.protobuf {
+			content[pbformat.ContentType] = openapi3.NewMediaType().WithSchema(openapi3.NewBytesSchema())
+		}
+		op.AddResponse(http.StatusOK, openapi3.NewResponse().WithDescription("OK").WithContent(content))
+		op.Responses.Set("default", &openapi3.ResponseRef{Value: openapi3.NewResponse().
+			WithDescription("Error, with the status in the envelope's code and the reason in its text").
+			WithJSONSchemaRef(envelope)})
+
+		doc.AddOperation(path, method, op)
+	}
+
+	doc.Components = &openapi3.Components{
+		Schemas: schemas.components,
+		SecuritySchemes: openapi3.SecuritySchemes{
+			"ApiKeyAuth": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().WithType("apiKey").WithIn("query").WithName("key")},
+		},
+	}
+	return doc
+}
+
+// operationID names an operation after its path, as in
+// arrivalsAndDeparturesForStop for
+// /api/where/arrivals-and-departures-for-stop/{id}.json.
+func operationID(path string) string {
+	path = strings.TrimPrefix(path, "/api/where/")
+	path = strings.TrimSuffix(pathWildcard.ReplaceAllString(path, ""), ".json")
+	var sb strings.Builder
+	for i, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if i > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		sb.WriteString(word)
+	}
+	return sb.String()
+}
+
+// openAPISchemas derives schemas from Go types as encoding/json marshals
+// them. Named structs become components referenced by name.
+type openAPISchemas struct {
+	components openapi3.Schemas
+	names      map[reflect.Type]string
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{components: make(openapi3.Schemas), names: make(map[reflect.Type]string)}
+}
+
+var (
+	modelTimeType     = reflect.TypeFor[models.ModelTime]()
+	modelDurationType = reflect.TypeFor[models.ModelDuration]()
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+)
+
+func (s *openAPISchemas) ref(t reflect.Type) *openapi3.SchemaRef {
+	switch t {
+	case modelTimeType:
+		schema := openapi3.NewInt64Schema()
+		schema.Description = "Unix milliseconds"
+		return openapi3.NewSchemaRef("", schema)
+	case modelDurationType:
+		schema := openapi3.NewInt64Schema()
+		schema.Description = "Seconds"
+		return openapi3.NewSchemaRef("", schema)
+	case timeType:
+		return openapi3.NewSchemaRef("", openapi3.NewDateTimeSchema())
+	case rawMessageType:
+		return openapi3.NewSchemaRef("", openapi3.NewSchema())
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		ref := s.ref(t.Elem())
+		if ref.Ref != "" {
+			return ref
+		}
+		nullable := *ref.Value
+		nullable.Nullable = true
+		return openapi3.NewSchemaRef("", &nullable)
+	case reflect.Bool:
+		return openapi3.NewSchemaRef("", openapi3.NewBoolSchema())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return openapi3.NewSchemaRef("", openapi3.NewInt32Schema())
+	case reflect.Int64, reflect.Uint64:
+		return openapi3.NewSchemaRef("", openapi3.NewInt64Schema())
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewSchemaRef("", openapi3.NewFloat64Schema())
+	case reflect.String:
+		return openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return openapi3.NewSchemaRef("", openapi3.NewBytesSchema())
+		}
+		array := openapi3.NewArraySchema()
+		array.Items = s.ref(t.Elem())
+		return openapi3.NewSchemaRef("", array)
+	case reflect.Map:
+		object := openapi3.NewObjectSchema()
+		object.AdditionalProperties = openapi3.AdditionalProperties{Schema: s.ref(t.Elem())}
+		return openapi3.NewSchemaRef("", object)
+	case reflect.Struct:
+		if t.Name() == "" {
+			return openapi3.NewSchemaRef("", s.object(t))
+		}
+		name := s.componentName(t)
+		component, ok := s.components[name]
+		if !ok {
+			// Register the component before describing its fields so that
+			// recursive types terminate.
+			component = openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+			s.components[name] = component
+			*component.Value = *s.object(t)
+		}
+		return openapi3.NewSchemaRef("#/components/schemas/"+name, component.Value)
+	default: // interfaces
+		return openapi3.NewSchemaRef("", openapi3.NewSchema())
+	}
+}
+
+// object describes a struct's JSON members, including promoted ones.
+func (s *openAPISchemas) object(t reflect.Type) *openapi3.Schema {
+	object := openapi3.NewObjectSchema()
+	for name, index := range jsonFieldIndexesOf(t) {
+		object.WithPropertyRef(name, s.ref(t.FieldByIndex(index).Type))
+	}
+	return object
+}
+
+// jsonFieldIndexesOf maps the JSON names of a struct's fields to their field
+// indexes, the shallowest field of a name winning as in encoding/json.
+func jsonFieldIndexesOf(t reflect.Type) map[string][]int {
+	indexes := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(t) {
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" || (field.Anonymous && tag == "") {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		if taken, ok := indexes[name]; !ok || len(field.Index) < len(taken) {
+			indexes[name] = field.Index
+		}
+	}
+	return indexes
+}
+
+// genericTypeArgs matches the package paths in the name of an instantiated
+// generic type, as in "ListData[maglev.onebusaway.org/internal/models.Stop]".
+var genericTypeArgs = regexp.MustCompile(`[\w./-]*\.`)
+
+// componentName names a struct's component schema: its type name, with the
+// type arguments of a generic type appended, as in ListDataStop. Types of the
+// same name from different packages are told apart by a package prefix.
+func (s *openAPISchemas) componentName(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	base, args, _ := strings.Cut(t.Name(), "[")
+	var sb strings.Builder
+	sb.WriteString(base)
+	for _, arg := range strings.FieldsFunc(genericTypeArgs.ReplaceAllString(args, ""), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		sb.WriteString(strings.ToUpper(arg[:1]) + arg[1:])
+	}
+	name := sb.String()
+	for _, taken := range s.names {
+		if taken == name {
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+			break
+		}
+	}
+	s.names[t] = name
+	return name
+}
+
+func (api *RestAPI) openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	api.openAPIOnce.Do(func() {
+		api.openAPIJSON, api.openAPIErr = json.Marshal(api.OpenAPISpec())
+	})
+	if api.openAPIErr != nil {
+		api.serverErrorResponse(w, r, api.openAPIErr)
+		return
+	}
+	setJSONResponseType(&w)
+	_, _ = w.Write(api.openAPIJSON)
+}
+
+// swaggerUIVersion pins the swagger-ui-dist release the docs page loads.
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIScript starts Swagger UI on the spec. The page's Content Security
+// Policy allows it by its hash rather than allowing inline scripts.
+const swaggerUIScript = `window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });`
+
+var swaggerUIPage = `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>OneBusAway API</title>
+<link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+<script>` + swaggerUIScript + `</script>
+</body>
+</html>
+`
+
+var swaggerUIScriptHash = func() string {
+	sum := sha256.Sum256([]byte(swaggerUIScript))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}()
+
+// openAPIDocsHandler serves Swagger UI for /api/openapi.json. Swagger UI is
+// loaded from the jsDelivr CDN, which the page's Content Security Policy
+// allows in place of the API's default-src 'none'.
+func (api *RestAPI) openAPIDocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; "+
+		"script-src https://cdn.jsdelivr.net "+swaggerUIScriptHash+"; "+
+		"style-src https://cdn.jsdelivr.net 'unsafe-inline'; "+
+		"img-src 'self' data:; connect-src 'self'; frame-ancestors 'none';")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(swaggerUIPage))
+}
@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestOpenAPIOperationsCoverRoutes(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	for _, pattern := range api.routePatterns() {
+		_, ok := openAPIOperations[pattern]
+		assert.True(t, ok, "route %s has no entry in openAPIOperations", pattern)
+	}
+}
+
+func TestOpenAPISpecIsValid(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	doc := api.OpenAPISpec()
+	require.NoError(t, doc.Validate(context.Background()))
+
+	// Every registered route is described, with its extension and wildcards.
+	assert.Equal(t, len(api.routePatterns()), doc.Paths.Len())
+	op := doc.Paths.Find("/api/where/arrivals-and-departures-for-stop/{id}.json").Get
+	require.NotNil(t, op)
+	assert.Equal(t, "arrivalsAndDeparturesForStop", op.OperationID)
+	assert.Equal(t, "id", op.Parameters[0].Value.Name)
+	assert.Equal(t, "path", op.Parameters[0].Value.In)
+	assert.NotNil(t, op.Responses.Status(http.StatusOK).Value.Content.Get("application/x-protobuf"))
+
+	assert.NotNil(t, doc.Paths.Find("/tiles/{z}/{x}/{y}"), "only /api/where wildcards carry .json")
+	assert.NotNil(t, doc.Paths.Find("/api/openapi.json").Get.Security, "public routes override the API key requirement")
+	assert.Empty(t, *doc.Paths.Find("/api/openapi.json").Get.Security)
+
+	// Response schemas follow the Go types.
+	entry := doc.Components.Schemas["ArrivalsAndDeparturesEntry"]
+	require.NotNil(t, entry)
+	arrival := doc.Components.Schemas["ArrivalAndDeparture"].Value
+	require.NotNil(t, arrival)
+	assert.True(t, arrival.Properties["scheduledArrivalTime"].Value.Type.Is(openapi3.TypeInteger), "ModelTime is documented as milliseconds")
+	assert.NotEmpty(t, arrival.Properties["tripStatus"].Ref, "named structs are components")
+	assert.Contains(t, doc.Components.Schemas, "EntryDataArrivalsAndDeparturesEntry")
+	assert.Contains(t, doc.Components.Schemas, "ListDataString")
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/openapi.json")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "the spec needs no API key")
+
+	doc, err := openapi3.NewLoader().LoadFromIoReader(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	assert.NotNil(t, doc.Paths.Find("/api/where/agency/{id}.json"))
+
+	resp, err = http.Get(server.URL + "/api/docs")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(page), swaggerUIScript)
+	assert.Contains(t, resp.Header.Get("Content-Security-Policy"), swaggerUIScriptHash)
+
+	// The spec's agency response describes the real response.
+	agencyResp, err := http.Get(server.URL + "/api/where/agency/" + testdata.Raba.ID + ".json?key=TEST")
+	require.NoError(t, err)
+	defer func() { _ = agencyResp.Body.Close() }()
+	var decoded any
+	require.NoError(t, json.NewDecoder(agencyResp.Body).Decode(&decoded))
+	schema := doc.Paths.Find("/api/where/agency/{id}.json").Get.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema.Value
+	assert.NoError(t, schema.VisitJSON(decoded))
+}
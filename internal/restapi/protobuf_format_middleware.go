@@ -0,0 +1,113 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/pbformat"
+)
+
+type protobufRequestKey struct{}
+
+type protobufMessageKey struct{}
+
+// protobufRequest marks a request that asked for a protobuf response.
+// Explicit requests named the .pb extension and fail with 406 Not Acceptable
+// on endpoints without a protobuf form; requests that only sent an Accept
+// header fall back to JSON.
+type protobufRequest struct {
+	explicit bool
+}
+
+// ProtobufFormatMiddleware recognizes /api/where requests for protobuf
+// responses: paths with a .pb extension, which are handled as their .json
+// counterpart, and .json paths whose Accept header names
+// application/x-protobuf or application/protobuf. Routes wrapped with
+// protobufFormat then encode their response with the schema in
+// internal/pbformat. It must run outside XMLFormatMiddleware so .xml requests
+// are never negotiated to protobuf.
+func ProtobufFormatMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/where/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var req protobufRequest
+		if base, ok := strings.CutSuffix(r.URL.Path, ".pb"); ok {
+			req.explicit = true
+			r = r.Clone(r.Context())
+			r.URL.Path = base + ".json"
+			if rawBase, ok := strings.CutSuffix(r.URL.RawPath, ".pb"); ok {
+				r.URL.RawPath = rawBase + ".json"
+			}
+		} else if !strings.HasSuffix(r.URL.Path, ".json") || !acceptsProtobuf(r.Header.Values("Accept")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), protobufRequestKey{}, req)))
+	})
+}
+
+// acceptsProtobuf reports whether an Accept header names a protobuf media
+// type with a non-zero quality.
+func acceptsProtobuf(accept []string) bool {
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || (mediaType != pbformat.ContentType && mediaType != "application/protobuf") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// protobufFormat gives a route a protobuf form, encoded as the pbformat
+// message called messageName. Its responses vary on Accept, since the same
+// URL may then be served as JSON or protobuf.
+func protobufFormat(messageName string, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	md := pbformat.Message(messageName)
+	if md == nil {
+		panic(fmt.Sprintf("restapi: no protobuf message %s", messageName))
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		handler(w, r.WithContext(context.WithValue(r.Context(), protobufMessageKey{}, md)))
+	}
+}
+
+// requestedProtobuf returns how a request asked for a protobuf response, if
+// it did.
+func requestedProtobuf(r *http.Request) (protobufRequest, bool) {
+	req, ok := r.Context().Value(protobufRequestKey{}).(protobufRequest)
+	return req, ok
+}
+
+// protobufMessage returns the message a route's responses encode to as
+// protobuf, or nil if the route has no protobuf form.
+func protobufMessage(r *http.Request) protoreflect.MessageDescriptor {
+	md, _ := r.Context().Value(protobufMessageKey{}).(protoreflect.MessageDescriptor)
+	return md
+}
+
+// sendProtobuf writes response as the protobuf message md.
+func (api *RestAPI) sendProtobuf(w http.ResponseWriter, r *http.Request, md protoreflect.MessageDescriptor, response models.ResponseModel) {
+	body, err := pbformat.Marshal(md, response)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", pbformat.ContentType)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,145 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/pbformat"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestAcceptsProtobuf(t *testing.T) {
+	tests := map[string]bool{
+		"application/x-protobuf":                  true,
+		"application/json, application/protobuf":  true,
+		"application/x-protobuf;q=0.5, */*;q=0.1": true,
+		"application/x-protobuf;q=0":              false,
+		"application/json":                        false,
+		"*/*":                                     false,
+		"":                                        false,
+	}
+	for accept, want := range tests {
+		assert.Equal(t, want, acceptsProtobuf([]string{accept}), accept)
+	}
+}
+
+func TestCoalescingKeySeparatesProtobuf(t *testing.T) {
+	var keys []string
+	handler := ProtobufFormatMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, coalescingKey(r))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.json?key=TEST", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.pb?key=TEST", nil))
+	accept := httptest.NewRequest(http.MethodGet, "/api/where/arrivals-and-departures-for-stop/1_1.json?key=TEST", nil)
+	accept.Header.Set("Accept", pbformat.ContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), accept)
+
+	require.Len(t, keys, 3)
+	assert.NotEqual(t, keys[0], keys[1])
+	assert.NotEqual(t, keys[0], keys[2])
+	assert.NotEqual(t, keys[1], keys[2], "only .pb requests fail without a protobuf form")
+}
+
+func TestProtobufEndpointsThroughMux(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	fetch := func(t *testing.T, endpoint, accept string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+endpoint, nil)
+		require.NoError(t, err)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+	decode := func(t *testing.T, messageName string, body []byte) protoreflect.Message {
+		msg := dynamicpb.NewMessage(pbformat.Message(messageName))
+		require.NoError(t, proto.Unmarshal(body, msg))
+		return msg
+	}
+	field := func(msg protoreflect.Message, name string) protoreflect.Value {
+		return msg.Get(msg.Descriptor().Fields().ByName(protoreflect.Name(name)))
+	}
+
+	arrivalsPath := "/api/where/arrivals-and-departures-for-stop/" + arrivalsTestStopID
+	var jsonModel ArrivalsAndDeparturesResponse
+	resp, body := fetch(t, arrivalsPath+".json?key=org.onebusaway.iphone", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.Unmarshal(body, &jsonModel))
+	require.NotEmpty(t, jsonModel.Data.Entry.ArrivalsAndDepartures)
+	assert.Contains(t, resp.Header.Values("Vary"), "Accept")
+
+	t.Run("extension", func(t *testing.T) {
+		resp, body := fetch(t, arrivalsPath+".pb?key=org.onebusaway.iphone", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, pbformat.ContentType, resp.Header.Get("Content-Type"))
+
+		msg := decode(t, "ArrivalsAndDeparturesForStopResponse", body)
+		assert.Equal(t, int64(http.StatusOK), field(msg, "code").Int())
+		entry := field(field(msg, "data").Message(), "entry").Message()
+		assert.Equal(t, arrivalsTestStopID, field(entry, "stop_id").String())
+
+		arrivals := field(entry, "arrivals_and_departures").List()
+		require.Equal(t, len(jsonModel.Data.Entry.ArrivalsAndDepartures), arrivals.Len())
+		first := arrivals.Get(0).Message()
+		assert.Equal(t, jsonModel.Data.Entry.ArrivalsAndDepartures[0].TripID, field(first, "trip_id").String())
+		assert.Equal(t, jsonModel.Data.Entry.ArrivalsAndDepartures[0].ScheduledArrivalTime.UnixMilli(), field(first, "scheduled_arrival_time").Int())
+
+		references := field(field(msg, "data").Message(), "references").Message()
+		assert.Equal(t, len(jsonModel.Data.References.Stops), field(references, "stops").List().Len())
+	})
+
+	t.Run("accept header", func(t *testing.T) {
+		resp, body := fetch(t, arrivalsPath+".json?key=org.onebusaway.iphone", "application/x-protobuf")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, pbformat.ContentType, resp.Header.Get("Content-Type"))
+		decode(t, "ArrivalsAndDeparturesForStopResponse", body)
+	})
+
+	t.Run("trips for location", func(t *testing.T) {
+		path := strings.Replace(tripsForLocationURL(1.0, 1.0, "includeSchedule=true"), ".json?key=TEST", ".pb?key=org.onebusaway.iphone", 1)
+		resp, body := fetch(t, path, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		assert.Equal(t, pbformat.ContentType, resp.Header.Get("Content-Type"))
+		msg := decode(t, "TripsForLocationResponse", body)
+		assert.Equal(t, int64(http.StatusOK), field(msg, "code").Int())
+	})
+
+	t.Run("accept header without a protobuf form", func(t *testing.T) {
+		resp, body := fetch(t, "/api/where/agency/"+testdata.Raba.ID+".json?key=org.onebusaway.iphone", "application/x-protobuf")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json"))
+		assert.True(t, json.Valid(body))
+	})
+
+	t.Run("extension without a protobuf form", func(t *testing.T) {
+		resp, body := fetch(t, "/api/where/agency/"+testdata.Raba.ID+".pb?key=org.onebusaway.iphone", "")
+		assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+		assert.True(t, json.Valid(body), "errors stay JSON")
+	})
+
+	t.Run("errors stay JSON", func(t *testing.T) {
+		resp, body := fetch(t, arrivalsPath+".pb?key=invalid", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.True(t, json.Valid(body))
+	})
+}
@@ -0,0 +1,194 @@
+package restapi
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryTimeLayouts are the calendar forms a time parameter may take besides
+// Unix milliseconds, selected with the layout tag option.
+var queryTimeLayouts = map[string]struct{ layout, description string }{
+	"date":     {"2006-01-02", "a date in yyyy-MM-dd format"},
+	"datetime": {"2006-01-02_15-04-05", "a datetime in yyyy-MM-dd_HH-mm-ss format"},
+}
+
+// bindQuery sets the fields of the struct dst points to from the query
+// parameters named by their `query` tags, and returns the validation errors
+// by parameter name, or nil when there are none. Fields of absent or empty
+// parameters are left alone, so dst can be filled with the defaults first;
+// pointer fields are only allocated for parameters that are present.
+//
+// Fields may be strings, bools, signed integers, float64s and time.Times,
+// which are read as Unix milliseconds. Options after the name in the tag
+// refine the validation:
+//
+//	min=N, max=N     bounds of a number
+//	clamp            lower a number above max to max instead of rejecting it
+//	oneof=a|b        the values a string may take
+//	layout=date      also accept a time as yyyy-MM-dd in loc
+//	layout=datetime  also accept a time as yyyy-MM-dd_HH-mm-ss in loc
+//
+// Times are returned in loc, or UTC when loc is nil. bindQuery panics if dst
+// is not a pointer to a struct or a tagged field has another type, as either
+// is a programming error.
+func bindQuery(query url.Values, dst any, loc *time.Location) map[string][]string {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("bindQuery: %T is not a pointer to a struct", dst))
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var fieldErrors map[string][]string
+	v = v.Elem()
+	for i := range v.NumField() {
+		tag, ok := v.Type().Field(i).Tag.Lookup("query")
+		if !ok {
+			continue
+		}
+		name, options := parseQueryTag(tag)
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Pointer {
+			value := reflect.New(field.Type().Elem())
+			if msg := bindQueryValue(value.Elem(), raw, options, loc); msg != "" {
+				fieldErrors = addFieldError(fieldErrors, name, msg)
+				continue
+			}
+			field.Set(value)
+		} else if msg := bindQueryValue(field, raw, options, loc); msg != "" {
+			fieldErrors = addFieldError(fieldErrors, name, msg)
+		}
+	}
+	return fieldErrors
+}
+
+func parseQueryTag(tag string) (name string, options map[string]string) {
+	name, rest, _ := strings.Cut(tag, ",")
+	options = make(map[string]string)
+	for rest != "" {
+		var option string
+		option, rest, _ = strings.Cut(rest, ",")
+		key, value, _ := strings.Cut(option, "=")
+		options[key] = value
+	}
+	return name, options
+}
+
+func addFieldError(fieldErrors map[string][]string, name, msg string) map[string][]string {
+	if fieldErrors == nil {
+		fieldErrors = make(map[string][]string)
+	}
+	fieldErrors[name] = append(fieldErrors[name], msg)
+	return fieldErrors
+}
+
+// bindQueryValue parses raw into field, returning the validation message
+// when it is not acceptable.
+func bindQueryValue(field reflect.Value, raw string, options map[string]string, loc *time.Location) string {
+	if field.Type() == timeType {
+		return bindQueryTime(field, raw, options["layout"], loc)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if oneOf, ok := options["oneof"]; ok {
+			allowed := strings.Split(oneOf, "|")
+			if !slices.Contains(allowed, raw) {
+				return "must be " + describeChoices(allowed)
+			}
+		}
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "must be a boolean value (true/false)"
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return "must be a valid integer"
+		}
+		f, msg := checkQueryBounds(float64(n), options, "integer")
+		if msg != "" {
+			return msg
+		}
+		field.SetInt(int64(f))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "must be a valid number"
+		}
+		f, msg := checkQueryBounds(n, options, "number")
+		if msg != "" {
+			return msg
+		}
+		field.SetFloat(f)
+	default:
+		panic(fmt.Sprintf("bindQuery: unsupported field type %s", field.Type()))
+	}
+	return ""
+}
+
+// checkQueryBounds applies the min, max and clamp options to n.
+func checkQueryBounds(n float64, options map[string]string, kind string) (float64, string) {
+	if bound, ok := options["min"]; ok {
+		minimum, _ := strconv.ParseFloat(bound, 64)
+		if n < minimum {
+			if minimum == 0 {
+				return 0, "must be a non-negative " + kind
+			}
+			return 0, "must be at least " + bound
+		}
+	}
+	if bound, ok := options["max"]; ok {
+		maximum, _ := strconv.ParseFloat(bound, 64)
+		if n > maximum {
+			if _, clamp := options["clamp"]; clamp {
+				return maximum, ""
+			}
+			return 0, "must not exceed " + bound
+		}
+	}
+	return n, ""
+}
+
+func bindQueryTime(field reflect.Value, raw, layoutName string, loc *time.Location) string {
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		field.Set(reflect.ValueOf(time.UnixMilli(ms).In(loc)))
+		return ""
+	}
+	msg := "must be a valid Unix timestamp in milliseconds"
+	if layoutName == "" {
+		return msg
+	}
+	layout, ok := queryTimeLayouts[layoutName]
+	if !ok {
+		panic(fmt.Sprintf("bindQuery: unknown time layout %q", layoutName))
+	}
+	t, err := time.ParseInLocation(layout.layout, raw, loc)
+	if err != nil {
+		return msg + " or " + layout.description
+	}
+	field.Set(reflect.ValueOf(t))
+	return ""
+}
+
+// describeChoices lists allowed values as "a or b" or "one of a, b, c".
+func describeChoices(values []string) string {
+	if len(values) <= 2 {
+		return strings.Join(values, " or ")
+	}
+	return "one of " + strings.Join(values, ", ")
+}
@@ -0,0 +1,104 @@
+package restapi
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type queryBindingTestParams struct {
+	Limit   int        `query:"limit,min=0,max=100"`
+	Minutes int        `query:"minutes,min=0,max=60,clamp"`
+	Radius  float64    `query:"radius,min=1"`
+	Verbose bool       `query:"verbose"`
+	Format  string     `query:"format,oneof=json|xml|csv"`
+	Time    *time.Time `query:"time"`
+	Date    *time.Time `query:"date,layout=date"`
+	Offset  *int       `query:"offset"`
+	Ignored string
+}
+
+func TestBindQuery_Values(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	query := url.Values{
+		"limit":   {"20"},
+		"minutes": {"90"},
+		"radius":  {"2.5"},
+		"verbose": {"true"},
+		"format":  {"xml"},
+		"time":    {"1718459400123"},
+		"date":    {"2024-06-15"},
+		"offset":  {"0"},
+		"Ignored": {"x"},
+	}
+
+	var params queryBindingTestParams
+	require.Nil(t, bindQuery(query, &params, loc))
+
+	assert.Equal(t, 20, params.Limit)
+	assert.Equal(t, 60, params.Minutes, "clamped to max")
+	assert.Equal(t, 2.5, params.Radius)
+	assert.True(t, params.Verbose)
+	assert.Equal(t, "xml", params.Format)
+	require.NotNil(t, params.Time)
+	assert.True(t, params.Time.Equal(time.UnixMilli(1718459400123)))
+	assert.Equal(t, loc, params.Time.Location())
+	require.NotNil(t, params.Date)
+	assert.Equal(t, time.Date(2024, 6, 15, 0, 0, 0, 0, loc), *params.Date)
+	require.NotNil(t, params.Offset)
+	assert.Equal(t, 0, *params.Offset)
+	assert.Empty(t, params.Ignored)
+}
+
+func TestBindQuery_KeepsDefaults(t *testing.T) {
+	params := queryBindingTestParams{Limit: 10, Format: "json"}
+	require.Nil(t, bindQuery(url.Values{"limit": {""}}, &params, nil))
+
+	assert.Equal(t, 10, params.Limit)
+	assert.Equal(t, "json", params.Format)
+	assert.Nil(t, params.Time, "pointers stay nil when the parameter is absent")
+	assert.Nil(t, params.Offset)
+}
+
+func TestBindQuery_Errors(t *testing.T) {
+	query := url.Values{
+		"limit":   {"101"},
+		"minutes": {"-1"},
+		"radius":  {"0.5"},
+		"verbose": {"maybe"},
+		"format":  {"yaml"},
+		"time":    {"2024-06-15"},
+		"date":    {"06/15/2024"},
+		"offset":  {"one"},
+	}
+
+	params := queryBindingTestParams{Limit: 10}
+	fieldErrors := bindQuery(query, &params, nil)
+
+	assert.Equal(t, map[string][]string{
+		"limit":   {"must not exceed 100"},
+		"minutes": {"must be a non-negative integer"},
+		"radius":  {"must be at least 1"},
+		"verbose": {"must be a boolean value (true/false)"},
+		"format":  {"must be one of json, xml, csv"},
+		"time":    {"must be a valid Unix timestamp in milliseconds"},
+		"date":    {"must be a valid Unix timestamp in milliseconds or a date in yyyy-MM-dd format"},
+		"offset":  {"must be a valid integer"},
+	}, fieldErrors)
+	assert.Equal(t, 10, params.Limit, "invalid values leave the field alone")
+	assert.Nil(t, params.Offset)
+}
+
+func TestBindQuery_PanicsOnMisuse(t *testing.T) {
+	assert.Panics(t, func() { bindQuery(url.Values{}, queryBindingTestParams{}, nil) })
+	assert.Panics(t, func() {
+		var params struct {
+			IDs []string `query:"ids"`
+		}
+		bindQuery(url.Values{"ids": {"a"}}, &params, nil)
+	})
+}
@@ -1,32 +1,85 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"math"
+	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"maglev.onebusaway.org/internal/appconf"
 	"maglev.onebusaway.org/internal/logging"
 	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/ratelimit"
 
 	"golang.org/x/time/rate"
 )
 
-// RateLimitMiddleware provides global rate limiting with optional per-key exemptions.
+// RateLimitMiddleware provides global rate limiting with optional per-key and
+// per-IP-range exemptions. Its settings can be changed while serving through
+// Reconfigure. The token bucket lives in a ratelimit.Backend, in memory unless
+// SetBackend shares it between replicas.
 type RateLimitMiddleware struct {
-	limiter    *rate.Limiter
-	rateLimit  rate.Limit
-	burstSize  int
-	exemptKeys map[string]bool
+	mu             sync.RWMutex // Guards the fields below against Reconfigure
+	interval       time.Duration
+	ratePerSecond  int
+	backend        ratelimit.Backend
+	rateLimit      rate.Limit
+	burstSize      int
+	exemptKeys     map[string]bool
+	exemptIPRanges []netip.Prefix
+
+	// backendFailing is set while the backend is returning errors, so the
+	// failure and the recovery are each logged once.
+	backendFailing atomic.Bool
 }
 
 // NewRateLimitMiddleware creates a new rate limiting middleware.
 // ratePerSecond: number of requests allowed per second (0 blocks all, negative is unlimited)
 // burstSize: equal to ratePerSecond
 func NewRateLimitMiddleware(ratePerSecond int, interval time.Duration, exemptKeys []string) *RateLimitMiddleware {
+	rl := &RateLimitMiddleware{interval: interval, backend: ratelimit.NewMemory()}
+	rl.setRateLocked(ratePerSecond)
+	rl.setExemptKeysLocked(exemptKeys)
+	return rl
+}
+
+// SetBackend replaces where the token bucket is kept. The previous backend is
+// not closed.
+func (rl *RateLimitMiddleware) SetBackend(backend ratelimit.Backend) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.backend = backend
+}
+
+// Close releases the backend's connections.
+func (rl *RateLimitMiddleware) Close() error {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.backend.Close()
+}
+
+// Reconfigure replaces the rate and exemptions, as when the configuration is
+// reloaded. With the memory backend the bucket starts full again only when
+// the rate changes.
+func (rl *RateLimitMiddleware) Reconfigure(ratePerSecond int, exemptKeys, exemptIPRanges []string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if ratePerSecond != rl.ratePerSecond {
+		rl.setRateLocked(ratePerSecond)
+	}
+	rl.setExemptKeysLocked(exemptKeys)
+	rl.setExemptIPRangesLocked(exemptIPRanges)
+}
+
+func (rl *RateLimitMiddleware) setRateLocked(ratePerSecond int) {
 	var rateLimit rate.Limit
 	switch {
 	case ratePerSecond < 0:
@@ -34,12 +87,18 @@ func NewRateLimitMiddleware(ratePerSecond int, interval time.Duration, exemptKey
 	case ratePerSecond == 0:
 		rateLimit = 0
 	default:
-		rateLimit = rate.Every(interval / time.Duration(ratePerSecond))
+		rateLimit = rate.Every(rl.interval / time.Duration(ratePerSecond))
 	}
 
 	// Clamp burst to 0 for the unlimited case so burstSize is never negative.
 	burst := max(ratePerSecond, 0)
 
+	rl.ratePerSecond = ratePerSecond
+	rl.rateLimit = rateLimit
+	rl.burstSize = burst
+}
+
+func (rl *RateLimitMiddleware) setExemptKeysLocked(exemptKeys []string) {
 	exemptMap := make(map[string]bool)
 	for _, key := range exemptKeys {
 		trimmedKey := strings.TrimSpace(key)
@@ -47,13 +106,34 @@ func NewRateLimitMiddleware(ratePerSecond int, interval time.Duration, exemptKey
 			exemptMap[trimmedKey] = true
 		}
 	}
+	rl.exemptKeys = exemptMap
+}
+
+// SetExemptIPRanges exempts requests from the given CIDR ranges or single
+// addresses, such as an internal signage network, from rate limiting. Ranges
+// are matched against the connection's remote address; forwarding headers are
+// not trusted since any client could set them. Invalid entries are logged and
+// skipped.
+func (rl *RateLimitMiddleware) SetExemptIPRanges(ranges []string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.setExemptIPRangesLocked(ranges)
+}
 
-	return &RateLimitMiddleware{
-		limiter:    rate.NewLimiter(rateLimit, burst),
-		rateLimit:  rateLimit,
-		burstSize:  burst,
-		exemptKeys: exemptMap,
+func (rl *RateLimitMiddleware) setExemptIPRangesLocked(ranges []string) {
+	var prefixes []netip.Prefix
+	for _, ipRange := range ranges {
+		if strings.TrimSpace(ipRange) == "" {
+			continue
+		}
+		prefix, err := appconf.ParseIPRange(ipRange)
+		if err != nil {
+			slog.Warn("ignoring invalid rate limit exempt IP range", slog.String("error", err.Error()))
+			continue
+		}
+		prefixes = append(prefixes, prefix)
 	}
+	rl.exemptIPRanges = prefixes
 }
 
 // Handler returns the HTTP middleware handler function
@@ -63,46 +143,134 @@ func (rl *RateLimitMiddleware) Handler() func(http.Handler) http.Handler {
 
 func (rl *RateLimitMiddleware) rateLimitHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		apiKey := r.URL.Query().Get("key")
+		rl.mu.RLock()
+		exempt := rl.exemptReasonLocked(r.URL.Query().Get("key"), remoteAddr(r)) != ""
+		backend, rateLimit, burstSize := rl.backend, rl.rateLimit, rl.burstSize
+		rl.mu.RUnlock()
 
-		if rl.exemptKeys[apiKey] {
+		if exempt || rateLimit == rate.Inf {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		if !rl.limiter.Allow() {
-			rl.sendRateLimitExceeded(w)
+		allowed, remaining, err := backend.Allow(r.Context(), rateLimit, burstSize)
+		rl.noteBackendResult(err)
+		if err != nil {
+			// Fail open: an unreachable shared store should not take the API
+			// down with it.
+			next.ServeHTTP(w, r)
 			return
 		}
-
-		if rl.rateLimit != rate.Inf {
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rl.burstSize))
-			remaining := int(math.Floor(rl.limiter.Tokens()))
-			if remaining < 0 {
-				remaining = 0
-			}
-			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			sendRateLimitExceeded(w, rateLimit, burstSize)
+			return
 		}
 
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(burstSize))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
 		next.ServeHTTP(w, r)
 	})
 }
 
-// sendRateLimitExceeded sends a 429 Too Many Requests response
-func (rl *RateLimitMiddleware) sendRateLimitExceeded(w http.ResponseWriter) {
+// noteBackendResult logs when the backend starts failing and when it recovers.
+func (rl *RateLimitMiddleware) noteBackendResult(err error) {
+	logger := slog.Default().With(slog.String("component", "rate_limit_middleware"))
+	if err != nil {
+		if rl.backendFailing.CompareAndSwap(false, true) {
+			logging.LogError(logger, "rate limit backend failed, allowing requests until it recovers", err)
+		}
+		return
+	}
+	if rl.backendFailing.CompareAndSwap(true, false) {
+		logger.Info("rate limit backend recovered")
+	}
+}
+
+// Rate limit exemption reasons reported by exemptReason.
+const (
+	rateLimitExemptAPIKey  = "api-key"
+	rateLimitExemptIPRange = "ip-range"
+)
+
+// exemptReason reports why a request with apiKey from addr bypasses rate
+// limiting, or "" when it does not. addr may be the zero Addr.
+func (rl *RateLimitMiddleware) exemptReason(apiKey string, addr netip.Addr) string {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.exemptReasonLocked(apiKey, addr)
+}
+
+// exemptReasonLocked is exemptReason for callers holding rl.mu.
+func (rl *RateLimitMiddleware) exemptReasonLocked(apiKey string, addr netip.Addr) string {
+	if rl.exemptKeys[apiKey] {
+		return rateLimitExemptAPIKey
+	}
+	if addr.IsValid() {
+		addr = addr.Unmap()
+		for _, prefix := range rl.exemptIPRanges {
+			if prefix.Contains(addr) {
+				return rateLimitExemptIPRange
+			}
+		}
+	}
+	return ""
+}
+
+// Status reports the current bucket state as seen by a request with apiKey
+// from addr. addr may be the zero Addr to check only the key. Remaining is 0
+// when the backend cannot be reached.
+func (rl *RateLimitMiddleware) Status(ctx context.Context, apiKey string, addr netip.Addr) models.RateLimitStatus {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	status := models.RateLimitStatus{
+		APIKey:       apiKey,
+		ExemptReason: rl.exemptReasonLocked(apiKey, addr),
+		Unlimited:    rl.rateLimit == rate.Inf,
+		Limit:        rl.burstSize,
+	}
+	status.Exempt = status.ExemptReason != ""
+	if addr.IsValid() {
+		status.IP = addr.Unmap().String()
+	}
+	if !status.Unlimited {
+		status.RefillPerSecond = float64(rl.rateLimit)
+		remaining, err := rl.backend.Remaining(ctx, rl.rateLimit, rl.burstSize)
+		rl.noteBackendResult(err)
+		status.Remaining = remaining
+	}
+	return status
+}
+
+// remoteAddr returns the address of the connection the request arrived on, or
+// the zero Addr when it cannot be parsed.
+func remoteAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr
+}
+
+// sendRateLimitExceeded sends a 429 Too Many Requests response for a bucket
+// that refills at rateLimit and holds burstSize requests.
+func sendRateLimitExceeded(w http.ResponseWriter, rateLimit rate.Limit, burstSize int) {
 	var retryAfter time.Duration
-	switch rl.rateLimit {
+	switch rateLimit {
 	case 0:
 		retryAfter = time.Hour // suggest retrying much later when all requests are blocked
 	case rate.Inf:
 		retryAfter = time.Second // should not happen, but fallback
 	default:
-		retryAfter = time.Duration(float64(time.Second) / float64(rl.rateLimit))
+		retryAfter = time.Duration(float64(time.Second) / float64(rateLimit))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
-	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rl.burstSize))
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(burstSize))
 	w.Header().Set("X-RateLimit-Remaining", "0")
 	w.WriteHeader(http.StatusTooManyRequests)
 
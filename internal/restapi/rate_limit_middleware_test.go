@@ -1,17 +1,22 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 func TestNewRateLimitMiddleware(t *testing.T) {
@@ -143,6 +148,66 @@ func TestRateLimitMiddleware_ExemptsConfiguredKeys(t *testing.T) {
 	})
 }
 
+func TestRateLimitMiddleware_ExemptsConfiguredIPRanges(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	middleware := NewRateLimitMiddleware(1, time.Second, nil)
+	middleware.SetExemptIPRanges([]string{"10.20.0.0/16", "2001:db8::1", "not-an-ip", ""})
+	require.Len(t, middleware.exemptIPRanges, 2, "invalid and empty entries are skipped")
+	limitedHandler := middleware.Handler()(handler)
+
+	serve := func(remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/test?key=signage", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		limitedHandler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, serve("10.20.5.6:41234"), "address inside an exempt range")
+		assert.Equal(t, http.StatusOK, serve("[::ffff:10.20.5.6]:41234"), "IPv4-mapped address inside an exempt range")
+		assert.Equal(t, http.StatusOK, serve("[2001:db8::1]:41234"), "exempt single address")
+	}
+
+	assert.Equal(t, http.StatusOK, serve("10.21.0.1:41234"), "first request outside the ranges uses the bucket")
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.21.0.1:41234"), "second request outside the ranges is limited")
+}
+
+func TestRateLimitMiddleware_Status(t *testing.T) {
+	middleware := NewRateLimitMiddleware(5, time.Second, []string{"exempt-key"})
+	middleware.SetExemptIPRanges([]string{"10.0.0.0/8"})
+
+	handler := middleware.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test?key=other", nil))
+	}
+
+	status := middleware.Status(context.Background(), "other", netip.Addr{})
+	assert.Equal(t, "other", status.APIKey)
+	assert.False(t, status.Exempt)
+	assert.False(t, status.Unlimited)
+	assert.Equal(t, 5, status.Limit)
+	assert.InDelta(t, 5.0, status.RefillPerSecond, 0.001)
+	assert.Equal(t, 3, status.Remaining)
+	assert.Empty(t, status.IP)
+
+	status = middleware.Status(context.Background(), "exempt-key", netip.Addr{})
+	assert.True(t, status.Exempt)
+	assert.Equal(t, rateLimitExemptAPIKey, status.ExemptReason)
+
+	status = middleware.Status(context.Background(), "other", netip.MustParseAddr("10.1.2.3"))
+	assert.True(t, status.Exempt)
+	assert.Equal(t, rateLimitExemptIPRange, status.ExemptReason)
+	assert.Equal(t, "10.1.2.3", status.IP)
+
+	unlimited := NewRateLimitMiddleware(-1, time.Second, nil).Status(context.Background(), "other", netip.Addr{})
+	assert.True(t, unlimited.Unlimited)
+	assert.Zero(t, unlimited.Remaining)
+}
+
 func TestRateLimitMiddleware_HandlesNoAPIKey(t *testing.T) {
 	middleware := NewRateLimitMiddleware(5, time.Second, nil)
 
@@ -353,13 +418,8 @@ func TestRateLimitMiddleware_CorrectRetryAfterTime(t *testing.T) {
 			})
 			limited := middleware.Handler()(handler)
 
-			// Drain the burst and reserve one full burst into the future so the
-			// next HTTP request remains rate limited even if CI is slow enough for
-			// a few tokens to refill between these calls and ServeHTTP.
-			now := time.Now()
-			assert.True(t, middleware.limiter.AllowN(now, testCase.rateLimit))
-			reservation := middleware.limiter.ReserveN(now, testCase.rateLimit)
-			assert.True(t, reservation.OK())
+			// An empty bucket keeps the request limited however slow CI is.
+			middleware.SetBackend(emptyBackend{})
 
 			req := httptest.NewRequest(http.MethodGet, "/test?key=test-key", nil)
 			last := httptest.NewRecorder()
@@ -385,10 +445,7 @@ func TestRateLimitMiddleware_CorrectRetryAfterTime(t *testing.T) {
 		})
 		limited := middleware.Handler()(handler)
 
-		now := time.Now()
-		assert.True(t, middleware.limiter.AllowN(now, 1))
-		reservation := middleware.limiter.ReserveN(now, 1)
-		assert.True(t, reservation.OK())
+		middleware.SetBackend(emptyBackend{})
 
 		req := httptest.NewRequest(http.MethodGet, "/test?key=test-key", nil)
 		last := httptest.NewRecorder()
@@ -405,3 +462,65 @@ func TestRateLimitMiddleware_CorrectRetryAfterTime(t *testing.T) {
 		assert.Equal(t, expected, int(retryAfter))
 	})
 }
+
+func TestRateLimitMiddleware_Reconfigure(t *testing.T) {
+	middleware := NewRateLimitMiddleware(1, time.Second, []string{"old-exempt"})
+	handler := middleware.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(key string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/test?key="+key, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("client"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("client"))
+
+	middleware.Reconfigure(3, []string{"new-exempt"}, []string{"10.0.0.0/8"})
+	for range 3 {
+		assert.Equal(t, http.StatusOK, serve("client"), "a new rate starts with a full bucket")
+	}
+	assert.Equal(t, http.StatusTooManyRequests, serve("client"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("old-exempt"), "removed exemptions no longer apply")
+	assert.Equal(t, http.StatusOK, serve("new-exempt"))
+	assert.True(t, middleware.Status(context.Background(), "client", netip.MustParseAddr("10.1.2.3")).Exempt)
+
+	// Keeping the rate keeps the bucket's current level.
+	middleware.Reconfigure(3, nil, nil)
+	assert.Equal(t, http.StatusTooManyRequests, serve("client"))
+}
+
+// emptyBackend is a rate limit backend whose bucket never has a token.
+type emptyBackend struct{}
+
+func (emptyBackend) Allow(context.Context, rate.Limit, int) (bool, int, error) { return false, 0, nil }
+
+func (emptyBackend) Remaining(context.Context, rate.Limit, int) (int, error) { return 0, nil }
+
+func (emptyBackend) Close() error { return nil }
+
+// failingBackend is a rate limit backend whose store cannot be reached.
+type failingBackend struct{}
+
+func (failingBackend) Allow(context.Context, rate.Limit, int) (bool, int, error) {
+	return false, 0, errors.New("connection refused")
+}
+
+func (failingBackend) Remaining(context.Context, rate.Limit, int) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (failingBackend) Close() error { return nil }
+
+func TestRateLimitMiddleware_FailsOpenWhenBackendFails(t *testing.T) {
+	middleware := NewRateLimitMiddleware(1, time.Second, nil)
+	middleware.SetBackend(failingBackend{})
+	handler := middleware.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for range 3 {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/test?key=client", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Remaining"))
+	}
+	assert.Zero(t, middleware.Status(context.Background(), "client", netip.Addr{}).Remaining)
+}
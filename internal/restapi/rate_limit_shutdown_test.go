@@ -3,9 +3,15 @@ package restapi
 import (
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
 )
 
 func TestRestAPI_Shutdown(t *testing.T) {
+	verifyNoGoroutineLeaks(t)
 	api := createTestApi(t)
 	defer api.Shutdown()
 
@@ -23,9 +29,48 @@ func TestRestAPI_Shutdown(t *testing.T) {
 }
 
 func TestRestAPI_ShutdownIdempotent(t *testing.T) {
+	verifyNoGoroutineLeaks(t)
+	api := createTestApi(t)
+
+	api.Shutdown()
+	api.Shutdown()
+	api.Shutdown()
+}
+
+func TestRestAPI_ShutdownStopsBackgroundWork(t *testing.T) {
+	verifyNoGoroutineLeaks(t)
 	api := createTestApi(t)
+	api.Config.Canary = appconf.CanaryConfig{
+		Interval: time.Hour,
+		StopID:   utils.FormCombinedID(testdata.Raba.ID, mustGetStop(t, api).ID),
+	}
 
+	api.StartScheduler()
+	api.StartCanary(api.SetupAPIRoutes())
 	api.Shutdown()
+}
+
+func TestRestAPI_StartAfterShutdownDoesNothing(t *testing.T) {
+	verifyNoGoroutineLeaks(t)
+	api := createTestApi(t)
+	api.Config.Canary = appconf.CanaryConfig{
+		Interval: time.Hour,
+		StopID:   utils.FormCombinedID(testdata.Raba.ID, mustGetStop(t, api).ID),
+	}
+
 	api.Shutdown()
+	api.StartScheduler()
+	api.StartCanary(api.SetupAPIRoutes())
+
+	assert.Nil(t, api.canary)
+}
+
+func TestRestAPI_ShutdownUnregistersConfigListener(t *testing.T) {
+	api := createTestApi(t)
+	suppressed := api.suppressed.Load()
+
 	api.Shutdown()
+	api.ApplyConfig(api.CurrentConfig())
+
+	assert.Same(t, suppressed, api.suppressed.Load(), "config change reached a shut down API")
 }
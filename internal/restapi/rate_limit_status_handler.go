@@ -0,0 +1,38 @@
+package restapi
+
+import (
+	"net/http"
+	"net/netip"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// rateLimitStatusHandler reports the rate limit bucket state for the API key in
+// the path, to help debug throttling complaints. The optional ip parameter also
+// checks a client address against the exempt IP ranges.
+func (api *RestAPI) rateLimitStatusHandler(w http.ResponseWriter, r *http.Request) {
+	apiKey, ok := api.extractAndValidateID(w, r)
+	if !ok {
+		return
+	}
+
+	var addr netip.Addr
+	if ip := r.URL.Query().Get("ip"); ip != "" {
+		var err error
+		addr, err = netip.ParseAddr(ip)
+		if err != nil {
+			fieldErrors := map[string][]string{"ip": {"ip must be a valid IP address"}}
+			api.validationErrorResponse(w, r, fieldErrors)
+			return
+		}
+	}
+
+	if api.rateLimiter == nil {
+		api.sendError(w, r, http.StatusNotFound, "rate limiting is not configured")
+		return
+	}
+
+	status := api.rateLimiter.Status(r.Context(), apiKey, addr)
+	response := models.NewEntryResponse(status, *models.NewEmptyReferences(), api.Clock)
+	api.sendResponse(w, r, response)
+}
@@ -0,0 +1,68 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type rateLimitStatusResponse struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+	Data struct {
+		Entry models.RateLimitStatus `json:"entry"`
+	} `json:"data"`
+}
+
+func TestRateLimitStatusRequiresProtectedApiKey(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[rateLimitStatusResponse](t, api, "/api/where/rate-limit-status/TEST.json?key=TEST")
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, model.Code)
+}
+
+func TestRateLimitStatusForKey(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[rateLimitStatusResponse](t, api, "/api/where/rate-limit-status/TEST.json?key=PROTECTED-TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entry := model.Data.Entry
+	assert.Equal(t, "TEST", entry.APIKey)
+	assert.False(t, entry.Exempt)
+	assert.Equal(t, 5, entry.Limit)
+	assert.GreaterOrEqual(t, entry.Remaining, 0)
+	assert.LessOrEqual(t, entry.Remaining, 5)
+}
+
+func TestRateLimitStatusForExemptKeyAndIP(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	api.rateLimiter.SetExemptIPRanges([]string{"10.20.0.0/16"})
+
+	_, model := callAPIHandler[rateLimitStatusResponse](t, api, "/api/where/rate-limit-status/org.onebusaway.iphone.json?key=PROTECTED-TEST")
+	assert.True(t, model.Data.Entry.Exempt)
+	assert.Equal(t, "api-key", model.Data.Entry.ExemptReason)
+
+	_, model = callAPIHandler[rateLimitStatusResponse](t, api, "/api/where/rate-limit-status/TEST.json?key=PROTECTED-TEST&ip=10.20.1.1")
+	assert.True(t, model.Data.Entry.Exempt)
+	assert.Equal(t, "ip-range", model.Data.Entry.ExemptReason)
+	assert.Equal(t, "10.20.1.1", model.Data.Entry.IP)
+}
+
+func TestRateLimitStatusInvalidIP(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[rateLimitStatusResponse](t, api, "/api/where/rate-limit-status/TEST.json?key=PROTECTED-TEST&ip=signage")
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "ip must be a valid IP address", model.Text)
+}
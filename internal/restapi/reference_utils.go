@@ -4,7 +4,6 @@ import (
 	"context"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/OneBusAway/go-gtfs"
 	"maglev.onebusaway.org/gtfsdb"
@@ -64,19 +63,7 @@ func buildRouteModels(ctx context.Context, agencyID string, routes []gtfsdb.Rout
 			return nil, ctx.Err()
 		}
 
-		combinedID := utils.FormCombinedID(agencyID, route.ID)
-
-		routeModel := models.NewRoute(
-			combinedID,
-			agencyID,
-			route.ShortName.String,
-			route.LongName.String,
-			route.Desc.String,
-			models.RouteType(route.Type),
-			route.Url.String,
-			route.Color.String,
-			route.TextColor.String,
-		)
+		routeModel := utils.RouteFromDatabase(agencyID, route)
 		modelRoutes = append(modelRoutes, routeModel)
 	}
 
@@ -94,21 +81,28 @@ func routeReferencesForStops(routesRows []gtfsdb.GetRoutesForStopsRow) []models.
 			continue
 		}
 
-		routesMap[combinedRouteID] = models.NewRoute(
-			combinedRouteID,
-			row.AgencyID,
-			nulls.StringOrEmpty(row.ShortName),
-			nulls.StringOrEmpty(row.LongName),
-			nulls.StringOrEmpty(row.Desc),
-			models.RouteType(row.Type),
-			nulls.StringOrEmpty(row.Url),
-			nulls.StringOrEmpty(row.Color),
-			nulls.StringOrEmpty(row.TextColor))
+		routesMap[combinedRouteID] = utils.RouteFromDatabase(row.AgencyID, routeFromStopsRow(row))
 	}
 
 	return utils.MapValues(routesMap)
 }
 
+// routeFromStopsRow returns the route of a GetRoutesForStops row.
+func routeFromStopsRow(row gtfsdb.GetRoutesForStopsRow) gtfsdb.Route {
+	return gtfsdb.Route{
+		ID:        row.ID,
+		AgencyID:  row.AgencyID,
+		ShortName: row.ShortName,
+		LongName:  row.LongName,
+		Desc:      row.Desc,
+		Type:      row.Type,
+		Url:       row.Url,
+		Color:     row.Color,
+		TextColor: row.TextColor,
+		SortOrder: row.SortOrder,
+	}
+}
+
 // agencyReferencesForStops deduplicates the rows returned by GetAgenciesForStops into
 // AgencyReference objects, reusing AgencyReferenceFromDatabase for the field mapping.
 func agencyReferencesForStops(agencyRows []gtfsdb.GetAgenciesForStopsRow) []models.AgencyReference {
@@ -133,77 +127,6 @@ func agencyReferencesForStops(agencyRows []gtfsdb.GetAgenciesForStopsRow) []mode
 	return utils.MapValues(agenciesMap)
 }
 
-func (api *RestAPI) BuildSituationReferences(alerts []gtfs.Alert) []models.Situation {
-	situations := make([]models.Situation, 0, len(alerts))
-
-	for _, alert := range alerts {
-		situation := models.Situation{
-			ID:                 alert.ID,
-			CreationTime:       models.NewModelTime(time.Time{}),
-			ActiveWindows:      make([]models.ActiveWindow, 0, len(alert.ActivePeriods)),
-			AllAffects:         make([]models.AffectedEntity, 0, len(alert.InformedEntities)),
-			ConsequenceMessage: "",
-			Consequences:       []any{},
-			PublicationWindows: []any{},
-			Reason:             mapAlertCauseToReason(alert.Cause),
-			Severity:           mapAlertEffectToSeverity(alert.Effect),
-		}
-
-		for _, period := range alert.ActivePeriods {
-			window := models.ActiveWindow{}
-			if period.StartsAt != nil {
-				window.From = period.StartsAt.UnixMilli()
-			}
-			if period.EndsAt != nil {
-				window.To = period.EndsAt.UnixMilli()
-			}
-			situation.ActiveWindows = append(situation.ActiveWindows, window)
-		}
-
-		for _, entity := range alert.InformedEntities {
-			affectedEntity := models.AffectedEntity{
-				AgencyID:      getStringValue(entity.AgencyID),
-				ApplicationID: "",
-				DirectionID:   entity.DirectionID.String(),
-				RouteID:       getStringValue(entity.RouteID),
-				StopID:        getStringValue(entity.StopID),
-				TripID:        "",
-			}
-
-			if entity.TripID != nil {
-				affectedEntity.TripID = entity.TripID.ID
-			}
-
-			situation.AllAffects = append(situation.AllAffects, affectedEntity)
-		}
-
-		if len(alert.Header) > 0 && alert.Header[0].Text != "" {
-			situation.Summary = &models.TranslatedString{
-				Value: alert.Header[0].Text,
-				Lang:  alert.Header[0].Language,
-			}
-		}
-
-		if len(alert.Description) > 0 && alert.Description[0].Text != "" {
-			situation.Description = &models.TranslatedString{
-				Value: alert.Description[0].Text,
-				Lang:  alert.Description[0].Language,
-			}
-		}
-
-		if len(alert.URL) > 0 && alert.URL[0].Text != "" {
-			situation.URL = &models.TranslatedString{
-				Value: alert.URL[0].Text,
-				Lang:  alert.URL[0].Language,
-			}
-		}
-
-		situations = append(situations, situation)
-	}
-
-	return situations
-}
-
 func getStringValue(ptr *string) string {
 	if ptr == nil {
 		return ""
@@ -374,17 +297,7 @@ func groupRoutesByStop(agencyID string, allRoutes []gtfsdb.GetRoutesForStopsRow)
 	routesByStop := make(map[string][]gtfsdb.Route)
 	uniqueRouteMap := make(map[string]gtfsdb.GetRoutesForStopsRow)
 	for _, routeRow := range allRoutes {
-		route := gtfsdb.Route{
-			ID:        routeRow.ID,
-			AgencyID:  routeRow.AgencyID,
-			ShortName: routeRow.ShortName,
-			LongName:  routeRow.LongName,
-			Desc:      routeRow.Desc,
-			Type:      routeRow.Type,
-			Url:       routeRow.Url,
-			Color:     routeRow.Color,
-			TextColor: routeRow.TextColor,
-		}
+		route := routeFromStopsRow(routeRow)
 		routesByStop[routeRow.StopID] = append(routesByStop[routeRow.StopID], route)
 		combinedID := utils.FormCombinedID(agencyID, routeRow.ID)
 		uniqueRouteMap[combinedID] = routeRow
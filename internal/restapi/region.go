@@ -0,0 +1,151 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// regionHeader names the region a request is meant for. The same can be
+// done with a /regions/{id} prefix on the path.
+const regionHeader = "X-Region"
+
+const regionPathPrefix = "/regions/"
+
+// regionRouter knows which configured region each agency belongs to. It is
+// the groundwork for serving several regional datasets from one process:
+// the datasets are loaded together, and a request routed to a region is
+// refused IDs of agencies outside it and only finds the region's stops,
+// routes and agencies in searches by location and agencies-with-coverage.
+type regionRouter struct {
+	ids           []string
+	agencyRegions map[string]string
+}
+
+// newRegionRouter returns the router for regions, or nil when none are
+// configured.
+func newRegionRouter(regions []appconf.RegionConfig) *regionRouter {
+	if len(regions) == 0 {
+		return nil
+	}
+	router := &regionRouter{agencyRegions: make(map[string]string)}
+	for _, region := range regions {
+		router.ids = append(router.ids, region.ID)
+		for _, agencyID := range region.AgencyIDs {
+			router.agencyRegions[agencyID] = region.ID
+		}
+	}
+	slices.Sort(router.ids)
+	return router
+}
+
+type regionContextKey struct{}
+
+// requestRegion returns the region the request was routed to, or "".
+func requestRegion(ctx context.Context) string {
+	region, _ := ctx.Value(regionContextKey{}).(string)
+	return region
+}
+
+// RegionMiddleware routes API requests to the region named by the X-Region
+// header or a /regions/{id} path prefix, which is removed before the request
+// reaches the routes. Unknown regions are answered 404 with the configured
+// ones. Without configured regions the header is ignored.
+func (api *RestAPI) RegionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if api.regions == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		region := r.Header.Get(regionHeader)
+		if rest, ok := strings.CutPrefix(r.URL.Path, regionPathPrefix); ok {
+			pathRegion, path, _ := strings.Cut(rest, "/")
+			if region != "" && region != pathRegion {
+				api.sendError(w, r, http.StatusBadRequest,
+					fmt.Sprintf("the %s header names region %q but the path names region %q", regionHeader, region, pathRegion))
+				return
+			}
+			region = pathRegion
+			r = r.Clone(r.Context())
+			r.URL.Path = "/" + path
+			r.URL.RawPath = ""
+		}
+		if region == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !slices.Contains(api.regions.ids, region) {
+			api.sendError(w, r, http.StatusNotFound,
+				fmt.Sprintf("unknown region %q; this server has regions %s", region, strings.Join(api.regions.ids, ", ")))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), regionContextKey{}, region)))
+	})
+}
+
+// inRequestRegion reports whether agencyID belongs to the region the request
+// was routed to. Every agency does when it was not routed to one.
+func (api *RestAPI) inRequestRegion(ctx context.Context, agencyID string) bool {
+	region := requestRegion(ctx)
+	if region == "" || api.regions == nil {
+		return true
+	}
+	return api.regions.agencyRegions[agencyID] == region
+}
+
+// regionIDParams are the query parameters that hold combined IDs.
+var regionIDParams = []string{"tripId", "stopId", "routeId", "vehicleId"}
+
+// allowRegion refuses a request routed to a region when the {id} in its path
+// or a combined ID in its query belongs to an agency outside the region,
+// naming the region that serves it. IDs that cannot be parsed are left to
+// the handler to reject.
+func (api *RestAPI) allowRegion(w http.ResponseWriter, r *http.Request) bool {
+	region := requestRegion(r.Context())
+	if region == "" || api.regions == nil {
+		return true
+	}
+
+	if id := utils.ExtractIDFromParams(r); id != "" {
+		// Agency endpoints take a bare agency ID rather than a combined one.
+		agencyID := id
+		if !strings.HasSuffix(r.Pattern, "agency/{id}") {
+			agencyID, _ = utils.ExtractAgencyID(id)
+		}
+		if !api.checkRegion(w, r, region, "id", id, agencyID) {
+			return false
+		}
+	}
+	query := r.URL.Query()
+	for _, param := range regionIDParams {
+		if id := query.Get(param); id != "" {
+			agencyID, _ := utils.ExtractAgencyID(id)
+			if !api.checkRegion(w, r, region, param, id, agencyID) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (api *RestAPI) checkRegion(w http.ResponseWriter, r *http.Request, region, param, id, agencyID string) bool {
+	if agencyID == "" {
+		return true
+	}
+	owner, ok := api.regions.agencyRegions[agencyID]
+	if owner == region {
+		return true
+	}
+	message := fmt.Sprintf("%s %q belongs to agency %q, which is not in region %q", param, id, agencyID, region)
+	if ok {
+		message += fmt.Sprintf("; use region %q", owner)
+	}
+	api.validationErrorResponse(w, r, map[string][]string{param: {message}})
+	return false
+}
@@ -0,0 +1,114 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func createRegionTestApi(t *testing.T) *RestAPI {
+	t.Helper()
+	api := createTestApi(t)
+	api.regions = newRegionRouter([]appconf.RegionConfig{
+		{ID: "north", AgencyIDs: []string{testdata.Raba.ID}},
+		{ID: "south", AgencyIDs: []string{"40"}},
+	})
+	return api
+}
+
+func TestRegionPathPrefix(t *testing.T) {
+	api := createRegionTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api, "/regions/north/api/where/agency/"+testdata.Raba.ID+".json?key=TEST")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, model.Code)
+
+	resp, model = serveApiAndRetrieveEndpoint(t, api, "/regions/south/api/where/agency/"+testdata.Raba.ID+".json?key=TEST")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, `id "25" belongs to agency "25", which is not in region "south"; use region "north"`, model.Text)
+
+	resp, model = serveApiAndRetrieveEndpoint(t, api, "/regions/south/api/where/stop/25_2000.json?key=TEST")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, model.Text, `use region "north"`)
+
+	resp, model = serveApiAndRetrieveEndpoint(t, api, "/regions/west/api/where/current-time.json?key=TEST")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, `unknown region "west"; this server has regions north, south`, model.Text)
+}
+
+func TestRegionHeader(t *testing.T) {
+	api := createRegionTestApi(t)
+	defer api.Shutdown()
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	get := func(path, region string) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set(regionHeader, region)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get("/api/where/routes-for-agency/25.json?key=TEST", "north"))
+	assert.Equal(t, http.StatusBadRequest, get("/api/where/routes-for-agency/25.json?key=TEST", "south"))
+	assert.Equal(t, http.StatusBadRequest, get("/api/where/arrival-and-departure-for-stop/40_1.json?key=TEST&tripId=25_1", "south"),
+		"combined IDs in the query are checked too")
+	assert.Equal(t, http.StatusBadRequest, get("/regions/north/api/where/current-time.json?key=TEST", "south"))
+	assert.Equal(t, http.StatusNotFound, get("/api/where/current-time.json?key=TEST", "east"))
+}
+
+func TestRegionIgnoredWithoutRegions(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	require.Nil(t, api.regions)
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/where/agency/25.json?key=TEST", nil)
+	require.NoError(t, err)
+	req.Header.Set(regionHeader, "anywhere")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRegionFiltersLocationSearchesAndCoverage(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(time.Date(2025, 12, 26, 14, 0, 0, 0, time.UTC)))
+	api.regions = newRegionRouter([]appconf.RegionConfig{
+		{ID: "north", AgencyIDs: []string{testdata.Raba.ID}},
+		{ID: "south", AgencyIDs: []string{"40"}},
+	})
+
+	list := func(region, path string) []any {
+		t.Helper()
+		resp, model := serveApiAndRetrieveEndpoint(t, api, "/regions/"+region+path)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, ok := model.Data.(map[string]any)
+		require.True(t, ok)
+		entries, _ := data["list"].([]any)
+		return entries
+	}
+
+	for _, path := range []string{
+		"/api/where/agencies-with-coverage.json?key=org.onebusaway.iphone",
+		"/api/where/stops-for-location.json?key=org.onebusaway.iphone&lat=40.583321&lon=-122.426966&radius=5000",
+		"/api/where/routes-for-location.json?key=org.onebusaway.iphone&lat=40.583321&lon=-122.426966&radius=5000",
+	} {
+		// The responses of both regions are cached apart, whichever comes first.
+		assert.NotEmpty(t, list("north", path), path)
+		assert.Empty(t, list("south", path), "%s has nothing of agency 25 in region south", path)
+		assert.NotEmpty(t, list("north", path), path)
+	}
+}
@@ -0,0 +1,191 @@
+package restapi
+
+import (
+	"container/list"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCachedResponses bounds the static response cache. Each entry is one
+// encoded response, so this keeps the cache to a few tens of megabytes even
+// for the larger agency-wide lists.
+const maxCachedResponses = 4096
+
+// cachedStatic serves handler from the response cache for endpoints whose
+// responses only change when the static feed reloads. Like etagStatic it
+// answers conditional requests with 304 Not Modified, and it additionally
+// honours If-Modified-Since against the static import time reported in
+// Last-Modified. Responses are keyed by the feed's ETag and the request's
+// path and query parameters, ignoring the API key (see coalescingKey), so a
+// hot-swap of the static data invalidates every entry built from the old
+// feed. The envelope's currentTime is that of the request that filled the
+// entry. Only 200 responses are stored. A handler filling an entry is
+// pinned to one dataset (see pinnedDataset).
+//
+// With datasetVersion the request is answered from a dataset retained from
+// an earlier reload (see datasetView), by handler called on an API over that
+// dataset, which keeps its own cache and ETag.
+func cachedStatic(api *RestAPI, handler func(*RestAPI, http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return cachedStaticFor(api, handler, 0)
+}
+
+// cachedStaticHourly is cachedStatic for responses that also describe the
+// current service day, like the agency's service hours. Its entries and ETag
+// are renewed at the start of every hour, so a new day shows within an hour
+// of midnight in any agency's time zone.
+func cachedStaticHourly(api *RestAPI, handler func(*RestAPI, http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return cachedStaticFor(api, handler, time.Hour)
+}
+
+// cachedStaticFor implements cachedStatic, renewing entries every period
+// when it is non-zero. Last-Modified is then no earlier than the start of
+// the current period, so that If-Modified-Since cannot keep a previous
+// period's response.
+func cachedStaticFor(api *RestAPI, handler func(*RestAPI, http.ResponseWriter, *http.Request), period time.Duration) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.GtfsManager == nil {
+			handler(api, w, r)
+			return
+		}
+		api, ok := api.forDatasetVersion(w, r)
+		if !ok {
+			return
+		}
+		serve := pinnedDataset(api, func(w http.ResponseWriter, r *http.Request) { handler(api, w, r) })
+		etag := api.staticETag(r.Context())
+		if etag == "" {
+			serve(w, r)
+			return
+		}
+		lastModified := api.GtfsManager.GetStaticLastUpdated(r.Context())
+		responseETag, key := etag, coalescingKey(r)
+		if period > 0 {
+			// The period goes in the key rather than the cache's ETag, which
+			// would empty the cache for every other endpoint.
+			start := api.Clock.Now().Truncate(period)
+			suffix := strconv.FormatInt(start.Unix(), 36)
+			responseETag += "-" + suffix
+			key += " @" + suffix
+			if start.After(lastModified) {
+				lastModified = start
+			}
+		}
+
+		w.Header().Set("ETag", responseETag)
+		if !lastModified.IsZero() {
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		}
+		if notModified(r, responseETag, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		resp, ok := api.responseCache.get(etag, key)
+		if !ok {
+			rec := &coalescingRecorder{header: make(http.Header)}
+			serve(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			resp = &coalescedResponse{header: rec.header, status: rec.status, body: rec.body.Bytes()}
+			if resp.status == http.StatusOK {
+				api.responseCache.put(etag, key, resp)
+			}
+		}
+
+		for name, values := range resp.header {
+			w.Header()[name] = slices.Clone(values)
+		}
+		w.WriteHeader(resp.status)
+		_, _ = w.Write(resp.body)
+	}
+}
+
+// notModified evaluates a request's conditional headers. If-None-Match takes
+// precedence; If-Modified-Since is only consulted without it, as RFC 7232
+// requires.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have one second resolution.
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// responseCache is an LRU of encoded responses for one static feed version.
+// Reading it with a different ETag than the one its entries were built from
+// empties it. A nil cache stores nothing.
+type responseCache struct {
+	mu      sync.Mutex
+	max     int
+	etag    string
+	order   *list.List // most recently used at the front
+	entries map[string]*list.Element
+}
+
+type responseCacheEntry struct {
+	key  string
+	resp *coalescedResponse
+}
+
+func newResponseCache(max int) *responseCache {
+	return &responseCache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *responseCache) get(etag, key string) (*coalescedResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if etag != c.etag {
+		c.etag = etag
+		c.order.Init()
+		clear(c.entries)
+		return nil, false
+	}
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*responseCacheEntry).resp, true
+}
+
+// put stores resp unless the cache has since moved on to another feed
+// version, so a response built before a reload is never kept after it.
+func (c *responseCache) put(etag, key string, resp *coalescedResponse) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if etag != c.etag {
+		return
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*responseCacheEntry).resp = resp
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&responseCacheEntry{key: key, resp: resp})
+	if c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*responseCacheEntry).key)
+	}
+}
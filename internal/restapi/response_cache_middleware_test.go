@@ -0,0 +1,193 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestResponseCache_NewETagDropsEntries(t *testing.T) {
+	cache := newResponseCache(2)
+	resp := &coalescedResponse{status: http.StatusOK, body: []byte("a")}
+
+	_, ok := cache.get("v1", "a")
+	assert.False(t, ok)
+	cache.put("v1", "a", resp)
+	got, ok := cache.get("v1", "a")
+	require.True(t, ok)
+	assert.Same(t, resp, got)
+
+	_, ok = cache.get("v2", "a")
+	assert.False(t, ok, "a new feed version must not serve the old response")
+
+	cache.put("v1", "a", resp)
+	_, ok = cache.get("v2", "a")
+	assert.False(t, ok, "a response built from the old feed must not be stored after a reload")
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newResponseCache(2)
+	resp := &coalescedResponse{status: http.StatusOK}
+
+	_, _ = cache.get("v1", "a")
+	cache.put("v1", "a", resp)
+	cache.put("v1", "b", resp)
+	_, _ = cache.get("v1", "a")
+	cache.put("v1", "c", resp)
+
+	_, ok := cache.get("v1", "b")
+	assert.False(t, ok)
+	_, ok = cache.get("v1", "a")
+	assert.True(t, ok)
+	_, ok = cache.get("v1", "c")
+	assert.True(t, ok)
+}
+
+func TestCachedStatic_ServesRepeatRequestsFromCache(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	calls := 0
+	handler := cachedStatic(api, func(_ *RestAPI, w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("missing") != "" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(`{"code":200}`))
+	})
+	serve := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	first := serve("/api/where/agency/1.json?key=TEST")
+	second := serve("/api/where/agency/1.json?key=OTHER")
+	assert.Equal(t, 1, calls, "the API key must not affect the cache key")
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.NotEmpty(t, second.Header().Get("ETag"))
+	assert.NotEmpty(t, second.Header().Get("Last-Modified"))
+
+	serve("/api/where/agency/2.json?key=TEST")
+	assert.Equal(t, 2, calls)
+
+	serve("/api/where/agency/1.json?key=TEST&missing=1")
+	serve("/api/where/agency/1.json?key=TEST&missing=1")
+	assert.Equal(t, 4, calls, "error responses must not be cached")
+}
+
+func TestCachedStatic_ConditionalRequests(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	etag := api.GtfsManager.GetSystemETag(context.Background())
+	lastModified := api.GtfsManager.GetStaticLastUpdated(context.Background())
+	require.NotEmpty(t, etag)
+	require.False(t, lastModified.IsZero())
+
+	calls := 0
+	handler := cachedStatic(api, func(_ *RestAPI, w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte("body"))
+	})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"matching ETag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"stale ETag", map[string]string{"If-None-Match": `"old"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified.Add(time.Second).Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"ETag takes precedence", map[string]string{
+			"If-None-Match":     `"old"`,
+			"If-Modified-Since": lastModified.Add(time.Second).Format(http.TimeFormat),
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/where/shape/1.json?key=TEST", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			assert.Equal(t, lastModified.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			}
+		})
+	}
+	assert.Equal(t, 1, calls, "every 200 after the first is served from the cache")
+}
+
+func TestAgencyEndpointIsCachedThroughMux(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api, "/api/where/agency/"+testdata.Raba.ID+".json?key=TEST")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, model.Code)
+	assert.NotEmpty(t, resp.Header.Get("ETag"))
+	assert.NotEmpty(t, resp.Header.Get("Last-Modified"))
+
+	hour := strconv.FormatInt(api.Clock.Now().Truncate(time.Hour).Unix(), 36)
+	_, ok := api.responseCache.get(api.GtfsManager.GetSystemETag(context.Background()),
+		coalescingKey(httptest.NewRequest(http.MethodGet, "/api/where/agency/"+testdata.Raba.ID+".json", nil))+" @"+hour)
+	assert.True(t, ok)
+}
+
+func TestCachedStaticHourly_RenewsEveryHour(t *testing.T) {
+	// After the static import, which the test API does on startup.
+	mockClock := clock.NewMockClock(time.Now().Add(24 * time.Hour))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+
+	calls := 0
+	handler := cachedStaticHourly(api, func(_ *RestAPI, w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte("body"))
+	})
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/where/agency/1.json?key=TEST", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	first := serve(nil)
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+	assert.Equal(t, mockClock.Now().Truncate(time.Hour).Format(http.TimeFormat), lastModified)
+	serve(nil)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusNotModified, serve(map[string]string{"If-None-Match": etag}).Code)
+
+	mockClock.Advance(time.Hour)
+	assert.Equal(t, http.StatusOK, serve(map[string]string{"If-None-Match": etag}).Code)
+	assert.Equal(t, http.StatusOK, serve(map[string]string{"If-Modified-Since": lastModified}).Code)
+	assert.Equal(t, 2, calls, "a new hour is built once and then served from the cache")
+
+	// Other endpoints keep their entries.
+	_, ok := api.responseCache.get(api.GtfsManager.GetSystemETag(context.Background()), "unrelated")
+	assert.False(t, ok)
+	assert.Equal(t, 2, api.responseCache.order.Len())
+}
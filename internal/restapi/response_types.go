@@ -48,16 +48,23 @@ type StopIDsForAgencyResponse ListResponse[string]
 type AgencyEntryResponse EntryResponse[models.AgencyReference]
 type ScheduleForRouteResponse EntryResponse[models.ScheduleForRouteEntry]
 type StopsForRouteResponse EntryResponse[models.RouteEntry]
+type FaresForRouteResponse EntryResponse[models.RouteFares]
+type FaresForLegResponse EntryResponse[models.LegFares]
+type TransfersForStopResponse ListResponse[models.Transfer]
 type TripDetailsResponse EntryResponse[models.TripDetails]
 type TripsForLocationResponse ListResponse[models.TripsForLocationListEntry]
 type BlockEntryResponse EntryResponse[models.BlockEntry]
 type TripsForRouteResponse ListResponse[models.TripsForRouteListEntry]
 type ArrivalAndDepartureResponse EntryResponse[models.ArrivalAndDeparture]
 type ArrivalsAndDeparturesResponse EntryResponse[models.ArrivalsAndDeparturesEntry]
+type ArrivalsAndDeparturesForLocationResponse EntryResponse[models.ArrivalsAndDeparturesForLocationEntry]
 type VehiclesForAgencyResponse ListResponse[models.VehicleStatus]
+type BlockAssignmentsForAgencyResponse ListResponse[models.BlockAssignment]
+type ServiceExceptionsForAgencyResponse ListResponse[models.ServiceException]
 type ProblemReportsForStopResponse ListResponse[models.ProblemReportStop]
 type ProblemReportsForTripResponse ListResponse[models.ProblemReportTrip]
 type RouteEntryResponse EntryResponse[models.Route]
 type StopEntryResponse EntryResponse[models.Stop]
+type AmenitiesForStopEntryResponse EntryResponse[models.AmenitiesForStop]
 type TripEntryResponse EntryResponse[models.TripResponse]
 type ShapeEntryResponse EntryResponse[models.ShapeEntry]
@@ -1,21 +1,133 @@
 package restapi
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"regexp"
+	"sync"
 
 	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
 )
 
+// jsonpCallbackPattern matches the JSONP callback names sendResponse accepts:
+// a JavaScript identifier or a dotted path of them, as jQuery and the classic
+// OneBusAway web clients generate.
+var jsonpCallbackPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+const maxJSONPCallbackLength = 128
+
+// maxPooledResponseBuffer is the largest encoding buffer kept for reuse, so
+// one unusually large response does not stay in memory for good.
+const maxPooledResponseBuffer = 1 << 20
+
+// responseBuffers holds the buffers responses are encoded into. A buffer that
+// has grown to fit a large list keeps its size, so the next response of that
+// size is encoded without growing it again.
+var responseBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getResponseBuffer() *bytes.Buffer {
+	return responseBuffers.Get().(*bytes.Buffer)
+}
+
+func putResponseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledResponseBuffer {
+		return
+	}
+	buf.Reset()
+	responseBuffers.Put(buf)
+}
+
+// writeJSON encodes v into a pooled buffer and writes it to w in one call.
+// Nothing is written if v cannot be encoded, so the caller can still send an
+// error response.
+func writeJSON(w io.Writer, v any) error {
+	buf := getResponseBuffer()
+	defer putResponseBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// sendResponse writes response as JSON, or as JSONP when the request names a
+// callback, as the classic OneBusAway API does. Requests for protobuf get
+// the route's protobuf form instead (see ProtobufFormatMiddleware).
 func (api *RestAPI) sendResponse(w http.ResponseWriter, r *http.Request, response models.ResponseModel) {
+	callback := r.URL.Query().Get("callback")
+	if callback != "" && (len(callback) > maxJSONPCallbackLength || !jsonpCallbackPattern.MatchString(callback)) {
+		api.validationErrorResponse(w, r, map[string][]string{
+			"callback": {"callback must be a JavaScript identifier of at most 128 characters"},
+		})
+		return
+	}
+
+	api.prepareResponse(r.Context(), response)
+	if req, ok := requestedProtobuf(r); ok {
+		if md := protobufMessage(r); md != nil {
+			api.sendProtobuf(w, r, md, response)
+			return
+		}
+		if req.explicit {
+			api.sendError(w, r, http.StatusNotAcceptable, "this endpoint has no protobuf form")
+			return
+		}
+	}
+	if callback != "" {
+		api.sendJSONP(w, r, callback, response)
+		return
+	}
 	setJSONResponseType(&w)
-	err := json.NewEncoder(w).Encode(response)
+	err := writeJSON(w, response)
 	if err != nil {
 		api.serverErrorResponse(w, r, err)
 		return
 	}
 }
 
+// sendJSONP writes response as a call to callback. The leading empty comment
+// keeps the body from starting with attacker-chosen bytes, which some plugins
+// would otherwise sniff as another content type.
+func (api *RestAPI) sendJSONP(w http.ResponseWriter, r *http.Request, callback string, response models.ResponseModel) {
+	buf := getResponseBuffer()
+	defer putResponseBuffer(buf)
+	buf.WriteString("/**/" + callback + "(")
+	if err := json.NewEncoder(buf).Encode(response); err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	buf.Truncate(buf.Len() - 1) // the encoder's trailing newline
+	buf.WriteString(");\n")
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// prepareResponse fills in what handlers leave to be done in one place: the
+// timezones of the trips a response serializes, the order of its route
+// references, by route_sort_order and then name, the removal of suppressed
+// stops and routes, and the flagging of stops served by continuous stopping.
+func (api *RestAPI) prepareResponse(ctx context.Context, response models.ResponseModel) {
+	data, ok := response.Data.(map[string]any)
+	if !ok {
+		return
+	}
+	if tripResponse, ok := data["entry"].(*models.TripResponse); ok && tripResponse != nil && tripResponse.Trip != nil {
+		trips := []models.Trip{*tripResponse.Trip}
+		api.populateTripTimeZones(ctx, trips)
+		tripResponse.TimeZone = trips[0].TimeZone
+	}
+	api.suppression().hideSuppressed(data)
+	api.markFlagStops(ctx, data)
+	if references, ok := data["references"].(models.ReferencesModel); ok {
+		api.populateTripTimeZones(ctx, references.Trips)
+		utils.SortRouteReferences(references.Routes)
+	}
+}
+
 func (api *RestAPI) sendNull(w http.ResponseWriter, r *http.Request) { // nolint:unused
 	setJSONResponseType(&w)
 	_, err := w.Write([]byte("null"))
@@ -36,7 +148,7 @@ func (api *RestAPI) sendNotFound(w http.ResponseWriter, r *http.Request) {
 		Version:     models.APIVersion,
 	}
 
-	err := json.NewEncoder(w).Encode(response)
+	err := writeJSON(w, response)
 	if err != nil {
 		api.serverErrorResponse(w, r, err)
 		return
@@ -54,7 +166,7 @@ func (api *RestAPI) sendUnauthorized(w http.ResponseWriter, r *http.Request) { /
 		Version:     models.APIVersion,
 	}
 
-	err := json.NewEncoder(w).Encode(response)
+	err := writeJSON(w, response)
 	if err != nil {
 		api.serverErrorResponse(w, r, err)
 		return
@@ -76,7 +188,7 @@ func (api *RestAPI) sendError(w http.ResponseWriter, r *http.Request, code int,
 		Version:     models.APIVersion,
 	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := writeJSON(w, response); err != nil {
 		api.serverErrorResponse(w, r, err)
 	}
 }
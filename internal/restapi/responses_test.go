@@ -1,14 +1,20 @@
 package restapi
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
 )
 
 func TestSendResponse(t *testing.T) {
@@ -67,6 +73,93 @@ func TestSendResponse(t *testing.T) {
 	})
 }
 
+func TestSendResponseJSONP(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	response := models.ResponseModel{
+		Code:        http.StatusOK,
+		CurrentTime: 1234567890,
+		Text:        "OK",
+		Version:     models.APIVersion,
+		Data:        map[string]string{"test": "data"},
+	}
+
+	t.Run("wraps the response in the callback", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/test.json?callback=jQuery123_456.cb", nil)
+
+		api.sendResponse(w, r, response)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		require.True(t, strings.HasPrefix(body, "/**/jQuery123_456.cb("), body)
+		require.True(t, strings.HasSuffix(body, ");\n"), body)
+
+		var decoded models.ResponseModel
+		payload := strings.TrimSuffix(strings.TrimPrefix(body, "/**/jQuery123_456.cb("), ");\n")
+		require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+		assert.Equal(t, "OK", decoded.Text)
+	})
+
+	for _, callback := range []string{"alert(1)", "cb;evil", "1cb", "a..b", "<script>", strings.Repeat("a", 129)} {
+		t.Run("rejects "+callback[:min(len(callback), 16)], func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/test.json?callback="+url.QueryEscape(callback), nil)
+
+			api.sendResponse(w, r, response)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.NotContains(t, w.Body.String(), callback)
+		})
+	}
+}
+
+func TestSendResponseSortsRouteReferences(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	// The stop served by the most routes, and its routes in route_sort_order.
+	var stopID string
+	require.NoError(t, api.GtfsManager.GtfsDB.DB.QueryRowContext(context.Background(), `
+		SELECT st.stop_id FROM stop_times st JOIN trips t ON t.id = st.trip_id
+		GROUP BY st.stop_id ORDER BY COUNT(DISTINCT t.route_id) DESC, st.stop_id LIMIT 1`,
+	).Scan(&stopID))
+	rows, err := api.GtfsManager.GtfsDB.DB.QueryContext(context.Background(), `
+		SELECT DISTINCT r.id, r.sort_order FROM stop_times st
+		JOIN trips t ON t.id = st.trip_id JOIN routes r ON r.id = t.route_id
+		WHERE st.stop_id = ? ORDER BY r.sort_order IS NULL, r.sort_order`, stopID)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	var all, ordered []string
+	for rows.Next() {
+		var routeID string
+		var sortOrder sql.NullInt64
+		require.NoError(t, rows.Scan(&routeID, &sortOrder))
+		id := utils.FormCombinedID(testdata.Raba.ID, routeID)
+		all = append(all, id)
+		if sortOrder.Valid {
+			ordered = append(ordered, id)
+		}
+	}
+	require.NoError(t, rows.Err())
+	require.Greater(t, len(ordered), 1)
+
+	resp, model := callAPIHandler[StopEntryResponse](t, api,
+		"/api/where/stop/"+utils.FormCombinedID(testdata.Raba.ID, stopID)+".json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	got := make([]string, 0, len(model.Data.References.Routes))
+	for _, route := range model.Data.References.Routes {
+		got = append(got, route.ID)
+	}
+	assert.ElementsMatch(t, all, got)
+	// Routes with a route_sort_order come first, in that order.
+	assert.Equal(t, ordered, got[:len(ordered)])
+}
+
 func TestSendNull(t *testing.T) {
 	api := createTestApi(t)
 	defer api.Shutdown()
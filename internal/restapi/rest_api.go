@@ -1,23 +1,208 @@
 package restapi
 
 import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+	"maglev.onebusaway.org/internal/apikeys"
 	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/portal"
+	"maglev.onebusaway.org/internal/ratelimit"
+	"maglev.onebusaway.org/internal/scheduler"
 )
 
 type RestAPI struct {
 	*app.Application
 	rateLimiter *RateLimitMiddleware
+	loadShedder *LoadShedder
+	sloTracker  *SLOTracker
+	// usage counts requests per API key; nil without a GTFS database.
+	usage     *UsageTracker
+	canary    *Canary
+	tileCache *vectorTileCache
+	// responseCache holds static endpoint responses; see cachedStatic.
+	responseCache *responseCache
+	// scheduler runs the periodic maintenance jobs; nil without a GTFS
+	// database.
+	scheduler *scheduler.Scheduler
+	// keyStore holds the API keys issued by an admin; nil without a GTFS
+	// database.
+	keyStore *apikeys.Store
+	// portal issues self-service API keys; nil unless the developer portal
+	// is enabled.
+	portal *portal.Service
+	// suppressed holds the stops and routes hidden from public responses; see
+	// suppression.
+	suppressed atomic.Pointer[suppressionList]
+	// regions maps agencies to the configured regions; nil without any. See
+	// RegionMiddleware.
+	regions *regionRouter
+	// datasetViews serves requests for earlier datasets; see datasetView.
+	datasetViews datasetViews
+	// flagStopRoutes caches the routes with continuous stopping; see
+	// markFlagStops.
+	flagStopRoutes flagStopRoutes
+	// requestGroup coalesces identical concurrent requests; see coalesced.
+	requestGroup singleflight.Group
+	// auditMu serializes audited admin mutations; see audited.
+	auditMu sync.Mutex
+	// openAPIOnce builds openAPIJSON, the encoded OpenAPISpec, on the first
+	// request for it; the routes do not change while the server runs.
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+	openAPIErr  error
+
+	// lifecycleMu guards shutDown against the Start methods, which do
+	// nothing once Shutdown has run.
+	lifecycleMu sync.Mutex
+	shutDown    bool
+	// removeConfigListener unregisters the API from configuration reloads.
+	removeConfigListener func()
 }
 
 // NewRestAPI creates a new RestAPI instance with initialized rate limiter
 func NewRestAPI(app *app.Application) *RestAPI {
-	return &RestAPI{
-		Application: app,
-		rateLimiter: NewRateLimitMiddleware(app.Config.RateLimit, time.Second, app.Config.ExemptApiKeys),
+	rateLimiter := NewRateLimitMiddleware(app.Config.RateLimit, time.Second, app.Config.ExemptApiKeys)
+	rateLimiter.SetExemptIPRanges(app.Config.ExemptIPRanges)
+	rateLimiter.SetBackend(newRateLimitBackend(app))
+
+	api := &RestAPI{
+		Application:   app,
+		rateLimiter:   rateLimiter,
+		loadShedder:   NewLoadShedder(app.Config.LoadShedding, app.Clock),
+		sloTracker:    NewSLOTracker(app.Config.SLO, app.Metrics),
+		usage:         newUsageTracker(app),
+		tileCache:     newVectorTileCache(maxCachedVectorTiles),
+		responseCache: newResponseCache(maxCachedResponses),
+		keyStore:      newKeyStore(app),
+		portal:        newPortal(app),
+		regions:       newRegionRouter(app.Config.Regions),
+	}
+	jobs, err := newScheduler(api)
+	if err != nil && app.Logger != nil {
+		app.Logger.Error("failed to set up scheduled jobs; scheduled jobs disabled", "error", err)
+	}
+	api.scheduler = jobs
+	api.suppressed.Store(newSuppressionList(app.Config.Suppression))
+	api.removeConfigListener = app.OnConfigChange(func(cfg appconf.Config) {
+		rateLimiter.Reconfigure(cfg.RateLimit, cfg.ExemptApiKeys, cfg.ExemptIPRanges)
+		api.suppressed.Store(newSuppressionList(cfg.Suppression))
+	})
+	return api
+}
+
+// newRateLimitBackend creates the configured store of the global rate limit
+// bucket, falling back to this process's memory when Redis cannot be set up.
+func newRateLimitBackend(app *app.Application) ratelimit.Backend {
+	cfg := app.Config.RateLimitBackend
+	if cfg.Type != appconf.RateLimitBackendRedis {
+		return ratelimit.NewMemory()
+	}
+	backend, err := ratelimit.NewRedis(cfg.RedisURL, cfg.RedisKey)
+	if err != nil {
+		if app.Logger != nil {
+			app.Logger.Error("failed to set up Redis rate limit backend; limiting in memory", "error", err)
+		}
+		return ratelimit.NewMemory()
+	}
+	return backend
+}
+
+// newKeyStore creates the store of admin-issued API keys, which are kept in
+// the GTFS database. Without one only the configured ApiKeys are accepted.
+func newKeyStore(app *app.Application) *apikeys.Store {
+	if app.GtfsManager == nil || app.GtfsManager.GtfsDB == nil {
+		return nil
+	}
+	return apikeys.New(app.GtfsManager.GtfsDB.Queries, app.Clock, app.Logger)
+}
+
+// newPortal creates the developer portal service when it is enabled. Portal
+// keys are stored in the GTFS database, so the portal stays disabled without
+// one.
+func newPortal(app *app.Application) *portal.Service {
+	cfg := app.Config.Portal
+	if !cfg.Enabled {
+		return nil
+	}
+	if app.GtfsManager == nil || app.GtfsManager.GtfsDB == nil {
+		if app.Logger != nil {
+			app.Logger.Warn("developer portal enabled but no GTFS database is available; portal disabled")
+		}
+		return nil
 	}
+	return portal.New(cfg, app.GtfsManager.GtfsDB.Queries, portal.NewMailer(cfg.SMTP, app.Logger), app.Clock, app.Logger)
 }
 
-// Shutdown gracefully stops the RestAPI resources
-func (api *RestAPI) Shutdown() {}
+// StartCanary begins probing handler with the configured canary requests. It
+// is a no-op when no canary stop or route is configured or when there is no
+// API key for the probes to use.
+func (api *RestAPI) StartCanary(handler http.Handler) {
+	if !api.Config.Canary.Enabled() {
+		return
+	}
+	api.lifecycleMu.Lock()
+	defer api.lifecycleMu.Unlock()
+	if api.shutDown || api.canary != nil {
+		return
+	}
+	apiKey := canaryAPIKey(api.Config)
+	if apiKey == "" {
+		api.Logger.Warn("canary configured but no API key is available; canary disabled")
+		return
+	}
+	api.canary = NewCanary(api.Config.Canary, handler, apiKey, api.Metrics, api.Logger)
+	api.canary.Start()
+}
+
+// canaryAPIKey prefers a rate-limit exempt key so the probes never consume a
+// real client's quota.
+func canaryAPIKey(cfg appconf.Config) string {
+	if len(cfg.ExemptApiKeys) > 0 {
+		return cfg.ExemptApiKeys[0]
+	}
+	if len(cfg.ApiKeys) > 0 {
+		return cfg.ApiKeys[0]
+	}
+	return ""
+}
+
+// Shutdown stops everything the RestAPI started: the scheduled jobs, the
+// canary and its configuration listener. It closes the rate limit backend and
+// saves the buffered API key usage. Calls after the first do nothing. The
+// GTFS manager belongs to the application and is shut down with it.
+func (api *RestAPI) Shutdown() {
+	api.lifecycleMu.Lock()
+	defer api.lifecycleMu.Unlock()
+	if api.shutDown {
+		return
+	}
+	api.shutDown = true
+
+	if api.removeConfigListener != nil {
+		api.removeConfigListener()
+	}
+	if api.scheduler != nil {
+		api.scheduler.Shutdown()
+	}
+	if api.canary != nil {
+		api.canary.Shutdown()
+	}
+	if api.rateLimiter != nil {
+		if err := api.rateLimiter.Close(); err != nil && api.Logger != nil {
+			api.Logger.Warn("failed to close rate limit backend", "error", err)
+		}
+	}
+	if api.usage != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), usageFlushTimeout)
+		defer cancel()
+		if err := api.usage.Flush(ctx); err != nil && api.Logger != nil {
+			api.Logger.Warn("failed to save API key usage", "error", err)
+		}
+	}
+}
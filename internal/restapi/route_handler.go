@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/utils"
 )
@@ -32,31 +33,43 @@ func (api *RestAPI) routeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	routeData := models.NewRoute(
-		utils.FormCombinedID(agencyID, route.ID),
-		agencyID,
-		route.ShortName.String,
-		route.LongName.String,
-		route.Desc.String,
-		models.RouteType(route.Type),
-		route.Url.String,
-		route.Color.String,
-		route.TextColor.String)
+	routeData := utils.RouteFromDatabase(agencyID, route)
 
-	references := models.NewEmptyReferences()
-
-	includeReferences := ShouldIncludeReferences(r)
+	bookingRules, err := api.GtfsManager.GtfsDB.Queries.GetBookingRulesForRoute(ctx, route.ID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	routeData.BookingRules = models.NewBookingRulesFromDB(bookingRules)
 
-	if includeReferences {
-		agency, err := api.GtfsManager.GtfsDB.Queries.GetAgency(ctx, agencyID)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				api.sendNotFound(w, r)
-				return
-			}
-			api.serverErrorResponse(w, r, err)
+	agency, err := api.GtfsManager.GtfsDB.Queries.GetAgency(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.sendNotFound(w, r)
 			return
 		}
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	routeData.ServiceHours, err = api.currentServiceHours(ctx, loc, func(serviceIDs []string) ([]gtfsdb.RouteServiceSpan, error) {
+		return api.GtfsManager.GtfsDB.Queries.GetRouteServiceSpans(ctx, gtfsdb.GetRouteServiceSpansParams{
+			RouteID:    route.ID,
+			ServiceIds: serviceIDs,
+		})
+	})
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	references := models.NewEmptyReferences()
+
+	if ShouldIncludeReferences(r) {
 		// Use the existing helper to map the database row to the model
 		references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&agency))
 	}
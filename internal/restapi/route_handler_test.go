@@ -3,10 +3,12 @@ package restapi
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/OneBusAway/go-gtfs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/restapi/testdata"
 	"maglev.onebusaway.org/internal/utils"
@@ -172,3 +174,40 @@ func TestRouteHandler_IncludeReferencesDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestRouteHandler_FlexBookingRules(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	_, routeID, err := utils.ExtractAgencyIDAndCodeID(testdata.Route1.ID)
+	require.NoError(t, err)
+	insertTestFlexService(t, api, routeID, "test_flex_stop")
+
+	resp, model := callAPIHandler[RouteEntryResponse](t, api, routeURL(testdata.Route1.ID))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, model.Data.Entry.BookingRules, 1)
+	assert.Equal(t, "test_flex_rule", model.Data.Entry.BookingRules[0].ID)
+	assert.Equal(t, "555-0100", model.Data.Entry.BookingRules[0].PhoneNumber)
+}
+
+func TestRouteHandler_ServiceHours(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[RouteEntryResponse](t, api, routeURL(testdata.Route1.ID))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	hours := model.Data.Entry.ServiceHours
+	require.NotNil(t, hours, "Route 1 runs on weekdays")
+
+	loc := arrivalsTestClock.Location()
+	serviceMidnight := time.Date(2025, 6, 13, 0, 0, 0, 0, loc)
+	assert.Equal(t, serviceMidnight.UnixMilli(), hours.ServiceDate)
+	assert.Greater(t, hours.FirstDeparture, hours.ServiceDate)
+	assert.Greater(t, hours.LastDeparture, hours.FirstDeparture)
+	assert.Less(t, hours.LastDeparture, serviceMidnight.Add(30*time.Hour).UnixMilli())
+
+	api = createTestApiWithClock(t, clock.NewMockClock(time.Date(2040, 1, 6, 12, 0, 0, 0, loc)))
+	defer api.Shutdown()
+	_, model = callAPIHandler[RouteEntryResponse](t, api, routeURL(testdata.Route1.ID))
+	assert.Nil(t, model.Data.Entry.ServiceHours, "no service after the feed ends")
+}
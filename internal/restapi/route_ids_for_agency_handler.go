@@ -37,5 +37,6 @@ func (api *RestAPI) routeIDsForAgencyHandler(w http.ResponseWriter, r *http.Requ
 		response = append(response, utils.FormCombinedID(id, routeID))
 	}
 
+	response = api.suppression().filterRouteIDs(response)
 	api.sendResponse(w, r, models.NewListResponse(response, *models.NewEmptyReferences(), false, api.Clock))
 }
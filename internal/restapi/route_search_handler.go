@@ -9,7 +9,8 @@ import (
 )
 
 // routeSearchHandler searches for routes matching a user-provided query string,
-// with optional geographic bounds filtering via lat, lon, and radius parameters.
+// ranked by text relevance and, when lat and lon are given, by the distance to
+// the closest stop each route serves.
 func (api *RestAPI) routeSearchHandler(w http.ResponseWriter, r *http.Request) {
 	queryParams := r.URL.Query()
 	includeReferences := ShouldIncludeReferences(r)
@@ -48,6 +49,7 @@ func (api *RestAPI) routeSearchHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	near, fieldErrors := api.parseSearchLocation(r, fieldErrors)
 	if len(fieldErrors) > 0 {
 		api.validationErrorResponse(w, r, fieldErrors)
 		return
@@ -59,7 +61,7 @@ func (api *RestAPI) routeSearchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	routes, err := api.GtfsManager.SearchRoutes(ctx, sanitizedInput, maxCount)
+	routes, err := api.GtfsManager.SearchRoutesNear(ctx, sanitizedInput, maxCount, near)
 	if err != nil {
 		api.serverErrorResponse(w, r, err)
 		return
@@ -75,41 +77,7 @@ func (api *RestAPI) routeSearchHandler(w http.ResponseWriter, r *http.Request) {
 
 		agencyIDs[routeRow.AgencyID] = true
 
-		shortName := ""
-		if routeRow.ShortName.Valid {
-			shortName = routeRow.ShortName.String
-		}
-		longName := ""
-		if routeRow.LongName.Valid {
-			longName = routeRow.LongName.String
-		}
-		desc := ""
-		if routeRow.Desc.Valid {
-			desc = routeRow.Desc.String
-		}
-		url := ""
-		if routeRow.Url.Valid {
-			url = routeRow.Url.String
-		}
-		color := ""
-		if routeRow.Color.Valid {
-			color = routeRow.Color.String
-		}
-		textColor := ""
-		if routeRow.TextColor.Valid {
-			textColor = routeRow.TextColor.String
-		}
-
-		results = append(results, models.NewRoute(
-			utils.FormCombinedID(routeRow.AgencyID, routeRow.ID),
-			routeRow.AgencyID,
-			shortName,
-			longName,
-			desc,
-			models.RouteType(routeRow.Type),
-			url,
-			color,
-			textColor))
+		results = append(results, utils.RouteFromDatabase(routeRow.AgencyID, routeRow))
 	}
 
 	references := models.NewEmptyReferences()
@@ -3,6 +3,7 @@ package restapi
 import (
 	"net/http"
 
+	"maglev.onebusaway.org/internal/gtfs"
 	"maglev.onebusaway.org/internal/models"
 )
 
@@ -21,29 +22,61 @@ func rateLimitAndValidateAPIKey(api *RestAPI, finalHandler handlerFunc) http.Han
 		rateLimitedHandler = finalHandlerHttp
 	}
 
+	shedHandler := api.withLoadShedding(rateLimitedHandler)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// First validate API key
-		if api.RequestHasInvalidAPIKey(r) {
-			api.invalidAPIKeyResponse(w)
+		// First validate API key. Keys issued by an admin or the developer
+		// portal are not configured and carry their own per-key limit on top
+		// of the shared one.
+		if api.RequestHasInvalidAPIKey(r) && !api.allowIssuedKey(w, r) {
+			return
+		}
+		if !api.allowRegion(w, r) {
 			return
 		}
-		// Then apply rate limiting
-		rateLimitedHandler.ServeHTTP(w, r)
+		api.recordUsage(r)
+		// Then shed load and apply rate limiting
+		shedHandler.ServeHTTP(w, r)
 	})
 }
 
+// rateLimited applies rate limiting and load shedding without requiring an
+// API key, for the developer portal endpoints that exist to obtain one.
+func rateLimited(api *RestAPI, finalHandler handlerFunc) http.Handler {
+	var handler http.Handler = http.HandlerFunc(finalHandler)
+	if api.rateLimiter != nil {
+		handler = api.rateLimiter.Handler()(handler)
+	}
+	return api.withLoadShedding(handler)
+}
+
+// withLoadShedding wraps next with the load shedder when one is configured.
+func (api *RestAPI) withLoadShedding(next http.Handler) http.Handler {
+	if api.loadShedder == nil {
+		return next
+	}
+	return api.loadShedder.Handler(next)
+}
+
+// SLOMiddleware records API requests against the service level objectives
+// reported by slo-report.json.
+func (api *RestAPI) SLOMiddleware(next http.Handler) http.Handler {
+	if api.sloTracker == nil {
+		return next
+	}
+	return api.sloTracker.Handler(next)
+}
+
 // etagStatic applies ETag middleware at the innermost handler level.
 // By using an unnamed function type, Go allows this to be passed seamlessly into
-// rateLimitAndValidateAPIKey (which expects handlerFunc).
+// rateLimitAndValidateAPIKey (which expects handlerFunc). handler is pinned
+// to one dataset (see pinnedDataset).
 func etagStatic(api *RestAPI, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	getETagFunc := func(r *http.Request) string {
-		if api.GtfsManager != nil {
-			return api.GtfsManager.GetSystemETag(r.Context())
-		}
-		return ""
+		return api.staticETag(r.Context())
 	}
 
-	wrapped := ETagMiddleware(getETagFunc)(http.HandlerFunc(handler))
+	wrapped := ETagMiddleware(getETagFunc)(http.HandlerFunc(pinnedDataset(api, handler)))
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Call ServeHTTP cleanly on our pre-built handler
@@ -64,47 +97,122 @@ func rateLimitAndValidateProtectedAPIKey(api *RestAPI, finalHandler handlerFunc)
 	}
 
 	// Auth check outermost, matching rateLimitAndValidateAPIKey pattern
-	return api.validateProtectedAPIKey(rateLimitedHandler)
+	return api.validateProtectedAPIKey(api.withLoadShedding(rateLimitedHandler))
+}
+
+// Router is the part of *http.ServeMux that SetRoutes registers endpoints
+// with. The OpenAPI spec is built by registering them with a recorder.
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
 }
 
-// SetRoutes registers all API endpoints with the provided mux
-func (api *RestAPI) SetRoutes(mux *http.ServeMux) {
-	// Health check endpoint - no authentication required
+// SetRoutes registers all API endpoints with the provided mux. Every route
+// needs an entry in openAPIOperations.
+//
+// Every endpoint that answers from the static dataset with more than one
+// query, and so may build its list, entry or references from two datasets
+// when a reload commits meanwhile, is pinned to one dataset: those served
+// through cachedStatic, etagStatic and etagStaticWithAlerts by them, the
+// others by pinnedDataset, and the stream by each event. Left unpinned are
+// the endpoints that write or only read the tables they own (problem
+// reports, API keys, the developer portal and the admin endpoints), those
+// that do not read the dataset (current-time, config, usage and the GTFS-RT
+// feeds), and metadata and schedule-deviation-history-for-trip, which make
+// one query.
+func (api *RestAPI) SetRoutes(mux Router) {
+	// Health check endpoints - no authentication required
 	mux.HandleFunc("GET /healthz", api.healthHandler)
+	mux.HandleFunc("GET /readyz", api.readyHandler)
+
+	// API description and its Swagger UI - no authentication required
+	mux.Handle("GET /api/openapi.json", CacheControlMiddleware(models.CacheDurationLong, http.HandlerFunc(api.openAPIHandler)))
+	mux.HandleFunc("GET /api/docs", api.openAPIDocsHandler)
 
 	// --- Metadata Endpoint (Special v2 exception) ---
 	mux.Handle("GET /api/v2/metadata.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.metadataHandler)))
 
 	// --- Routes without ID validation ---
-	mux.Handle("GET /api/where/agencies-with-coverage.json", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.agenciesWithCoverageHandler))))
+	mux.Handle("GET /api/where/agencies-with-coverage.json", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStatic(api, (*RestAPI).agenciesWithCoverageHandler))))
 	mux.Handle("GET /api/where/search/stop.json", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.searchStopsHandler))))
 	mux.Handle("GET /api/where/search/route.json", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.routeSearchHandler))))
 
 	// Non-static endpoints (no ETag)
 	mux.Handle("GET /api/where/current-time.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.currentTimeHandler)))
-	mux.Handle("GET /api/where/stops-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.stopsForLocationHandler)))
-	mux.Handle("GET /api/where/routes-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.routesForLocationHandler)))
-	mux.Handle("GET /api/where/trips-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.tripsForLocationHandler)))
+	mux.Handle("GET /api/where/stops-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.stopsForLocationHandler))))
+	mux.Handle("GET /api/where/stop-clusters.json", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.stopClustersHandler))))
+	mux.Handle("GET /api/where/routes-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.routesForLocationHandler))))
+	mux.Handle("GET /api/where/arrivals-and-departures-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, longPolled(api, pinnedDataset(api, protobufFormat("ArrivalsAndDeparturesForLocationResponse", api.arrivalsAndDeparturesForLocationHandler))))))
+	mux.Handle("GET /api/where/sms.txt", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.smsHandler))))
+	mux.Handle("GET /api/where/trips-for-location.json", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, protobufFormat("TripsForLocationResponse", api.tripsForLocationHandler)))))
 	mux.Handle("GET /api/where/config.json", rateLimitAndValidateAPIKey(api, api.configHandler))
+	mux.Handle("GET /api/where/usage.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateAPIKey(api, api.usageHandler)))
+	mux.Handle("GET /api/where/static-reload-status.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.staticReloadStatusHandler)))
+	mux.Handle("GET /api/where/slo-report.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.sloReportHandler)))
+	mux.Handle("GET /api/where/approve-static-reload.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("approve-static-reload", api.approveStaticReloadHandler))))
+	mux.Handle("GET /api/where/scheduled-jobs.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.scheduledJobsHandler)))
+	mux.Handle("GET /api/where/run-scheduled-job.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("run-scheduled-job", api.runScheduledJobHandler))))
+	mux.Handle("GET /api/where/reload-config.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("reload-config", api.reloadConfigHandler))))
+	if api.usage != nil {
+		mux.Handle("GET /api/where/api-key-usage.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.apiKeyUsageHandler)))
+	}
+	mux.Handle("GET /api/where/admin-audit-log.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.adminAuditLogHandler)))
+
+	// API keys issued by an admin, when a GTFS database stores them
+	if api.keyStore != nil {
+		mux.Handle("GET /api/where/api-keys.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.apiKeysHandler)))
+		mux.Handle("GET /api/where/create-api-key.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("create-api-key", api.createAPIKeyHandler))))
+		mux.Handle("GET /api/where/revoke-api-key.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("revoke-api-key", api.revokeAPIKeyHandler))))
+	}
+
+	// Developer portal: self-service key signup and quota approval, when enabled
+	if api.portal != nil {
+		mux.Handle("GET /api/where/developer-signup.json", CacheControlMiddleware(models.CacheDurationNone, rateLimited(api, api.developerSignupHandler)))
+		mux.Handle("GET /api/where/developer-verify.json", CacheControlMiddleware(models.CacheDurationNone, rateLimited(api, api.developerVerifyHandler)))
+		mux.Handle("GET /api/where/developer-quota-request.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateAPIKey(api, api.developerQuotaRequestHandler)))
+		mux.Handle("GET /api/where/developer-quota-requests.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.developerQuotaRequestsHandler)))
+		mux.Handle("GET /api/where/approve-developer-quota.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("approve-developer-quota", api.decideDeveloperQuotaHandler(true)))))
+		mux.Handle("GET /api/where/deny-developer-quota.json", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.audited("deny-developer-quota", api.decideDeveloperQuotaHandler(false)))))
+	}
+
+	// SIRI stop and vehicle monitoring for consumers that do not speak the OBA API
+	mux.Handle("GET /siri/stop-monitoring", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.siriStopMonitoringHandler))))
+	mux.Handle("GET /siri/vehicle-monitoring", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.siriVehicleMonitoringHandler))))
+
+	// The merged realtime state republished as GTFS-RT
+	mux.Handle("GET /gtfs-rt/trip-updates.pb", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.gtfsRTTripUpdatesHandler)))
+	mux.Handle("GET /gtfs-rt/vehicle-positions.pb", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.gtfsRTVehiclePositionsHandler)))
+	mux.Handle("GET "+streamPath, rateLimitAndValidateAPIKey(api, api.streamHandler))
+
+	// Vector tiles of route shapes and stops for web maps
+	mux.Handle("GET /tiles/{z}/{x}/{y}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.vectorTileHandler))))
 
 	// --- Routes with simple ID validation (agency IDs) ---
-	mux.Handle("GET /api/where/agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.agencyHandler))))
-	mux.Handle("GET /api/where/routes-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.routesForAgencyHandler))))
-	mux.Handle("GET /api/where/stop-ids-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.stopIDsForAgencyHandler))))
-	mux.Handle("GET /api/where/stops-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.stopsForAgencyHandler))))
-	mux.Handle("GET /api/where/route-ids-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.routeIDsForAgencyHandler))))
+	mux.Handle("GET /api/where/agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStaticHourly(api, (*RestAPI).agencyHandler))))
+	mux.Handle("GET /api/where/routes-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStatic(api, (*RestAPI).routesForAgencyHandler))))
+	mux.Handle("GET /api/where/stop-ids-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStatic(api, (*RestAPI).stopIDsForAgencyHandler))))
+	mux.Handle("GET /api/where/stops-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStatic(api, (*RestAPI).stopsForAgencyHandler))))
+	mux.Handle("GET /api/where/route-ids-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStatic(api, (*RestAPI).routeIDsForAgencyHandler))))
+	mux.Handle("GET /api/where/service-exceptions-for-agency/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStaticHourly(api, (*RestAPI).serviceExceptionsForAgencyHandler))))
 
 	// Real-time simple ID endpoints (no ETag)
-	mux.Handle("GET /api/where/vehicles-for-agency/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.vehiclesForAgencyHandler)))
+	mux.Handle("GET /api/where/vehicles-for-agency/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.vehiclesForAgencyHandler))))
+	mux.Handle("GET /api/where/block-assignments-for-agency/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.blockAssignmentsForAgencyHandler))))
 
 	// --- Routes with combined ID validation (agency_id_code format) ---
+	// route and stop embed live service alerts, so they are never served from
+	// the response cache, are cached briefly, and their ETag covers the alerts.
 	mux.Handle("GET /api/where/trip/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.tripHandler))))
-	mux.Handle("GET /api/where/route/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.routeHandler))))
-	mux.Handle("GET /api/where/stop/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.stopHandler))))
-	mux.Handle("GET /api/where/shape/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.shapesHandler))))
-	mux.Handle("GET /api/where/stops-for-route/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.stopsForRouteHandler))))
-	mux.Handle("GET /api/where/schedule-for-stop/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.scheduleForStopHandler))))
-	mux.Handle("GET /api/where/schedule-for-route/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.scheduleForRouteHandler))))
+	mux.Handle("GET /api/where/route/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, unlessRouteSuppressed(api, etagStaticWithAlerts(api, (*gtfs.Manager).GetAlertsForRoute, api.routeHandler)))))
+	mux.Handle("GET /api/where/stop/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, unlessStopSuppressed(api, etagStaticWithAlerts(api, (*gtfs.Manager).GetAlertsForStop, api.stopHandler)))))
+	mux.Handle("GET /api/where/amenities-for-stop/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessStopSuppressed(api, cachedStatic(api, (*RestAPI).amenitiesForStopHandler)))))
+	mux.Handle("GET /api/where/shape/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, cachedStatic(api, (*RestAPI).shapesHandler))))
+	mux.Handle("GET /api/where/stops-for-route/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessRouteSuppressed(api, cachedStatic(api, (*RestAPI).stopsForRouteHandler)))))
+	mux.Handle("GET /api/where/fares-for-route/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessRouteSuppressed(api, cachedStatic(api, (*RestAPI).faresForRouteHandler)))))
+	mux.Handle("GET /api/where/fares-for-leg/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessRouteSuppressed(api, cachedStatic(api, (*RestAPI).faresForLegHandler)))))
+	mux.Handle("GET /api/where/transfers-for-stop/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessStopSuppressed(api, cachedStatic(api, (*RestAPI).transfersForStopHandler)))))
+	mux.Handle("GET /api/where/schedule-for-stop/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessStopSuppressed(api, etagStatic(api, api.scheduleForStopHandler)))))
+	mux.Handle("GET /api/where/schedule-for-route/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, unlessRouteSuppressed(api, etagStatic(api, api.scheduleForRouteHandler)))))
 	mux.Handle("GET /api/where/block/{id}", CacheControlMiddleware(models.CacheDurationLong, rateLimitAndValidateAPIKey(api, etagStatic(api, api.blockHandler))))
 
 	// Real-time or transactional combined ID endpoints (no ETag)
@@ -112,9 +220,11 @@ func (api *RestAPI) SetRoutes(mux *http.ServeMux) {
 	mux.Handle("GET /api/where/report-problem-with-stop/{id}", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateAPIKey(api, api.reportProblemWithStopHandler)))
 	mux.Handle("GET /api/where/problem-reports-for-trip/{id}", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.problemReportsForTripHandler)))
 	mux.Handle("GET /api/where/problem-reports-for-stop/{id}", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.problemReportsForStopHandler)))
-	mux.Handle("GET /api/where/trip-details/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.tripDetailsHandler)))
-	mux.Handle("GET /api/where/trip-for-vehicle/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.tripForVehicleHandler)))
-	mux.Handle("GET /api/where/arrival-and-departure-for-stop/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.arrivalAndDepartureForStopHandler)))
-	mux.Handle("GET /api/where/trips-for-route/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.tripsForRouteHandler)))
-	mux.Handle("GET /api/where/arrivals-and-departures-for-stop/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.arrivalsAndDeparturesForStopHandler)))
+	mux.Handle("GET /api/where/rate-limit-status/{id}", CacheControlMiddleware(models.CacheDurationNone, rateLimitAndValidateProtectedAPIKey(api, api.rateLimitStatusHandler)))
+	mux.Handle("GET /api/where/trip-details/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.tripDetailsHandler))))
+	mux.Handle("GET /api/where/schedule-deviation-history-for-trip/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, api.scheduleDeviationHistoryHandler)))
+	mux.Handle("GET /api/where/trip-for-vehicle/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, api.tripForVehicleHandler))))
+	mux.Handle("GET /api/where/arrival-and-departure-for-stop/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, longPolled(api, pinnedDataset(api, unlessStopSuppressed(api, coalesced(api, api.arrivalAndDepartureForStopHandler)))))))
+	mux.Handle("GET /api/where/trips-for-route/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, pinnedDataset(api, unlessRouteSuppressed(api, api.tripsForRouteHandler)))))
+	mux.Handle("GET /api/where/arrivals-and-departures-for-stop/{id}", CacheControlMiddleware(models.CacheDurationShort, rateLimitAndValidateAPIKey(api, longPolled(api, pinnedDataset(api, protobufFormat("ArrivalsAndDeparturesForStopResponse", unlessStopSuppressed(api, coalesced(api, api.arrivalsAndDeparturesForStopHandler))))))))
 }
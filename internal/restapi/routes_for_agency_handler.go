@@ -3,8 +3,8 @@ package restapi
 import (
 	"net/http"
 
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
-	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
 )
 
@@ -35,16 +35,18 @@ func (api *RestAPI) routesForAgencyHandler(w http.ResponseWriter, r *http.Reques
 	routesList := make([]models.Route, 0, len(routesForAgency))
 
 	for _, route := range routesForAgency {
-		routesList = append(routesList, models.NewRoute(
-			utils.FormCombinedID(agency.ID, route.ID),
-			agency.ID,
-			nulls.StringOrEmpty(route.ShortName),
-			nulls.StringOrEmpty(route.LongName),
-			nulls.StringOrEmpty(route.Desc),
-			models.RouteType(route.Type),
-			nulls.StringOrEmpty(route.Url),
-			nulls.StringOrEmpty(route.Color),
-			nulls.StringOrEmpty(route.TextColor)))
+		routesList = append(routesList, utils.RouteFromDatabase(agency.ID, gtfsdb.Route{
+			ID:        route.ID,
+			AgencyID:  agency.ID,
+			ShortName: route.ShortName,
+			LongName:  route.LongName,
+			Desc:      route.Desc,
+			Type:      route.Type,
+			Url:       route.Url,
+			Color:     route.Color,
+			TextColor: route.TextColor,
+			SortOrder: route.SortOrder,
+		}))
 	}
 
 	references := models.NewEmptyReferences()
@@ -7,8 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
-	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
 )
 
@@ -18,8 +18,10 @@ func (api *RestAPI) routesForLocationHandler(w http.ResponseWriter, r *http.Requ
 	queryParams := r.URL.Query()
 
 	var fieldErrors map[string][]string
+	search := api.searchConfig()
 	loc, fieldErrors := api.parseLocationParams(r, fieldErrors)
-	maxCount, fieldErrors := utils.ParseMaxCount(queryParams, models.DefaultMaxCountForRoutes, fieldErrors)
+	maxCount, fieldErrors := utils.ParseMaxCountWithLimit(queryParams, search.DefaultMaxCountRoutes, search.MaxCount, fieldErrors)
+	routeTypes, fieldErrors := parseRouteTypeFilter(queryParams, fieldErrors)
 
 	if len(fieldErrors) > 0 {
 		api.validationErrorResponse(w, r, fieldErrors)
@@ -36,14 +38,18 @@ func (api *RestAPI) routesForLocationHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	if loc.Radius == 0 {
-		loc.Radius = models.DefaultSearchRadiusInMeters
+		loc.Radius = search.DefaultRadius
 		if query != "" {
-			loc.Radius = models.QuerySearchRadiusInMeters
+			loc.Radius = search.QueryRadius
 		}
 	}
+	loc.Radius = min(loc.Radius, search.MaxRadius)
 
 	ctx := r.Context()
-	routes, isLimitExceeded := api.GtfsManager.GetRoutesForLocation(ctx, loc, sanitizedQuery, maxCount, time.Time{})
+	routes, isLimitExceeded := api.GtfsManager.GetRoutesForLocation(ctx, loc, sanitizedQuery, maxCount, time.Time{}, routeTypes)
+	routes = slices.DeleteFunc(routes, func(route gtfsdb.Route) bool {
+		return !api.inRequestRegion(ctx, route.AgencyID)
+	})
 	if len(routes) == 0 {
 		references := models.NewEmptyReferences()
 		response := models.NewListResponseWithRange([]models.Route{}, *references, api.GtfsManager.CheckIfOutOfBounds(loc), api.Clock, false)
@@ -57,16 +63,7 @@ func (api *RestAPI) routesForLocationHandler(w http.ResponseWriter, r *http.Requ
 	for _, route := range routes {
 		agencyIDs[route.AgencyID] = true
 		routeIDs[route.ID] = true
-		results = append(results, models.NewRoute(
-			utils.FormCombinedID(route.AgencyID, route.ID),
-			route.AgencyID,
-			nulls.StringOrEmpty(route.ShortName),
-			nulls.StringOrEmpty(route.LongName),
-			nulls.StringOrEmpty(route.Desc),
-			models.RouteType(route.Type),
-			nulls.StringOrEmpty(route.Url),
-			nulls.StringOrEmpty(route.Color),
-			nulls.StringOrEmpty(route.TextColor)))
+		results = append(results, utils.RouteFromDatabase(route.AgencyID, route))
 	}
 
 	references := models.NewEmptyReferences()
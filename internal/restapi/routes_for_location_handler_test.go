@@ -41,6 +41,34 @@ func TestRoutesForLocationQuery(t *testing.T) {
 	assert.ElementsMatch(t, model.Data.References.Agencies, []models.AgencyReference{testdata.Raba})
 }
 
+func TestRoutesForLocationRouteTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		routeTypes string
+		wantStatus int
+		wantRoutes []models.Route
+	}{
+		{name: "matching bus type", routeTypes: "3", wantStatus: http.StatusOK, wantRoutes: []models.Route{testdata.Route19}},
+		{name: "multiple types including bus", routeTypes: "0,3", wantStatus: http.StatusOK, wantRoutes: []models.Route{testdata.Route19}},
+		{name: "no matching type", routeTypes: "0", wantStatus: http.StatusOK, wantRoutes: []models.Route{}},
+		{name: "extended bus type matches basic bus route", routeTypes: "700", wantStatus: http.StatusOK, wantRoutes: []models.Route{testdata.Route19}},
+		{name: "unknown GTFS code rejected", routeTypes: "9", wantStatus: http.StatusBadRequest},
+		{name: "non-numeric rejected", routeTypes: "bus", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := createTestApi(t)
+			resp, model := callAPIHandler[RoutesResponse](t, api, "/api/where/routes-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&routeTypes="+tt.routeTypes)
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus == http.StatusOK {
+				assert.ElementsMatch(t, tt.wantRoutes, model.Data.List)
+			}
+		})
+	}
+}
+
 func TestRoutesForLocationLatSpanAndLonSpan(t *testing.T) {
 	api := createTestApi(t)
 
@@ -12,10 +12,14 @@ func (api *RestAPI) SetupAPIRoutes() http.Handler {
 	// Register all API routes
 	api.SetRoutes(mux)
 
-	// Apply global middleware chain: freshness -> compression -> version -> expiry -> base routes
+	// Apply global middleware chain: freshness -> compression -> region -> protobuf -> xml -> slo -> version -> expiry -> base routes
 	var handler http.Handler = mux
 	handler = GtfsExpiryMiddleware(api.GtfsManager)(handler)
 	handler = api.VersionValidationMiddleware(handler)
+	handler = api.SLOMiddleware(handler)
+	handler = XMLFormatMiddleware(handler)
+	handler = ProtobufFormatMiddleware(handler)
+	handler = api.RegionMiddleware(handler)
 	handler = CompressionMiddleware(handler)
 	handler = api.FreshnessMiddleware(handler)
 
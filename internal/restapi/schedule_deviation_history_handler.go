@@ -0,0 +1,44 @@
+package restapi
+
+import (
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// scheduleDeviationHistoryHandler returns the schedule deviations recently
+// sampled for a trip, oldest first. The list is empty for a trip without
+// realtime updates.
+func (api *RestAPI) scheduleDeviationHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	agencyID, id, ok := api.extractAndValidateAgencyCodeID(w, r)
+	if !ok {
+		return
+	}
+
+	if !api.GtfsManager.DeviationHistoryEnabled() {
+		api.sendError(w, r, http.StatusNotFound, "schedule deviation history is not enabled")
+		return
+	}
+
+	if _, err := api.GtfsManager.GtfsDB.Queries.GetTrip(r.Context(), id); err != nil {
+		api.sendNotFound(w, r)
+		return
+	}
+
+	history := api.GtfsManager.ScheduleDeviationHistory(id)
+	samples := make([]models.ScheduleDeviationSample, 0, len(history))
+	for _, sample := range history {
+		var vehicleID string
+		if sample.VehicleID != "" {
+			vehicleID = utils.FormCombinedID(agencyID, sample.VehicleID)
+		}
+		samples = append(samples, models.ScheduleDeviationSample{
+			Time:              sample.Time.UnixMilli(),
+			ScheduleDeviation: int(sample.Deviation.Seconds()),
+			VehicleID:         vehicleID,
+		})
+	}
+
+	api.sendResponse(w, r, models.NewListResponse(samples, *models.NewEmptyReferences(), false, api.Clock))
+}
@@ -0,0 +1,48 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type scheduleDeviationHistoryResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List []models.ScheduleDeviationSample `json:"list"`
+	} `json:"data"`
+}
+
+func TestScheduleDeviationHistoryForTrip(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 2, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	t.Cleanup(func() { api.GtfsManager.MockSampleScheduleDeviations(0, time.Time{}) })
+
+	_, _, tripID, _ := setupDelayPropTestData(t, api, 1)
+	endpoint := "/api/where/schedule-deviation-history-for-trip/dp-agency_" + tripID + ".json?key=TEST"
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, endpoint)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "sampling is disabled by default")
+
+	api.GtfsManager.MockAddVehicle("v1", tripID, "dp-route")
+	delay := 90 * time.Second
+	api.GtfsManager.MockAddTripUpdate(tripID, &delay, nil)
+	sampledAt := mockClock.Now()
+	api.GtfsManager.MockSampleScheduleDeviations(time.Minute, sampledAt)
+
+	resp, model := callAPIHandler[scheduleDeviationHistoryResponse](t, api, endpoint)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []models.ScheduleDeviationSample{
+		{Time: sampledAt.UnixMilli(), ScheduleDeviation: 90, VehicleID: "dp-agency_v1"},
+	}, model.Data.List)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/schedule-deviation-history-for-trip/dp-agency_nope.json?key=TEST")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -68,16 +68,7 @@ func (api *RestAPI) scheduleForRouteHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	agencyModel := models.AgencyReferenceFromDatabase(&agency)
-	routeModel := models.NewRoute(
-		utils.FormCombinedID(agencyID, route.ID),
-		route.AgencyID,
-		route.ShortName.String,
-		route.LongName.String,
-		route.Desc.String,
-		models.RouteType(route.Type),
-		route.Url.String,
-		route.Color.String,
-		route.TextColor.String)
+	routeModel := utils.RouteFromDatabase(agencyID, route)
 
 	serviceIDs, err := api.GtfsManager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, targetDate)
 	if err != nil {
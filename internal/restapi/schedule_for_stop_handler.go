@@ -299,16 +299,7 @@ func (api *RestAPI) fetchRouteRefs(ctx context.Context, agencyID string, routeID
 
 	for _, route := range fetchedRoutes {
 		combinedRouteID := utils.FormCombinedID(agencyID, route.ID)
-		routeRefs[combinedRouteID] = models.NewRoute(
-			combinedRouteID,
-			route.AgencyID,
-			route.ShortName.String,
-			route.LongName.String,
-			route.Desc.String,
-			models.RouteType(route.Type),
-			route.Url.String,
-			route.Color.String,
-			route.TextColor.String)
+		routeRefs[combinedRouteID] = utils.RouteFromDatabase(agencyID, route)
 	}
 
 	return routeRefs, nil
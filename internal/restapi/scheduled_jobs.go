@@ -0,0 +1,191 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/logging"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/scheduler"
+)
+
+// defaultScheduledJobs holds the schedule of each maintenance job unless
+// overridden by the scheduled-jobs config.
+var defaultScheduledJobs = map[string]appconf.JobConfig{
+	appconf.JobFeedReload:         {Interval: 24 * time.Hour, Jitter: 10 * time.Minute},
+	appconf.JobArchivePruning:     {Interval: 24 * time.Hour, Jitter: 30 * time.Minute, Retention: 90 * 24 * time.Hour},
+	appconf.JobOnTimePerformance:  {Interval: 5 * time.Minute, Jitter: 30 * time.Second},
+	appconf.JobStatsRecomputation: {Interval: 6 * time.Hour, Jitter: 30 * time.Minute},
+	appconf.JobAPIKeyUsageFlush:   {Interval: time.Minute, Jitter: 5 * time.Second},
+}
+
+// feedReloadTimeout matches the timeout of the manager's own periodic reload.
+const feedReloadTimeout = 5 * time.Minute
+
+// jobConfig returns the schedule of a maintenance job with the configured
+// overrides applied.
+func jobConfig(cfg appconf.Config, name string) appconf.JobConfig {
+	job := defaultScheduledJobs[name]
+	override := cfg.Jobs[name]
+	if override.Enabled != nil {
+		job.Enabled = override.Enabled
+	}
+	if override.Interval > 0 {
+		job.Interval = override.Interval
+	}
+	if override.Jitter > 0 {
+		job.Jitter = override.Jitter
+	}
+	if override.Retention > 0 {
+		job.Retention = override.Retention
+	}
+	return job
+}
+
+// newScheduler creates the scheduler of the maintenance jobs. Every job works
+// on the GTFS database, so there is no scheduler without one.
+func newScheduler(api *RestAPI) (*scheduler.Scheduler, error) {
+	if api.GtfsManager == nil || api.GtfsManager.GtfsDB == nil {
+		return nil, nil
+	}
+	manager := api.GtfsManager
+	s := scheduler.New(api.Clock, api.Logger)
+	for _, name := range appconf.JobNames {
+		cfg := jobConfig(api.Config, name)
+		job := scheduler.Job{
+			Name:     name,
+			Enabled:  cfg.Enabled == nil || *cfg.Enabled,
+			Interval: cfg.Interval,
+			Jitter:   cfg.Jitter,
+		}
+		switch name {
+		case appconf.JobFeedReload:
+			// A local feed is only read at startup.
+			job.Enabled = job.Enabled && manager.RefreshesStatic()
+			job.Timeout = feedReloadTimeout
+			job.NextDelay = func() time.Duration { return manager.StaticRefreshDelay(cfg.Interval) }
+			job.Run = func(ctx context.Context) error {
+				_, err := manager.ReloadStatic(ctx)
+				return err
+			}
+		case appconf.JobArchivePruning:
+			job.Run = func(ctx context.Context) error { return api.pruneArchives(ctx, cfg.Retention) }
+		case appconf.JobOnTimePerformance:
+			job.Run = func(ctx context.Context) error {
+				api.recordTripStartPerformance()
+				return nil
+			}
+		case appconf.JobStatsRecomputation:
+			job.Run = func(ctx context.Context) error {
+				_, err := manager.GtfsDB.DB.ExecContext(ctx, "PRAGMA optimize")
+				return err
+			}
+		case appconf.JobAPIKeyUsageFlush:
+			job.Run = api.usage.Flush
+		}
+		if err := s.Add(job); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// pruneArchives deletes problem reports and API key usage counts older than
+// retention and developer portal signups whose verification link expired that
+// long ago.
+func (api *RestAPI) pruneArchives(ctx context.Context, retention time.Duration) error {
+	queries := api.GtfsManager.GtfsDB.Queries
+	before := api.Clock.Now().Add(-retention).UnixMilli()
+
+	tripReports, err := queries.DeleteProblemReportsTripBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+	stopReports, err := queries.DeleteProblemReportsStopBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+	signups, err := queries.DeleteExpiredDeveloperAPIKeySignups(ctx, before)
+	if err != nil {
+		return err
+	}
+	usageCounts, err := queries.DeleteAPIKeyUsageBefore(ctx, api.Clock.Now().Add(-retention).UTC().Format(usageDayLayout))
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("pruned archived rows",
+		slog.Int64("trip_problem_reports", tripReports),
+		slog.Int64("stop_problem_reports", stopReports),
+		slog.Int64("expired_signups", signups),
+		slog.Int64("api_key_usage", usageCounts))
+	return nil
+}
+
+// recordTripStartPerformance exports how punctually trips have been starting.
+func (api *RestAPI) recordTripStartPerformance() {
+	performance := api.GtfsManager.TripStartPerformance()
+	if api.Metrics == nil {
+		return
+	}
+	api.Metrics.TripStarts.WithLabelValues("early").Set(float64(performance.Early))
+	api.Metrics.TripStarts.WithLabelValues("on_time").Set(float64(performance.OnTime))
+	api.Metrics.TripStarts.WithLabelValues("late").Set(float64(performance.Late))
+}
+
+// StartScheduler begins running the periodic maintenance jobs.
+func (api *RestAPI) StartScheduler() {
+	api.lifecycleMu.Lock()
+	defer api.lifecycleMu.Unlock()
+	if api.scheduler != nil && !api.shutDown {
+		api.scheduler.Start()
+	}
+}
+
+// scheduledJobsHandler reports the schedule and latest outcome of each
+// maintenance job.
+func (api *RestAPI) scheduledJobsHandler(w http.ResponseWriter, r *http.Request) {
+	var jobs []scheduler.JobStatus
+	if api.scheduler != nil {
+		jobs = api.scheduler.Status()
+	}
+	response := models.NewListResponse(jobs, *models.NewEmptyReferences(), false, api.Clock)
+	api.sendResponse(w, r, response)
+}
+
+// runScheduledJobHandler starts a maintenance job right away, even a
+// disabled one. The run happens in the background; its outcome is reported
+// by scheduled-jobs.
+func (api *RestAPI) runScheduledJobHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		api.validationErrorResponse(w, r, map[string][]string{"name": {"name is required"}})
+		return
+	}
+	if api.scheduler == nil {
+		api.sendError(w, r, http.StatusServiceUnavailable, "scheduled jobs are unavailable")
+		return
+	}
+	switch err := api.scheduler.Trigger(name); {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		api.sendError(w, r, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, scheduler.ErrJobRunning):
+		api.sendError(w, r, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	recordAuditChange(r, name, nil, map[string]string{"triggered": name})
+	for _, status := range api.scheduler.Status() {
+		if status.Name == name {
+			api.sendResponse(w, r, models.NewEntryResponse(status, *models.NewEmptyReferences(), api.Clock))
+			return
+		}
+	}
+}
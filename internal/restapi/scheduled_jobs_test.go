@@ -0,0 +1,114 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/scheduler"
+)
+
+type scheduledJobsResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List []scheduler.JobStatus `json:"list"`
+	} `json:"data"`
+}
+
+func scheduledJobStatus(t *testing.T, api *RestAPI, name string) scheduler.JobStatus {
+	t.Helper()
+	resp, jobs := callAPIHandler[scheduledJobsResponse](t, api, "/api/where/scheduled-jobs.json?key=PROTECTED-TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	for _, job := range jobs.Data.List {
+		if job.Name == name {
+			return job
+		}
+	}
+	t.Fatalf("no scheduled job %q", name)
+	return scheduler.JobStatus{}
+}
+
+func TestScheduledJobsHandler(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/scheduled-jobs.json?key=TEST")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, jobs := callAPIHandler[scheduledJobsResponse](t, api, "/api/where/scheduled-jobs.json?key=PROTECTED-TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	names := make([]string, 0, len(jobs.Data.List))
+	for _, job := range jobs.Data.List {
+		names = append(names, job.Name)
+	}
+	assert.Equal(t, appconf.JobNames, names)
+
+	feedReload := jobs.Data.List[0]
+	assert.False(t, feedReload.Enabled, "a local static feed is not reloaded")
+	archivePruning := jobs.Data.List[1]
+	assert.True(t, archivePruning.Enabled)
+	assert.Equal(t, int64(24*time.Hour/time.Second), archivePruning.IntervalSeconds)
+	assert.Equal(t, int64(30*time.Minute/time.Second), archivePruning.JitterSeconds)
+}
+
+func TestScheduledJobConfigOverrides(t *testing.T) {
+	disabled := false
+	cfg := appconf.Config{Jobs: map[string]appconf.JobConfig{
+		appconf.JobArchivePruning: {Enabled: &disabled, Interval: time.Hour, Retention: 7 * 24 * time.Hour},
+	}}
+
+	job := jobConfig(cfg, appconf.JobArchivePruning)
+	require.NotNil(t, job.Enabled)
+	assert.False(t, *job.Enabled)
+	assert.Equal(t, time.Hour, job.Interval)
+	assert.Equal(t, 30*time.Minute, job.Jitter, "unset fields keep the defaults")
+	assert.Equal(t, 7*24*time.Hour, job.Retention)
+
+	assert.Equal(t, defaultScheduledJobs[appconf.JobStatsRecomputation], jobConfig(cfg, appconf.JobStatsRecomputation))
+}
+
+func TestRunScheduledJobPrunesArchives(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	now := api.Clock.Now()
+	for _, createdAt := range []time.Time{now.AddDate(-1, 0, 0), now} {
+		require.NoError(t, queries.CreateProblemReportStop(ctx, gtfsdb.CreateProblemReportStopParams{
+			StopID:      "scheduled_job_test_stop",
+			Code:        nulls.String("stop_name_wrong"),
+			CreatedAt:   createdAt.UnixMilli(),
+			SubmittedAt: createdAt.UnixMilli(),
+		}))
+	}
+	t.Cleanup(func() {
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(),
+			`DELETE FROM problem_reports_stop WHERE stop_id = ?`, "scheduled_job_test_stop")
+	})
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/run-scheduled-job.json?key=PROTECTED-TEST")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/run-scheduled-job.json?key=PROTECTED-TEST&name=vacuum")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/run-scheduled-job.json?key=PROTECTED-TEST&name="+appconf.JobArchivePruning)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Eventually(t, func() bool {
+		return scheduledJobStatus(t, api, appconf.JobArchivePruning).Runs == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	status := scheduledJobStatus(t, api, appconf.JobArchivePruning)
+	assert.Empty(t, status.LastError)
+	assert.NotZero(t, status.LastSuccessTime)
+
+	reports, err := queries.GetProblemReportsByStop(ctx, "scheduled_job_test_stop")
+	require.NoError(t, err)
+	require.Len(t, reports, 1, "only the report past the retention period is pruned")
+	assert.Equal(t, now.UnixMilli(), reports[0].CreatedAt)
+}
@@ -51,7 +51,8 @@ func extractFTS5Terms(sanitizedQuery string) []string {
 }
 
 // searchStopsHandler searches for stops matching a user-provided query string
-// using full-text search, with optional geographic bounds filtering.
+// using full-text search, ranked by text relevance and, when lat and lon are
+// given, by distance.
 func (api *RestAPI) searchStopsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -64,6 +65,7 @@ func (api *RestAPI) searchStopsHandler(w http.ResponseWriter, r *http.Request) {
 	// Standardized parameter parsing
 	query, fieldErrors := utils.ParseRequiredStringParam(queryParams, "input", fieldErrors)
 	limit, fieldErrors := utils.ParseMaxCount(queryParams, 20, fieldErrors)
+	near, fieldErrors := api.parseSearchLocation(r, fieldErrors)
 	if len(fieldErrors) > 0 {
 		api.validationErrorResponse(w, r, fieldErrors)
 		return
@@ -85,9 +87,14 @@ func (api *RestAPI) searchStopsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	searchQuery := strings.Join(queryTerms, " AND ")
 
+	// Request limit + 1 to accurately determine if pagination boundaries are exceeded.
+	candidates := limit + 1
+	if near != nil {
+		candidates = max(candidates, utils.SearchCandidateLimit(limit))
+	}
 	searchParams := gtfsdb.SearchStopsByNameParams{
 		SearchQuery: searchQuery,
-		Limit:       int64(limit + 1), // Request limit + 1 to accurately determine if pagination boundaries are exceeded.
+		Limit:       int64(candidates),
 	}
 
 	// 3. Perform Full Text Search (with logged fallback)
@@ -131,6 +138,11 @@ func (api *RestAPI) searchStopsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if near != nil {
+		utils.SortByRelevanceAndDistance(stops, func(s gtfsdb.SearchStopsByNameRow) float64 {
+			return utils.Distance(near.Lat, near.Lon, s.Lat, s.Lon)
+		})
+	}
 	stops, isLimitExceeded := utils.PaginateSlice(stops, 0, limit)
 
 	// 4. Batch Fetch Related Data
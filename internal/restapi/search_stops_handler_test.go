@@ -299,3 +299,38 @@ func TestSearchStopsHandlerLimitExceeded(t *testing.T) {
 	assert.True(t, stopsRespExceeded.Data.LimitExceeded, "Expected LimitExceeded to be true when available records exceed maxCount")
 	assert.Len(t, stopsRespExceeded.Data.List, totalMatches-1)
 }
+
+func TestSearchStopsHandlerRanksByDistance(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, all := callAPIHandler[StopsResponse](t, api, searchStopsURL(url.Values{"input": {"Buenaventura"}, "maxCount": {"100"}}))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Greater(t, len(all.Data.List), 1)
+
+	// Searching from the least relevant match pulls it to the front.
+	target := all.Data.List[len(all.Data.List)-1]
+	resp, near := callAPIHandler[StopsResponse](t, api, searchStopsURL(url.Values{
+		"input":    {"Buenaventura"},
+		"maxCount": {"1"},
+		"lat":      {strconv.FormatFloat(target.Lat, 'f', -1, 64)},
+		"lon":      {strconv.FormatFloat(target.Lon, 'f', -1, 64)},
+	}))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, near.Data.List, 1)
+	assert.Equal(t, target.ID, near.Data.List[0].ID)
+	assert.True(t, near.Data.LimitExceeded)
+}
+
+func TestSearchStopsHandlerValidatesLocation(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[StopsResponse](t, api, searchStopsURL(url.Values{"input": {"Buenaventura"}, "lat": {"40.58"}}))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, model.Data.FieldErrors, "lon")
+
+	resp, model = callAPIHandler[StopsResponse](t, api, searchStopsURL(url.Values{"input": {"Buenaventura"}, "lat": {"91"}, "lon": {"-122.4"}}))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, model.Data.FieldErrors, "lat")
+}
@@ -0,0 +1,115 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// Service exceptions window: defaultServiceExceptionDays days from startDate
+// unless endDate is given, covering at most maxServiceExceptionDays days.
+const (
+	defaultServiceExceptionDays = 30
+	maxServiceExceptionDays     = 366
+)
+
+// serviceExceptionsForAgencyHandler lists the dates from startDate to endDate
+// (both included) on which calendar_dates adds or removes any of the agency's
+// services, so clients can warn riders about holiday schedules ahead of time.
+func (api *RestAPI) serviceExceptionsForAgencyHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := api.extractAndValidateID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+
+	agency, err := api.GtfsManager.FindAgency(ctx, id)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	if agency == nil {
+		api.sendNotFound(w, r)
+		return
+	}
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	now := api.Clock.Now().In(loc)
+	query := struct {
+		StartDate time.Time  `query:"startDate,layout=date"`
+		EndDate   *time.Time `query:"endDate,layout=date"`
+	}{
+		StartDate: now,
+	}
+	fieldErrors := bindQuery(r.URL.Query(), &query, loc)
+	startOfDay := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	startDate := startOfDay(query.StartDate)
+	endDate := startDate.AddDate(0, 0, defaultServiceExceptionDays-1)
+	if query.EndDate != nil {
+		endDate = startOfDay(*query.EndDate)
+	}
+	if endDate.Before(startDate) {
+		fieldErrors = addFieldError(fieldErrors, "endDate", "must not be before startDate")
+	} else if endDate.After(startDate.AddDate(0, 0, maxServiceExceptionDays-1)) {
+		fieldErrors = addFieldError(fieldErrors, "endDate", fmt.Sprintf("must be at most %d days from startDate, both included", maxServiceExceptionDays))
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	rows, err := api.GtfsManager.GtfsDB.Queries.GetServiceExceptionsForAgency(ctx, gtfsdb.GetServiceExceptionsForAgencyParams{
+		StartDate: startDate.Format("20060102"),
+		EndDate:   endDate.Format("20060102"),
+		AgencyID:  agency.ID,
+	})
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Rows are ordered by date, so each date's exceptions are adjacent.
+	list := []models.ServiceException{}
+	for _, row := range rows {
+		date, err := time.ParseInLocation("20060102", row.Date, loc)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		if len(list) == 0 || list[len(list)-1].ServiceDate != date.UnixMilli() {
+			list = append(list, models.ServiceException{
+				Date:              date.Format("2006-01-02"),
+				ServiceDate:       date.UnixMilli(),
+				AddedServiceIDs:   []string{},
+				RemovedServiceIDs: []string{},
+			})
+		}
+		exception := &list[len(list)-1]
+		serviceID := utils.FormCombinedID(agency.ID, row.ServiceID)
+		if row.ExceptionType == 1 {
+			exception.AddedServiceIDs = append(exception.AddedServiceIDs, serviceID)
+		} else {
+			exception.RemovedServiceIDs = append(exception.RemovedServiceIDs, serviceID)
+		}
+	}
+
+	references := models.NewEmptyReferences()
+	if ShouldIncludeReferences(r) {
+		references.Agencies = []models.AgencyReference{models.AgencyReferenceFromDatabase(agency)}
+	}
+
+	// Every date in the window is listed, so limitExceeded is always false.
+	response := models.NewListResponse(list, *references, false, api.Clock)
+	api.sendResponse(w, r, response)
+}
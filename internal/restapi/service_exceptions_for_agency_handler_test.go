@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func serviceExceptionsURL(agencyID string, params url.Values) string {
+	params.Set("key", "TEST")
+	return "/api/where/service-exceptions-for-agency/" + agencyID + ".json?" + params.Encode()
+}
+
+func TestServiceExceptionsForAgencyHandler(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	t.Run("defaults to the next 30 days", func(t *testing.T) {
+		resp, model := callAPIHandler[ServiceExceptionsForAgencyResponse](t, api, serviceExceptionsURL(testdata.Raba.ID, url.Values{}))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		list := model.Data.List
+		require.Len(t, list, 2, "Juneteenth and Independence Day")
+		assert.Equal(t, "2025-06-19", list[0].Date)
+		assert.Equal(t, "2025-07-04", list[1].Date)
+		assert.Empty(t, list[1].AddedServiceIDs)
+		assert.Equal(t, []string{"25_c_1658_b_18260_d_31", "25_c_1658_b_18260_d_32", "25_c_868_b_79978_d_31"}, list[1].RemovedServiceIDs)
+		assert.False(t, model.Data.LimitExceeded)
+		require.Len(t, model.Data.References.Agencies, 1)
+		assert.Equal(t, testdata.Raba.ID, model.Data.References.Agencies[0].ID)
+	})
+
+	t.Run("added and removed services", func(t *testing.T) {
+		_, model := callAPIHandler[ServiceExceptionsForAgencyResponse](t, api, serviceExceptionsURL(testdata.Raba.ID, url.Values{
+			"startDate": {"2025-12-24"},
+			"endDate":   {"2025-12-24"},
+		}))
+		require.Len(t, model.Data.List, 1)
+		exception := model.Data.List[0]
+		assert.Equal(t, []string{"25_c_1658_b_18260_d_32"}, exception.AddedServiceIDs)
+		assert.Equal(t, []string{"25_c_1658_b_18260_d_31", "25_c_868_b_79978_d_31"}, exception.RemovedServiceIDs)
+	})
+
+	t.Run("no exceptions", func(t *testing.T) {
+		_, model := callAPIHandler[ServiceExceptionsForAgencyResponse](t, api, serviceExceptionsURL(testdata.Raba.ID, url.Values{
+			"startDate": {"2025-08-01"},
+			"endDate":   {"2025-08-31"},
+		}))
+		assert.Empty(t, model.Data.List)
+	})
+}
+
+func TestServiceExceptionsForAgencyHandlerErrors(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	tests := []struct {
+		name     string
+		agencyID string
+		params   url.Values
+		want     int
+	}{
+		{"unknown agency", "missing", url.Values{}, http.StatusNotFound},
+		{"bad date", testdata.Raba.ID, url.Values{"startDate": {"tomorrow"}}, http.StatusBadRequest},
+		{"end before start", testdata.Raba.ID, url.Values{"startDate": {"2025-07-04"}, "endDate": {"2025-07-03"}}, http.StatusBadRequest},
+		{"longest window", testdata.Raba.ID, url.Values{"startDate": {"2025-01-01"}, "endDate": {"2026-01-01"}}, http.StatusOK},
+		{"too long", testdata.Raba.ID, url.Values{"startDate": {"2025-01-01"}, "endDate": {"2026-01-02"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := callAPIHandler[ServiceExceptionsForAgencyResponse](t, api, serviceExceptionsURL(tt.agencyID, tt.params))
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package restapi
+
+import (
+	"context"
+	"time"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// currentServiceHours returns the service hours of the current service day in
+// loc, combining the route service spans that spans loads for the day's
+// active service IDs. It is nil when nothing runs today.
+func (api *RestAPI) currentServiceHours(ctx context.Context, loc *time.Location, spans func(serviceIDs []string) ([]gtfsdb.RouteServiceSpan, error)) (*models.ServiceHours, error) {
+	now := api.Clock.Now().In(loc)
+	serviceMidnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	serviceIDs, err := api.GtfsManager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, serviceMidnight.Format("20060102"))
+	if err != nil {
+		return nil, err
+	}
+	if len(serviceIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := spans(serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewServiceHours(serviceMidnight, rows), nil
+}
@@ -98,6 +98,9 @@ func setupTzTestGTFS(t *testing.T, queries *gtfsdb.Queries, td tzTestData, activ
 		ArrivalTime: 9 * 3600 * int64(time.Second), DepartureTime: 9 * 3600 * int64(time.Second),
 	})
 	require.NoError(t, err)
+
+	require.NoError(t, queries.BulkUpdateTripTimeBounds(ctx))
+	require.NoError(t, queries.BuildBlockTripOrder(ctx))
 }
 
 // serveAndGet starts the API server and GETs the endpoint, returning the decoded response.
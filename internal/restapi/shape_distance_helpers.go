@@ -43,6 +43,11 @@ func (api *RestAPI) getStopDistanceAlongShape(ctx context.Context, tripID, stopI
 	return getDistanceAlongShape(stop.Lat, stop.Lon, shapePoints)
 }
 
+// getVehicleDistanceAlongShapeContextual returns how far along tripID's shape
+// the vehicle is, by snapping its position to the closest point of the shape.
+// The stop the vehicle reports, by CurrentStopSequence or else StopID, limits
+// the search to the shape between that stop and the one before it, so a loop
+// or out-and-back shape is matched on the pass the vehicle is on.
 func (api *RestAPI) getVehicleDistanceAlongShapeContextual(ctx context.Context, tripID string, vehicle *gtfs.Vehicle) float64 {
 	if vehicle == nil || vehicle.Position == nil || vehicle.Position.Latitude == nil || vehicle.Position.Longitude == nil {
 		return 0
@@ -58,33 +63,18 @@ func (api *RestAPI) getVehicleDistanceAlongShapeContextual(ctx context.Context,
 	lat := float64(*vehicle.Position.Latitude)
 	lon := float64(*vehicle.Position.Longitude)
 
-	if vehicle.CurrentStopSequence != nil {
+	if vehicle.CurrentStopSequence != nil || vehicle.StopID != nil {
 		stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, tripID)
-		if err == nil && len(stopTimes) > 0 {
-			currentSeq := int64(*vehicle.CurrentStopSequence)
-			var prevStopDist, nextStopDist float64
-			foundNext := false
-
-			for i, st := range stopTimes {
-				if st.StopSequence >= currentSeq {
-					if st.ShapeDistTraveled.Valid {
-						nextStopDist = st.ShapeDistTraveled.Float64
-					} else {
-						nextStopDist = api.getStopDistanceAlongShape(ctx, tripID, st.StopID)
-					}
-					if i > 0 {
-						if stopTimes[i-1].ShapeDistTraveled.Valid {
-							prevStopDist = stopTimes[i-1].ShapeDistTraveled.Float64
-						} else {
-							prevStopDist = api.getStopDistanceAlongShape(ctx, tripID, stopTimes[i-1].StopID)
-						}
-					}
-					foundNext = true
-					break
+		if err == nil {
+			if i, ok := vehicleStopIndex(stopTimes, vehicle); ok {
+				var prevStopDist float64
+				nextStopDist := api.stopTimeDistanceAlongShape(ctx, stopTimes[i], shapePoints)
+				if i > 0 {
+					prevStopDist = api.stopTimeDistanceAlongShape(ctx, stopTimes[i-1], shapePoints)
+				}
+				if ctx.Err() != nil {
+					return 0
 				}
-			}
-
-			if foundNext {
 				return getDistanceAlongShapeInRange(lat, lon, shapePoints, prevStopDist, nextStopDist)
 			}
 		}
@@ -92,3 +82,39 @@ func (api *RestAPI) getVehicleDistanceAlongShapeContextual(ctx context.Context,
 
 	return getDistanceAlongShape(lat, lon, shapePoints)
 }
+
+// vehicleStopIndex returns the index in stopTimes of the stop the vehicle is
+// stopped at or heading to, preferring its CurrentStopSequence to its StopID,
+// which is ambiguous on trips that serve a stop twice.
+func vehicleStopIndex(stopTimes []gtfsdb.StopTime, vehicle *gtfs.Vehicle) (int, bool) {
+	if vehicle.CurrentStopSequence != nil {
+		currentSeq := int64(*vehicle.CurrentStopSequence)
+		for i, st := range stopTimes {
+			if st.StopSequence >= currentSeq {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	if vehicle.StopID != nil && *vehicle.StopID != "" {
+		for i, st := range stopTimes {
+			if st.StopID == *vehicle.StopID {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// stopTimeDistanceAlongShape returns a stop time's shape_dist_traveled, or
+// the distance along shapePoints of its stop when it has none.
+func (api *RestAPI) stopTimeDistanceAlongShape(ctx context.Context, st gtfsdb.StopTime, shapePoints []gtfs.ShapePoint) float64 {
+	if st.ShapeDistTraveled.Valid {
+		return st.ShapeDistTraveled.Float64
+	}
+	stop, err := api.GtfsManager.GtfsDB.Queries.GetStop(ctx, st.StopID)
+	if err != nil {
+		return 0
+	}
+	return getDistanceAlongShape(stop.Lat, stop.Lon, shapePoints)
+}
@@ -0,0 +1,83 @@
+package restapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+)
+
+func TestVehicleStopIndex(t *testing.T) {
+	// A loop that starts and ends at the same stop.
+	stopTimes := []gtfsdb.StopTime{
+		{StopID: "terminal", StopSequence: 1},
+		{StopID: "a", StopSequence: 2},
+		{StopID: "b", StopSequence: 4},
+		{StopID: "terminal", StopSequence: 5},
+	}
+	seq := func(n uint32) *gtfs.Vehicle { return &gtfs.Vehicle{CurrentStopSequence: &n} }
+	stop := func(id string) *gtfs.Vehicle { return &gtfs.Vehicle{StopID: &id} }
+
+	tests := []struct {
+		name    string
+		vehicle *gtfs.Vehicle
+		index   int
+		ok      bool
+	}{
+		{"sequence", seq(2), 1, true},
+		{"sequence between stops", seq(3), 2, true},
+		{"sequence past the last stop", seq(6), 0, false},
+		{"stop ID", stop("b"), 2, true},
+		{"unknown stop ID", stop("elsewhere"), 0, false},
+		{"no hint", &gtfs.Vehicle{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, ok := vehicleStopIndex(stopTimes, tt.vehicle)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.index, index)
+		})
+	}
+
+	t.Run("sequence wins over a stop ID served twice", func(t *testing.T) {
+		vehicle := seq(5)
+		terminal := "terminal"
+		vehicle.StopID = &terminal
+
+		index, ok := vehicleStopIndex(stopTimes, vehicle)
+		require.True(t, ok)
+		assert.Equal(t, 3, index)
+	})
+}
+
+func TestGetVehicleDistanceAlongShapeContextual_SnapsToShape(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+
+	trip := mustGetTrip(t, api)
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trip.ID)
+	require.NoError(t, err)
+	require.Greater(t, len(stopTimes), 3)
+	target := stopTimes[2]
+	stop, err := api.GtfsManager.GtfsDB.Queries.GetStop(ctx, target.StopID)
+	require.NoError(t, err)
+	shapeRows, err := api.GtfsManager.GtfsDB.Queries.GetShapePointsByTripID(ctx, trip.ID)
+	require.NoError(t, err)
+	expected := getDistanceAlongShapeInRange(stop.Lat, stop.Lon, shapeRowsToPoints(shapeRows),
+		api.stopTimeDistanceAlongShape(ctx, stopTimes[1], nil), api.stopTimeDistanceAlongShape(ctx, target, nil))
+
+	lat, lon := float32(stop.Lat), float32(stop.Lon)
+	position := &gtfs.Position{Latitude: &lat, Longitude: &lon}
+	seq := uint32(target.StopSequence)
+
+	bySequence := api.getVehicleDistanceAlongShapeContextual(ctx, trip.ID, &gtfs.Vehicle{Position: position, CurrentStopSequence: &seq})
+	byStopID := api.getVehicleDistanceAlongShapeContextual(ctx, trip.ID, &gtfs.Vehicle{Position: position, StopID: &target.StopID})
+
+	assert.Greater(t, bySequence, 0.0)
+	assert.InDelta(t, expected, bySequence, 1)
+	assert.InDelta(t, bySequence, byStopID, 1, "a reported stop ID narrows the search like a stop sequence")
+}
@@ -0,0 +1,58 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// Values of the format query parameter of the SIRI endpoints. SIRI consumers
+// expect XML unless they ask for JSON.
+const (
+	siriFormatXML  = "xml"
+	siriFormatJSON = "json"
+)
+
+// sendSiri writes response as a SIRI XML document or, for siriFormatJSON, as
+// SIRI JSON with a "Siri" root member.
+func (api *RestAPI) sendSiri(w http.ResponseWriter, r *http.Request, response models.Siri, format string) {
+	var buf bytes.Buffer
+	contentType := xmlContentType
+	var err error
+	if format == siriFormatJSON {
+		contentType = "application/json"
+		err = json.NewEncoder(&buf).Encode(struct {
+			Siri models.Siri `json:"Siri"`
+		}{response})
+	} else {
+		buf.WriteString(xml.Header)
+		err = xml.NewEncoder(&buf).Encode(response)
+	}
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// siriTime returns t for an optional SIRI time element, or nil when t is
+// zero.
+func siriTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// siriDataFrameRef formats a service date as the DataFrameRef of a
+// FramedVehicleJourneyRef.
+func siriDataFrameRef(serviceDate time.Time) string {
+	return serviceDate.Format("2006-01-02")
+}
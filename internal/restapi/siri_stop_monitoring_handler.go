@@ -0,0 +1,145 @@
+package restapi
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// siriStopMonitoringHandler serves the upcoming visits to the stop
+// MonitoringRef as a SIRI stop monitoring delivery. The visits come from the
+// same arrivals computation as arrivals-and-departures-for-stop. LineRef
+// keeps the visits of one route and MaximumStopVisits caps their number.
+func (api *RestAPI) siriStopMonitoringHandler(w http.ResponseWriter, r *http.Request) {
+	query := struct {
+		MonitoringRef     string    `query:"MonitoringRef"`
+		LineRef           string    `query:"LineRef"`
+		MaximumStopVisits int       `query:"MaximumStopVisits,min=1"`
+		MinutesAfter      int       `query:"minutesAfter,min=0,max=240,clamp"`
+		Time              time.Time `query:"time"`
+		Format            string    `query:"format,oneof=xml|json"`
+	}{
+		MinutesAfter: 35,
+		Time:         api.Clock.Now(),
+		Format:       siriFormatXML,
+	}
+	fieldErrors := bindQuery(r.URL.Query(), &query, nil)
+	var stopAgencyID, stopCode string
+	if query.MonitoringRef == "" {
+		fieldErrors = addFieldError(fieldErrors, "MonitoringRef", "missingRequiredField")
+	} else {
+		var err error
+		if stopAgencyID, stopCode, err = utils.ExtractAgencyIDAndCodeID(query.MonitoringRef); err != nil {
+			fieldErrors = addFieldError(fieldErrors, "MonitoringRef", err.Error())
+		}
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+
+	stop, err := api.GtfsManager.GtfsDB.Queries.GetStop(ctx, stopCode)
+	if err != nil {
+		api.sendNotFound(w, r)
+		return
+	}
+	agency, err := api.GtfsManager.GtfsDB.Queries.GetAgency(ctx, stopAgencyID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// A few minutes before now are searched too, so that late vehicles
+	// still on their way are listed.
+	params := ArrivalsStopParams{
+		After:  time.Duration(query.MinutesAfter) * time.Minute,
+		Before: 5 * time.Minute,
+		Time:   query.Time.In(loc),
+	}
+	c := newArrivalsCollector(requestLanguage(r))
+	if _, err := api.collectArrivalsForStop(ctx, c, stopAgencyID, stop, loc, params); err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	arrivals := slices.DeleteFunc(c.arrivals, func(a models.ArrivalAndDeparture) bool {
+		return arrivalDepartureTime(a).Before(params.Time) || (query.LineRef != "" && a.RouteID != query.LineRef)
+	})
+	slices.SortStableFunc(arrivals, func(a, b models.ArrivalAndDeparture) int {
+		return arrivalDepartureTime(a).Compare(arrivalDepartureTime(b))
+	})
+	if query.MaximumStopVisits > 0 && len(arrivals) > query.MaximumStopVisits {
+		arrivals = arrivals[:query.MaximumStopVisits]
+	}
+
+	now := api.Clock.Now().In(loc)
+	visits := make([]models.SiriMonitoredStopVisit, len(arrivals))
+	for i, a := range arrivals {
+		visits[i] = siriStopVisit(c, a, query.MonitoringRef, now)
+	}
+
+	response := models.NewSiri(now)
+	response.ServiceDelivery.StopMonitoringDelivery = []models.SiriStopMonitoringDelivery{{
+		ResponseTimestamp:  now,
+		MonitoredStopVisit: visits,
+	}}
+	api.sendSiri(w, r, response, query.Format)
+}
+
+// siriStopVisit converts an arrival at the stop monitoringRef into a SIRI
+// visit. Expected times and the vehicle's location are only given for
+// predicted arrivals.
+func siriStopVisit(c *arrivalsCollector, a models.ArrivalAndDeparture, monitoringRef string, now time.Time) models.SiriMonitoredStopVisit {
+	operatorRef, _, _ := utils.ExtractAgencyIDAndCodeID(a.RouteID)
+	journey := models.SiriVehicleJourney{
+		LineRef: a.RouteID,
+		FramedVehicleJourneyRef: &models.SiriFramedVehicleJourneyRef{
+			DataFrameRef:           siriDataFrameRef(a.ServiceDate.Time),
+			DatedVehicleJourneyRef: a.TripID,
+		},
+		PublishedLineName: cmp.Or(a.RouteShortName, a.RouteLongName),
+		OperatorRef:       operatorRef,
+		DestinationName:   a.TripHeadsign,
+		Monitored:         a.Predicted,
+		VehicleRef:        a.VehicleID,
+		MonitoredCall: &models.SiriMonitoredCall{
+			StopPointRef:       a.StopID,
+			AimedArrivalTime:   siriTime(a.ScheduledArrivalTime.Time),
+			AimedDepartureTime: siriTime(a.ScheduledDepartureTime.Time),
+		},
+	}
+	if _, tripID, err := utils.ExtractAgencyIDAndCodeID(a.TripID); err == nil {
+		if trip := c.trips[tripID]; trip != nil && trip.DirectionID.Valid {
+			journey.DirectionRef = strconv.FormatInt(trip.DirectionID.Int64, 10)
+		}
+	}
+	if a.Predicted {
+		journey.MonitoredCall.ExpectedArrivalTime = siriTime(a.PredictedArrivalTime.Time)
+		journey.MonitoredCall.ExpectedDepartureTime = siriTime(a.PredictedDepartureTime.Time)
+		if ts := a.TripStatus; ts != nil && (ts.Position.Lat != 0 || ts.Position.Lon != 0) {
+			journey.VehicleLocation = &models.SiriLocation{Longitude: ts.Position.Lon, Latitude: ts.Position.Lat}
+		}
+	}
+
+	recordedAt := now
+	if !a.LastUpdateTime.IsZero() {
+		recordedAt = a.LastUpdateTime.Time
+	}
+	return models.SiriMonitoredStopVisit{
+		RecordedAtTime:          recordedAt,
+		MonitoringRef:           monitoringRef,
+		MonitoredVehicleJourney: journey,
+	}
+}
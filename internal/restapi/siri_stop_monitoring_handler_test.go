@@ -0,0 +1,98 @@
+package restapi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/models"
+)
+
+func siriStopMonitoringParams(extra url.Values) url.Values {
+	params := url.Values{
+		"MonitoringRef": {arrivalsTestStopID},
+		"time":          {strconv.FormatInt(arrivalsTestClock.UnixMilli(), 10)},
+	}
+	for k, v := range extra {
+		params[k] = v
+	}
+	return params
+}
+
+func TestSiriStopMonitoringHandler(t *testing.T) {
+	api, cleanup := createTestApiWithRealTimeData(t, clock.NewMockClock(arrivalsTestClock))
+	defer cleanup()
+
+	t.Run("xml", func(t *testing.T) {
+		resp, body := callRawEndpoint(t, api, "/siri/stop-monitoring", siriStopMonitoringParams(nil))
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		assert.Equal(t, xmlContentType, resp.Header.Get("Content-Type"))
+
+		var siri models.Siri
+		require.NoError(t, xml.Unmarshal(body, &siri))
+		assert.Equal(t, "2.0", siri.Version)
+		require.Len(t, siri.ServiceDelivery.StopMonitoringDelivery, 1)
+		visits := siri.ServiceDelivery.StopMonitoringDelivery[0].MonitoredStopVisit
+		require.NotEmpty(t, visits, "Stop4062 has an 11:17 arrival")
+
+		visit := visits[0]
+		assert.Equal(t, arrivalsTestStopID, visit.MonitoringRef)
+		journey := visit.MonitoredVehicleJourney
+		assert.Equal(t, "25", journey.OperatorRef)
+		assert.Contains(t, journey.LineRef, "25_")
+		require.NotNil(t, journey.FramedVehicleJourneyRef)
+		assert.Equal(t, "2025-06-13", journey.FramedVehicleJourneyRef.DataFrameRef)
+		require.NotNil(t, journey.MonitoredCall)
+		assert.Equal(t, arrivalsTestStopID, journey.MonitoredCall.StopPointRef)
+		require.NotNil(t, journey.MonitoredCall.AimedArrivalTime)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		resp, body := callRawEndpoint(t, api, "/siri/stop-monitoring", siriStopMonitoringParams(url.Values{"format": {"json"}}))
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+
+		var wrapper struct {
+			Siri models.Siri `json:"Siri"`
+		}
+		require.NoError(t, json.Unmarshal(body, &wrapper))
+		require.Len(t, wrapper.Siri.ServiceDelivery.StopMonitoringDelivery, 1)
+		assert.NotEmpty(t, wrapper.Siri.ServiceDelivery.StopMonitoringDelivery[0].MonitoredStopVisit)
+	})
+
+	t.Run("maximum stop visits", func(t *testing.T) {
+		params := siriStopMonitoringParams(url.Values{"MaximumStopVisits": {"1"}, "minutesAfter": {"240"}})
+		_, body := callRawEndpoint(t, api, "/siri/stop-monitoring", params)
+		var siri models.Siri
+		require.NoError(t, xml.Unmarshal(body, &siri))
+		assert.Len(t, siri.ServiceDelivery.StopMonitoringDelivery[0].MonitoredStopVisit, 1)
+	})
+
+	t.Run("line ref", func(t *testing.T) {
+		params := siriStopMonitoringParams(url.Values{"LineRef": {"25_missing"}})
+		_, body := callRawEndpoint(t, api, "/siri/stop-monitoring", params)
+		var siri models.Siri
+		require.NoError(t, xml.Unmarshal(body, &siri))
+		assert.Empty(t, siri.ServiceDelivery.StopMonitoringDelivery[0].MonitoredStopVisit)
+	})
+}
+
+func TestSiriStopMonitoringHandlerErrors(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callRawEndpoint(t, api, "/siri/stop-monitoring", url.Values{})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "MonitoringRef is required")
+
+	resp, _ = callRawEndpoint(t, api, "/siri/stop-monitoring", url.Values{"MonitoringRef": {"25_missing"}})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = callRawEndpoint(t, api, "/siri/stop-monitoring", url.Values{"MonitoringRef": {arrivalsTestStopID}, "format": {"csv"}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,149 @@
+package restapi
+
+import (
+	"cmp"
+	"net/http"
+	"strconv"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// siriVehicleMonitoringHandler serves the real-time vehicles of the agency
+// OperatorRef as a SIRI vehicle monitoring delivery, from the same vehicle
+// positions as vehicles-for-agency. LineRef keeps the vehicles on one route
+// and VehicleRef a single vehicle. An unknown operator has no vehicles.
+func (api *RestAPI) siriVehicleMonitoringHandler(w http.ResponseWriter, r *http.Request) {
+	query := struct {
+		OperatorRef string `query:"OperatorRef"`
+		LineRef     string `query:"LineRef"`
+		VehicleRef  string `query:"VehicleRef"`
+		Format      string `query:"format,oneof=xml|json"`
+	}{
+		Format: siriFormatXML,
+	}
+	fieldErrors := bindQuery(r.URL.Query(), &query, nil)
+	if query.OperatorRef == "" {
+		fieldErrors = addFieldError(fieldErrors, "OperatorRef", "missingRequiredField")
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	now := api.Clock.Now()
+	response := models.NewSiri(now)
+	delivery := models.SiriVehicleMonitoringDelivery{
+		ResponseTimestamp: now,
+		VehicleActivity:   []models.SiriVehicleActivity{},
+	}
+
+	agency, err := api.GtfsManager.FindAgency(ctx, query.OperatorRef)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	if agency == nil {
+		response.ServiceDelivery.VehicleMonitoringDelivery = []models.SiriVehicleMonitoringDelivery{delivery}
+		api.sendSiri(w, r, response, query.Format)
+		return
+	}
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	now = now.In(loc)
+	response.ServiceDelivery.ResponseTimestamp = now
+	delivery.ResponseTimestamp = now
+
+	vehicles, err := api.GtfsManager.VehiclesForAgencyID(ctx, agency.ID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	routeIDs := make([]string, 0, len(vehicles))
+	tripIDs := make([]string, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		if vehicle.Trip != nil {
+			routeIDs = append(routeIDs, vehicle.Trip.ID.RouteID)
+			tripIDs = append(tripIDs, vehicle.Trip.ID.ID)
+		}
+	}
+	routes, err := api.GtfsManager.GtfsDB.Queries.GetRoutesByIDs(ctx, routeIDs)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	routeByID := make(map[string]gtfsdb.Route, len(routes))
+	for _, route := range routes {
+		routeByID[route.ID] = route
+	}
+	trips, err := api.GtfsManager.GtfsDB.Queries.GetTripsByIDs(ctx, tripIDs)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	tripByID := make(map[string]gtfsdb.Trip, len(trips))
+	for _, trip := range trips {
+		tripByID[trip.ID] = trip
+	}
+
+	for _, vehicle := range vehicles {
+		if vehicle.ID == nil || (query.VehicleRef != "" && vehicle.ID.ID != query.VehicleRef) {
+			continue
+		}
+
+		journey := models.SiriVehicleJourney{
+			OperatorRef: agency.ID,
+			Monitored:   true,
+			VehicleRef:  vehicle.ID.ID,
+			Bearing:     vehicleBearing(&vehicle),
+		}
+		if vehicle.Position != nil && vehicle.Position.Latitude != nil && vehicle.Position.Longitude != nil {
+			journey.VehicleLocation = &models.SiriLocation{
+				Longitude: float64(*vehicle.Position.Longitude),
+				Latitude:  float64(*vehicle.Position.Latitude),
+			}
+		}
+		if vehicle.Trip != nil {
+			tripID := vehicle.Trip.ID
+			journey.LineRef = utils.FormCombinedID(agency.ID, tripID.RouteID)
+			serviceDate := now
+			if tripID.HasStartDate {
+				serviceDate = tripID.StartDate
+			}
+			journey.FramedVehicleJourneyRef = &models.SiriFramedVehicleJourneyRef{
+				DataFrameRef:           siriDataFrameRef(serviceDate),
+				DatedVehicleJourneyRef: utils.FormCombinedID(agency.ID, tripID.ID),
+			}
+			if route, ok := routeByID[tripID.RouteID]; ok {
+				journey.PublishedLineName = cmp.Or(route.ShortName.String, route.LongName.String)
+			}
+			if trip, ok := tripByID[tripID.ID]; ok {
+				journey.DestinationName = trip.TripHeadsign.String
+				if trip.DirectionID.Valid {
+					journey.DirectionRef = strconv.FormatInt(trip.DirectionID.Int64, 10)
+				}
+			}
+		}
+		if query.LineRef != "" && journey.LineRef != query.LineRef {
+			continue
+		}
+
+		recordedAt := now
+		if vehicle.Timestamp != nil {
+			recordedAt = vehicle.Timestamp.In(loc)
+		}
+		delivery.VehicleActivity = append(delivery.VehicleActivity, models.SiriVehicleActivity{
+			RecordedAtTime:          recordedAt,
+			MonitoredVehicleJourney: journey,
+		})
+	}
+
+	response.ServiceDelivery.VehicleMonitoringDelivery = []models.SiriVehicleMonitoringDelivery{delivery}
+	api.sendSiri(w, r, response, query.Format)
+}
@@ -0,0 +1,81 @@
+package restapi
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	gogtfs "github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func siriVehicleActivity(t *testing.T, api *RestAPI, params url.Values) []models.SiriVehicleActivity {
+	t.Helper()
+	resp, body := callRawEndpoint(t, api, "/siri/vehicle-monitoring", params)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	var siri models.Siri
+	require.NoError(t, xml.Unmarshal(body, &siri))
+	require.Len(t, siri.ServiceDelivery.VehicleMonitoringDelivery, 1)
+	return siri.ServiceDelivery.VehicleMonitoringDelivery[0].VehicleActivity
+}
+
+func TestSiriVehicleMonitoringHandler(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	trip := mustGetTrip(t, api)
+	lat, lon, bearing := float32(40.58), float32(-122.39), float32(90)
+	api.GtfsManager.MockAddVehicleWithOptions("siri_v1", trip.ID, trip.RouteID, gtfs.MockVehicleOptions{
+		Position: &gogtfs.Position{Latitude: &lat, Longitude: &lon, Bearing: &bearing},
+	})
+	api.GtfsManager.MockAddVehicleWithOptions("siri_v2", trip.ID, trip.RouteID, gtfs.MockVehicleOptions{})
+
+	t.Run("operator", func(t *testing.T) {
+		activity := siriVehicleActivity(t, api, url.Values{"OperatorRef": {testdata.Raba.ID}})
+		require.Len(t, activity, 2)
+		journey := activity[0].MonitoredVehicleJourney
+		assert.Equal(t, "siri_v1", journey.VehicleRef)
+		assert.Equal(t, testdata.Raba.ID, journey.OperatorRef)
+		assert.Equal(t, utils.FormCombinedID(testdata.Raba.ID, trip.RouteID), journey.LineRef)
+		assert.True(t, journey.Monitored)
+		require.NotNil(t, journey.FramedVehicleJourneyRef)
+		assert.Equal(t, utils.FormCombinedID(testdata.Raba.ID, trip.ID), journey.FramedVehicleJourneyRef.DatedVehicleJourneyRef)
+		require.NotNil(t, journey.VehicleLocation)
+		assert.InDelta(t, 40.58, journey.VehicleLocation.Latitude, 1e-4)
+		assert.InDelta(t, -122.39, journey.VehicleLocation.Longitude, 1e-4)
+		require.NotNil(t, journey.Bearing)
+		assert.Nil(t, journey.MonitoredCall)
+	})
+
+	t.Run("vehicle ref", func(t *testing.T) {
+		activity := siriVehicleActivity(t, api, url.Values{"OperatorRef": {testdata.Raba.ID}, "VehicleRef": {"siri_v2"}})
+		require.Len(t, activity, 1)
+		assert.Equal(t, "siri_v2", activity[0].MonitoredVehicleJourney.VehicleRef)
+		assert.Nil(t, activity[0].MonitoredVehicleJourney.VehicleLocation)
+	})
+
+	t.Run("line ref", func(t *testing.T) {
+		activity := siriVehicleActivity(t, api, url.Values{"OperatorRef": {testdata.Raba.ID}, "LineRef": {utils.FormCombinedID(testdata.Raba.ID, "missing")}})
+		assert.Empty(t, activity)
+	})
+
+	t.Run("unknown operator", func(t *testing.T) {
+		activity := siriVehicleActivity(t, api, url.Values{"OperatorRef": {"missing"}})
+		assert.Empty(t, activity)
+	})
+}
+
+func TestSiriVehicleMonitoringHandlerRequiresOperatorRef(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callRawEndpoint(t, api, "/siri/vehicle-monitoring", url.Values{})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
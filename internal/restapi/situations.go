@@ -0,0 +1,297 @@
+package restapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// GetSituationIDsForTrip returns the situation IDs of the alerts affecting a
+// trip, its route or its agency. Every handler that reports situationIds for
+// a trip goes through here so the IDs are identical across trip-details,
+// arrivals and trips-for-route.
+func (api *RestAPI) GetSituationIDsForTrip(ctx context.Context, tripID string) []string {
+	return situationIDsForTripAlerts(api.GtfsManager.GetTripAlerts(ctx, tripID))
+}
+
+// situationIDsForTripAlerts forms situation IDs in the namespace of the
+// trip's agency. Trips missing from the static data keep the raw alert IDs.
+func situationIDsForTripAlerts(tripAlerts internalgtfs.TripAlerts) []string {
+	situationIDs := make([]string, 0, len(tripAlerts.Alerts))
+	for _, alert := range tripAlerts.Alerts {
+		if alert.ID == "" {
+			continue
+		}
+		situationIDs = append(situationIDs, situationID(tripAlerts.AgencyID, alert.ID))
+	}
+	return situationIDs
+}
+
+// situationID is the ID an alert is reported under for agencyID, or the raw
+// alert ID when the agency is unknown.
+func situationID(agencyID, alertID string) string {
+	if agencyID == "" {
+		return alertID
+	}
+	return utils.FormCombinedID(agencyID, alertID)
+}
+
+// situationSet collects the alerts a response refers to, so that the
+// situationIds of its entries and references.situations are built from the
+// same IDs. An alert reached through several trips or stops is kept once.
+type situationSet struct {
+	lang    string
+	ids     []string
+	entries map[string]situationEntry
+}
+
+type situationEntry struct {
+	alert    gtfs.Alert
+	agencyID string
+}
+
+// newSituationSet creates a set whose situations are rendered in lang where
+// the feed provides a translation; see requestLanguage.
+func newSituationSet(lang string) *situationSet {
+	return &situationSet{lang: lang, entries: make(map[string]situationEntry)}
+}
+
+// add records alerts in the namespace of agencyID and returns their
+// situation IDs.
+func (s *situationSet) add(agencyID string, alerts ...gtfs.Alert) []string {
+	ids := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.ID == "" {
+			continue
+		}
+		id := situationID(agencyID, alert.ID)
+		if _, seen := s.entries[id]; !seen {
+			s.entries[id] = situationEntry{alert: alert, agencyID: agencyID}
+			s.ids = append(s.ids, id)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// addTrip records the alerts affecting a trip, its route or its agency and
+// returns their situation IDs.
+func (s *situationSet) addTrip(tripAlerts internalgtfs.TripAlerts) []string {
+	return s.add(tripAlerts.AgencyID, tripAlerts.Alerts...)
+}
+
+// situationIDs returns the ID of every recorded situation in the order they
+// were first added.
+func (s *situationSet) situationIDs() []string {
+	return append(make([]string, 0, len(s.ids)), s.ids...)
+}
+
+// references returns the recorded situations as reference elements.
+func (s *situationSet) references() []models.Situation {
+	situations := make([]models.Situation, 0, len(s.ids))
+	for _, id := range s.ids {
+		entry := s.entries[id]
+		situations = append(situations, buildSituation(entry.alert, entry.agencyID, s.lang))
+	}
+	return situations
+}
+
+// BuildSituationReferences converts alerts to situation references under
+// their raw alert IDs, for handlers that report situations without tying them
+// to an agency.
+func (api *RestAPI) BuildSituationReferences(alerts []gtfs.Alert) []models.Situation {
+	situations := make([]models.Situation, 0, len(alerts))
+	for _, alert := range alerts {
+		situations = append(situations, buildSituation(alert, "", ""))
+	}
+	return situations
+}
+
+// buildSituation converts a GTFS-RT alert into a situation element. Affected
+// route, stop and trip IDs are combined with the entity's agency, falling
+// back to agencyID; with no agency at all they stay raw.
+func buildSituation(alert gtfs.Alert, agencyID, lang string) models.Situation {
+	situation := models.Situation{
+		ID:                 situationID(agencyID, alert.ID),
+		CreationTime:       models.NewModelTime(time.Time{}),
+		ActiveWindows:      make([]models.ActiveWindow, 0, len(alert.ActivePeriods)),
+		AllAffects:         make([]models.AffectedEntity, 0, len(alert.InformedEntities)),
+		ConsequenceMessage: "",
+		Consequences:       []models.Consequence{},
+		PublicationWindows: []any{},
+		Reason:             mapAlertCauseToReason(alert.Cause),
+		Severity:           mapAlertEffectToSeverity(alert.Effect),
+	}
+
+	for _, period := range alert.ActivePeriods {
+		window := models.ActiveWindow{}
+		if period.StartsAt != nil {
+			window.From = period.StartsAt.UnixMilli()
+		}
+		if period.EndsAt != nil {
+			window.To = period.EndsAt.UnixMilli()
+		}
+		situation.ActiveWindows = append(situation.ActiveWindows, window)
+	}
+
+	for _, entity := range alert.InformedEntities {
+		entityAgencyID := getStringValue(entity.AgencyID)
+		namespace := entityAgencyID
+		if namespace == "" {
+			namespace = agencyID
+		}
+		affected := models.AffectedEntity{
+			AgencyID:    entityAgencyID,
+			DirectionID: affectedDirectionID(entity.DirectionID),
+			RouteID:     situationID(namespace, getStringValue(entity.RouteID)),
+			StopID:      situationID(namespace, getStringValue(entity.StopID)),
+		}
+		if entity.TripID != nil {
+			affected.TripID = situationID(namespace, entity.TripID.ID)
+		}
+		situation.AllAffects = append(situation.AllAffects, affected)
+	}
+
+	if condition := alertCondition(alert.Effect); condition != "" {
+		situation.Consequences = append(situation.Consequences, models.Consequence{Condition: condition})
+	}
+
+	situation.Summary = translatedString(alert.Header, lang)
+	situation.Description = translatedString(alert.Description, lang)
+	situation.URL = translatedString(alert.URL, lang)
+
+	return situation
+}
+
+// affectedDirectionID reports a GTFS-RT direction as "0" or "1", or "" when
+// the entity does not restrict the direction.
+func affectedDirectionID(direction gtfs.DirectionID) string {
+	switch direction {
+	case gtfs.DirectionID_False:
+		return "0"
+	case gtfs.DirectionID_True:
+		return "1"
+	default:
+		return ""
+	}
+}
+
+// alertCondition is the consequence condition for an alert effect, the
+// GTFS-RT enum name in lower case such as "detour" or "no_service". Alerts
+// without a known effect have no consequence.
+func alertCondition(effect gtfs.AlertEffect) string {
+	if effect == 0 || effect == gtfs.UnknownEffect {
+		return ""
+	}
+	return strings.ToLower(effect.String())
+}
+
+// translatedString picks the translation of texts in lang. Without a match it
+// falls back to the untranslated text, then to the first translation, as the
+// GTFS-RT spec leaves the feed's default language untagged.
+func translatedString(texts []gtfs.AlertText, lang string) *models.TranslatedString {
+	var match, untagged, first *gtfs.AlertText
+	for i := range texts {
+		text := &texts[i]
+		if text.Text == "" {
+			continue
+		}
+		if first == nil {
+			first = text
+		}
+		if text.Language == "" && untagged == nil {
+			untagged = text
+		}
+		if lang != "" && match == nil && sameLanguage(text.Language, lang) {
+			match = text
+		}
+	}
+	chosen := match
+	if chosen == nil {
+		chosen = untagged
+	}
+	if chosen == nil {
+		chosen = first
+	}
+	if chosen == nil {
+		return nil
+	}
+	return &models.TranslatedString{Value: chosen.Text, Lang: chosen.Language}
+}
+
+// sameLanguage compares BCP 47 tags by their primary language subtag, so
+// "en-US" matches a translation tagged "en".
+func sameLanguage(a, b string) bool {
+	primary := func(tag string) string {
+		tag, _, _ = strings.Cut(tag, "-")
+		return strings.ToLower(strings.TrimSpace(tag))
+	}
+	return a != "" && primary(a) == primary(b)
+}
+
+// requestLanguage returns the language the client prefers most in its
+// Accept-Language header, or "" when it expresses no preference.
+func requestLanguage(r *http.Request) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+// etagStaticWithAlerts is etagStatic for endpoints that also show the live
+// service alerts of their {id}, which alertsFor returns given its code ID.
+// Their ETag changes with those alerts and the language they are translated
+// to, so a client revalidating sees a new, changed or expired alert. Like
+// etagStatic, it pins handler to one dataset.
+func etagStaticWithAlerts(api *RestAPI, alertsFor func(manager *internalgtfs.Manager, codeID string) []gtfs.Alert, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	getETagFunc := func(r *http.Request) string {
+		etag := api.staticETag(r.Context())
+		if etag == "" {
+			return ""
+		}
+		_, codeID, err := utils.ExtractAgencyIDAndCodeID(utils.ExtractIDFromParams(r))
+		if err != nil {
+			return etag
+		}
+		alerts := alertsFor(api.GtfsManager, codeID)
+		if len(alerts) == 0 {
+			return etag
+		}
+		h := sha256.New()
+		_ = json.NewEncoder(h).Encode(alerts)
+		h.Write([]byte(requestLanguage(r)))
+		return etag + "-" + hex.EncodeToString(h.Sum(nil))[:16]
+	}
+
+	wrapped := ETagMiddleware(getETagFunc)(http.HandlerFunc(pinnedDataset(api, handler)))
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		wrapped.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,169 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestSituationIDsForTripAlerts(t *testing.T) {
+	alerts := []gtfs.Alert{{ID: "a1"}, {ID: ""}, {ID: "a2"}}
+
+	assert.Equal(t, []string{"25_a1", "25_a2"},
+		situationIDsForTripAlerts(internalgtfs.TripAlerts{AgencyID: "25", Alerts: alerts}))
+	assert.Equal(t, []string{"a1", "a2"},
+		situationIDsForTripAlerts(internalgtfs.TripAlerts{Alerts: alerts}),
+		"trips without a known agency keep the raw alert IDs")
+	assert.Empty(t, situationIDsForTripAlerts(internalgtfs.TripAlerts{AgencyID: "25"}))
+}
+
+func TestSituationSetDeduplicates(t *testing.T) {
+	set := newSituationSet("")
+
+	assert.Equal(t, []string{"25_a1", "25_a2"}, set.add("25", gtfs.Alert{ID: "a1"}, gtfs.Alert{ID: "a2"}))
+	assert.Equal(t, []string{"25_a1"}, set.addTrip(internalgtfs.TripAlerts{AgencyID: "25", Alerts: []gtfs.Alert{{ID: "a1"}}}))
+	assert.Equal(t, []string{"a3"}, set.add("", gtfs.Alert{ID: "a3"}, gtfs.Alert{ID: ""}))
+
+	assert.Equal(t, []string{"25_a1", "25_a2", "a3"}, set.situationIDs())
+	references := set.references()
+	require.Len(t, references, 3)
+	for i, id := range set.situationIDs() {
+		assert.Equal(t, id, references[i].ID)
+	}
+}
+
+func TestBuildSituation(t *testing.T) {
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	routeID, stopID, otherAgency := "151", "4062", "40"
+	alert := gtfs.Alert{
+		ID:            "detour-1",
+		Cause:         gtfs.Construction,
+		Effect:        gtfs.Detour,
+		ActivePeriods: []gtfs.AlertActivePeriod{{StartsAt: &start}},
+		InformedEntities: []gtfs.AlertInformedEntity{
+			{RouteID: &routeID, DirectionID: gtfs.DirectionID_True},
+			{AgencyID: &otherAgency, StopID: &stopID},
+			{TripID: &gtfs.TripID{ID: "t1"}},
+		},
+		Header: []gtfs.AlertText{
+			{Text: "Detour", Language: ""},
+			{Text: "Desvío", Language: "es"},
+		},
+	}
+
+	situation := buildSituation(alert, "25", "es-MX")
+
+	assert.Equal(t, "25_detour-1", situation.ID)
+	assert.Equal(t, []models.ActiveWindow{{From: start.UnixMilli()}}, situation.ActiveWindows)
+	assert.Equal(t, []models.AffectedEntity{
+		{RouteID: "25_151", DirectionID: "1"},
+		{AgencyID: "40", StopID: "40_4062"},
+		{TripID: "25_t1"},
+	}, situation.AllAffects)
+	assert.Equal(t, []models.Consequence{{Condition: "detour"}}, situation.Consequences)
+	require.NotNil(t, situation.Summary)
+	assert.Equal(t, models.TranslatedString{Value: "Desvío", Lang: "es"}, *situation.Summary)
+	assert.Nil(t, situation.Description)
+
+	situation = buildSituation(gtfs.Alert{ID: "a", Header: alert.Header}, "", "fr")
+	assert.Equal(t, "a", situation.ID)
+	assert.Empty(t, situation.Consequences, "alerts without an effect have no consequence")
+	assert.Equal(t, "Detour", situation.Summary.Value, "untagged text is the default translation")
+}
+
+func TestTranslatedStringFallsBackToFirst(t *testing.T) {
+	texts := []gtfs.AlertText{{Text: "", Language: "de"}, {Text: "Closed", Language: "en"}, {Text: "Fermé", Language: "fr"}}
+
+	assert.Equal(t, "Fermé", translatedString(texts, "fr").Value)
+	assert.Equal(t, "Closed", translatedString(texts, "de").Value)
+	assert.Nil(t, translatedString(nil, "en"))
+}
+
+func TestRequestLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"fr", "fr"},
+		{"en-US,en;q=0.9,es;q=0.8", "en-US"},
+		{"de;q=0.2, es;q=0.7, *", "es"},
+		{"en;q=bogus, it;q=0.1", "it"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", tt.header)
+		assert.Equal(t, tt.want, requestLanguage(r), "Accept-Language %q", tt.header)
+	}
+}
+
+func TestStopHandlerIncludesSituations(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	_, stopCode, err := utils.ExtractAgencyIDAndCodeID(testdata.Stop4062.ID)
+	require.NoError(t, err)
+	api.GtfsManager.MockAddAlert("feed", gtfs.Alert{
+		ID:               "stop-closed",
+		Effect:           gtfs.NoService,
+		InformedEntities: []gtfs.AlertInformedEntity{{StopID: &stopCode}},
+		Header:           []gtfs.AlertText{{Text: "Stop closed"}},
+	})
+
+	resp, model := callAPIHandler[StopEntryResponse](t, api, stopURL(testdata.Stop4062.ID))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, model.Data.References.Situations, 1)
+	situation := model.Data.References.Situations[0]
+	assert.Equal(t, "25_stop-closed", situation.ID)
+	assert.Equal(t, []models.Consequence{{Condition: "no_service"}}, situation.Consequences)
+	assert.Equal(t, "25_4062", situation.AllAffects[0].StopID)
+	assert.Equal(t, "Stop closed", situation.Summary.Value)
+}
+
+func TestStopHandlerETagCoversSituations(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	get := func(ifNoneMatch string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+stopURL(testdata.Stop4062.ID), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	before := get("").Header.Get("ETag")
+	require.NotEmpty(t, before)
+	require.Equal(t, http.StatusNotModified, get(before).StatusCode)
+
+	_, stopCode, err := utils.ExtractAgencyIDAndCodeID(testdata.Stop4062.ID)
+	require.NoError(t, err)
+	api.GtfsManager.MockAddAlert("feed", gtfs.Alert{
+		ID:               "stop-closed",
+		Effect:           gtfs.NoService,
+		InformedEntities: []gtfs.AlertInformedEntity{{StopID: &stopCode}},
+		Header:           []gtfs.AlertText{{Text: "Stop closed"}},
+	})
+
+	resp := get(before)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "a new alert invalidates the stop's ETag")
+	after := resp.Header.Get("ETag")
+	assert.NotEqual(t, before, after)
+	assert.Equal(t, http.StatusNotModified, get(after).StatusCode)
+}
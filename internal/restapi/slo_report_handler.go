@@ -0,0 +1,19 @@
+package restapi
+
+import (
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// sloReportHandler reports per-endpoint availability and latency against the
+// service level objectives over the rolling SLO window.
+func (api *RestAPI) sloReportHandler(w http.ResponseWriter, r *http.Request) {
+	if api.sloTracker == nil {
+		api.sendError(w, r, http.StatusNotFound, "SLO tracking is not configured")
+		return
+	}
+	report := api.sloTracker.Report()
+	response := models.NewEntryResponse(report, *models.NewEmptyReferences(), api.Clock)
+	api.sendResponse(w, r, response)
+}
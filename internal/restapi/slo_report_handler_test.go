@@ -0,0 +1,45 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type sloReportResponse struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+	Data struct {
+		Entry models.SLOReport `json:"entry"`
+	} `json:"data"`
+}
+
+func TestSLOReportRequiresProtectedApiKey(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[sloReportResponse](t, api, "/api/where/slo-report.json?key=TEST")
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestSLOReportTracksRequests(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, model := callAPIHandler[sloReportResponse](t, api, "/api/where/slo-report.json?key=PROTECTED-TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	report := model.Data.Entry
+	assert.Equal(t, 99.9, report.AvailabilityTargetPercent)
+	require.NotEmpty(t, report.Endpoints)
+	assert.Equal(t, "current-time", report.Endpoints[0].Endpoint)
+	assert.Equal(t, int64(1), report.Endpoints[0].Requests)
+	assert.Equal(t, int64(0), report.Endpoints[0].Failed)
+}
@@ -0,0 +1,220 @@
+package restapi
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/metrics"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// sloBucketWidth is the resolution of the rolling SLO window.
+const sloBucketWidth = time.Hour
+
+// SLOTracker counts API requests per endpoint against the availability and
+// latency objectives over a rolling window. Requests are bucketed by hour, so
+// the window slides in hourly steps.
+type SLOTracker struct {
+	cfg     appconf.SLOConfig
+	metrics *metrics.Metrics
+	buckets int
+	now     func() time.Time // wall time; overridden in tests
+
+	mu        sync.Mutex
+	endpoints map[string]*sloWindow
+}
+
+type sloCounts struct {
+	requests, failed, slow int64
+}
+
+func (c *sloCounts) add(o sloCounts) {
+	c.requests += o.requests
+	c.failed += o.failed
+	c.slow += o.slow
+}
+
+func (c *sloCounts) sub(o sloCounts) {
+	c.requests -= o.requests
+	c.failed -= o.failed
+	c.slow -= o.slow
+}
+
+// sloWindow is a ring of hourly buckets plus their running total, so recording
+// a request and reading the window are both constant time.
+type sloWindow struct {
+	buckets []sloCounts
+	hour    int64 // hour of the newest bucket, in hours since the Unix epoch
+	total   sloCounts
+}
+
+// advance expires the buckets that have left the window by hour.
+func (w *sloWindow) advance(hour int64) {
+	if hour <= w.hour {
+		return
+	}
+	if hour-w.hour >= int64(len(w.buckets)) {
+		clear(w.buckets)
+		w.total = sloCounts{}
+	} else {
+		for h := w.hour + 1; h <= hour; h++ {
+			i := h % int64(len(w.buckets))
+			w.total.sub(w.buckets[i])
+			w.buckets[i] = sloCounts{}
+		}
+	}
+	w.hour = hour
+}
+
+// NewSLOTracker tracks requests against cfg, filling unset objectives with the
+// defaults. m may be nil to skip exporting the SLIs as metrics.
+func NewSLOTracker(cfg appconf.SLOConfig, m *metrics.Metrics) *SLOTracker {
+	cfg = cfg.WithDefaults()
+	return &SLOTracker{
+		cfg:       cfg,
+		metrics:   m,
+		buckets:   max(int(cfg.Window/sloBucketWidth), 1),
+		now:       time.Now,
+		endpoints: make(map[string]*sloWindow),
+	}
+}
+
+// Handler returns middleware recording each API request. It reads r.Pattern
+// after the request is served, so it may wrap the mux.
+func (t *SLOTracker) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		wrapped := &metricsResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r)
+
+		if r.Pattern == "" {
+			return
+		}
+		latency := time.Since(start)
+		if isLongPoll(r) {
+			// Held open on purpose; only its status counts.
+			latency = 0
+		}
+		t.record(endpointFromPattern(r.Pattern), wrapped.statusCode, latency)
+	})
+}
+
+func (t *SLOTracker) record(endpoint string, status int, latency time.Duration) {
+	sample := sloCounts{requests: 1}
+	if status >= http.StatusInternalServerError {
+		sample.failed = 1
+	}
+	if latency > t.cfg.LatencyThreshold {
+		sample.slow = 1
+	}
+
+	t.mu.Lock()
+	window := t.windowLocked(endpoint)
+	window.buckets[window.hour%int64(len(window.buckets))].add(sample)
+	window.total.add(sample)
+	report := t.endpointReport(endpoint, window.total)
+	t.mu.Unlock()
+
+	t.export(report)
+}
+
+// windowLocked returns the endpoint's window advanced to the current hour.
+func (t *SLOTracker) windowLocked(endpoint string) *sloWindow {
+	hour := t.now().Unix() / int64(sloBucketWidth/time.Second)
+	window, ok := t.endpoints[endpoint]
+	if !ok {
+		window = &sloWindow{buckets: make([]sloCounts, t.buckets), hour: hour}
+		t.endpoints[endpoint] = window
+	}
+	window.advance(hour)
+	return window
+}
+
+// Report summarizes every endpoint seen during the window, sorted by name.
+func (t *SLOTracker) Report() models.SLOReport {
+	t.mu.Lock()
+	names := make([]string, 0, len(t.endpoints))
+	for name := range t.endpoints {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var overall sloCounts
+	endpoints := make([]models.SLOEndpointReport, 0, len(names))
+	for _, name := range names {
+		total := t.windowLocked(name).total
+		if total.requests == 0 {
+			continue
+		}
+		overall.add(total)
+		endpoints = append(endpoints, t.endpointReport(name, total))
+	}
+	t.mu.Unlock()
+
+	for _, report := range endpoints {
+		t.export(report)
+	}
+	return models.SLOReport{
+		WindowDays:                t.cfg.Window.Hours() / 24,
+		AvailabilityTargetPercent: t.cfg.AvailabilityTarget,
+		LatencyThresholdMs:        t.cfg.LatencyThreshold.Milliseconds(),
+		LatencyTargetPercent:      t.cfg.LatencyTarget,
+		Overall:                   t.endpointReport("", overall),
+		Endpoints:                 endpoints,
+	}
+}
+
+func (t *SLOTracker) endpointReport(endpoint string, c sloCounts) models.SLOEndpointReport {
+	report := models.SLOEndpointReport{
+		Endpoint:                    endpoint,
+		Requests:                    c.requests,
+		Failed:                      c.failed,
+		Slow:                        c.slow,
+		AvailabilityPercent:         goodPercent(c.requests, c.failed),
+		LatencyPercent:              goodPercent(c.requests, c.slow),
+		AvailabilityBudgetRemaining: budgetRemaining(c.requests, c.failed, t.cfg.AvailabilityTarget),
+		LatencyBudgetRemaining:      budgetRemaining(c.requests, c.slow, t.cfg.LatencyTarget),
+	}
+	report.Met = report.AvailabilityPercent >= t.cfg.AvailabilityTarget && report.LatencyPercent >= t.cfg.LatencyTarget
+	return report
+}
+
+func (t *SLOTracker) export(report models.SLOEndpointReport) {
+	if t.metrics == nil {
+		return
+	}
+	t.metrics.SLOCompliance.WithLabelValues(report.Endpoint, "availability").Set(report.AvailabilityPercent / 100)
+	t.metrics.SLOCompliance.WithLabelValues(report.Endpoint, "latency").Set(report.LatencyPercent / 100)
+	t.metrics.SLOErrorBudgetRemaining.WithLabelValues(report.Endpoint, "availability").Set(report.AvailabilityBudgetRemaining)
+	t.metrics.SLOErrorBudgetRemaining.WithLabelValues(report.Endpoint, "latency").Set(report.LatencyBudgetRemaining)
+}
+
+// goodPercent is the share of requests that were not bad, or 100 with no traffic.
+func goodPercent(requests, bad int64) float64 {
+	if requests == 0 {
+		return 100
+	}
+	return float64(requests-bad) * 100 / float64(requests)
+}
+
+// budgetRemaining is the fraction of the error budget for targetPercent left
+// after bad of requests missed the objective.
+func budgetRemaining(requests, bad int64, targetPercent float64) float64 {
+	allowed := float64(requests) * (100 - targetPercent) / 100
+	if allowed == 0 {
+		if bad == 0 {
+			return 1
+		}
+		return -float64(bad)
+	}
+	return 1 - float64(bad)/allowed
+}
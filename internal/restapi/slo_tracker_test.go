@@ -0,0 +1,115 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/metrics"
+)
+
+func newTestSLOTracker(now *time.Time, m *metrics.Metrics) *SLOTracker {
+	tracker := NewSLOTracker(appconf.SLOConfig{Window: 2 * time.Hour}, m)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestSLOTrackerDefaults(t *testing.T) {
+	report := NewSLOTracker(appconf.SLOConfig{}, nil).Report()
+
+	assert.Equal(t, 99.9, report.AvailabilityTargetPercent)
+	assert.Equal(t, int64(300), report.LatencyThresholdMs)
+	assert.Equal(t, 99.0, report.LatencyTargetPercent)
+	assert.Equal(t, 30.0, report.WindowDays)
+	assert.Empty(t, report.Endpoints)
+	assert.True(t, report.Overall.Met, "no traffic meets the objectives")
+	assert.Equal(t, 1.0, report.Overall.AvailabilityBudgetRemaining)
+}
+
+func TestSLOTrackerReport(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestSLOTracker(&now, nil)
+
+	for range 1998 {
+		tracker.record("stop", http.StatusOK, 10*time.Millisecond)
+	}
+	tracker.record("stop", http.StatusInternalServerError, 10*time.Millisecond)
+	tracker.record("stop", http.StatusNotFound, time.Second)
+	tracker.record("trip", http.StatusServiceUnavailable, time.Millisecond)
+
+	report := tracker.Report()
+	require.Len(t, report.Endpoints, 2)
+
+	stop := report.Endpoints[0]
+	assert.Equal(t, "stop", stop.Endpoint)
+	assert.Equal(t, int64(2000), stop.Requests)
+	assert.Equal(t, int64(1), stop.Failed, "only 5xx responses count against availability")
+	assert.Equal(t, int64(1), stop.Slow)
+	assert.InDelta(t, 99.95, stop.AvailabilityPercent, 1e-9)
+	assert.InDelta(t, 0.5, stop.AvailabilityBudgetRemaining, 1e-9, "1 of 2 allowed failures used")
+	assert.InDelta(t, 0.95, stop.LatencyBudgetRemaining, 1e-9, "1 of 20 allowed slow requests used")
+	assert.True(t, stop.Met)
+
+	trip := report.Endpoints[1]
+	assert.Equal(t, "trip", trip.Endpoint)
+	assert.False(t, trip.Met)
+	assert.Less(t, trip.AvailabilityBudgetRemaining, 0.0)
+
+	assert.Equal(t, int64(2001), report.Overall.Requests)
+	assert.Equal(t, int64(2), report.Overall.Failed)
+}
+
+func TestSLOTrackerWindowSlides(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestSLOTracker(&now, nil)
+
+	tracker.record("stop", http.StatusInternalServerError, time.Millisecond)
+	now = now.Add(time.Hour)
+	tracker.record("stop", http.StatusOK, time.Millisecond)
+
+	report := tracker.Report()
+	require.Len(t, report.Endpoints, 1)
+	assert.Equal(t, int64(2), report.Endpoints[0].Requests)
+	assert.Equal(t, int64(1), report.Endpoints[0].Failed)
+
+	// The failure's bucket leaves the two hour window.
+	now = now.Add(time.Hour)
+	report = tracker.Report()
+	require.Len(t, report.Endpoints, 1)
+	assert.Equal(t, int64(1), report.Endpoints[0].Requests)
+	assert.Equal(t, int64(0), report.Endpoints[0].Failed)
+
+	// Idle endpoints drop out of the report entirely.
+	now = now.Add(24 * time.Hour)
+	assert.Empty(t, tracker.Report().Endpoints)
+}
+
+func TestSLOTrackerHandler(t *testing.T) {
+	m := metrics.New()
+	now := time.Now()
+	tracker := newTestSLOTracker(&now, m)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/where/stop/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {})
+	handler := tracker.Handler(mux)
+
+	for _, path := range []string{"/api/where/stop/1_2.json", "/healthz", "/api/where/missing.json"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	report := tracker.Report()
+	require.Len(t, report.Endpoints, 1, "only matched API routes are tracked")
+	assert.Equal(t, "stop", report.Endpoints[0].Endpoint)
+	assert.Equal(t, int64(1), report.Endpoints[0].Failed)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.SLOCompliance.WithLabelValues("stop", "availability")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.SLOCompliance.WithLabelValues("stop", "latency")))
+}
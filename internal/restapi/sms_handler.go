@@ -0,0 +1,185 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/nulls"
+)
+
+const (
+	// smsMaxLength is the length of a single-segment SMS.
+	smsMaxLength = 160
+	// smsMaxTimesPerRoute caps the departures listed for each route.
+	smsMaxTimesPerRoute = 3
+	// smsMaxRoutes bounds the routes considered before trimming to fit.
+	smsMaxRoutes = 10
+)
+
+// smsMessage is the data available to SMS templates.
+type smsMessage struct {
+	StopName   string
+	StopCode   string
+	AgencyName string
+	Departures string
+}
+
+// smsHandler answers an SMS gateway with a plain-text departure summary that
+// fits one SMS, for the stop with the given stopCode. agencyId picks between
+// agencies that reuse a stop code.
+func (api *RestAPI) smsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	stopCode := strings.TrimSpace(query.Get("stopCode"))
+	agencyID := query.Get("agencyId")
+
+	params, fieldErrors := api.parseArrivalsAndDeparturesParams(r)
+	if stopCode == "" {
+		if fieldErrors == nil {
+			fieldErrors = make(map[string][]string)
+		}
+		fieldErrors["stopCode"] = append(fieldErrors["stopCode"], "stopCode is required")
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+
+	candidates, err := api.GtfsManager.GtfsDB.Queries.GetStopsByCode(ctx, nulls.String(stopCode))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	stopIDs := make([]string, len(candidates))
+	for i, stop := range candidates {
+		stopIDs[i] = stop.ID
+	}
+	agencyRows, err := api.GtfsManager.GtfsDB.Queries.GetAgenciesForStops(ctx, stopIDs)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The first stop with service from the requested agency (or any agency)
+	// answers; stops without stop_times have no agency and are skipped.
+	var (
+		stop   gtfsdb.Stop
+		agency gtfsdb.Agency
+		found  bool
+	)
+	for _, candidate := range candidates {
+		for _, row := range agencyRows {
+			if row.StopID != candidate.ID || (agencyID != "" && row.ID != agencyID) {
+				continue
+			}
+			stop = candidate
+			agency = gtfsdb.Agency{ID: row.ID, Name: row.Name, Timezone: row.Timezone}
+			found = true
+			break
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		api.sendNotFound(w, r)
+		return
+	}
+
+	loc, err := loadAgencyLocation(agency.ID, agency.Timezone)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	params.Time = params.Time.In(loc)
+
+	c := newArrivalsCollector(requestLanguage(r))
+	if _, err := api.collectArrivalsForStop(ctx, c, agency.ID, stop, loc, params); err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	tmpl, err := template.New("sms").Parse(api.Config.SMS.TemplateFor(agency.ID))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	msg := smsMessage{
+		StopName:   stop.Name.String,
+		StopCode:   stopCode,
+		AgencyName: agency.Name,
+	}
+	text, err := renderSMS(tmpl, msg, groupUpcomingDepartures(c.arrivals, params.Time, smsMaxRoutes, smsMaxTimesPerRoute), int(params.After.Minutes()))
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(text))
+}
+
+// renderSMS executes tmpl with as many departure groups as fit in one SMS,
+// dropping the latest routes first. If even a single route is too long the
+// message is cut with an ellipsis.
+func renderSMS(tmpl *template.Template, msg smsMessage, groups []*departureGroup, windowMinutes int) (string, error) {
+	if len(groups) == 0 {
+		msg.Departures = fmt.Sprintf("No departures in the next %d min", windowMinutes)
+		return executeSMS(tmpl, msg)
+	}
+
+	var text string
+	for n := len(groups); n >= 1; n-- {
+		parts := make([]string, n)
+		for i, g := range groups[:n] {
+			parts[i] = smsGroup(g)
+		}
+		msg.Departures = strings.Join(parts, "; ")
+
+		var err error
+		text, err = executeSMS(tmpl, msg)
+		if err != nil {
+			return "", err
+		}
+		if utf8.RuneCountInString(text) <= smsMaxLength {
+			return text, nil
+		}
+	}
+	return string([]rune(text)[:smsMaxLength-1]) + "…", nil
+}
+
+func executeSMS(tmpl *template.Template, msg smsMessage) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, msg); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// smsGroup renders one route compactly, e.g. "40 Downtown: now, 12 min".
+func smsGroup(g *departureGroup) string {
+	label := g.route
+	if g.headsign != "" {
+		label += " " + g.headsign
+	}
+	times := make([]string, len(g.minutes))
+	for i, m := range g.minutes {
+		if m == 0 {
+			times[i] = "now"
+		} else {
+			times[i] = strconv.Itoa(m)
+		}
+	}
+	text := label + ": " + strings.Join(times, ", ")
+	if g.minutes[len(g.minutes)-1] != 0 {
+		text += " min"
+	}
+	return text
+}
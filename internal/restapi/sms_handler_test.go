@@ -0,0 +1,108 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/clock"
+)
+
+func getSMS(t *testing.T, api *RestAPI, query url.Values) (*http.Response, string) {
+	t.Helper()
+	query.Set("key", "TEST")
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/api/where/sms.txt?" + query.Encode())
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+// smsTestStopCode is the stop code of RABA stop 1001, "Northpoint Dr at Lake Blvd".
+const smsTestStopCode = "1001"
+
+func TestSMSHandlerSummarizesDepartures(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+
+	resp, body := getSMS(t, api, url.Values{"stopCode": {smsTestStopCode}, "minutesAfter": {"120"}})
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(body, "Northpoint Dr at Lake Blvd: "), body)
+	assert.Contains(t, body, " min")
+	assert.LessOrEqual(t, utf8.RuneCountInString(body), smsMaxLength)
+}
+
+func TestSMSHandlerUsesAgencyTemplate(t *testing.T) {
+	api := createTestApiWithClock(t, clock.NewMockClock(arrivalsTestClock))
+	defer api.Shutdown()
+	api.Config.SMS = appconf.SMSConfig{
+		Template:        "unused",
+		AgencyTemplates: map[string]string{"25": "{{.AgencyName}} #{{.StopCode}}: {{.Departures}}"},
+	}
+	resp, body := getSMS(t, api, url.Values{"stopCode": {smsTestStopCode}, "agencyId": {"25"}})
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, " #"+smsTestStopCode+": ")
+	assert.NotContains(t, body, "unused")
+}
+
+func TestSMSHandlerErrors(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := getSMS(t, api, url.Values{})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = getSMS(t, api, url.Values{"stopCode": {"no-such-code"}})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = getSMS(t, api, url.Values{"stopCode": {smsTestStopCode}, "agencyId": {"other"}})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRenderSMSFitsOneMessage(t *testing.T) {
+	tmpl := template.Must(template.New("sms").Parse(appconf.DefaultSMSTemplate))
+	msg := smsMessage{StopName: "Main St & 3rd Ave"}
+
+	groups := []*departureGroup{
+		{route: "40", headsign: "Downtown", minutes: []int{0, 12}},
+		{route: "8", headsign: "Airport", minutes: []int{5}},
+	}
+	text, err := renderSMS(tmpl, msg, groups, 35)
+	require.NoError(t, err)
+	assert.Equal(t, "Main St & 3rd Ave: 40 Downtown: now, 12 min; 8 Airport: 5 min", text)
+
+	// Routes that do not fit are dropped, latest first.
+	var many []*departureGroup
+	for range 10 {
+		many = append(many, &departureGroup{route: "100", headsign: "Somewhere Quite Far Away", minutes: []int{1, 2, 3}})
+	}
+	text, err = renderSMS(tmpl, msg, many, 35)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, utf8.RuneCountInString(text), smsMaxLength)
+	assert.NotContains(t, text, "…")
+
+	// A single oversized route is cut with an ellipsis.
+	long := []*departureGroup{{route: strings.Repeat("X", 200), minutes: []int{1}}}
+	text, err = renderSMS(tmpl, msg, long, 35)
+	require.NoError(t, err)
+	assert.Equal(t, smsMaxLength, utf8.RuneCountInString(text))
+	assert.True(t, strings.HasSuffix(text, "…"))
+
+	text, err = renderSMS(tmpl, msg, nil, 35)
+	require.NoError(t, err)
+	assert.Equal(t, "Main St & 3rd Ave: No departures in the next 35 min", text)
+}
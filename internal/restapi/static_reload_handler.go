@@ -0,0 +1,36 @@
+package restapi
+
+import (
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// staticReloadStatusHandler reports the static dataset the reload guard is
+// currently refusing, or a null entry when the latest reload was accepted.
+func (api *RestAPI) staticReloadStatusHandler(w http.ResponseWriter, r *http.Request) {
+	refused := api.GtfsManager.RefusedStaticReload()
+	response := models.NewEntryResponse(refused, *models.NewEmptyReferences(), api.Clock)
+	api.sendResponse(w, r, response)
+}
+
+// approveStaticReloadHandler lets an admin put a refused dataset into service.
+// The hash must match the refused dataset reported by static-reload-status.
+func (api *RestAPI) approveStaticReloadHandler(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Query().Get("hash")
+	if hash == "" {
+		fieldErrors := map[string][]string{"hash": {"hash is required"}}
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	refused := api.GtfsManager.RefusedStaticReload()
+	if err := api.GtfsManager.ApproveStaticReload(hash); err != nil {
+		api.sendError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+
+	recordAuditChange(r, hash, refused, map[string]string{"approvedHash": hash})
+	response := models.NewEntryResponse(refused, *models.NewEmptyReferences(), api.Clock)
+	api.sendResponse(w, r, response)
+}
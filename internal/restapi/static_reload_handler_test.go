@@ -0,0 +1,51 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/gtfs"
+)
+
+type staticReloadStatusResponse struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+	Data struct {
+		Entry *gtfs.RefusedReload `json:"entry"`
+	} `json:"data"`
+}
+
+func TestStaticReloadStatusRequiresProtectedApiKey(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[staticReloadStatusResponse](t, api, "/api/where/static-reload-status.json?key=TEST")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = callAPIHandler[staticReloadStatusResponse](t, api, "/api/where/approve-static-reload.json?key=TEST&hash=abc")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestStaticReloadStatusNothingRefused(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[staticReloadStatusResponse](t, api, "/api/where/static-reload-status.json?key=PROTECTED-TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, model.Data.Entry)
+}
+
+func TestApproveStaticReloadErrors(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[staticReloadStatusResponse](t, api, "/api/where/approve-static-reload.json?key=PROTECTED-TEST")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, model := callAPIHandler[staticReloadStatusResponse](t, api, "/api/where/approve-static-reload.json?key=PROTECTED-TEST&hash=abc")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, model.Text, "no refused static reload")
+}
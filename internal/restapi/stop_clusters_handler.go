@@ -0,0 +1,105 @@
+package restapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// stopClustersHandler returns the active stops within a bounding box grouped
+// into clusters sized for a map zoom level, so map clients can show stop
+// density at city zoom without downloading every stop.
+func (api *RestAPI) stopClustersHandler(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	fieldErrors := make(map[string][]string)
+	bounds, ok := parseBBox(queryParams.Get("bbox"))
+	if !ok {
+		fieldErrors["bbox"] = []string{"bbox must be minLon,minLat,maxLon,maxLat with valid coordinates and min < max"}
+	}
+	zoom, err := strconv.Atoi(queryParams.Get("zoom"))
+	if err != nil || zoom < 0 || zoom > gtfs.MaxStopClusterZoom {
+		fieldErrors["zoom"] = []string{"zoom must be an integer between 0 and " + strconv.Itoa(gtfs.MaxStopClusterZoom)}
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	clusters, err := api.GtfsManager.GetStopClusters(ctx, bounds, zoom)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var singleStopIDs []string
+	for _, cluster := range clusters {
+		if cluster.StopID != "" {
+			singleStopIDs = append(singleStopIDs, cluster.StopID)
+		}
+	}
+	stopAgency := make(map[string]string, len(singleStopIDs))
+	if len(singleStopIDs) > 0 {
+		rows, err := api.GtfsManager.GtfsDB.Queries.GetAgenciesForStops(ctx, singleStopIDs)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		for _, row := range rows {
+			if _, seen := stopAgency[row.StopID]; !seen {
+				stopAgency[row.StopID] = row.ID
+			}
+		}
+	}
+
+	results := make([]models.StopCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		result := models.StopCluster{
+			Lat:       cluster.Lat,
+			Lon:       cluster.Lon,
+			StopCount: cluster.StopCount,
+			Bounds: models.StopClusterBounds{
+				MinLat: cluster.Bounds.MinLat,
+				MinLon: cluster.Bounds.MinLon,
+				MaxLat: cluster.Bounds.MaxLat,
+				MaxLon: cluster.Bounds.MaxLon,
+			},
+		}
+		if agencyID, ok := stopAgency[cluster.StopID]; ok {
+			result.StopID = utils.FormCombinedID(agencyID, cluster.StopID)
+		}
+		results = append(results, result)
+	}
+
+	response := models.NewListResponse(results, *models.NewEmptyReferences(), false, api.Clock)
+	api.sendResponse(w, r, response)
+}
+
+// parseBBox parses a "minLon,minLat,maxLon,maxLat" bounding box, the axis
+// order used by GeoJSON and most web map libraries.
+func parseBBox(value string) (utils.CoordinateBounds, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return utils.CoordinateBounds{}, false
+	}
+	var coords [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return utils.CoordinateBounds{}, false
+		}
+		coords[i] = v
+	}
+	bounds := utils.CoordinateBounds{MinLon: coords[0], MinLat: coords[1], MaxLon: coords[2], MaxLat: coords[3]}
+	if utils.ValidateLongitude(bounds.MinLon) != nil || utils.ValidateLongitude(bounds.MaxLon) != nil ||
+		utils.ValidateLatitude(bounds.MinLat) != nil || utils.ValidateLatitude(bounds.MaxLat) != nil ||
+		bounds.MinLon >= bounds.MaxLon || bounds.MinLat >= bounds.MaxLat {
+		return utils.CoordinateBounds{}, false
+	}
+	return bounds, true
+}
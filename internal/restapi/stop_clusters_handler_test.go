@@ -0,0 +1,77 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+type StopClustersResponse ListResponse[models.StopCluster]
+
+func stopClustersURL(bbox string, zoom int) string {
+	return fmt.Sprintf("/api/where/stop-clusters.json?key=TEST&bbox=%s&zoom=%d", bbox, zoom)
+}
+
+func TestStopClustersHandler_CityZoom(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, model := callAPIHandler[StopClustersResponse](t, api, stopClustersURL("-123,40,-121,41", 9))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, model.Data.List)
+
+	total := 0
+	for _, cluster := range model.Data.List {
+		total += cluster.StopCount
+		assert.GreaterOrEqual(t, cluster.Lat, cluster.Bounds.MinLat)
+		assert.LessOrEqual(t, cluster.Lat, cluster.Bounds.MaxLat)
+	}
+	assert.Less(t, len(model.Data.List), total, "stops are grouped at city zoom")
+
+	count, err := api.GtfsManager.GtfsDB.Queries.CountStops(t.Context())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, int64(total), count)
+}
+
+func TestStopClustersHandler_SingleStopHasID(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	stop := testdata.Stop4062
+	bbox := fmt.Sprintf("%f,%f,%f,%f", stop.Lon-0.0001, stop.Lat-0.0001, stop.Lon+0.0001, stop.Lat+0.0001)
+	resp, model := callAPIHandler[StopClustersResponse](t, api, stopClustersURL(bbox, 20))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, model.Data.List, 1)
+	assert.Equal(t, 1, model.Data.List[0].StopCount)
+	assert.Equal(t, stop.ID, model.Data.List[0].StopID)
+}
+
+func TestStopClustersHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name, query, field string
+	}{
+		{"missing bbox", "zoom=10", "bbox"},
+		{"short bbox", "bbox=1,2,3&zoom=10", "bbox"},
+		{"inverted bbox", "bbox=-121,40,-123,41&zoom=10", "bbox"},
+		{"out of range bbox", "bbox=-190,40,-121,41&zoom=10", "bbox"},
+		{"missing zoom", "bbox=-123,40,-121,41", "zoom"},
+		{"zoom too high", "bbox=-123,40,-121,41&zoom=23", "zoom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A fresh API per case keeps the requests under the test rate limit.
+			api := createTestApi(t)
+			defer api.Shutdown()
+
+			resp, model := serveApiAndRetrieveEndpoint(t, api, "/api/where/stop-clusters.json?key=TEST&"+tt.query)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			fieldErrors := model.Data.(map[string]any)["fieldErrors"].(map[string]any)
+			assert.Contains(t, fieldErrors, tt.field)
+		})
+	}
+}
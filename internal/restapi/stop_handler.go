@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"sort"
 
+	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
@@ -86,6 +87,14 @@ func (api *RestAPI) stopHandler(w http.ResponseWriter, r *http.Request) {
 		Parent:             parentID,
 	}
 
+	bookingRules, err := api.GtfsManager.GtfsDB.Queries.GetBookingRulesForStop(ctx, stop.ID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	stopData.BookingRules = models.NewBookingRulesFromDB(bookingRules)
+	stopData.Amenities = api.GtfsManager.GetAmenitiesForStop(stop.ID)
+
 	// Initialize empty references struct
 	references := models.NewEmptyReferences()
 
@@ -102,16 +111,18 @@ func (api *RestAPI) stopHandler(w http.ResponseWriter, r *http.Request) {
 			// GetRoutesForStop is already DISTINCT, so there are no duplicates here.
 			// This primarily seeds the dedup set for the parent station routes below.
 			if !uniqueRouteIDs[combinedRouteID] {
-				routeModel := models.NewRoute(
-					combinedRouteID,
-					route.AgencyID,
-					route.ShortName.String,
-					route.LongName.String,
-					route.Desc.String,
-					models.RouteType(route.Type),
-					route.Url.String,
-					route.Color.String,
-					route.TextColor.String)
+				routeModel := utils.RouteFromDatabase(route.AgencyID, gtfsdb.Route{
+					ID:        route.ID,
+					AgencyID:  route.AgencyID,
+					ShortName: route.ShortName,
+					LongName:  route.LongName,
+					Desc:      route.Desc,
+					Type:      route.Type,
+					Url:       route.Url,
+					Color:     route.Color,
+					TextColor: route.TextColor,
+					SortOrder: route.SortOrder,
+				})
 
 				references.Routes = append(references.Routes, routeModel)
 				uniqueRouteIDs[combinedRouteID] = true
@@ -129,16 +140,7 @@ func (api *RestAPI) stopHandler(w http.ResponseWriter, r *http.Request) {
 			for combinedRouteID, pr := range parentRoutesMap {
 				// Only add if we haven't seen this route yet (Deduplication)
 				if !uniqueRouteIDs[combinedRouteID] {
-					routeModel := models.NewRoute(
-						combinedRouteID,
-						pr.AgencyID,
-						pr.ShortName.String,
-						pr.LongName.String,
-						pr.Desc.String,
-						models.RouteType(pr.Type),
-						pr.Url.String,
-						pr.Color.String,
-						pr.TextColor.String)
+					routeModel := utils.RouteFromDatabase(pr.AgencyID, routeFromStopsRow(pr))
 					references.Routes = append(references.Routes, routeModel)
 					uniqueRouteIDs[combinedRouteID] = true
 					uniqueAgencyIDs[pr.AgencyID] = true
@@ -169,6 +171,10 @@ func (api *RestAPI) stopHandler(w http.ResponseWriter, r *http.Request) {
 			// Use the existing helper to map the database row to the model
 			references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&agency))
 		}
+
+		situations := newSituationSet(requestLanguage(r))
+		situations.add(agencyID, api.GtfsManager.GetAlertsForStop(stop.ID)...)
+		references.Situations = append(references.Situations, situations.references()...)
 	}
 
 	response := models.NewEntryResponse(stopData, *references, api.Clock)
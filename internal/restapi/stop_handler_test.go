@@ -52,7 +52,7 @@ func TestStopHandlerEndToEnd(t *testing.T) {
 	assert.Equal(t, []models.AgencyReference{testdata.Raba}, model.Data.References.Agencies)
 	assert.Empty(t, model.Data.References.Trips, "trips should always be empty for this endpoint")
 	assert.Empty(t, model.Data.References.StopTimes, "stopTimes should always be empty for this endpoint")
-	assert.Empty(t, model.Data.References.Situations, "situations should always be empty for this endpoint")
+	assert.Empty(t, model.Data.References.Situations, "no alerts affect this stop")
 }
 
 func TestStopHandler_NotFoundAndMalformed(t *testing.T) {
@@ -670,3 +670,52 @@ func TestStopHandler_WrongAgency(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, respInvalid.StatusCode)
 	assert.Equal(t, http.StatusNotFound, modelInvalid.Code)
 }
+
+// insertTestFlexService adds a GTFS-Flex booking rule served at stopID on
+// routeID (raw, uncombined IDs) to the shared test database, removing it when
+// the test ends.
+func insertTestFlexService(t *testing.T, api *RestAPI, routeID, stopID string) {
+	t.Helper()
+	ctx := context.Background()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	require.NoError(t, queries.CreateBookingRule(ctx, gtfsdb.CreateBookingRuleParams{
+		ID:                     "test_flex_rule",
+		BookingType:            1,
+		PriorNoticeDurationMin: nulls.Int64(30),
+		PhoneNumber:            nulls.String("555-0100"),
+	}))
+	require.NoError(t, queries.CreateFlexStopTime(ctx, gtfsdb.CreateFlexStopTimeParams{
+		TripID:              "test_flex_trip",
+		StopSequence:        1,
+		RouteID:             routeID,
+		StopID:              nulls.String(stopID),
+		PickupBookingRuleID: nulls.String("test_flex_rule"),
+	}))
+	t.Cleanup(func() {
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(), `DELETE FROM flex_stop_times WHERE trip_id = ?`, "test_flex_trip")
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(), `DELETE FROM booking_rules WHERE id = ?`, "test_flex_rule")
+	})
+}
+
+func TestStopHandler_FlexBookingRules(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	_, model := callAPIHandler[StopEntryResponse](t, api, stopURL(testdata.Stop4062.ID))
+	assert.Empty(t, model.Data.Entry.BookingRules, "fixed-route stops have no booking rules")
+
+	_, stopID, err := utils.ExtractAgencyIDAndCodeID(testdata.Stop4062.ID)
+	require.NoError(t, err)
+	insertTestFlexService(t, api, "test_flex_route", stopID)
+
+	resp, model := callAPIHandler[StopEntryResponse](t, api, stopURL(testdata.Stop4062.ID))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, model.Data.Entry.BookingRules, 1)
+	rule := model.Data.Entry.BookingRules[0]
+	assert.Equal(t, "test_flex_rule", rule.ID)
+	assert.Equal(t, 1, rule.BookingType)
+	require.NotNil(t, rule.PriorNoticeDurationMin)
+	assert.Equal(t, int64(30), *rule.PriorNoticeDurationMin)
+	assert.Equal(t, "555-0100", rule.PhoneNumber)
+}
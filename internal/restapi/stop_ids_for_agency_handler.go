@@ -44,6 +44,7 @@ func (api *RestAPI) stopIDsForAgencyHandler(w http.ResponseWriter, r *http.Reque
 		response = append(response, utils.FormCombinedID(id, stopID))
 	}
 
+	response = api.suppression().filterStopIDs(response)
 	api.sendResponse(w, r, models.NewListResponse(response, *models.NewEmptyReferences(), false, api.Clock))
 
 }
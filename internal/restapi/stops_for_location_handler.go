@@ -2,10 +2,10 @@ package restapi
 
 import (
 	"cmp"
+	"context"
 	"fmt"
 	"net/http"
 	"slices"
-	"strings"
 	"time"
 
 	"maglev.onebusaway.org/gtfsdb"
@@ -19,45 +19,16 @@ import (
 func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Request) {
 	queryParams := r.URL.Query()
 
-	var fieldErrors map[string][]string
+	var params struct {
+		IncludeInactive bool `query:"includeInactive"`
+	}
+	fieldErrors := bindQuery(queryParams, &params, nil)
+	search := api.searchConfig()
 	loc, fieldErrors := api.parseLocationParams(r, fieldErrors)
-	maxCount, fieldErrors := utils.ParseMaxCount(queryParams, models.DefaultMaxCountForStops, fieldErrors)
+	maxCount, fieldErrors := utils.ParseMaxCountWithLimit(queryParams, search.DefaultMaxCountStops, search.MaxCount, fieldErrors)
 	query := queryParams.Get("query")
 
-	var routeTypes []int
-	if routeTypeStr := queryParams.Get("routeType"); routeTypeStr != "" {
-		routeTypeStrs := strings.Split(routeTypeStr, ",")
-
-		const maxRouteTypeTokens = 100
-
-		if len(routeTypeStrs) > maxRouteTypeTokens {
-			if fieldErrors == nil {
-				fieldErrors = make(map[string][]string)
-			}
-			fieldErrors["routeType"] = []string{
-				fmt.Sprintf("too many route types (maximum %d allowed)", maxRouteTypeTokens),
-			}
-		} else {
-			for _, rtStr := range routeTypeStrs {
-				rtStr = strings.TrimSpace(rtStr)
-				if rtStr != "" {
-					var rt int
-					if _, err := fmt.Sscanf(rtStr, "%d", &rt); err != nil {
-						if fieldErrors == nil {
-							fieldErrors = make(map[string][]string)
-						}
-						if _, exists := fieldErrors["routeType"]; !exists {
-							fieldErrors["routeType"] = []string{
-								`Invalid field value for field "routeType".`,
-							}
-						}
-					} else {
-						routeTypes = append(routeTypes, rt)
-					}
-				}
-			}
-		}
-	}
+	routeTypes, fieldErrors := parseRouteTypeFilter(queryParams, fieldErrors)
 
 	queryTime := api.Clock.Now()
 
@@ -86,6 +57,12 @@ func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Reque
 	}
 	query = sanitizedQuery
 
+	// A radius, when given, takes precedence over spans.
+	if loc.Radius == 0 && (loc.LatSpan == 0 || loc.LonSpan == 0) {
+		loc.Radius = search.DefaultRadius
+	}
+	loc.Radius = min(loc.Radius, search.MaxRadius)
+
 	ctx := r.Context()
 
 	// Check if context is already cancelled
@@ -190,6 +167,27 @@ func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Reque
 		routeIDs[routeIDStr] = true
 	}
 
+	// Stops without service on the query date are normally hidden; when the caller
+	// opts in, describe them with every route that serves them on any date instead.
+	var inactiveStopRouteIDs map[string][]string
+	if params.IncludeInactive {
+		inactiveStopRouteIDs, err = api.staticRouteIDsForStops(ctx, stopsWithoutRoutes(stopIDs, stopRouteIDs))
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		for _, rids := range inactiveStopRouteIDs {
+			for _, routeIDStr := range rids {
+				agencyId, _, err := utils.ExtractAgencyIDAndCodeID(routeIDStr)
+				if err != nil {
+					continue
+				}
+				agencyIDs[agencyId] = true
+				routeIDs[routeIDStr] = true
+			}
+		}
+	}
+
 	// Group agencies by stop (take the first agency for each stop)
 	for _, agencyRow := range agenciesForStops {
 		stopID := agencyRow.StopID
@@ -211,8 +209,12 @@ func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Reque
 		stop := stopMap[stopID]
 		rids := stopRouteIDs[stopID]
 		agency := stopAgency[stopID]
+		activeToday := len(rids) > 0
+		if !activeToday {
+			rids = inactiveStopRouteIDs[stopID]
+		}
 
-		if len(rids) == 0 || agency == nil {
+		if len(rids) == 0 || agency == nil || !api.inRequestRegion(ctx, agency.ID) {
 			continue
 		}
 
@@ -220,7 +222,7 @@ func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Reque
 
 		direction := api.DirectionCalculator.CalculateStopDirection(ctx, stop.ID, stop.Direction)
 
-		results = append(results, models.NewStop(
+		stopModel := models.NewStop(
 			nulls.StringOrEmpty(stop.Code),
 			direction,
 			utils.FormCombinedID(agency.ID, stop.ID),
@@ -232,7 +234,9 @@ func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Reque
 			0,
 			rids,
 			rids,
-		))
+		)
+		stopModel.ActiveToday = &activeToday
+		results = append(results, stopModel)
 	}
 
 	if ctx.Err() != nil {
@@ -262,3 +266,35 @@ func (api *RestAPI) stopsForLocationHandler(w http.ResponseWriter, r *http.Reque
 	response := models.NewListResponseWithRange(results, *references, api.GtfsManager.CheckIfOutOfBounds(loc), api.Clock, isLimitExceeded)
 	api.sendResponse(w, r, response)
 }
+
+// stopsWithoutRoutes returns the stop IDs that have no entry in routeIDsByStop.
+func stopsWithoutRoutes(stopIDs []string, routeIDsByStop map[string][]string) []string {
+	var missing []string
+	for _, stopID := range stopIDs {
+		if len(routeIDsByStop[stopID]) == 0 {
+			missing = append(missing, stopID)
+		}
+	}
+	return missing
+}
+
+// staticRouteIDsForStops returns the combined IDs of every route serving each stop,
+// regardless of which service dates those routes run on.
+func (api *RestAPI) staticRouteIDsForStops(ctx context.Context, stopIDs []string) (map[string][]string, error) {
+	routeIDsByStop := make(map[string][]string)
+	if len(stopIDs) == 0 {
+		return routeIDsByStop, nil
+	}
+
+	rows, err := api.GtfsManager.GtfsDB.Queries.GetRoutesForStops(ctx, stopIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		routeIDsByStop[row.StopID] = append(routeIDsByStop[row.StopID], utils.FormCombinedID(row.AgencyID, row.ID))
+	}
+	for _, rids := range routeIDsByStop {
+		slices.Sort(rids)
+	}
+	return routeIDsByStop, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/OneBusAway/go-gtfs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
 	"maglev.onebusaway.org/internal/clock"
 	"maglev.onebusaway.org/internal/models"
 )
@@ -135,6 +136,22 @@ func TestStopsForLocationIsLimitExceeded(t *testing.T) {
 	assert.True(t, model.Data.LimitExceeded)
 }
 
+func TestStopsForLocationUsesConfiguredSearchLimits(t *testing.T) {
+	clock := clock.NewMockClock(time.Date(2025, 12, 26, 14, 0, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, clock)
+	cfg := api.CurrentConfig()
+	cfg.Search = appconf.SearchConfig{DefaultRadius: 5000, DefaultMaxCountStops: 2, MaxCount: 3}
+	api.ApplyConfig(cfg)
+
+	resp, model := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&includeInactive=true")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, model.Data.List, 2, "the configured default maxCount applies")
+	assert.True(t, model.Data.LimitExceeded, "the configured default radius reaches more than two stops")
+
+	resp, _ = callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&maxCount=4")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "maxCount is capped at the configured maximum")
+}
+
 func TestStopsForLocationActiveRoutesOnly(t *testing.T) {
 	futureClock := clock.NewMockClock(time.Date(2031, 1, 1, 12, 0, 0, 0, time.UTC))
 	api := createTestApiWithClock(t, futureClock)
@@ -144,6 +161,55 @@ func TestStopsForLocationActiveRoutesOnly(t *testing.T) {
 	assert.Empty(t, model.Data.List, "Should return empty stops when no routes are active")
 }
 
+func TestStopsForLocationIncludeInactive(t *testing.T) {
+	futureClock := clock.NewMockClock(time.Date(2031, 1, 1, 12, 0, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, futureClock)
+
+	resp, model := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&radius=5000&includeInactive=true")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, model.Data.List, "Inactive stops should be returned when includeInactive=true")
+
+	refRouteIDs := make(map[string]bool)
+	for _, route := range model.Data.References.Routes {
+		refRouteIDs[route.ID] = true
+	}
+	for _, stop := range model.Data.List {
+		require.NotNil(t, stop.ActiveToday)
+		assert.False(t, *stop.ActiveToday)
+		assert.NotEmpty(t, stop.RouteIDs)
+		for _, routeID := range stop.RouteIDs {
+			assert.Contains(t, refRouteIDs, routeID)
+		}
+	}
+}
+
+func TestStopsForLocationIncludeInactiveInvalid(t *testing.T) {
+	api := createTestApi(t)
+	resp, model := callAPIHandler[models.ResponseModel](t, api, "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&includeInactive=yes")
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	data, ok := model.Data.(map[string]any)
+	require.True(t, ok, "response data should be a map")
+	fieldErrors, ok := data["fieldErrors"].(map[string]any)
+	require.True(t, ok, "data should contain fieldErrors map")
+	assert.Contains(t, fieldErrors, "includeInactive")
+}
+
+func TestStopsForLocationActiveTodayFlag(t *testing.T) {
+	clock := clock.NewMockClock(time.Date(2025, 12, 26, 14, 0, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, clock)
+
+	_, activeOnly := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&radius=2500")
+	_, withInactive := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&radius=2500&includeInactive=true")
+
+	require.NotEmpty(t, activeOnly.Data.List)
+	for _, stop := range activeOnly.Data.List {
+		require.NotNil(t, stop.ActiveToday)
+		assert.True(t, *stop.ActiveToday)
+	}
+	assert.GreaterOrEqual(t, len(withInactive.Data.List), len(activeOnly.Data.List))
+}
+
 func TestStopsForLocationHandlerValidatesParameters(t *testing.T) {
 	api := createTestApi(t)
 	resp, model := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-location.json?key=TEST&lat=invalid&lon=-121.74")
@@ -274,6 +340,28 @@ func TestStopsForLocationHandlerRouteTypeValidMultiple(t *testing.T) {
 	assert.NotEmpty(t, model.Data.References.Routes)
 }
 
+func TestStopsForLocationHandlerRouteTypes(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2025, 12, 26, 14, 0, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	baseURL := "/api/where/stops-for-location.json?key=TEST&lat=40.583321&lon=-122.426966&radius=2500&routeTypes="
+
+	resp, model := callAPIHandler[StopsResponse](t, api, baseURL+"3")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, model.Data.List, "RABA bus stops should match route type 3")
+
+	resp, model = callAPIHandler[StopsResponse](t, api, baseURL+"1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, model.Data.List, "RABA has no subway routes")
+
+	resp, errModel := callAPIHandler[models.ResponseModel](t, api, baseURL+"3,42")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	data, ok := errModel.Data.(map[string]any)
+	require.True(t, ok)
+	fieldErrors, ok := data["fieldErrors"].(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, fieldErrors, "routeTypes")
+}
+
 func TestStopsForLocationQueryOutOfArea(t *testing.T) {
 	clock := clock.NewMockClock(time.Date(2025, 6, 13, 14, 0, 0, 0, time.UTC))
 	api := createTestApiWithClock(t, clock)
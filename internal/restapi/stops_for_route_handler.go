@@ -5,7 +5,6 @@ import (
 	"context"
 	"net/http"
 	"slices"
-	"time"
 
 	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/models"
@@ -15,26 +14,14 @@ import (
 
 type stopsForRouteParams struct {
 	IncludePolylines bool
-	Time             *time.Time
 }
 
-func (api *RestAPI) parseStopsForRouteParams(r *http.Request) stopsForRouteParams {
-	now := api.Clock.Now()
-	params := stopsForRouteParams{
-		IncludePolylines: true,
-		Time:             &now,
+// parseStopsForRouteParams reads the optional query parameters. The time
+// parameter is validated by the handler, which needs the agency's timezone.
+func parseStopsForRouteParams(r *http.Request) stopsForRouteParams {
+	return stopsForRouteParams{
+		IncludePolylines: r.URL.Query().Get("includePolylines") != "false",
 	}
-
-	if r.URL.Query().Get("includePolylines") == "false" {
-		params.IncludePolylines = false
-	}
-
-	if timeParam := r.URL.Query().Get("time"); timeParam != "" {
-		if t, err := time.Parse(time.RFC3339, timeParam); err == nil {
-			params.Time = &t
-		}
-	}
-	return params
 }
 
 // stopsForRouteHandler returns all stops served by a route, grouped by direction
@@ -53,7 +40,7 @@ func (api *RestAPI) stopsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	params := api.parseStopsForRouteParams(r)
+	params := parseStopsForRouteParams(r)
 
 	currentAgency, err := api.GtfsManager.GtfsDB.Queries.GetAgency(ctx, agencyID)
 	if err != nil {
@@ -87,8 +74,10 @@ func (api *RestAPI) stopsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	_, err = api.GtfsManager.GtfsDB.Queries.GetRoute(ctx, routeID)
-	if err != nil {
+	// The route must belong to the agency named in the ID; another agency's
+	// route would otherwise be reported under the wrong agency prefix.
+	route, err := api.GtfsManager.GtfsDB.Queries.GetRoute(ctx, routeID)
+	if err != nil || route.AgencyID != agencyID {
 		api.sendNotFound(w, r)
 		return
 	}
@@ -226,6 +226,13 @@ func TestStopsForRouteHandlerNonExistentAgency(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
+func TestStopsForRouteHandlerRouteOfAnotherAgency(t *testing.T) {
+	api := createTestApiWithNullDirectionID(t)
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/stops-for-route/agencyB_routeA.json?key=TEST")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
 func TestStopsForRouteHandlerWithInvalidTimeFormats(t *testing.T) {
 	invalidFormats := []string{
 		"yesterday",       // Relative time
@@ -265,8 +272,10 @@ func createTestApiWithNullDirectionID(t *testing.T) *RestAPI {
 	w := zip.NewWriter(&buf)
 
 	files := map[string]string{
+		// agencyB runs no routes; it is only used as a mismatched agency prefix.
 		"agency.txt": "agency_id,agency_name,agency_url,agency_timezone\n" +
-			"agencyA,Test Agency,http://example.com,America/Los_Angeles\n",
+			"agencyA,Test Agency,http://example.com,America/Los_Angeles\n" +
+			"agencyB,Other Agency,http://example.com,America/Los_Angeles\n",
 		"routes.txt": "route_id,agency_id,route_short_name,route_long_name,route_type\n" +
 			"routeA,agencyA,RA,Route A,3\n",
 		"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
@@ -0,0 +1,168 @@
+package restapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// streamPath is the realtime stream endpoint, which load shedding and
+	// SLO tracking leave out of their latency measurements like long polls.
+	streamPath = "/api/where/stream"
+	// maxStreamTopics caps the stops, routes and vehicles one stream follows.
+	maxStreamTopics = 20
+	// streamKeepAlive is how often an idle stream sends a comment, so that
+	// proxies do not take it for a dead connection.
+	streamKeepAlive = 15 * time.Second
+	// streamWriteTimeout bounds each write to a stream.
+	streamWriteTimeout = 10 * time.Second
+)
+
+// streamTopic is a stop, route or vehicle a stream follows. Its events are
+// the responses of the endpoint that serves it on its own.
+type streamTopic struct {
+	event   string
+	id      string
+	path    string
+	handler func(http.ResponseWriter, *http.Request)
+	// etag is the ETag of the last event sent, for endpoints that set one.
+	etag string
+}
+
+// streamHandler pushes realtime updates as server-sent events, so clients
+// need not poll. stopIds, routeIds and vehicleIds list, comma separated, what
+// to follow. Each is sent as a "stop", "route" or "vehicle" event whose data
+// is the response of arrivals-and-departures-for-stop, trips-for-route or
+// trip-for-vehicle for it, once when the stream opens and again after every
+// merge of the realtime feeds. Arrivals are only sent again when their ETag
+// changes. A topic that cannot be served when the stream opens fails the
+// request with that endpoint's error instead.
+func (api *RestAPI) streamHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var topics []*streamTopic
+	addTopics := func(param, event, prefix string, handler func(http.ResponseWriter, *http.Request)) {
+		for id := range strings.SplitSeq(query.Get(param), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				topics = append(topics, &streamTopic{event: event, id: id, path: prefix + id, handler: handler})
+			}
+		}
+	}
+	addTopics("stopIds", "stop", "/api/where/arrivals-and-departures-for-stop/",
+		pinnedDataset(api, unlessStopSuppressed(api, coalesced(api, api.arrivalsAndDeparturesForStopHandler))))
+	addTopics("routeIds", "route", "/api/where/trips-for-route/",
+		pinnedDataset(api, unlessRouteSuppressed(api, api.tripsForRouteHandler)))
+	addTopics("vehicleIds", "vehicle", "/api/where/trip-for-vehicle/", pinnedDataset(api, api.tripForVehicleHandler))
+
+	var fieldErrors map[string][]string
+	if len(topics) == 0 {
+		fieldErrors = addFieldError(fieldErrors, "stopIds", "missingRequiredField")
+	} else if len(topics) > maxStreamTopics {
+		fieldErrors = addFieldError(fieldErrors, "stopIds", fmt.Sprintf("at most %d stops, routes and vehicles can be followed", maxStreamTopics))
+	}
+	if len(fieldErrors) > 0 {
+		api.validationErrorResponse(w, r, fieldErrors)
+		return
+	}
+
+	// Subscribe first so a merge while the first events are built is not
+	// missed.
+	changed := api.GtfsManager.RealtimeChanged()
+	first := make([]*coalescingRecorder, len(topics))
+	for i, topic := range topics {
+		rec := api.streamTopicResponse(r, topic)
+		if rec.status != http.StatusOK {
+			rec.replay(w)
+			return
+		}
+		first[i] = rec
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	for i, topic := range topics {
+		if !sendStreamEvent(w, rc, topic, first[i]) {
+			return
+		}
+	}
+	if !streamFlush(rc) {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-changed:
+			changed = api.GtfsManager.RealtimeChanged()
+			for _, topic := range topics {
+				rec := api.streamTopicResponse(r, topic)
+				if rec.status != http.StatusOK {
+					continue
+				}
+				if !sendStreamEvent(w, rc, topic, rec) {
+					return
+				}
+			}
+		case <-keepAlive.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if !streamFlush(rc) {
+			return
+		}
+	}
+}
+
+// streamTopicResponse serves topic as its own endpoint would, with that
+// endpoint's default parameters.
+func (api *RestAPI) streamTopicResponse(r *http.Request, topic *streamTopic) *coalescingRecorder {
+	req := r.Clone(r.Context())
+	req.URL = &url.URL{Path: topic.path}
+	req.RequestURI = topic.path
+	req.SetPathValue("id", topic.id)
+	rec := &coalescingRecorder{header: make(http.Header)}
+	topic.handler(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec
+}
+
+// sendStreamEvent writes rec as an event of topic, unless its ETag shows
+// the client already has it. It reports false when the client is gone.
+func sendStreamEvent(w http.ResponseWriter, rc *http.ResponseController, topic *streamTopic, rec *coalescingRecorder) bool {
+	if etag := rec.header.Get("ETag"); etag != "" {
+		if etag == topic.etag {
+			return true
+		}
+		topic.etag = etag
+	}
+	_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	var buf bytes.Buffer
+	buf.WriteString("event: " + topic.event + "\n")
+	// Data lines cannot hold a newline, and the JSON encoder ends the body
+	// with one.
+	for line := range strings.SplitSeq(strings.TrimRight(rec.body.String(), "\n"), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err == nil
+}
+
+// streamFlush sends what has been written to the client, and reports false
+// when it cannot be reached.
+func streamFlush(rc *http.ResponseController) bool {
+	return rc.Flush() == nil
+}
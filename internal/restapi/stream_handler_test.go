@@ -0,0 +1,110 @@
+package restapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+)
+
+func streamURL(params url.Values) string {
+	params.Set("key", "TEST")
+	return streamPath + "?" + params.Encode()
+}
+
+// readStreamEvent reads the next event of a server-sent event stream,
+// skipping comments.
+func readStreamEvent(t *testing.T, reader *bufio.Reader) (event string, data string) {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, strings.Join(lines, "\n")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			lines = append(lines, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2010, 1, 1, 8, 2, 0, 0, time.UTC))
+	api := createTestApiWithClock(t, mockClock)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	_, combinedStopID, tripID, _ := setupDelayPropTestData(t, api, 1)
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+streamURL(url.Values{"stopIds": {combinedStopID}}), nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	decode := func(data string) ArrivalsAndDeparturesResponse {
+		t.Helper()
+		var model ArrivalsAndDeparturesResponse
+		require.NoError(t, json.Unmarshal([]byte(data), &model))
+		return model
+	}
+
+	event, data := readStreamEvent(t, reader)
+	assert.Equal(t, "stop", event)
+	first := decode(data)
+	assert.Equal(t, combinedStopID, first.Data.Entry.StopID)
+
+	delay := 3 * time.Minute
+	api.GtfsManager.MockAddVehicle("v1", tripID, "dp-route")
+	api.GtfsManager.MockAddTripUpdate(tripID, &delay, nil)
+
+	event, data = readStreamEvent(t, reader)
+	assert.Equal(t, "stop", event)
+	updated := decode(data)
+	assert.Equal(t, combinedStopID, updated.Data.Entry.StopID)
+	assert.NotEqual(t, first.Data.Entry.ArrivalsAndDepartures, updated.Data.Entry.ArrivalsAndDepartures)
+}
+
+func TestStreamHandlerErrors(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	tooMany := make([]string, maxStreamTopics+1)
+	for i := range tooMany {
+		tooMany[i] = "25_" + string(rune('a'+i))
+	}
+	tests := []struct {
+		name   string
+		params url.Values
+		want   int
+	}{
+		{"nothing to follow", url.Values{}, http.StatusBadRequest},
+		{"too many topics", url.Values{"stopIds": {strings.Join(tooMany, ",")}}, http.StatusBadRequest},
+		{"unknown stop", url.Values{"stopIds": {"25_missing"}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := serveApiAndRetrieveEndpoint(t, api, streamURL(tt.params))
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package restapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// suppressionList is the set of stops and routes hidden from public
+// responses, keyed by combined ID. A nil list hides nothing.
+type suppressionList struct {
+	stops  map[string]struct{}
+	routes map[string]struct{}
+	// version changes whenever the lists do, so responses cached or
+	// validated against the static ETag are refreshed; see staticETag.
+	version string
+}
+
+// newSuppressionList builds the list of cfg, or returns nil when it hides
+// nothing.
+func newSuppressionList(cfg appconf.SuppressionConfig) *suppressionList {
+	if len(cfg.StopIDs) == 0 && len(cfg.RouteIDs) == 0 {
+		return nil
+	}
+	list := &suppressionList{
+		stops:  make(map[string]struct{}, len(cfg.StopIDs)),
+		routes: make(map[string]struct{}, len(cfg.RouteIDs)),
+	}
+	for _, id := range cfg.StopIDs {
+		list.stops[id] = struct{}{}
+	}
+	for _, id := range cfg.RouteIDs {
+		list.routes[id] = struct{}{}
+	}
+
+	stops, routes := slices.Sorted(maps.Keys(list.stops)), slices.Sorted(maps.Keys(list.routes))
+	sum := sha256.Sum256([]byte(strings.Join(stops, ",") + "|" + strings.Join(routes, ",")))
+	list.version = hex.EncodeToString(sum[:4])
+	return list
+}
+
+func (l *suppressionList) stopHidden(id string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.stops[id]
+	return ok
+}
+
+func (l *suppressionList) routeHidden(id string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.routes[id]
+	return ok
+}
+
+// suppression returns the suppression list in effect.
+func (api *RestAPI) suppression() *suppressionList {
+	return api.suppressed.Load()
+}
+
+// staticETag is the ETag of responses built from static data: the system
+// ETag of the feed, qualified by the suppression list in effect so hiding or
+// restoring a stop or route invalidates them too.
+func (api *RestAPI) staticETag(ctx context.Context) string {
+	if api.GtfsManager == nil {
+		return ""
+	}
+	etag := api.GtfsManager.GetSystemETag(ctx)
+	if list := api.suppression(); etag != "" && list != nil {
+		etag += "-" + list.version
+	}
+	return etag
+}
+
+// unlessStopSuppressed answers 404 for a suppressed stop instead of running
+// handler, for endpoints whose {id} is a combined stop ID.
+func unlessStopSuppressed(api *RestAPI, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.suppression().stopHidden(utils.ExtractIDFromParams(r)) {
+			api.sendNotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// unlessRouteSuppressed answers 404 for a suppressed route instead of running
+// handler, for endpoints whose {id} is a combined route ID.
+func unlessRouteSuppressed(api *RestAPI, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.suppression().routeHidden(utils.ExtractIDFromParams(r)) {
+			api.sendNotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// without returns items minus those hidden reports, leaving items itself
+// untouched since handlers may share it.
+func without[T any](items []T, hidden func(T) bool) []T {
+	if !slices.ContainsFunc(items, hidden) {
+		return items
+	}
+	kept := make([]T, 0, len(items))
+	for _, item := range items {
+		if !hidden(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// filterStops drops suppressed stops, and suppressed routes from the route
+// IDs of the stops that remain.
+func (l *suppressionList) filterStops(stops []models.Stop) []models.Stop {
+	if l == nil || stops == nil {
+		return stops
+	}
+	kept := make([]models.Stop, 0, len(stops))
+	for _, stop := range stops {
+		if l.stopHidden(stop.ID) {
+			continue
+		}
+		stop.RouteIDs = without(stop.RouteIDs, l.routeHidden)
+		stop.StaticRouteIDs = without(stop.StaticRouteIDs, l.routeHidden)
+		kept = append(kept, stop)
+	}
+	return kept
+}
+
+func (l *suppressionList) filterRoutes(routes []models.Route) []models.Route {
+	if l == nil {
+		return routes
+	}
+	return without(routes, func(route models.Route) bool { return l.routeHidden(route.ID) })
+}
+
+func (l *suppressionList) filterStopIDs(ids []string) []string {
+	if l == nil {
+		return ids
+	}
+	return without(ids, l.stopHidden)
+}
+
+func (l *suppressionList) filterRouteIDs(ids []string) []string {
+	if l == nil {
+		return ids
+	}
+	return without(ids, l.routeHidden)
+}
+
+// filterRouteEntry drops suppressed stops from a stops-for-route entry and
+// its stop groups.
+func (l *suppressionList) filterRouteEntry(entry models.RouteEntry) models.RouteEntry {
+	entry.StopIds = l.filterStopIDs(entry.StopIds)
+	if entry.StopGroupings == nil {
+		return entry
+	}
+	groupings := make([]models.StopGrouping, len(entry.StopGroupings))
+	for i, grouping := range entry.StopGroupings {
+		if grouping.StopGroups != nil {
+			groups := make([]models.StopGroup, len(grouping.StopGroups))
+			for j, group := range grouping.StopGroups {
+				group.StopIds = l.filterStopIDs(group.StopIds)
+				groups[j] = group
+			}
+			grouping.StopGroups = groups
+		}
+		groupings[i] = grouping
+	}
+	entry.StopGroupings = groupings
+	return entry
+}
+
+// hideSuppressed removes suppressed stops and routes from a response's list,
+// stops-for-route entry and references. Endpoints about a single stop or
+// route are refused before they run by unlessStopSuppressed and
+// unlessRouteSuppressed instead.
+func (l *suppressionList) hideSuppressed(data map[string]any) {
+	if l == nil {
+		return
+	}
+	switch list := data["list"].(type) {
+	case []models.Stop:
+		data["list"] = l.filterStops(list)
+	case []models.Route:
+		data["list"] = l.filterRoutes(list)
+	}
+	if entry, ok := data["entry"].(models.RouteEntry); ok {
+		data["entry"] = l.filterRouteEntry(entry)
+	}
+	if references, ok := data["references"].(models.ReferencesModel); ok {
+		references.Stops = l.filterStops(references.Stops)
+		references.Routes = l.filterRoutes(references.Routes)
+		data["references"] = references
+	}
+}
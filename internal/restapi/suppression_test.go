@@ -0,0 +1,112 @@
+package restapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestNewSuppressionList(t *testing.T) {
+	assert.Nil(t, newSuppressionList(appconf.SuppressionConfig{}))
+
+	a := newSuppressionList(appconf.SuppressionConfig{StopIDs: []string{"1_2", "1_1"}, RouteIDs: []string{"1_9"}})
+	b := newSuppressionList(appconf.SuppressionConfig{StopIDs: []string{"1_1", "1_2"}, RouteIDs: []string{"1_9"}})
+	c := newSuppressionList(appconf.SuppressionConfig{StopIDs: []string{"1_1"}, RouteIDs: []string{"1_2", "1_9"}})
+	assert.Equal(t, a.version, b.version, "the order of the IDs must not matter")
+	assert.NotEqual(t, a.version, c.version)
+
+	assert.True(t, a.stopHidden("1_1"))
+	assert.False(t, a.stopHidden("1_9"))
+	assert.True(t, a.routeHidden("1_9"))
+
+	var none *suppressionList
+	assert.False(t, none.stopHidden("1_1"))
+	assert.Equal(t, []string{"1_1"}, none.filterStopIDs([]string{"1_1"}))
+}
+
+func TestHideSuppressed(t *testing.T) {
+	list := newSuppressionList(appconf.SuppressionConfig{StopIDs: []string{"1_2"}, RouteIDs: []string{"1_20"}})
+	stops := []models.Stop{
+		{ID: "1_1", RouteIDs: []string{"1_10", "1_20"}},
+		{ID: "1_2", RouteIDs: []string{"1_10"}},
+	}
+	references := models.ReferencesModel{
+		Stops:  stops,
+		Routes: []models.Route{{ID: "1_10"}, {ID: "1_20"}},
+	}
+	data := map[string]any{
+		"list":       stops,
+		"references": references,
+	}
+
+	list.hideSuppressed(data)
+
+	kept := data["list"].([]models.Stop)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "1_1", kept[0].ID)
+	assert.Equal(t, []string{"1_10"}, kept[0].RouteIDs)
+	assert.Len(t, stops, 2, "the handler's slice must be left untouched")
+	assert.Equal(t, []string{"1_10", "1_20"}, stops[0].RouteIDs)
+
+	filtered := data["references"].(models.ReferencesModel)
+	assert.Len(t, filtered.Stops, 1)
+	require.Len(t, filtered.Routes, 1)
+	assert.Equal(t, "1_10", filtered.Routes[0].ID)
+}
+
+func TestHideSuppressed_StopsForRouteEntry(t *testing.T) {
+	list := newSuppressionList(appconf.SuppressionConfig{StopIDs: []string{"1_2"}})
+	data := map[string]any{
+		"entry": models.RouteEntry{
+			StopIds: []string{"1_1", "1_2"},
+			StopGroupings: []models.StopGrouping{{
+				StopGroups: []models.StopGroup{{StopIds: []string{"1_2", "1_3"}}},
+			}},
+		},
+	}
+
+	list.hideSuppressed(data)
+
+	entry := data["entry"].(models.RouteEntry)
+	assert.Equal(t, []string{"1_1"}, entry.StopIds)
+	assert.Equal(t, []string{"1_3"}, entry.StopGroupings[0].StopGroups[0].StopIds)
+}
+
+func TestSuppressedStopIsHiddenThroughMux(t *testing.T) {
+	// The exempt key keeps the repeated requests clear of the test rate limit
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	_, ids := callAPIHandler[StopIDsForAgencyResponse](t, api, stopIdsForAgencyURL(testdata.Raba.ID, url.Values{"key": {"org.onebusaway.iphone"}}))
+	require.NotEmpty(t, ids.Data.List)
+	stopID := ids.Data.List[0]
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/stop/"+stopID+".json?key=org.onebusaway.iphone")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+
+	api.suppressed.Store(newSuppressionList(appconf.SuppressionConfig{StopIDs: []string{stopID}}))
+
+	resp, model := serveApiAndRetrieveEndpoint(t, api, "/api/where/stop/"+stopID+".json?key=org.onebusaway.iphone")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, model.Code)
+
+	_, ids = callAPIHandler[StopIDsForAgencyResponse](t, api, stopIdsForAgencyURL(testdata.Raba.ID, url.Values{"key": {"org.onebusaway.iphone"}}))
+	assert.NotContains(t, ids.Data.List, stopID, "a cached list from before the change must not be served")
+
+	_, stops := callAPIHandler[StopsResponse](t, api, "/api/where/stops-for-agency/"+testdata.Raba.ID+".json?key=org.onebusaway.iphone")
+	require.NotEmpty(t, stops.Data.List)
+	for _, stop := range stops.Data.List {
+		assert.NotEqual(t, stopID, stop.ID)
+	}
+
+	resp, _ = serveApiAndRetrieveEndpoint(t, api, "/api/where/stop/"+ids.Data.List[0]+".json?key=org.onebusaway.iphone")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"), "changing the list must change the static ETag")
+}
@@ -0,0 +1,90 @@
+package restapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/nulls"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// transfersForStopHandler returns the transfers from a stop: the feed's
+// transfers.txt rows, or the transfers generated between nearby stops when
+// the feed has none and transfer-radius-meters is set. Transfers the feed
+// gives for the stop's parent station apply to the stop too, unless the stop
+// has its own transfer to the same stop.
+func (api *RestAPI) transfersForStopHandler(w http.ResponseWriter, r *http.Request) {
+	agencyID, stopID, ok := api.extractAndValidateAgencyCodeID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	queries := api.GtfsManager.GtfsDB.Queries
+
+	stop, err := queries.GetStop(ctx, stopID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.sendNotFound(w, r)
+			return
+		}
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+
+	rows, err := queries.GetTransfersFromStop(ctx, stopID)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	if parent := nulls.StringOrEmpty(stop.ParentStation); parent != "" {
+		stationRows, err := queries.GetTransfersFromStop(ctx, parent)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		own := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			own[row.ToStopID] = true
+		}
+		for _, row := range stationRows {
+			if !own[row.ToStopID] {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	combinedID := func(id string) string { return utils.FormCombinedID(agencyID, id) }
+	suppressed := api.suppressed.Load()
+	transfers := make([]models.Transfer, 0, len(rows))
+	stopIDs := []string{stopID}
+	for _, row := range rows {
+		transfer := models.NewTransferFromDB(row, combinedID)
+		if suppressed.stopHidden(transfer.ToStopID) {
+			continue
+		}
+		transfers = append(transfers, transfer)
+		stopIDs = append(stopIDs, row.FromStopID, row.ToStopID)
+	}
+
+	references := models.NewEmptyReferences()
+	if ShouldIncludeReferences(r) {
+		stops, uniqueRouteMap, err := BuildStopReferencesAndRouteIDsForStops(api, ctx, agencyID, stopIDs)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		references.Stops = stops
+
+		routeRefs := make(map[string]models.Route, len(uniqueRouteMap))
+		for combinedID, route := range uniqueRouteMap {
+			routeRefs[combinedID] = utils.RouteFromDatabase(agencyID, routeFromStopsRow(route))
+		}
+		references.Routes = utils.MapValues(routeRefs)
+	}
+
+	response := models.NewListResponse(transfers, *references, false, api.Clock)
+	api.sendResponse(w, r, response)
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfersForStopHandler(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	// RABA's transfers.txt only has transfers from a stop to itself, which
+	// go-gtfs skips, and the test manager does not generate any.
+	resp, model := callAPIHandler[TransfersForStopResponse](t, api, "/api/where/transfers-for-stop/25_1000.json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, model.Data.List)
+	require.Len(t, model.Data.References.Stops, 1)
+	assert.Equal(t, "25_1000", model.Data.References.Stops[0].ID)
+}
+
+func TestTransfersForStopHandler_Generated(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+
+	_, err := api.GtfsManager.GtfsDB.DB.ExecContext(ctx, `INSERT INTO transfers (from_stop_id, to_stop_id, transfer_type, min_transfer_time, generated, distance) VALUES ('1001', '1000', 2, 191, 1, 267.1)`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = api.GtfsManager.GtfsDB.DB.ExecContext(context.Background(), `DELETE FROM transfers WHERE generated = 1`)
+	})
+
+	_, model := callAPIHandler[TransfersForStopResponse](t, api, "/api/where/transfers-for-stop/25_1001.json?key=TEST")
+	require.Len(t, model.Data.List, 1)
+	transfer := model.Data.List[0]
+	assert.Equal(t, "25_1000", transfer.ToStopID)
+	assert.Equal(t, 2, transfer.TransferType)
+	assert.True(t, transfer.Generated)
+	require.NotNil(t, transfer.MinTransferTime)
+	assert.Equal(t, int64(191), *transfer.MinTransferTime)
+	require.NotNil(t, transfer.Distance)
+	assert.InDelta(t, 267.1, *transfer.Distance, 0.01)
+	assert.Len(t, model.Data.References.Stops, 2)
+	assert.NotEmpty(t, model.Data.References.Routes)
+}
+
+func TestTransfersForStopHandler_ParentStation(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	ctx := context.Background()
+	db := api.GtfsManager.GtfsDB.DB
+
+	// Treat stop 1002 as the station of stop 1001. The station's transfer to
+	// 1003 applies to 1001, while 1001's own transfer to 1000 replaces the
+	// station's.
+	_, err := db.ExecContext(ctx, `UPDATE stops SET parent_station = '1002' WHERE id = '1001'`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO transfers (from_stop_id, to_stop_id, transfer_type, min_transfer_time, generated) VALUES
+		('1002', '1003', 2, 120, 0), ('1002', '1000', 2, 300, 0), ('1001', '1000', 1, NULL, 0)`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM transfers WHERE from_stop_id IN ('1001', '1002')`)
+		_, _ = db.ExecContext(context.Background(), `UPDATE stops SET parent_station = NULL WHERE id = '1001'`)
+	})
+
+	_, model := callAPIHandler[TransfersForStopResponse](t, api, "/api/where/transfers-for-stop/25_1001.json?key=TEST")
+	require.Len(t, model.Data.List, 2)
+	assert.Equal(t, "25_1001", model.Data.List[0].FromStopID)
+	assert.Equal(t, "25_1000", model.Data.List[0].ToStopID)
+	assert.Equal(t, 1, model.Data.List[0].TransferType)
+	assert.Nil(t, model.Data.List[0].MinTransferTime)
+	assert.Equal(t, "25_1002", model.Data.List[1].FromStopID)
+	assert.Equal(t, "25_1003", model.Data.List[1].ToStopID)
+	assert.Len(t, model.Data.References.Stops, 4)
+}
+
+func TestTransfersForStopHandler_UnknownStop(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := callAPIHandler[TransfersForStopResponse](t, api, "/api/where/transfers-for-stop/25_nonexistent.json?key=TEST")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
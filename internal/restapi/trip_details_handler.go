@@ -2,6 +2,8 @@ package restapi
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -16,17 +18,25 @@ import (
 // TripParams holds the common query parameters for trip-related endpoints
 // (trip-details, trip-for-vehicle, etc.).
 type TripParams struct {
-	ServiceDate     *time.Time
-	IncludeTrip     bool
-	IncludeSchedule bool
-	IncludeStatus   bool
-	Time            *time.Time
-	VehicleID       string
+	// ServiceDate accepts either a Unix timestamp in milliseconds
+	// (e.g. "1718409600000") or a calendar date (e.g. "2024-06-15").
+	ServiceDate     *time.Time `query:"serviceDate,layout=date"`
+	IncludeTrip     bool       `query:"includeTrip"`
+	IncludeSchedule bool       `query:"includeSchedule"`
+	IncludeStatus   bool       `query:"includeStatus"`
+	// Time accepts either a Unix timestamp in milliseconds
+	// (e.g. "1718459400000") or a datetime (e.g. "2024-06-15_14-30-00").
+	Time      *time.Time `query:"time,layout=datetime"`
+	VehicleID string     `query:"vehicleId"`
 }
 
 // parseTripParams parses and validates the common trip query params
 // includeScheduleDefault controls the default value of IncludeSchedule when the
 // parameter is not present in the request (true for trip-details, false for trip-for-vehicle).
+//
+// If a timezone location is provided, dates and datetimes are read in it and
+// serviceDate and time are returned in it, so that downstream
+// Year()/Month()/Day()/Format() calls extract the agency's calendar date.
 func (api *RestAPI) parseTripParams(r *http.Request, includeScheduleDefault bool, loc ...*time.Location) (TripParams, map[string][]string) {
 	params := TripParams{
 		IncludeTrip:     true,
@@ -34,92 +44,13 @@ func (api *RestAPI) parseTripParams(r *http.Request, includeScheduleDefault bool
 		IncludeStatus:   true,
 	}
 
-	fieldErrors := make(map[string][]string)
-
-	// Validate serviceDate — accepts either a Unix timestamp in milliseconds
-	// (e.g. "1718409600000") or a calendar date in yyyy-MM-dd format (e.g. "2024-06-15").
-	if serviceDateStr := r.URL.Query().Get("serviceDate"); serviceDateStr != "" {
-		if serviceDateMs, err := strconv.ParseInt(serviceDateStr, 10, 64); err == nil {
-			serviceDate := time.Unix(serviceDateMs/1000, 0)
-			params.ServiceDate = &serviceDate
-		} else {
-			dateLoc := time.UTC
-			if len(loc) > 0 && loc[0] != nil {
-				dateLoc = loc[0]
-			}
-			if serviceDate, err := time.ParseInLocation("2006-01-02", serviceDateStr, dateLoc); err == nil {
-				params.ServiceDate = &serviceDate
-			} else {
-				fieldErrors["serviceDate"] = []string{"must be a valid Unix timestamp in milliseconds or a date in yyyy-MM-dd format"}
-			}
-		}
-	}
-
-	if includeTripStr := r.URL.Query().Get("includeTrip"); includeTripStr != "" {
-		if val, err := strconv.ParseBool(includeTripStr); err == nil {
-			params.IncludeTrip = val
-		} else {
-			fieldErrors["includeTrip"] = []string{"must be a boolean value (true/false)"}
-		}
-	}
-
-	if includeScheduleStr := r.URL.Query().Get("includeSchedule"); includeScheduleStr != "" {
-		if val, err := strconv.ParseBool(includeScheduleStr); err == nil {
-			params.IncludeSchedule = val
-		} else {
-			fieldErrors["includeSchedule"] = []string{"must be a boolean value (true/false)"}
-		}
-	}
-
-	if includeStatusStr := r.URL.Query().Get("includeStatus"); includeStatusStr != "" {
-		if val, err := strconv.ParseBool(includeStatusStr); err == nil {
-			params.IncludeStatus = val
-		} else {
-			fieldErrors["includeStatus"] = []string{"must be a boolean value (true/false)"}
-		}
-	}
-
-	// Validate time — accepts either a Unix timestamp in milliseconds
-	// (e.g. "1718459400000") or a datetime in yyyy-MM-dd_HH-mm-ss format (e.g. "2024-06-15_14-30-00").
-	if timeStr := r.URL.Query().Get("time"); timeStr != "" {
-		if timeMs, err := strconv.ParseInt(timeStr, 10, 64); err == nil {
-			timeParam := time.Unix(timeMs/1000, 0)
-			params.Time = &timeParam
-		} else {
-			timeLoc := time.UTC
-			if len(loc) > 0 && loc[0] != nil {
-				timeLoc = loc[0]
-			}
-			if timeParam, err := time.ParseInLocation("2006-01-02_15-04-05", timeStr, timeLoc); err == nil {
-				params.Time = &timeParam
-			} else {
-				fieldErrors["time"] = []string{"must be a valid Unix timestamp in milliseconds or a datetime in yyyy-MM-dd_HH-mm-ss format"}
-			}
-		}
+	var location *time.Location
+	if len(loc) > 0 {
+		location = loc[0]
 	}
-
-	params.VehicleID = r.URL.Query().Get("vehicleId")
-
-	if len(fieldErrors) > 0 {
+	if fieldErrors := bindQuery(r.URL.Query(), &params, location); fieldErrors != nil {
 		return params, fieldErrors
 	}
-
-	// If a timezone location was provided, localize serviceDate and time so that
-	// callers receive times in the agency's timezone by default. This prevents the
-	// bug where time.Unix(ms/1000, 0) creates a UTC time and downstream
-	// Year()/Month()/Day()/Format() calls extract the wrong calendar date for
-	// agencies in positive UTC offsets.
-	if len(loc) > 0 && loc[0] != nil {
-		if params.ServiceDate != nil {
-			localized := params.ServiceDate.In(loc[0])
-			params.ServiceDate = &localized
-		}
-		if params.Time != nil {
-			localized := params.Time.In(loc[0])
-			params.Time = &localized
-		}
-	}
-
 	return params, nil
 }
 
@@ -135,7 +66,11 @@ func (api *RestAPI) tripDetailsHandler(w http.ResponseWriter, r *http.Request) {
 
 	trip, err := api.GtfsManager.GtfsDB.Queries.GetTrip(ctx, tripID)
 	if err != nil {
-		api.sendNotFound(w, r)
+		if errors.Is(err, sql.ErrNoRows) {
+			api.sendNotFound(w, r)
+			return
+		}
+		api.serverErrorResponse(w, r, err)
 		return
 	}
 
@@ -208,7 +143,7 @@ func (api *RestAPI) tripDetailsHandler(w http.ResponseWriter, r *http.Request) {
 			api.sendNotFound(w, r)
 			return
 		}
-		v, vErr := api.GtfsManager.GetVehicleByID(rawVehicleID)
+		v, vErr := api.GtfsManager.GetVehicleForAgency(vehicleAgencyID, rawVehicleID)
 		if vErr != nil || v == nil {
 			api.sendNotFound(w, r)
 			return
@@ -222,6 +157,10 @@ func (api *RestAPI) tripDetailsHandler(w http.ResponseWriter, r *http.Request) {
 	if params.IncludeStatus {
 		var statusErr error
 		status, statusErr = api.BuildTripStatus(ctx, agencyID, trip.ID, requestedVehicle, serviceDate, currentTime)
+		if ctx.Err() != nil {
+			api.clientCanceledResponse(w, r, ctx.Err())
+			return
+		}
 		if statusErr != nil {
 			api.Logger.Warn("BuildTripStatus failed",
 				"trip_id", trip.ID,
@@ -246,12 +185,8 @@ func (api *RestAPI) tripDetailsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var situationsIDs []string
-	if status != nil && len(status.SituationIDs) > 0 {
-		situationsIDs = status.SituationIDs
-	} else {
-		situationsIDs = api.GetSituationIDsForTrip(r.Context(), tripID)
-	}
+	situations := newSituationSet(requestLanguage(r))
+	situationsIDs := situations.addTrip(api.GtfsManager.GetTripAlerts(ctx, tripID))
 
 	freqRows, err := api.GtfsManager.GtfsDB.Queries.GetFrequenciesForTrip(ctx, tripID)
 	if err != nil {
@@ -266,14 +201,23 @@ func (api *RestAPI) tripDetailsHandler(w http.ResponseWriter, r *http.Request) {
 		// TripDetails has only one frequency field, but GetFrequenciesForTrip query can return multiple rows
 		// when there are multiple frequency entries for the same trip. In order to adhere to the API contract,
 		// we take the first row which gives us the frequency with the earliest start_time
-		converted := models.NewFrequencyFromDB(freqRows[0], serviceDate)
-		converted.ServiceDate = models.NewModelTime(midnight)
-		converted.ServiceID = utils.FormCombinedID(agencyID, trip.ServiceID)
-		converted.TripID = utils.FormCombinedID(agencyID, trip.ID)
-		frequency = &converted
+		frequency = newTripFrequency(freqRows[0], agencyID, trip, midnight)
+	}
+
+	var bookingRules []models.BookingRule
+	flexStopTimes, err := api.GtfsManager.GtfsDB.Queries.GetFlexStopTimesForTrip(ctx, trip.ID)
+	if err == nil {
+		bookingRules, err = api.flexBookingRulesForTrip(ctx, flexStopTimes)
+	}
+	if err != nil {
+		api.Logger.Warn("failed to fetch booking rules for trip",
+			"trip_id", trip.ID,
+			"error", err.Error())
+		bookingRules = nil
 	}
 
 	tripDetails := &models.TripDetails{
+		BookingRules: bookingRules,
 		TripID:       utils.FormCombinedID(agencyID, trip.ID),
 		ServiceDate:  models.NewModelTime(midnight),
 		Schedule:     schedule,
@@ -326,13 +270,7 @@ func (api *RestAPI) tripDetailsHandler(w http.ResponseWriter, r *http.Request) {
 		agencyModel := models.AgencyReferenceFromDatabase(&agency)
 		references.Agencies = append(references.Agencies, agencyModel)
 
-		if len(situationsIDs) > 0 {
-			alerts := api.GtfsManager.GetAlertsForTrip(r.Context(), tripID)
-			if len(alerts) > 0 {
-				situations := api.BuildSituationReferences(alerts)
-				references.Situations = append(references.Situations, situations...)
-			}
-		}
+		references.Situations = append(references.Situations, situations.references()...)
 
 		if params.IncludeSchedule && schedule != nil {
 			stopIDs := make([]string, 0, len(schedule.StopTimes))
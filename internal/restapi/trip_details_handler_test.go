@@ -187,11 +187,13 @@ func TestTripDetailsHandlerIncludeTripFalseKeepsBlockTrips(t *testing.T) {
 	trip := mustGetTrip(t, api)
 	tripID := utils.FormCombinedID(agency.ID, trip.ID)
 
+	// Block neighbours depend on the services running that day; 2025-07-21 is
+	// a Monday within the test GTFS calendar range.
 	_, withTrip := callAPIHandler[TripDetailsResponse](t, api,
-		"/api/where/trip-details/"+tripID+".json?key=TEST&includeTrip=true&includeSchedule=true")
+		"/api/where/trip-details/"+tripID+".json?key=TEST&includeTrip=true&includeSchedule=true&serviceDate=2025-07-21")
 
 	resp, withoutTrip := callAPIHandler[TripDetailsResponse](t, api,
-		"/api/where/trip-details/"+tripID+".json?key=TEST&includeTrip=false&includeSchedule=true")
+		"/api/where/trip-details/"+tripID+".json?key=TEST&includeTrip=false&includeSchedule=true&serviceDate=2025-07-21")
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -18,7 +18,7 @@ func (api *RestAPI) tripForVehicleHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	vehicle, err := api.GtfsManager.GetVehicleByID(vehicleID)
+	vehicle, err := api.GtfsManager.GetVehicleForAgency(agencyID, vehicleID)
 
 	if err != nil {
 		api.sendNotFound(w, r)
@@ -71,6 +71,10 @@ func (api *RestAPI) tripForVehicleHandler(w http.ResponseWriter, r *http.Request
 	if params.IncludeStatus {
 		var statusErr error
 		status, statusErr = api.BuildTripStatus(ctx, agencyID, tripID, nil, serviceDate, currentTime)
+		if ctx.Err() != nil {
+			api.clientCanceledResponse(w, r, ctx.Err())
+			return
+		}
 		if statusErr != nil {
 			api.Logger.Warn("failed to build trip status",
 				"tripID", tripID,
@@ -160,16 +164,7 @@ func (api *RestAPI) tripForVehicleHandler(w http.ResponseWriter, r *http.Request
 
 	routeRefs := make(map[string]models.Route, len(uniqueRouteMap))
 	for combinedID, route := range uniqueRouteMap {
-		routeRefs[combinedID] = models.NewRoute(
-			utils.FormCombinedID(agencyID, route.ID),
-			agencyID,
-			route.ShortName.String,
-			route.LongName.String,
-			route.Desc.String,
-			models.RouteType(route.Type),
-			route.Url.String,
-			route.Color.String,
-			route.TextColor.String)
+		routeRefs[combinedID] = utils.RouteFromDatabase(agencyID, routeFromStopsRow(route))
 	}
 	references.Routes = utils.MapValues(routeRefs)
 
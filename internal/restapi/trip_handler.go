@@ -75,16 +75,7 @@ func (api *RestAPI) tripHandler(w http.ResponseWriter, r *http.Request) {
 	// includeReferences defaults to true; when explicitly false the references
 	// block is returned with all sub-arrays empty (matches the Java reference).
 	if ShouldIncludeReferences(r) {
-		references.Routes = append(references.Routes, models.NewRoute(
-			utils.FormCombinedID(route.AgencyID, trip.RouteID),
-			route.AgencyID,
-			route.ShortName.String,
-			route.LongName.String,
-			route.Desc.String,
-			models.RouteType(route.Type),
-			route.Url.String,
-			route.Color.String,
-			route.TextColor.String))
+		references.Routes = append(references.Routes, utils.RouteFromDatabase(route.AgencyID, route))
 
 		references.Agencies = append(references.Agencies, models.AgencyReferenceFromDatabase(&agency))
 	}
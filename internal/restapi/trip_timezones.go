@@ -0,0 +1,50 @@
+package restapi
+
+import (
+	"context"
+
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// populateTripTimeZones fills in the TimeZone of trips that have none. A trip
+// runs in the timezone of the agency operating its route, unless the stop it
+// departs from sets a stop_timezone. Trips whose timezone cannot be resolved
+// keep an empty value.
+func (api *RestAPI) populateTripTimeZones(ctx context.Context, trips []models.Trip) {
+	if len(trips) == 0 || api.GtfsManager == nil || api.GtfsManager.GtfsDB == nil {
+		return
+	}
+
+	rawIDs := make([]string, 0, len(trips))
+	for _, trip := range trips {
+		if trip.TimeZone != "" {
+			continue
+		}
+		if _, rawID, err := utils.ExtractAgencyIDAndCodeID(trip.ID); err == nil {
+			rawIDs = append(rawIDs, rawID)
+		}
+	}
+	if len(rawIDs) == 0 {
+		return
+	}
+
+	rows, err := api.GtfsManager.GtfsDB.Queries.GetTripTimeZones(ctx, rawIDs)
+	if err != nil {
+		api.Logger.Warn("failed to look up trip timezones", "error", err)
+		return
+	}
+	timeZones := make(map[string]string, len(rows))
+	for _, row := range rows {
+		timeZones[row.TripID] = row.Timezone
+	}
+
+	for i := range trips {
+		if trips[i].TimeZone != "" {
+			continue
+		}
+		if _, rawID, err := utils.ExtractAgencyIDAndCodeID(trips[i].ID); err == nil {
+			trips[i].TimeZone = timeZones[rawID]
+		}
+	}
+}
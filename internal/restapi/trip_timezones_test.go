@@ -0,0 +1,68 @@
+package restapi
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestTripHandlerTimeZoneIsAgencyTimeZone(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	trip := mustGetTrip(t, api)
+	resp, model := callAPIHandler[TripEntryResponse](t, api,
+		tripURL(utils.FormCombinedID(testdata.Raba.ID, trip.ID)))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, testdata.Raba.Timezone, model.Data.Entry.TimeZone)
+}
+
+func TestTripDetailsReferencedTripsHaveTimeZone(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	trip := mustGetTrip(t, api)
+	resp, model := callAPIHandler[TripDetailsResponse](t, api,
+		"/api/where/trip-details/"+utils.FormCombinedID(testdata.Raba.ID, trip.ID)+".json?key=TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, model.Data.References.Trips)
+	for _, refTrip := range model.Data.References.Trips {
+		assert.Equal(t, testdata.Raba.Timezone, refTrip.TimeZone, "references.trips[%s].timeZone", refTrip.ID)
+	}
+}
+
+func TestTripTimeZonePrefersOriginStopTimeZone(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	ctx := context.Background()
+	db := api.GtfsManager.GtfsDB.DB
+	trip := mustGetTrip(t, api)
+
+	var originStopID string
+	var previous sql.NullString
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT st.stop_id, s.timezone FROM stop_times st JOIN stops s ON s.id = st.stop_id
+		WHERE st.trip_id = ? ORDER BY st.stop_sequence LIMIT 1`, trip.ID,
+	).Scan(&originStopID, &previous))
+
+	_, err := db.ExecContext(ctx, `UPDATE stops SET timezone = 'America/Denver' WHERE id = ?`, originStopID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), `UPDATE stops SET timezone = ? WHERE id = ?`, previous, originStopID)
+	})
+
+	resp, model := callAPIHandler[TripEntryResponse](t, api,
+		tripURL(utils.FormCombinedID(testdata.Raba.ID, trip.ID)))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "America/Denver", model.Data.Entry.TimeZone)
+}
@@ -1,5 +1,14 @@
 package restapi
 
+import (
+	"context"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"maglev.onebusaway.org/gtfsdb"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
+)
+
 type StopDelayInfo struct {
 	ArrivalDelay   int64
 	DepartureDelay int64
@@ -15,22 +24,8 @@ func (api *RestAPI) GetScheduleDeviation(tripID string) (int, bool) {
 		return 0, false
 	}
 
-	tu := tripUpdates[0]
-
-	if tu.Delay != nil {
-		return int(tu.Delay.Seconds()), true
-	}
-
-	for _, stu := range tu.StopTimeUpdates {
-		if stu.Arrival != nil && stu.Arrival.Delay != nil {
-			return int(stu.Arrival.Delay.Seconds()), true
-		}
-		if stu.Departure != nil && stu.Departure.Delay != nil {
-			return int(stu.Departure.Delay.Seconds()), true
-		}
-	}
-
-	return 0, false
+	deviation, ok := internalgtfs.TripScheduleDeviation(tripUpdates[0])
+	return int(deviation.Seconds()), ok
 }
 
 // GetStopDelaysFromTripUpdates returns a map of stop ID → per-stop delay information
@@ -62,3 +57,80 @@ func (api *RestAPI) GetStopDelaysFromTripUpdates(tripID string) map[string]StopD
 
 	return delays
 }
+
+// tripSchedule gives the prediction path the static stop times of a trip,
+// loaded on first use, so that trip updates can be interpreted without
+// vehicle positions.
+type tripSchedule struct {
+	ctx       context.Context
+	api       *RestAPI
+	tripID    string
+	loaded    bool
+	stopTimes []gtfsdb.StopTime
+}
+
+func (s *tripSchedule) load() []gtfsdb.StopTime {
+	if !s.loaded {
+		s.loaded = true
+		stopTimes, err := s.api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(s.ctx, s.tripID)
+		if err != nil {
+			s.api.Logger.Debug("failed to load stop times for trip update", "tripID", s.tripID, "error", err)
+		}
+		s.stopTimes = stopTimes
+	}
+	return s.stopTimes
+}
+
+func (s *tripSchedule) stopTime(sequence int64) (gtfsdb.StopTime, bool) {
+	for _, st := range s.load() {
+		if st.StopSequence == sequence {
+			return st, true
+		}
+	}
+	return gtfsdb.StopTime{}, false
+}
+
+// sequenceAfter returns the sequence of the first visit to stopID after
+// sequence after, or -1. Updates are in stop order, so on loop trips this
+// picks the visit following the previous update.
+func (s *tripSchedule) sequenceAfter(stopID string, after int64) int64 {
+	for _, st := range s.load() {
+		if st.StopID == stopID && st.StopSequence > after {
+			return st.StopSequence
+		}
+	}
+	return -1
+}
+
+// updateDelay returns the delay reported by stu for the stop at sequence. A
+// stated delay is used as is; an absolute time is compared with the stop's
+// scheduled time, found relative to the target stop, whose scheduled arrival
+// is targetArrival.
+func (s *tripSchedule) updateDelay(stu gtfs.StopTimeUpdate, sequence, targetSequence int64, targetArrival time.Time) (time.Duration, bool) {
+	if stu.Departure != nil && stu.Departure.Delay != nil {
+		return *stu.Departure.Delay, true
+	}
+	if stu.Arrival != nil && stu.Arrival.Delay != nil {
+		return *stu.Arrival.Delay, true
+	}
+
+	departed := stu.Departure != nil && stu.Departure.Time != nil
+	arrived := stu.Arrival != nil && stu.Arrival.Time != nil
+	if !departed && !arrived {
+		return 0, false
+	}
+	prior, ok := s.stopTime(sequence)
+	if !ok {
+		return 0, false
+	}
+	target, ok := s.stopTime(targetSequence)
+	if !ok {
+		return 0, false
+	}
+	if departed {
+		scheduled := targetArrival.Add(time.Duration(prior.DepartureTime - target.ArrivalTime))
+		return stu.Departure.Time.Sub(scheduled), true
+	}
+	scheduled := targetArrival.Add(time.Duration(prior.ArrivalTime - target.ArrivalTime))
+	return stu.Arrival.Time.Sub(scheduled), true
+}
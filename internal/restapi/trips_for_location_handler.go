@@ -51,15 +51,25 @@ func (api *RestAPI) tripsForLocationHandler(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
-		if vehicle.Position == nil || vehicle.Position.Latitude == nil || vehicle.Position.Longitude == nil {
+		if !parsedReq.IncludeCanceled && api.GtfsManager.IsTripCanceled(vehicle.Trip.ID.ID) {
 			continue
 		}
-		vLat, vLon := float64(*vehicle.Position.Latitude), float64(*vehicle.Position.Longitude)
-		if vLat >= bounds.MinLat && vLat <= bounds.MaxLat && vLon >= bounds.MinLon && vLon <= bounds.MaxLon {
+		if vehicleInBounds(vehicle, bounds) {
 			visibleTripIDs = append(visibleTripIDs, vehicle.Trip.ID.ID)
 		}
 	}
 
+	// ADDED trips have no static stop times, so they are found by where
+	// their vehicle is rather than through the stops in the area.
+	var addedTrips []gtfs.Trip
+	if parsedReq.IncludeAdded {
+		for _, trip := range api.GtfsManager.GetAddedTrips() {
+			if trip.Vehicle != nil && vehicleInBounds(*trip.Vehicle, bounds) {
+				addedTrips = append(addedTrips, trip)
+			}
+		}
+	}
+
 	var trips []gtfsdb.Trip
 	if len(visibleTripIDs) > 0 {
 		trips, err = api.GtfsManager.GtfsDB.Queries.GetTripsByIDs(ctx, visibleTripIDs)
@@ -69,12 +79,15 @@ func (api *RestAPI) tripsForLocationHandler(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	routeIDs := make([]string, 0, len(trips))
+	routeIDs := make([]string, 0, len(trips)+len(addedTrips))
 	tripRouteMap := make(map[string]string)
 	for _, trip := range trips {
 		routeIDs = append(routeIDs, trip.RouteID)
 		tripRouteMap[trip.ID] = trip.RouteID
 	}
+	for _, trip := range addedTrips {
+		routeIDs = append(routeIDs, trip.ID.RouteID)
+	}
 
 	var routes []gtfsdb.Route
 	if len(routeIDs) > 0 {
@@ -102,6 +115,29 @@ func (api *RestAPI) tripsForLocationHandler(w http.ResponseWriter, r *http.Reque
 	if result == nil {
 		return
 	}
+	for _, trip := range addedTrips {
+		agencyID, ok := routeAgencyMap[trip.ID.RouteID]
+		if !ok {
+			continue
+		}
+		entry := models.TripsForLocationListEntry{
+			ServiceDate:  parsedReq.TodayMidnight.UnixMilli(),
+			SituationIds: api.GetSituationIDsForTrip(ctx, trip.ID.ID),
+			TripId:       utils.FormCombinedID(agencyID, trip.ID.ID),
+		}
+		if parsedReq.IncludeSchedule {
+			entry.Schedule = addedTripSchedule(trip, agencyID, parsedReq.TodayMidnight)
+		}
+		if parsedReq.IncludeStatus {
+			status, statusErr := api.buildAddedTripStatus(ctx, agencyID, trip, parsedReq.TodayMidnight, parsedReq.CurrentTime)
+			if statusErr != nil {
+				api.Logger.Warn("BuildTripStatus failed for ADDED trip", "tripID", trip.ID.ID, "error", statusErr)
+			} else {
+				entry.Status = status
+			}
+		}
+		result = append(result, entry)
+	}
 
 	if ctx.Err() != nil {
 		api.clientCanceledResponse(w, r, ctx.Err())
@@ -131,6 +167,8 @@ type tripsForLocationRequest struct {
 	IncludeTrip     bool
 	IncludeSchedule bool
 	IncludeStatus   bool
+	IncludeCanceled bool
+	IncludeAdded    bool
 	CurrentLocation *time.Location
 	CurrentTime     time.Time
 	TodayMidnight   time.Time
@@ -147,6 +185,8 @@ func (api *RestAPI) parseAndValidateRequest(r *http.Request) (*tripsForLocationR
 	// Intentionally defaulting includeStatus to false to align with includeSchedule
 	// behavior for this endpoint, even though trips-for-route defaults to true.
 	includeStatus, _ := strconv.ParseBool(queryParams.Get("includeStatus"))
+	includeCanceled, _ := strconv.ParseBool(queryParams.Get("includeCanceled"))
+	includeAdded, _ := strconv.ParseBool(queryParams.Get("includeAdded"))
 
 	agencies, agenciesErr := api.GtfsManager.GetAgencies(r.Context())
 
@@ -178,6 +218,8 @@ func (api *RestAPI) parseAndValidateRequest(r *http.Request) (*tripsForLocationR
 		IncludeTrip:     includeTrip,
 		IncludeSchedule: includeSchedule,
 		IncludeStatus:   includeStatus,
+		IncludeCanceled: includeCanceled,
+		IncludeAdded:    includeAdded,
 		CurrentLocation: currentLocation,
 		CurrentTime:     currentTime,
 		TodayMidnight:   todayMidnight,
@@ -242,6 +284,16 @@ func (api *RestAPI) getActiveTrips(stopTimes []gtfsdb.StopTime, realTimeVehicles
 	return activeTrips
 }
 
+// vehicleInBounds reports whether the vehicle's reported position lies within
+// bounds. Vehicles without a position are never in bounds.
+func vehicleInBounds(vehicle gtfs.Vehicle, bounds utils.CoordinateBounds) bool {
+	if vehicle.Position == nil || vehicle.Position.Latitude == nil || vehicle.Position.Longitude == nil {
+		return false
+	}
+	lat, lon := float64(*vehicle.Position.Latitude), float64(*vehicle.Position.Longitude)
+	return lat >= bounds.MinLat && lat <= bounds.MaxLat && lon >= bounds.MinLon && lon <= bounds.MaxLon
+}
+
 // buildTripsForLocationEntries builds trip entries from pre-fetched batch data.
 func (api *RestAPI) buildTripsForLocationEntries(
 	ctx context.Context,
@@ -396,11 +448,15 @@ func (api *RestAPI) buildTripsForLocationEntries(
 				stopCoords,
 				blockTrips,
 			)
+			api.setHistoricalOccupancy(ctx, stopTimesMap[tripID], schedule.StopTimes)
 		}
 
 		if includeStatus {
 			var statusErr error
 			status, statusErr = api.BuildTripStatus(ctx, agencyID, tripID, nil, todayMidnight, currentTime)
+			if ctx.Err() != nil {
+				return result
+			}
 			if statusErr != nil {
 				api.Logger.Warn("BuildTripStatus failed", "tripID", tripID, "error", statusErr)
 				status = nil
@@ -478,8 +534,9 @@ func buildStopTimesList(api *RestAPI, ctx context.Context, stopTimes []gtfsdb.St
 		}
 	}
 
-	return api.calculateBatchStopDistances(stopTimes, shapePoints, stopCoords, agencyID)
-
+	stopTimesList := api.calculateBatchStopDistances(stopTimes, shapePoints, stopCoords, agencyID)
+	api.setHistoricalOccupancy(ctx, stopTimes, stopTimesList)
+	return stopTimesList
 }
 
 type ReferenceParams struct {
@@ -707,17 +764,7 @@ func (rb *referenceBuilder) collectAgenciesAndRoutes() error {
 }
 
 func (rb *referenceBuilder) createRoute(route gtfsdb.Route) models.Route {
-	return models.NewRoute(
-		utils.FormCombinedID(route.AgencyID, route.ID),
-		route.AgencyID,
-		route.ShortName.String,
-		route.LongName.String,
-		route.Desc.String,
-		models.RouteType(route.Type),
-		route.Url.String,
-		route.Color.String,
-		route.TextColor.String)
-
+	return utils.RouteFromDatabase(route.AgencyID, route)
 }
 
 func (rb *referenceBuilder) buildTripReferences() error {
@@ -8,10 +8,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"maglev.onebusaway.org/internal/clock"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
 	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
 )
 
 const (
@@ -617,3 +622,57 @@ func TestTripsForLocationHandler_ContextCancellation(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), "gateway timeout")
 	})
 }
+
+func TestTripsForLocationHandler_CanceledAndAddedTrips(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	ctx := context.Background()
+
+	trip := mustGetTrip(t, api)
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trip.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, stopTimes)
+	stop, err := api.GtfsManager.GtfsDB.Queries.GetStop(ctx, stopTimes[0].StopID)
+	require.NoError(t, err)
+	lat, lon := float32(stop.Lat), float32(stop.Lon)
+	position := &gtfs.Position{Latitude: &lat, Longitude: &lon}
+
+	api.GtfsManager.MockAddVehicleWithOptions("v_canceled", trip.ID, trip.RouteID, internalgtfs.MockVehicleOptions{
+		Position:             position,
+		ScheduleRelationship: gtfsrt.TripDescriptor_CANCELED,
+	})
+	api.GtfsManager.MockAddVehicleWithOptions("v_added", "added-trip", trip.RouteID, internalgtfs.MockVehicleOptions{
+		Position:             position,
+		ScheduleRelationship: gtfsrt.TripDescriptor_ADDED,
+	})
+
+	list := func(extras string) map[string]models.TripsForLocationListEntry {
+		t.Helper()
+		url := fmt.Sprintf("/api/where/trips-for-location.json?key=TEST&lat=%f&lon=%f&latSpan=0.01&lonSpan=0.01&includeStatus=true%s",
+			stop.Lat, stop.Lon, extras)
+		resp, model := callAPIHandler[TripsForLocationResponse](t, api, url)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entries := make(map[string]models.TripsForLocationListEntry, len(model.Data.List))
+		for _, entry := range model.Data.List {
+			entries[entry.TripId] = entry
+		}
+		return entries
+	}
+	agencyID := testdata.Raba.ID
+	canceledID := utils.FormCombinedID(agencyID, trip.ID)
+	addedID := utils.FormCombinedID(agencyID, "added-trip")
+
+	entries := list("")
+	assert.NotContains(t, entries, canceledID, "canceled trips are hidden by default")
+	assert.NotContains(t, entries, addedID, "added trips are hidden by default")
+
+	entries = list("&includeCanceled=true&includeAdded=true")
+	require.Contains(t, entries, canceledID)
+	require.NotNil(t, entries[canceledID].Status)
+	assert.Equal(t, "CANCELED", entries[canceledID].Status.Status)
+	require.Contains(t, entries, addedID)
+	require.NotNil(t, entries[addedID].Status)
+	assert.Equal(t, "ADDED", entries[addedID].Status.Status)
+	assert.Equal(t, utils.FormCombinedID(agencyID, "v_added"), entries[addedID].Status.VehicleID)
+}
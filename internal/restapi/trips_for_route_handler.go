@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OneBusAway/go-gtfs"
 	"maglev.onebusaway.org/gtfsdb"
 	"maglev.onebusaway.org/internal/logging"
 	"maglev.onebusaway.org/internal/models"
@@ -29,10 +30,18 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 
 	includeSchedule := r.URL.Query().Get("includeSchedule") != "false"
 	includeStatus := r.URL.Query().Get("includeStatus") != "false"
+	// Riders have no use for canceled or realtime-only added trips, so both
+	// are left out unless a dispatcher tool asks for them.
+	includeCanceled := r.URL.Query().Get("includeCanceled") == "true"
+	includeAdded := r.URL.Query().Get("includeAdded") == "true"
 
 	currentAgency, err := api.GtfsManager.GtfsDB.Queries.GetAgency(ctx, agencyID)
 	if err != nil {
-		api.sendNotFound(w, r)
+		if errors.Is(err, sql.ErrNoRows) {
+			api.sendNotFound(w, r)
+			return
+		}
+		api.serverErrorResponse(w, r, err)
 		return
 	}
 
@@ -181,7 +190,7 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	if len(allLinkedBlocks) == 0 && len(nullBlockTrips) == 0 {
+	if len(allLinkedBlocks) == 0 && len(nullBlockTrips) == 0 && !includeAdded {
 		references := buildTripReferences(api, ctx, includeSchedule, []models.TripsForRouteListEntry{}, []gtfsdb.Stop{}, nil)
 		response := models.NewListResponseWithRange([]models.TripsForRouteListEntry{}, references, false, api.Clock, false)
 		api.sendResponse(w, r, response)
@@ -307,6 +316,7 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 
 	todayMidnight := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, currentLocation)
 	stopIDsMap := make(map[string]bool)
+	situations := newSituationSet(requestLanguage(r))
 
 	var result []models.TripsForRouteListEntry
 	for _, fetchedTrip := range fetchedTrips {
@@ -321,6 +331,9 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		if !ok {
 			continue
 		}
+		if !includeCanceled && api.GtfsManager.IsTripCanceled(tripID) {
+			continue
+		}
 
 		var schedule *models.TripsSchedule
 		var status *models.TripStatus
@@ -340,6 +353,10 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		if includeStatus {
 			var statusErr error
 			status, statusErr = api.BuildTripStatus(ctx, agencyID, tripID, nil, todayMidnight, currentTime)
+			if ctx.Err() != nil {
+				api.clientCanceledResponse(w, r, ctx.Err())
+				return
+			}
 			if statusErr != nil {
 				api.Logger.Warn("BuildTripStatus failed", "trip_id", tripID, "error", statusErr)
 				status = nil
@@ -351,7 +368,7 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 			Schedule:     schedule,
 			Status:       status,
 			ServiceDate:  todayMidnight.UnixMilli(),
-			SituationIds: api.GetSituationIDsForTrip(r.Context(), tripID),
+			SituationIds: situations.addTrip(api.GtfsManager.GetTripAlerts(ctx, tripID)),
 			TripId:       utils.FormCombinedID(agencyID, tripID),
 		}
 		result = append(result, entry)
@@ -369,6 +386,10 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 	// We try the full trip ID first, then fall back to stripping a numeric suffix.
 	duplicatedVehicles := api.GtfsManager.GetDuplicatedVehiclesForRoute(routeID)
 	for _, vehicle := range duplicatedVehicles {
+		if ctx.Err() != nil {
+			api.clientCanceledResponse(w, r, ctx.Err())
+			return
+		}
 		if vehicle.Trip == nil || vehicle.Trip.ID.ID == "" {
 			continue
 		}
@@ -404,6 +425,10 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		if includeStatus {
 			var statusErr error
 			status, statusErr = api.BuildTripStatus(ctx, agencyID, baseTripID, &vehicle, todayMidnight, currentTime)
+			if ctx.Err() != nil {
+				api.clientCanceledResponse(w, r, ctx.Err())
+				return
+			}
 			if statusErr != nil {
 				api.Logger.Warn("BuildTripStatus failed for DUPLICATED trip", "trip_id", baseTripID, "error", statusErr)
 				status = nil
@@ -415,7 +440,7 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 			Schedule:     schedule,
 			Status:       status,
 			ServiceDate:  todayMidnight.UnixMilli(),
-			SituationIds: api.GetSituationIDsForTrip(r.Context(), baseTripID),
+			SituationIds: situations.addTrip(api.GtfsManager.GetTripAlerts(ctx, baseTripID)),
 			TripId:       utils.FormCombinedID(agencyID, dupTripID),
 		}
 		result = append(result, entry)
@@ -429,6 +454,42 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Include ADDED trips from real-time data on request. These are runs the
+	// agency put into service that have no static trip at all, so their
+	// schedule is read from their stop time updates.
+	if includeAdded {
+		for _, trip := range api.GtfsManager.GetAddedTrips() {
+			if trip.ID.RouteID != routeID {
+				continue
+			}
+			var schedule *models.TripsSchedule
+			if includeSchedule {
+				schedule = addedTripSchedule(trip, agencyID, todayMidnight)
+				collectStopIDsFromSchedule(schedule, stopIDsMap)
+			}
+			var status *models.TripStatus
+			if includeStatus {
+				var statusErr error
+				status, statusErr = api.buildAddedTripStatus(ctx, agencyID, trip, todayMidnight, currentTime)
+				if ctx.Err() != nil {
+					api.clientCanceledResponse(w, r, ctx.Err())
+					return
+				}
+				if statusErr != nil {
+					api.Logger.Warn("BuildTripStatus failed for ADDED trip", "trip_id", trip.ID.ID, "error", statusErr)
+					status = nil
+				}
+			}
+			result = append(result, models.TripsForRouteListEntry{
+				Schedule:     schedule,
+				Status:       status,
+				ServiceDate:  todayMidnight.UnixMilli(),
+				SituationIds: situations.addTrip(api.GtfsManager.GetTripAlerts(ctx, trip.ID.ID)),
+				TripId:       utils.FormCombinedID(agencyID, trip.ID.ID),
+			})
+		}
+	}
+
 	if result == nil {
 		result = []models.TripsForRouteListEntry{}
 	}
@@ -448,6 +509,7 @@ func (api *RestAPI) tripsForRouteHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	references := buildTripReferences(api, ctx, includeSchedule, result, stops, fetchedTrips)
+	references.Situations = append(references.Situations, situations.references()...)
 	response := models.NewListResponseWithRange(result, references, false, api.Clock, false)
 	api.sendResponse(w, r, response)
 }
@@ -569,16 +631,7 @@ func buildTripReferences(
 		}
 
 		for _, route := range fetchedRoutes {
-			presentRoutes[route.ID] = models.NewRoute(
-				utils.FormCombinedID(route.AgencyID, route.ID),
-				route.AgencyID,
-				route.ShortName.String,
-				route.LongName.String,
-				route.Desc.String,
-				models.RouteType(route.Type),
-				route.Url.String,
-				route.Color.String,
-				route.TextColor.String)
+			presentRoutes[route.ID] = utils.RouteFromDatabase(route.AgencyID, route)
 
 			if _, exists := presentAgencies[route.AgencyID]; !exists {
 				agency, err := api.GtfsManager.FindAgency(ctx, route.AgencyID)
@@ -675,6 +728,45 @@ func buildTripReferences(
 	return *references
 }
 
+// addedTripSchedule builds the schedule of a realtime-only ADDED trip from its
+// stop time updates, with times relative to serviceDate (midnight). Updates
+// without a stop or a time are left out.
+func addedTripSchedule(trip gtfs.Trip, agencyID string, serviceDate time.Time) *models.TripsSchedule {
+	schedule := &models.TripsSchedule{
+		StopTimes: []models.StopTime{},
+		TimeZone:  serviceDate.Location().String(),
+	}
+	for _, update := range trip.StopTimeUpdates {
+		arrival, departure := update.GetArrival().Time, update.GetDeparture().Time
+		if arrival == nil {
+			arrival = departure
+		}
+		if departure == nil {
+			departure = arrival
+		}
+		if update.StopID == nil || arrival == nil {
+			continue
+		}
+		schedule.StopTimes = append(schedule.StopTimes, models.NewStopTime(
+			arrival.Sub(serviceDate),
+			departure.Sub(serviceDate),
+			utils.FormCombinedID(agencyID, *update.StopID),
+			"", 0, "",
+		))
+	}
+	return schedule
+}
+
+// buildAddedTripStatus builds the status of a realtime-only ADDED trip. The
+// trip keeps the ADDED status even when no vehicle serves it yet.
+func (api *RestAPI) buildAddedTripStatus(ctx context.Context, agencyID string, trip gtfs.Trip, serviceDate, currentTime time.Time) (*models.TripStatus, error) {
+	status, err := api.BuildTripStatus(ctx, agencyID, trip.ID.ID, trip.Vehicle, serviceDate, currentTime)
+	if status != nil {
+		status.Status = "ADDED"
+	}
+	return status, err
+}
+
 // stripNumericSuffix removes a trailing ".<digits>" from a trip ID.
 // Some GTFS-RT feeds append a numeric suffix to DUPLICATED trip IDs to
 // distinguish individual runs (e.g., "LLR_..._1083.00060" -> "LLR_..._1083").
@@ -12,6 +12,8 @@ import (
 	"testing"
 	"time"
 
+	gogtfs "github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"maglev.onebusaway.org/internal/app"
@@ -319,3 +321,54 @@ func TestCollectStopIDsFromSchedule_EmptyStopTimes(t *testing.T) {
 
 	assert.Empty(t, stopIDsMap)
 }
+
+func TestTripsForRouteHandler_CanceledAndAddedTrips(t *testing.T) {
+	api := createTestApiWithTripsForRouteFixture(t, clock.NewMockClock(tripsForRouteTestClock))
+	combinedRouteID := utils.FormCombinedID(tripsForRouteAgencyID, tripsForRouteRouteID)
+
+	api.GtfsManager.MockAddRealtimeTrip(gogtfs.Trip{
+		ID: gogtfs.TripID{ID: tripsForRouteTripID, ScheduleRelationship: gtfsrt.TripDescriptor_CANCELED},
+	})
+	stopID := tripsForRouteStop2ID
+	arrival := tripsForRouteTestClock.Add(15 * time.Minute)
+	api.GtfsManager.MockAddRealtimeTrip(gogtfs.Trip{
+		ID: gogtfs.TripID{ID: "tfr-added", RouteID: tripsForRouteRouteID, ScheduleRelationship: gtfsrt.TripDescriptor_ADDED},
+		StopTimeUpdates: []gogtfs.StopTimeUpdate{
+			{StopID: &stopID, Arrival: &gogtfs.StopTimeEvent{Time: &arrival}},
+		},
+	})
+
+	list := func(params string) map[string]models.TripsForRouteListEntry {
+		t.Helper()
+		url := fmt.Sprintf("/api/where/trips-for-route/%s.json?key=TEST&time=%d%s",
+			combinedRouteID, tripsForRouteTestClock.UnixMilli(), params)
+		resp, model := callAPIHandler[TripsForRouteResponse](t, api, url)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entries := make(map[string]models.TripsForRouteListEntry, len(model.Data.List))
+		for _, entry := range model.Data.List {
+			entries[entry.TripId] = entry
+		}
+		return entries
+	}
+	canceledID := utils.FormCombinedID(tripsForRouteAgencyID, tripsForRouteTripID)
+	addedID := utils.FormCombinedID(tripsForRouteAgencyID, "tfr-added")
+
+	entries := list("")
+	assert.NotContains(t, entries, canceledID, "canceled trips are hidden by default")
+	assert.NotContains(t, entries, addedID, "added trips are hidden by default")
+
+	entries = list("&includeCanceled=true&includeAdded=true")
+	require.Contains(t, entries, canceledID)
+	require.NotNil(t, entries[canceledID].Status)
+	assert.Equal(t, "CANCELED", entries[canceledID].Status.Status)
+	assert.True(t, entries[canceledID].Status.Predicted, "the cancellation is real-time information")
+
+	require.Contains(t, entries, addedID)
+	added := entries[addedID]
+	require.NotNil(t, added.Status)
+	assert.Equal(t, "ADDED", added.Status.Status)
+	require.NotNil(t, added.Schedule)
+	require.Len(t, added.Schedule.StopTimes, 1)
+	assert.Equal(t, utils.FormCombinedID(tripsForRouteAgencyID, tripsForRouteStop2ID), added.Schedule.StopTimes[0].StopID)
+	assert.Equal(t, 12*time.Hour+15*time.Minute, added.Schedule.StopTimes[0].ArrivalTime.Duration)
+}
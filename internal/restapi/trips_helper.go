@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"log/slog"
 	"math"
 	"sort"
@@ -13,6 +12,7 @@ import (
 
 	"github.com/OneBusAway/go-gtfs"
 	"maglev.onebusaway.org/gtfsdb"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
 	"maglev.onebusaway.org/internal/models"
 	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
@@ -27,6 +27,9 @@ import (
 // tripID is used for DB lookups (stop times, shapes, block sequence). For DUPLICATED
 // trips whose synthetic ActiveTripID has no DB entry, set tripID to the base/static
 // trip ID so the correct schedule data is used.
+//
+// When ctx is canceled part way, the remaining calculations are skipped and
+// ctx.Err() is returned with a nil status.
 func (api *RestAPI) BuildTripStatus(
 	ctx context.Context,
 	agencyID, tripID string,
@@ -50,23 +53,32 @@ func (api *RestAPI) BuildTripStatus(
 		if vehicle.ID != nil {
 			status.VehicleID = utils.FormCombinedID(agencyID, vehicle.ID.ID)
 		}
-		if vehicle.OccupancyStatus != nil {
-			status.OccupancyStatus = vehicle.OccupancyStatus.String()
-		}
+		status.OccupancyStatus = internalgtfs.VehicleOccupancyStatus(vehicle)
 		// NOTE: GTFS-RT OccupancyPercentage (0-100%) has no direct equivalent in the
 		// OBA TripStatus schema. The Java OBA server populates occupancyCapacity from
 		// agency-provided vehicle capacity data, not from GTFS-RT percentages.
-		// We intentionally leave OccupancyCapacity at its zero value (0) here, as GTFS-RT OccupancyPercentage has no direct mapping to OBA's capacity-based model.
+		// We intentionally leave OccupancyCapacity at its zero value (0) here, and only
+		// use the percentage to pick an occupancyStatus when the feed gives none.
 		// See: TripStatusBeanServiceImpl.java in onebusaway-transit-data-federation.
 	}
 	api.BuildVehicleStatus(ctx, vehicle, tripID, agencyID, status, currentTime)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// A trip update can cancel a trip that no vehicle reports on.
+	canceledByUpdate := status.Status != "CANCELED" && api.GtfsManager.IsTripCanceled(tripID)
+	if canceledByUpdate {
+		status.Status = "CANCELED"
+		status.Phase = ""
+	}
 
 	// CANCELED trips are no longer running there is no active position or schedule
 	// to report. Return immediately with the cancellation status and skip all stop-time
 	// and shape calculations, which are meaningless for a trip that is not operating.
 	// Predicted is true because the cancellation itself is real-time information.
 	if status.Status == "CANCELED" {
-		status.Predicted = vehicle != nil && !defaultStaleDetector.Check(vehicle, currentTime)
+		status.Predicted = canceledByUpdate || (vehicle != nil && !defaultStaleDetector.Check(vehicle, currentTime))
 		status.Scheduled = !status.Predicted
 		return status, nil
 	}
@@ -103,11 +115,21 @@ func (api *RestAPI) BuildTripStatus(
 	status.SetPredicted(hasVehicleRealtimeData || hasRealtimeTripUpdate)
 
 	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, dbTripID)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		slog.Warn("buildTripStatusCore: failed to get stop times",
 			slog.String("trip_id", dbTripID),
 			slog.String("error", err.Error()))
 	}
+	scheduleTime := api.applyTripFrequency(ctx, status, agencyID, dbTripID, sdMidnight, currentTime, stopTimes)
+	if status.Frequency == nil && len(stopTimes) > 0 {
+		status.ScheduledStartTime = models.NewModelTime(sdMidnight.Add(time.Duration(stopTimes[0].DepartureTime)))
+	}
+	if start, ok := api.GtfsManager.TripStartTime(dbTripID, sdMidnight); ok {
+		status.ActualStartTime = models.NewModelTime(start)
+	}
 	if err == nil && len(stopTimes) > 0 {
 		stopTimesPtrs := make([]*gtfsdb.StopTime, len(stopTimes))
 		for i := range stopTimes {
@@ -120,30 +142,30 @@ func (api *RestAPI) BuildTripStatus(
 		if vehicle != nil && vehicle.Position != nil {
 			if vehicle.StopID != nil && *vehicle.StopID != "" {
 				closestStopID = *vehicle.StopID
-				closestOffset = api.calculateOffsetForStop(closestStopID, stopTimesPtrs, currentTime, serviceDate, scheduleDeviation)
+				closestOffset = api.calculateOffsetForStop(closestStopID, stopTimesPtrs, scheduleTime, serviceDate, scheduleDeviation)
 				isStoppedAt := vehicle.CurrentStatus != nil && *vehicle.CurrentStatus == gtfs.CurrentStatus(1)
 				if isStoppedAt {
-					nextStopID, nextOffset = api.findNextStopAfter(closestStopID, stopTimesPtrs, currentTime, serviceDate, scheduleDeviation)
+					nextStopID, nextOffset = api.findNextStopAfter(closestStopID, stopTimesPtrs, scheduleTime, serviceDate, scheduleDeviation)
 				} else {
 					nextStopID = closestStopID
 					nextOffset = closestOffset
 				}
 			} else if vehicle.CurrentStopSequence != nil {
 				closestStopID, closestOffset = api.findClosestStopBySequence(
-					stopTimesPtrs, *vehicle.CurrentStopSequence, currentTime, serviceDate, scheduleDeviation,
+					stopTimesPtrs, *vehicle.CurrentStopSequence, scheduleTime, serviceDate, scheduleDeviation,
 				)
 				nextStopID, nextOffset = api.findNextStopBySequence(
-					ctx, stopTimesPtrs, *vehicle.CurrentStopSequence, currentTime, serviceDate, scheduleDeviation, vehicle, tripID,
+					ctx, stopTimesPtrs, *vehicle.CurrentStopSequence, scheduleTime, serviceDate, scheduleDeviation, vehicle, tripID,
 				)
 			} else {
 				closestStopID, closestOffset, nextStopID, nextOffset = api.findStopsByScheduleDeviation(
-					stopTimesPtrs, currentTime, serviceDate, scheduleDeviation,
+					stopTimesPtrs, scheduleTime, serviceDate, scheduleDeviation,
 				)
 			}
 		} else {
 			stopDelays := api.GetStopDelaysFromTripUpdates(dbTripID)
-			closestStopID, closestOffset = findClosestStopByTimeWithDelays(currentTime, serviceDate, stopTimesPtrs, stopDelays)
-			nextStopID, nextOffset = findNextStopByTimeWithDelays(currentTime, serviceDate, stopTimesPtrs, stopDelays)
+			closestStopID, closestOffset = findClosestStopByTimeWithDelays(scheduleTime, serviceDate, stopTimesPtrs, stopDelays)
+			nextStopID, nextOffset = findNextStopByTimeWithDelays(scheduleTime, serviceDate, stopTimesPtrs, stopDelays)
 		}
 
 		if closestStopID != "" {
@@ -157,11 +179,16 @@ func (api *RestAPI) BuildTripStatus(
 	}
 
 	if status.ClosestStop == "" || status.NextStop == "" {
-		api.fillStopsFromSchedule(ctx, status, dbTripID, currentTime, serviceDate, agencyID, stopTimes)
+		api.fillStopsFromSchedule(ctx, status, dbTripID, scheduleTime, serviceDate, agencyID, stopTimes)
 	}
 
+	// The shape projections below are the costliest part of a status; skip
+	// them for a client that has gone away.
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	shapeRows, shapeErr := api.GtfsManager.GtfsDB.Queries.GetShapePointsByTripID(ctx, dbTripID)
-	if shapeErr != nil {
+	if shapeErr != nil && ctx.Err() == nil {
 		slog.Warn("buildTripStatusCore: failed to get shape points",
 			slog.String("trip_id", dbTripID),
 			slog.String("error", shapeErr.Error()))
@@ -194,9 +221,15 @@ func (api *RestAPI) BuildTripStatus(
 				}
 			}
 
+			if distance, ok := api.interpolatedDistance(ctx, vehicle, actualDistance, currentTime, stopTimes, shapePoints, cumulativeDistances); ok {
+				status.DistanceAlongTrip = distance
+				status.Position = pointAlongShape(shapePoints, cumulativeDistances, distance)
+				status.PositionInterpolated = true
+			}
+
 			if scheduleDeviation != 0 && len(stopTimes) > 0 {
 				scheduledDistance := api.calculateEffectiveDistanceAlongTrip(
-					ctx, actualDistance, scheduleDeviation, currentTime, serviceDate,
+					ctx, actualDistance, scheduleDeviation, scheduleTime, serviceDate,
 					stopTimes, shapePoints, cumulativeDistances,
 				)
 				status.ScheduledDistanceAlongTrip = scheduledDistance
@@ -204,6 +237,9 @@ func (api *RestAPI) BuildTripStatus(
 		}
 	}
 
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	blockTripSequence := api.calculateBlockTripSequence(ctx, tripID, serviceDate)
 	if blockTripSequence > 0 {
 		status.BlockTripSequence = blockTripSequence
@@ -247,8 +283,15 @@ func (api *RestAPI) BuildTripSchedule(ctx context.Context, agencyID string, serv
 	}
 
 	stopTimesVals := api.calculateBatchStopDistances(stopTimes, shapePoints, stopCoords, agencyID)
+	api.setHistoricalOccupancy(ctx, stopTimes, stopTimesVals)
+
+	flexStopTimes, err := api.GtfsManager.GtfsDB.Queries.GetFlexStopTimesForTrip(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &models.Schedule{
+		FlexStopTimes:  newFlexStopTimes(flexStopTimes, agencyID),
 		StopTimes:      stopTimesVals,
 		TimeZone:       loc.String(),
 		Frequency:      nil,
@@ -266,58 +309,15 @@ func (api *RestAPI) GetNextAndPreviousTripIDs(ctx context.Context, trip *gtfsdb.
 		return "", "", stopTimes, nil
 	}
 
-	navResult, err := api.GtfsManager.GtfsDB.Queries.GetNextAndPreviousTripsInBlock(ctx, gtfsdb.GetNextAndPreviousTripsInBlockParams{
-		TripID:     trip.ID,
-		BlockID:    trip.BlockID,
-		ServiceIds: []string{trip.ServiceID},
-	})
+	prev, next, err := api.GtfsManager.BlockNeighbors(ctx, trip.ID, serviceDate)
 	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			// Real DB error (timeout, connection failure, etc.) — propagate it.
-			return "", "", nil, err
-		}
-		// ErrNoRows: trip not in block for this service date — no prev/next, but
-		// still fetch stop times so callers get the schedule for the trip itself.
-		stopTimes, stopErr := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trip.ID)
-		if stopErr != nil {
-			return "", "", nil, stopErr
-		}
-		return "", "", stopTimes, nil
+		return "", "", nil, err
 	}
-
-	// LAG/LEAD return interface{} (nullable); type-switch to handle both string and []byte
-	switch prev := navResult.PrevTripID.(type) {
-	case string:
-		if prev != "" {
-			previousTripID = utils.FormCombinedID(agencyID, prev)
-		}
-	case []byte:
-		if len(prev) > 0 {
-			previousTripID = utils.FormCombinedID(agencyID, string(prev))
-		}
-	case nil:
-		// Expected for the first trip in the block.
-	default:
-		slog.Warn("GetNextAndPreviousTripIDs: unexpected type for prev_trip_id",
-			slog.String("type", fmt.Sprintf("%T", prev)),
-			slog.String("trip_id", trip.ID))
-	}
-
-	switch next := navResult.NextTripID.(type) {
-	case string:
-		if next != "" {
-			nextTripID = utils.FormCombinedID(agencyID, next)
-		}
-	case []byte:
-		if len(next) > 0 {
-			nextTripID = utils.FormCombinedID(agencyID, string(next))
-		}
-	case nil:
-		// Expected for the last trip in the block.
-	default:
-		slog.Warn("GetNextAndPreviousTripIDs: unexpected type for next_trip_id",
-			slog.String("type", fmt.Sprintf("%T", next)),
-			slog.String("trip_id", trip.ID))
+	if prev != "" {
+		previousTripID = utils.FormCombinedID(agencyID, prev)
+	}
+	if next != "" {
+		nextTripID = utils.FormCombinedID(agencyID, next)
 	}
 
 	stopTimes, err = api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trip.ID)
@@ -569,52 +569,9 @@ func (api *RestAPI) calculateBlockTripSequence(ctx context.Context, tripID strin
 
 // blockTripSequence returns the zero-based index of a trip within its block's
 // ordered sequence for the given service date, and whether it was resolved.
-// Uses GetBlockTripSequence with ROW_NUMBER() window function instead of fetching all trips and looping.
+// Block orderings are cached per service date by the GTFS manager.
 func (api *RestAPI) blockTripSequence(ctx context.Context, tripID string, serviceDate time.Time) (int, bool) {
-	trip, err := api.GtfsManager.GtfsDB.Queries.GetTrip(ctx, tripID)
-	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			slog.Warn("blockTripSequence: failed to get trip",
-				slog.String("trip_id", tripID),
-				slog.String("error", err.Error()))
-		}
-		return 0, false
-	}
-
-	if !trip.BlockID.Valid {
-		return 0, false
-	}
-
-	formattedDate := serviceDate.Format("20060102")
-	activeServiceIDs, err := api.GtfsManager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, formattedDate)
-	if err != nil {
-		slog.Warn("blockTripSequence: failed to get active service IDs",
-			slog.String("trip_id", tripID),
-			slog.String("date", formattedDate),
-			slog.String("error", err.Error()))
-		return 0, false
-	}
-	if len(activeServiceIDs) == 0 {
-		return 0, false
-	}
-
-	// Use optimized query with ROW_NUMBER() window function
-	seq, err := api.GtfsManager.GtfsDB.Queries.GetBlockTripSequence(ctx, gtfsdb.GetBlockTripSequenceParams{
-		TripID:     tripID,
-		BlockID:    trip.BlockID,
-		ServiceIds: activeServiceIDs,
-	})
-	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			slog.Warn("blockTripSequence: failed to get block trip sequence",
-				slog.String("trip_id", tripID),
-				slog.String("block_id", trip.BlockID.String),
-				slog.String("error", err.Error()))
-		}
-		return 0, false
-	}
-
-	return int(seq), true
+	return api.GtfsManager.BlockTripSequence(ctx, tripID, serviceDate)
 }
 
 // calculatePreciseDistanceAlongTripWithCoords calculates the distance along a trip's shape to a stop
@@ -720,49 +677,6 @@ func distanceToLineSegment(px, py, x1, y1, x2, y2 float64) (distance, ratio floa
 	return d, r
 }
 
-func (api *RestAPI) GetSituationIDsForTrip(ctx context.Context, tripID string) []string {
-	var routeID string
-	var agencyID string
-
-	if api.GtfsManager.GtfsDB != nil {
-		trip, err := api.GtfsManager.GtfsDB.Queries.GetTrip(ctx, tripID)
-		if err == nil {
-			routeID = trip.RouteID
-			route, err := api.GtfsManager.GtfsDB.Queries.GetRoute(ctx, routeID)
-			if err == nil {
-				agencyID = route.AgencyID
-			} else if !errors.Is(err, sql.ErrNoRows) {
-				api.Logger.Warn("Failed to fetch route for alerts; degrading to trip+route matching only",
-					slog.String("trip_id", tripID),
-					slog.String("route_id", routeID),
-					slog.Any("error", err),
-				)
-			}
-		} else if !errors.Is(err, sql.ErrNoRows) {
-			api.Logger.Warn("Failed to fetch trip for alerts; degrading to trip matching only",
-				slog.String("trip_id", tripID),
-				slog.Any("error", err),
-			)
-		}
-	}
-
-	alerts := api.GtfsManager.GetAlertsByIDs(tripID, routeID, agencyID)
-
-	situationIDs := []string{}
-	for _, alert := range alerts {
-		if alert.ID == "" {
-			continue
-		}
-		if agencyID != "" {
-			situationIDs = append(situationIDs, utils.FormCombinedID(agencyID, alert.ID))
-		} else {
-			situationIDs = append(situationIDs, alert.ID)
-		}
-	}
-
-	return situationIDs
-}
-
 func (api *RestAPI) calculateOffsetForStop(
 	stopID string,
 	stopTimes []*gtfsdb.StopTime,
@@ -920,6 +834,34 @@ func (api *RestAPI) calculateBatchStopDistances(
 	return stopTimesList
 }
 
+// historicalOccupancy returns the occupancy most often recorded for a trip at
+// the stop with the given sequence, or "" when arrival history is disabled or
+// has none.
+func (api *RestAPI) historicalOccupancy(ctx context.Context, tripID string, stopSequence int64) string {
+	arrivals, err := api.GtfsManager.HistoricalArrivals(ctx, tripID)
+	if err != nil {
+		api.Logger.Warn("failed to load arrival history", "tripID", tripID, "error", err)
+		return ""
+	}
+	return arrivals[stopSequence].Occupancy
+}
+
+// setHistoricalOccupancy fills in the historical occupancy of the stop times
+// built by calculateBatchStopDistances from stopTimes, all of one trip.
+func (api *RestAPI) setHistoricalOccupancy(ctx context.Context, stopTimes []gtfsdb.StopTime, stopTimesList []models.StopTime) {
+	if len(stopTimes) == 0 || len(stopTimes) != len(stopTimesList) {
+		return
+	}
+	arrivals, err := api.GtfsManager.HistoricalArrivals(ctx, stopTimes[0].TripID)
+	if err != nil {
+		api.Logger.Warn("failed to load arrival history", "tripID", stopTimes[0].TripID, "error", err)
+		return
+	}
+	for i, st := range stopTimes {
+		stopTimesList[i].HistoricalOccupancy = arrivals[st.StopSequence].Occupancy
+	}
+}
+
 func (api *RestAPI) findStopsByScheduleDeviation(
 	stopTimes []*gtfsdb.StopTime,
 	currentTime time.Time,
@@ -1017,7 +959,7 @@ func (api *RestAPI) findNextStopBySequence(
 				if i+1 < len(stopTimes) {
 					nextStop = stopTimes[i+1]
 				} else {
-					nextStop = api.getFirstStopOfNextTripInBlock(ctx, tripID)
+					nextStop = api.getFirstStopOfNextTripInBlock(ctx, tripID, serviceDate)
 				}
 			} else {
 				nextStop = st
@@ -1034,37 +976,31 @@ func (api *RestAPI) findNextStopBySequence(
 	return "", 0
 }
 
-// getFirstStopOfNextTripInBlock uses LEAD() window function to find the next trip
-// in the block and directly fetches its first stop in a single SQL query.
-func (api *RestAPI) getFirstStopOfNextTripInBlock(ctx context.Context, currentTripID string) *gtfsdb.StopTime {
-	trip, err := api.GtfsManager.GtfsDB.Queries.GetTrip(ctx, currentTripID)
+// getFirstStopOfNextTripInBlock returns the first stop of the trip that follows
+// currentTripID in its block on serviceDate, or nil if the block ends there.
+func (api *RestAPI) getFirstStopOfNextTripInBlock(ctx context.Context, currentTripID string, serviceDate time.Time) *gtfsdb.StopTime {
+	_, nextTripID, err := api.GtfsManager.BlockNeighbors(ctx, currentTripID, serviceDate)
 	if err != nil {
-		slog.Warn("getFirstStopOfNextTripInBlock: failed to get trip",
+		slog.Warn("getFirstStopOfNextTripInBlock: failed to resolve next block trip",
 			slog.String("trip_id", currentTripID),
 			slog.String("error", err.Error()))
 		return nil
 	}
-	if !trip.BlockID.Valid {
+	if nextTripID == "" {
 		return nil
 	}
 
-	// Use optimized query with LEAD() window function
-	stopTime, err := api.GtfsManager.GtfsDB.Queries.GetFirstStopOfNextTripInBlock(ctx, gtfsdb.GetFirstStopOfNextTripInBlockParams{
-		BlockID:    trip.BlockID,
-		ServiceIds: []string{trip.ServiceID},
-		TripID:     currentTripID,
-	})
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, nextTripID)
 	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			slog.Warn("getFirstStopOfNextTripInBlock: query failed",
-				slog.String("trip_id", currentTripID),
-				slog.String("block_id", trip.BlockID.String),
-				slog.String("error", err.Error()))
-		}
+		slog.Warn("getFirstStopOfNextTripInBlock: failed to get stop times",
+			slog.String("trip_id", nextTripID),
+			slog.String("error", err.Error()))
 		return nil
 	}
-
-	return &stopTime
+	if len(stopTimes) == 0 {
+		return nil
+	}
+	return &stopTimes[0]
 }
 
 func (api *RestAPI) calculateEffectiveDistanceAlongTrip(
@@ -1081,34 +1017,63 @@ func (api *RestAPI) calculateEffectiveDistanceAlongTrip(
 		return actualDistance
 	}
 
-	stopIDs := make([]string, len(stopTimes))
-	for i, st := range stopTimes {
-		stopIDs[i] = st.StopID
-	}
-	stops, err := api.GtfsManager.GtfsDB.Queries.GetStopsByIDs(ctx, stopIDs)
-	if err != nil {
+	stopDistances, ok := api.stopDistancesAlongShape(ctx, stopTimes, shapePoints, cumulativeDistances)
+	if !ok {
 		return actualDistance
 	}
-	stopByID := make(map[string]gtfsdb.Stop, len(stops))
-	for _, s := range stops {
-		stopByID[s.ID] = s
+
+	currentTimeSeconds := utils.CalculateSecondsSinceServiceDate(currentTime, serviceDate)
+	effectiveScheduleTime := currentTimeSeconds - int64(scheduleDeviation)
+
+	return interpolateDistanceAtScheduledTime(effectiveScheduleTime, stopTimes, stopDistances)
+}
+
+// stopDistancesAlongShape returns how far along the shape each of stopTimes
+// is, from its shape_dist_traveled or else by projecting its stop onto the
+// shape. ok is false when a stop cannot be found or ctx is done.
+func (api *RestAPI) stopDistancesAlongShape(
+	ctx context.Context,
+	stopTimes []gtfsdb.StopTime,
+	shapePoints []gtfs.ShapePoint,
+	cumulativeDistances []float64,
+) ([]float64, bool) {
+	// Stored shape distances follow the trip's own path; projecting stops
+	// onto the shape is only needed for stop times imported without one.
+	var missingStopIDs []string
+	for _, st := range stopTimes {
+		if !st.ShapeDistTraveled.Valid {
+			missingStopIDs = append(missingStopIDs, st.StopID)
+		}
+	}
+	stopByID := make(map[string]gtfsdb.Stop, len(missingStopIDs))
+	if len(missingStopIDs) > 0 {
+		stops, err := api.GtfsManager.GtfsDB.Queries.GetStopsByIDs(ctx, missingStopIDs)
+		if err != nil {
+			return nil, false
+		}
+		for _, s := range stops {
+			stopByID[s.ID] = s
+		}
 	}
 
 	stopDistances := make([]float64, len(stopTimes))
 	for i, st := range stopTimes {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		if st.ShapeDistTraveled.Valid {
+			stopDistances[i] = st.ShapeDistTraveled.Float64
+			continue
+		}
 		stop, ok := stopByID[st.StopID]
 		if !ok {
-			return actualDistance
+			return nil, false
 		}
 		stopDistances[i] = api.calculatePreciseDistanceAlongTripWithCoords(
 			stop.Lat, stop.Lon, shapePoints, cumulativeDistances,
 		)
 	}
-
-	currentTimeSeconds := utils.CalculateSecondsSinceServiceDate(currentTime, serviceDate)
-	effectiveScheduleTime := currentTimeSeconds - int64(scheduleDeviation)
-
-	return interpolateDistanceAtScheduledTime(effectiveScheduleTime, stopTimes, stopDistances)
+	return stopDistances, true
 }
 
 func interpolateDistanceAtScheduledTime(
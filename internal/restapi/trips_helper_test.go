@@ -3,7 +3,9 @@ package restapi
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -1548,6 +1550,29 @@ func TestInterpolateDistanceAtScheduledTime_MultipleSegments(t *testing.T) {
 	assert.InDelta(t, 1000.0, d, 0.01)
 }
 
+func TestCalculateEffectiveDistanceAlongTrip_UsesStoredShapeDistances(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	// The stops are unknown, so projecting them onto a shape would fail and
+	// leave the actual distance; the stored distances need no lookup.
+	stopTimes := []gtfsdb.StopTime{
+		{StopID: "unknown_1", ArrivalTime: secondsToNanos(100), DepartureTime: secondsToNanos(100), ShapeDistTraveled: sql.NullFloat64{Float64: 0, Valid: true}},
+		{StopID: "unknown_2", ArrivalTime: secondsToNanos(200), DepartureTime: secondsToNanos(200), ShapeDistTraveled: sql.NullFloat64{Float64: 300, Valid: true}},
+		{StopID: "unknown_3", ArrivalTime: secondsToNanos(300), DepartureTime: secondsToNanos(300), ShapeDistTraveled: sql.NullFloat64{Float64: 2300, Valid: true}},
+	}
+	serviceDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 250s into the day and 100s late puts the vehicle's schedule at 150s,
+	// halfway through the short first segment.
+	d := api.calculateEffectiveDistanceAlongTrip(t.Context(), 9999, 100, serviceDate.Add(250*time.Second), serviceDate, stopTimes, nil, nil)
+	assert.InDelta(t, 150.0, d, 0.01)
+
+	// At 250s the schedule is halfway through the long second segment.
+	d = api.calculateEffectiveDistanceAlongTrip(t.Context(), 9999, 100, serviceDate.Add(350*time.Second), serviceDate, stopTimes, nil, nil)
+	assert.InDelta(t, 1300.0, d, 0.01)
+}
+
 func TestGetDistanceAlongShape_Projection(t *testing.T) {
 	shape := []gtfs.ShapePoint{
 		{Latitude: 0.0, Longitude: 0.0},
@@ -1622,35 +1647,37 @@ func TestGetFirstStopOfNextTripInBlock_WithBlockContinuation(t *testing.T) {
 	defer api.Shutdown()
 	ctx := context.Background()
 
-	// Find a trip that belongs to a block with at least two trips.
-	trips, err := api.GtfsManager.GetTrips(ctx, 100)
+	// Find a trip followed in its block by another trip of the same service.
+	var targetTripID, serviceID string
+	err := api.GtfsManager.GtfsDB.DB.QueryRowContext(ctx, `
+		SELECT a.trip_id, a.service_id FROM block_trip_order a
+		JOIN block_trip_order b ON b.block_id = a.block_id
+			AND b.block_sequence = a.block_sequence + 1
+			AND b.service_id = a.service_id
+		LIMIT 1`).Scan(&targetTripID, &serviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		t.Skip("no multi-trip block found in test data; skipping block continuation test")
+	}
 	require.NoError(t, err)
-	require.NotEmpty(t, trips, "need at least one trip in test data")
 
-	// Locate a trip that has a block ID and more than one trip in the block.
-	var targetTripID string
-	for _, trip := range trips {
-		if !trip.BlockID.Valid || trip.BlockID.String == "" {
-			continue
-		}
-		blockID := trip.BlockID.String
-		count := 0
-		for _, other := range trips {
-			if other.BlockID.String == blockID {
-				count++
-			}
-		}
-		if count >= 2 {
-			targetTripID = trip.ID
+	// Pick a date in the service's first week on which it runs.
+	var startDate string
+	require.NoError(t, api.GtfsManager.GtfsDB.DB.QueryRowContext(ctx,
+		"SELECT start_date FROM calendar WHERE id = ?", serviceID).Scan(&startDate))
+	first, err := time.Parse("20060102", startDate)
+	require.NoError(t, err)
+	var serviceDate time.Time
+	for day := first; day.Before(first.AddDate(0, 0, 7)); day = day.AddDate(0, 0, 1) {
+		active, err := api.GtfsManager.GtfsDB.Queries.GetActiveServiceIDsForDate(ctx, day.Format("20060102"))
+		require.NoError(t, err)
+		if slices.Contains(active, serviceID) {
+			serviceDate = day
 			break
 		}
 	}
+	require.False(t, serviceDate.IsZero(), "service %s should run in its first week", serviceID)
 
-	if targetTripID == "" {
-		t.Skip("no multi-trip block found in test data; skipping block continuation test")
-	}
-
-	result := api.getFirstStopOfNextTripInBlock(ctx, targetTripID)
+	result := api.getFirstStopOfNextTripInBlock(ctx, targetTripID, serviceDate)
 	require.NotNil(t, result, "should find the first stop of the next block trip")
 	assert.NotEmpty(t, result.StopID, "returned stop should have a non-empty StopID")
 }
@@ -2056,3 +2083,43 @@ func testTripIDs(trips []gtfsdb.Trip) []string {
 	}
 	return ids
 }
+
+func TestBuildTripStatus_StartTimes(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	ctx := context.Background()
+
+	agencies := mustGetAgencies(t, api)
+	require.NotEmpty(t, agencies)
+	agency := agencies[0]
+	trip := mustGetTrip(t, api)
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, trip.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, stopTimes)
+	first := stopTimes[0]
+
+	loc, err := time.LoadLocation(agency.Timezone)
+	require.NoError(t, err)
+	serviceDate := time.Date(2024, 11, 4, 0, 0, 0, 0, loc)
+	scheduledStart := serviceDate.Add(time.Duration(first.DepartureTime))
+
+	status, err := api.BuildTripStatus(ctx, agency.ID, trip.ID, nil, serviceDate, scheduledStart)
+	require.NoError(t, err)
+	assert.Equal(t, scheduledStart.UnixMilli(), status.ScheduledStartTime.UnixMilli())
+	assert.True(t, status.ActualStartTime.IsZero(), "no start has been observed")
+
+	// The trip update reports the departure from the first stop two minutes late.
+	actualStart := scheduledStart.Add(2 * time.Minute)
+	sequence := uint32(first.StopSequence)
+	api.GtfsManager.MockAddTripUpdate(trip.ID, nil, []gtfs.StopTimeUpdate{{
+		StopSequence: &sequence,
+		Departure:    &gtfs.StopTimeEvent{Time: &actualStart},
+	}})
+	api.GtfsManager.MockObserveTripStarts(actualStart.Add(time.Minute))
+
+	status, err = api.BuildTripStatus(ctx, agency.ID, trip.ID, nil, serviceDate, actualStart.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, scheduledStart.UnixMilli(), status.ScheduledStartTime.UnixMilli())
+	assert.Equal(t, actualStart.UnixMilli(), status.ActualStartTime.UnixMilli())
+}
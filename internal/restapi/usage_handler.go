@@ -0,0 +1,59 @@
+package restapi
+
+import (
+	"math"
+	"net/http"
+
+	"maglev.onebusaway.org/internal/models"
+)
+
+// usageHandler reports the calling key's rate limit buckets and the requests
+// it has made today, so developers can diagnose throttling themselves. The
+// request itself is counted.
+func (api *RestAPI) usageHandler(w http.ResponseWriter, r *http.Request) {
+	apiKey := r.URL.Query().Get("key")
+	usage := models.KeyUsage{
+		KeyFingerprint:     models.APIKeyFingerprint(apiKey),
+		RateLimit:          models.RateLimitWindow{Unlimited: true},
+		Day:                api.Clock.Now().UTC().Format(usageDayLayout),
+		RequestsByEndpoint: map[string]int64{},
+	}
+
+	if api.rateLimiter != nil {
+		status := api.rateLimiter.Status(r.Context(), apiKey, remoteAddr(r))
+		usage.RateLimit = models.RateLimitWindow{
+			Exempt:          status.Exempt,
+			Unlimited:       status.Unlimited,
+			Limit:           status.Limit,
+			RefillPerSecond: status.RefillPerSecond,
+			Remaining:       status.Remaining,
+		}
+	}
+
+	limiter, err := api.issuedKeyLimiter(r.Context(), apiKey)
+	if err != nil {
+		api.serverErrorResponse(w, r, err)
+		return
+	}
+	if limiter != nil {
+		usage.KeyRateLimit = &models.RateLimitWindow{
+			Limit:           limiter.Burst(),
+			RefillPerSecond: float64(limiter.Limit()),
+			Remaining:       max(0, int(math.Floor(limiter.Tokens()))),
+		}
+	}
+
+	if api.usage != nil {
+		today, err := api.usage.Report(r.Context(), usage.Day, usage.KeyFingerprint)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		for _, u := range today {
+			usage.RequestsToday += u.Requests
+			usage.RequestsByEndpoint[u.Endpoint] += u.Requests
+		}
+	}
+
+	api.sendResponse(w, r, models.NewEntryResponse(usage, *models.NewEmptyReferences(), api.Clock))
+}
@@ -0,0 +1,68 @@
+package restapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/models"
+)
+
+type keyUsageResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		Entry models.KeyUsage `json:"entry"`
+	} `json:"data"`
+}
+
+func TestUsageReportsConfiguredKey(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	// Other tests share the database and the TEST key, so compare against a
+	// first report.
+	_, before := callAPIHandler[keyUsageResponse](t, api, "/api/where/usage.json?key=TEST")
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/current-time.json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, model := callAPIHandler[keyUsageResponse](t, api, "/api/where/usage.json?key=TEST")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	usage := model.Data.Entry
+	assert.Equal(t, models.APIKeyFingerprint("TEST"), usage.KeyFingerprint)
+	assert.Equal(t, 100, usage.RateLimit.Limit)
+	assert.Less(t, usage.RateLimit.Remaining, 100, "the requests drew from the shared bucket")
+	assert.Nil(t, usage.KeyRateLimit, "configured keys have no bucket of their own")
+	assert.Equal(t, api.Clock.Now().UTC().Format(usageDayLayout), usage.Day)
+	assert.Equal(t, before.Data.Entry.RequestsToday+2, usage.RequestsToday)
+	assert.Equal(t, before.Data.Entry.RequestsByEndpoint["current-time"]+1, usage.RequestsByEndpoint["current-time"])
+	assert.Equal(t, before.Data.Entry.RequestsByEndpoint["usage"]+1, usage.RequestsByEndpoint["usage"])
+}
+
+func TestUsageReportsIssuedKeyBucket(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	_, created := callAPIHandler[apiKeyResponse](t, api,
+		"/api/where/create-api-key.json?key=PROTECTED-TEST&rateLimit=10&email="+url.QueryEscape("usage@example.org"))
+	key := created.Data.Entry.Key
+	require.NotEmpty(t, key)
+
+	resp, model := callAPIHandler[keyUsageResponse](t, api, "/api/where/usage.json?key="+key)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	usage := model.Data.Entry
+	require.NotNil(t, usage.KeyRateLimit)
+	assert.Equal(t, 10, usage.KeyRateLimit.Limit)
+	assert.InDelta(t, 10, usage.KeyRateLimit.RefillPerSecond, 0.001)
+	assert.Equal(t, 9, usage.KeyRateLimit.Remaining, "the usage request itself took a token")
+	assert.Equal(t, int64(1), usage.RequestsToday)
+}
+
+func TestUsageRequiresValidKey(t *testing.T) {
+	api := createKeyTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := serveApiAndRetrieveEndpoint(t, api, "/api/where/usage.json?key=nope")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
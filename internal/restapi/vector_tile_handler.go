@@ -0,0 +1,259 @@
+package restapi
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/tiles"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// stopsMinZoom is the lowest zoom at which the stops layer is included. Below
+// it a tile can hold thousands of stops, too dense to be useful on a map.
+const stopsMinZoom = 12
+
+// maxCachedVectorTiles bounds the encoded tile cache. A city-scale network at
+// typical zoom levels is covered by a few hundred tiles.
+const maxCachedVectorTiles = 1024
+
+// vectorTileHandler serves route shapes and stops as a Mapbox vector tile
+// with a "routes" layer of route geometry and, from stopsMinZoom, a "stops"
+// layer.
+func (api *RestAPI) vectorTileHandler(w http.ResponseWriter, r *http.Request) {
+	tile, ok := parseTilePath(r)
+	if !ok {
+		api.validationErrorResponse(w, r, map[string][]string{
+			"tile": {"tile coordinates must be valid integers in the form /tiles/{z}/{x}/{y}.mvt"},
+		})
+		return
+	}
+
+	// Keying on the static data hash means a reload naturally stops serving
+	// tiles built from the old feed, or before a suppression list change.
+	cacheKey := api.staticETag(r.Context()) + "/" + tile.String()
+	data, ok := api.tileCache.get(cacheKey)
+	if !ok {
+		var err error
+		data, err = api.buildVectorTile(r.Context(), tile)
+		if err != nil {
+			api.serverErrorResponse(w, r, err)
+			return
+		}
+		api.tileCache.put(cacheKey, data)
+	}
+
+	w.Header().Set("Content-Type", tiles.ContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func parseTilePath(r *http.Request) (tiles.Tile, bool) {
+	yStr, found := strings.CutSuffix(r.PathValue("y"), ".mvt")
+	if !found {
+		return tiles.Tile{}, false
+	}
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	y, errY := strconv.Atoi(yStr)
+	if errZ != nil || errX != nil || errY != nil {
+		return tiles.Tile{}, false
+	}
+	tile, err := tiles.NewTile(z, x, y)
+	return tile, err == nil
+}
+
+func (api *RestAPI) buildVectorTile(ctx context.Context, tile tiles.Tile) ([]byte, error) {
+	queries := api.GtfsManager.GtfsDB.Queries
+	suppressed := api.suppression()
+	minLat, minLon, maxLat, maxLon := tile.Bounds(float64(tiles.DefaultBuffer) / tiles.DefaultExtent)
+
+	routesLayer, err := api.buildRoutesLayer(ctx, queries, suppressed, tile, gtfsdb.GetShapeIDsWithinBoundsParams{
+		MinLat: minLat, MaxLat: maxLat, MinLon: minLon, MaxLon: maxLon,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stopsLayer *tiles.Layer
+	if tile.Z >= stopsMinZoom {
+		stopsLayer, err = buildStopsLayer(ctx, queries, suppressed, tile, gtfsdb.GetActiveStopsWithinBoundsParams{
+			MinLat: minLat, MaxLat: maxLat, MinLon: minLon, MaxLon: maxLon,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tiles.Encode(routesLayer, stopsLayer), nil
+}
+
+// buildRoutesLayer emits one feature per route, made up of every shape of
+// that route that passes through the tile. Suppressed routes are left out.
+func (api *RestAPI) buildRoutesLayer(ctx context.Context, queries *gtfsdb.Queries, suppressed *suppressionList, tile tiles.Tile, bounds gtfsdb.GetShapeIDsWithinBoundsParams) (*tiles.Layer, error) {
+	layer := tiles.NewLayer("routes")
+
+	shapeIDs, err := queries.GetShapeIDsWithinBounds(ctx, bounds)
+	if err != nil || len(shapeIDs) == 0 {
+		return layer, err
+	}
+
+	nullShapeIDs := make([]sql.NullString, len(shapeIDs))
+	for i, id := range shapeIDs {
+		nullShapeIDs[i] = sql.NullString{String: id, Valid: true}
+	}
+	routeRows, err := queries.GetRoutesForShapeIDs(ctx, nullShapeIDs)
+	if err != nil {
+		return nil, err
+	}
+	pointRows, err := queries.GetShapePointsByIDs(ctx, shapeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	shapeLines := make(map[string][]tiles.Point)
+	for _, p := range pointRows {
+		shapeLines[p.ShapeID] = append(shapeLines[p.ShapeID], tile.Project(p.Lat, p.Lon, tiles.DefaultExtent))
+	}
+
+	// Rows are ordered by shape then route; regroup by route, keeping the order
+	// in which routes are first seen so the output is deterministic.
+	var routeOrder []string
+	routeShapes := make(map[string][]string)
+	routeInfo := make(map[string]gtfsdb.GetRoutesForShapeIDsRow)
+	for _, row := range routeRows {
+		if suppressed.routeHidden(utils.FormCombinedID(row.AgencyID, row.RouteID)) {
+			continue
+		}
+		if _, seen := routeInfo[row.RouteID]; !seen {
+			routeOrder = append(routeOrder, row.RouteID)
+			routeInfo[row.RouteID] = row
+		}
+		routeShapes[row.RouteID] = append(routeShapes[row.RouteID], row.ShapeID.String)
+	}
+
+	for i, routeID := range routeOrder {
+		var lines [][]tiles.Point
+		for _, shapeID := range routeShapes[routeID] {
+			lines = append(lines, tiles.ClipLine(shapeLines[shapeID], tiles.DefaultExtent, tiles.DefaultBuffer)...)
+		}
+		route := routeInfo[routeID]
+		props := map[string]any{
+			"id":        utils.FormCombinedID(route.AgencyID, route.RouteID),
+			"agencyId":  route.AgencyID,
+			"type":      route.Type,
+			"shortName": route.ShortName.String,
+			"longName":  route.LongName.String,
+		}
+		if route.Color.Valid && route.Color.String != "" {
+			props["color"] = route.Color.String
+		}
+		layer.AddLineStrings(uint64(i+1), lines, props)
+	}
+	return layer, nil
+}
+
+// buildStopsLayer emits one point per active stop in the tile, leaving out
+// suppressed stops.
+func buildStopsLayer(ctx context.Context, queries *gtfsdb.Queries, suppressed *suppressionList, tile tiles.Tile, bounds gtfsdb.GetActiveStopsWithinBoundsParams) (*tiles.Layer, error) {
+	layer := tiles.NewLayer("stops")
+
+	stops, err := queries.GetActiveStopsWithinBounds(ctx, bounds)
+	if err != nil || len(stops) == 0 {
+		return layer, err
+	}
+
+	stopIDs := make([]string, len(stops))
+	for i, stop := range stops {
+		stopIDs[i] = stop.ID
+	}
+	agencyRows, err := queries.GetAgenciesForStops(ctx, stopIDs)
+	if err != nil {
+		return nil, err
+	}
+	stopAgency := make(map[string]string, len(agencyRows))
+	for _, row := range agencyRows {
+		if _, exists := stopAgency[row.StopID]; !exists {
+			stopAgency[row.StopID] = row.ID
+		}
+	}
+
+	for i, stop := range stops {
+		agencyID, ok := stopAgency[stop.ID]
+		if !ok {
+			continue
+		}
+		stopID := utils.FormCombinedID(agencyID, stop.ID)
+		if suppressed.stopHidden(stopID) {
+			continue
+		}
+		props := map[string]any{
+			"id":   stopID,
+			"name": stop.Name.String,
+		}
+		if stop.Code.Valid && stop.Code.String != "" {
+			props["code"] = stop.Code.String
+		}
+		layer.AddPoint(uint64(i+1), tile.Project(stop.Lat, stop.Lon, tiles.DefaultExtent), props)
+	}
+	return layer, nil
+}
+
+// vectorTileCache is a small LRU of encoded tiles. A nil cache stores nothing.
+type vectorTileCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // most recently used at the front
+	entries map[string]*list.Element
+}
+
+type vectorTileEntry struct {
+	key  string
+	data []byte
+}
+
+func newVectorTileCache(max int) *vectorTileCache {
+	return &vectorTileCache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *vectorTileCache) get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*vectorTileEntry).data, true
+}
+
+func (c *vectorTileCache) put(key string, data []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*vectorTileEntry).data = data
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&vectorTileEntry{key: key, data: data})
+	if c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*vectorTileEntry).key)
+	}
+}
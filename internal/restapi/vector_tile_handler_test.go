@@ -0,0 +1,142 @@
+package restapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"maglev.onebusaway.org/internal/tiles"
+)
+
+// tileContaining returns the z/x/y path of the tile containing a position.
+func tileContaining(z int, lat, lon float64) string {
+	world := tiles.Tile{}.Project(lat, lon, 1<<z)
+	return fmt.Sprintf("%d/%d/%d", z, world.X, world.Y)
+}
+
+// tileLayerNames decodes just the layer names from an encoded vector tile.
+func tileLayerNames(t *testing.T, b []byte) []string {
+	t.Helper()
+	var names []string
+	for len(b) > 0 {
+		num, _, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		require.Equal(t, protowire.Number(3), num)
+		layer, m := protowire.ConsumeBytes(b[n:])
+		require.GreaterOrEqual(t, m, 0)
+		b = b[n+m:]
+		for len(layer) > 0 {
+			lnum, ltyp, ln := protowire.ConsumeTag(layer)
+			require.GreaterOrEqual(t, ln, 0)
+			fieldLen := protowire.ConsumeFieldValue(lnum, ltyp, layer[ln:])
+			require.GreaterOrEqual(t, fieldLen, 0)
+			if lnum == 1 {
+				name, _ := protowire.ConsumeString(layer[ln:])
+				names = append(names, name)
+			}
+			layer = layer[ln+fieldLen:]
+		}
+	}
+	return names
+}
+
+func getTile(t *testing.T, api *RestAPI, path string) (*http.Response, []byte) {
+	t.Helper()
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+	resp, err := http.Get(server.URL + path)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestVectorTileHandlerServesRoutesAndStops(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	stop := mustGetStop(t, api)
+
+	resp, body := getTile(t, api, "/tiles/"+tileContaining(14, stop.Lat, stop.Lon)+".mvt?key=TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, tiles.ContentType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+	assert.ElementsMatch(t, []string{"routes", "stops"}, tileLayerNames(t, body))
+}
+
+func TestVectorTileHandlerOmitsStopsAtLowZoom(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	stop := mustGetStop(t, api)
+
+	resp, body := getTile(t, api, "/tiles/"+tileContaining(8, stop.Lat, stop.Lon)+".mvt?key=TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"routes"}, tileLayerNames(t, body))
+}
+
+func TestVectorTileHandlerEmptyTile(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, body := getTile(t, api, "/tiles/14/0/0.mvt?key=TEST")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, body)
+}
+
+func TestVectorTileHandlerCachesTiles(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	stop := mustGetStop(t, api)
+	path := "/tiles/" + tileContaining(13, stop.Lat, stop.Lon) + ".mvt?key=TEST"
+
+	_, first := getTile(t, api, path)
+	assert.Equal(t, 1, api.tileCache.order.Len())
+	_, second := getTile(t, api, path)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, api.tileCache.order.Len())
+}
+
+func TestVectorTileHandlerRejectsInvalidTiles(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	for _, path := range []string{"/tiles/2/4/0.mvt", "/tiles/a/0/0.mvt", "/tiles/1/0/0.png", "/tiles/23/0/0.mvt"} {
+		t.Run(path, func(t *testing.T) {
+			resp, _ := getTile(t, api, path+"?key=TEST")
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestVectorTileHandlerRequiresApiKey(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	resp, _ := getTile(t, api, "/tiles/0/0/0.mvt")
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestVectorTileCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newVectorTileCache(2)
+	cache.put("a", []byte("a"))
+	cache.put("b", []byte("b"))
+	_, _ = cache.get("a")
+	cache.put("c", []byte("c"))
+
+	_, okA := cache.get("a")
+	_, okB := cache.get("b")
+	_, okC := cache.get("c")
+	assert.True(t, okA)
+	assert.False(t, okB)
+	assert.True(t, okC)
+}
@@ -0,0 +1,135 @@
+package restapi
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+// interpolatedDistance estimates how far along the shape the vehicle is at
+// now, given actualDistance, where its last update placed it. The vehicle is
+// advanced for the time since that update, up to the configured maximum, at
+// its reported speed or else at the pace stopTimes schedule from where it
+// is. ok is false when interpolation is off, the vehicle is stopped or stale,
+// or it would not move.
+func (api *RestAPI) interpolatedDistance(
+	ctx context.Context,
+	vehicle *gtfs.Vehicle,
+	actualDistance float64,
+	now time.Time,
+	stopTimes []gtfsdb.StopTime,
+	shapePoints []gtfs.ShapePoint,
+	cumulativeDistances []float64,
+) (float64, bool) {
+	cfg := api.Config.Interpolation.WithDefaults()
+	if !cfg.Enabled || vehicle == nil || vehicle.Timestamp == nil || vehicle.Position == nil {
+		return 0, false
+	}
+	if vehicle.CurrentStatus != nil && *vehicle.CurrentStatus == gtfsrt.VehiclePosition_STOPPED_AT {
+		return 0, false
+	}
+	if defaultStaleDetector.Check(vehicle, now) {
+		return 0, false
+	}
+	elapsed := min(now.Sub(*vehicle.Timestamp), cfg.MaxElapsed)
+	if elapsed <= 0 || len(cumulativeDistances) < 2 {
+		return 0, false
+	}
+
+	var distance float64
+	if vehicle.Position.Speed != nil {
+		distance = actualDistance + float64(*vehicle.Position.Speed)*elapsed.Seconds()
+	} else {
+		stopDistances, ok := api.stopDistancesAlongShape(ctx, stopTimes, shapePoints, cumulativeDistances)
+		if !ok {
+			return 0, false
+		}
+		scheduledTime, ok := scheduledTimeAtDistance(actualDistance, stopTimes, stopDistances)
+		if !ok {
+			return 0, false
+		}
+		distance = interpolateDistanceAtScheduledTime(scheduledTime+int64(elapsed/time.Second), stopTimes, stopDistances)
+	}
+
+	distance = min(distance, cumulativeDistances[len(cumulativeDistances)-1])
+	if distance <= actualDistance {
+		return 0, false
+	}
+	return distance, true
+}
+
+// scheduledTimeAtDistance inverts interpolateDistanceAtScheduledTime: it
+// returns when, in seconds since the service date, the schedule has the
+// vehicle at distance. A vehicle at a stop is taken to be leaving it. ok is
+// false before the first stop and past the last one.
+func scheduledTimeAtDistance(distance float64, stopTimes []gtfsdb.StopTime, stopDistances []float64) (int64, bool) {
+	if len(stopTimes) < 2 || len(stopDistances) != len(stopTimes) {
+		return 0, false
+	}
+	for i := 0; i < len(stopTimes)-1; i++ {
+		fromDistance, toDistance := stopDistances[i], stopDistances[i+1]
+		if distance < fromDistance || distance >= toDistance {
+			continue
+		}
+		fromTime := utils.NanosToSeconds(stopTimes[i].DepartureTime)
+		toTime := utils.NanosToSeconds(stopTimes[i+1].ArrivalTime)
+		ratio := (distance - fromDistance) / (toDistance - fromDistance)
+		return fromTime + int64(ratio*float64(toTime-fromTime)), true
+	}
+	return 0, false
+}
+
+// pointAlongShape returns the point distance meters along the shape, given
+// the shape's cumulative distances. Distances past either end are clamped to
+// it.
+func pointAlongShape(shapePoints []gtfs.ShapePoint, cumulativeDistances []float64, distance float64) models.Location {
+	i := sort.SearchFloat64s(cumulativeDistances, distance)
+	if i == 0 {
+		return models.Location{Lat: shapePoints[0].Latitude, Lon: shapePoints[0].Longitude}
+	}
+	if i >= len(shapePoints) {
+		last := shapePoints[len(shapePoints)-1]
+		return models.Location{Lat: last.Latitude, Lon: last.Longitude}
+	}
+	from, to := shapePoints[i-1], shapePoints[i]
+	var ratio float64
+	if segment := cumulativeDistances[i] - cumulativeDistances[i-1]; segment > 0 {
+		ratio = (distance - cumulativeDistances[i-1]) / segment
+	}
+	return models.Location{
+		Lat: from.Latitude + ratio*(to.Latitude-from.Latitude),
+		Lon: from.Longitude + ratio*(to.Longitude-from.Longitude),
+	}
+}
+
+// interpolatedVehicleLocation returns the vehicle's position advanced along
+// the shape of tripID to now (see interpolatedDistance), for endpoints that
+// report vehicles without building a full trip status. shapeCache holds the
+// shape points already fetched for this request, keyed by trip ID.
+func (api *RestAPI) interpolatedVehicleLocation(ctx context.Context, vehicle *gtfs.Vehicle, tripID string, now time.Time, shapeCache map[string][]gtfs.ShapePoint) (models.Location, bool) {
+	if !api.Config.Interpolation.Enabled || vehicle == nil || vehicle.Position == nil ||
+		vehicle.Position.Latitude == nil || vehicle.Position.Longitude == nil {
+		return models.Location{}, false
+	}
+	shapePoints := api.cachedShapePoints(ctx, tripID, shapeCache)
+	if len(shapePoints) < 2 {
+		return models.Location{}, false
+	}
+	stopTimes, err := api.GtfsManager.GtfsDB.Queries.GetStopTimesForTrip(ctx, tripID)
+	if err != nil {
+		return models.Location{}, false
+	}
+	cumulativeDistances := preCalculateCumulativeDistances(shapePoints)
+	actualDistance := api.getVehicleDistanceAlongShapeContextual(ctx, tripID, vehicle)
+	distance, ok := api.interpolatedDistance(ctx, vehicle, actualDistance, now, stopTimes, shapePoints, cumulativeDistances)
+	if !ok {
+		return models.Location{}, false
+	}
+	return pointAlongShape(shapePoints, cumulativeDistances, distance), true
+}
@@ -0,0 +1,104 @@
+package restapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestPointAlongShape(t *testing.T) {
+	shape := []gtfs.ShapePoint{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 0.01}, {Latitude: 0.01, Longitude: 0.01}}
+	distances := preCalculateCumulativeDistances(shape)
+
+	assert.Equal(t, models.Location{Lat: 0, Lon: 0}, pointAlongShape(shape, distances, -5))
+	assert.Equal(t, models.Location{Lat: 0.01, Lon: 0.01}, pointAlongShape(shape, distances, distances[2]+5))
+	mid := pointAlongShape(shape, distances, distances[1]/2)
+	assert.InDelta(t, 0, mid.Lat, 1e-9)
+	assert.InDelta(t, 0.005, mid.Lon, 1e-9)
+	corner := pointAlongShape(shape, distances, distances[1])
+	assert.InDelta(t, 0.01, corner.Lon, 1e-9)
+}
+
+func TestScheduledTimeAtDistance(t *testing.T) {
+	stopTimes := []gtfsdb.StopTime{
+		{ArrivalTime: int64(10 * time.Minute), DepartureTime: int64(11 * time.Minute)},
+		{ArrivalTime: int64(15 * time.Minute), DepartureTime: int64(15 * time.Minute)},
+		{ArrivalTime: int64(20 * time.Minute), DepartureTime: int64(20 * time.Minute)},
+	}
+	stopDistances := []float64{0, 1000, 1500}
+
+	at, ok := scheduledTimeAtDistance(0, stopTimes, stopDistances)
+	require.True(t, ok)
+	assert.Equal(t, int64(11*60), at, "a vehicle at a stop is leaving it")
+
+	at, ok = scheduledTimeAtDistance(500, stopTimes, stopDistances)
+	require.True(t, ok)
+	assert.Equal(t, int64(13*60), at)
+	assert.InDelta(t, 500, interpolateDistanceAtScheduledTime(at, stopTimes, stopDistances), 1e-9)
+
+	_, ok = scheduledTimeAtDistance(-1, stopTimes, stopDistances)
+	assert.False(t, ok)
+	_, ok = scheduledTimeAtDistance(1500, stopTimes, stopDistances)
+	assert.False(t, ok, "past the last stop the schedule has no pace")
+}
+
+func TestVehiclesForAgencyHandler_Interpolation(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	trip := mustGetTrip(t, api)
+	shapeRows, err := api.GtfsManager.GtfsDB.Queries.GetShapePointsByTripID(context.Background(), trip.ID)
+	require.NoError(t, err)
+	require.Greater(t, len(shapeRows), 10)
+	start := shapeRows[5]
+	lat, lon := float32(start.Lat), float32(start.Lon)
+	speed := float32(5)
+	updated := time.Now().Add(-30 * time.Second)
+	api.GtfsManager.MockAddVehicleWithOptions("v_interpolated", trip.ID, trip.RouteID, internalgtfs.MockVehicleOptions{
+		Position:  &gtfs.Position{Latitude: &lat, Longitude: &lon, Speed: &speed},
+		Timestamp: &updated,
+	})
+
+	find := func() models.VehicleStatus {
+		t.Helper()
+		_, model := callAPIHandler[VehiclesForAgencyResponse](t, api, vehiclesForAgencyURL(testdata.Raba.ID))
+		for _, vehicle := range model.Data.List {
+			if vehicle.VehicleID == "v_interpolated" {
+				return vehicle
+			}
+		}
+		require.FailNow(t, "the mock vehicle is not listed")
+		return models.VehicleStatus{}
+	}
+
+	vehicle := find()
+	assert.False(t, vehicle.LocationInterpolated, "interpolation is opt-in")
+	require.NotNil(t, vehicle.Location)
+	assert.InDelta(t, start.Lat, vehicle.Location.Lat, 1e-5)
+
+	api.Config.Interpolation.Enabled = true
+	vehicle = find()
+	assert.True(t, vehicle.LocationInterpolated)
+	require.NotNil(t, vehicle.TripStatus)
+	assert.True(t, vehicle.TripStatus.PositionInterpolated)
+	assert.Equal(t, *vehicle.Location, vehicle.TripStatus.Position)
+	moved := utils.Distance(start.Lat, start.Lon, vehicle.Location.Lat, vehicle.Location.Lon)
+	assert.Greater(t, moved, 10.0)
+	assert.LessOrEqual(t, moved, 5*30+1.0, "at most 30 seconds at 5 m/s along the shape")
+
+	status, err := api.BuildTripStatus(context.Background(), testdata.Raba.ID, trip.ID, nil, time.Now(), time.Now())
+	require.NoError(t, err)
+	assert.True(t, status.PositionInterpolated)
+	require.NotNil(t, status.LastKnownLocation)
+	assert.InDelta(t, start.Lat, status.LastKnownLocation.Lat, 1e-5, "the last known location stays the reported one")
+}
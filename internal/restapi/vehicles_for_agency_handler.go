@@ -5,9 +5,10 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/OneBusAway/go-gtfs"
 	"maglev.onebusaway.org/gtfsdb"
+	internalgtfs "maglev.onebusaway.org/internal/gtfs"
 	"maglev.onebusaway.org/internal/models"
-	"maglev.onebusaway.org/internal/nulls"
 	"maglev.onebusaway.org/internal/utils"
 )
 
@@ -95,6 +96,8 @@ func (api *RestAPI) vehiclesForAgencyHandler(w http.ResponseWriter, r *http.Requ
 	// Maps to build references
 	routeRefs := make(map[string]models.Route)
 	tripRefs := make(map[string]models.Trip)
+	// Vehicles on the same trip share a shape; fetch it once per request.
+	shapeCache := make(map[string][]gtfs.ShapePoint)
 
 	for _, vehicle := range vehiclesForAgency {
 		if ctx.Err() != nil {
@@ -127,6 +130,8 @@ func (api *RestAPI) vehiclesForAgencyHandler(w http.ResponseWriter, r *http.Requ
 			}
 		}
 
+		vehicleStatus.Bearing = vehicleBearing(&vehicle)
+
 		// Set status and phase based on current status
 		vehicleStatus.Status, vehicleStatus.Phase = GetVehicleStatusAndPhase(&vehicle)
 
@@ -156,15 +161,16 @@ func (api *RestAPI) vehiclesForAgencyHandler(w http.ResponseWriter, r *http.Requ
 				}
 			}
 
-			// Add orientation if available (convert from GTFS bearing to OBA orientation)
-			if vehicle.Position != nil && vehicle.Position.Bearing != nil {
-				// Convert from GTFS bearing (0° = North, 90° = East) to OBA orientation (0° = East, 90° = North)
-				// OBA orientation = (90 - GTFS bearing) mod 360
-				obaOrientation := (90 - *vehicle.Position.Bearing)
-				if obaOrientation < 0 {
-					obaOrientation += 360
-				}
-				tripStatus.Orientation = float64(obaOrientation)
+			if location, ok := api.interpolatedVehicleLocation(ctx, &vehicle, activeTripID, referenceTime, shapeCache); ok {
+				vehicleStatus.Location = &location
+				vehicleStatus.LocationInterpolated = true
+				tripStatus.Position = location
+				tripStatus.PositionInterpolated = true
+			}
+
+			if orientation, ok := api.vehicleOrientation(ctx, &vehicle, activeTripID, shapeCache); ok {
+				tripStatus.Orientation = orientation
+				tripStatus.LastKnownOrientation = orientation
 			}
 
 			// Trip status update times default to 0 when no real update exists.
@@ -180,11 +186,9 @@ func (api *RestAPI) vehiclesForAgencyHandler(w http.ResponseWriter, r *http.Requ
 			// Propagate occupancy status from GTFS-RT to both TripStatus and VehicleStatus.
 			// There is no source for occupancyCapacity or occupancyCount anywhere in maglev — not in the SQLite DB,
 			// not in GTFS-RT. Those fields will remain omitted.
-			if vehicle.OccupancyStatus != nil {
-				occupancy := vehicle.OccupancyStatus.String()
-				tripStatus.OccupancyStatus = occupancy
-				vehicleStatus.OccupancyStatus = occupancy
-			}
+			occupancy := internalgtfs.VehicleOccupancyStatus(&vehicle)
+			tripStatus.OccupancyStatus = occupancy
+			vehicleStatus.OccupancyStatus = occupancy
 
 			vehicleStatus.TripStatus = tripStatus
 
@@ -260,14 +264,5 @@ func (api *RestAPI) vehiclesForAgencyHandler(w http.ResponseWriter, r *http.Requ
 // addRouteReference inserts a route reference keyed by its combined agencyID_routeID.
 func addRouteReference(routeRefs map[string]models.Route, route gtfsdb.Route) {
 	combinedRouteID := utils.FormCombinedID(route.AgencyID, route.ID)
-	routeRefs[combinedRouteID] = models.NewRoute(
-		combinedRouteID, route.AgencyID,
-		nulls.StringOrEmpty(route.ShortName),
-		nulls.StringOrEmpty(route.LongName),
-		nulls.StringOrEmpty(route.Desc),
-		models.RouteType(route.Type),
-		nulls.StringOrEmpty(route.Url),
-		nulls.StringOrEmpty(route.Color),
-		nulls.StringOrEmpty(route.TextColor),
-	)
+	routeRefs[combinedRouteID] = utils.RouteFromDatabase(route.AgencyID, route)
 }
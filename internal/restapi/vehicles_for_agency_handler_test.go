@@ -797,6 +797,9 @@ func TestVehiclesForAgencyHandlerWithRealTimeData(t *testing.T) {
 // createTestApiWithRealTimeData creates a test API with real-time GTFS-RT data served
 // from local .pb files.
 func createTestApiWithRealTimeData(t testing.TB, c clock.Clock) (*RestAPI, func()) {
+	// The cleanup must stop the realtime feed polling and everything the
+	// RestAPI started.
+	verifyNoGoroutineLeaks(t)
 	ctx := context.Background()
 
 	mux := http.NewServeMux()
@@ -1024,3 +1027,69 @@ func TestAddRouteReference(t *testing.T) {
 	assert.Equal(t, "FF0000", ref.Color)
 	assert.Equal(t, "", ref.TextColor, "unset nullable fields map to empty string")
 }
+
+func findVehicleStatus(list []models.VehicleStatus, vehicleID string) *models.VehicleStatus {
+	for i := range list {
+		if list[i].VehicleID == vehicleID {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func TestVehiclesForAgencyHandler_BearingPropagated(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+
+	trip := mustGetTrip(t, api)
+	lat, lon, bearing := float32(38.56), float32(-121.76), float32(0)
+	api.GtfsManager.MockAddVehicleWithOptions("v_bearing", trip.ID, trip.RouteID, gtfs.MockVehicleOptions{
+		Position: &gogtfs.Position{Latitude: &lat, Longitude: &lon, Bearing: &bearing},
+	})
+
+	_, model := callAPIHandler[VehiclesForAgencyResponse](t, api, vehiclesForAgencyURL(testdata.Raba.ID))
+
+	vehicle := findVehicleStatus(model.Data.List, "v_bearing")
+	require.NotNil(t, vehicle)
+	require.NotNil(t, vehicle.Bearing, "bearing must be exposed when the feed reports it")
+	assert.Equal(t, 0.0, *vehicle.Bearing)
+	require.NotNil(t, vehicle.TripStatus)
+	// A bearing of due north is an orientation of 90 degrees counterclockwise
+	// from east.
+	assert.Equal(t, 90.0, vehicle.TripStatus.Orientation)
+	assert.Equal(t, 90.0, vehicle.TripStatus.LastKnownOrientation)
+}
+
+func TestVehiclesForAgencyHandler_OrientationInferredFromShape(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+	t.Cleanup(api.GtfsManager.MockResetRealTimeData)
+	ctx := context.Background()
+
+	var tripID, routeID string
+	err := api.GtfsManager.GtfsDB.DB.QueryRowContext(ctx,
+		`SELECT id, route_id FROM trips WHERE shape_id IS NOT NULL AND shape_id != '' LIMIT 1`,
+	).Scan(&tripID, &routeID)
+	require.NoError(t, err)
+	shapeRows, err := api.GtfsManager.GtfsDB.Queries.GetShapePointsByTripID(ctx, tripID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(shapeRows), 2)
+
+	lat := float32((shapeRows[0].Lat + shapeRows[1].Lat) / 2)
+	lon := float32((shapeRows[0].Lon + shapeRows[1].Lon) / 2)
+	api.GtfsManager.MockAddVehicleWithOptions("v_no_bearing", tripID, routeID, gtfs.MockVehicleOptions{
+		Position: &gogtfs.Position{Latitude: &lat, Longitude: &lon},
+	})
+
+	_, model := callAPIHandler[VehiclesForAgencyResponse](t, api, vehiclesForAgencyURL(testdata.Raba.ID))
+
+	vehicle := findVehicleStatus(model.Data.List, "v_no_bearing")
+	require.NotNil(t, vehicle)
+	assert.Nil(t, vehicle.Bearing, "bearing must be omitted when the feed does not report it")
+	require.NotNil(t, vehicle.TripStatus)
+	expected := inferOrientationFromShape(float64(lat), float64(lon), shapeRowsToPoints(shapeRows))
+	require.GreaterOrEqual(t, expected, 0.0)
+	assert.InDelta(t, expected, vehicle.TripStatus.Orientation, 1e-9)
+	assert.InDelta(t, expected, vehicle.TripStatus.LastKnownOrientation, 1e-9)
+}
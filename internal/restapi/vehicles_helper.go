@@ -136,12 +136,9 @@ func (api *RestAPI) BuildVehicleStatus(
 	}
 
 	if vehicle.Position != nil && vehicle.Position.Bearing != nil {
-		obaOrientation := (90 - *vehicle.Position.Bearing)
-		if obaOrientation < 0 {
-			obaOrientation += 360
-		}
-		status.Orientation = float64(obaOrientation)
-		status.LastKnownOrientation = float64(obaOrientation)
+		orientation := bearingToOrientation(*vehicle.Position.Bearing)
+		status.Orientation = orientation
+		status.LastKnownOrientation = orientation
 	}
 
 	status.Status, status.Phase = GetVehicleStatusAndPhase(vehicle)
@@ -153,6 +150,59 @@ func (api *RestAPI) BuildVehicleStatus(
 	}
 }
 
+// bearingToOrientation converts a GTFS-RT bearing (0° = North, clockwise) to an
+// OBA orientation (0° = East, counter-clockwise).
+func bearingToOrientation(bearing float32) float64 {
+	orientation := 90 - float64(bearing)
+	if orientation < 0 {
+		orientation += 360
+	}
+	return orientation
+}
+
+// vehicleBearing returns the raw GTFS-RT bearing reported by the vehicle, or nil.
+func vehicleBearing(vehicle *gtfs.Vehicle) *float64 {
+	if vehicle == nil || vehicle.Position == nil || vehicle.Position.Bearing == nil {
+		return nil
+	}
+	bearing := float64(*vehicle.Position.Bearing)
+	return &bearing
+}
+
+// vehicleOrientation returns the vehicle's OBA orientation, taken from its
+// reported bearing or, when the feed omits bearing, inferred from the heading of
+// the active trip's shape at the vehicle's position. shapeCache holds shape
+// points already fetched for this request, keyed by trip ID.
+func (api *RestAPI) vehicleOrientation(ctx context.Context, vehicle *gtfs.Vehicle, activeTripID string, shapeCache map[string][]gtfs.ShapePoint) (float64, bool) {
+	if bearing := vehicleBearing(vehicle); bearing != nil {
+		return bearingToOrientation(float32(*bearing)), true
+	}
+	if vehicle == nil || vehicle.Position == nil || vehicle.Position.Latitude == nil || vehicle.Position.Longitude == nil {
+		return 0, false
+	}
+
+	shapePoints := api.cachedShapePoints(ctx, activeTripID, shapeCache)
+	inferred := inferOrientationFromShape(float64(*vehicle.Position.Latitude), float64(*vehicle.Position.Longitude), shapePoints)
+	if inferred < 0 {
+		return 0, false
+	}
+	return inferred, true
+}
+
+// cachedShapePoints returns the shape points of tripID, fetching them once
+// per request into shapeCache. A trip whose shape cannot be read has none.
+func (api *RestAPI) cachedShapePoints(ctx context.Context, tripID string, shapeCache map[string][]gtfs.ShapePoint) []gtfs.ShapePoint {
+	shapePoints, ok := shapeCache[tripID]
+	if !ok {
+		shapeRows, err := api.GtfsManager.GtfsDB.Queries.GetShapePointsByTripID(ctx, tripID)
+		if err == nil {
+			shapePoints = shapeRowsToPoints(shapeRows)
+		}
+		shapeCache[tripID] = shapePoints
+	}
+	return shapePoints
+}
+
 func GetVehicleActiveTripID(vehicle *gtfs.Vehicle) string {
 	if vehicle == nil || vehicle.Trip == nil || vehicle.Trip.ID.ID == "" {
 		return ""
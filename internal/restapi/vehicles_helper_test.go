@@ -457,3 +457,13 @@ func TestWallClockSinceMidnightNs(t *testing.T) {
 	normal := time.Date(2024, 6, 1, 10, 15, 30, 0, loc)
 	assert.Equal(t, int64(10*time.Hour+15*time.Minute+30*time.Second), wallClockSinceMidnightNs(normal))
 }
+
+func TestVehicleBearing(t *testing.T) {
+	assert.Nil(t, vehicleBearing(nil))
+	assert.Nil(t, vehicleBearing(&gtfs.Vehicle{Position: &gtfs.Position{}}))
+
+	bearing := float32(270)
+	got := vehicleBearing(&gtfs.Vehicle{Position: &gtfs.Position{Bearing: &bearing}})
+	require.NotNil(t, got)
+	assert.Equal(t, 270.0, *got)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"maglev.onebusaway.org/internal/buildinfo"
 	"maglev.onebusaway.org/internal/models"
 )
 
@@ -26,3 +27,17 @@ func (api *RestAPI) VersionValidationMiddleware(next http.Handler) http.Handler
 		next.ServeHTTP(w, r)
 	})
 }
+
+// versionHeader identifies the server build on every response so support can
+// tell which release answered a request.
+const versionHeader = "X-Maglev-Version"
+
+// VersionHeaderMiddleware sets the X-Maglev-Version header, see
+// buildinfo.HeaderValue, on every response.
+func VersionHeaderMiddleware(next http.Handler) http.Handler {
+	value := buildinfo.HeaderValue()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(versionHeader, value)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"maglev.onebusaway.org/internal/app"
+	"maglev.onebusaway.org/internal/buildinfo"
 	"maglev.onebusaway.org/internal/clock"
 	"maglev.onebusaway.org/internal/models"
 )
@@ -90,3 +91,19 @@ func TestVersionValidationMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionHeaderMiddleware(t *testing.T) {
+	originalVersion, originalCommit := buildinfo.Version, buildinfo.CommitHash
+	defer func() { buildinfo.Version, buildinfo.CommitHash = originalVersion, originalCommit }()
+	buildinfo.Version = "v1.4.0"
+	buildinfo.CommitHash = "1a2b3c4d5e6f"
+
+	handler := VersionHeaderMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/anything", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "v1.4.0+1a2b3c4", rr.Header().Get("X-Maglev-Version"))
+}
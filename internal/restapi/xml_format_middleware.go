@@ -0,0 +1,169 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// xmlContentType is what the Java OneBusAway server sends with .xml responses.
+const xmlContentType = "text/xml; charset=utf-8"
+
+// XMLFormatMiddleware serves /api/where endpoints with an .xml extension, as
+// the Java OneBusAway server does. The request is handled as its .json
+// counterpart and the JSON response is then rewritten as XML in the OBA
+// envelope: a <response> element holding <code>, <currentTime>, <text>,
+// <version> and <data>. Responses that are not JSON, such as JSONP, are sent
+// as they are.
+func XMLFormatMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base, isXML := strings.CutSuffix(r.URL.Path, ".xml")
+		if !isXML || !strings.HasPrefix(r.URL.Path, "/api/where/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		jsonReq := r.Clone(r.Context())
+		jsonReq.URL.Path = base + ".json"
+		if rawBase, ok := strings.CutSuffix(r.URL.RawPath, ".xml"); ok {
+			jsonReq.URL.RawPath = rawBase + ".json"
+		}
+
+		rec := &coalescingRecorder{header: make(http.Header)}
+		next.ServeHTTP(rec, jsonReq)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		body := rec.body.Bytes()
+		if strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") && len(body) > 0 {
+			var buf bytes.Buffer
+			if err := jsonToXML(&buf, body); err == nil {
+				rec.header.Set("Content-Type", xmlContentType)
+				body = buf.Bytes()
+			}
+		}
+		rec.header.Del("Content-Length")
+
+		for name, values := range rec.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// jsonToXML writes the JSON document in data as an XML document with a
+// <response> root. Object members become child elements in their JSON
+// order, null members are left out, and array items are named after their
+// array (see xmlItemName).
+func jsonToXML(w io.Writer, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := encodeJSONValue(enc, dec, "response", false); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// xmlNamePattern matches the JSON member names usable as element names as
+// is. Other members are written as <entry key="...">.
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// encodeJSONValue encodes the next JSON value as an element called name.
+// Array items of scalar type are named after their type instead, as the Java
+// server's XStream serializer does.
+func encodeJSONValue(enc *xml.Encoder, dec *json.Decoder, name string, arrayItem bool) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if arrayItem {
+		switch v := tok.(type) {
+		case string:
+			name = "string"
+		case bool:
+			name = "boolean"
+		case json.Number:
+			name = "long"
+			if strings.ContainsAny(v.String(), ".eE") {
+				name = "double"
+			}
+		}
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !xmlNamePattern.MatchString(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+
+	switch v := tok.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for dec.More() {
+			childName := xmlItemName(name)
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				childName = key.(string)
+			}
+			if err := encodeJSONValue(enc, dec, childName, v == '['); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	case string:
+		return enc.EncodeElement(v, start)
+	case json.Number:
+		return enc.EncodeElement(v.String(), start)
+	case bool:
+		return enc.EncodeElement(strconv.FormatBool(v), start)
+	default:
+		return fmt.Errorf("unexpected JSON token %v", tok)
+	}
+}
+
+// xmlItemNames holds the item names of arrays whose name does not singularize
+// by rule.
+var xmlItemNames = map[string]string{
+	"list":                  "entry",
+	"arrivalsAndDepartures": "arrivalAndDeparture",
+}
+
+// xmlItemName names the items of an array: "stops" holds <stop> elements
+// and "agencies" <agency> elements.
+func xmlItemName(arrayName string) string {
+	if name, ok := xmlItemNames[arrayName]; ok {
+		return name
+	}
+	if stem, ok := strings.CutSuffix(arrayName, "ies"); ok {
+		return stem + "y"
+	}
+	if stem, ok := strings.CutSuffix(arrayName, "s"); ok && stem != "" {
+		return stem
+	}
+	return "item"
+}
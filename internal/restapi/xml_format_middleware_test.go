@@ -0,0 +1,105 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/restapi/testdata"
+)
+
+func TestJSONToXML(t *testing.T) {
+	input := `{"code":200,"data":{"entry":{"id":"1_2","lat":47.5,"routeIds":["1_10","1_20"],"parent":null,"flag":true},` +
+		`"list":[{"id":"a"}],"references":{"agencies":[{"id":"1"}],"situations":[]},"1_2":"odd"},"text":"OK & <ok>"}`
+
+	var buf bytes.Buffer
+	require.NoError(t, jsonToXML(&buf, []byte(input)))
+
+	want := xml.Header + `<response><code>200</code><data>` +
+		`<entry><id>1_2</id><lat>47.5</lat><routeIds><string>1_10</string><string>1_20</string></routeIds><flag>true</flag></entry>` +
+		`<list><entry><id>a</id></entry></list>` +
+		`<references><agencies><agency><id>1</id></agency></agencies><situations></situations></references>` +
+		`<entry key="1_2">odd</entry>` +
+		`</data><text>OK &amp; &lt;ok&gt;</text></response>`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestXMLItemName(t *testing.T) {
+	tests := map[string]string{
+		"stops":                 "stop",
+		"agencies":              "agency",
+		"list":                  "entry",
+		"arrivalsAndDepartures": "arrivalAndDeparture",
+		"s":                     "item",
+		"data":                  "item",
+	}
+	for arrayName, want := range tests {
+		assert.Equal(t, want, xmlItemName(arrayName), arrayName)
+	}
+}
+
+func TestXMLFormatMiddleware_PassesThroughOtherResponses(t *testing.T) {
+	var gotPath string
+	handler := XMLFormatMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		_, _ = w.Write([]byte("/**/cb({});"))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/where/stop/1_2.xml?callback=cb", nil))
+	assert.Equal(t, "/api/where/stop/1_2.json", gotPath)
+	assert.Equal(t, "/**/cb({});", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/static/feed.xml", nil))
+	assert.Equal(t, "/static/feed.xml", gotPath, "only /api/where requests are rewritten")
+}
+
+func TestXMLEndpointsThroughMux(t *testing.T) {
+	api := createTestApi(t)
+	defer api.Shutdown()
+
+	server := httptest.NewServer(api.SetupAPIRoutes())
+	defer server.Close()
+
+	fetch := func(t *testing.T, endpoint string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + endpoint)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	type envelope struct {
+		Code int    `xml:"code"`
+		Text string `xml:"text"`
+		ID   string `xml:"data>entry>id"`
+	}
+
+	t.Run("entry", func(t *testing.T) {
+		resp, body := fetch(t, "/api/where/agency/"+testdata.Raba.ID+".xml?key=TEST")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, xmlContentType, resp.Header.Get("Content-Type"))
+
+		var got envelope
+		require.NoError(t, xml.Unmarshal([]byte(body), &got), body)
+		assert.Equal(t, http.StatusOK, got.Code)
+		assert.Equal(t, testdata.Raba.ID, got.ID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		resp, body := fetch(t, "/api/where/agency/"+testdata.Raba.ID+".xml?key=invalid")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		var got envelope
+		require.NoError(t, xml.Unmarshal([]byte(body), &got), body)
+		assert.Equal(t, http.StatusUnauthorized, got.Code)
+		assert.Equal(t, "permission denied", got.Text)
+	})
+}
@@ -0,0 +1,293 @@
+// Package scheduler runs periodic maintenance jobs in-process, such as
+// reloading the static feed or pruning old rows, and reports on each job for
+// the admin API.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"maglev.onebusaway.org/internal/clock"
+	"maglev.onebusaway.org/internal/logging"
+)
+
+// ErrUnknownJob is returned by Trigger for a name no job was added with.
+var ErrUnknownJob = errors.New("unknown job")
+
+// ErrJobRunning is returned by Trigger when the job is already running.
+var ErrJobRunning = errors.New("job is already running")
+
+// defaultJobTimeout bounds a run of a job without its own Timeout.
+const defaultJobTimeout = 10 * time.Minute
+
+// Job is a task the scheduler runs every Interval.
+type Job struct {
+	Name     string
+	Enabled  bool          // Disabled jobs are reported but never run on their own
+	Interval time.Duration // Time between the end of a run and the start of the next
+	Jitter   time.Duration // Up to this much random delay is added to each wait
+	Timeout  time.Duration // Bounds a single run; defaultJobTimeout when zero
+	// NextDelay, when set, returns the wait before the next run in place of
+	// Interval, e.g. to retry sooner after a failure.
+	NextDelay func() time.Duration
+	Run       func(ctx context.Context) error
+}
+
+// JobStatus reports the state of one job.
+type JobStatus struct {
+	Name            string `json:"name"`
+	Enabled         bool   `json:"enabled"`
+	Running         bool   `json:"running"`
+	IntervalSeconds int64  `json:"intervalSeconds"`
+	JitterSeconds   int64  `json:"jitterSeconds"`
+	Runs            int64  `json:"runs"`
+	Failures        int64  `json:"failures"`
+	// SkippedRuns counts runs that were due while the job was still running.
+	SkippedRuns int64 `json:"skippedRuns"`
+	// Times are in milliseconds since the epoch; zero when it never happened.
+	LastStartTime   int64  `json:"lastStartTime"`
+	LastEndTime     int64  `json:"lastEndTime"`
+	LastSuccessTime int64  `json:"lastSuccessTime"`
+	LastDurationMs  int64  `json:"lastDurationMs"`
+	LastError       string `json:"lastError,omitempty"`
+	NextRunTime     int64  `json:"nextRunTime"`
+}
+
+// job is a Job with its run state. mu guards the fields below it.
+type job struct {
+	Job
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// Scheduler runs each enabled job in its own goroutine. A job never overlaps
+// itself: a run that comes due while the previous one is still going is
+// skipped.
+type Scheduler struct {
+	clock  clock.Clock
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	jobs    []*job
+	byName  map[string]*job
+	started bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New returns a scheduler that reports times from clk. A nil logger logs to
+// slog.Default().
+func New(clk clock.Clock, logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		clock:  clk,
+		logger: logger.With(slog.String("component", "scheduler")),
+		byName: make(map[string]*job),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job. It must be called before Start; job names must be
+// unique.
+func (s *Scheduler) Add(j Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("job %q added after the scheduler started", j.Name)
+	}
+	if _, ok := s.byName[j.Name]; ok {
+		return fmt.Errorf("job %q added twice", j.Name)
+	}
+	if j.Enabled && j.Interval <= 0 && j.NextDelay == nil {
+		return fmt.Errorf("job %q needs a positive interval", j.Name)
+	}
+	if j.Timeout <= 0 {
+		j.Timeout = defaultJobTimeout
+	}
+	added := &job{Job: j, status: JobStatus{
+		Name:            j.Name,
+		Enabled:         j.Enabled,
+		IntervalSeconds: int64(j.Interval.Seconds()),
+		JitterSeconds:   int64(j.Jitter.Seconds()),
+	}}
+	s.jobs = append(s.jobs, added)
+	s.byName[j.Name] = added
+	return nil
+}
+
+// Start begins running the enabled jobs. Each job first runs one interval
+// after Start, since the work it does has usually just happened at startup.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, j := range s.jobs {
+		if !j.Enabled {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Shutdown stops the jobs and waits for runs in progress to finish. Their
+// contexts are canceled.
+func (s *Scheduler) Shutdown() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Status returns the state of every job in the order they were added.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		statuses = append(statuses, j.status)
+		j.mu.Unlock()
+	}
+	return statuses
+}
+
+// Trigger starts a run of a job in the background, whether or not it is
+// enabled. It returns ErrJobRunning instead of starting a second run.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	j, ok := s.byName[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownJob
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	start, ok := s.begin(j)
+	if !ok {
+		return ErrJobRunning
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.finish(j, start, s.runGuarded(s.ctx, j))
+	}()
+	return nil
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+	timer := time.NewTimer(s.scheduleNext(j))
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if !s.run(s.ctx, j) {
+			j.mu.Lock()
+			j.status.SkippedRuns++
+			j.mu.Unlock()
+			s.logger.Warn("skipped scheduled job run; previous run still in progress",
+				slog.String("job", j.Name))
+		}
+		timer.Reset(s.scheduleNext(j))
+	}
+}
+
+// scheduleNext picks the wait before the job's next run and records when
+// that will be.
+func (s *Scheduler) scheduleNext(j *job) time.Duration {
+	delay := j.Interval
+	if j.NextDelay != nil {
+		delay = j.NextDelay()
+	}
+	if j.Jitter > 0 {
+		delay += rand.N(j.Jitter)
+	}
+	j.mu.Lock()
+	j.status.NextRunTime = s.clock.Now().Add(delay).UnixMilli()
+	j.mu.Unlock()
+	return delay
+}
+
+// run runs the job unless it is already running, reporting whether it ran.
+func (s *Scheduler) run(ctx context.Context, j *job) bool {
+	start, ok := s.begin(j)
+	if !ok {
+		return false
+	}
+	s.finish(j, start, s.runGuarded(ctx, j))
+	return true
+}
+
+// begin marks the job as running unless it already is, returning the start
+// time.
+func (s *Scheduler) begin(j *job) (time.Time, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.Running {
+		return time.Time{}, false
+	}
+	start := s.clock.Now()
+	j.status.Running = true
+	j.status.LastStartTime = start.UnixMilli()
+	return start, true
+}
+
+// finish records the outcome of a run that began at start.
+func (s *Scheduler) finish(j *job, start time.Time, err error) {
+	end := s.clock.Now()
+	j.mu.Lock()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastEndTime = end.UnixMilli()
+	j.status.LastDurationMs = end.Sub(start).Milliseconds()
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	} else {
+		j.status.LastSuccessTime = end.UnixMilli()
+		j.status.LastError = ""
+	}
+	j.mu.Unlock()
+
+	if err != nil {
+		logging.LogError(s.logger, "scheduled job failed", err, slog.String("job", j.Name))
+	} else {
+		s.logger.Debug("scheduled job finished", slog.String("job", j.Name),
+			slog.Duration("duration", end.Sub(start)))
+	}
+}
+
+// runGuarded runs the job with its timeout, turning a panic into an error so
+// one broken job cannot take down the server. The job's context carries a
+// logger for the job.
+func (s *Scheduler) runGuarded(ctx context.Context, j *job) (err error) {
+	ctx = logging.WithLogger(ctx, s.logger.With(slog.String("job", j.Name)))
+	ctx, cancel := context.WithTimeout(ctx, j.Timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.Run(ctx)
+}
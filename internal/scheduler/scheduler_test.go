@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/internal/clock"
+)
+
+func newTestScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	s := New(clock.RealClock{}, slog.New(slog.DiscardHandler))
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func statusOf(t *testing.T, s *Scheduler, name string) JobStatus {
+	t.Helper()
+	for _, status := range s.Status() {
+		if status.Name == name {
+			return status
+		}
+	}
+	t.Fatalf("no job %q", name)
+	return JobStatus{}
+}
+
+func TestSchedulerRunsEnabledJobs(t *testing.T) {
+	s := newTestScheduler(t)
+	var enabledRuns, disabledRuns atomic.Int32
+	require.NoError(t, s.Add(Job{
+		Name:     "enabled",
+		Enabled:  true,
+		Interval: 5 * time.Millisecond,
+		Run: func(context.Context) error {
+			enabledRuns.Add(1)
+			return nil
+		},
+	}))
+	require.NoError(t, s.Add(Job{
+		Name:     "disabled",
+		Interval: 5 * time.Millisecond,
+		Run: func(context.Context) error {
+			disabledRuns.Add(1)
+			return nil
+		},
+	}))
+	s.Start()
+
+	require.Eventually(t, func() bool { return enabledRuns.Load() >= 3 }, time.Second, time.Millisecond)
+	s.Shutdown()
+	assert.Zero(t, disabledRuns.Load())
+
+	status := statusOf(t, s, "enabled")
+	assert.True(t, status.Enabled)
+	assert.False(t, status.Running)
+	assert.GreaterOrEqual(t, status.Runs, int64(3))
+	assert.Zero(t, status.Failures)
+	assert.NotZero(t, status.LastSuccessTime)
+	assert.NotZero(t, status.NextRunTime)
+
+	assert.False(t, statusOf(t, s, "disabled").Enabled)
+	assert.Zero(t, statusOf(t, s, "disabled").Runs)
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	s := newTestScheduler(t)
+	fail := atomic.Bool{}
+	fail.Store(true)
+	require.NoError(t, s.Add(Job{
+		Name:     "flaky",
+		Enabled:  true,
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			if fail.Load() {
+				return errors.New("upstream unavailable")
+			}
+			return nil
+		},
+	}))
+	require.NoError(t, s.Add(Job{
+		Name:     "broken",
+		Interval: time.Hour,
+		Run:      func(context.Context) error { panic("nil map") },
+	}))
+
+	require.True(t, s.run(context.Background(), s.byName["flaky"]))
+	status := statusOf(t, s, "flaky")
+	assert.Equal(t, int64(1), status.Failures)
+	assert.Equal(t, "upstream unavailable", status.LastError)
+	assert.Zero(t, status.LastSuccessTime)
+
+	fail.Store(false)
+	require.True(t, s.run(context.Background(), s.byName["flaky"]))
+	status = statusOf(t, s, "flaky")
+	assert.Equal(t, int64(2), status.Runs)
+	assert.Equal(t, int64(1), status.Failures)
+	assert.Empty(t, status.LastError, "a success clears the last error")
+
+	require.True(t, s.run(context.Background(), s.byName["broken"]))
+	assert.Equal(t, "panic: nil map", statusOf(t, s, "broken").LastError)
+}
+
+func TestSchedulerPreventsOverlap(t *testing.T) {
+	s := newTestScheduler(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var runs atomic.Int32
+	require.NoError(t, s.Add(Job{
+		Name:     "slow",
+		Enabled:  true,
+		Interval: 2 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return nil
+		},
+	}))
+
+	require.NoError(t, s.Trigger("slow"))
+	<-started
+	assert.True(t, statusOf(t, s, "slow").Running)
+	assert.ErrorIs(t, s.Trigger("slow"), ErrJobRunning)
+
+	// The schedule comes due while the triggered run is still going.
+	s.Start()
+	require.Eventually(t, func() bool { return statusOf(t, s, "slow").SkippedRuns > 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+
+	close(release)
+	require.Eventually(t, func() bool { return statusOf(t, s, "slow").Runs > 1 }, time.Second, time.Millisecond)
+}
+
+func TestSchedulerTrigger(t *testing.T) {
+	s := newTestScheduler(t)
+	done := make(chan struct{})
+	require.NoError(t, s.Add(Job{
+		Name:     "manual",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			close(done)
+			return nil
+		},
+	}))
+
+	assert.ErrorIs(t, s.Trigger("missing"), ErrUnknownJob)
+	require.NoError(t, s.Trigger("manual"), "disabled jobs can still be run by hand")
+	<-done
+	require.Eventually(t, func() bool { return statusOf(t, s, "manual").Runs == 1 }, time.Second, time.Millisecond)
+
+	s.Shutdown()
+	assert.ErrorIs(t, s.Trigger("manual"), context.Canceled)
+}
+
+func TestSchedulerCancelsRunsOnShutdown(t *testing.T) {
+	s := newTestScheduler(t)
+	started := make(chan struct{})
+	require.NoError(t, s.Add(Job{
+		Name:     "long",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+	require.NoError(t, s.Trigger("long"))
+	<-started
+
+	s.Shutdown()
+	status := statusOf(t, s, "long")
+	assert.False(t, status.Running)
+	assert.Equal(t, context.Canceled.Error(), status.LastError)
+}
+
+func TestSchedulerNextDelayAndJitter(t *testing.T) {
+	now := time.Date(2025, 6, 13, 11, 0, 0, 0, time.UTC)
+	s := New(clock.NewMockClock(now), slog.New(slog.DiscardHandler))
+	require.NoError(t, s.Add(Job{Name: "jittered", Interval: time.Hour, Jitter: time.Minute, Run: func(context.Context) error { return nil }}))
+	require.NoError(t, s.Add(Job{Name: "retrying", Interval: time.Hour, NextDelay: func() time.Duration { return time.Minute }, Run: func(context.Context) error { return nil }}))
+
+	for range 20 {
+		delay := s.scheduleNext(s.byName["jittered"])
+		assert.GreaterOrEqual(t, delay, time.Hour)
+		assert.Less(t, delay, time.Hour+time.Minute)
+		assert.Equal(t, now.Add(delay).UnixMilli(), statusOf(t, s, "jittered").NextRunTime)
+	}
+	assert.Equal(t, time.Minute, s.scheduleNext(s.byName["retrying"]))
+}
+
+func TestSchedulerAddValidation(t *testing.T) {
+	s := newTestScheduler(t)
+	run := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Add(Job{Name: "a", Enabled: true, Interval: time.Minute, Run: run}))
+	assert.ErrorContains(t, s.Add(Job{Name: "a", Interval: time.Minute, Run: run}), "added twice")
+	assert.ErrorContains(t, s.Add(Job{Name: "b", Enabled: true, Run: run}), "needs a positive interval")
+	require.NoError(t, s.Add(Job{Name: "c", Run: run}), "a disabled job needs no interval")
+
+	s.Start()
+	assert.ErrorContains(t, s.Add(Job{Name: "d", Interval: time.Minute, Run: run}), "after the scheduler started")
+}
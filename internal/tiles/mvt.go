@@ -0,0 +1,285 @@
+package tiles
+
+import (
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ContentType is the media type of an encoded vector tile.
+const ContentType = "application/vnd.mapbox-vector-tile"
+
+const (
+	// DefaultExtent is the number of units across a tile in its local
+	// coordinate space.
+	DefaultExtent = 4096
+	// DefaultBuffer is how far, in tile units, geometry is kept past the tile
+	// edge so that lines and symbols do not visibly break at tile seams.
+	DefaultBuffer = 64
+)
+
+// Point is a position in tile-local coordinates.
+type Point struct {
+	X, Y int64
+}
+
+// Geometry types from the vector tile specification.
+const (
+	geomTypePoint      = 1
+	geomTypeLineString = 2
+)
+
+// Drawing commands from the vector tile specification.
+const (
+	cmdMoveTo = 1
+	cmdLineTo = 2
+)
+
+// Field numbers from vector_tile.proto (version 2.1).
+const (
+	tileLayers = 3
+
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+	layerVersion  = 15
+
+	featureID       = 1
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueDouble = 3
+	valueSint   = 6
+	valueBool   = 7
+)
+
+// Layer accumulates features for one named layer of a tile.
+type Layer struct {
+	name     string
+	extent   uint32
+	features [][]byte
+	keys     []string
+	keyIndex map[string]uint32
+	values   [][]byte
+	valIndex map[any]uint32
+}
+
+// NewLayer returns an empty layer using DefaultExtent.
+func NewLayer(name string) *Layer {
+	return &Layer{
+		name:     name,
+		extent:   DefaultExtent,
+		keyIndex: make(map[string]uint32),
+		valIndex: make(map[any]uint32),
+	}
+}
+
+// Len returns the number of features added to the layer.
+func (l *Layer) Len() int {
+	return len(l.features)
+}
+
+// AddPoint adds a point feature. Property values may be string, int, int64,
+// float64 or bool; other types are skipped.
+func (l *Layer) AddPoint(id uint64, p Point, props map[string]any) {
+	geometry := []uint32{command(cmdMoveTo, 1), zigzag(p.X), zigzag(p.Y)}
+	l.addFeature(id, geomTypePoint, geometry, props)
+}
+
+// AddLineStrings adds a single (multi-)linestring feature. Lines with fewer
+// than two distinct points are dropped; nothing is added if none remain.
+func (l *Layer) AddLineStrings(id uint64, lines [][]Point, props map[string]any) {
+	var geometry []uint32
+	var cursor Point
+	for _, line := range lines {
+		line = dedupe(line)
+		if len(line) < 2 {
+			continue
+		}
+		geometry = append(geometry, command(cmdMoveTo, 1), zigzag(line[0].X-cursor.X), zigzag(line[0].Y-cursor.Y))
+		cursor = line[0]
+		geometry = append(geometry, command(cmdLineTo, uint32(len(line)-1)))
+		for _, p := range line[1:] {
+			geometry = append(geometry, zigzag(p.X-cursor.X), zigzag(p.Y-cursor.Y))
+			cursor = p
+		}
+	}
+	if len(geometry) == 0 {
+		return
+	}
+	l.addFeature(id, geomTypeLineString, geometry, props)
+}
+
+func (l *Layer) addFeature(id uint64, geomType uint64, geometry []uint32, props map[string]any) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var tags []byte
+	for _, k := range keys {
+		valueIdx, ok := l.valueIndex(props[k])
+		if !ok {
+			continue
+		}
+		tags = protowire.AppendVarint(tags, uint64(l.keyIndexOf(k)))
+		tags = protowire.AppendVarint(tags, uint64(valueIdx))
+	}
+
+	var packed []byte
+	for _, g := range geometry {
+		packed = protowire.AppendVarint(packed, uint64(g))
+	}
+
+	var b []byte
+	if id != 0 {
+		b = protowire.AppendTag(b, featureID, protowire.VarintType)
+		b = protowire.AppendVarint(b, id)
+	}
+	if len(tags) > 0 {
+		b = protowire.AppendTag(b, featureTags, protowire.BytesType)
+		b = protowire.AppendBytes(b, tags)
+	}
+	b = protowire.AppendTag(b, featureType, protowire.VarintType)
+	b = protowire.AppendVarint(b, geomType)
+	b = protowire.AppendTag(b, featureGeometry, protowire.BytesType)
+	b = protowire.AppendBytes(b, packed)
+	l.features = append(l.features, b)
+}
+
+func (l *Layer) keyIndexOf(key string) uint32 {
+	if idx, ok := l.keyIndex[key]; ok {
+		return idx
+	}
+	idx := uint32(len(l.keys))
+	l.keys = append(l.keys, key)
+	l.keyIndex[key] = idx
+	return idx
+}
+
+func (l *Layer) valueIndex(v any) (uint32, bool) {
+	switch val := v.(type) {
+	case int:
+		v = int64(val)
+	case string, int64, float64, bool:
+	default:
+		return 0, false
+	}
+	if idx, ok := l.valIndex[v]; ok {
+		return idx, true
+	}
+
+	var b []byte
+	switch val := v.(type) {
+	case string:
+		b = protowire.AppendTag(b, valueString, protowire.BytesType)
+		b = protowire.AppendString(b, val)
+	case int64:
+		b = protowire.AppendTag(b, valueSint, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(val))
+	case float64:
+		b = protowire.AppendTag(b, valueDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(val))
+	case bool:
+		b = protowire.AppendTag(b, valueBool, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(val))
+	}
+
+	idx := uint32(len(l.values))
+	l.values = append(l.values, b)
+	l.valIndex[v] = idx
+	return idx, true
+}
+
+func (l *Layer) encode() []byte {
+	var b []byte
+	b = protowire.AppendTag(b, layerVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, 2)
+	b = protowire.AppendTag(b, layerName, protowire.BytesType)
+	b = protowire.AppendString(b, l.name)
+	for _, f := range l.features {
+		b = protowire.AppendTag(b, layerFeatures, protowire.BytesType)
+		b = protowire.AppendBytes(b, f)
+	}
+	for _, k := range l.keys {
+		b = protowire.AppendTag(b, layerKeys, protowire.BytesType)
+		b = protowire.AppendString(b, k)
+	}
+	for _, v := range l.values {
+		b = protowire.AppendTag(b, layerValues, protowire.BytesType)
+		b = protowire.AppendBytes(b, v)
+	}
+	b = protowire.AppendTag(b, layerExtent, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(l.extent))
+	return b
+}
+
+// Encode serializes the layers into a vector tile. Empty layers are omitted.
+func Encode(layers ...*Layer) []byte {
+	var b []byte
+	for _, l := range layers {
+		if l == nil || l.Len() == 0 {
+			continue
+		}
+		b = protowire.AppendTag(b, tileLayers, protowire.BytesType)
+		b = protowire.AppendBytes(b, l.encode())
+	}
+	return b
+}
+
+// ClipLine splits a line into the runs of segments that touch the tile plus
+// buffer, so that long shapes only carry the part near the tile. Segments are
+// kept when their bounding box overlaps the buffered tile, which keeps lines
+// that cross the tile without a vertex inside it.
+func ClipLine(line []Point, extent, buffer int64) [][]Point {
+	minC, maxC := -buffer, extent+buffer
+	var parts [][]Point
+	var current []Point
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		if min(a.X, b.X) > maxC || max(a.X, b.X) < minC || min(a.Y, b.Y) > maxC || max(a.Y, b.Y) < minC {
+			if len(current) > 0 {
+				parts = append(parts, current)
+				current = nil
+			}
+			continue
+		}
+		if len(current) == 0 {
+			current = append(current, a)
+		}
+		current = append(current, b)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// dedupe drops consecutive points that collapse onto the same tile unit.
+func dedupe(line []Point) []Point {
+	if len(line) < 2 {
+		return line
+	}
+	out := make([]Point, 0, len(line))
+	out = append(out, line[0])
+	for _, p := range line[1:] {
+		if p != out[len(out)-1] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func command(id, count uint32) uint32 {
+	return (id & 0x7) | (count << 3)
+}
+
+func zigzag(v int64) uint32 {
+	return uint32(protowire.EncodeZigZag(v))
+}
@@ -0,0 +1,126 @@
+package tiles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fields decodes one level of a protobuf message into field number -> raw
+// values, where varints are returned as their encoded bytes.
+func fields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	t.Helper()
+	out := make(map[protowire.Number][][]byte)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			_, n = protowire.ConsumeVarint(b)
+			v = b[:n]
+		case protowire.Fixed64Type:
+			_, n = protowire.ConsumeFixed64(b)
+			v = b[:n]
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		out[num] = append(out[num], v)
+		b = b[n:]
+	}
+	return out
+}
+
+func varints(t *testing.T, b []byte) []uint64 {
+	t.Helper()
+	var out []uint64
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		require.GreaterOrEqual(t, n, 0)
+		out = append(out, v)
+		b = b[n:]
+	}
+	return out
+}
+
+func TestEncodePointMatchesSpecExample(t *testing.T) {
+	layer := NewLayer("stops")
+	layer.AddPoint(1, Point{X: 25, Y: 17}, map[string]any{"name": "Main St", "wheelchair": true})
+
+	tile := fields(t, Encode(layer))
+	require.Len(t, tile[tileLayers], 1)
+
+	l := fields(t, tile[tileLayers][0])
+	assert.Equal(t, "stops", string(l[layerName][0]))
+	assert.Equal(t, []uint64{2}, varints(t, l[layerVersion][0]))
+	assert.Equal(t, []uint64{DefaultExtent}, varints(t, l[layerExtent][0]))
+	assert.Equal(t, []string{"name", "wheelchair"}, []string{string(l[layerKeys][0]), string(l[layerKeys][1])})
+	require.Len(t, l[layerFeatures], 1)
+
+	f := fields(t, l[layerFeatures][0])
+	assert.Equal(t, []uint64{1}, varints(t, f[featureID][0]))
+	assert.Equal(t, []uint64{geomTypePoint}, varints(t, f[featureType][0]))
+	assert.Equal(t, []uint64{9, 50, 34}, varints(t, f[featureGeometry][0]))
+	assert.Equal(t, []uint64{0, 0, 1, 1}, varints(t, f[featureTags][0]))
+}
+
+func TestEncodeLineStringMatchesSpecExample(t *testing.T) {
+	layer := NewLayer("routes")
+	layer.AddLineStrings(0, [][]Point{{{2, 2}, {2, 10}, {10, 10}}}, nil)
+
+	l := fields(t, fields(t, Encode(layer))[tileLayers][0])
+	f := fields(t, l[layerFeatures][0])
+
+	assert.Empty(t, f[featureID])
+	assert.Equal(t, []uint64{geomTypeLineString}, varints(t, f[featureType][0]))
+	assert.Equal(t, []uint64{9, 4, 4, 18, 0, 16, 16, 0}, varints(t, f[featureGeometry][0]))
+}
+
+func TestEncodeMultiLineStringUsesRelativeCursor(t *testing.T) {
+	layer := NewLayer("routes")
+	layer.AddLineStrings(0, [][]Point{{{2, 2}, {2, 10}, {10, 10}}, {{1, 1}, {3, 5}}}, nil)
+
+	l := fields(t, fields(t, Encode(layer))[tileLayers][0])
+	f := fields(t, l[layerFeatures][0])
+
+	assert.Equal(t, []uint64{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8}, varints(t, f[featureGeometry][0]))
+}
+
+func TestLayerSharesRepeatedValues(t *testing.T) {
+	layer := NewLayer("stops")
+	layer.AddPoint(1, Point{}, map[string]any{"type": 3})
+	layer.AddPoint(2, Point{}, map[string]any{"type": int64(3)})
+	layer.AddPoint(3, Point{}, map[string]any{"type": 1.5, "skipped": []int{1}})
+
+	l := fields(t, fields(t, Encode(layer))[tileLayers][0])
+
+	assert.Len(t, l[layerKeys], 1)
+	assert.Len(t, l[layerValues], 2)
+}
+
+func TestAddLineStringsDropsDegenerateLines(t *testing.T) {
+	layer := NewLayer("routes")
+	layer.AddLineStrings(0, [][]Point{{{1, 1}, {1, 1}}, {{4, 4}}}, nil)
+
+	assert.Zero(t, layer.Len())
+	assert.Empty(t, Encode(layer))
+}
+
+func TestClipLine(t *testing.T) {
+	line := []Point{{-500, 10}, {-400, 10}, {10, 10}, {20, 20}, {9000, 20}, {9100, 20}, {9200, -500}}
+
+	parts := ClipLine(line, DefaultExtent, DefaultBuffer)
+
+	require.Len(t, parts, 1)
+	assert.Equal(t, []Point{{-400, 10}, {10, 10}, {20, 20}, {9000, 20}}, parts[0])
+
+	// A segment crossing the tile with both ends outside it is kept.
+	crossing := ClipLine([]Point{{-1000, 2000}, {5000, 2000}}, DefaultExtent, DefaultBuffer)
+	assert.Len(t, crossing, 1)
+}
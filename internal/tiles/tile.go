@@ -0,0 +1,66 @@
+// Package tiles encodes GTFS geometry as Mapbox vector tiles.
+package tiles
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxZoom is the deepest zoom level served. Beyond it tiles are smaller than a
+// few metres and add nothing for transit geometry.
+const MaxZoom = 22
+
+// Tile identifies a tile in the XYZ (slippy map) scheme.
+type Tile struct {
+	Z, X, Y uint32
+}
+
+// NewTile validates tile coordinates for the given zoom level.
+func NewTile(z, x, y int) (Tile, error) {
+	if z < 0 || z > MaxZoom {
+		return Tile{}, fmt.Errorf("zoom must be between 0 and %d, got %d", MaxZoom, z)
+	}
+	n := 1 << z
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return Tile{}, fmt.Errorf("tile %d/%d is outside zoom level %d", x, y, z)
+	}
+	return Tile{Z: uint32(z), X: uint32(x), Y: uint32(y)}, nil
+}
+
+func (t Tile) String() string {
+	return fmt.Sprintf("%d/%d/%d", t.Z, t.X, t.Y)
+}
+
+// Bounds returns the tile's extent in WGS84 degrees, grown on every side by
+// buffer, a fraction of the tile's width.
+func (t Tile) Bounds(buffer float64) (minLat, minLon, maxLat, maxLon float64) {
+	n := float64(uint64(1) << t.Z)
+	x0, x1 := float64(t.X)-buffer, float64(t.X)+1+buffer
+	y0, y1 := float64(t.Y)-buffer, float64(t.Y)+1+buffer
+	return tileYToLat(y1, n), tileXToLon(x0, n), tileYToLat(y0, n), tileXToLon(x1, n)
+}
+
+// Project converts a WGS84 position to the tile's local coordinate space,
+// where (0, 0) is the top-left corner and (extent, extent) the bottom-right.
+func (t Tile) Project(lat, lon float64, extent uint32) Point {
+	n := float64(uint64(1) << t.Z)
+	lat = math.Max(math.Min(lat, maxMercatorLat), -maxMercatorLat)
+	latRad := lat * math.Pi / 180
+	worldX := (lon + 180) / 360 * n
+	worldY := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n
+	return Point{
+		X: int64(math.Round((worldX - float64(t.X)) * float64(extent))),
+		Y: int64(math.Round((worldY - float64(t.Y)) * float64(extent))),
+	}
+}
+
+// maxMercatorLat is the latitude at which Web Mercator is cut off.
+const maxMercatorLat = 85.0511287798066
+
+func tileXToLon(x, n float64) float64 {
+	return x/n*360 - 180
+}
+
+func tileYToLat(y, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+}
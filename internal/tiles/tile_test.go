@@ -0,0 +1,41 @@
+package tiles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTileValidatesCoordinates(t *testing.T) {
+	tile, err := NewTile(3, 7, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "3/7/0", tile.String())
+
+	_, err = NewTile(3, 8, 0)
+	assert.Error(t, err)
+	_, err = NewTile(-1, 0, 0)
+	assert.Error(t, err)
+	_, err = NewTile(MaxZoom+1, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestTileBounds(t *testing.T) {
+	tile := Tile{Z: 1, X: 1, Y: 0}
+
+	minLat, minLon, maxLat, maxLon := tile.Bounds(0)
+
+	assert.InDelta(t, 0, minLat, 1e-9)
+	assert.InDelta(t, 0, minLon, 1e-9)
+	assert.InDelta(t, maxMercatorLat, maxLat, 1e-9)
+	assert.InDelta(t, 180, maxLon, 1e-9)
+}
+
+func TestTileProject(t *testing.T) {
+	tile := Tile{Z: 1, X: 1, Y: 0}
+
+	assert.Equal(t, Point{X: 0, Y: DefaultExtent}, tile.Project(0, 0, DefaultExtent))
+	assert.Equal(t, Point{X: DefaultExtent, Y: 0}, tile.Project(89, 180, DefaultExtent))
+	// Positions outside the tile project outside the extent.
+	assert.Less(t, tile.Project(0, -90, DefaultExtent).X, int64(0))
+}
@@ -215,6 +215,11 @@ func ParseTimeParameter(timeParam string, currentLocation *time.Location) (strin
 // It accepts a default value and enforces a maximum of 250 (matching Java's MaxCountSupport).
 // Returns an error in fieldErrors if the value is <= 0 or > 250.
 func ParseMaxCount(queryParams url.Values, defaultCount int, fieldErrors map[string][]string) (int, map[string][]string) {
+	return ParseMaxCountWithLimit(queryParams, defaultCount, models.MaxAllowedCount, fieldErrors)
+}
+
+// ParseMaxCountWithLimit is ParseMaxCount with a configurable maximum.
+func ParseMaxCountWithLimit(queryParams url.Values, defaultCount, maxAllowed int, fieldErrors map[string][]string) (int, map[string][]string) {
 	if fieldErrors == nil {
 		fieldErrors = make(map[string][]string)
 	}
@@ -227,8 +232,8 @@ func ParseMaxCount(queryParams url.Values, defaultCount int, fieldErrors map[str
 			if maxCount <= 0 {
 				fieldErrors["maxCount"] = []string{"must be greater than zero"}
 				maxCount = defaultCount
-			} else if maxCount > models.MaxAllowedCount {
-				fieldErrors["maxCount"] = []string{"must not exceed 250"}
+			} else if maxCount > maxAllowed {
+				fieldErrors["maxCount"] = []string{fmt.Sprintf("must not exceed %d", maxAllowed)}
 				maxCount = defaultCount
 			}
 		} else {
@@ -238,6 +243,46 @@ func ParseMaxCount(queryParams url.Values, defaultCount int, fieldErrors map[str
 	return maxCount, fieldErrors
 }
 
+// maxRouteTypeTokens caps how many comma-separated route types a single parameter may carry.
+const maxRouteTypeTokens = 100
+
+// ParseRouteTypes parses a comma-separated list of integer route types from the given
+// query parameter. Blank tokens are skipped. Any malformed token, or more than 100
+// tokens, produces a single field error under key.
+func ParseRouteTypes(queryParams url.Values, key string, fieldErrors map[string][]string) ([]int, map[string][]string) {
+	raw := queryParams.Get(key)
+	if raw == "" {
+		return nil, fieldErrors
+	}
+
+	tokens := strings.Split(raw, ",")
+	if len(tokens) > maxRouteTypeTokens {
+		if fieldErrors == nil {
+			fieldErrors = make(map[string][]string)
+		}
+		fieldErrors[key] = []string{fmt.Sprintf("too many route types (maximum %d allowed)", maxRouteTypeTokens)}
+		return nil, fieldErrors
+	}
+
+	var routeTypes []int
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		routeType, err := strconv.Atoi(token)
+		if err != nil {
+			if fieldErrors == nil {
+				fieldErrors = make(map[string][]string)
+			}
+			fieldErrors[key] = []string{fmt.Sprintf("Invalid field value for field %q.", key)}
+			return nil, fieldErrors
+		}
+		routeTypes = append(routeTypes, routeType)
+	}
+	return routeTypes, fieldErrors
+}
+
 // ParsePaginationParams parses offset and limit from request parameters.
 // maxCount is the primary parameter for limit, falling back to limit.
 // If neither is present, limit is -1 (return all).
@@ -730,6 +730,16 @@ func TestParseMaxCount(t *testing.T) {
 	}
 }
 
+func TestParseMaxCountWithLimit(t *testing.T) {
+	maxCount, fieldErrors := ParseMaxCountWithLimit(url.Values{"maxCount": {"400"}}, 100, 500, nil)
+	assert.Empty(t, fieldErrors)
+	assert.Equal(t, 400, maxCount)
+
+	maxCount, fieldErrors = ParseMaxCountWithLimit(url.Values{"maxCount": {"40"}}, 10, 20, nil)
+	assert.Equal(t, []string{"must not exceed 20"}, fieldErrors["maxCount"])
+	assert.Equal(t, 10, maxCount)
+}
+
 func TestParsePaginationParams(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -1120,3 +1130,37 @@ func TestClampRadius(t *testing.T) {
 	assert.Equal(t, 5000.0, ClampRadius(5000.0))
 	assert.Equal(t, float64(models.MaxSearchRadiusInMeters), ClampRadius(models.MaxSearchRadiusInMeters+10000.0))
 }
+
+func TestParseRouteTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		want       []int
+		wantErrors bool
+	}{
+		{name: "absent", value: "", want: nil},
+		{name: "single", value: "3", want: []int{3}},
+		{name: "list with spaces and blanks", value: "3, 0,,", want: []int{3, 0}},
+		{name: "malformed token", value: "3,bus", wantErrors: true},
+		{name: "too many tokens", value: strings.Repeat("3,", 100) + "3", wantErrors: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := url.Values{}
+			if tt.value != "" {
+				params.Set("routeTypes", tt.value)
+			}
+
+			got, fieldErrors := ParseRouteTypes(params, "routeTypes", nil)
+
+			if tt.wantErrors {
+				assert.Len(t, fieldErrors["routeTypes"], 1)
+				assert.Nil(t, got)
+				return
+			}
+			assert.Empty(t, fieldErrors)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
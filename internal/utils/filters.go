@@ -30,10 +30,7 @@ func FilterRoutes(q *gtfsdb.Queries, ctx context.Context, present map[string]boo
 	for _, r := range routes {
 		routeIDStr := FormCombinedID(r.AgencyID, r.ID)
 		if present[routeIDStr] {
-			refs = append(refs, models.NewRoute(
-				routeIDStr, r.AgencyID, r.ShortName.String, r.LongName.String,
-				r.Desc.String, models.RouteType(r.Type), r.Url.String,
-				r.Color.String, r.TextColor.String))
+			refs = append(refs, RouteFromDatabase(r.AgencyID, gtfsdb.Route(r)))
 		}
 	}
 	return refs
@@ -46,10 +43,7 @@ func GetAllRoutesRefs(q *gtfsdb.Queries, ctx context.Context) []models.Route {
 	}
 	var refs []models.Route
 	for _, r := range routes {
-		refs = append(refs, models.NewRoute(
-			FormCombinedID(r.AgencyID, r.ID), r.AgencyID, r.ShortName.String, r.LongName.String,
-			r.Desc.String, models.RouteType(r.Type), r.Url.String,
-			r.Color.String, r.TextColor.String))
+		refs = append(refs, RouteFromDatabase(r.AgencyID, gtfsdb.Route(r)))
 	}
 	return refs
 }
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+)
+
+// RouteFromDatabase builds the route model of a database route, with its
+// combined ID formed from agencyID. NULL text fields become empty strings.
+// Handlers use it for every route they serialize so route entries and
+// references look the same everywhere.
+func RouteFromDatabase(agencyID string, route gtfsdb.Route) models.Route {
+	model := models.NewRoute(
+		FormCombinedID(agencyID, route.ID),
+		agencyID,
+		route.ShortName.String,
+		route.LongName.String,
+		route.Desc.String,
+		models.RouteType(route.Type),
+		route.Url.String,
+		route.Color.String,
+		route.TextColor.String)
+	if route.SortOrder.Valid {
+		sortOrder := int(route.SortOrder.Int64)
+		model.SortOrder = &sortOrder
+	}
+	return model
+}
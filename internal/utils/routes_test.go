@@ -0,0 +1,61 @@
+package utils_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"maglev.onebusaway.org/gtfsdb"
+	"maglev.onebusaway.org/internal/models"
+	"maglev.onebusaway.org/internal/utils"
+)
+
+func TestRouteFromDatabase(t *testing.T) {
+	route := utils.RouteFromDatabase("25", gtfsdb.Route{
+		ID:        "151",
+		AgencyID:  "25",
+		ShortName: sql.NullString{String: "1", Valid: true},
+		LongName:  sql.NullString{String: "Route 1", Valid: true},
+		Type:      715,
+		Color:     sql.NullString{String: "55d1b0", Valid: true},
+		SortOrder: sql.NullInt64{Int64: 4, Valid: true},
+	})
+
+	assert.Equal(t, "25_151", route.ID)
+	assert.Equal(t, "25", route.AgencyID)
+	assert.Equal(t, "1", route.ShortName)
+	assert.Equal(t, "1", route.NullSafeShortName)
+	assert.Equal(t, models.RouteTypeBus, route.Type)
+	assert.Equal(t, models.RouteType(715), route.RawType)
+	assert.Equal(t, "55d1b0", route.Color)
+	assert.Empty(t, route.TextColor, "NULL text fields become empty strings")
+	assert.Empty(t, route.Description)
+	assert.Empty(t, route.URL)
+	require.NotNil(t, route.SortOrder)
+	assert.Equal(t, 4, *route.SortOrder)
+
+	unordered := utils.RouteFromDatabase("25", gtfsdb.Route{ID: "9", AgencyID: "25"})
+	assert.Nil(t, unordered.SortOrder)
+	assert.Equal(t, "25_9", unordered.NullSafeShortName)
+}
+
+func TestSortRouteReferences(t *testing.T) {
+	sortOrder := func(n int) *int { return &n }
+	routes := []models.Route{
+		{ID: "a_10", ShortName: "10"},
+		{ID: "a_2", ShortName: "2"},
+		{ID: "a_99", ShortName: "99", SortOrder: sortOrder(1)},
+		{ID: "a_5", ShortName: "5", SortOrder: sortOrder(2)},
+		{ID: "a_7", ShortName: "7", SortOrder: sortOrder(1)},
+	}
+
+	utils.SortRouteReferences(routes)
+
+	ids := make([]string, len(routes))
+	for i, route := range routes {
+		ids[i] = route.ID
+	}
+	// By sort order, then naturally by short name; routes without a sort order last.
+	assert.Equal(t, []string{"a_7", "a_99", "a_5", "a_2", "a_10"}, ids)
+}
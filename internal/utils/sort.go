@@ -65,6 +65,25 @@ func SortModelRoutesByName(routes []models.Route) {
 	})
 }
 
+// SortRouteReferences orders route references by the feed's route_sort_order,
+// with routes that set one first, then as SortModelRoutesByName.
+func SortRouteReferences(routes []models.Route) {
+	slices.SortFunc(routes, func(a, b models.Route) int {
+		if (a.SortOrder == nil) != (b.SortOrder == nil) {
+			if a.SortOrder != nil {
+				return -1
+			}
+			return 1
+		}
+		if a.SortOrder != nil {
+			if res := cmp.Compare(*a.SortOrder, *b.SortOrder); res != 0 {
+				return res
+			}
+		}
+		return compareRouteSortKeys(modelRouteSortKey(a), modelRouteSortKey(b))
+	})
+}
+
 // SortAgencyReferencesByID sorts models.AgencyReference values alphabetically by ID.
 func SortAgencyReferencesByID(agencies []models.AgencyReference) {
 	slices.SortFunc(agencies, func(a, b models.AgencyReference) int {
@@ -72,6 +91,38 @@ func SortAgencyReferencesByID(agencies []models.AgencyReference) {
 	})
 }
 
+// SearchDistanceScaleInMeters is how far a search result may be from the
+// searcher before it needs to be twice as relevant to keep its rank.
+const SearchDistanceScaleInMeters = 1000.0
+
+// SearchCandidateLimit is how many text matches to fetch for a search that
+// returns maxCount results after SortByRelevanceAndDistance, so that nearby
+// matches ranked just below the cut can still make it in.
+func SearchCandidateLimit(maxCount int) int {
+	return min(max(maxCount*5, 100), 500)
+}
+
+// SortByRelevanceAndDistance reorders search results, given in descending
+// text relevance, so that nearby results move ahead of slightly more relevant
+// distant ones. Each result scores its relevance position scaled by
+// 1 + distance/SearchDistanceScaleInMeters; ties keep the relevance order.
+func SortByRelevanceAndDistance[T any](items []T, distance func(T) float64) {
+	type scored struct {
+		item  T
+		score float64
+	}
+	ranked := make([]scored, len(items))
+	for i, item := range items {
+		ranked[i] = scored{item, float64(i+1) * (1 + distance(item)/SearchDistanceScaleInMeters)}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int {
+		return cmp.Compare(a.score, b.score)
+	})
+	for i := range ranked {
+		items[i] = ranked[i].item
+	}
+}
+
 func routesForStopRowSortKey(r gtfsdb.GetRoutesForStopRow) RouteSortKey {
 	return RouteSortKey{nulls.StringOrEmpty(r.ShortName), nulls.StringOrEmpty(r.LongName), r.AgencyID, r.ID}
 }
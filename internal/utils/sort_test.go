@@ -148,3 +148,17 @@ func TestSortAgencyReferencesByID(t *testing.T) {
 	assert.Equal(t, "metro", agencies[1].ID)
 	assert.Equal(t, "sound-transit", agencies[2].ID)
 }
+
+func TestSortByRelevanceAndDistance(t *testing.T) {
+	distances := map[string]float64{"best": 5000, "good": 100, "fair": 0, "poor": 20000}
+	items := []string{"best", "good", "fair", "poor"}
+
+	utils.SortByRelevanceAndDistance(items, func(s string) float64 { return distances[s] })
+
+	// Scores: best 6, good 2.2, fair 3, poor 84.
+	assert.Equal(t, []string{"good", "fair", "best", "poor"}, items)
+
+	equal := []string{"a", "b", "c"}
+	utils.SortByRelevanceAndDistance(equal, func(string) float64 { return 0 })
+	assert.Equal(t, []string{"a", "b", "c"}, equal, "equal distances keep the relevance order")
+}
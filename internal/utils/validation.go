@@ -2,9 +2,12 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"time"
+
+	"maglev.onebusaway.org/internal/models"
 )
 
 // Compiled regular expressions for validation
@@ -107,6 +110,17 @@ func ValidateDate(date string) error {
 	return nil
 }
 
+// ValidateRouteType checks that a value is a GTFS route type: one of the basic
+// route_type codes (0-7, 11, 12) or a code in the extended route type range (100-1702).
+func ValidateRouteType(routeType int) error {
+	isBasic := (routeType >= 0 && routeType <= 7) || routeType == 11 || routeType == 12
+	isExtended := routeType >= models.MinExtendedRouteType && routeType <= models.MaxExtendedRouteType
+	if !isBasic && !isExtended {
+		return fmt.Errorf("invalid GTFS route type %d", routeType)
+	}
+	return nil
+}
+
 // SanitizeInput removes HTML tags and other potentially dangerous content
 func SanitizeInput(input string) string {
 	// Remove HTML tags
@@ -476,3 +476,12 @@ func TestValidateDate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRouteType(t *testing.T) {
+	for _, valid := range []int{0, 3, 7, 11, 12, 100, 700, 1702} {
+		assert.NoError(t, ValidateRouteType(valid), "route type %d should be valid", valid)
+	}
+	for _, invalid := range []int{-1, 8, 10, 13, 99, 1703} {
+		assert.Error(t, ValidateRouteType(invalid), "route type %d should be invalid", invalid)
+	}
+}
@@ -9,8 +9,11 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"maglev.onebusaway.org/internal/appconf"
+	"maglev.onebusaway.org/internal/buildinfo"
+	"maglev.onebusaway.org/internal/gtfs"
 )
 
 //go:embed debug_index.html
@@ -26,7 +29,10 @@ func writeDebugData(w http.ResponseWriter, title string, data any) {
 	w.WriteHeader(http.StatusOK)
 
 	fmt.Fprintf(w, "<!doctype html><html><head><meta charset=\"utf-8\"><title>%s</title></head><body>", html.EscapeString(title))
-	fmt.Fprintf(w, "<h1>%s</h1><pre>", html.EscapeString(title))
+	fmt.Fprintf(w, "<h1>%s</h1>", html.EscapeString(title))
+	build := buildinfo.Current()
+	fmt.Fprintf(w, "<p>maglev %s (commit %s, built %s)</p><pre>",
+		html.EscapeString(build.Version), html.EscapeString(buildinfo.ShortCommit()), html.EscapeString(build.BuildTime))
 
 	if data == nil {
 		io.WriteString(w, "nil")
@@ -90,12 +96,40 @@ func (webUI *WebUI) debugIndexHandler(w http.ResponseWriter, r *http.Request) {
 	case "realtime_vehicles":
 		data = webUI.GtfsManager.GetRealTimeVehicles()
 		title = "GTFS Realtime - Vehicles"
+	case "block_assignments":
+		data = webUI.blockAssignments(ctx)
+		title = "GTFS Realtime - Block Assignments"
 	default:
 		data = map[string]string{
-			"error": "Please use one of the following: agencies, routes, stops, trips, realtime_trips, realtime_vehicles.",
+			"error": "Please use one of the following: agencies, routes, stops, trips, realtime_trips, realtime_vehicles, block_assignments.",
 		}
 		title = "Choose a data type"
 	}
 
 	writeDebugData(w, title, data)
 }
+
+// blockAssignments reports the active blocks of every agency at the current
+// time in the agency's timezone, unassigned blocks first.
+func (webUI *WebUI) blockAssignments(ctx context.Context) map[string][]gtfs.BlockAssignment {
+	agencies, err := webUI.GtfsManager.GtfsDB.Queries.ListAgencies(ctx)
+	if err != nil {
+		slog.Error("debug: failed to list agencies", "error", err)
+		return nil
+	}
+	report := make(map[string][]gtfs.BlockAssignment, len(agencies))
+	for _, agency := range agencies {
+		loc, err := time.LoadLocation(agency.Timezone)
+		if err != nil {
+			slog.Error("debug: invalid agency timezone", "agency", agency.ID, "error", err)
+			continue
+		}
+		assignments, err := webUI.GtfsManager.GetBlockAssignments(ctx, agency.ID, webUI.Clock.Now().In(loc))
+		if err != nil {
+			slog.Error("debug: failed to get block assignments", "agency", agency.ID, "error", err)
+			continue
+		}
+		report[agency.ID] = assignments
+	}
+	return report
+}
@@ -66,7 +66,7 @@ func TestWriteDebugData(t *testing.T) {
 			name:           "string data",
 			title:          "Test String",
 			data:           "hello world",
-			expectedInBody: []string{"Test String", "hello world"},
+			expectedInBody: []string{"Test String", "hello world", "maglev dev (commit unknown, built unknown)"},
 		},
 		{
 			name:           "nil data",